@@ -32,6 +32,7 @@ import (
 	nhbstate "nhbchain/core/state"
 	"nhbchain/crypto"
 	nativecommon "nhbchain/native/common"
+	"nhbchain/native/governance"
 	"nhbchain/native/lending"
 	nativeparams "nhbchain/native/params"
 	swap "nhbchain/native/swap"
@@ -177,6 +178,17 @@ func main() {
 	if err != nil {
 		panic(fmt.Sprintf("Failed to create node: %v", err))
 	}
+	exe, err := os.Executable()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to locate running binary: %v", err))
+	}
+	if _, err := node.CheckPendingUpgrade(exe); err != nil {
+		panic(fmt.Sprintf("Software upgrade check failed: %v", err))
+	}
+	node.SetUpgradeHaltHandler(func(plan governance.UpgradePlan) {
+		fmt.Printf("--- Chain halted at height %d for software upgrade %q; restart with the new binary ---\n", plan.Height, plan.Name)
+		os.Exit(0)
+	})
 
 	if err := node.SetGlobalConfig(cfg.Global); err != nil {
 		log.Fatal("invalid global configuration", "err", err)
@@ -242,6 +254,8 @@ func main() {
 	node.SetLendingRiskParameters(lending.RiskParameters{
 		MaxLTV:               cfg.Lending.MaxLTVBps,
 		LiquidationThreshold: cfg.Lending.LiquidationThresholdBps,
+		CloseFactorBps:       cfg.Lending.CloseFactorBps,
+		Auction:              cfg.Lending.Auction,
 		DeveloperFeeCapBps:   cfg.Lending.DeveloperFeeBps,
 	})
 
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type callResultResponse struct {
+	ReturnData   string `json:"returnData"`
+	GasUsed      string `json:"gasUsed"`
+	Reverted     bool   `json:"reverted"`
+	RevertReason string `json:"revertReason,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// runCallCommand executes a read-only contract call via nhb_call.
+func runCallCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("call", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", "", "caller address (bech32 or 0x hex)")
+	value := fs.String("value", "", "wei value attached to the call")
+	gas := fs.String("gas", "", "gas limit (defaults to the node's call limit)")
+	block := fs.String("block", "latest", "block height or \"latest\"")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() < 1 {
+		fmt.Fprintln(stderr, callUsage())
+		return 1
+	}
+	call := map[string]string{"to": fs.Arg(0)}
+	if fs.NArg() > 1 {
+		call["data"] = fs.Arg(1)
+	}
+	if strings.TrimSpace(*from) != "" {
+		call["from"] = *from
+	}
+	if strings.TrimSpace(*value) != "" {
+		call["value"] = *value
+	}
+	if strings.TrimSpace(*gas) != "" {
+		call["gas"] = *gas
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":     1,
+		"method": "nhb_call",
+		"params": []interface{}{call, *block},
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error building request: %v\n", err)
+		return 1
+	}
+	resp, err := doRPCRequest(payload, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "RPC error: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+	var rpcResp struct {
+		Result callResultResponse `json:"result"`
+		Error  *rpcError          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		fmt.Fprintf(stderr, "Failed to decode response: %v\n", err)
+		return 1
+	}
+	if rpcResp.Error != nil {
+		fmt.Fprintf(stderr, "RPC error: %s\n", rpcResp.Error.Message)
+		return 1
+	}
+	result := rpcResp.Result
+	fmt.Fprintf(stdout, "Return data: %s\n", result.ReturnData)
+	fmt.Fprintf(stdout, "Gas used:    %s\n", result.GasUsed)
+	if result.Reverted {
+		reason := result.RevertReason
+		if reason == "" {
+			reason = "(no reason)"
+		}
+		fmt.Fprintf(stdout, "Reverted:    %s\n", reason)
+		return 2
+	}
+	if result.Error != "" {
+		fmt.Fprintf(stdout, "VM error:    %s\n", result.Error)
+		return 2
+	}
+	return 0
+}
+
+func callUsage() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "Usage: nhb-cli call [--from <addr>] [--value <wei>] [--gas <limit>] [--block <height|latest>] <contract> [calldata_hex]")
+	return buf.String()
+}
@@ -28,6 +28,22 @@ func runIdentityCommand(args []string, stdout, stderr io.Writer) int {
 		return runIdentitySetPrimary(args[1:], stdout, stderr)
 	case "rename":
 		return runIdentityRename(args[1:], stdout, stderr)
+	case "renew":
+		return runIdentityRenew(args[1:], stdout, stderr)
+	case "list-for-sale":
+		return runIdentityListForSale(args[1:], stdout, stderr)
+	case "cancel-listing":
+		return runIdentityCancelListing(args[1:], stdout, stderr)
+	case "buy":
+		return runIdentityBuy(args[1:], stdout, stderr)
+	case "sub-create":
+		return runIdentitySubCreate(args[1:], stdout, stderr)
+	case "sub-reassign":
+		return runIdentitySubReassign(args[1:], stdout, stderr)
+	case "sub-revoke":
+		return runIdentitySubRevoke(args[1:], stdout, stderr)
+	case "sub-list":
+		return runIdentitySubList(args[1:], stdout, stderr)
 	case "resolve":
 		return runIdentityResolve(args[1:], stdout, stderr)
 	case "reverse":
@@ -261,6 +277,249 @@ func runIdentityRename(args []string, stdout, stderr io.Writer) int {
 	return 0
 }
 
+func runIdentityRenew(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("id renew", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var owner, alias string
+	fs.StringVar(&owner, "owner", "", "bech32 address owning the alias")
+	fs.StringVar(&alias, "alias", "", "alias to renew")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "Error: unexpected positional arguments")
+		return 1
+	}
+	trimmedOwner := strings.TrimSpace(owner)
+	trimmedAlias := strings.TrimSpace(alias)
+	if trimmedOwner == "" || trimmedAlias == "" {
+		fmt.Fprintln(stderr, "Error: --owner and --alias are required")
+		return 1
+	}
+	payload := map[string]interface{}{
+		"owner": trimmedOwner,
+		"alias": trimmedAlias,
+	}
+	return runIdentityObjectCall(stdout, stderr, "identity_renew", payload)
+}
+
+func runIdentityListForSale(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("id list-for-sale", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var owner, alias, price, buyer string
+	fs.StringVar(&owner, "owner", "", "bech32 address owning the alias")
+	fs.StringVar(&alias, "alias", "", "alias to list")
+	fs.StringVar(&price, "price", "", "sale price in wei of NHB")
+	fs.StringVar(&buyer, "buyer", "", "optional bech32 address the listing is reserved for")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "Error: unexpected positional arguments")
+		return 1
+	}
+	trimmedOwner := strings.TrimSpace(owner)
+	trimmedAlias := strings.TrimSpace(alias)
+	trimmedPrice := strings.TrimSpace(price)
+	if trimmedOwner == "" || trimmedAlias == "" || trimmedPrice == "" {
+		fmt.Fprintln(stderr, "Error: --owner, --alias, and --price are required")
+		return 1
+	}
+	payload := map[string]interface{}{
+		"owner": trimmedOwner,
+		"alias": trimmedAlias,
+		"price": trimmedPrice,
+	}
+	if trimmedBuyer := strings.TrimSpace(buyer); trimmedBuyer != "" {
+		payload["buyer"] = trimmedBuyer
+	}
+	return runIdentityObjectCall(stdout, stderr, "identity_listForSale", payload)
+}
+
+func runIdentityCancelListing(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("id cancel-listing", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var owner, alias string
+	fs.StringVar(&owner, "owner", "", "bech32 address owning the alias")
+	fs.StringVar(&alias, "alias", "", "alias whose listing to cancel")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "Error: unexpected positional arguments")
+		return 1
+	}
+	trimmedOwner := strings.TrimSpace(owner)
+	trimmedAlias := strings.TrimSpace(alias)
+	if trimmedOwner == "" || trimmedAlias == "" {
+		fmt.Fprintln(stderr, "Error: --owner and --alias are required")
+		return 1
+	}
+	payload := map[string]interface{}{
+		"owner": trimmedOwner,
+		"alias": trimmedAlias,
+	}
+	return runIdentityObjectCall(stdout, stderr, "identity_cancelListing", payload)
+}
+
+func runIdentityBuy(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("id buy", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var buyer, alias, price string
+	fs.StringVar(&buyer, "buyer", "", "bech32 address buying the alias")
+	fs.StringVar(&alias, "alias", "", "listed alias to buy")
+	fs.StringVar(&price, "price", "", "listed price in wei of NHB")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "Error: unexpected positional arguments")
+		return 1
+	}
+	trimmedBuyer := strings.TrimSpace(buyer)
+	trimmedAlias := strings.TrimSpace(alias)
+	trimmedPrice := strings.TrimSpace(price)
+	if trimmedBuyer == "" || trimmedAlias == "" || trimmedPrice == "" {
+		fmt.Fprintln(stderr, "Error: --buyer, --alias, and --price are required")
+		return 1
+	}
+	payload := map[string]interface{}{
+		"buyer": trimmedBuyer,
+		"alias": trimmedAlias,
+		"price": trimmedPrice,
+	}
+	return runIdentityObjectCall(stdout, stderr, "identity_buy", payload)
+}
+
+func runIdentitySubCreate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("id sub-create", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var owner, parent, label, addr string
+	fs.StringVar(&owner, "owner", "", "bech32 address owning the parent alias")
+	fs.StringVar(&parent, "parent", "", "parent alias")
+	fs.StringVar(&label, "label", "", "sub-alias label, e.g. cashier1 for cashier1.<parent>")
+	fs.StringVar(&addr, "addr", "", "bech32 address the sub-alias resolves to")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "Error: unexpected positional arguments")
+		return 1
+	}
+	trimmedOwner := strings.TrimSpace(owner)
+	trimmedParent := strings.TrimSpace(parent)
+	trimmedLabel := strings.TrimSpace(label)
+	trimmedAddr := strings.TrimSpace(addr)
+	if trimmedOwner == "" || trimmedParent == "" || trimmedLabel == "" || trimmedAddr == "" {
+		fmt.Fprintln(stderr, "Error: --owner, --parent, --label, and --addr are required")
+		return 1
+	}
+	payload := map[string]interface{}{
+		"owner":   trimmedOwner,
+		"parent":  trimmedParent,
+		"label":   trimmedLabel,
+		"address": trimmedAddr,
+	}
+	return runIdentityObjectCall(stdout, stderr, "identity_createSubAlias", payload)
+}
+
+func runIdentitySubReassign(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("id sub-reassign", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var owner, alias, addr string
+	fs.StringVar(&owner, "owner", "", "bech32 address owning the parent alias")
+	fs.StringVar(&alias, "alias", "", "full sub-alias, e.g. cashier1.acme")
+	fs.StringVar(&addr, "addr", "", "new bech32 address the sub-alias resolves to")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "Error: unexpected positional arguments")
+		return 1
+	}
+	trimmedOwner := strings.TrimSpace(owner)
+	trimmedAlias := strings.TrimSpace(alias)
+	trimmedAddr := strings.TrimSpace(addr)
+	if trimmedOwner == "" || trimmedAlias == "" || trimmedAddr == "" {
+		fmt.Fprintln(stderr, "Error: --owner, --alias, and --addr are required")
+		return 1
+	}
+	payload := map[string]interface{}{
+		"owner":   trimmedOwner,
+		"alias":   trimmedAlias,
+		"address": trimmedAddr,
+	}
+	return runIdentityObjectCall(stdout, stderr, "identity_reassignSubAlias", payload)
+}
+
+func runIdentitySubRevoke(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("id sub-revoke", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var owner, alias string
+	fs.StringVar(&owner, "owner", "", "bech32 address owning the parent alias")
+	fs.StringVar(&alias, "alias", "", "full sub-alias to revoke")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "Error: unexpected positional arguments")
+		return 1
+	}
+	trimmedOwner := strings.TrimSpace(owner)
+	trimmedAlias := strings.TrimSpace(alias)
+	if trimmedOwner == "" || trimmedAlias == "" {
+		fmt.Fprintln(stderr, "Error: --owner and --alias are required")
+		return 1
+	}
+	payload := map[string]interface{}{
+		"owner": trimmedOwner,
+		"alias": trimmedAlias,
+	}
+	return runIdentityObjectCall(stdout, stderr, "identity_revokeSubAlias", payload)
+}
+
+func runIdentitySubList(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("id sub-list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var parent string
+	fs.StringVar(&parent, "parent", "", "parent alias")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "Error: unexpected positional arguments")
+		return 1
+	}
+	trimmed := strings.TrimSpace(parent)
+	if trimmed == "" {
+		fmt.Fprintln(stderr, "Error: --parent is required")
+		return 1
+	}
+	result, rpcErr, err := identityRPCCall("identity_listSubAliases", []interface{}{trimmed}, false)
+	if err != nil {
+		return handleRPCCallError(stderr, err)
+	}
+	if rpcErr != nil {
+		return handleRPCError(stderr, rpcErr)
+	}
+	writeRPCResult(stdout, result)
+	return 0
+}
+
+// runIdentityObjectCall sends an authenticated identity RPC that takes a single
+// parameter object and prints its result.
+func runIdentityObjectCall(stdout, stderr io.Writer, method string, payload map[string]interface{}) int {
+	result, rpcErr, err := identityRPCCall(method, []interface{}{payload}, true)
+	if err != nil {
+		return handleRPCCallError(stderr, err)
+	}
+	if rpcErr != nil {
+		return handleRPCError(stderr, rpcErr)
+	}
+	writeRPCResult(stdout, result)
+	return 0
+}
+
 func runIdentityResolve(args []string, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("id resolve", flag.ContinueOnError)
 	fs.SetOutput(stderr)
@@ -410,6 +669,14 @@ Commands:
   remove-address     Unlink an address from an alias
   set-primary        Promote an address to become the alias primary
   rename             Rename an existing alias
+  renew              Extend an alias registration by one term
+  list-for-sale      List an alias for sale, optionally to a single buyer
+  cancel-listing     Withdraw an alias sale listing
+  buy                Buy a listed alias through escrow
+  sub-create         Delegate a sub-alias such as cashier1.<parent>
+  sub-reassign       Point a sub-alias at a different address
+  sub-revoke         Remove a sub-alias
+  sub-list           List the sub-aliases of a parent alias
   resolve            Resolve an alias to metadata and addresses
   reverse            Look up the alias associated with an address
   create-claimable   Create a pay-by-email claimable escrow
@@ -138,4 +138,75 @@ func TestIdentityCommandRPCSuccess(t *testing.T) {
 			t.Fatalf("unexpected stdout: %q", stdout.String())
 		}
 	})
+
+	t.Run("buy", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		original := identityRPCCall
+		identityRPCCall = func(method string, params []interface{}, requireAuth bool) (json.RawMessage, *rpcError, error) {
+			if method != "identity_buy" {
+				t.Fatalf("unexpected method %s", method)
+			}
+			if !requireAuth {
+				t.Fatalf("expected authenticated call")
+			}
+			expected := map[string]interface{}{
+				"buyer": addr,
+				"alias": alias,
+				"price": "1000",
+			}
+			if diff := diffParams(params[0], expected); diff != "" {
+				t.Fatalf("unexpected params diff: %s", diff)
+			}
+			return json.RawMessage(`{"alias":"builder"}`), nil, nil
+		}
+		defer func() { identityRPCCall = original }()
+
+		exit := runIdentityCommand([]string{"buy", "--buyer", addr, "--alias", alias, "--price", "1000"}, stdout, stderr)
+		if exit != 0 {
+			t.Fatalf("unexpected exit code: %d", exit)
+		}
+		if stderr.Len() != 0 {
+			t.Fatalf("expected empty stderr, got %q", stderr.String())
+		}
+		if stdout.String() != "{\"alias\":\"builder\"}\n" {
+			t.Fatalf("unexpected stdout: %q", stdout.String())
+		}
+	})
+
+	t.Run("sub_create", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		original := identityRPCCall
+		identityRPCCall = func(method string, params []interface{}, requireAuth bool) (json.RawMessage, *rpcError, error) {
+			if method != "identity_createSubAlias" {
+				t.Fatalf("unexpected method %s", method)
+			}
+			if !requireAuth {
+				t.Fatalf("expected authenticated call")
+			}
+			expected := map[string]interface{}{
+				"owner":   owner,
+				"parent":  alias,
+				"label":   "cashier1",
+				"address": addr,
+			}
+			if diff := diffParams(params[0], expected); diff != "" {
+				t.Fatalf("unexpected params diff: %s", diff)
+			}
+			return json.RawMessage(`{"alias":"cashier1.builder"}`), nil, nil
+		}
+		defer func() { identityRPCCall = original }()
+
+		exit := runIdentityCommand([]string{"sub-create", "--owner", owner, "--parent", alias, "--label", "cashier1", "--addr", addr}, stdout, stderr)
+		if exit != 0 {
+			t.Fatalf("unexpected exit code: %d", exit)
+		}
+		if stderr.Len() != 0 {
+			t.Fatalf("expected empty stderr, got %q", stderr.String())
+		}
+		if stdout.String() != "{\"alias\":\"cashier1.builder\"}\n" {
+			t.Fatalf("unexpected stdout: %q", stdout.String())
+		}
+	})
 }
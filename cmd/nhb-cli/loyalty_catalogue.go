@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/types"
+)
+
+func loyaltySetCatalogueItem(caller, programID, spec string) {
+	param := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(spec), &param); err != nil {
+		fmt.Printf("Invalid catalogue item JSON: %v\n", err)
+		return
+	}
+	param["caller"] = caller
+	param["programId"] = programID
+	if _, err := callLoyaltyRPC("loyalty_setCatalogueItem", param, true); err != nil {
+		fmt.Printf("Error setting catalogue item: %v\n", err)
+		return
+	}
+	fmt.Println("Catalogue item saved.")
+}
+
+func loyaltyListCatalogue(programID string) {
+	param := map[string]string{"programId": programID}
+	result, err := callLoyaltyRPC("loyalty_listCatalogue", param, false)
+	if err != nil {
+		fmt.Printf("Error listing catalogue: %v\n", err)
+		return
+	}
+	printJSONResult(result)
+}
+
+func loyaltyGetRedemption(id string) {
+	param := map[string]string{"id": id}
+	result, err := callLoyaltyRPC("loyalty_getRedemption", param, false)
+	if err != nil {
+		fmt.Printf("Error fetching redemption: %v\n", err)
+		return
+	}
+	printJSONResult(result)
+}
+
+func loyaltyUserTier(programID, user string) {
+	param := map[string]string{"programId": programID, "user": user}
+	result, err := callLoyaltyRPC("loyalty_userTier", param, false)
+	if err != nil {
+		fmt.Printf("Error fetching user tier: %v\n", err)
+		return
+	}
+	printJSONResult(result)
+}
+
+// loyaltyRedeem signs a TxTypeLoyaltyRedeem transaction spending the key
+// holder's program rewards on a catalogue item. It returns a process exit
+// code like the other transaction-sending subcommands.
+func loyaltyRedeem(programID, itemID, quantity, keyFile string) int {
+	cleaned := strings.TrimPrefix(strings.TrimSpace(programID), "0x")
+	idBytes, err := hex.DecodeString(cleaned)
+	if err != nil || len(idBytes) != 32 {
+		fmt.Println("Error: programId must be a 32-byte hex string")
+		return 1
+	}
+	qty, err := strconv.ParseUint(strings.TrimSpace(quantity), 10, 64)
+	if err != nil || qty == 0 {
+		fmt.Println("Error: quantity must be a positive integer")
+		return 1
+	}
+	privKey, err := loadPrivateKey(keyFile)
+	if err != nil {
+		fmt.Printf("Error loading private key: %v\n", err)
+		return 1
+	}
+	account, err := fetchAccount(privKey.PubKey().Address().String())
+	if err != nil {
+		fmt.Printf("Error fetching account details: %v\n", err)
+		return 1
+	}
+	payload := struct {
+		ProgramID [32]byte
+		ItemID    string
+		Quantity  uint64
+	}{ItemID: strings.TrimSpace(itemID), Quantity: qty}
+	copy(payload.ProgramID[:], idBytes)
+	data, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		fmt.Printf("Error encoding payload: %v\n", err)
+		return 1
+	}
+	tx := types.Transaction{
+		ChainID:  types.NHBChainID(),
+		Type:     types.TxTypeLoyaltyRedeem,
+		Nonce:    account.Nonce,
+		Data:     data,
+		GasLimit: 21000,
+		GasPrice: big.NewInt(1),
+	}
+	if err := tx.Sign(privKey.PrivateKey); err != nil {
+		fmt.Printf("Error signing transaction: %v\n", err)
+		return 1
+	}
+	if _, err := sendTransaction(&tx); err != nil {
+		fmt.Printf("Error sending transaction: %v\n", err)
+		return 1
+	}
+	hash, err := tx.Hash()
+	if err != nil {
+		fmt.Printf("Error computing transaction hash: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Redemption submitted. Transaction hash: 0x%s\n", hex.EncodeToString(hash))
+	fmt.Println("Look up the receipt with loyalty-get-redemption once the transaction is included.")
+	return 0
+}
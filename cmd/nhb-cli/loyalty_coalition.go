@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/types"
+)
+
+func loyaltyCreateCoalition(caller, spec string) {
+	param := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(spec), &param); err != nil {
+		fmt.Printf("Invalid coalition JSON: %v\n", err)
+		return
+	}
+	param["caller"] = caller
+	if _, err := callLoyaltyRPC("loyalty_createCoalition", param, true); err != nil {
+		fmt.Printf("Error creating coalition: %v\n", err)
+		return
+	}
+	fmt.Println("Coalition created.")
+}
+
+// loyaltyCoalitionMembership invites, joins or removes a program depending
+// on the RPC method.
+func loyaltyCoalitionMembership(method, caller, coalitionID, programID string) {
+	param := map[string]string{
+		"caller":      caller,
+		"coalitionId": coalitionID,
+		"programId":   programID,
+	}
+	if _, err := callLoyaltyRPC(method, param, true); err != nil {
+		fmt.Printf("Error updating coalition membership: %v\n", err)
+		return
+	}
+	fmt.Println("Coalition membership updated.")
+}
+
+func loyaltyGetCoalition(coalitionID string) {
+	param := map[string]string{"coalitionId": coalitionID}
+	result, err := callLoyaltyRPC("loyalty_getCoalition", param, false)
+	if err != nil {
+		fmt.Printf("Error fetching coalition: %v\n", err)
+		return
+	}
+	printJSONResult(result)
+}
+
+// loyaltyCoalitionReport prints the settlement report of a coalition period.
+// With --csv the per-member positions and transfers are written as CSV so the
+// report can be exported to accounting tools.
+func loyaltyCoalitionReport(args []string) {
+	var (
+		coalitionID string
+		period      string
+		asCSV       bool
+	)
+	for _, arg := range args {
+		switch {
+		case arg == "--csv":
+			asCSV = true
+		case coalitionID == "":
+			coalitionID = arg
+		case period == "":
+			period = arg
+		}
+	}
+	if coalitionID == "" {
+		fmt.Println("Usage: loyalty-coalition-report <coalitionId> [period] [--csv]")
+		return
+	}
+	param := map[string]interface{}{"coalitionId": coalitionID}
+	if period != "" {
+		value, err := strconv.ParseUint(period, 10, 64)
+		if err != nil {
+			fmt.Println("Error: period must be a non-negative integer")
+			return
+		}
+		param["period"] = value
+	}
+	result, err := callLoyaltyRPC("loyalty_coalitionReport", param, false)
+	if err != nil {
+		fmt.Printf("Error fetching coalition report: %v\n", err)
+		return
+	}
+	if !asCSV {
+		printJSONResult(result)
+		return
+	}
+	var report struct {
+		CoalitionID string `json:"coalitionId"`
+		Period      uint64 `json:"period"`
+		Settled     bool   `json:"settled"`
+		Members     []struct {
+			ProgramID string `json:"programId"`
+			Issued    string `json:"issued"`
+			Redeemed  string `json:"redeemed"`
+			Net       string `json:"net"`
+		} `json:"members"`
+		Transfers []struct {
+			From   string `json:"from"`
+			To     string `json:"to"`
+			Amount string `json:"amount"`
+			Paid   string `json:"paid"`
+		} `json:"transfers"`
+	}
+	if err := json.Unmarshal(result, &report); err != nil {
+		fmt.Printf("Error decoding coalition report: %v\n", err)
+		return
+	}
+	writer := csv.NewWriter(os.Stdout)
+	periodStr := strconv.FormatUint(report.Period, 10)
+	settled := strconv.FormatBool(report.Settled)
+	_ = writer.Write([]string{"record", "coalitionId", "period", "settled", "programId", "counterparty", "issued", "redeemed", "net", "amount", "paid"})
+	for _, member := range report.Members {
+		_ = writer.Write([]string{"member", report.CoalitionID, periodStr, settled, member.ProgramID, "", member.Issued, member.Redeemed, member.Net, "", ""})
+	}
+	for _, transfer := range report.Transfers {
+		_ = writer.Write([]string{"transfer", report.CoalitionID, periodStr, settled, transfer.From, transfer.To, "", "", "", transfer.Amount, transfer.Paid})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		fmt.Printf("Error writing CSV: %v\n", err)
+	}
+}
+
+// loyaltySettleCoalition signs a TxTypeLoyaltyCoalitionSettle transaction
+// for a closed period. Any account may submit it. It returns a process exit
+// code like the other transaction-sending subcommands.
+func loyaltySettleCoalition(coalitionID, period, keyFile string) int {
+	cleaned := strings.TrimPrefix(strings.TrimSpace(coalitionID), "0x")
+	idBytes, err := hex.DecodeString(cleaned)
+	if err != nil || len(idBytes) != 32 {
+		fmt.Println("Error: coalitionId must be a 32-byte hex string")
+		return 1
+	}
+	periodValue, err := strconv.ParseUint(strings.TrimSpace(period), 10, 64)
+	if err != nil {
+		fmt.Println("Error: period must be a non-negative integer")
+		return 1
+	}
+	privKey, err := loadPrivateKey(keyFile)
+	if err != nil {
+		fmt.Printf("Error loading private key: %v\n", err)
+		return 1
+	}
+	account, err := fetchAccount(privKey.PubKey().Address().String())
+	if err != nil {
+		fmt.Printf("Error fetching account details: %v\n", err)
+		return 1
+	}
+	payload := struct {
+		CoalitionID [32]byte
+		Period      uint64
+	}{Period: periodValue}
+	copy(payload.CoalitionID[:], idBytes)
+	data, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		fmt.Printf("Error encoding payload: %v\n", err)
+		return 1
+	}
+	tx := types.Transaction{
+		ChainID:  types.NHBChainID(),
+		Type:     types.TxTypeLoyaltyCoalitionSettle,
+		Nonce:    account.Nonce,
+		Data:     data,
+		GasLimit: 21000,
+		GasPrice: big.NewInt(1),
+	}
+	if err := tx.Sign(privKey.PrivateKey); err != nil {
+		fmt.Printf("Error signing transaction: %v\n", err)
+		return 1
+	}
+	if _, err := sendTransaction(&tx); err != nil {
+		fmt.Printf("Error sending transaction: %v\n", err)
+		return 1
+	}
+	hash, err := tx.Hash()
+	if err != nil {
+		fmt.Printf("Error computing transaction hash: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Settlement submitted. Transaction hash: 0x%s\n", hex.EncodeToString(hash))
+	fmt.Println("Inspect the result with loyalty-coalition-report once the transaction is included.")
+	return 0
+}
@@ -121,6 +121,12 @@ func main() {
 			return
 		}
 		deploy(args[1], args[2])
+	case "call":
+		code := runCallCommand(args[1:], os.Stdout, os.Stderr)
+		if code != 0 {
+			os.Exit(code)
+		}
+		return
 	case "id":
 		code := runIdentityCommand(args[1:], os.Stdout, os.Stderr)
 		if code != 0 {
@@ -272,6 +278,78 @@ func main() {
 			return
 		}
 		loyaltyUserQR(args[1], args[2])
+	case "loyalty-set-catalogue-item":
+		if len(args) < 4 {
+			fmt.Println("Usage: loyalty-set-catalogue-item <caller> <programId> <itemJSON>")
+			return
+		}
+		loyaltySetCatalogueItem(args[1], args[2], args[3])
+	case "loyalty-list-catalogue":
+		if len(args) < 2 {
+			fmt.Println("Usage: loyalty-list-catalogue <programId>")
+			return
+		}
+		loyaltyListCatalogue(args[1])
+	case "loyalty-get-redemption":
+		if len(args) < 2 {
+			fmt.Println("Usage: loyalty-get-redemption <receiptId>")
+			return
+		}
+		loyaltyGetRedemption(args[1])
+	case "loyalty-user-tier":
+		if len(args) < 3 {
+			fmt.Println("Usage: loyalty-user-tier <programId> <user>")
+			return
+		}
+		loyaltyUserTier(args[1], args[2])
+	case "loyalty-redeem":
+		if len(args) < 5 {
+			fmt.Println("Usage: loyalty-redeem <programId> <itemId> <quantity> <key_file>")
+			return
+		}
+		if code := loyaltyRedeem(args[1], args[2], args[3], args[4]); code != 0 {
+			os.Exit(code)
+		}
+	case "loyalty-create-coalition":
+		if len(args) < 3 {
+			fmt.Println("Usage: loyalty-create-coalition <caller> <coalitionJSON>")
+			return
+		}
+		loyaltyCreateCoalition(args[1], args[2])
+	case "loyalty-invite-coalition-member":
+		if len(args) < 4 {
+			fmt.Println("Usage: loyalty-invite-coalition-member <caller> <coalitionId> <programId>")
+			return
+		}
+		loyaltyCoalitionMembership("loyalty_inviteCoalitionMember", args[1], args[2], args[3])
+	case "loyalty-join-coalition":
+		if len(args) < 4 {
+			fmt.Println("Usage: loyalty-join-coalition <caller> <coalitionId> <programId>")
+			return
+		}
+		loyaltyCoalitionMembership("loyalty_joinCoalition", args[1], args[2], args[3])
+	case "loyalty-leave-coalition":
+		if len(args) < 4 {
+			fmt.Println("Usage: loyalty-leave-coalition <caller> <coalitionId> <programId>")
+			return
+		}
+		loyaltyCoalitionMembership("loyalty_leaveCoalition", args[1], args[2], args[3])
+	case "loyalty-get-coalition":
+		if len(args) < 2 {
+			fmt.Println("Usage: loyalty-get-coalition <coalitionId>")
+			return
+		}
+		loyaltyGetCoalition(args[1])
+	case "loyalty-coalition-report":
+		loyaltyCoalitionReport(args[1:])
+	case "loyalty-settle-coalition":
+		if len(args) < 4 {
+			fmt.Println("Usage: loyalty-settle-coalition <coalitionId> <period> <key_file>")
+			return
+		}
+		if code := loyaltySettleCoalition(args[1], args[2], args[3]); code != 0 {
+			os.Exit(code)
+		}
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		printUsage()
@@ -916,6 +994,7 @@ func printUsage() {
 	fmt.Println("  address <key_file>                 - Print the public address for a local key file")
 	fmt.Println("  send-znhb [--rpc <url>] [--gas <limit>] <recipient> <amount> <key_file> - Transfers ZapNHB using the new transaction type")
 	fmt.Println("  deploy <bytecode_file> <key_file>    - Deploys a smart contract")
+	fmt.Println("  call [flags] <contract> [calldata]  - Executes a read-only contract call against latest or historical state")
 	fmt.Println("  id                                 - Identity alias management subcommands")
 	fmt.Println("  escrow                             - Escrow management subcommands")
 	fmt.Println("  claimable                          - Hash-lock claimable subcommands")
@@ -211,13 +211,21 @@ func runP2PDispute(args []string, stdout, stderr io.Writer) int {
 func runP2PResolve(args []string, stdout, stderr io.Writer) int {
 	fs := newP2PFlagSet("p2p resolve", stderr)
 	var (
-		id      string
-		caller  string
-		outcome string
+		id            string
+		caller        string
+		outcome       string
+		basePayeeBps  uint
+		basePayerBps  uint
+		quotePayeeBps uint
+		quotePayerBps uint
 	)
 	fs.StringVar(&id, "id", "", "trade identifier")
 	fs.StringVar(&caller, "caller", "", "arbitrator bech32 address")
 	fs.StringVar(&outcome, "outcome", "", "resolution outcome")
+	fs.UintVar(&basePayeeBps, "base-payee-bps", 0, "split: share of the base leg paid to the buyer")
+	fs.UintVar(&basePayerBps, "base-payer-bps", 0, "split: share of the base leg returned to the seller")
+	fs.UintVar(&quotePayeeBps, "quote-payee-bps", 0, "split: share of the quote leg paid to the seller")
+	fs.UintVar(&quotePayerBps, "quote-payer-bps", 0, "split: share of the quote leg returned to the buyer")
 	if err := fs.Parse(args); err != nil {
 		return 1
 	}
@@ -233,13 +241,22 @@ func runP2PResolve(args []string, stdout, stderr io.Writer) int {
 	}
 	normalizedOutcome := strings.ToLower(strings.TrimSpace(outcome))
 	if _, ok := validResolveOutcomes()[normalizedOutcome]; !ok {
-		return printP2PError(stderr, "--outcome must be one of release_both, refund_both, release_base_refund_quote, release_quote_refund_base")
+		return printP2PError(stderr, "--outcome must be one of release_both, refund_both, release_base_refund_quote, release_quote_refund_base, split")
 	}
 	params := map[string]interface{}{
 		"tradeId": id,
 		"caller":  caller,
 		"outcome": normalizedOutcome,
 	}
+	if normalizedOutcome == "split" {
+		if basePayeeBps+basePayerBps != 10_000 || quotePayeeBps+quotePayerBps != 10_000 {
+			return printP2PError(stderr, "split shares of each leg must total 10000 bps")
+		}
+		params["basePayeeBps"] = basePayeeBps
+		params["basePayerBps"] = basePayerBps
+		params["quotePayeeBps"] = quotePayeeBps
+		params["quotePayerBps"] = quotePayerBps
+	}
 	result, rpcErr, err := p2pRPCCall("p2p_resolve", params, true)
 	if err != nil {
 		return handleRPCCallError(stderr, err)
@@ -323,5 +340,6 @@ func validResolveOutcomes() map[string]struct{} {
 		"refund_both":               {},
 		"release_base_refund_quote": {},
 		"release_quote_refund_base": {},
+		"split":                     {},
 	}
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"nhbchain/cmd/internal/passphrase"
+	"nhbchain/config"
+	"nhbchain/core"
+	"nhbchain/core/genesis"
+	"nhbchain/storage"
+)
+
+// runExportGenesis implements `nhb export-genesis`. It reads the state
+// committed at a block height from a stopped node's data directory and writes
+// it as a genesis spec that BuildGenesisFromSpec can load.
+func runExportGenesis(args []string) int {
+	fs := flag.NewFlagSet("export-genesis", flag.ContinueOnError)
+	configFile := fs.String("config", "./config.toml", "Path to the configuration file")
+	height := fs.Int64("height", -1, "Block height to export (defaults to the chain tip)")
+	out := fs.String("out", "exported-genesis.json", "Path of the genesis file to write")
+	genesisTime := fs.String("genesis-time", "", "genesisTime of the exported spec in RFC3339 (defaults to the exported block's timestamp)")
+	roles := fs.String("roles", "", "Comma-separated role names to export in addition to those in the original genesis file")
+	params := fs.String("params", "", "Comma-separated governance parameter keys to export in addition to Governance.AllowedParams")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if _, err := os.Stat(*configFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: config file: %v\n", err)
+		return 1
+	}
+	cfg, err := config.Load(*configFile, config.WithKeystorePassphraseSource(passphrase.NewSource(validatorPassEnv).Get))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		return 1
+	}
+
+	db, err := storage.NewLevelDB(cfg.DataDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to open database (is another nhb process already using it?): %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	// An empty genesis path makes an empty data directory an error instead
+	// of initialising a new chain.
+	chain, err := core.NewBlockchain(db, "", false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to open chain in %s: %v\n", cfg.DataDir, err)
+		return 1
+	}
+	target := chain.Height()
+	if *height >= 0 {
+		if uint64(*height) > target {
+			fmt.Fprintf(os.Stderr, "Error: height %d is above the chain tip %d\n", *height, target)
+			return 1
+		}
+		target = uint64(*height)
+	}
+	block, err := chain.GetBlockByHeight(target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load block %d: %v\n", target, err)
+		return 1
+	}
+
+	opts := genesis.ExportOptions{
+		GenesisTime: time.Unix(block.Header.Timestamp, 0).UTC(),
+		Roles:       splitList(*roles),
+		Params:      append(append([]string(nil), cfg.Governance.AllowedParams...), splitList(*params)...),
+	}
+	if strings.TrimSpace(*genesisTime) != "" {
+		parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(*genesisTime))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --genesis-time: %v\n", err)
+			return 1
+		}
+		opts.GenesisTime = parsed
+	}
+	// Settings that live only in the genesis file are carried over from the
+	// chain's original genesis.
+	if path := strings.TrimSpace(cfg.GenesisFile); path != "" {
+		original, err := genesis.LoadGenesisSpec(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load original genesis %s: %v\n", path, err)
+			return 1
+		}
+		opts.AdminWallet = original.AdminWallet
+		opts.BuybackSigners = original.BuybackSigners
+		opts.BuybackSignerThreshold = original.BuybackSignerThreshold
+		for role := range original.Roles {
+			opts.Roles = append(opts.Roles, role)
+		}
+	}
+	opts.Roles = dedupeSorted(opts.Roles)
+
+	spec, err := genesis.ExportSpec(db, block.Header.StateRoot, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: export failed: %v\n", err)
+		return 1
+	}
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to encode genesis: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", *out, err)
+		return 1
+	}
+	fmt.Printf("Exported state at height %d (root 0x%x) to %s: %d accounts, %d validators\n",
+		target, block.Header.StateRoot, *out, len(spec.Accounts), len(spec.Validators))
+	return 0
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
+func dedupeSorted(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		out = append(out, value)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -23,6 +23,7 @@ import (
 	"nhbchain/core/genesis"
 	nhbstate "nhbchain/core/state"
 	"nhbchain/crypto"
+	"nhbchain/native/governance"
 	"nhbchain/native/lending"
 	nativeparams "nhbchain/native/params"
 	swap "nhbchain/native/swap"
@@ -44,7 +45,11 @@ const (
 )
 
 func main() {
-	configFile := flag.String("config", "./config.toml", "Path to the configuration file")
+	if len(os.Args) > 1 && os.Args[1] == "export-genesis" {
+		os.Exit(runExportGenesis(os.Args[2:]))
+	}
+
+	configFile :=flag.String("config", "./config.toml", "Path to the configuration file")
 	genesisFlag := flag.String("genesis", "", "Path to a genesis block JSON file (overrides NHB_GENESIS and config GenesisFile)")
 	allowAutogenesisFlag := flag.Bool("allow-autogenesis", false, "DEV ONLY: allow automatic genesis creation when no stored genesis exists")
 	allowMigrateFlag := flag.Bool("allow-migrate", false, "Allow starting with a mismatched state schema (manual migrations only)")
@@ -131,6 +136,16 @@ func main() {
 		os.WriteFile("nhb_startup_err.log", []byte(fmt.Sprintf("%v", err)), 0644)
 		panic(fmt.Sprintf("Failed to create node: %v", err))
 	}
+	if err := applyScheduledUpgrade(node, logger); err != nil {
+		logger.Error("software upgrade failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	accessKey, err := loadOrCreateCreatorAccessKey(filepath.Join(cfg.DataDir, "creator_access_key"))
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load creator access key: %v", err))
+	}
+	node.SetCreatorAccessKey(accessKey)
 
 	if err := node.SetGlobalConfig(cfg.Global); err != nil {
 		panic(fmt.Sprintf("Failed to apply global config: %v", err))
@@ -209,6 +224,8 @@ func main() {
 	node.SetLendingRiskParameters(lending.RiskParameters{
 		MaxLTV:               cfg.Lending.MaxLTVBps,
 		LiquidationThreshold: cfg.Lending.LiquidationThresholdBps,
+		CloseFactorBps:       cfg.Lending.CloseFactorBps,
+		Auction:              cfg.Lending.Auction,
 		DeveloperFeeCapBps:   cfg.Lending.DeveloperFeeBps,
 	})
 
@@ -546,6 +563,35 @@ func main() {
 	select {}
 }
 
+// applyScheduledUpgrade refuses to start a binary that cannot continue past a
+// governance-scheduled upgrade, or that carries the upgrade's migration but
+// does not match the plan checksum. It arranges for the process to exit
+// cleanly when the chain halts for an upgrade this binary cannot apply.
+func applyScheduledUpgrade(node *core.Node, logger *slog.Logger) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate running binary: %w", err)
+	}
+	plan, err := node.CheckPendingUpgrade(exe)
+	if err != nil {
+		return err
+	}
+	if plan != nil {
+		logger.Info("software upgrade scheduled",
+			slog.String("upgrade", plan.Name),
+			slog.Uint64("height", plan.Height),
+			slog.Bool("handler_registered", core.UpgradeHandlerRegistered(plan.Name)))
+	}
+	node.SetUpgradeHaltHandler(func(plan governance.UpgradePlan) {
+		logger.Warn("chain halted for software upgrade; restart with the new binary",
+			slog.String("upgrade", plan.Name),
+			slog.Uint64("height", plan.Height),
+			slog.String("checksum", plan.Checksum))
+		os.Exit(0)
+	})
+	return nil
+}
+
 func startValidatorHeartbeatLoop(node *core.Node, privKey *crypto.PrivateKey, logger *slog.Logger) {
 	if node == nil || privKey == nil {
 		return
@@ -665,15 +711,11 @@ func configureStableTradingEngine(rpcServer *rpc.Server, aggregator *swap.Oracle
 			if quote.Rate == nil || quote.Rate.Sign() <= 0 {
 				continue
 			}
-			rate, _ := quote.Rate.Float64()
-			if rate <= 0 {
-				continue
-			}
 			updated := quote.Timestamp
 			if updated.IsZero() {
 				updated = now
 			}
-			engine.RecordPrice("USD", symbol, rate, updated)
+			engine.RecordPrice("USD", symbol, quote.Rate, updated)
 		}
 	}
 
@@ -852,6 +894,32 @@ func loadValidatorKey(cfg *config.Config, resolvePassphrase func() (string, erro
 	return key, nil
 }
 
+// loadOrCreateCreatorAccessKey returns the key that signs creator access
+// tokens, generating and persisting it on first start so issued tokens keep
+// verifying across restarts.
+func loadOrCreateCreatorAccessKey(path string) (*crypto.PrivateKey, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		raw, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+		return crypto.PrivateKeyFromBytes(raw)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	key, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key.Bytes())), 0o600); err != nil {
+		return nil, fmt.Errorf("persist %s: %w", path, err)
+	}
+	return key, nil
+}
+
 type p2pNetworkAdapter struct {
 	server *p2p.Server
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"math"
 	"math/big"
 	"strings"
@@ -197,6 +198,30 @@ type Quotas struct {
 	POTSO   Quota
 }
 
+// Identity configures optional registration terms for identity aliases. With
+// AliasTermDays left at zero aliases never expire.
+type Identity struct {
+	AliasTermDays      uint64
+	AliasGraceDays     uint64
+	AliasRenewalFeeWei string
+}
+
+// AliasRenewalFee parses AliasRenewalFeeWei, treating an empty value as zero.
+func (i Identity) AliasRenewalFee() (*big.Int, error) {
+	trimmed := strings.TrimSpace(i.AliasRenewalFeeWei)
+	if trimmed == "" {
+		return big.NewInt(0), nil
+	}
+	amount, ok := new(big.Int).SetString(trimmed, 10)
+	if !ok {
+		return nil, fmt.Errorf("alias_renewal_fee_wei must be a base-10 integer")
+	}
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("alias_renewal_fee_wei must be >= 0")
+	}
+	return amount, nil
+}
+
 // Loyalty controls the automatic adjustments applied to the base loyalty reward rate.
 type Loyalty struct {
 	Dynamic LoyaltyDynamic
@@ -262,4 +287,5 @@ type Global struct {
 	Paymaster  Paymaster
 	Fees       Fees
 	Loyalty    Loyalty
+	Identity   Identity
 }
@@ -140,6 +140,12 @@ func ValidateConfig(g Global) error {
 			return fmt.Errorf("loyalty.dynamic.price_guard: fallback_min_emission_znhb_wei must be >= 0")
 		}
 	}
+	if _, err := g.Identity.AliasRenewalFee(); err != nil {
+		return fmt.Errorf("identity: %w", err)
+	}
+	if g.Identity.AliasTermDays == 0 && g.Identity.AliasGraceDays > 0 {
+		return fmt.Errorf("identity: alias_grace_days requires alias_term_days")
+	}
 	znhbEnabled := false
 	for _, asset := range g.Fees.Assets {
 		if strings.EqualFold(strings.TrimSpace(asset.Asset), fees.AssetZNHB) {
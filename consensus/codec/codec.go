@@ -202,6 +202,10 @@ func transactionFromModulePayload(body *consensusv1.TxEnvelope, payload *anypb.A
 		return modulePOSTx(body, payload, types.TxTypePOSCapture)
 	case *posv1.MsgVoidPayment:
 		return modulePOSTx(body, payload, types.TxTypePOSVoid)
+	case *posv1.MsgIncrementAuthorization:
+		return modulePOSTx(body, payload, types.TxTypePOSIncrement)
+	case *posv1.MsgRefundPayment:
+		return modulePOSTx(body, payload, types.TxTypePOSRefund)
 	case *posv1.MsgRegisterMerchant, *posv1.MsgRegisterDevice, *posv1.MsgPauseMerchant, *posv1.MsgResumeMerchant, *posv1.MsgRevokeDevice, *posv1.MsgRestoreDevice:
 		return modulePOSTx(body, payload, types.TxTypePOSRegistry)
 	default:
@@ -41,3 +41,20 @@ func (s *Store) SaveValidators(validators []Validator) error {
 	}
 	return s.db.Put(validatorSetKey, encoded)
 }
+
+// Validators returns the persisted validator list. A database without a
+// stored validator set yields an empty slice.
+func (s *Store) Validators() ([]Validator, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("consensus store uninitialised")
+	}
+	data, err := s.db.Get(validatorSetKey)
+	if err != nil || len(data) == 0 {
+		return []Validator{}, nil
+	}
+	var validators []Validator
+	if err := rlp.DecodeBytes(data, &validators); err != nil {
+		return nil, err
+	}
+	return validators, nil
+}
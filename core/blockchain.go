@@ -40,6 +40,8 @@ var (
 	heightKeyName    = []byte("height")
 	heightPrefix     = []byte("height:")
 	hashPrefix       = []byte("hash:")
+	txPrefix         = []byte("tx:")
+	txIndexHeightKey = []byte("txIndexHeight")
 	lastTimestampKey = []byte("lastTimestamp")
 )
 
@@ -77,6 +79,34 @@ func hashKey(hash []byte) []byte {
 	return key
 }
 
+func txKey(hash []byte) []byte {
+	key := make([]byte, len(txPrefix)+len(hash))
+	copy(key, txPrefix)
+	copy(key[len(txPrefix):], hash)
+	return key
+}
+
+// indexTransactions records the height of every transaction in block under
+// its hash so lookups do not have to walk the chain.
+func indexTransactions(db storage.Database, block *types.Block, height uint64) error {
+	if block == nil {
+		return nil
+	}
+	for _, tx := range block.Transactions {
+		if tx == nil {
+			continue
+		}
+		hash, err := tx.Hash()
+		if err != nil {
+			return fmt.Errorf("hash transaction: %w", err)
+		}
+		if err := db.Put(txKey(hash), encodeUint64(height)); err != nil {
+			return fmt.Errorf("store transaction index: %w", err)
+		}
+	}
+	return db.Put(txIndexHeightKey, encodeUint64(height))
+}
+
 func cloneBytes(b []byte) []byte {
 	if b == nil {
 		return nil
@@ -184,6 +214,10 @@ func NewBlockchain(db storage.Database, genesisPath string, allowAutogenesis boo
 		}
 	}
 
+	if err := bc.backfillTransactionIndex(); err != nil {
+		return nil, err
+	}
+
 	if raw, err := db.Get(lastTimestampKey); err == nil {
 		bc.lastTimestamp = decodeInt64(raw)
 	} else if header := bc.CurrentHeader(); header != nil {
@@ -280,6 +314,9 @@ func (bc *Blockchain) AddBlock(b *types.Block) error {
 	if err := bc.db.Put(hashKey(blockHash), encodeUint64(newHeight)); err != nil {
 		return fmt.Errorf("store hash index: %w", err)
 	}
+	if err := indexTransactions(bc.db, b, newHeight); err != nil {
+		return err
+	}
 	if err := bc.db.Put(lastTimestampKey, encodeInt64(b.Header.Timestamp)); err != nil {
 		return fmt.Errorf("store last timestamp: %w", err)
 	}
@@ -335,6 +372,35 @@ func (bc *Blockchain) GetBlocks(fromHeight uint64) ([]*types.Block, error) {
 	return blocks, nil
 }
 
+// TransactionHeight returns the height of the block that committed the
+// transaction with the provided hash.
+func (bc *Blockchain) TransactionHeight(hash []byte) (uint64, bool) {
+	raw, err := bc.db.Get(txKey(hash))
+	if err != nil || len(raw) != 8 {
+		return 0, false
+	}
+	return decodeUint64(raw), true
+}
+
+// backfillTransactionIndex indexes blocks committed before the transaction
+// index existed. Progress is persisted so the walk only happens once.
+func (bc *Blockchain) backfillTransactionIndex() error {
+	start := uint64(0)
+	if raw, err := bc.db.Get(txIndexHeightKey); err == nil && len(raw) == 8 {
+		start = decodeUint64(raw) + 1
+	}
+	for height := start; height <= bc.height; height++ {
+		block, err := bc.GetBlockByHeight(height)
+		if err != nil {
+			return fmt.Errorf("load block %d for transaction index: %w", height, err)
+		}
+		if err := indexTransactions(bc.db, block, height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (bc *Blockchain) GetHeight() uint64 {
 	bc.mu.RLock()
 	defer bc.mu.RUnlock()
@@ -573,6 +639,9 @@ func persistGenesisBlock(db storage.Database, genesis *types.Block) ([]byte, err
 	if err := db.Put(hashKey(genesisHash), encodeUint64(0)); err != nil {
 		return nil, fmt.Errorf("store hash index: %w", err)
 	}
+	if err := indexTransactions(db, genesis, 0); err != nil {
+		return nil, err
+	}
 	if err := db.Put(lastTimestampKey, encodeInt64(genesis.Header.Timestamp)); err != nil {
 		return nil, fmt.Errorf("store genesis timestamp: %w", err)
 	}
@@ -207,6 +207,39 @@ func TestBlockchainPersistenceAcrossRestart(t *testing.T) {
 
 }
 
+func TestBlockchainIndexesTransactionsByHash(t *testing.T) {
+	bc, err := NewBlockchain(storage.NewMemDB(), "", true)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	key, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tx := &types.Transaction{ChainID: types.NHBChainID(), Type: types.TxTypeTransfer, To: make([]byte, 20), GasLimit: 21_000}
+	if err := tx.Sign(key.PrivateKey); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	block := newTestBlock(1, bc.Tip())
+	block.Transactions = []*types.Transaction{tx}
+	if block.Header.TxRoot, err = ComputeTxRoot(block.Transactions); err != nil {
+		t.Fatalf("tx root: %v", err)
+	}
+	if err := bc.AddBlock(block); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	hash, err := tx.Hash()
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if height, ok := bc.TransactionHeight(hash); !ok || height != 1 {
+		t.Fatalf("expected transaction at height 1, got %d (ok=%v)", height, ok)
+	}
+	if _, ok := bc.TransactionHeight(make([]byte, 32)); ok {
+		t.Fatalf("expected unknown hash to miss the index")
+	}
+}
+
 func TestTipReturnsCopy(t *testing.T) {
 	db := storage.NewMemDB()
 	t.Cleanup(func() { db.Close() })
@@ -16,6 +16,7 @@ type Config struct {
 	LambdaDenominator      uint64        // Denominator for EMA decay factor
 	HeartbeatInterval      time.Duration // Minimum interval between heartbeats
 	MaxMinutesPerHeartbeat uint64        // Clamp for minutes accrued per heartbeat
+	MaxDevicesPerAccount   uint64        // Active heartbeat devices allowed per address (0 disables the limit)
 }
 
 // DefaultConfig returns a conservative engagement configuration suitable for
@@ -31,6 +32,7 @@ func DefaultConfig() Config {
 		LambdaDenominator:      5,
 		HeartbeatInterval:      time.Minute,
 		MaxMinutesPerHeartbeat: 5,
+		MaxDevicesPerAccount:   8,
 	}
 }
 
@@ -2,43 +2,82 @@ package engagement
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
+
+	"nhbchain/core/state"
 )
 
-// Manager tracks registered devices and enforces basic heartbeat semantics
-// (rate limits and replay protection) before heartbeats are materialised on
-// chain.
+// ErrDeviceLimitReached is returned when registering a new device would take
+// an account past Config.MaxDevicesPerAccount.
+var ErrDeviceLimitReached = errors.New("device limit reached")
+
+// ErrUnknownDevice is returned for device identifiers that are not registered
+// or have been revoked.
+var ErrUnknownDevice = errors.New("unknown device")
+
+var errNoRegistry = errors.New("device registry unavailable")
+
+// DeviceRegistry reads the heartbeat devices registered on chain.
+type DeviceRegistry interface {
+	EngagementDeviceGet(deviceID string) (*state.EngagementDevice, bool, error)
+	EngagementDevicesByOwner(owner [20]byte) ([]*state.EngagementDevice, error)
+}
+
+// Manager issues device tokens and enforces basic heartbeat semantics (rate
+// limits and replay protection) before heartbeats are materialised on chain.
+// Registrations themselves live in chain state: the node submits them as
+// TxTypeRegisterDevice transactions, so every validator applies the same
+// device limit, and the manager checks tokens against the registered digest.
 type Manager struct {
-	mu      sync.Mutex
-	config  Config
-	now     func() time.Time
-	devices map[string]*deviceState
+	mu        sync.Mutex
+	config    Config
+	now       func() time.Time
+	registry  DeviceRegistry
+	lastStamp map[string]int64
 }
 
-type deviceState struct {
-	id        string
-	token     string
-	address   [20]byte
-	lastStamp int64
+// Device describes a registered heartbeat device. The bearer token is never
+// exposed after registration.
+type Device struct {
+	ID            string
+	Owner         [20]byte
+	RegisteredAt  int64
+	LastHeartbeat int64
 }
 
-// NewManager constructs a manager with the provided configuration.
+// NewManager constructs a manager with the provided configuration and no
+// device registry. It can report the heartbeat interval but rejects device
+// operations.
 func NewManager(cfg Config) *Manager {
+	return NewManagerWithRegistry(cfg, nil)
+}
+
+// NewManagerWithRegistry constructs a manager that checks devices against
+// registry.
+func NewManagerWithRegistry(cfg Config, registry DeviceRegistry) *Manager {
 	if err := cfg.Validate(); err != nil {
 		panic(err)
 	}
 	return &Manager{
-		config:  cfg,
-		now:     time.Now,
-		devices: make(map[string]*deviceState),
+		config:    cfg,
+		now:       time.Now,
+		registry:  registry,
+		lastStamp: make(map[string]int64),
 	}
 }
 
+// TokenHash returns the digest of a device token that is stored on chain.
+func TokenHash(token string) [32]byte {
+	return sha256.Sum256([]byte(token))
+}
+
 // SetNow overrides the time source. It is intended for tests.
 func (m *Manager) SetNow(now func() time.Time) {
 	if now == nil {
@@ -64,33 +103,94 @@ func (m *Manager) HeartbeatInterval() time.Duration {
 	return m.config.HeartbeatInterval
 }
 
-// RegisterDevice associates a device identifier with a validator address and
-// returns a freshly generated authentication token for subsequent heartbeats.
+// RegisterDevice checks that address may register deviceID and returns a
+// freshly generated authentication token for subsequent heartbeats. The
+// caller submits TokenHash(token) in a TxTypeRegisterDevice transaction; the
+// device is usable once that transaction is included. Re-registering a device
+// the address already owns rotates its token. A device that belongs to
+// another address must be revoked by its owner first.
 func (m *Manager) RegisterDevice(address [20]byte, deviceID string) (string, error) {
 	deviceID = strings.TrimSpace(deviceID)
 	if deviceID == "" {
 		return "", fmt.Errorf("device id required")
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.registry == nil {
+		return "", errNoRegistry
+	}
+	record, ok, err := m.registry.EngagementDeviceGet(deviceID)
+	if err != nil {
+		return "", err
+	}
+	if ok && record.Owner != address {
+		return "", fmt.Errorf("device registered to another address")
+	}
+	if !ok {
+		owned, err := m.registry.EngagementDevicesByOwner(address)
+		if err != nil {
+			return "", err
+		}
+		if limit := m.config.MaxDevicesPerAccount; limit > 0 && uint64(len(owned)) >= limit {
+			return "", fmt.Errorf("%w: %d devices", ErrDeviceLimitReached, limit)
+		}
+	}
+
 	tokenBytes := make([]byte, 16)
 	if _, err := rand.Read(tokenBytes); err != nil {
 		return "", fmt.Errorf("token generation failed: %w", err)
 	}
-	token := hex.EncodeToString(tokenBytes)
+	return hex.EncodeToString(tokenBytes), nil
+}
 
+// ListDevices returns the devices registered to owner in registration order.
+func (m *Manager) ListDevices(owner [20]byte) ([]Device, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	state, ok := m.devices[deviceID]
-	if !ok {
-		state = &deviceState{id: deviceID}
-		m.devices[deviceID] = state
+	if m.registry == nil {
+		return nil, errNoRegistry
+	}
+	records, err := m.registry.EngagementDevicesByOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]Device, 0, len(records))
+	for _, record := range records {
+		devices = append(devices, Device{
+			ID:            record.DeviceID,
+			Owner:         record.Owner,
+			RegisteredAt:  int64(record.RegisteredAt),
+			LastHeartbeat: int64(record.LastHeartbeat),
+		})
+	}
+	return devices, nil
+}
+
+// CheckRevocation reports whether owner may revoke deviceID. The caller
+// submits the revocation as a TxTypeRevokeDevice transaction.
+func (m *Manager) CheckRevocation(owner [20]byte, deviceID string) error {
+	deviceID = strings.TrimSpace(deviceID)
+	if deviceID == "" {
+		return fmt.Errorf("device id required")
 	}
-	state.token = token
-	state.address = address
-	state.lastStamp = 0
 
-	return token, nil
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.registry == nil {
+		return errNoRegistry
+	}
+	record, ok, err := m.registry.EngagementDeviceGet(deviceID)
+	if err != nil {
+		return err
+	}
+	if !ok || record.Owner != owner {
+		return ErrUnknownDevice
+	}
+	return nil
 }
 
 // SubmitHeartbeat validates the provided credentials and timestamp against the
@@ -109,11 +209,18 @@ func (m *Manager) SubmitHeartbeat(deviceID, token string, timestamp int64) (int6
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	state, ok := m.devices[deviceID]
+	if m.registry == nil {
+		return 0, errNoRegistry
+	}
+	record, ok, err := m.registry.EngagementDeviceGet(deviceID)
+	if err != nil {
+		return 0, err
+	}
 	if !ok {
-		return 0, fmt.Errorf("unknown device")
+		return 0, ErrUnknownDevice
 	}
-	if subtle.ConstantTimeCompare([]byte(state.token), []byte(token)) == 0 {
+	tokenHash := TokenHash(token)
+	if subtle.ConstantTimeCompare(record.TokenHash[:], tokenHash[:]) == 0 {
 		return 0, fmt.Errorf("invalid token")
 	}
 
@@ -123,16 +230,22 @@ func (m *Manager) SubmitHeartbeat(deviceID, token string, timestamp int64) (int6
 	if timestamp <= 0 {
 		return 0, fmt.Errorf("invalid timestamp")
 	}
-	if state.lastStamp != 0 {
-		if timestamp <= state.lastStamp {
+	// Heartbeats accepted here may still be waiting in the mempool, so the
+	// last stamp is the later of the on-chain one and the last one accepted.
+	last := int64(record.LastHeartbeat)
+	if pending := m.lastStamp[deviceID]; pending > last {
+		last = pending
+	}
+	if last != 0 {
+		if timestamp <= last {
 			return 0, fmt.Errorf("heartbeat replay")
 		}
 		minDelta := int64(m.config.HeartbeatInterval.Seconds())
-		if timestamp-state.lastStamp < minDelta {
+		if timestamp-last < minDelta {
 			return 0, fmt.Errorf("heartbeat rate limited")
 		}
 	}
 
-	state.lastStamp = timestamp
+	m.lastStamp[deviceID] = timestamp
 	return timestamp, nil
 }
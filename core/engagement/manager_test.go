@@ -1,21 +1,55 @@
 package engagement
 
 import (
+	"errors"
 	"testing"
 	"time"
+
+	"nhbchain/core/state"
+	"nhbchain/storage"
+	statetrie "nhbchain/storage/trie"
 )
 
+func newTestRegistry(t *testing.T) *state.Manager {
+	t.Helper()
+	db := storage.NewMemDB()
+	t.Cleanup(db.Close)
+	tr, err := statetrie.NewTrie(db, nil)
+	if err != nil {
+		t.Fatalf("create trie: %v", err)
+	}
+	return state.NewManager(tr)
+}
+
+// registerDevice issues a token and records the registration the way an
+// included TxTypeRegisterDevice transaction would.
+func registerDevice(t *testing.T, mgr *Manager, registry *state.Manager, owner [20]byte, deviceID string, registeredAt uint64) string {
+	t.Helper()
+	token, err := mgr.RegisterDevice(owner, deviceID)
+	if err != nil {
+		t.Fatalf("register %s: %v", deviceID, err)
+	}
+	device := &state.EngagementDevice{DeviceID: deviceID, Owner: owner, TokenHash: TokenHash(token), RegisteredAt: registeredAt}
+	if existing, ok, err := registry.EngagementDeviceGet(deviceID); err != nil {
+		t.Fatalf("load %s: %v", deviceID, err)
+	} else if ok {
+		device.RegisteredAt = existing.RegisteredAt
+	}
+	if err := registry.EngagementDevicePut(device, 0); err != nil {
+		t.Fatalf("store %s: %v", deviceID, err)
+	}
+	return token
+}
+
 func TestManagerRateLimit(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.HeartbeatInterval = time.Minute
-	mgr := NewManager(cfg)
+	registry := newTestRegistry(t)
+	mgr := NewManagerWithRegistry(cfg, registry)
 	mgr.SetNow(func() time.Time { return time.Unix(1000, 0).UTC() })
 
 	var addr [20]byte
-	token, err := mgr.RegisterDevice(addr, "device-1")
-	if err != nil {
-		t.Fatalf("register device: %v", err)
-	}
+	token := registerDevice(t, mgr, registry, addr, "device-1", 1000)
 
 	ts, err := mgr.SubmitHeartbeat("device-1", token, 0)
 	if err != nil {
@@ -43,14 +77,12 @@ func TestManagerHeartbeatInterval(t *testing.T) {
 
 func TestManagerReplay(t *testing.T) {
 	cfg := DefaultConfig()
-	mgr := NewManager(cfg)
+	registry := newTestRegistry(t)
+	mgr := NewManagerWithRegistry(cfg, registry)
 	mgr.SetNow(func() time.Time { return time.Unix(2000, 0).UTC() })
 
 	var addr [20]byte
-	token, err := mgr.RegisterDevice(addr, "device-2")
-	if err != nil {
-		t.Fatalf("register device: %v", err)
-	}
+	token := registerDevice(t, mgr, registry, addr, "device-2", 2000)
 
 	ts, err := mgr.SubmitHeartbeat("device-2", token, 0)
 	if err != nil {
@@ -60,3 +92,76 @@ func TestManagerReplay(t *testing.T) {
 		t.Fatalf("expected replay detection")
 	}
 }
+
+func TestManagerChecksOnChainHeartbeatAfterRestart(t *testing.T) {
+	registry := newTestRegistry(t)
+	mgr := NewManagerWithRegistry(DefaultConfig(), registry)
+	mgr.SetNow(func() time.Time { return time.Unix(3000, 0).UTC() })
+
+	var addr [20]byte
+	addr[0] = 0x01
+	if _, err := mgr.SubmitHeartbeat("device-3", "token", 0); !errors.Is(err, ErrUnknownDevice) {
+		t.Fatalf("expected unregistered device to be rejected, got %v", err)
+	}
+	token := registerDevice(t, mgr, registry, addr, "device-3", 3000)
+	device, _, err := registry.EngagementDeviceGet("device-3")
+	if err != nil {
+		t.Fatalf("load device: %v", err)
+	}
+	device.LastHeartbeat = 3000
+	if err := registry.EngagementDevicePut(device, 0); err != nil {
+		t.Fatalf("stamp device: %v", err)
+	}
+
+	restarted := NewManagerWithRegistry(DefaultConfig(), registry)
+	if _, err := restarted.SubmitHeartbeat("device-3", token, 3000); err == nil {
+		t.Fatalf("expected replay detection against the on-chain heartbeat")
+	}
+	if _, err := restarted.SubmitHeartbeat("device-3", "wrong", 3060); err == nil {
+		t.Fatalf("expected invalid token error")
+	}
+	if _, err := restarted.SubmitHeartbeat("device-3", token, 3060); err != nil {
+		t.Fatalf("heartbeat after restart failed: %v", err)
+	}
+	devices, err := restarted.ListDevices(addr)
+	if err != nil {
+		t.Fatalf("list devices: %v", err)
+	}
+	if len(devices) != 1 || devices[0].ID != "device-3" || devices[0].RegisteredAt != 3000 || devices[0].LastHeartbeat != 3000 {
+		t.Fatalf("unexpected devices: %+v", devices)
+	}
+}
+
+func TestManagerDeviceLimitAndRevocation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxDevicesPerAccount = 2
+	registry := newTestRegistry(t)
+	mgr := NewManagerWithRegistry(cfg, registry)
+
+	var owner, other [20]byte
+	owner[0], other[0] = 0x01, 0x02
+	registerDevice(t, mgr, registry, owner, "device-a", 1)
+	registerDevice(t, mgr, registry, owner, "device-b", 2)
+	if _, err := mgr.RegisterDevice(owner, "device-c"); !errors.Is(err, ErrDeviceLimitReached) {
+		t.Fatalf("expected device limit error, got %v", err)
+	}
+	if _, err := mgr.RegisterDevice(owner, "device-a"); err != nil {
+		t.Fatalf("re-registering an owned device should rotate its token: %v", err)
+	}
+	if _, err := mgr.RegisterDevice(other, "device-a"); err == nil {
+		t.Fatalf("expected error registering another owner's device")
+	}
+
+	if err := mgr.CheckRevocation(other, "device-b"); !errors.Is(err, ErrUnknownDevice) {
+		t.Fatalf("expected unknown device revoking another owner's device, got %v", err)
+	}
+	if err := mgr.CheckRevocation(owner, "device-a"); err != nil {
+		t.Fatalf("check revocation of device-a: %v", err)
+	}
+	if err := registry.EngagementDeviceDelete("device-a"); err != nil {
+		t.Fatalf("delete device-a: %v", err)
+	}
+	if _, err := mgr.RegisterDevice(owner, "device-c"); err != nil {
+		t.Fatalf("revocation should free a device slot: %v", err)
+	}
+}
@@ -136,6 +136,9 @@ func (sp *StateProcessor) ProcessBlockLifecycle(height uint64, timestamp int64)
 	if err := sp.CheckZNHBSupplyInvariant(); err != nil {
 		return err
 	}
+	if err := sp.CheckAssetSupplyInvariant(); err != nil {
+		return err
+	}
 	if sp.epochConfig.Length == 0 {
 		return nil
 	}
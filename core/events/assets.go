@@ -0,0 +1,143 @@
+package events
+
+import (
+	"math/big"
+	"strconv"
+
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+const (
+	// TypeAssetCreated is emitted when an issuer registers a new asset.
+	TypeAssetCreated = "asset.created"
+	// TypeAssetMinted is emitted when the mint authority issues new units.
+	TypeAssetMinted = "asset.minted"
+	// TypeAssetBurned is emitted when a holder destroys units of an asset.
+	TypeAssetBurned = "asset.burned"
+	// TypeAssetFreeze is emitted when the freeze authority freezes or
+	// unfreezes an account.
+	TypeAssetFreeze = "asset.freeze"
+	// TypeAssetTransferred is emitted for TxTypeTransferAsset transfers.
+	TypeAssetTransferred = "asset.transferred"
+)
+
+// AssetCreated describes a newly registered asset.
+type AssetCreated struct {
+	Symbol          string
+	Name            string
+	Decimals        uint8
+	Issuer          [20]byte
+	MintAuthority   [20]byte
+	FreezeAuthority [20]byte
+	SupplyCap       *big.Int
+}
+
+// EventType satisfies the events.Event interface.
+func (AssetCreated) EventType() string { return TypeAssetCreated }
+
+// Event converts the payload into a broadcastable event.
+func (e AssetCreated) Event() *types.Event {
+	attrs := map[string]string{
+		"symbol":        e.Symbol,
+		"name":          e.Name,
+		"decimals":      strconv.Itoa(int(e.Decimals)),
+		"issuer":        crypto.MustNewAddress(crypto.NHBPrefix, e.Issuer[:]).String(),
+		"mintAuthority": crypto.MustNewAddress(crypto.NHBPrefix, e.MintAuthority[:]).String(),
+		"supplyCap":     formatAmount(e.SupplyCap),
+	}
+	if e.FreezeAuthority != ([20]byte{}) {
+		attrs["freezeAuthority"] = crypto.MustNewAddress(crypto.NHBPrefix, e.FreezeAuthority[:]).String()
+	}
+	return &types.Event{Type: TypeAssetCreated, Attributes: attrs}
+}
+
+// AssetMinted reports units issued to an account.
+type AssetMinted struct {
+	Symbol string
+	To     [20]byte
+	Amount *big.Int
+}
+
+// EventType satisfies the events.Event interface.
+func (AssetMinted) EventType() string { return TypeAssetMinted }
+
+// Event converts the payload into a broadcastable event.
+func (e AssetMinted) Event() *types.Event {
+	return &types.Event{
+		Type: TypeAssetMinted,
+		Attributes: map[string]string{
+			"symbol": e.Symbol,
+			"to":     crypto.MustNewAddress(crypto.NHBPrefix, e.To[:]).String(),
+			"amount": formatAmount(e.Amount),
+		},
+	}
+}
+
+// AssetBurned reports units destroyed by their holder.
+type AssetBurned struct {
+	Symbol string
+	From   [20]byte
+	Amount *big.Int
+}
+
+// EventType satisfies the events.Event interface.
+func (AssetBurned) EventType() string { return TypeAssetBurned }
+
+// Event converts the payload into a broadcastable event.
+func (e AssetBurned) Event() *types.Event {
+	return &types.Event{
+		Type: TypeAssetBurned,
+		Attributes: map[string]string{
+			"symbol": e.Symbol,
+			"from":   crypto.MustNewAddress(crypto.NHBPrefix, e.From[:]).String(),
+			"amount": formatAmount(e.Amount),
+		},
+	}
+}
+
+// AssetFreeze reports a change to an account's frozen flag.
+type AssetFreeze struct {
+	Symbol  string
+	Account [20]byte
+	Frozen  bool
+}
+
+// EventType satisfies the events.Event interface.
+func (AssetFreeze) EventType() string { return TypeAssetFreeze }
+
+// Event converts the payload into a broadcastable event.
+func (e AssetFreeze) Event() *types.Event {
+	return &types.Event{
+		Type: TypeAssetFreeze,
+		Attributes: map[string]string{
+			"symbol":  e.Symbol,
+			"account": crypto.MustNewAddress(crypto.NHBPrefix, e.Account[:]).String(),
+			"frozen":  strconv.FormatBool(e.Frozen),
+		},
+	}
+}
+
+// AssetTransferred reports a TxTypeTransferAsset transfer.
+type AssetTransferred struct {
+	Symbol string
+	From   [20]byte
+	To     [20]byte
+	Amount *big.Int
+}
+
+// EventType satisfies the events.Event interface.
+func (AssetTransferred) EventType() string { return TypeAssetTransferred }
+
+// Event converts the payload into a broadcastable event.
+func (e AssetTransferred) Event() *types.Event {
+	return &types.Event{
+		Type: TypeAssetTransferred,
+		Attributes: map[string]string{
+			"symbol": e.Symbol,
+			"from":   crypto.MustNewAddress(crypto.NHBPrefix, e.From[:]).String(),
+			"to":     crypto.MustNewAddress(crypto.NHBPrefix, e.To[:]).String(),
+			"amount": formatAmount(e.Amount),
+		},
+	}
+}
@@ -10,6 +10,11 @@ import (
 const (
 	TypeEngagementHeartbeat    = "engagement.heartbeat"
 	TypeEngagementScoreUpdated = "engagement.score_updated"
+	// TypeEngagementDeviceRegistered is emitted when a heartbeat device is
+	// registered or its token rotated.
+	TypeEngagementDeviceRegistered = "engagement.device_registered"
+	// TypeEngagementDeviceRevoked is emitted when an owner revokes a device.
+	TypeEngagementDeviceRevoked = "engagement.device_revoked"
 )
 
 // EngagementHeartbeat is emitted for each processed heartbeat transaction.
@@ -61,3 +66,43 @@ func (e EngagementScoreUpdated) Event() *types.Event {
 		},
 	}
 }
+
+// EngagementDeviceRegistered reports a device registration or token rotation.
+type EngagementDeviceRegistered struct {
+	Address  [20]byte
+	DeviceID string
+}
+
+// EventType implements the Event interface.
+func (EngagementDeviceRegistered) EventType() string { return TypeEngagementDeviceRegistered }
+
+// Event converts the registration to the generic representation.
+func (e EngagementDeviceRegistered) Event() *types.Event {
+	return &types.Event{
+		Type: TypeEngagementDeviceRegistered,
+		Attributes: map[string]string{
+			"address":   crypto.MustNewAddress(crypto.NHBPrefix, e.Address[:]).String(),
+			"device_id": e.DeviceID,
+		},
+	}
+}
+
+// EngagementDeviceRevoked reports a device revoked by its owner.
+type EngagementDeviceRevoked struct {
+	Address  [20]byte
+	DeviceID string
+}
+
+// EventType implements the Event interface.
+func (EngagementDeviceRevoked) EventType() string { return TypeEngagementDeviceRevoked }
+
+// Event converts the revocation to the generic representation.
+func (e EngagementDeviceRevoked) Event() *types.Event {
+	return &types.Event{
+		Type: TypeEngagementDeviceRevoked,
+		Attributes: map[string]string{
+			"address":   crypto.MustNewAddress(crypto.NHBPrefix, e.Address[:]).String(),
+			"device_id": e.DeviceID,
+		},
+	}
+}
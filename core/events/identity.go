@@ -1,6 +1,10 @@
 package events
 
 import (
+	"encoding/hex"
+	"math/big"
+	"strconv"
+
 	"nhbchain/core/types"
 	"nhbchain/crypto"
 )
@@ -12,6 +16,15 @@ const (
 	TypeIdentityAliasAddressLinked  = "identity.alias.addressLinked"
 	TypeIdentityAliasAddressRemoved = "identity.alias.addressRemoved"
 	TypeIdentityAliasPrimaryUpdated = "identity.alias.primaryUpdated"
+	TypeIdentityAliasRenewed        = "identity.alias.renewed"
+	TypeIdentityAliasListed         = "identity.alias.listed"
+	TypeIdentityAliasUnlisted       = "identity.alias.unlisted"
+	TypeIdentityAliasSold           = "identity.alias.sold"
+	TypeIdentityRecordSet           = "identity.record.set"
+	TypeIdentityRecordDeleted       = "identity.record.deleted"
+	TypeIdentitySubAliasCreated     = "identity.subalias.created"
+	TypeIdentitySubAliasReassigned  = "identity.subalias.reassigned"
+	TypeIdentitySubAliasRevoked     = "identity.subalias.revoked"
 )
 
 // IdentityAliasSet is emitted when an address registers an alias for the first time.
@@ -137,3 +150,228 @@ func (e IdentityAliasPrimaryUpdated) Event() *types.Event {
 		},
 	}
 }
+
+// IdentityAliasRenewed is emitted when an alias owner extends its registration term.
+type IdentityAliasRenewed struct {
+	Alias     string
+	Owner     [20]byte
+	ExpiresAt int64
+	Fee       *big.Int
+}
+
+// EventType implements the Event interface.
+func (IdentityAliasRenewed) EventType() string { return TypeIdentityAliasRenewed }
+
+// Event converts the strongly typed event to the generic representation used by subscribers.
+func (e IdentityAliasRenewed) Event() *types.Event {
+	fee := "0"
+	if e.Fee != nil {
+		fee = e.Fee.String()
+	}
+	return &types.Event{
+		Type: TypeIdentityAliasRenewed,
+		Attributes: map[string]string{
+			"alias":     e.Alias,
+			"owner":     crypto.MustNewAddress(crypto.NHBPrefix, e.Owner[:]).String(),
+			"expiresAt": strconv.FormatInt(e.ExpiresAt, 10),
+			"fee":       fee,
+		},
+	}
+}
+
+// IdentityAliasListed is emitted when an owner lists an alias for sale or transfer.
+type IdentityAliasListed struct {
+	Alias  string
+	Seller [20]byte
+	Buyer  [20]byte
+	Price  *big.Int
+}
+
+// EventType implements the Event interface.
+func (IdentityAliasListed) EventType() string { return TypeIdentityAliasListed }
+
+// Event converts the strongly typed event to the generic representation used by subscribers.
+func (e IdentityAliasListed) Event() *types.Event {
+	price := "0"
+	if e.Price != nil {
+		price = e.Price.String()
+	}
+	attrs := map[string]string{
+		"alias":  e.Alias,
+		"seller": crypto.MustNewAddress(crypto.NHBPrefix, e.Seller[:]).String(),
+		"price":  price,
+	}
+	if e.Buyer != ([20]byte{}) {
+		attrs["buyer"] = crypto.MustNewAddress(crypto.NHBPrefix, e.Buyer[:]).String()
+	}
+	return &types.Event{Type: TypeIdentityAliasListed, Attributes: attrs}
+}
+
+// IdentityAliasUnlisted is emitted when an owner withdraws an alias listing.
+type IdentityAliasUnlisted struct {
+	Alias  string
+	Seller [20]byte
+}
+
+// EventType implements the Event interface.
+func (IdentityAliasUnlisted) EventType() string { return TypeIdentityAliasUnlisted }
+
+// Event converts the strongly typed event to the generic representation used by subscribers.
+func (e IdentityAliasUnlisted) Event() *types.Event {
+	return &types.Event{
+		Type: TypeIdentityAliasUnlisted,
+		Attributes: map[string]string{
+			"alias":  e.Alias,
+			"seller": crypto.MustNewAddress(crypto.NHBPrefix, e.Seller[:]).String(),
+		},
+	}
+}
+
+// IdentityAliasSold is emitted when a listed alias changes hands. EscrowID is
+// zero for unpriced two-party transfers.
+type IdentityAliasSold struct {
+	Alias    string
+	Seller   [20]byte
+	Buyer    [20]byte
+	Price    *big.Int
+	EscrowID [32]byte
+}
+
+// EventType implements the Event interface.
+func (IdentityAliasSold) EventType() string { return TypeIdentityAliasSold }
+
+// Event converts the strongly typed event to the generic representation used by subscribers.
+func (e IdentityAliasSold) Event() *types.Event {
+	price := "0"
+	if e.Price != nil {
+		price = e.Price.String()
+	}
+	attrs := map[string]string{
+		"alias":  e.Alias,
+		"seller": crypto.MustNewAddress(crypto.NHBPrefix, e.Seller[:]).String(),
+		"buyer":  crypto.MustNewAddress(crypto.NHBPrefix, e.Buyer[:]).String(),
+		"price":  price,
+	}
+	if e.EscrowID != ([32]byte{}) {
+		attrs["escrowId"] = "0x" + hex.EncodeToString(e.EscrowID[:])
+	}
+	return &types.Event{Type: TypeIdentityAliasSold, Attributes: attrs}
+}
+
+// IdentityRecordSet is emitted for each text record an owner adds or changes.
+type IdentityRecordSet struct {
+	Alias string
+	Owner [20]byte
+	Key   string
+	Value string
+}
+
+// EventType implements the Event interface.
+func (IdentityRecordSet) EventType() string { return TypeIdentityRecordSet }
+
+// Event converts the strongly typed event to the generic representation used by subscribers.
+func (e IdentityRecordSet) Event() *types.Event {
+	return &types.Event{
+		Type: TypeIdentityRecordSet,
+		Attributes: map[string]string{
+			"alias": e.Alias,
+			"owner": crypto.MustNewAddress(crypto.NHBPrefix, e.Owner[:]).String(),
+			"key":   e.Key,
+			"value": e.Value,
+		},
+	}
+}
+
+// IdentityRecordDeleted is emitted for each text record an owner removes.
+type IdentityRecordDeleted struct {
+	Alias string
+	Owner [20]byte
+	Key   string
+}
+
+// EventType implements the Event interface.
+func (IdentityRecordDeleted) EventType() string { return TypeIdentityRecordDeleted }
+
+// Event converts the strongly typed event to the generic representation used by subscribers.
+func (e IdentityRecordDeleted) Event() *types.Event {
+	return &types.Event{
+		Type: TypeIdentityRecordDeleted,
+		Attributes: map[string]string{
+			"alias": e.Alias,
+			"owner": crypto.MustNewAddress(crypto.NHBPrefix, e.Owner[:]).String(),
+			"key":   e.Key,
+		},
+	}
+}
+
+// IdentitySubAliasCreated is emitted when a parent owner delegates a sub-alias.
+type IdentitySubAliasCreated struct {
+	Alias  string
+	Parent string
+	Owner  [20]byte
+	Target [20]byte
+}
+
+// EventType implements the Event interface.
+func (IdentitySubAliasCreated) EventType() string { return TypeIdentitySubAliasCreated }
+
+// Event converts the strongly typed event to the generic representation used by subscribers.
+func (e IdentitySubAliasCreated) Event() *types.Event {
+	return &types.Event{
+		Type: TypeIdentitySubAliasCreated,
+		Attributes: map[string]string{
+			"alias":  e.Alias,
+			"parent": e.Parent,
+			"owner":  crypto.MustNewAddress(crypto.NHBPrefix, e.Owner[:]).String(),
+			"target": crypto.MustNewAddress(crypto.NHBPrefix, e.Target[:]).String(),
+		},
+	}
+}
+
+// IdentitySubAliasReassigned is emitted when a sub-alias is pointed at a new address.
+type IdentitySubAliasReassigned struct {
+	Alias          string
+	Parent         string
+	Owner          [20]byte
+	PreviousTarget [20]byte
+	Target         [20]byte
+}
+
+// EventType implements the Event interface.
+func (IdentitySubAliasReassigned) EventType() string { return TypeIdentitySubAliasReassigned }
+
+// Event converts the strongly typed event to the generic representation used by subscribers.
+func (e IdentitySubAliasReassigned) Event() *types.Event {
+	return &types.Event{
+		Type: TypeIdentitySubAliasReassigned,
+		Attributes: map[string]string{
+			"alias":          e.Alias,
+			"parent":         e.Parent,
+			"owner":          crypto.MustNewAddress(crypto.NHBPrefix, e.Owner[:]).String(),
+			"previousTarget": crypto.MustNewAddress(crypto.NHBPrefix, e.PreviousTarget[:]).String(),
+			"target":         crypto.MustNewAddress(crypto.NHBPrefix, e.Target[:]).String(),
+		},
+	}
+}
+
+// IdentitySubAliasRevoked is emitted when a parent owner removes a sub-alias.
+type IdentitySubAliasRevoked struct {
+	Alias  string
+	Parent string
+	Owner  [20]byte
+}
+
+// EventType implements the Event interface.
+func (IdentitySubAliasRevoked) EventType() string { return TypeIdentitySubAliasRevoked }
+
+// Event converts the strongly typed event to the generic representation used by subscribers.
+func (e IdentitySubAliasRevoked) Event() *types.Event {
+	return &types.Event{
+		Type: TypeIdentitySubAliasRevoked,
+		Attributes: map[string]string{
+			"alias":  e.Alias,
+			"parent": e.Parent,
+			"owner":  crypto.MustNewAddress(crypto.NHBPrefix, e.Owner[:]).String(),
+		},
+	}
+}
@@ -0,0 +1,143 @@
+package events
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strconv"
+
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+const (
+	// TypeInvoiceCreated is emitted when a merchant opens a native invoice.
+	TypeInvoiceCreated = "invoice.created"
+	// TypeInvoicePartiallyPaid is emitted when a payment settles part of an
+	// invoice that accepts partial payments.
+	TypeInvoicePartiallyPaid = "invoice.partially_paid"
+	// TypeInvoicePaid is emitted once an invoice is settled in full.
+	TypeInvoicePaid = "invoice.paid"
+	// TypeInvoiceRefunded is emitted when part or all of a payment is
+	// returned to the payer under the invoice refund rule.
+	TypeInvoiceRefunded = "invoice.refunded"
+	// TypeInvoiceCancelled is emitted when a merchant withdraws an invoice.
+	TypeInvoiceCancelled = "invoice.cancelled"
+)
+
+// InvoiceCreated describes a newly opened merchant invoice.
+type InvoiceCreated struct {
+	InvoiceID    [32]byte
+	Merchant     [20]byte
+	Asset        string
+	Amount       *big.Int
+	MemoHash     [32]byte
+	AllowPartial bool
+	Expiry       uint64
+}
+
+// EventType satisfies the events.Event interface.
+func (InvoiceCreated) EventType() string { return TypeInvoiceCreated }
+
+// Event converts the payload into a broadcastable event.
+func (e InvoiceCreated) Event() *types.Event {
+	attrs := map[string]string{
+		"invoiceId":    hex.EncodeToString(e.InvoiceID[:]),
+		"merchant":     crypto.MustNewAddress(crypto.NHBPrefix, e.Merchant[:]).String(),
+		"asset":        e.Asset,
+		"amount":       formatAmount(e.Amount),
+		"allowPartial": strconv.FormatBool(e.AllowPartial),
+		"expiry":       strconv.FormatUint(e.Expiry, 10),
+	}
+	if !zeroBytes(e.MemoHash[:]) {
+		attrs["memoHash"] = hex.EncodeToString(e.MemoHash[:])
+	}
+	return &types.Event{Type: TypeInvoiceCreated, Attributes: attrs}
+}
+
+// InvoicePayment reports a payment applied to an invoice. Final marks the
+// payment that settled the invoice in full.
+type InvoicePayment struct {
+	InvoiceID [32]byte
+	Merchant  [20]byte
+	Payer     [20]byte
+	Asset     string
+	Amount    *big.Int
+	TotalPaid *big.Int
+	Final     bool
+	TxHash    []byte
+}
+
+// EventType satisfies the events.Event interface.
+func (e InvoicePayment) EventType() string {
+	if e.Final {
+		return TypeInvoicePaid
+	}
+	return TypeInvoicePartiallyPaid
+}
+
+// Event converts the payload into a broadcastable event.
+func (e InvoicePayment) Event() *types.Event {
+	attrs := map[string]string{
+		"invoiceId": hex.EncodeToString(e.InvoiceID[:]),
+		"merchant":  crypto.MustNewAddress(crypto.NHBPrefix, e.Merchant[:]).String(),
+		"payer":     crypto.MustNewAddress(crypto.NHBPrefix, e.Payer[:]).String(),
+		"asset":     e.Asset,
+		"amount":    formatAmount(e.Amount),
+		"totalPaid": formatAmount(e.TotalPaid),
+	}
+	if len(e.TxHash) > 0 {
+		attrs["txHash"] = withHexPrefix(e.TxHash)
+	}
+	return &types.Event{Type: e.EventType(), Attributes: attrs}
+}
+
+// InvoiceRefunded reports funds returned from the merchant to the payer
+// because a payment did not fit the invoice.
+type InvoiceRefunded struct {
+	InvoiceID [32]byte
+	Merchant  [20]byte
+	Payer     [20]byte
+	Asset     string
+	Amount    *big.Int
+	Reason    string
+	TxHash    []byte
+}
+
+// EventType satisfies the events.Event interface.
+func (InvoiceRefunded) EventType() string { return TypeInvoiceRefunded }
+
+// Event converts the payload into a broadcastable event.
+func (e InvoiceRefunded) Event() *types.Event {
+	attrs := map[string]string{
+		"invoiceId": hex.EncodeToString(e.InvoiceID[:]),
+		"merchant":  crypto.MustNewAddress(crypto.NHBPrefix, e.Merchant[:]).String(),
+		"payer":     crypto.MustNewAddress(crypto.NHBPrefix, e.Payer[:]).String(),
+		"asset":     e.Asset,
+		"amount":    formatAmount(e.Amount),
+		"reason":    e.Reason,
+	}
+	if len(e.TxHash) > 0 {
+		attrs["txHash"] = withHexPrefix(e.TxHash)
+	}
+	return &types.Event{Type: TypeInvoiceRefunded, Attributes: attrs}
+}
+
+// InvoiceCancelled reports an invoice withdrawn by its merchant.
+type InvoiceCancelled struct {
+	InvoiceID [32]byte
+	Merchant  [20]byte
+}
+
+// EventType satisfies the events.Event interface.
+func (InvoiceCancelled) EventType() string { return TypeInvoiceCancelled }
+
+// Event converts the payload into a broadcastable event.
+func (e InvoiceCancelled) Event() *types.Event {
+	return &types.Event{
+		Type: TypeInvoiceCancelled,
+		Attributes: map[string]string{
+			"invoiceId": hex.EncodeToString(e.InvoiceID[:]),
+			"merchant":  crypto.MustNewAddress(crypto.NHBPrefix, e.Merchant[:]).String(),
+		},
+	}
+}
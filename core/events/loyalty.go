@@ -1,6 +1,7 @@
 package events
 
 import (
+	"encoding/hex"
 	"math/big"
 	"strconv"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"nhbchain/core/types"
+	"nhbchain/crypto"
 )
 
 const (
@@ -40,6 +42,18 @@ const (
 	// TypeLoyaltyPriceFallback is emitted when the loyalty controller applies a
 	// fallback strategy after price guard failures.
 	TypeLoyaltyPriceFallback = "loyalty.price.fallback"
+	// TypeLoyaltyCatalogueItemUpdated is emitted when a merchant adds or
+	// changes an item in a program's redemption catalogue.
+	TypeLoyaltyCatalogueItemUpdated = "loyalty.catalogue.updated"
+	// TypeLoyaltyRedemption is emitted as the receipt of a catalogue
+	// redemption. Points of sale verify it against loyalty_getRedemption.
+	TypeLoyaltyRedemption = "loyalty.redemption.receipt"
+	// TypeLoyaltyCoalitionUpdated is emitted when a coalition is created or
+	// its membership changes.
+	TypeLoyaltyCoalitionUpdated = "loyalty.coalition.updated"
+	// TypeLoyaltyCoalitionSettled is emitted when a coalition period has been
+	// net-settled between member paymasters.
+	TypeLoyaltyCoalitionSettled = "loyalty.coalition.settled"
 )
 
 const (
@@ -78,6 +92,9 @@ type LoyaltyProgramUpdated struct {
 	EndTime            uint64
 	Pool               [20]byte
 	TokenSymbol        string
+	TierCount          int
+	TierWindowDays     uint32
+	RewardExpiryDays   uint32
 }
 
 // EventType implements the Event interface.
@@ -271,3 +288,103 @@ func (e LoyaltyBudgetProRated) Event() *types.Event {
 		},
 	}
 }
+
+// LoyaltyCatalogueItemUpdated captures a catalogue item after it was added or
+// changed.
+type LoyaltyCatalogueItemUpdated struct {
+	ProgramID [32]byte
+	ItemID    string
+	Name      string
+	CostWei   *big.Int
+	Mode      string
+	Stock     uint64
+	Active    bool
+}
+
+// EventType implements the Event interface.
+func (LoyaltyCatalogueItemUpdated) EventType() string { return TypeLoyaltyCatalogueItemUpdated }
+
+// LoyaltyRedemption is the receipt of a catalogue redemption.
+type LoyaltyRedemption struct {
+	ReceiptID [32]byte
+	ProgramID [32]byte
+	ItemID    string
+	User      [20]byte
+	Quantity  uint64
+	Cost      *big.Int
+	Token     string
+	Mode      string
+	Pool      [20]byte
+	// Expired is the amount of expired rewards reclaimed into the program
+	// pool before the redemption was charged.
+	Expired *big.Int
+	// CoalitionID is set when the program redeems as a coalition member.
+	CoalitionID [32]byte
+}
+
+// EventType implements the Event interface.
+func (LoyaltyRedemption) EventType() string { return TypeLoyaltyRedemption }
+
+// Event converts the redemption receipt into the generic event payload.
+func (e LoyaltyRedemption) Event() *types.Event {
+	attrs := map[string]string{
+		"receiptId": hex.EncodeToString(e.ReceiptID[:]),
+		"programId": hex.EncodeToString(e.ProgramID[:]),
+		"itemId":    e.ItemID,
+		"user":      crypto.MustNewAddress(crypto.NHBPrefix, e.User[:]).String(),
+		"quantity":  strconv.FormatUint(e.Quantity, 10),
+		"cost":      formatAmount(e.Cost),
+		"token":     e.Token,
+		"mode":      e.Mode,
+	}
+	if e.Pool != ([20]byte{}) {
+		attrs["pool"] = crypto.MustNewAddress(crypto.NHBPrefix, e.Pool[:]).String()
+	}
+	if e.Expired != nil && e.Expired.Sign() > 0 {
+		attrs["expired"] = e.Expired.String()
+	}
+	if e.CoalitionID != ([32]byte{}) {
+		attrs["coalitionId"] = hex.EncodeToString(e.CoalitionID[:])
+	}
+	return &types.Event{Type: TypeLoyaltyRedemption, Attributes: attrs}
+}
+
+// LoyaltyCoalitionUpdated captures a coalition after it was created or a
+// member was invited, joined or left.
+type LoyaltyCoalitionUpdated struct {
+	CoalitionID [32]byte
+	Action      string
+	ProgramID   [32]byte
+	Mode        string
+	Members     int
+}
+
+// EventType implements the Event interface.
+func (LoyaltyCoalitionUpdated) EventType() string { return TypeLoyaltyCoalitionUpdated }
+
+// LoyaltyCoalitionSettled summarises the net settlement of one coalition
+// period. The full report is available through loyalty_coalitionReport.
+type LoyaltyCoalitionSettled struct {
+	CoalitionID [32]byte
+	Period      uint64
+	Transfers   int
+	Settled     *big.Int
+	Shortfall   *big.Int
+}
+
+// EventType implements the Event interface.
+func (LoyaltyCoalitionSettled) EventType() string { return TypeLoyaltyCoalitionSettled }
+
+// Event converts the settlement summary into the generic event payload.
+func (e LoyaltyCoalitionSettled) Event() *types.Event {
+	return &types.Event{
+		Type: TypeLoyaltyCoalitionSettled,
+		Attributes: map[string]string{
+			"coalitionId": hex.EncodeToString(e.CoalitionID[:]),
+			"period":      strconv.FormatUint(e.Period, 10),
+			"transfers":   strconv.Itoa(e.Transfers),
+			"settled":     formatAmount(e.Settled),
+			"shortfall":   formatAmount(e.Shortfall),
+		},
+	}
+}
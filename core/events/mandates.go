@@ -0,0 +1,147 @@
+package events
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strconv"
+
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+const (
+	// TypeMandateCreated is emitted when a payer signs a recurring payment
+	// mandate for a merchant.
+	TypeMandateCreated = "mandate.created"
+	// TypeMandatePulled is emitted when a merchant collects a period's
+	// payment under a mandate.
+	TypeMandatePulled = "mandate.pulled"
+	// TypeMandatePullFailed is emitted when a pull cannot be funded by the
+	// payer so that merchants can run dunning on the failure.
+	TypeMandatePullFailed = "mandate.pull_failed"
+	// TypeMandateCancelled is emitted when a mandate is revoked.
+	TypeMandateCancelled = "mandate.cancelled"
+)
+
+// MandateCreated describes a newly signed recurring payment mandate.
+type MandateCreated struct {
+	MandateID     [32]byte
+	Payer         [20]byte
+	Merchant      [20]byte
+	Asset         string
+	MaxAmount     *big.Int
+	PeriodSeconds uint64
+	StartAt       uint64
+	EndAt         uint64
+}
+
+// EventType satisfies the events.Event interface.
+func (MandateCreated) EventType() string { return TypeMandateCreated }
+
+// Event converts the payload into a broadcastable event.
+func (e MandateCreated) Event() *types.Event {
+	return &types.Event{
+		Type: TypeMandateCreated,
+		Attributes: map[string]string{
+			"mandateId":     hex.EncodeToString(e.MandateID[:]),
+			"payer":         crypto.MustNewAddress(crypto.NHBPrefix, e.Payer[:]).String(),
+			"merchant":      crypto.MustNewAddress(crypto.NHBPrefix, e.Merchant[:]).String(),
+			"asset":         e.Asset,
+			"maxAmount":     formatAmount(e.MaxAmount),
+			"periodSeconds": strconv.FormatUint(e.PeriodSeconds, 10),
+			"startAt":       strconv.FormatUint(e.StartAt, 10),
+			"endAt":         strconv.FormatUint(e.EndAt, 10),
+		},
+	}
+}
+
+// MandatePulled reports a successful merchant pull for one billing period.
+type MandatePulled struct {
+	MandateID   [32]byte
+	Payer       [20]byte
+	Merchant    [20]byte
+	Asset       string
+	Amount      *big.Int
+	Period      uint64
+	PulledTotal *big.Int
+	TxHash      []byte
+}
+
+// EventType satisfies the events.Event interface.
+func (MandatePulled) EventType() string { return TypeMandatePulled }
+
+// Event converts the payload into a broadcastable event.
+func (e MandatePulled) Event() *types.Event {
+	attrs := map[string]string{
+		"mandateId":   hex.EncodeToString(e.MandateID[:]),
+		"payer":       crypto.MustNewAddress(crypto.NHBPrefix, e.Payer[:]).String(),
+		"merchant":    crypto.MustNewAddress(crypto.NHBPrefix, e.Merchant[:]).String(),
+		"asset":       e.Asset,
+		"amount":      formatAmount(e.Amount),
+		"period":      strconv.FormatUint(e.Period, 10),
+		"pulledTotal": formatAmount(e.PulledTotal),
+	}
+	if len(e.TxHash) > 0 {
+		attrs["txHash"] = withHexPrefix(e.TxHash)
+	}
+	return &types.Event{Type: TypeMandatePulled, Attributes: attrs}
+}
+
+// MandatePullFailed reports a pull the payer could not fund. Attempts counts
+// the consecutive failures since the last successful pull.
+type MandatePullFailed struct {
+	MandateID [32]byte
+	Payer     [20]byte
+	Merchant  [20]byte
+	Asset     string
+	Amount    *big.Int
+	Period    uint64
+	Attempts  uint64
+	Reason    string
+	TxHash    []byte
+}
+
+// EventType satisfies the events.Event interface.
+func (MandatePullFailed) EventType() string { return TypeMandatePullFailed }
+
+// Event converts the payload into a broadcastable event.
+func (e MandatePullFailed) Event() *types.Event {
+	attrs := map[string]string{
+		"mandateId": hex.EncodeToString(e.MandateID[:]),
+		"payer":     crypto.MustNewAddress(crypto.NHBPrefix, e.Payer[:]).String(),
+		"merchant":  crypto.MustNewAddress(crypto.NHBPrefix, e.Merchant[:]).String(),
+		"asset":     e.Asset,
+		"amount":    formatAmount(e.Amount),
+		"period":    strconv.FormatUint(e.Period, 10),
+		"attempts":  strconv.FormatUint(e.Attempts, 10),
+		"reason":    e.Reason,
+	}
+	if len(e.TxHash) > 0 {
+		attrs["txHash"] = withHexPrefix(e.TxHash)
+	}
+	return &types.Event{Type: TypeMandatePullFailed, Attributes: attrs}
+}
+
+// MandateCancelled reports a mandate revoked by its payer or merchant.
+type MandateCancelled struct {
+	MandateID   [32]byte
+	Payer       [20]byte
+	Merchant    [20]byte
+	CancelledBy [20]byte
+}
+
+// EventType satisfies the events.Event interface.
+func (MandateCancelled) EventType() string { return TypeMandateCancelled }
+
+// Event converts the payload into a broadcastable event.
+func (e MandateCancelled) Event() *types.Event {
+	return &types.Event{
+		Type: TypeMandateCancelled,
+		Attributes: map[string]string{
+			"mandateId":   hex.EncodeToString(e.MandateID[:]),
+			"payer":       crypto.MustNewAddress(crypto.NHBPrefix, e.Payer[:]).String(),
+			"merchant":    crypto.MustNewAddress(crypto.NHBPrefix, e.Merchant[:]).String(),
+			"cancelledBy": crypto.MustNewAddress(crypto.NHBPrefix, e.CancelledBy[:]).String(),
+		},
+	}
+}
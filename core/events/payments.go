@@ -19,6 +19,12 @@ const (
 	// TypePaymentCaptured is emitted after an authorization is successfully
 	// captured and the funds transferred to the merchant.
 	TypePaymentCaptured = "payments.captured"
+	// TypePaymentIncremented is emitted when the payer adds funds to a
+	// pending authorization.
+	TypePaymentIncremented = "payments.incremented"
+	// TypePaymentRefunded is emitted when a merchant refunds captured funds
+	// of an authorization to the payer.
+	TypePaymentRefunded = "payments.refunded"
 	// TypePaymentVoided marks authorizations that returned funds to the
 	// payer either manually or due to expiry.
 	TypePaymentVoided = "payments.voided"
@@ -65,6 +71,7 @@ type PaymentAuthorized struct {
 	AuthorizationID [32]byte
 	Payer           [20]byte
 	Merchant        [20]byte
+	Token           string
 	Amount          *big.Int
 	Expiry          uint64
 	IntentRef       []byte
@@ -87,6 +94,9 @@ func (e PaymentAuthorized) Event() *types.Event {
 	if !zeroBytes(e.Merchant[:]) {
 		attrs["merchant"] = hex.EncodeToString(e.Merchant[:])
 	}
+	if e.Token != "" {
+		attrs["token"] = e.Token
+	}
 	if e.Amount != nil {
 		attrs["amount"] = e.Amount.String()
 	}
@@ -107,6 +117,10 @@ type PaymentCaptured struct {
 	Merchant        [20]byte
 	CapturedAmount  *big.Int
 	RefundedAmount  *big.Int
+	// TotalCaptured is the amount captured across all captures so far.
+	TotalCaptured *big.Int
+	// Final reports whether the capture closed the authorization.
+	Final bool
 }
 
 // EventType satisfies the events.Event interface.
@@ -132,9 +146,93 @@ func (e PaymentCaptured) Event() *types.Event {
 	if e.RefundedAmount != nil {
 		attrs["refundedAmount"] = e.RefundedAmount.String()
 	}
+	if e.TotalCaptured != nil {
+		attrs["totalCaptured"] = e.TotalCaptured.String()
+	}
+	attrs["final"] = strconv.FormatBool(e.Final)
 	return &types.Event{Type: TypePaymentCaptured, Attributes: attrs}
 }
 
+// PaymentIncremented reports additional funds locked on a pending
+// authorization.
+type PaymentIncremented struct {
+	AuthorizationID [32]byte
+	Payer           [20]byte
+	Merchant        [20]byte
+	Amount          *big.Int
+	TotalAmount     *big.Int
+	Expiry          uint64
+}
+
+// EventType satisfies the events.Event interface.
+func (PaymentIncremented) EventType() string { return TypePaymentIncremented }
+
+// Event converts the increment payload into a broadcastable event.
+func (e PaymentIncremented) Event() *types.Event {
+	if zeroBytes(e.AuthorizationID[:]) {
+		return nil
+	}
+	attrs := map[string]string{
+		"authorizationId": hex.EncodeToString(e.AuthorizationID[:]),
+	}
+	if !zeroBytes(e.Payer[:]) {
+		attrs["payer"] = hex.EncodeToString(e.Payer[:])
+	}
+	if !zeroBytes(e.Merchant[:]) {
+		attrs["merchant"] = hex.EncodeToString(e.Merchant[:])
+	}
+	if e.Amount != nil {
+		attrs["amount"] = e.Amount.String()
+	}
+	if e.TotalAmount != nil {
+		attrs["totalAmount"] = e.TotalAmount.String()
+	}
+	if e.Expiry != 0 {
+		attrs["expiry"] = strconv.FormatUint(e.Expiry, 10)
+	}
+	return &types.Event{Type: TypePaymentIncremented, Attributes: attrs}
+}
+
+// PaymentRefunded records captured funds returned by the merchant to the
+// payer.
+type PaymentRefunded struct {
+	AuthorizationID [32]byte
+	Payer           [20]byte
+	Merchant        [20]byte
+	Amount          *big.Int
+	TotalRefunded   *big.Int
+	Reason          string
+}
+
+// EventType satisfies the events.Event interface.
+func (PaymentRefunded) EventType() string { return TypePaymentRefunded }
+
+// Event converts the refund payload into a broadcastable event.
+func (e PaymentRefunded) Event() *types.Event {
+	if zeroBytes(e.AuthorizationID[:]) {
+		return nil
+	}
+	attrs := map[string]string{
+		"authorizationId": hex.EncodeToString(e.AuthorizationID[:]),
+	}
+	if !zeroBytes(e.Payer[:]) {
+		attrs["payer"] = hex.EncodeToString(e.Payer[:])
+	}
+	if !zeroBytes(e.Merchant[:]) {
+		attrs["merchant"] = hex.EncodeToString(e.Merchant[:])
+	}
+	if e.Amount != nil {
+		attrs["amount"] = e.Amount.String()
+	}
+	if e.TotalRefunded != nil {
+		attrs["totalRefunded"] = e.TotalRefunded.String()
+	}
+	if reason := strings.TrimSpace(e.Reason); reason != "" {
+		attrs["reason"] = reason
+	}
+	return &types.Event{Type: TypePaymentRefunded, Attributes: attrs}
+}
+
 // PaymentVoided records the release of an authorization lock back to the payer.
 type PaymentVoided struct {
 	AuthorizationID [32]byte
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"strconv"
+	"strings"
+
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+const (
+	// TypeRecoveryGuardiansSet is emitted when an account registers, changes
+	// or clears its guardian set.
+	TypeRecoveryGuardiansSet = "recovery.guardians.set"
+	// TypeRecoveryApproved is emitted for each guardian approval of a key
+	// rotation.
+	TypeRecoveryApproved = "recovery.approved"
+	// TypeRecoveryCancelled is emitted when the owner cancels a pending
+	// rotation.
+	TypeRecoveryCancelled = "recovery.cancelled"
+	// TypeRecoveryExecuted is emitted once a rotation has moved the account to
+	// its new address.
+	TypeRecoveryExecuted = "recovery.executed"
+)
+
+// RecoveryGuardiansSet describes the guardian set registered for an account.
+// An empty guardian list means recovery was disabled.
+type RecoveryGuardiansSet struct {
+	Account      [20]byte
+	Guardians    [][20]byte
+	Threshold    uint32
+	DelaySeconds uint64
+}
+
+// EventType satisfies the events.Event interface.
+func (RecoveryGuardiansSet) EventType() string { return TypeRecoveryGuardiansSet }
+
+// Event converts the payload into a broadcastable event.
+func (e RecoveryGuardiansSet) Event() *types.Event {
+	guardians := make([]string, len(e.Guardians))
+	for i, guardian := range e.Guardians {
+		guardians[i] = crypto.MustNewAddress(crypto.NHBPrefix, guardian[:]).String()
+	}
+	return &types.Event{
+		Type: TypeRecoveryGuardiansSet,
+		Attributes: map[string]string{
+			"account":      crypto.MustNewAddress(crypto.NHBPrefix, e.Account[:]).String(),
+			"guardians":    strings.Join(guardians, ","),
+			"threshold":    strconv.FormatUint(uint64(e.Threshold), 10),
+			"delaySeconds": strconv.FormatUint(e.DelaySeconds, 10),
+		},
+	}
+}
+
+// RecoveryApproved reports a guardian approval. ExecutableAt is non-zero once
+// the approvals reach the threshold.
+type RecoveryApproved struct {
+	Account      [20]byte
+	NewAddress   [20]byte
+	Guardian     [20]byte
+	Approvals    int
+	Threshold    uint32
+	ExecutableAt uint64
+}
+
+// EventType satisfies the events.Event interface.
+func (RecoveryApproved) EventType() string { return TypeRecoveryApproved }
+
+// Event converts the payload into a broadcastable event.
+func (e RecoveryApproved) Event() *types.Event {
+	attrs := map[string]string{
+		"account":    crypto.MustNewAddress(crypto.NHBPrefix, e.Account[:]).String(),
+		"newAddress": crypto.MustNewAddress(crypto.NHBPrefix, e.NewAddress[:]).String(),
+		"guardian":   crypto.MustNewAddress(crypto.NHBPrefix, e.Guardian[:]).String(),
+		"approvals":  strconv.Itoa(e.Approvals),
+		"threshold":  strconv.FormatUint(uint64(e.Threshold), 10),
+	}
+	if e.ExecutableAt != 0 {
+		attrs["executableAt"] = strconv.FormatUint(e.ExecutableAt, 10)
+	}
+	return &types.Event{Type: TypeRecoveryApproved, Attributes: attrs}
+}
+
+// RecoveryCancelled reports a rotation withdrawn by the account owner.
+type RecoveryCancelled struct {
+	Account    [20]byte
+	NewAddress [20]byte
+}
+
+// EventType satisfies the events.Event interface.
+func (RecoveryCancelled) EventType() string { return TypeRecoveryCancelled }
+
+// Event converts the payload into a broadcastable event.
+func (e RecoveryCancelled) Event() *types.Event {
+	return &types.Event{
+		Type: TypeRecoveryCancelled,
+		Attributes: map[string]string{
+			"account":    crypto.MustNewAddress(crypto.NHBPrefix, e.Account[:]).String(),
+			"newAddress": crypto.MustNewAddress(crypto.NHBPrefix, e.NewAddress[:]).String(),
+		},
+	}
+}
+
+// RecoveryExecuted reports a completed rotation. Alias is set when an alias
+// followed the account to its new address.
+type RecoveryExecuted struct {
+	Account    [20]byte
+	NewAddress [20]byte
+	Alias      string
+}
+
+// EventType satisfies the events.Event interface.
+func (RecoveryExecuted) EventType() string { return TypeRecoveryExecuted }
+
+// Event converts the payload into a broadcastable event.
+func (e RecoveryExecuted) Event() *types.Event {
+	attrs := map[string]string{
+		"account":    crypto.MustNewAddress(crypto.NHBPrefix, e.Account[:]).String(),
+		"newAddress": crypto.MustNewAddress(crypto.NHBPrefix, e.NewAddress[:]).String(),
+	}
+	if e.Alias != "" {
+		attrs["alias"] = e.Alias
+	}
+	return &types.Event{Type: TypeRecoveryExecuted, Attributes: attrs}
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+const (
+	// TypeSessionKeyRegistered is emitted when an account adds or updates a
+	// session key.
+	TypeSessionKeyRegistered = "session.key.registered"
+	// TypeSessionKeyRevoked is emitted when an account revokes a session key.
+	TypeSessionKeyRevoked = "session.key.revoked"
+)
+
+// SessionKeyRegistered describes the policy attached to a session key.
+type SessionKeyRegistered struct {
+	Account        [20]byte
+	Key            [20]byte
+	TxTypes        []uint8
+	DailyLimitNHB  *big.Int
+	DailyLimitZNHB *big.Int
+	Counterparties int
+	ExpiresAt      uint64
+}
+
+// EventType satisfies the events.Event interface.
+func (SessionKeyRegistered) EventType() string { return TypeSessionKeyRegistered }
+
+// Event converts the payload into a broadcastable event.
+func (e SessionKeyRegistered) Event() *types.Event {
+	txTypes := make([]string, len(e.TxTypes))
+	for i, t := range e.TxTypes {
+		txTypes[i] = fmt.Sprintf("0x%02x", t)
+	}
+	return &types.Event{
+		Type: TypeSessionKeyRegistered,
+		Attributes: map[string]string{
+			"account":        crypto.MustNewAddress(crypto.NHBPrefix, e.Account[:]).String(),
+			"key":            crypto.MustNewAddress(crypto.NHBPrefix, e.Key[:]).String(),
+			"txTypes":        strings.Join(txTypes, ","),
+			"dailyLimitNHB":  formatAmount(e.DailyLimitNHB),
+			"dailyLimitZNHB": formatAmount(e.DailyLimitZNHB),
+			"counterparties": strconv.Itoa(e.Counterparties),
+			"expiresAt":      strconv.FormatUint(e.ExpiresAt, 10),
+		},
+	}
+}
+
+// SessionKeyRevoked reports a session key removed by its account.
+type SessionKeyRevoked struct {
+	Account [20]byte
+	Key     [20]byte
+}
+
+// EventType satisfies the events.Event interface.
+func (SessionKeyRevoked) EventType() string { return TypeSessionKeyRevoked }
+
+// Event converts the payload into a broadcastable event.
+func (e SessionKeyRevoked) Event() *types.Event {
+	return &types.Event{
+		Type: TypeSessionKeyRevoked,
+		Attributes: map[string]string{
+			"account": crypto.MustNewAddress(crypto.NHBPrefix, e.Account[:]).String(),
+			"key":     crypto.MustNewAddress(crypto.NHBPrefix, e.Key[:]).String(),
+		},
+	}
+}
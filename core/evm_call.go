@@ -0,0 +1,424 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"nhbchain/core/types"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	gethcore "github.com/ethereum/go-ethereum/core"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	gethvm "github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native" // registers callTracer, prestateTracer, ...
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// BlockGasLimit is the most gas a read-only call may consume: no call may
+// cost more than executing a full block. Caller-supplied limits above it are
+// clamped.
+const BlockGasLimit uint64 = 30_000_000
+
+// DefaultCallGasLimit bounds read-only calls that do not specify a gas limit.
+const DefaultCallGasLimit = BlockGasLimit
+
+// TracerStructLogger names the opcode-level struct logger. It is used when a
+// trace request does not name a tracer, matching go-ethereum's behaviour.
+const TracerStructLogger = "structLogger"
+
+var (
+	// ErrCallStateUnavailable indicates the requested block has no state to
+	// execute against.
+	ErrCallStateUnavailable = errors.New("evm call: state unavailable")
+	// ErrTraceNotEVM indicates the traced transaction is a native module
+	// transaction and therefore never reaches the EVM.
+	ErrTraceNotEVM = errors.New("trace: transaction is not executed by the EVM")
+	// ErrTraceTxNotFound indicates the requested transaction hash is not part
+	// of any committed block.
+	ErrTraceTxNotFound = errors.New("trace: transaction not found")
+	// ErrUnknownTracer indicates the trace request named an unregistered tracer.
+	ErrUnknownTracer = errors.New("trace: unknown tracer")
+)
+
+// CallMsg describes a message executed read-only against a state snapshot.
+// Unset gas defaults to DefaultCallGasLimit, gas above BlockGasLimit is
+// clamped and unset values default to zero.
+type CallMsg struct {
+	From     []byte
+	To       []byte
+	Data     []byte
+	Value    *big.Int
+	Gas      uint64
+	GasPrice *big.Int
+}
+
+// CallResult captures the outcome of a read-only EVM execution. Reverted
+// executions are reported via Reverted/RevertReason rather than an error so
+// callers can surface the revert payload to developers.
+type CallResult struct {
+	ReturnData   []byte
+	GasUsed      uint64
+	Reverted     bool
+	RevertReason string
+	VMError      string
+}
+
+// TraceConfig selects the tracer applied to a traced execution. An empty
+// Tracer selects the struct logger; any other name is resolved through
+// go-ethereum's tracer directory (e.g. "callTracer", "prestateTracer").
+type TraceConfig struct {
+	Tracer       string
+	TracerConfig json.RawMessage
+	Logger       logger.Config
+}
+
+type evmTracer struct {
+	hooks     *tracing.Hooks
+	getResult func() (json.RawMessage, error)
+	stop      func(error)
+}
+
+func newEVMTracer(cfg TraceConfig, txCtx *tracers.Context) (*evmTracer, error) {
+	name := strings.TrimSpace(cfg.Tracer)
+	if name == "" || name == TracerStructLogger {
+		loggerCfg := cfg.Logger
+		structLogger := logger.NewStructLogger(&loggerCfg)
+		return &evmTracer{hooks: structLogger.Hooks(), getResult: structLogger.GetResult, stop: structLogger.Stop}, nil
+	}
+	// Unregistered names are treated as JavaScript by go-ethereum; JS tracers
+	// are not linked into the node so reject them up front.
+	if tracers.DefaultDirectory.IsJS(name) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTracer, name)
+	}
+	tracer, err := tracers.DefaultDirectory.New(name, txCtx, cfg.TracerConfig, params.TestChainConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrUnknownTracer, name, err)
+	}
+	return &evmTracer{hooks: tracer.Hooks, getResult: tracer.GetResult, stop: tracer.Stop}, nil
+}
+
+// evmBlockContext returns the EVM block context for the block currently being
+// executed by the state processor.
+func (sp *StateProcessor) evmBlockContext(coinbase common.Address) gethvm.BlockContext {
+	return gethvm.BlockContext{
+		CanTransfer: gethcore.CanTransfer,
+		Transfer:    gethcore.Transfer,
+		GetHash: func(uint64) common.Hash {
+			return common.Hash{}
+		},
+		Coinbase:    coinbase,
+		BlockNumber: new(big.Int).SetUint64(sp.blockHeight()),
+		Time:        uint64(sp.blockTimestamp().Unix()),
+		Difficulty:  big.NewInt(0),
+		BaseFee:     big.NewInt(0),
+	}
+}
+
+// CallContract executes msg against the processor's pending state without
+// persisting any mutation. Nonce checks are skipped so view functions can be
+// invoked from any address. When hooks is non-nil the execution is traced.
+func (sp *StateProcessor) CallContract(msg CallMsg, hooks *tracing.Hooks) (*CallResult, error) {
+	if sp == nil || sp.Trie == nil {
+		return nil, ErrCallStateUnavailable
+	}
+	staging, err := sp.Trie.Copy()
+	if err != nil {
+		return nil, err
+	}
+	root, release, err := staging.Stage(sp.blockHeight())
+	if err != nil {
+		return nil, fmt.Errorf("evm call: stage state: %w", err)
+	}
+	defer release()
+	statedb, err := gethstate.New(root, sp.stateDB)
+	if err != nil {
+		return nil, fmt.Errorf("evm call: statedb init: %w", err)
+	}
+
+	from := common.BytesToAddress(msg.From)
+	var to *common.Address
+	if len(msg.To) > 0 {
+		addr := common.BytesToAddress(msg.To)
+		to = &addr
+	}
+	gas := msg.Gas
+	if gas == 0 {
+		gas = DefaultCallGasLimit
+	}
+	if gas > BlockGasLimit {
+		gas = BlockGasLimit
+	}
+	value := big.NewInt(0)
+	if msg.Value != nil {
+		value = new(big.Int).Set(msg.Value)
+	}
+	gasPrice := big.NewInt(0)
+	if msg.GasPrice != nil {
+		gasPrice = new(big.Int).Set(msg.GasPrice)
+	}
+	message := &gethcore.Message{
+		From:             from,
+		To:               to,
+		Nonce:            statedb.GetNonce(from),
+		Value:            value,
+		GasLimit:         gas,
+		GasPrice:         gasPrice,
+		GasFeeCap:        gasPrice,
+		GasTipCap:        gasPrice,
+		Data:             append([]byte(nil), msg.Data...),
+		SkipNonceChecks:  true,
+		SkipFromEOACheck: true,
+	}
+
+	var coinbase common.Address
+	if policy := sp.TransferGasPolicy(); !isZeroAddress(policy.FeeCollector) {
+		coinbase = common.BytesToAddress(policy.FeeCollector[:])
+	}
+	var vmState gethvm.StateDB = statedb
+	vmConfig := gethvm.Config{NoBaseFee: true}
+	if hooks != nil {
+		vmState = gethstate.NewHookedState(statedb, hooks)
+		vmConfig.Tracer = hooks
+	}
+	evm := gethvm.NewEVM(sp.evmBlockContext(coinbase), vmState, params.TestChainConfig, vmConfig)
+	evm.SetTxContext(gethcore.NewEVMTxContext(message))
+
+	if hooks != nil && hooks.OnTxStart != nil {
+		hooks.OnTxStart(evm.GetVMContext(), gethtypes.NewTx(&gethtypes.LegacyTx{
+			Nonce:    message.Nonce,
+			GasPrice: gasPrice,
+			Gas:      gas,
+			To:       to,
+			Value:    value,
+			Data:     message.Data,
+		}), from)
+	}
+	result, err := gethcore.ApplyMessage(evm, message, new(gethcore.GasPool).AddGas(gas))
+	if hooks != nil && hooks.OnTxEnd != nil {
+		var receipt *gethtypes.Receipt
+		if result != nil {
+			receipt = &gethtypes.Receipt{GasUsed: result.UsedGas}
+		}
+		hooks.OnTxEnd(receipt, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("evm call: %w", err)
+	}
+
+	out := &CallResult{
+		ReturnData: append([]byte(nil), result.ReturnData...),
+		GasUsed:    result.UsedGas,
+	}
+	if result.Err != nil {
+		out.VMError = result.Err.Error()
+		if errors.Is(result.Err, gethvm.ErrExecutionReverted) {
+			out.Reverted = true
+			if reason, unpackErr := abi.UnpackRevert(result.Revert()); unpackErr == nil {
+				out.RevertReason = reason
+			}
+		}
+	}
+	return out, nil
+}
+
+// TraceCall executes msg read-only with the tracer selected by cfg and
+// returns the tracer output.
+func (sp *StateProcessor) TraceCall(msg CallMsg, cfg TraceConfig) (json.RawMessage, error) {
+	return sp.traceCall(msg, cfg, &tracers.Context{BlockNumber: new(big.Int).SetUint64(sp.blockHeight())})
+}
+
+func (sp *StateProcessor) traceCall(msg CallMsg, cfg TraceConfig, txCtx *tracers.Context) (json.RawMessage, error) {
+	tracer, err := newEVMTracer(cfg, txCtx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sp.CallContract(msg, tracer.hooks); err != nil {
+		tracer.stop(err)
+		return nil, err
+	}
+	return tracer.getResult()
+}
+
+// callMsgFromTransaction converts an EVM-bound transaction into the message
+// replayed by the tracer.
+func callMsgFromTransaction(tx *types.Transaction) (CallMsg, error) {
+	if tx == nil {
+		return CallMsg{}, fmt.Errorf("trace: transaction required")
+	}
+	if tx.Type != types.TxTypeTransfer {
+		return CallMsg{}, fmt.Errorf("%w: type 0x%x", ErrTraceNotEVM, byte(tx.Type))
+	}
+	from, err := tx.From()
+	if err != nil {
+		return CallMsg{}, err
+	}
+	return CallMsg{
+		From:     from,
+		To:       tx.To,
+		Data:     tx.Data,
+		Value:    tx.Value,
+		Gas:      tx.GasLimit,
+		GasPrice: tx.GasPrice,
+	}, nil
+}
+
+// stateAtHeight returns a detached state processor positioned at the
+// post-state of the block at height together with that block. The processor
+// shares no mutable state with the canonical one and may be used without
+// holding stateMu.
+func (n *Node) stateAtHeight(height uint64) (*StateProcessor, *types.Block, error) {
+	if n == nil || n.chain == nil {
+		return nil, nil, fmt.Errorf("node unavailable")
+	}
+	block, err := n.chain.GetBlockByHeight(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: height %d: %v", ErrCallStateUnavailable, height, err)
+	}
+	if block == nil || block.Header == nil {
+		return nil, nil, fmt.Errorf("%w: height %d", ErrCallStateUnavailable, height)
+	}
+	n.stateMu.Lock()
+	if n.state == nil {
+		n.stateMu.Unlock()
+		return nil, nil, ErrCallStateUnavailable
+	}
+	view, err := n.state.Copy()
+	if err == nil {
+		view.events = nil
+		view.SetPauseView(n)
+		view.SetQuotaConfig(n.moduleQuotaSnapshot())
+	}
+	n.stateMu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	root := common.BytesToHash(block.Header.StateRoot)
+	if root != view.CurrentRoot() {
+		if err := view.ResetToRoot(root); err != nil {
+			return nil, nil, fmt.Errorf("%w: height %d: %v", ErrCallStateUnavailable, height, err)
+		}
+		if err := view.loadUsernameIndex(); err != nil {
+			return nil, nil, err
+		}
+		if err := view.loadValidatorSet(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return view, block, nil
+}
+
+func resolveCallHeight(n *Node, height *uint64) uint64 {
+	if height == nil {
+		return n.chain.GetHeight()
+	}
+	return *height
+}
+
+// CallContract executes msg read-only against the post-state of the block at
+// height, or the latest committed block when height is nil. No state is
+// persisted and no transaction is broadcast.
+func (n *Node) CallContract(msg CallMsg, height *uint64) (*CallResult, error) {
+	if n == nil || n.chain == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	view, block, err := n.stateAtHeight(resolveCallHeight(n, height))
+	if err != nil {
+		return nil, err
+	}
+	view.BeginBlock(block.Header.Height, time.Unix(block.Header.Timestamp, 0))
+	defer view.EndBlock()
+	return view.CallContract(msg, nil)
+}
+
+// TraceCall executes msg read-only against the post-state of the block at
+// height (latest when nil) and returns the output of the configured tracer.
+func (n *Node) TraceCall(msg CallMsg, height *uint64, cfg TraceConfig) (json.RawMessage, error) {
+	if n == nil || n.chain == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	view, block, err := n.stateAtHeight(resolveCallHeight(n, height))
+	if err != nil {
+		return nil, err
+	}
+	view.BeginBlock(block.Header.Height, time.Unix(block.Header.Timestamp, 0))
+	defer view.EndBlock()
+	return view.TraceCall(msg, cfg)
+}
+
+// TraceTransaction re-executes a committed transaction with the configured
+// tracer. The parent block's post-state is loaded and every transaction that
+// precedes the target in the block's canonical order is replayed first so the
+// traced execution observes exactly the pre-state it saw on-chain.
+func (n *Node) TraceTransaction(hash []byte, cfg TraceConfig) (json.RawMessage, error) {
+	if n == nil || n.chain == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	block, index, err := n.findCommittedTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	if block.Header.Height == 0 {
+		return nil, fmt.Errorf("%w: genesis transactions cannot be traced", ErrCallStateUnavailable)
+	}
+	msg, err := callMsgFromTransaction(block.Transactions[index])
+	if err != nil {
+		return nil, err
+	}
+	view, _, err := n.stateAtHeight(block.Header.Height - 1)
+	if err != nil {
+		return nil, err
+	}
+	view.BeginBlock(block.Header.Height, time.Unix(block.Header.Timestamp, 0))
+	defer view.EndBlock()
+	for i := 0; i < index; i++ {
+		if err := view.ApplyTransaction(block.Transactions[i]); err != nil {
+			return nil, fmt.Errorf("trace: replay transaction %d: %w", i, err)
+		}
+	}
+	blockHash, err := block.Header.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return view.traceCall(msg, cfg, &tracers.Context{
+		BlockHash:   common.BytesToHash(blockHash),
+		BlockNumber: new(big.Int).SetUint64(block.Header.Height),
+		TxIndex:     index,
+		TxHash:      common.BytesToHash(hash),
+	})
+}
+
+// findCommittedTransaction resolves the transaction with the provided hash
+// through the chain's transaction index and returns its block and position.
+func (n *Node) findCommittedTransaction(hash []byte) (*types.Block, int, error) {
+	if len(hash) != common.HashLength {
+		return nil, 0, fmt.Errorf("trace: transaction hash must be 32 bytes")
+	}
+	height, ok := n.chain.TransactionHeight(hash)
+	if !ok {
+		return nil, 0, ErrTraceTxNotFound
+	}
+	block, err := n.chain.GetBlockByHeight(height)
+	if err != nil || block == nil || block.Header == nil {
+		return nil, 0, ErrTraceTxNotFound
+	}
+	for i, tx := range block.Transactions {
+		if tx == nil {
+			continue
+		}
+		txHash, hashErr := tx.Hash()
+		if hashErr == nil && bytes.Equal(txHash, hash) {
+			return block, i, nil
+		}
+	}
+	return nil, 0, ErrTraceTxNotFound
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+// returnFortyTwoCode is runtime bytecode that returns the 32-byte word 42.
+const returnFortyTwoCode = "602a60005260206000f3"
+
+// revertNopeCode is runtime bytecode that reverts with Error("nope").
+const revertNopeCode = "6064600c60003960646000fd" +
+	"08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"0000000000000000000000000000000000000000000000000000000000000004" +
+	"6e6f706500000000000000000000000000000000000000000000000000000000"
+
+func installContractCode(t *testing.T, sp *StateProcessor, addr common.Address, codeHex string) {
+	t.Helper()
+	code, err := hex.DecodeString(codeHex)
+	if err != nil {
+		t.Fatalf("decode code: %v", err)
+	}
+	root, err := sp.Commit(0)
+	if err != nil {
+		t.Fatalf("commit state: %v", err)
+	}
+	statedb, err := gethstate.New(root, sp.stateDB)
+	if err != nil {
+		t.Fatalf("open statedb: %v", err)
+	}
+	statedb.SetCode(addr, code)
+	newRoot, err := statedb.Commit(0, false, false)
+	if err != nil {
+		t.Fatalf("commit statedb: %v", err)
+	}
+	if err := sp.ResetToRoot(newRoot); err != nil {
+		t.Fatalf("reset root: %v", err)
+	}
+}
+
+func TestCallContractReturnsDataWithoutMutatingState(t *testing.T) {
+	sp, _ := newTestStateProcessor(t)
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	installContractCode(t, sp, contract, returnFortyTwoCode)
+	before := sp.PendingRoot()
+
+	result, err := sp.CallContract(CallMsg{To: contract.Bytes()}, nil)
+	if err != nil {
+		t.Fatalf("call contract: %v", err)
+	}
+	if result.Reverted {
+		t.Fatalf("unexpected revert: %s", result.VMError)
+	}
+	if got := new(big.Int).SetBytes(result.ReturnData); got.Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("expected 42, got %s", got)
+	}
+	if result.GasUsed == 0 {
+		t.Fatalf("expected gas to be consumed")
+	}
+	if after := sp.PendingRoot(); after != before {
+		t.Fatalf("call mutated state: %x -> %x", before, after)
+	}
+}
+
+func TestCallContractSurfacesRevertReason(t *testing.T) {
+	sp, _ := newTestStateProcessor(t)
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	installContractCode(t, sp, contract, revertNopeCode)
+
+	result, err := sp.CallContract(CallMsg{To: contract.Bytes()}, nil)
+	if err != nil {
+		t.Fatalf("call contract: %v", err)
+	}
+	if !result.Reverted {
+		t.Fatalf("expected revert")
+	}
+	if result.RevertReason != "nope" {
+		t.Fatalf("expected revert reason nope, got %q", result.RevertReason)
+	}
+}
+
+func TestTraceCallTracers(t *testing.T) {
+	sp, _ := newTestStateProcessor(t)
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000c2")
+	installContractCode(t, sp, contract, returnFortyTwoCode)
+
+	raw, err := sp.TraceCall(CallMsg{To: contract.Bytes()}, TraceConfig{Tracer: "callTracer"})
+	if err != nil {
+		t.Fatalf("call tracer: %v", err)
+	}
+	var frame struct {
+		Type   string `json:"type"`
+		To     string `json:"to"`
+		Output string `json:"output"`
+	}
+	if err := json.Unmarshal(raw, &frame); err != nil {
+		t.Fatalf("decode call frame: %v", err)
+	}
+	if frame.Type != "CALL" || !strings.EqualFold(frame.To, contract.Hex()) {
+		t.Fatalf("unexpected call frame: %s", raw)
+	}
+	if !strings.HasSuffix(frame.Output, "2a") {
+		t.Fatalf("expected output to end in 0x2a, got %s", frame.Output)
+	}
+
+	raw, err = sp.TraceCall(CallMsg{To: contract.Bytes()}, TraceConfig{})
+	if err != nil {
+		t.Fatalf("struct logger: %v", err)
+	}
+	var logs struct {
+		Failed     bool              `json:"failed"`
+		StructLogs []json.RawMessage `json:"structLogs"`
+	}
+	if err := json.Unmarshal(raw, &logs); err != nil {
+		t.Fatalf("decode struct logs: %v", err)
+	}
+	if logs.Failed || len(logs.StructLogs) != 6 {
+		t.Fatalf("expected 6 opcode steps, got %d (failed=%v)", len(logs.StructLogs), logs.Failed)
+	}
+
+	raw, err = sp.TraceCall(CallMsg{To: contract.Bytes(), Gas: 1 << 62}, TraceConfig{Tracer: "callTracer"})
+	if err != nil {
+		t.Fatalf("call tracer with oversized gas: %v", err)
+	}
+	var capped struct {
+		Gas string `json:"gas"`
+	}
+	if err := json.Unmarshal(raw, &capped); err != nil {
+		t.Fatalf("decode capped frame: %v", err)
+	}
+	if capped.Gas != hexutil.EncodeUint64(BlockGasLimit) {
+		t.Fatalf("expected gas clamped to the block gas limit, got %s", capped.Gas)
+	}
+
+	if _, err := sp.TraceCall(CallMsg{To: contract.Bytes()}, TraceConfig{Tracer: "noSuchTracer"}); err == nil {
+		t.Fatalf("expected unknown tracer error")
+	}
+}
+
+func TestNodeTraceTransactionReplaysPrecedingTransactions(t *testing.T) {
+	node := newTestNode(t)
+	node.SetTransactionSimulationEnabled(false)
+
+	senderKey, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate sender key: %v", err)
+	}
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000d0")
+	ensureAccountState(t, node, senderKey, 0)
+	sender := senderKey.PubKey().Address().Bytes()
+
+	txs := make([]*types.Transaction, 0, 2)
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx := &types.Transaction{
+			ChainID:  types.NHBChainID(),
+			Type:     types.TxTypeTransfer,
+			Nonce:    nonce,
+			To:       recipient.Bytes(),
+			Value:    big.NewInt(1_000),
+			GasLimit: 21_000,
+			GasPrice: big.NewInt(1),
+		}
+		if err := tx.Sign(senderKey.PrivateKey); err != nil {
+			t.Fatalf("sign transfer: %v", err)
+		}
+		txs = append(txs, tx)
+	}
+	block, err := node.CreateBlock(txs)
+	if err != nil {
+		t.Fatalf("create block: %v", err)
+	}
+	if err := node.CommitBlock(block); err != nil {
+		t.Fatalf("commit block: %v", err)
+	}
+
+	hash, err := txs[1].Hash()
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	raw, err := node.TraceTransaction(hash, TraceConfig{Tracer: "prestateTracer"})
+	if err != nil {
+		t.Fatalf("trace transaction: %v", err)
+	}
+	var prestate map[string]struct {
+		Balance string `json:"balance"`
+	}
+	if err := json.Unmarshal(raw, &prestate); err != nil {
+		t.Fatalf("decode prestate: %v", err)
+	}
+	senderState, ok := prestate[strings.ToLower(common.BytesToAddress(sender).Hex())]
+	if !ok {
+		t.Fatalf("sender missing from prestate: %s", raw)
+	}
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(senderState.Balance, "0x"), 16)
+	if !ok {
+		t.Fatalf("invalid balance %q", senderState.Balance)
+	}
+	if balance.Cmp(big.NewInt(1_000_000_000_000-1_000)) > 0 {
+		t.Fatalf("expected first transfer to be replayed before trace, sender balance %s", balance)
+	}
+
+	if _, err := node.TraceTransaction(make([]byte, 32), TraceConfig{}); err == nil {
+		t.Fatalf("expected unknown transaction error")
+	}
+}
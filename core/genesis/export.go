@@ -0,0 +1,1204 @@
+// core/genesis/export.go
+package genesis
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"nhbchain/consensus/store"
+	"nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/native/escrow"
+	"nhbchain/native/governance"
+	"nhbchain/native/loyalty"
+	"nhbchain/native/reputation"
+	"nhbchain/storage"
+	"nhbchain/storage/trie"
+)
+
+// ExportOptions carries the genesis settings that are not part of chain state.
+type ExportOptions struct {
+	// GenesisTime becomes the exported genesisTime. It also selects the
+	// calendar year whose emission counters are exported.
+	GenesisTime time.Time
+	// AdminWallet and the buyback signer quorum are copied from the original
+	// genesis file.
+	AdminWallet            string
+	BuybackSigners         []string
+	BuybackSignerThreshold uint32
+	// Roles lists the role names to export. Role membership is not indexed
+	// by name.
+	Roles []string
+	// Params lists the governance parameter store keys to export.
+	Params []string
+}
+
+// ExportSpec walks the state committed at stateRoot and returns a genesis spec
+// that BuildGenesisFromSpec turns back into the same module state. The result
+// is deterministic for a given root and options. ChainID is left unset
+// because it is derived from the genesis hash.
+func ExportSpec(db storage.Database, stateRoot []byte, opts ExportOptions) (*GenesisSpec, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database must not be nil")
+	}
+	if opts.GenesisTime.IsZero() {
+		return nil, fmt.Errorf("genesis time must be provided")
+	}
+	stateTrie, err := trie.NewTrie(db, stateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("open state at %x: %w", stateRoot, err)
+	}
+	manager := state.NewManager(stateTrie)
+	spec := &GenesisSpec{
+		GenesisTime:            opts.GenesisTime.UTC().Format(time.RFC3339),
+		Alloc:                  make(map[string]map[string]string),
+		Roles:                  make(map[string][]string),
+		AdminWallet:            opts.AdminWallet,
+		BuybackSigners:         append([]string(nil), opts.BuybackSigners...),
+		BuybackSignerThreshold: opts.BuybackSignerThreshold,
+	}
+
+	symbols, err := manager.TokenList()
+	if err != nil {
+		return nil, fmt.Errorf("list tokens: %w", err)
+	}
+	symbols = append([]string(nil), symbols...)
+	sort.Strings(symbols)
+	if err := exportTokens(manager, symbols, spec); err != nil {
+		return nil, err
+	}
+	if err := auditIssuedAssets(manager); err != nil {
+		return nil, err
+	}
+	accounts, err := exportedAccountList(db, manager)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+	if err := exportAccounts(manager, accounts, genesisTokens(spec), spec); err != nil {
+		return nil, err
+	}
+	if spec.IssuedAssets, err = exportIssuedAssets(manager); err != nil {
+		return nil, fmt.Errorf("issued assets: %w", err)
+	}
+	if err := exportValidators(db, spec); err != nil {
+		return nil, err
+	}
+	if spec.Staking, err = exportStaking(manager, symbols, uint32(opts.GenesisTime.UTC().Year())); err != nil {
+		return nil, fmt.Errorf("staking: %w", err)
+	}
+	if spec.Escrow, err = exportEscrow(manager, symbols); err != nil {
+		return nil, fmt.Errorf("escrow: %w", err)
+	}
+	if err := exportLoyalty(manager, spec); err != nil {
+		return nil, fmt.Errorf("loyalty: %w", err)
+	}
+	if spec.Lending, err = exportLending(manager, accounts); err != nil {
+		return nil, fmt.Errorf("lending: %w", err)
+	}
+	if spec.Governance, err = exportGovernance(manager, opts.Params); err != nil {
+		return nil, fmt.Errorf("governance: %w", err)
+	}
+	if spec.Identity, err = exportIdentity(manager); err != nil {
+		return nil, fmt.Errorf("identity: %w", err)
+	}
+	if spec.Invoices, err = exportInvoices(manager); err != nil {
+		return nil, fmt.Errorf("invoices: %w", err)
+	}
+	if spec.Mandates, err = exportMandates(manager); err != nil {
+		return nil, fmt.Errorf("mandates: %w", err)
+	}
+	if spec.SessionKeys, err = exportSessionKeys(manager); err != nil {
+		return nil, fmt.Errorf("session keys: %w", err)
+	}
+	if spec.Recovery, err = exportRecovery(manager, accounts); err != nil {
+		return nil, fmt.Errorf("recovery: %w", err)
+	}
+	if spec.Creator, err = exportCreator(manager); err != nil {
+		return nil, fmt.Errorf("creator: %w", err)
+	}
+	if spec.EngagementDevices, err = exportEngagementDevices(manager); err != nil {
+		return nil, fmt.Errorf("engagement devices: %w", err)
+	}
+	if spec.Reputation, err = exportReputation(manager); err != nil {
+		return nil, fmt.Errorf("reputation: %w", err)
+	}
+	for _, role := range opts.Roles {
+		members, err := manager.RoleMembers(role)
+		if err != nil {
+			return nil, fmt.Errorf("role %q: %w", role, err)
+		}
+		if len(members) == 0 {
+			continue
+		}
+		addrs := make([]string, 0, len(members))
+		for _, member := range members {
+			addrs = append(addrs, formatAddress(member))
+		}
+		sort.Strings(addrs)
+		spec.Roles[role] = addrs
+	}
+	return spec, nil
+}
+
+func exportTokens(manager *state.Manager, symbols []string, spec *GenesisSpec) error {
+	for _, symbol := range symbols {
+		meta, err := manager.Token(symbol)
+		if err != nil {
+			return fmt.Errorf("token %q: %w", symbol, err)
+		}
+		if meta == nil || meta.IsIssuedAsset() {
+			continue
+		}
+		supply, err := manager.TokenSupply(symbol)
+		if err != nil {
+			return fmt.Errorf("token %q supply: %w", symbol, err)
+		}
+		token := NativeTokenSpec{
+			Symbol:        meta.Symbol,
+			Name:          meta.Name,
+			Decimals:      meta.Decimals,
+			MintAuthority: formatAddress(meta.MintAuthority),
+			Supply:        formatAmount(supply),
+		}
+		if meta.MintPaused {
+			paused := true
+			token.InitialMintPaused = &paused
+		}
+		spec.NativeTokens = append(spec.NativeTokens, token)
+	}
+	return nil
+}
+
+// genesisTokens returns the symbols exported as native tokens. Issued asset
+// balances are exported with the asset rather than through Alloc.
+func genesisTokens(spec *GenesisSpec) []string {
+	symbols := make([]string, 0, len(spec.NativeTokens))
+	for _, token := range spec.NativeTokens {
+		symbols = append(symbols, token.Symbol)
+	}
+	return symbols
+}
+
+func exportIssuedAssets(manager *state.Manager) ([]IssuedAssetSpec, error) {
+	assets, err := manager.IssuedAssets()
+	if err != nil {
+		return nil, err
+	}
+	var out []IssuedAssetSpec
+	for _, meta := range assets {
+		supply, err := manager.TokenSupply(meta.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%s supply: %w", meta.Symbol, err)
+		}
+		asset := IssuedAssetSpec{
+			Symbol:          meta.Symbol,
+			Name:            meta.Name,
+			Decimals:        meta.Decimals,
+			Issuer:          formatAddress(meta.Issuer),
+			MintAuthority:   formatAddress(meta.MintAuthority),
+			FreezeAuthority: formatAddress(meta.FreezeAuthority),
+			SupplyCap:       formatAmount(meta.SupplyCap),
+			Minted:          formatAmount(meta.Minted),
+			Burned:          formatAmount(meta.Burned),
+			MintPaused:      meta.MintPaused,
+			Supply:          formatAmount(supply),
+		}
+		holders, err := manager.AssetHolders(meta.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%s holders: %w", meta.Symbol, err)
+		}
+		for _, holder := range holders {
+			balance, err := manager.Balance(holder, meta.Symbol)
+			if err != nil {
+				return nil, fmt.Errorf("%s balance of %x: %w", meta.Symbol, holder, err)
+			}
+			if value := formatAmount(balance); value != "" {
+				asset.Holders = append(asset.Holders, AssetHolderSpec{Address: formatAddress(holder), Balance: value})
+			}
+		}
+		frozen, err := manager.AssetFrozenAccounts(meta.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%s freezes: %w", meta.Symbol, err)
+		}
+		for _, addr := range frozen {
+			asset.Frozen = append(asset.Frozen, formatAddress(addr))
+		}
+		out = append(out, asset)
+	}
+	return out, nil
+}
+
+// auditIssuedAssets walks every issued asset's holder index and checks the
+// summed balances against the running total and the supply before anything is
+// exported.
+func auditIssuedAssets(manager *state.Manager) error {
+	assets, err := manager.IssuedAssets()
+	if err != nil {
+		return fmt.Errorf("list issued assets: %w", err)
+	}
+	for _, meta := range assets {
+		held, err := manager.AuditAssetHolders(meta.Symbol)
+		if err != nil {
+			return fmt.Errorf("audit %s: %w", meta.Symbol, err)
+		}
+		supply, err := manager.TokenSupply(meta.Symbol)
+		if err != nil {
+			return fmt.Errorf("token %q supply: %w", meta.Symbol, err)
+		}
+		if held.Cmp(supply) != 0 {
+			return fmt.Errorf("audit %s: holders hold %s but supply is %s", meta.Symbol, held, supply)
+		}
+	}
+	return nil
+}
+
+// exportedAccountList returns the indexed accounts plus every validator. The
+// genesis loader grants validators stake without indexing their accounts, so
+// they would otherwise be missed.
+func exportedAccountList(db storage.Database, manager *state.Manager) ([][20]byte, error) {
+	indexed, err := manager.AccountList()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[[20]byte]struct{}, len(indexed))
+	accounts := append([][20]byte(nil), indexed...)
+	for _, addr := range indexed {
+		seen[addr] = struct{}{}
+	}
+	add := func(raw []byte) {
+		var addr [20]byte
+		if len(raw) != len(addr) {
+			return
+		}
+		copy(addr[:], raw)
+		if _, ok := seen[addr]; ok {
+			return
+		}
+		seen[addr] = struct{}{}
+		accounts = append(accounts, addr)
+	}
+	validatorSet, err := manager.LoadValidatorSet()
+	if err != nil {
+		return nil, err
+	}
+	eligible, err := manager.LoadEligibleValidatorSet()
+	if err != nil {
+		return nil, err
+	}
+	for _, set := range []map[string]*big.Int{validatorSet, eligible} {
+		for key := range set {
+			add([]byte(key))
+		}
+	}
+	consensusValidators, err := store.New(db).Validators()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range consensusValidators {
+		add(v.Address)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return bytes.Compare(accounts[i][:], accounts[j][:]) < 0
+	})
+	return accounts, nil
+}
+
+func exportAccounts(manager *state.Manager, accounts [][20]byte, symbols []string, spec *GenesisSpec) error {
+	spec.Accounts = make(map[string]*AccountStateSpec, len(accounts))
+	for _, addr := range accounts {
+		addrStr := formatAddress(addr[:])
+		account, err := manager.GetAccount(addr[:])
+		if err != nil {
+			return fmt.Errorf("account %s: %w", addrStr, err)
+		}
+		balances := make(map[string]string)
+		for _, symbol := range symbols {
+			var amount *big.Int
+			switch symbol {
+			case "NHB":
+				amount = account.BalanceNHB
+			case "ZNHB":
+				amount = account.BalanceZNHB
+			default:
+				if amount, err = manager.Balance(addr[:], symbol); err != nil {
+					return fmt.Errorf("account %s balance %s: %w", addrStr, symbol, err)
+				}
+			}
+			if value := formatAmount(amount); value != "" {
+				balances[symbol] = value
+			}
+		}
+		if len(balances) > 0 {
+			spec.Alloc[addrStr] = balances
+		}
+
+		accountSpec := newAccountStateSpec(account)
+		escrowed, err := manager.GovernanceEscrowBalance(addr[:])
+		if err != nil {
+			return fmt.Errorf("account %s governance escrow: %w", addrStr, err)
+		}
+		accountSpec.GovernanceEscrow = formatAmount(escrowed)
+		accrued, err := manager.LoyaltyBaseTotalAccrued(addr[:])
+		if err != nil {
+			return fmt.Errorf("account %s loyalty accrual: %w", addrStr, err)
+		}
+		accountSpec.LoyaltyBaseAccrued = formatAmount(accrued)
+		spec.Accounts[addrStr] = accountSpec
+	}
+	return nil
+}
+
+func newAccountStateSpec(account *types.Account) *AccountStateSpec {
+	spec := &AccountStateSpec{
+		Nonce:              account.Nonce,
+		Stake:              formatAmount(account.Stake),
+		StakeShares:        formatAmount(account.StakeShares),
+		StakeLastIndex:     formatAmount(account.StakeLastIndex),
+		StakeLastPayoutTs:  account.StakeLastPayoutTs,
+		LockedZNHB:         formatAmount(account.LockedZNHB),
+		DelegatedValidator: formatAddress(account.DelegatedValidator),
+		RewardBeneficiary:  formatAddress(account.RewardBeneficiary),
+		NextUnbondingID:    account.NextUnbondingID,
+		Username:           account.Username,
+	}
+	for _, unbond := range account.PendingUnbonds {
+		amount := "0"
+		if unbond.Amount != nil {
+			amount = unbond.Amount.String()
+		}
+		spec.PendingUnbonds = append(spec.PendingUnbonds, UnbondSpec{
+			ID:          unbond.ID,
+			Validator:   formatAddress(unbond.Validator),
+			Amount:      amount,
+			ReleaseTime: unbond.ReleaseTime,
+		})
+	}
+	engagement := EngagementSpec{
+		Score:         account.EngagementScore,
+		Day:           account.EngagementDay,
+		Minutes:       account.EngagementMinutes,
+		TxCount:       account.EngagementTxCount,
+		EscrowEvents:  account.EngagementEscrowEvents,
+		GovEvents:     account.EngagementGovEvents,
+		LastHeartbeat: account.EngagementLastHeartbeat,
+	}
+	if engagement != (EngagementSpec{}) {
+		spec.Engagement = &engagement
+	}
+	lendingSpec := AccountLendingSpec{
+		CollateralBalance:  formatAmount(account.CollateralBalance),
+		DebtPrincipal:      formatAmount(account.DebtPrincipal),
+		SupplyShares:       formatAmount(account.SupplyShares),
+		SupplyIndex:        formatAmount(account.LendingSnapshot.SupplyIndex),
+		BorrowIndex:        formatAmount(account.LendingSnapshot.BorrowIndex),
+		CollateralDisabled: account.LendingBreaker.CollateralDisabled,
+		BorrowDisabled:     account.LendingBreaker.BorrowDisabled,
+	}
+	if lendingSpec != (AccountLendingSpec{}) {
+		spec.Lending = &lendingSpec
+	}
+	rewards := StakingRewardsSpec{
+		AccruedZNHB:    formatAmount(account.StakingRewards.AccruedZNHB),
+		LastIndex:      formatHash(account.StakingRewards.LastIndexUQ128x128[:]),
+		LastPayoutUnix: account.StakingRewards.LastPayoutUnix,
+	}
+	if rewards != (StakingRewardsSpec{}) {
+		spec.StakingRewards = &rewards
+	}
+	return spec
+}
+
+func exportValidators(db storage.Database, spec *GenesisSpec) error {
+	validators, err := store.New(db).Validators()
+	if err != nil {
+		return fmt.Errorf("load consensus validators: %w", err)
+	}
+	for _, v := range validators {
+		validator := ValidatorSpec{
+			Address: formatAddress(v.Address),
+			Power:   v.Power,
+			Moniker: v.Moniker,
+		}
+		if len(v.PubKey) > 0 {
+			validator.PubKey = hex.EncodeToString(v.PubKey)
+		}
+		spec.Validators = append(spec.Validators, validator)
+	}
+	sort.Slice(spec.Validators, func(i, j int) bool {
+		return spec.Validators[i].Address < spec.Validators[j].Address
+	})
+	return nil
+}
+
+func exportStaking(manager *state.Manager, symbols []string, year uint32) (*StakingStateSpec, error) {
+	index, err := manager.GetGlobalIndex()
+	if err != nil {
+		return nil, err
+	}
+	stakingYTD, err := manager.StakingEmissionYTD(year)
+	if err != nil {
+		return nil, err
+	}
+	spec := &StakingStateSpec{
+		GlobalIndex:        formatHash(index.UQ128x128),
+		LastIndexUpdate:    index.LastUpdateUnix,
+		YTDEmissions:       formatAmount(index.YTDEmissions),
+		EmissionYear:       year,
+		StakingEmissionYTD: formatAmount(stakingYTD),
+	}
+	for _, symbol := range symbols {
+		minted, err := manager.MintEmissionYTD(symbol, year)
+		if err != nil {
+			return nil, err
+		}
+		if value := formatAmount(minted); value != "" {
+			if spec.MintEmissionYTD == nil {
+				spec.MintEmissionYTD = make(map[string]string)
+			}
+			spec.MintEmissionYTD[symbol] = value
+		}
+	}
+	validatorSet, err := manager.LoadValidatorSet()
+	if err != nil {
+		return nil, err
+	}
+	spec.ValidatorSet = formatValidatorSet(validatorSet)
+	eligible, err := manager.LoadEligibleValidatorSet()
+	if err != nil {
+		return nil, err
+	}
+	spec.EligibleValidators = formatValidatorSet(eligible)
+	return spec, nil
+}
+
+func newRealmFeeScheduleSpec(schedule *escrow.RealmFeeSchedule) *RealmFeeScheduleSpec {
+	if schedule == nil {
+		return nil
+	}
+	return &RealmFeeScheduleSpec{FeeBps: schedule.FeeBps, Recipient: formatAddress(schedule.Recipient[:]), ArbitratorFeeBps: schedule.ArbitratorFeeBps}
+}
+
+func newArbitratorSetSpec(set *escrow.ArbitratorSet) *ArbitratorSetSpec {
+	if set == nil {
+		return nil
+	}
+	return &ArbitratorSetSpec{Scheme: uint8(set.Scheme), Threshold: set.Threshold, Members: formatMembers(set.Members)}
+}
+
+func newRealmMetadataSpec(metadata *escrow.EscrowRealmMetadata) *RealmMetadataSpec {
+	if metadata == nil {
+		return nil
+	}
+	return &RealmMetadataSpec{
+		Scope:             uint8(metadata.Scope),
+		ProviderProfile:   metadata.ProviderProfile,
+		ArbitrationFeeBps: metadata.ArbitrationFeeBps,
+		FeeRecipient:      metadata.FeeRecipientBech32,
+	}
+}
+
+func formatMembers(members [][20]byte) []string {
+	out := make([]string, 0, len(members))
+	for _, member := range members {
+		out = append(out, formatAddress(member[:]))
+	}
+	return out
+}
+
+// exportEscrow exports every realm and every escrow with its vault balances.
+func exportEscrow(manager *state.Manager, symbols []string) (*EscrowStateSpec, error) {
+	ids, err := manager.EscrowList()
+	if err != nil {
+		return nil, err
+	}
+	storedRealms, err := manager.EscrowRealmList()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 && len(storedRealms) == 0 {
+		return nil, nil
+	}
+	spec := &EscrowStateSpec{}
+	realmIDs := make(map[string]struct{}, len(storedRealms))
+	for _, realmID := range storedRealms {
+		realmIDs[realmID] = struct{}{}
+	}
+	for _, id := range ids {
+		e, ok := manager.EscrowGet(id)
+		if !ok {
+			return nil, fmt.Errorf("escrow %x could not be loaded", id)
+		}
+		record := EscrowRecordSpec{
+			ID:             "0x" + hex.EncodeToString(e.ID[:]),
+			Payer:          formatAddress(e.Payer[:]),
+			Payee:          formatAddress(e.Payee[:]),
+			Mediator:       formatAddress(e.Mediator[:]),
+			Token:          e.Token,
+			Amount:         e.Amount.String(),
+			FeeBps:         e.FeeBps,
+			Deadline:       e.Deadline,
+			CreatedAt:      e.CreatedAt,
+			Nonce:          e.Nonce,
+			MetaHash:       formatHash(e.MetaHash[:]),
+			Status:         uint8(e.Status),
+			RealmID:        e.RealmID,
+			ResolutionHash: formatHash(e.ResolutionHash[:]),
+			DisputeReason:  e.DisputeReason,
+			DisputedAt:     e.DisputedAt,
+			EscalatedAt:    e.EscalatedAt,
+		}
+		for _, item := range e.Evidence {
+			record.Evidence = append(record.Evidence, EvidenceSpec{
+				Submitter:   formatAddress(item.Submitter[:]),
+				Hash:        formatHash(item.Hash[:]),
+				URI:         item.URI,
+				SubmittedAt: item.SubmittedAt,
+			})
+		}
+		if f := e.FrozenArb; f != nil {
+			record.FrozenArb = &FrozenArbSpec{
+				RealmID:      f.RealmID,
+				RealmVersion: f.RealmVersion,
+				PolicyNonce:  f.PolicyNonce,
+				Scheme:       uint8(f.Scheme),
+				Threshold:    f.Threshold,
+				Members:      formatMembers(f.Members),
+				FrozenAt:     f.FrozenAt,
+				FeeSchedule:  newRealmFeeScheduleSpec(f.FeeSchedule),
+				Metadata:     newRealmMetadataSpec(f.Metadata),
+
+				EvidenceWindowSeconds: f.EvidenceWindowSeconds,
+				ArbitrationSLASeconds: f.ArbitrationSLASeconds,
+				Fallback:              newArbitratorSetSpec(f.Fallback),
+			}
+		}
+		for _, token := range symbols {
+			balance, err := manager.EscrowBalance(id, token)
+			if err != nil {
+				return nil, fmt.Errorf("escrow %x balance %s: %w", id, token, err)
+			}
+			if value := formatAmount(balance); value != "" {
+				if record.Balances == nil {
+					record.Balances = make(map[string]string)
+				}
+				record.Balances[token] = value
+			}
+		}
+		if e.RealmID != "" {
+			realmIDs[e.RealmID] = struct{}{}
+		}
+		spec.Escrows = append(spec.Escrows, record)
+	}
+	for _, realmID := range sortedKeys(realmIDs) {
+		realm, ok, err := manager.EscrowRealmGet(realmID)
+		if err != nil {
+			return nil, fmt.Errorf("realm %q: %w", realmID, err)
+		}
+		if !ok {
+			continue
+		}
+		realmSpec := EscrowRealmSpec{
+			ID:              realm.ID,
+			Version:         realm.Version,
+			NextPolicyNonce: realm.NextPolicyNonce,
+			CreatedAt:       realm.CreatedAt,
+			UpdatedAt:       realm.UpdatedAt,
+			FeeSchedule:     newRealmFeeScheduleSpec(realm.FeeSchedule),
+			Metadata:        newRealmMetadataSpec(realm.Metadata),
+
+			EvidenceWindowSeconds: realm.EvidenceWindowSeconds,
+			ArbitrationSLASeconds: realm.ArbitrationSLASeconds,
+			Fallback:              newArbitratorSetSpec(realm.FallbackArbitrators),
+			MinReputationScore:    realm.MinReputationScore,
+		}
+		if realm.Arbitrators != nil {
+			realmSpec.Scheme = uint8(realm.Arbitrators.Scheme)
+			realmSpec.Threshold = realm.Arbitrators.Threshold
+			realmSpec.Members = formatMembers(realm.Arbitrators.Members)
+		}
+		spec.Realms = append(spec.Realms, realmSpec)
+	}
+	return spec, nil
+}
+
+func exportLoyalty(manager *state.Manager, spec *GenesisSpec) error {
+	cfg, err := manager.LoyaltyGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if cfg != nil {
+		spec.LoyaltyGlobal = newLoyaltyGlobalSpec(cfg)
+	}
+	out := &LoyaltyStateSpec{}
+	dynamic, err := manager.LoyaltyDynamicState()
+	if err != nil {
+		return err
+	}
+	if dynamic != nil {
+		out.Controller = &LoyaltyControllerSpec{
+			EffectiveBps:     dynamic.EffectiveBps,
+			TargetBps:        dynamic.TargetBps,
+			MinBps:           dynamic.MinBps,
+			MaxBps:           dynamic.MaxBps,
+			SmoothingStepBps: dynamic.SmoothingStepBps,
+			YtdEmissionsZNHB: formatAmount(dynamic.YtdEmissionsZNHB),
+			YearlyCapZNHB:    formatAmount(dynamic.YearlyCapZNHB),
+		}
+	}
+	registry := loyalty.NewRegistry(manager)
+	businesses, err := registry.ListBusinesses()
+	if err != nil {
+		return fmt.Errorf("list businesses: %w", err)
+	}
+	for _, business := range businesses {
+		active, ok, err := registry.ActivePaymasterBusiness(business.Owner)
+		if err != nil {
+			return fmt.Errorf("business %x paymaster: %w", business.ID, err)
+		}
+		out.Businesses = append(out.Businesses, LoyaltyBusinessSpec{
+			ID:                  formatHash(business.ID[:]),
+			Owner:               formatAddress(business.Owner[:]),
+			Name:                business.Name,
+			Paymaster:           formatAddress(business.Paymaster[:]),
+			PaymasterReserveMin: formatAmount(business.PaymasterReserveMin),
+			ActivePaymaster:     ok && active == business.ID,
+			Merchants:           formatMembers(business.Merchants),
+		})
+	}
+	programIDs, err := registry.ListPrograms()
+	if err != nil {
+		return fmt.Errorf("list programs: %w", err)
+	}
+	for _, id := range programIDs {
+		program, ok := registry.GetProgram(id)
+		if !ok {
+			return fmt.Errorf("program %x could not be loaded", id)
+		}
+		out.Programs = append(out.Programs, newLoyaltyProgramSpec(program))
+	}
+	if out.Controller != nil || len(out.Businesses) > 0 || len(out.Programs) > 0 {
+		spec.Loyalty = out
+	}
+	return nil
+}
+
+func newLoyaltyProgramSpec(p *loyalty.Program) LoyaltyProgramSpec {
+	spec := LoyaltyProgramSpec{
+		ID:                 formatHash(p.ID[:]),
+		Owner:              formatAddress(p.Owner[:]),
+		Pool:               formatAddress(p.Pool[:]),
+		TokenSymbol:        p.TokenSymbol,
+		AccrualBps:         p.AccrualBps,
+		MinSpendWei:        formatAmount(p.MinSpendWei),
+		CapPerTx:           formatAmount(p.CapPerTx),
+		DailyCapUser:       formatAmount(p.DailyCapUser),
+		DailyCapProgram:    formatAmount(p.DailyCapProgram),
+		EpochCapProgram:    formatAmount(p.EpochCapProgram),
+		EpochLengthSeconds: p.EpochLengthSeconds,
+		IssuanceCapUser:    formatAmount(p.IssuanceCapUser),
+		StartTime:          p.StartTime,
+		EndTime:            p.EndTime,
+		Active:             p.Active,
+		TierWindowDays:     p.TierWindowDays,
+		RewardExpiryDays:   p.RewardExpiryDays,
+	}
+	for _, tier := range p.Tiers {
+		spec.Tiers = append(spec.Tiers, LoyaltyTierSpec{
+			Name:        tier.Name,
+			MinSpendWei: formatAmount(tier.MinSpendWei),
+			AccrualBps:  tier.AccrualBps,
+		})
+	}
+	return spec
+}
+
+// newLoyaltyGlobalSpec is the inverse of LoyaltyGlobalSpec.Config. The seed
+// is zero because the treasury balance is already part of Alloc.
+func newLoyaltyGlobalSpec(cfg *loyalty.GlobalConfig) *LoyaltyGlobalSpec {
+	amount := func(v *big.Int) string {
+		if v == nil {
+			return "0"
+		}
+		return v.String()
+	}
+	d := cfg.Dynamic
+	spec := &LoyaltyGlobalSpec{
+		Active:               cfg.Active,
+		Treasury:             formatAddress(cfg.Treasury),
+		BaseBps:              cfg.BaseBps,
+		MinSpend:             amount(cfg.MinSpend),
+		CapPerTx:             amount(cfg.CapPerTx),
+		DailyCapUser:         amount(cfg.DailyCapUser),
+		DailyCapCounterparty: amount(cfg.DailyCapCounterparty),
+		SeedZNHB:             "0",
+		Dynamic: LoyaltyDynamicSpec{
+			TargetBps:                   d.TargetBps,
+			MinBps:                      d.MinBps,
+			MaxBps:                      d.MaxBps,
+			SmoothingStepBps:            d.SmoothingStepBps,
+			CoverageMax:                 float64(d.CoverageMaxBps) / loyalty.BaseRewardBpsDenominator,
+			CoverageLookbackDays:        d.CoverageLookbackDays,
+			DailyCapPctOf7dFees:         float64(d.DailyCapPctOf7dFeesBps) / loyalty.BaseRewardBpsDenominator,
+			DailyCapUSD:                 float64(d.DailyCapUsd),
+			YearlyCapPctOfInitialSupply: float64(d.YearlyCapPctOfInitialSupplyBps) / 100,
+			PriceGuard: LoyaltyPriceGuardSpec{
+				Enabled:                    d.PriceGuard.Enabled,
+				PricePair:                  d.PriceGuard.PricePair,
+				TwapWindowSeconds:          d.PriceGuard.TwapWindowSeconds,
+				MaxDeviationBps:            d.PriceGuard.MaxDeviationBps,
+				PriceMaxAgeSeconds:         d.PriceGuard.PriceMaxAgeSeconds,
+				FallbackMinEmissionZNHBWei: amount(d.PriceGuard.FallbackMinEmissionZNHB),
+				UseLastGoodPriceFallback:   d.PriceGuard.UseLastGoodPriceFallback,
+			},
+		},
+	}
+	if d.EnableProRateSet {
+		enabled := d.EnableProRate
+		spec.Dynamic.EnableProRate = &enabled
+	}
+	if d.EnforceProRateSet {
+		enforced := d.EnforceProRate
+		spec.Dynamic.EnforceProRate = &enforced
+	}
+	return spec
+}
+
+func exportLending(manager *state.Manager, accounts [][20]byte) (*LendingStateSpec, error) {
+	poolIDs, err := manager.LendingListPoolIDs()
+	if err != nil {
+		return nil, err
+	}
+	if len(poolIDs) == 0 {
+		return nil, nil
+	}
+	poolIDs = append([]string(nil), poolIDs...)
+	sort.Strings(poolIDs)
+	spec := &LendingStateSpec{}
+	for _, poolID := range poolIDs {
+		market, ok, err := manager.LendingGetMarket(poolID)
+		if err != nil {
+			return nil, fmt.Errorf("market %q: %w", poolID, err)
+		}
+		if !ok {
+			continue
+		}
+		marketSpec := LendingMarketSpec{
+			PoolID:                market.PoolID,
+			DeveloperOwner:        formatAddress(market.DeveloperOwner.Bytes()),
+			DeveloperFeeCollector: formatAddress(market.DeveloperFeeCollector.Bytes()),
+			DeveloperFeeBps:       market.DeveloperFeeBps,
+			TotalNHBSupplied:      formatAmount(market.TotalNHBSupplied),
+			TotalSupplyShares:     formatAmount(market.TotalSupplyShares),
+			TotalNHBBorrowed:      formatAmount(market.TotalNHBBorrowed),
+			SupplyIndex:           formatAmount(market.SupplyIndex),
+			BorrowIndex:           formatAmount(market.BorrowIndex),
+			LastUpdateBlock:       market.LastUpdateBlock,
+			ReserveFactor:         market.ReserveFactor,
+			BorrowAsset:           market.BorrowAsset,
+			BorrowOracleFeed:      market.BorrowOracleFeed,
+			OracleMaxAgeSeconds:   market.OracleMaxAgeSeconds,
+			TotalCollateral:       formatLendingAssetAmounts(market.TotalCollateral),
+		}
+		for _, cfg := range market.Collateral {
+			marketSpec.Collateral = append(marketSpec.Collateral, LendingCollateralSpec{
+				Asset:                cfg.Asset,
+				MaxLTV:               cfg.MaxLTV,
+				LiquidationThreshold: cfg.LiquidationThreshold,
+				OracleFeed:           cfg.OracleFeed,
+				SupplyCap:            formatAmount(cfg.SupplyCap),
+			})
+		}
+		fees, ok, err := manager.LendingGetFeeAccrual(poolID)
+		if err != nil {
+			return nil, fmt.Errorf("market %q fees: %w", poolID, err)
+		}
+		if ok && fees != nil {
+			marketSpec.ProtocolFees = formatAmount(fees.ProtocolFeesWei)
+			marketSpec.DeveloperFees = formatAmount(fees.DeveloperFeesWei)
+		}
+		for _, addr := range accounts {
+			user, ok, err := manager.LendingGetUserAccount(poolID, addr)
+			if err != nil {
+				return nil, fmt.Errorf("market %q user %x: %w", poolID, addr, err)
+			}
+			if !ok || user == nil {
+				continue
+			}
+			marketSpec.Users = append(marketSpec.Users, LendingUserSpec{
+				Address:      formatAddress(addr[:]),
+				Collateral:   formatLendingAssetAmounts(user.Collateral),
+				SupplyShares: formatAmount(user.SupplyShares),
+				DebtNHB:      formatAmount(user.DebtNHB),
+				ScaledDebt:   formatAmount(user.ScaledDebt),
+			})
+		}
+		spec.Markets = append(spec.Markets, marketSpec)
+	}
+	return spec, nil
+}
+
+func formatLendingAssetAmounts(amounts map[string]*big.Int) map[string]string {
+	var formatted map[string]string
+	for asset, amount := range amounts {
+		value := formatAmount(amount)
+		if value == "" {
+			continue
+		}
+		if formatted == nil {
+			formatted = make(map[string]string)
+		}
+		formatted[asset] = value
+	}
+	return formatted
+}
+
+func exportGovernance(manager *state.Manager, params []string) (*GovernanceStateSpec, error) {
+	spec := &GovernanceStateSpec{}
+	if _, err := manager.KVGet(state.GovernanceSequenceKey(), &spec.ProposalSequence); err != nil {
+		return nil, fmt.Errorf("proposal sequence: %w", err)
+	}
+	for id := uint64(1); id <= spec.ProposalSequence; id++ {
+		proposal, ok, err := manager.GovernanceGetProposal(id)
+		if err != nil {
+			return nil, fmt.Errorf("proposal %d: %w", id, err)
+		}
+		if !ok {
+			continue
+		}
+		proposalSpec := GovernanceProposalSpec{
+			ID:             proposal.ID,
+			Title:          proposal.Title,
+			Summary:        proposal.Summary,
+			MetadataURI:    proposal.MetadataURI,
+			Submitter:      formatAddress(proposal.Submitter.Bytes()),
+			Status:         uint8(proposal.Status),
+			Deposit:        formatAmount(proposal.Deposit),
+			SubmitTime:     proposal.SubmitTime.Unix(),
+			VotingStart:    proposal.VotingStart.Unix(),
+			VotingEnd:      proposal.VotingEnd.Unix(),
+			TimelockEnd:    proposal.TimelockEnd.Unix(),
+			Target:         proposal.Target,
+			ProposedChange: proposal.ProposedChange,
+			Queued:         proposal.Queued,
+		}
+		votes, err := manager.GovernanceListVotes(id)
+		if err != nil {
+			return nil, fmt.Errorf("proposal %d votes: %w", id, err)
+		}
+		for _, vote := range votes {
+			proposalSpec.Votes = append(proposalSpec.Votes, GovernanceVoteSpec{
+				Voter:     formatAddress(vote.Voter.Bytes()),
+				Choice:    vote.Choice.String(),
+				PowerBps:  vote.PowerBps,
+				Timestamp: vote.Timestamp.Unix(),
+			})
+		}
+		spec.Proposals = append(spec.Proposals, proposalSpec)
+	}
+
+	names := map[string]struct{}{governance.ParamKeyMinimumValidatorStake: {}}
+	for _, name := range params {
+		if trimmed := strings.TrimSpace(name); trimmed != "" {
+			names[trimmed] = struct{}{}
+		}
+	}
+	for _, name := range sortedKeys(names) {
+		value, ok, err := manager.ParamStoreGet(name)
+		if err != nil {
+			return nil, fmt.Errorf("param %q: %w", name, err)
+		}
+		if !ok {
+			continue
+		}
+		if spec.Params == nil {
+			spec.Params = make(map[string]string)
+		}
+		spec.Params[name] = "0x" + hex.EncodeToString(value)
+	}
+	if spec.ProposalSequence == 0 && len(spec.Params) == 0 {
+		return nil, nil
+	}
+	return spec, nil
+}
+
+func exportIdentity(manager *state.Manager) (*IdentityStateSpec, error) {
+	records, err := manager.IdentityAliases()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := manager.IdentityListingNonce()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 && nonce == 0 {
+		return nil, nil
+	}
+	spec := &IdentityStateSpec{ListingNonce: nonce}
+	for _, record := range records {
+		alias := IdentityAliasSpec{
+			Alias:     record.Alias,
+			Owner:     formatAddress(record.Owner[:]),
+			Primary:   formatAddress(record.Primary[:]),
+			AvatarRef: record.AvatarRef,
+			CreatedAt: record.CreatedAt,
+			UpdatedAt: record.UpdatedAt,
+			ExpiresAt: record.ExpiresAt,
+			Addresses: formatMembers(record.Addresses),
+		}
+		for _, text := range record.Records {
+			if alias.Records == nil {
+				alias.Records = make(map[string]string, len(record.Records))
+			}
+			alias.Records[text.Key] = text.Value
+		}
+		subs, err := manager.IdentitySubAliases(record.Alias)
+		if err != nil {
+			return nil, fmt.Errorf("alias %q sub-aliases: %w", record.Alias, err)
+		}
+		for _, sub := range subs {
+			alias.SubAliases = append(alias.SubAliases, IdentitySubAliasSpec{
+				Label:     sub.Label,
+				Target:    formatAddress(sub.Target[:]),
+				CreatedAt: sub.CreatedAt,
+				UpdatedAt: sub.UpdatedAt,
+			})
+		}
+		listing, ok, err := manager.IdentityGetListing(record.Alias)
+		if err != nil {
+			return nil, fmt.Errorf("alias %q listing: %w", record.Alias, err)
+		}
+		if ok {
+			alias.Listing = &IdentityListingSpec{
+				Seller:   formatAddress(listing.Seller[:]),
+				Buyer:    formatAddress(listing.Buyer[:]),
+				Price:    formatAmount(listing.Price),
+				Nonce:    listing.Nonce,
+				ListedAt: listing.ListedAt,
+			}
+		}
+		spec.Aliases = append(spec.Aliases, alias)
+	}
+	return spec, nil
+}
+
+func exportInvoices(manager *state.Manager) ([]InvoiceSpec, error) {
+	invoices, err := manager.Invoices()
+	if err != nil {
+		return nil, err
+	}
+	var out []InvoiceSpec
+	for _, inv := range invoices {
+		out = append(out, InvoiceSpec{
+			ID:           "0x" + hex.EncodeToString(inv.ID[:]),
+			Merchant:     formatAddress(inv.Merchant[:]),
+			Asset:        inv.Asset,
+			Amount:       inv.Amount.String(),
+			Paid:         formatAmount(inv.Paid),
+			MemoHash:     formatHash(inv.MemoHash[:]),
+			AllowPartial: inv.AllowPartial,
+			Status:       inv.Status,
+			CreatedAt:    inv.CreatedAt,
+			Expiry:       inv.Expiry,
+			SettledAt:    inv.SettledAt,
+		})
+	}
+	return out, nil
+}
+
+func exportMandates(manager *state.Manager) ([]MandateSpec, error) {
+	mandates, err := manager.Mandates()
+	if err != nil {
+		return nil, err
+	}
+	var out []MandateSpec
+	for _, m := range mandates {
+		out = append(out, MandateSpec{
+			ID:             "0x" + hex.EncodeToString(m.ID[:]),
+			Payer:          formatAddress(m.Payer[:]),
+			Merchant:       formatAddress(m.Merchant[:]),
+			Asset:          m.Asset,
+			MaxAmount:      m.MaxAmount.String(),
+			PeriodSeconds:  m.PeriodSeconds,
+			StartAt:        m.StartAt,
+			EndAt:          m.EndAt,
+			CreatedAt:      m.CreatedAt,
+			Status:         m.Status,
+			LastPullPeriod: m.LastPullPeriod,
+			PulledTotal:    formatAmount(m.PulledTotal),
+			PullCount:      m.PullCount,
+			FailedPulls:    m.FailedPulls,
+			LastFailureAt:  m.LastFailureAt,
+			CancelledAt:    m.CancelledAt,
+		})
+	}
+	return out, nil
+}
+
+func exportSessionKeys(manager *state.Manager) ([]SessionKeySpec, error) {
+	keys, err := manager.SessionKeys()
+	if err != nil {
+		return nil, err
+	}
+	var out []SessionKeySpec
+	for _, key := range keys {
+		spec := SessionKeySpec{
+			Key:            formatAddress(key.Key[:]),
+			Account:        formatAddress(key.Account[:]),
+			DailyLimitNHB:  formatAmount(key.DailyLimitNHB),
+			DailyLimitZNHB: formatAmount(key.DailyLimitZNHB),
+			Counterparties: formatMembers(key.Counterparties),
+			ExpiresAt:      key.ExpiresAt,
+			CreatedAt:      key.CreatedAt,
+			SpentDay:       key.SpentDay,
+			SpentNHB:       formatAmount(key.SpentNHB),
+			SpentZNHB:      formatAmount(key.SpentZNHB),
+		}
+		for _, t := range key.TxTypes {
+			spec.TxTypes = append(spec.TxTypes, uint32(t))
+		}
+		out = append(out, spec)
+	}
+	return out, nil
+}
+
+// exportRecovery exports guardian sets of the exported accounts and every
+// pending rotation. Guardian sets are keyed by account alone; an account has
+// to sign a transaction to register one, so it is always indexed.
+func exportRecovery(manager *state.Manager, accounts [][20]byte) (*RecoveryStateSpec, error) {
+	spec := &RecoveryStateSpec{}
+	for _, addr := range accounts {
+		cfg, ok, err := manager.RecoveryGetConfig(addr)
+		if err != nil {
+			return nil, fmt.Errorf("account %x guardians: %w", addr, err)
+		}
+		if !ok {
+			continue
+		}
+		spec.Guardians = append(spec.Guardians, RecoveryGuardiansSpec{
+			Account:      formatAddress(addr[:]),
+			Guardians:    formatMembers(cfg.Guardians),
+			Threshold:    cfg.Threshold,
+			DelaySeconds: cfg.DelaySeconds,
+			UpdatedAt:    cfg.UpdatedAt,
+		})
+	}
+	pending, err := manager.RecoveryPendingList()
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range pending {
+		pendingSpec := RecoveryPendingSpec{
+			Account:      formatAddress(req.Account[:]),
+			NewAddress:   formatAddress(req.NewAddress[:]),
+			CreatedAt:    req.CreatedAt,
+			ExecutableAt: req.ExecutableAt,
+		}
+		for _, vote := range req.Votes {
+			pendingSpec.Votes = append(pendingSpec.Votes, RecoveryVoteSpec{
+				Guardian:   formatAddress(vote.Guardian[:]),
+				NewAddress: formatAddress(vote.NewAddress[:]),
+			})
+		}
+		spec.Pending = append(spec.Pending, pendingSpec)
+	}
+	if len(spec.Guardians) == 0 && len(spec.Pending) == 0 {
+		return nil, nil
+	}
+	return spec, nil
+}
+
+func exportCreator(manager *state.Manager) (*CreatorStateSpec, error) {
+	tiers, err := manager.CreatorTiers()
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := manager.CreatorSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	if len(tiers) == 0 && len(subscriptions) == 0 {
+		return nil, nil
+	}
+	spec := &CreatorStateSpec{}
+	for _, tier := range tiers {
+		spec.Tiers = append(spec.Tiers, CreatorTierSpec{
+			Creator:       formatAddress(tier.Creator[:]),
+			ID:            tier.ID,
+			Name:          tier.Name,
+			Asset:         tier.Asset,
+			Price:         formatAmount(tier.Price),
+			PeriodSeconds: tier.PeriodSeconds,
+			Active:        tier.Active,
+			UpdatedAt:     tier.UpdatedAt,
+		})
+	}
+	for _, sub := range subscriptions {
+		spec.Subscriptions = append(spec.Subscriptions, CreatorSubscriptionSpec{
+			Creator:       formatAddress(sub.Creator[:]),
+			Fan:           formatAddress(sub.Fan[:]),
+			TierID:        sub.TierID,
+			Asset:         sub.Asset,
+			Price:         formatAmount(sub.Price),
+			PeriodSeconds: sub.PeriodSeconds,
+			StartedAt:     sub.StartedAt,
+			PaidThrough:   sub.PaidThrough,
+			Cancelled:     sub.Cancelled,
+			CancelledAt:   sub.CancelledAt,
+		})
+	}
+	return spec, nil
+}
+
+func exportEngagementDevices(manager *state.Manager) ([]EngagementDeviceSpec, error) {
+	devices, err := manager.EngagementDevices()
+	if err != nil {
+		return nil, err
+	}
+	var out []EngagementDeviceSpec
+	for _, device := range devices {
+		out = append(out, EngagementDeviceSpec{
+			DeviceID:      device.DeviceID,
+			Owner:         formatAddress(device.Owner[:]),
+			TokenHash:     "0x" + hex.EncodeToString(device.TokenHash[:]),
+			RegisteredAt:  device.RegisteredAt,
+			LastHeartbeat: device.LastHeartbeat,
+		})
+	}
+	return out, nil
+}
+
+func exportReputation(manager *state.Manager) ([]ReputationSpec, error) {
+	aggregates, err := reputation.NewScoreBook(manager).Aggregates()
+	if err != nil {
+		return nil, err
+	}
+	var out []ReputationSpec
+	for _, agg := range aggregates {
+		spec := ReputationSpec{
+			Address:           formatAddress(agg.Address[:]),
+			FirstSeen:         agg.FirstSeen,
+			UpdatedAt:         agg.UpdatedAt,
+			EscrowsCompleted:  agg.EscrowsCompleted,
+			EscrowsDisputed:   agg.EscrowsDisputed,
+			TradesCompleted:   agg.TradesCompleted,
+			TradesDisputed:    agg.TradesDisputed,
+			ArbitrationWins:   agg.ArbitrationWins,
+			ArbitrationLosses: agg.ArbitrationLosses,
+		}
+		for _, att := range agg.Attestations {
+			spec.Attestations = append(spec.Attestations, ReputationWeightSpec{
+				ID:        "0x" + hex.EncodeToString(att.ID[:]),
+				Verifier:  formatAddress(att.Verifier[:]),
+				Weight:    att.Weight,
+				ExpiresAt: att.ExpiresAt,
+			})
+		}
+		out = append(out, spec)
+	}
+	return out, nil
+}
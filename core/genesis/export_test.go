@@ -0,0 +1,469 @@
+// core/genesis/export_test.go
+package genesis
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"nhbchain/core/identity"
+	"nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+	"nhbchain/native/creator"
+	"nhbchain/native/escrow"
+	"nhbchain/native/governance"
+	"nhbchain/native/lending"
+	"nhbchain/native/loyalty"
+	"nhbchain/native/reputation"
+	"nhbchain/storage"
+	"nhbchain/storage/trie"
+)
+
+func buildGenesisFromFile(t *testing.T, path string) (storage.Database, []byte) {
+	t.Helper()
+	spec, err := LoadGenesisSpec(path)
+	if err != nil {
+		t.Fatalf("load genesis spec: %v", err)
+	}
+	db := storage.NewMemDB()
+	block, finalize, err := BuildGenesisFromSpec(spec, db)
+	if err != nil {
+		t.Fatalf("build genesis: %v", err)
+	}
+	if err := finalize(); err != nil {
+		t.Fatalf("finalize genesis: %v", err)
+	}
+	return db, block.Header.StateRoot
+}
+
+func exportJSON(t *testing.T, db storage.Database, root []byte, opts ExportOptions) []byte {
+	t.Helper()
+	spec, err := ExportSpec(db, root, opts)
+	if err != nil {
+		t.Fatalf("export spec: %v", err)
+	}
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal exported spec: %v", err)
+	}
+	return data
+}
+
+func TestExportSpecRoundTrip(t *testing.T) {
+	db, root := buildGenesisFromFile(t, filepath.Join("..", "..", "config", "genesis.json"))
+
+	stateTrie, err := trie.NewTrie(db, root)
+	if err != nil {
+		t.Fatalf("open state: %v", err)
+	}
+	manager := state.NewManager(stateTrie)
+
+	alice := bytes.Repeat([]byte{0xa1}, 20)
+	bob := bytes.Repeat([]byte{0xb2}, 20)
+	arbiter := bytes.Repeat([]byte{0xc3}, 20)
+
+	account := &types.Account{
+		Nonce:              7,
+		BalanceNHB:         big.NewInt(5_000),
+		BalanceZNHB:        big.NewInt(2_500),
+		Stake:              big.NewInt(1_200),
+		StakeShares:        big.NewInt(1_100),
+		LockedZNHB:         big.NewInt(300),
+		DelegatedValidator: bob,
+		PendingUnbonds:     []types.StakeUnbond{{ID: 3, Validator: bob, Amount: big.NewInt(40), ReleaseTime: 1_800_000_000}},
+		NextUnbondingID:    4,
+		Username:           "alice",
+		EngagementScore:    12,
+		EngagementDay:      "2026-03-02",
+		CollateralBalance:  big.NewInt(900),
+		DebtPrincipal:      big.NewInt(150),
+		LendingBreaker:     types.LendingBreakerFlags{BorrowDisabled: true},
+	}
+	mustNoErr(t, manager.PutAccount(alice, account))
+	mustNoErr(t, manager.PutAccount(bob, &types.Account{BalanceNHB: big.NewInt(10)}))
+	mustNoErr(t, manager.PutAccountStakingRewards(alice, &types.StakingRewards{
+		AccruedZNHB:        big.NewInt(55),
+		LastIndexUQ128x128: types.Uint128x128FromBytes([]byte{0x01, 0x02}),
+		LastPayoutUnix:     1_772_400_000,
+	}))
+	mustNoErr(t, manager.PutGlobalIndex(&state.GlobalIndex{UQ128x128: []byte{0x05}, LastUpdateUnix: 1_772_400_000, YTDEmissions: big.NewInt(77)}))
+	mustNoErr(t, manager.SetStakingEmissionYTD(2026, big.NewInt(77)))
+	mustNoErr(t, manager.SetMintEmissionYTD("ZNHB", 2026, big.NewInt(88)))
+	mustNoErr(t, manager.SetTokenSupply("NHB", big.NewInt(1_000_000)))
+	if _, err := manager.GovernanceEscrowLock(alice, big.NewInt(25)); err != nil {
+		t.Fatalf("lock governance escrow: %v", err)
+	}
+	mustNoErr(t, manager.SetLoyaltyBaseTotalAccrued(alice, big.NewInt(9)))
+
+	var arbiterAddr [20]byte
+	copy(arbiterAddr[:], arbiter)
+	realm := &escrow.EscrowRealm{
+		ID:              "platform",
+		Version:         1,
+		NextPolicyNonce: 2,
+		CreatedAt:       1_772_300_000,
+		UpdatedAt:       1_772_300_000,
+		Arbitrators:     &escrow.ArbitratorSet{Scheme: escrow.ArbitrationSchemeSingle, Threshold: 1, Members: [][20]byte{arbiterAddr}},
+		Metadata:        &escrow.EscrowRealmMetadata{Scope: escrow.EscrowRealmScopePlatform, ProviderProfile: "core"},
+	}
+	mustNoErr(t, manager.EscrowRealmPut(realm))
+	mustNoErr(t, manager.EscrowRealmPut(&escrow.EscrowRealm{
+		ID:              "unused",
+		Version:         1,
+		NextPolicyNonce: 1,
+		CreatedAt:       1_772_300_000,
+		UpdatedAt:       1_772_300_000,
+		Arbitrators:     &escrow.ArbitratorSet{Scheme: escrow.ArbitrationSchemeSingle, Threshold: 1, Members: [][20]byte{arbiterAddr}},
+		Metadata:        &escrow.EscrowRealmMetadata{Scope: escrow.EscrowRealmScopeMarketplace, ProviderProfile: "shop"},
+	}))
+	escrowID := [32]byte{0x42}
+	record := &escrow.Escrow{
+		ID:        escrowID,
+		Token:     "NHB",
+		Amount:    big.NewInt(600),
+		Deadline:  1_773_000_000,
+		CreatedAt: 1_772_400_000,
+		Nonce:     1,
+		Status:    escrow.EscrowFunded,
+		RealmID:   "platform",
+		FrozenArb: &escrow.FrozenArb{
+			RealmID:      "platform",
+			RealmVersion: 1,
+			PolicyNonce:  1,
+			Scheme:       escrow.ArbitrationSchemeSingle,
+			Threshold:    1,
+			Members:      [][20]byte{arbiterAddr},
+			FrozenAt:     1_772_400_000,
+			Metadata:     &escrow.EscrowRealmMetadata{Scope: escrow.EscrowRealmScopePlatform, ProviderProfile: "core"},
+		},
+	}
+	copy(record.Payer[:], alice)
+	copy(record.Payee[:], bob)
+	mustNoErr(t, manager.EscrowPut(record))
+	mustNoErr(t, manager.EscrowFrozenPolicyPut(escrowID, record.FrozenArb))
+	mustNoErr(t, manager.EscrowCredit(escrowID, "NHB", big.NewInt(600)))
+
+	var aliceAddr, bobAddr [20]byte
+	copy(aliceAddr[:], alice)
+	copy(bobAddr[:], bob)
+	registry := loyalty.NewRegistry(manager)
+	businessID, err := registry.RegisterBusiness(aliceAddr, "Alice Coffee")
+	mustNoErr(t, err)
+	mustNoErr(t, registry.SetPaymaster(businessID, aliceAddr, bobAddr))
+	mustNoErr(t, registry.AddMerchantAddress(businessID, bobAddr))
+	if _, err := registry.RegisterBusiness(bobAddr, "Bob Books"); err != nil {
+		t.Fatalf("register business: %v", err)
+	}
+	programID := loyalty.ProgramID{0x77}
+	mustNoErr(t, registry.CreateProgram(aliceAddr, &loyalty.Program{
+		ID:               programID,
+		Owner:            aliceAddr,
+		Pool:             bobAddr,
+		TokenSymbol:      "ZNHB",
+		AccrualBps:       250,
+		DailyCapProgram:  big.NewInt(10_000),
+		Active:           true,
+		Tiers:            []loyalty.Tier{{Name: "gold", MinSpendWei: big.NewInt(1_000), AccrualBps: 500}},
+		TierWindowDays:   30,
+		RewardExpiryDays: 90,
+	}))
+
+	mustNoErr(t, manager.LendingPutMarket("default", &lending.Market{
+		PoolID:           "default",
+		DeveloperOwner:   crypto.MustNewAddress(crypto.NHBPrefix, bob),
+		DeveloperFeeBps:  25,
+		TotalNHBSupplied: big.NewInt(10_000),
+		TotalNHBBorrowed: big.NewInt(150),
+		SupplyIndex:      big.NewInt(1_000_000),
+		BorrowIndex:      big.NewInt(1_000_500),
+		LastUpdateBlock:  9,
+		ReserveFactor:    1_000,
+	}))
+	mustNoErr(t, manager.LendingPutFeeAccrual("default", &lending.FeeAccrual{ProtocolFeesWei: big.NewInt(3), DeveloperFeesWei: big.NewInt(1)}))
+	mustNoErr(t, manager.LendingPutUserAccount("default", &lending.UserAccount{
+		Address:    crypto.MustNewAddress(crypto.NHBPrefix, alice),
+		Collateral: map[string]*big.Int{lending.AssetZNHB: big.NewInt(900)},
+		DebtNHB:    big.NewInt(150),
+		ScaledDebt: big.NewInt(149),
+	}))
+
+	mustNoErr(t, manager.KVPut(state.GovernanceSequenceKey(), uint64(1)))
+	submitted := time.Unix(1_772_400_000, 0).UTC()
+	mustNoErr(t, manager.GovernancePutProposal(&governance.Proposal{
+		ID:             1,
+		Title:          "raise fee",
+		Submitter:      crypto.MustNewAddress(crypto.NHBPrefix, alice),
+		Status:         governance.ProposalStatusVotingPeriod,
+		Deposit:        big.NewInt(25),
+		SubmitTime:     submitted,
+		VotingStart:    submitted,
+		VotingEnd:      submitted.Add(time.Hour),
+		TimelockEnd:    submitted.Add(2 * time.Hour),
+		Target:         "param.update",
+		ProposedChange: `{"fees.baseBps":"60"}`,
+	}))
+	mustNoErr(t, manager.GovernancePutVote(&governance.Vote{
+		ProposalID: 1,
+		Voter:      crypto.MustNewAddress(crypto.NHBPrefix, bob),
+		Choice:     governance.VoteChoiceYes,
+		PowerBps:   5_000,
+		Timestamp:  submitted.Add(time.Minute),
+	}))
+	mustNoErr(t, manager.ParamStoreSet("fees.baseBps", []byte("60")))
+
+	vault := bytes.Repeat([]byte{0xd4}, 20)
+	mustNoErr(t, manager.CreateAsset(&state.TokenMetadata{
+		Symbol:          "GEM",
+		Name:            "Gem",
+		Decimals:        6,
+		Issuer:          alice,
+		MintAuthority:   alice,
+		FreezeAuthority: alice,
+		SupplyCap:       big.NewInt(1_000_000),
+	}))
+	if _, err := manager.MintAsset("GEM", alice, big.NewInt(700)); err != nil {
+		t.Fatalf("mint asset: %v", err)
+	}
+	if _, err := manager.MintAsset("GEM", vault, big.NewInt(300)); err != nil {
+		t.Fatalf("mint asset to vault: %v", err)
+	}
+	mustNoErr(t, manager.SetAssetFrozen("GEM", bob, true))
+
+	mustNoErr(t, manager.IdentitySetAlias(alice, "alicecafe"))
+	if _, err := manager.IdentitySetTextRecords("alicecafe", []identity.TextRecord{{Key: "url", Value: "https://alice.example"}}, 1_772_400_000); err != nil {
+		t.Fatalf("set text records: %v", err)
+	}
+	mustNoErr(t, manager.IdentityPutSubAlias(&identity.SubAlias{Label: "pay", Parent: "alicecafe", Target: bobAddr, CreatedAt: 1_772_400_100}))
+	if _, err := manager.IdentityPutListing(&identity.Listing{Alias: "alicecafe", Seller: aliceAddr, Price: big.NewInt(5_000), ListedAt: 1_772_400_200}); err != nil {
+		t.Fatalf("put listing: %v", err)
+	}
+
+	invoiceID := [32]byte{0x11}
+	mustNoErr(t, manager.CreateInvoice(&state.StoredInvoice{
+		ID:           invoiceID,
+		Merchant:     bobAddr,
+		Asset:        "NHB",
+		Amount:       big.NewInt(100),
+		Paid:         big.NewInt(40),
+		AllowPartial: true,
+		Status:       string(state.InvoiceStatusPartiallyPaid),
+		CreatedAt:    1_772_400_000,
+	}))
+	mandateID := [32]byte{0x12}
+	mustNoErr(t, manager.CreateMandate(&state.StoredMandate{
+		ID:             mandateID,
+		Payer:          aliceAddr,
+		Merchant:       bobAddr,
+		Asset:          "NHB",
+		MaxAmount:      big.NewInt(50),
+		PeriodSeconds:  86_400,
+		StartAt:        1_772_400_000,
+		CreatedAt:      1_772_400_000,
+		Status:         string(state.MandateStatusActive),
+		LastPullPeriod: 1,
+		PulledTotal:    big.NewInt(50),
+		PullCount:      1,
+	}))
+	sessionKey := [20]byte{0x13}
+	mustNoErr(t, manager.SessionKeyPut(&state.SessionKey{
+		Key:           sessionKey,
+		Account:       aliceAddr,
+		TxTypes:       []uint8{uint8(types.TxTypeTransfer)},
+		DailyLimitNHB: big.NewInt(500),
+		ExpiresAt:     1_773_000_000,
+		CreatedAt:     1_772_400_000,
+		SpentDay:      "2026-03-02",
+		SpentNHB:      big.NewInt(120),
+	}))
+	mustNoErr(t, manager.RecoveryPutConfig(aliceAddr, &state.RecoveryConfig{
+		Guardians:    [][20]byte{bobAddr, arbiterAddr},
+		Threshold:    2,
+		DelaySeconds: state.MinRecoveryDelaySeconds,
+		UpdatedAt:    1_772_400_000,
+	}))
+	pending := &state.RecoveryRequest{Account: aliceAddr, CreatedAt: 1_772_400_300}
+	pending.SetVote(bobAddr, [20]byte{0x14})
+	mustNoErr(t, manager.RecoveryPutPending(pending))
+	mustNoErr(t, manager.CreatorTierPut(&creator.SubscriptionTier{
+		Creator:       bobAddr,
+		ID:            "gold",
+		Name:          "Gold",
+		Asset:         "NHB",
+		Price:         big.NewInt(30),
+		PeriodSeconds: 2_592_000,
+		Active:        true,
+		UpdatedAt:     1_772_400_000,
+	}))
+	mustNoErr(t, manager.CreatorSubscriptionPut(&creator.Subscription{
+		Creator:       bobAddr,
+		Fan:           aliceAddr,
+		TierID:        "gold",
+		Asset:         "NHB",
+		Price:         big.NewInt(30),
+		PeriodSeconds: 2_592_000,
+		StartedAt:     1_772_400_000,
+		PaidThrough:   1_774_992_000,
+	}))
+	mustNoErr(t, manager.EngagementDevicePut(&state.EngagementDevice{
+		DeviceID:     "alice-phone",
+		Owner:        aliceAddr,
+		TokenHash:    [32]byte{0x15},
+		RegisteredAt: 1_772_400_000,
+	}, 1))
+	mustNoErr(t, reputation.NewScoreBook(manager).Restore(&reputation.Aggregate{
+		Address:          bobAddr,
+		FirstSeen:        1_772_300_000,
+		UpdatedAt:        1_772_400_000,
+		Attestations:     []reputation.AttestationWeight{{ID: [32]byte{0x16}, Verifier: arbiterAddr, Weight: 40}},
+		EscrowsCompleted: 3,
+		ArbitrationWins:  2,
+	}))
+
+	committedRoot, err := stateTrie.Commit(common.BytesToHash(root), 1)
+	if err != nil {
+		t.Fatalf("commit mutated state: %v", err)
+	}
+
+	opts := ExportOptions{
+		GenesisTime: time.Unix(1_772_409_600, 0),
+		AdminWallet: "nhb10lephh6ffd79cc7lk6edc6rkxe9ha8xekt0y8h",
+		Params:      []string{"fees.baseBps"},
+	}
+	exported := exportJSON(t, db, committedRoot.Bytes(), opts)
+	if again := exportJSON(t, db, committedRoot.Bytes(), opts); !bytes.Equal(exported, again) {
+		t.Fatalf("export is not deterministic")
+	}
+
+	path := filepath.Join(t.TempDir(), "exported.json")
+	if err := os.WriteFile(path, exported, 0o644); err != nil {
+		t.Fatalf("write exported spec: %v", err)
+	}
+	reimportedDB, reimportedRoot := buildGenesisFromFile(t, path)
+	if roundTrip := exportJSON(t, reimportedDB, reimportedRoot, opts); !bytes.Equal(exported, roundTrip) {
+		t.Fatalf("re-imported state exports differently:\nfirst:\n%s\nsecond:\n%s", exported, roundTrip)
+	}
+
+	reimportedTrie, err := trie.NewTrie(reimportedDB, reimportedRoot)
+	if err != nil {
+		t.Fatalf("open re-imported state: %v", err)
+	}
+	reimported := state.NewManager(reimportedTrie)
+	got, err := reimported.GetAccount(alice)
+	if err != nil {
+		t.Fatalf("get account: %v", err)
+	}
+	if got.Nonce != 7 || got.Stake.Cmp(big.NewInt(1_200)) != 0 || got.Username != "alice" || !got.LendingBreaker.BorrowDisabled {
+		t.Fatalf("account state not restored: %+v", got)
+	}
+	if got.StakingRewards.AccruedZNHB.Cmp(big.NewInt(55)) != 0 {
+		t.Fatalf("staking rewards not restored: %+v", got.StakingRewards)
+	}
+	restoredEscrow, ok := reimported.EscrowGet(escrowID)
+	if !ok || restoredEscrow.Status != escrow.EscrowFunded || restoredEscrow.FrozenArb == nil {
+		t.Fatalf("escrow not restored: %+v", restoredEscrow)
+	}
+	if balance, err := reimported.EscrowBalance(escrowID, "NHB"); err != nil || balance.Cmp(big.NewInt(600)) != 0 {
+		t.Fatalf("escrow vault balance not restored: %v (err=%v)", balance, err)
+	}
+	if _, ok, err := reimported.EscrowRealmGet("unused"); err != nil || !ok {
+		t.Fatalf("unreferenced realm not restored (ok=%v err=%v)", ok, err)
+	}
+	reimportedRegistry := loyalty.NewRegistry(reimported)
+	if paymaster, ok := reimportedRegistry.PrimaryPaymaster(aliceAddr); !ok || paymaster != bobAddr {
+		t.Fatalf("paymaster not restored: %x (ok=%v)", paymaster, ok)
+	}
+	if id, ok := reimportedRegistry.IsMerchant(bobAddr); !ok || id != businessID {
+		t.Fatalf("merchant index not restored: %x (ok=%v)", id, ok)
+	}
+	if program, ok := reimportedRegistry.GetProgram(programID); !ok || len(program.Tiers) != 1 || program.RewardExpiryDays != 90 {
+		t.Fatalf("program not restored: %+v (ok=%v)", program, ok)
+	}
+	if owned, err := reimportedRegistry.ListProgramsByOwner(aliceAddr); err != nil || len(owned) != 1 {
+		t.Fatalf("program owner index not restored: %v (err=%v)", owned, err)
+	}
+	next, err := reimportedRegistry.RegisterBusiness(bobAddr, "Bob Games")
+	if err != nil {
+		t.Fatalf("register business after import: %v", err)
+	}
+	if next[31] != 3 {
+		t.Fatalf("business counter not restored: next id %x", next)
+	}
+	if user, ok, err := reimported.LendingGetUserAccount("default", [20]byte(alice)); err != nil || !ok || user.ScaledDebt.Cmp(big.NewInt(149)) != 0 {
+		t.Fatalf("lending user not restored: %+v (ok=%v err=%v)", user, ok, err)
+	}
+	if votes, err := reimported.GovernanceListVotes(1); err != nil || len(votes) != 1 || votes[0].Choice != governance.VoteChoiceYes {
+		t.Fatalf("governance votes not restored: %+v (err=%v)", votes, err)
+	}
+	if value, ok, err := reimported.ParamStoreGet("fees.baseBps"); err != nil || !ok || string(value) != "60" {
+		t.Fatalf("param not restored: %q (ok=%v err=%v)", value, ok, err)
+	}
+
+	assets, err := reimported.IssuedAssets()
+	if err != nil || len(assets) != 1 || !bytes.Equal(assets[0].Issuer, alice) || !bytes.Equal(assets[0].FreezeAuthority, alice) || assets[0].Minted.Cmp(big.NewInt(1_000)) != 0 {
+		t.Fatalf("issued asset not restored: %+v (err=%v)", assets, err)
+	}
+	if balance, err := reimported.Balance(vault, "GEM"); err != nil || balance.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("vault asset balance not restored: %v (err=%v)", balance, err)
+	}
+	if held, err := reimported.AuditAssetHolders("GEM"); err != nil || held.Cmp(big.NewInt(1_000)) != 0 {
+		t.Fatalf("asset holders not restored: %v (err=%v)", held, err)
+	}
+	if frozen, err := reimported.AssetFrozen("GEM", bob); err != nil || !frozen {
+		t.Fatalf("asset freeze not restored (frozen=%v err=%v)", frozen, err)
+	}
+
+	if record, ok := reimported.IdentityResolve("alicecafe"); !ok || record.Owner != aliceAddr || len(record.Records) != 1 {
+		t.Fatalf("alias not restored: %+v (ok=%v)", record, ok)
+	}
+	if alias, ok := reimported.IdentityReverse(alice); !ok || alias != "alicecafe" {
+		t.Fatalf("alias reverse mapping not restored: %q (ok=%v)", alias, ok)
+	}
+	if sub, ok, err := reimported.IdentityGetSubAlias("pay.alicecafe"); err != nil || !ok || sub.Target != bobAddr {
+		t.Fatalf("sub-alias not restored: %+v (ok=%v err=%v)", sub, ok, err)
+	}
+	if listing, ok, err := reimported.IdentityGetListing("alicecafe"); err != nil || !ok || listing.Nonce != 1 || listing.Price.Cmp(big.NewInt(5_000)) != 0 {
+		t.Fatalf("alias listing not restored: %+v (ok=%v err=%v)", listing, ok, err)
+	}
+
+	if invoices, err := reimported.ListInvoicesByMerchant(bobAddr); err != nil || len(invoices) != 1 || invoices[0].ID != invoiceID || invoices[0].Paid.Cmp(big.NewInt(40)) != 0 {
+		t.Fatalf("invoice not restored: %+v (err=%v)", invoices, err)
+	}
+	if mandates, err := reimported.ListMandatesByMerchant(bobAddr); err != nil || len(mandates) != 1 || mandates[0].ID != mandateID || mandates[0].PullCount != 1 {
+		t.Fatalf("mandate not restored: %+v (err=%v)", mandates, err)
+	}
+	if keys, err := reimported.SessionKeysByAccount(aliceAddr); err != nil || len(keys) != 1 || keys[0].Key != sessionKey || keys[0].Spent("NHB", "2026-03-02").Cmp(big.NewInt(120)) != 0 {
+		t.Fatalf("session key not restored: %+v (err=%v)", keys, err)
+	}
+	if cfg, ok, err := reimported.RecoveryGetConfig(aliceAddr); err != nil || !ok || cfg.Threshold != 2 || len(cfg.Guardians) != 2 {
+		t.Fatalf("guardian set not restored: %+v (ok=%v err=%v)", cfg, ok, err)
+	}
+	if req, ok, err := reimported.RecoveryGetPending(aliceAddr); err != nil || !ok || len(req.Approvals([20]byte{0x14})) != 1 {
+		t.Fatalf("pending recovery not restored: %+v (ok=%v err=%v)", req, ok, err)
+	}
+	if _, ok, err := reimported.CreatorTierGet(bobAddr, "gold"); err != nil || !ok {
+		t.Fatalf("creator tier not restored (ok=%v err=%v)", ok, err)
+	}
+	if sub, ok, err := reimported.CreatorSubscriptionGet(bobAddr, aliceAddr); err != nil || !ok || sub.PaidThrough != 1_774_992_000 {
+		t.Fatalf("creator subscription not restored: %+v (ok=%v err=%v)", sub, ok, err)
+	}
+	if devices, err := reimported.EngagementDevicesByOwner(aliceAddr); err != nil || len(devices) != 1 || devices[0].DeviceID != "alice-phone" {
+		t.Fatalf("engagement device not restored: %+v (err=%v)", devices, err)
+	}
+	if agg, ok, err := reputation.NewScoreBook(reimported).Get(bobAddr); err != nil || !ok || agg.ArbitrationWins != 2 || len(agg.Attestations) != 1 {
+		t.Fatalf("reputation not restored: %+v (ok=%v err=%v)", agg, ok, err)
+	}
+}
+
+func mustNoErr(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
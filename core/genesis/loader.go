@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
-	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 
 	"nhbchain/consensus/store"
 	"nhbchain/core/state"
@@ -104,6 +103,15 @@ func BuildGenesisFromSpec(spec *GenesisSpec, db storage.Database) (*types.Block,
 				return nil, nil, fmt.Errorf("token %q: %w", token.Symbol, err)
 			}
 		}
+		if strings.TrimSpace(token.Supply) != "" {
+			supply, err := parseAmountString(token.Supply)
+			if err != nil {
+				return nil, nil, fmt.Errorf("token %q supply: %w", token.Symbol, err)
+			}
+			if err := manager.SetTokenSupply(token.Symbol, supply); err != nil {
+				return nil, nil, fmt.Errorf("token %q: %w", token.Symbol, err)
+			}
+		}
 	}
 
 	// 2) Allocations (outer: addresses sorted; inner: symbols sorted)
@@ -185,7 +193,18 @@ func BuildGenesisFromSpec(spec *GenesisSpec, db storage.Database) (*types.Block,
 		}
 	}
 
-	// 4) Validators (sorted by address)
+	// 4) Exported module state
+	if err := applyModuleState(manager, spec); err != nil {
+		return nil, nil, err
+	}
+	exportedAccounts := make(map[string]struct{}, len(spec.Accounts))
+	for addrStr := range spec.Accounts {
+		if parsed, err := ParseBech32Account(addrStr); err == nil {
+			exportedAccounts[string(parsed[:])] = struct{}{}
+		}
+	}
+
+	// 5) Validators (sorted by address)
 	validators := append([]ValidatorSpec(nil), spec.Validators...)
 	sort.Slice(validators, func(i, j int) bool {
 		return strings.Compare(validators[i].Address, validators[j].Address) < 0
@@ -202,9 +221,11 @@ func BuildGenesisFromSpec(spec *GenesisSpec, db storage.Database) (*types.Block,
 		addrCopy := append([]byte(nil), parsed[:]...)
 		validatorPowers[string(addrCopy)] = new(big.Int).SetUint64(v.Power)
 
-		// Grant necessary stake to prevent epoch rotation dropout
+		// Grant necessary stake to prevent epoch rotation dropout. Exported
+		// accounts already carry their real stake.
+		_, exported := exportedAccounts[string(addrCopy)]
 		if acc, err := manager.GetAccount(addrCopy); err == nil {
-			if acc.Stake.Cmp(defaultStake) < 0 {
+			if !exported && acc.Stake.Cmp(defaultStake) < 0 {
 				acc.Stake = new(big.Int).Set(defaultStake)
 				manager.PutAccountMetadata(addrCopy, acc)
 			}
@@ -228,11 +249,21 @@ func BuildGenesisFromSpec(spec *GenesisSpec, db storage.Database) (*types.Block,
 			Moniker: v.Moniker,
 		})
 	}
+	if spec.Staking != nil && len(spec.Staking.ValidatorSet) > 0 {
+		if validatorPowers, err = parseValidatorSet(spec.Staking.ValidatorSet); err != nil {
+			return nil, nil, fmt.Errorf("staking.validatorSet: %w", err)
+		}
+	}
+	if spec.Staking != nil && len(spec.Staking.EligibleValidators) > 0 {
+		if eligibleSet, err = parseValidatorSet(spec.Staking.EligibleValidators); err != nil {
+			return nil, nil, fmt.Errorf("staking.eligibleValidators: %w", err)
+		}
+	}
 	if err := manager.WriteValidatorSet(validatorPowers); err != nil {
 		return nil, nil, fmt.Errorf("persist validator set: %w", err)
 	}
-	if encodedEligible, err := state.EncodeValidatorSet(eligibleSet); err == nil {
-		stateTrie.Update(ethcrypto.Keccak256([]byte("validator-eligible-set")), encodedEligible)
+	if err := manager.WriteEligibleValidatorSet(eligibleSet); err != nil {
+		return nil, nil, fmt.Errorf("persist eligible validator set: %w", err)
 	}
 
 	header.StateRoot = stateTrie.Hash().Bytes()
@@ -259,3 +290,70 @@ func BuildGenesisFromSpec(spec *GenesisSpec, db storage.Database) (*types.Block,
 
 	return types.NewBlock(header, nil), finalize, nil
 }
+
+// applyModuleState writes the module sections produced by ExportSpec. Realms
+// precede escrows, accounts precede everything that references them and
+// issued assets are registered before escrows can hold them.
+func applyModuleState(manager *state.Manager, spec *GenesisSpec) error {
+	if len(spec.Accounts) > 0 {
+		if err := applyAccountState(manager, spec.Accounts); err != nil {
+			return err
+		}
+	}
+	if err := applyIssuedAssets(manager, spec.IssuedAssets); err != nil {
+		return err
+	}
+	if spec.Staking != nil {
+		if err := applyStakingState(manager, spec.Staking); err != nil {
+			return fmt.Errorf("staking: %w", err)
+		}
+	}
+	if spec.Escrow != nil {
+		if err := applyEscrowState(manager, spec.Escrow); err != nil {
+			return fmt.Errorf("escrow: %w", err)
+		}
+	}
+	if spec.Loyalty != nil {
+		if err := applyLoyaltyState(manager, spec.Loyalty); err != nil {
+			return fmt.Errorf("loyalty: %w", err)
+		}
+	}
+	if spec.Lending != nil {
+		if err := applyLendingState(manager, spec.Lending); err != nil {
+			return fmt.Errorf("lending: %w", err)
+		}
+	}
+	if spec.Governance != nil {
+		if err := applyGovernanceState(manager, spec.Governance); err != nil {
+			return fmt.Errorf("governance: %w", err)
+		}
+	}
+	if spec.Identity != nil {
+		if err := applyIdentityState(manager, spec.Identity); err != nil {
+			return fmt.Errorf("identity: %w", err)
+		}
+	}
+	if err := applyInvoices(manager, spec.Invoices); err != nil {
+		return err
+	}
+	if err := applyMandates(manager, spec.Mandates); err != nil {
+		return err
+	}
+	if err := applySessionKeys(manager, spec.SessionKeys); err != nil {
+		return err
+	}
+	if spec.Recovery != nil {
+		if err := applyRecoveryState(manager, spec.Recovery); err != nil {
+			return fmt.Errorf("recovery: %w", err)
+		}
+	}
+	if spec.Creator != nil {
+		if err := applyCreatorState(manager, spec.Creator); err != nil {
+			return fmt.Errorf("creator: %w", err)
+		}
+	}
+	if err := applyEngagementDevices(manager, spec.EngagementDevices); err != nil {
+		return err
+	}
+	return applyReputation(manager, spec.Reputation)
+}
@@ -0,0 +1,1733 @@
+// core/genesis/module_state.go
+package genesis
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"nhbchain/core/identity"
+	"nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+	"nhbchain/native/creator"
+	"nhbchain/native/escrow"
+	"nhbchain/native/governance"
+	"nhbchain/native/lending"
+	"nhbchain/native/loyalty"
+	"nhbchain/native/reputation"
+)
+
+// The sections below carry module state captured by ExportSpec so a genesis
+// file can reproduce a live chain. Hand-written genesis files normally leave
+// them empty. Amounts are base-10 strings, addresses bech32, hashes 0x-hex and
+// timestamps unix seconds. Zero values are omitted.
+
+// AccountStateSpec holds the non-balance account state. Token balances stay in
+// GenesisSpec.Alloc.
+type AccountStateSpec struct {
+	Nonce              uint64              `json:"nonce,omitempty"`
+	Stake              string              `json:"stake,omitempty"`
+	StakeShares        string              `json:"stakeShares,omitempty"`
+	StakeLastIndex     string              `json:"stakeLastIndex,omitempty"`
+	StakeLastPayoutTs  uint64              `json:"stakeLastPayoutTs,omitempty"`
+	LockedZNHB         string              `json:"lockedZNHB,omitempty"`
+	DelegatedValidator string              `json:"delegatedValidator,omitempty"`
+	RewardBeneficiary  string              `json:"rewardBeneficiary,omitempty"`
+	PendingUnbonds     []UnbondSpec        `json:"pendingUnbonds,omitempty"`
+	NextUnbondingID    uint64              `json:"nextUnbondingId,omitempty"`
+	Username           string              `json:"username,omitempty"`
+	Engagement         *EngagementSpec     `json:"engagement,omitempty"`
+	Lending            *AccountLendingSpec `json:"lending,omitempty"`
+	StakingRewards     *StakingRewardsSpec `json:"stakingRewards,omitempty"`
+	GovernanceEscrow   string              `json:"governanceEscrow,omitempty"`
+	LoyaltyBaseAccrued string              `json:"loyaltyBaseAccrued,omitempty"`
+}
+
+type UnbondSpec struct {
+	ID          uint64 `json:"id"`
+	Validator   string `json:"validator,omitempty"`
+	Amount      string `json:"amount"`
+	ReleaseTime uint64 `json:"releaseTime"`
+}
+
+type EngagementSpec struct {
+	Score         uint64 `json:"score,omitempty"`
+	Day           string `json:"day,omitempty"`
+	Minutes       uint64 `json:"minutes,omitempty"`
+	TxCount       uint64 `json:"txCount,omitempty"`
+	EscrowEvents  uint64 `json:"escrowEvents,omitempty"`
+	GovEvents     uint64 `json:"govEvents,omitempty"`
+	LastHeartbeat uint64 `json:"lastHeartbeat,omitempty"`
+}
+
+type AccountLendingSpec struct {
+	CollateralBalance  string `json:"collateralBalance,omitempty"`
+	DebtPrincipal      string `json:"debtPrincipal,omitempty"`
+	SupplyShares       string `json:"supplyShares,omitempty"`
+	SupplyIndex        string `json:"supplyIndex,omitempty"`
+	BorrowIndex        string `json:"borrowIndex,omitempty"`
+	CollateralDisabled bool   `json:"collateralDisabled,omitempty"`
+	BorrowDisabled     bool   `json:"borrowDisabled,omitempty"`
+}
+
+type StakingRewardsSpec struct {
+	AccruedZNHB    string `json:"accruedZNHB,omitempty"`
+	LastIndex      string `json:"lastIndex,omitempty"`
+	LastPayoutUnix int64  `json:"lastPayoutUnix,omitempty"`
+}
+
+// StakingStateSpec captures the protocol-wide staking accumulators and the
+// validator sets as stored in state, where powers are full stake amounts.
+type StakingStateSpec struct {
+	GlobalIndex        string            `json:"globalIndex,omitempty"`
+	LastIndexUpdate    int64             `json:"lastIndexUpdate,omitempty"`
+	YTDEmissions       string            `json:"ytdEmissions,omitempty"`
+	EmissionYear       uint32            `json:"emissionYear,omitempty"`
+	StakingEmissionYTD string            `json:"stakingEmissionYtd,omitempty"`
+	MintEmissionYTD    map[string]string `json:"mintEmissionYtd,omitempty"`
+	ValidatorSet       map[string]string `json:"validatorSet,omitempty"`
+	EligibleValidators map[string]string `json:"eligibleValidators,omitempty"`
+}
+
+type EscrowStateSpec struct {
+	Realms  []EscrowRealmSpec  `json:"realms,omitempty"`
+	Escrows []EscrowRecordSpec `json:"escrows,omitempty"`
+}
+
+type EscrowRealmSpec struct {
+	ID              string                `json:"id"`
+	Version         uint64                `json:"version"`
+	NextPolicyNonce uint64                `json:"nextPolicyNonce"`
+	CreatedAt       int64                 `json:"createdAt"`
+	UpdatedAt       int64                 `json:"updatedAt"`
+	Scheme          uint8                 `json:"scheme"`
+	Threshold       uint32                `json:"threshold"`
+	Members         []string              `json:"members"`
+	FeeSchedule     *RealmFeeScheduleSpec `json:"feeSchedule,omitempty"`
+	Metadata        *RealmMetadataSpec    `json:"metadata,omitempty"`
+
+	EvidenceWindowSeconds int64              `json:"evidenceWindowSeconds,omitempty"`
+	ArbitrationSLASeconds int64              `json:"arbitrationSlaSeconds,omitempty"`
+	Fallback              *ArbitratorSetSpec `json:"fallback,omitempty"`
+	MinReputationScore    uint32             `json:"minReputationScore,omitempty"`
+}
+
+type RealmFeeScheduleSpec struct {
+	FeeBps           uint32 `json:"feeBps"`
+	Recipient        string `json:"recipient,omitempty"`
+	ArbitratorFeeBps uint32 `json:"arbitratorFeeBps,omitempty"`
+}
+
+type ArbitratorSetSpec struct {
+	Scheme    uint8    `json:"scheme"`
+	Threshold uint32   `json:"threshold"`
+	Members   []string `json:"members"`
+}
+
+type RealmMetadataSpec struct {
+	Scope             uint8  `json:"scope"`
+	ProviderProfile   string `json:"providerProfile,omitempty"`
+	ArbitrationFeeBps uint32 `json:"arbitrationFeeBps,omitempty"`
+	FeeRecipient      string `json:"feeRecipient,omitempty"`
+}
+
+type FrozenArbSpec struct {
+	RealmID      string                `json:"realmId"`
+	RealmVersion uint64                `json:"realmVersion"`
+	PolicyNonce  uint64                `json:"policyNonce"`
+	Scheme       uint8                 `json:"scheme"`
+	Threshold    uint32                `json:"threshold"`
+	Members      []string              `json:"members"`
+	FrozenAt     int64                 `json:"frozenAt"`
+	FeeSchedule  *RealmFeeScheduleSpec `json:"feeSchedule,omitempty"`
+	Metadata     *RealmMetadataSpec    `json:"metadata,omitempty"`
+
+	EvidenceWindowSeconds int64              `json:"evidenceWindowSeconds,omitempty"`
+	ArbitrationSLASeconds int64              `json:"arbitrationSlaSeconds,omitempty"`
+	Fallback              *ArbitratorSetSpec `json:"fallback,omitempty"`
+}
+
+type EscrowRecordSpec struct {
+	ID             string            `json:"id"`
+	Payer          string            `json:"payer"`
+	Payee          string            `json:"payee"`
+	Mediator       string            `json:"mediator,omitempty"`
+	Token          string            `json:"token"`
+	Amount         string            `json:"amount"`
+	FeeBps         uint32            `json:"feeBps,omitempty"`
+	Deadline       int64             `json:"deadline"`
+	CreatedAt      int64             `json:"createdAt"`
+	Nonce          uint64            `json:"nonce"`
+	MetaHash       string            `json:"metaHash,omitempty"`
+	Status         uint8             `json:"status"`
+	RealmID        string            `json:"realmId,omitempty"`
+	FrozenArb      *FrozenArbSpec    `json:"frozenArb,omitempty"`
+	ResolutionHash string            `json:"resolutionHash,omitempty"`
+	DisputeReason  string            `json:"disputeReason,omitempty"`
+	DisputedAt     int64             `json:"disputedAt,omitempty"`
+	EscalatedAt    int64             `json:"escalatedAt,omitempty"`
+	Evidence       []EvidenceSpec    `json:"evidence,omitempty"`
+	Balances       map[string]string `json:"balances,omitempty"`
+}
+
+type EvidenceSpec struct {
+	Submitter   string `json:"submitter"`
+	Hash        string `json:"hash"`
+	URI         string `json:"uri,omitempty"`
+	SubmittedAt int64  `json:"submittedAt"`
+}
+
+// LoyaltyStateSpec captures the loyalty controller runtime state and the
+// business and program registry. The global configuration itself travels in
+// GenesisSpec.LoyaltyGlobal.
+type LoyaltyStateSpec struct {
+	Controller *LoyaltyControllerSpec `json:"controller,omitempty"`
+	Businesses []LoyaltyBusinessSpec  `json:"businesses,omitempty"`
+	Programs   []LoyaltyProgramSpec   `json:"programs,omitempty"`
+}
+
+type LoyaltyControllerSpec struct {
+	EffectiveBps     uint32 `json:"effectiveBps"`
+	TargetBps        uint32 `json:"targetBps"`
+	MinBps           uint32 `json:"minBps"`
+	MaxBps           uint32 `json:"maxBps"`
+	SmoothingStepBps uint32 `json:"smoothingStepBps"`
+	YtdEmissionsZNHB string `json:"ytdEmissionsZNHB,omitempty"`
+	YearlyCapZNHB    string `json:"yearlyCapZNHB,omitempty"`
+}
+
+// LoyaltyBusinessSpec is a registered business. ActivePaymaster marks the
+// business whose paymaster funds the owner's programs.
+type LoyaltyBusinessSpec struct {
+	ID                  string   `json:"id"`
+	Owner               string   `json:"owner"`
+	Name                string   `json:"name"`
+	Paymaster           string   `json:"paymaster,omitempty"`
+	PaymasterReserveMin string   `json:"paymasterReserveMin,omitempty"`
+	ActivePaymaster     bool     `json:"activePaymaster,omitempty"`
+	Merchants           []string `json:"merchants,omitempty"`
+}
+
+type LoyaltyProgramSpec struct {
+	ID                 string            `json:"id"`
+	Owner              string            `json:"owner"`
+	Pool               string            `json:"pool,omitempty"`
+	TokenSymbol        string            `json:"tokenSymbol"`
+	AccrualBps         uint32            `json:"accrualBps"`
+	MinSpendWei        string            `json:"minSpendWei,omitempty"`
+	CapPerTx           string            `json:"capPerTx,omitempty"`
+	DailyCapUser       string            `json:"dailyCapUser,omitempty"`
+	DailyCapProgram    string            `json:"dailyCapProgram,omitempty"`
+	EpochCapProgram    string            `json:"epochCapProgram,omitempty"`
+	EpochLengthSeconds uint64            `json:"epochLengthSeconds,omitempty"`
+	IssuanceCapUser    string            `json:"issuanceCapUser,omitempty"`
+	StartTime          uint64            `json:"startTime,omitempty"`
+	EndTime            uint64            `json:"endTime,omitempty"`
+	Active             bool              `json:"active"`
+	Tiers              []LoyaltyTierSpec `json:"tiers,omitempty"`
+	TierWindowDays     uint32            `json:"tierWindowDays,omitempty"`
+	RewardExpiryDays   uint32            `json:"rewardExpiryDays,omitempty"`
+}
+
+type LoyaltyTierSpec struct {
+	Name        string `json:"name"`
+	MinSpendWei string `json:"minSpendWei,omitempty"`
+	AccrualBps  uint32 `json:"accrualBps"`
+}
+
+type LendingStateSpec struct {
+	Markets []LendingMarketSpec `json:"markets,omitempty"`
+}
+
+type LendingMarketSpec struct {
+	PoolID                string                  `json:"poolId"`
+	DeveloperOwner        string                  `json:"developerOwner,omitempty"`
+	DeveloperFeeCollector string                  `json:"developerFeeCollector,omitempty"`
+	DeveloperFeeBps       uint64                  `json:"developerFeeBps,omitempty"`
+	TotalNHBSupplied      string                  `json:"totalNHBSupplied,omitempty"`
+	TotalSupplyShares     string                  `json:"totalSupplyShares,omitempty"`
+	TotalNHBBorrowed      string                  `json:"totalNHBBorrowed,omitempty"`
+	SupplyIndex           string                  `json:"supplyIndex,omitempty"`
+	BorrowIndex           string                  `json:"borrowIndex,omitempty"`
+	LastUpdateBlock       uint64                  `json:"lastUpdateBlock,omitempty"`
+	ReserveFactor         uint64                  `json:"reserveFactor,omitempty"`
+	ProtocolFees          string                  `json:"protocolFees,omitempty"`
+	DeveloperFees         string                  `json:"developerFees,omitempty"`
+	BorrowAsset           string                  `json:"borrowAsset,omitempty"`
+	BorrowOracleFeed      string                  `json:"borrowOracleFeed,omitempty"`
+	OracleMaxAgeSeconds   uint64                  `json:"oracleMaxAgeSeconds,omitempty"`
+	Collateral            []LendingCollateralSpec `json:"collateral,omitempty"`
+	TotalCollateral       map[string]string       `json:"totalCollateral,omitempty"`
+	Users                 []LendingUserSpec       `json:"users,omitempty"`
+}
+
+type LendingCollateralSpec struct {
+	Asset                string `json:"asset"`
+	MaxLTV               uint64 `json:"maxLTV"`
+	LiquidationThreshold uint64 `json:"liquidationThreshold"`
+	OracleFeed           string `json:"oracleFeed,omitempty"`
+	SupplyCap            string `json:"supplyCap,omitempty"`
+}
+
+// LendingUserSpec carries a borrower position. CollateralZNHB is the
+// single-asset form accepted from older genesis files; exports use
+// Collateral.
+type LendingUserSpec struct {
+	Address        string            `json:"address"`
+	CollateralZNHB string            `json:"collateralZNHB,omitempty"`
+	Collateral     map[string]string `json:"collateral,omitempty"`
+	SupplyShares   string            `json:"supplyShares,omitempty"`
+	DebtNHB        string            `json:"debtNHB,omitempty"`
+	ScaledDebt     string            `json:"scaledDebt,omitempty"`
+}
+
+type GovernanceStateSpec struct {
+	ProposalSequence uint64                   `json:"proposalSequence,omitempty"`
+	Proposals        []GovernanceProposalSpec `json:"proposals,omitempty"`
+	// Params holds raw parameter store values, 0x-hex encoded.
+	Params map[string]string `json:"params,omitempty"`
+}
+
+type GovernanceProposalSpec struct {
+	ID             uint64               `json:"id"`
+	Title          string               `json:"title,omitempty"`
+	Summary        string               `json:"summary,omitempty"`
+	MetadataURI    string               `json:"metadataUri,omitempty"`
+	Submitter      string               `json:"submitter"`
+	Status         uint8                `json:"status"`
+	Deposit        string               `json:"deposit,omitempty"`
+	SubmitTime     int64                `json:"submitTime"`
+	VotingStart    int64                `json:"votingStart"`
+	VotingEnd      int64                `json:"votingEnd"`
+	TimelockEnd    int64                `json:"timelockEnd"`
+	Target         string               `json:"target"`
+	ProposedChange string               `json:"proposedChange,omitempty"`
+	Queued         bool                 `json:"queued,omitempty"`
+	Votes          []GovernanceVoteSpec `json:"votes,omitempty"`
+}
+
+type GovernanceVoteSpec struct {
+	Voter     string `json:"voter"`
+	Choice    string `json:"choice"`
+	PowerBps  uint32 `json:"powerBps"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// IssuedAssetSpec captures an asset created through issuance. Holder balances
+// travel here rather than in Alloc so module vaults and addresses that never
+// signed a transaction are included.
+type IssuedAssetSpec struct {
+	Symbol          string            `json:"symbol"`
+	Name            string            `json:"name"`
+	Decimals        uint8             `json:"decimals"`
+	Issuer          string            `json:"issuer"`
+	MintAuthority   string            `json:"mintAuthority"`
+	FreezeAuthority string            `json:"freezeAuthority,omitempty"`
+	SupplyCap       string            `json:"supplyCap,omitempty"`
+	Minted          string            `json:"minted,omitempty"`
+	Burned          string            `json:"burned,omitempty"`
+	MintPaused      bool              `json:"mintPaused,omitempty"`
+	Supply          string            `json:"supply,omitempty"`
+	Holders         []AssetHolderSpec `json:"holders,omitempty"`
+	Frozen          []string          `json:"frozen,omitempty"`
+}
+
+type AssetHolderSpec struct {
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+}
+
+// IdentityStateSpec captures the alias registry. ListingNonce is the nonce of
+// the most recent sale listing.
+type IdentityStateSpec struct {
+	Aliases      []IdentityAliasSpec `json:"aliases,omitempty"`
+	ListingNonce uint64              `json:"listingNonce,omitempty"`
+}
+
+type IdentityAliasSpec struct {
+	Alias      string                 `json:"alias"`
+	Owner      string                 `json:"owner"`
+	Primary    string                 `json:"primary,omitempty"`
+	Addresses  []string               `json:"addresses,omitempty"`
+	AvatarRef  string                 `json:"avatarRef,omitempty"`
+	CreatedAt  int64                  `json:"createdAt"`
+	UpdatedAt  int64                  `json:"updatedAt"`
+	ExpiresAt  int64                  `json:"expiresAt,omitempty"`
+	Records    map[string]string      `json:"records,omitempty"`
+	SubAliases []IdentitySubAliasSpec `json:"subAliases,omitempty"`
+	Listing    *IdentityListingSpec   `json:"listing,omitempty"`
+}
+
+type IdentitySubAliasSpec struct {
+	Label     string `json:"label"`
+	Target    string `json:"target"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+type IdentityListingSpec struct {
+	Seller   string `json:"seller"`
+	Buyer    string `json:"buyer,omitempty"`
+	Price    string `json:"price,omitempty"`
+	Nonce    uint64 `json:"nonce"`
+	ListedAt int64  `json:"listedAt"`
+}
+
+type InvoiceSpec struct {
+	ID           string `json:"id"`
+	Merchant     string `json:"merchant"`
+	Asset        string `json:"asset"`
+	Amount       string `json:"amount"`
+	Paid         string `json:"paid,omitempty"`
+	MemoHash     string `json:"memoHash,omitempty"`
+	AllowPartial bool   `json:"allowPartial,omitempty"`
+	Status       string `json:"status"`
+	CreatedAt    uint64 `json:"createdAt"`
+	Expiry       uint64 `json:"expiry,omitempty"`
+	SettledAt    uint64 `json:"settledAt,omitempty"`
+}
+
+type MandateSpec struct {
+	ID             string `json:"id"`
+	Payer          string `json:"payer"`
+	Merchant       string `json:"merchant"`
+	Asset          string `json:"asset"`
+	MaxAmount      string `json:"maxAmount"`
+	PeriodSeconds  uint64 `json:"periodSeconds"`
+	StartAt        uint64 `json:"startAt"`
+	EndAt          uint64 `json:"endAt,omitempty"`
+	CreatedAt      uint64 `json:"createdAt"`
+	Status         string `json:"status"`
+	LastPullPeriod uint64 `json:"lastPullPeriod,omitempty"`
+	PulledTotal    string `json:"pulledTotal,omitempty"`
+	PullCount      uint64 `json:"pullCount,omitempty"`
+	FailedPulls    uint64 `json:"failedPulls,omitempty"`
+	LastFailureAt  uint64 `json:"lastFailureAt,omitempty"`
+	CancelledAt    uint64 `json:"cancelledAt,omitempty"`
+}
+
+type SessionKeySpec struct {
+	Key            string   `json:"key"`
+	Account        string   `json:"account"`
+	TxTypes        []uint32 `json:"txTypes"`
+	DailyLimitNHB  string   `json:"dailyLimitNHB,omitempty"`
+	DailyLimitZNHB string   `json:"dailyLimitZNHB,omitempty"`
+	Counterparties []string `json:"counterparties,omitempty"`
+	ExpiresAt      uint64   `json:"expiresAt"`
+	CreatedAt      uint64   `json:"createdAt"`
+	SpentDay       string   `json:"spentDay,omitempty"`
+	SpentNHB       string   `json:"spentNHB,omitempty"`
+	SpentZNHB      string   `json:"spentZNHB,omitempty"`
+}
+
+// RecoveryStateSpec captures guardian sets and pending key rotations.
+type RecoveryStateSpec struct {
+	Guardians []RecoveryGuardiansSpec `json:"guardians,omitempty"`
+	Pending   []RecoveryPendingSpec   `json:"pending,omitempty"`
+}
+
+type RecoveryGuardiansSpec struct {
+	Account      string   `json:"account"`
+	Guardians    []string `json:"guardians"`
+	Threshold    uint32   `json:"threshold"`
+	DelaySeconds uint64   `json:"delaySeconds"`
+	UpdatedAt    uint64   `json:"updatedAt"`
+}
+
+type RecoveryPendingSpec struct {
+	Account      string             `json:"account"`
+	NewAddress   string             `json:"newAddress,omitempty"`
+	Votes        []RecoveryVoteSpec `json:"votes,omitempty"`
+	CreatedAt    uint64             `json:"createdAt"`
+	ExecutableAt uint64             `json:"executableAt,omitempty"`
+}
+
+type RecoveryVoteSpec struct {
+	Guardian   string `json:"guardian"`
+	NewAddress string `json:"newAddress"`
+}
+
+// CreatorStateSpec captures creator subscription tiers and fan subscriptions.
+type CreatorStateSpec struct {
+	Tiers         []CreatorTierSpec         `json:"tiers,omitempty"`
+	Subscriptions []CreatorSubscriptionSpec `json:"subscriptions,omitempty"`
+}
+
+type CreatorTierSpec struct {
+	Creator       string `json:"creator"`
+	ID            string `json:"id"`
+	Name          string `json:"name,omitempty"`
+	Asset         string `json:"asset"`
+	Price         string `json:"price,omitempty"`
+	PeriodSeconds int64  `json:"periodSeconds"`
+	Active        bool   `json:"active,omitempty"`
+	UpdatedAt     int64  `json:"updatedAt"`
+}
+
+type CreatorSubscriptionSpec struct {
+	Creator       string `json:"creator"`
+	Fan           string `json:"fan"`
+	TierID        string `json:"tierId"`
+	Asset         string `json:"asset"`
+	Price         string `json:"price,omitempty"`
+	PeriodSeconds int64  `json:"periodSeconds"`
+	StartedAt     int64  `json:"startedAt"`
+	PaidThrough   int64  `json:"paidThrough"`
+	Cancelled     bool   `json:"cancelled,omitempty"`
+	CancelledAt   int64  `json:"cancelledAt,omitempty"`
+}
+
+type EngagementDeviceSpec struct {
+	DeviceID      string `json:"deviceId"`
+	Owner         string `json:"owner"`
+	TokenHash     string `json:"tokenHash"`
+	RegisteredAt  uint64 `json:"registeredAt"`
+	LastHeartbeat uint64 `json:"lastHeartbeat,omitempty"`
+}
+
+type ReputationSpec struct {
+	Address           string                 `json:"address"`
+	FirstSeen         int64                  `json:"firstSeen,omitempty"`
+	UpdatedAt         int64                  `json:"updatedAt,omitempty"`
+	Attestations      []ReputationWeightSpec `json:"attestations,omitempty"`
+	EscrowsCompleted  uint64                 `json:"escrowsCompleted,omitempty"`
+	EscrowsDisputed   uint64                 `json:"escrowsDisputed,omitempty"`
+	TradesCompleted   uint64                 `json:"tradesCompleted,omitempty"`
+	TradesDisputed    uint64                 `json:"tradesDisputed,omitempty"`
+	ArbitrationWins   uint64                 `json:"arbitrationWins,omitempty"`
+	ArbitrationLosses uint64                 `json:"arbitrationLosses,omitempty"`
+}
+
+type ReputationWeightSpec struct {
+	ID        string `json:"id"`
+	Verifier  string `json:"verifier"`
+	Weight    uint32 `json:"weight"`
+	ExpiresAt int64  `json:"expiresAt,omitempty"`
+}
+
+func formatAmount(v *big.Int) string {
+	if v == nil || v.Sign() == 0 {
+		return ""
+	}
+	return v.String()
+}
+
+func parseOptionalAmount(value string) (*big.Int, error) {
+	if strings.TrimSpace(value) == "" {
+		return big.NewInt(0), nil
+	}
+	return parseAmountString(value)
+}
+
+// amountField binds an optional amount string to the big.Int it populates.
+type amountField struct {
+	name  string
+	value string
+	dst   **big.Int
+}
+
+func parseAmountFields(fields []amountField) error {
+	for _, field := range fields {
+		amount, err := parseOptionalAmount(field.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.name, err)
+		}
+		*field.dst = amount
+	}
+	return nil
+}
+
+func formatAddress(addr []byte) string {
+	if len(addr) == 0 {
+		return ""
+	}
+	var zero [20]byte
+	if len(addr) == len(zero) && string(addr) == string(zero[:]) {
+		return ""
+	}
+	return crypto.MustNewAddress(crypto.NHBPrefix, addr).String()
+}
+
+func parseOptionalAddress(value string) ([20]byte, error) {
+	if strings.TrimSpace(value) == "" {
+		return [20]byte{}, nil
+	}
+	return ParseBech32Account(strings.TrimSpace(value))
+}
+
+func formatHash(b []byte) string {
+	for _, v := range b {
+		if v != 0 {
+			return "0x" + hex.EncodeToString(b)
+		}
+	}
+	return ""
+}
+
+func parseHash32(value string) ([32]byte, error) {
+	var out [32]byte
+	trimmed := strings.TrimPrefix(strings.TrimSpace(value), "0x")
+	if trimmed == "" {
+		return out, nil
+	}
+	decoded, err := hex.DecodeString(trimmed)
+	if err != nil {
+		return out, err
+	}
+	if len(decoded) != len(out) {
+		return out, fmt.Errorf("expected 32 bytes, got %d", len(decoded))
+	}
+	copy(out[:], decoded)
+	return out, nil
+}
+
+func parseHexBytes(value string) ([]byte, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(value), "0x")
+	if trimmed == "" {
+		return nil, nil
+	}
+	return hex.DecodeString(trimmed)
+}
+
+func formatValidatorSet(set map[string]*big.Int) map[string]string {
+	if len(set) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(set))
+	for key, power := range set {
+		value := "0"
+		if power != nil {
+			value = power.String()
+		}
+		out[formatAddress([]byte(key))] = value
+	}
+	return out
+}
+
+func parseValidatorSet(entries map[string]string) (map[string]*big.Int, error) {
+	set := make(map[string]*big.Int, len(entries))
+	for addrStr, powerStr := range entries {
+		addr, err := ParseBech32Account(addrStr)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", addrStr, err)
+		}
+		power, err := parseAmountString(powerStr)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", addrStr, err)
+		}
+		set[string(addr[:])] = power
+	}
+	return set, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// applyAccountState overlays the exported account state on accounts already
+// credited through Alloc.
+func applyAccountState(manager *state.Manager, accounts map[string]*AccountStateSpec) error {
+	usernames := make(map[string][]byte)
+	for _, addrStr := range sortedKeys(accounts) {
+		spec := accounts[addrStr]
+		if spec == nil {
+			spec = &AccountStateSpec{}
+		}
+		parsed, err := ParseBech32Account(addrStr)
+		if err != nil {
+			return fmt.Errorf("accounts[%q]: %w", addrStr, err)
+		}
+		addr := parsed[:]
+		account, err := manager.GetAccount(addr)
+		if err != nil {
+			return fmt.Errorf("accounts[%q]: %w", addrStr, err)
+		}
+		if err := spec.apply(account); err != nil {
+			return fmt.Errorf("accounts[%q]: %w", addrStr, err)
+		}
+		if err := manager.PutAccount(addr, account); err != nil {
+			return fmt.Errorf("accounts[%q]: %w", addrStr, err)
+		}
+		if account.Username != "" {
+			usernames[account.Username] = append([]byte(nil), addr...)
+		}
+		if spec.StakingRewards != nil {
+			rewards, err := spec.StakingRewards.rewards()
+			if err != nil {
+				return fmt.Errorf("accounts[%q].stakingRewards: %w", addrStr, err)
+			}
+			if err := manager.PutAccountStakingRewards(addr, rewards); err != nil {
+				return fmt.Errorf("accounts[%q].stakingRewards: %w", addrStr, err)
+			}
+		}
+		if spec.GovernanceEscrow != "" {
+			amount, err := parseAmountString(spec.GovernanceEscrow)
+			if err != nil {
+				return fmt.Errorf("accounts[%q].governanceEscrow: %w", addrStr, err)
+			}
+			if _, err := manager.GovernanceEscrowLock(addr, amount); err != nil {
+				return fmt.Errorf("accounts[%q].governanceEscrow: %w", addrStr, err)
+			}
+		}
+		if spec.LoyaltyBaseAccrued != "" {
+			amount, err := parseAmountString(spec.LoyaltyBaseAccrued)
+			if err != nil {
+				return fmt.Errorf("accounts[%q].loyaltyBaseAccrued: %w", addrStr, err)
+			}
+			if err := manager.SetLoyaltyBaseTotalAccrued(addr, amount); err != nil {
+				return fmt.Errorf("accounts[%q].loyaltyBaseAccrued: %w", addrStr, err)
+			}
+		}
+	}
+	if len(usernames) == 0 {
+		return nil
+	}
+	return manager.WriteUsernameIndex(usernames)
+}
+
+func (s *AccountStateSpec) apply(account *types.Account) error {
+	fields := []amountField{
+		{"stake", s.Stake, &account.Stake},
+		{"stakeShares", s.StakeShares, &account.StakeShares},
+		{"stakeLastIndex", s.StakeLastIndex, &account.StakeLastIndex},
+		{"lockedZNHB", s.LockedZNHB, &account.LockedZNHB},
+	}
+	if l := s.Lending; l != nil {
+		fields = append(fields,
+			amountField{"lending.collateralBalance", l.CollateralBalance, &account.CollateralBalance},
+			amountField{"lending.debtPrincipal", l.DebtPrincipal, &account.DebtPrincipal},
+			amountField{"lending.supplyShares", l.SupplyShares, &account.SupplyShares},
+			amountField{"lending.supplyIndex", l.SupplyIndex, &account.LendingSnapshot.SupplyIndex},
+			amountField{"lending.borrowIndex", l.BorrowIndex, &account.LendingSnapshot.BorrowIndex},
+		)
+		account.LendingBreaker = types.LendingBreakerFlags{
+			CollateralDisabled: l.CollateralDisabled,
+			BorrowDisabled:     l.BorrowDisabled,
+		}
+	}
+	if err := parseAmountFields(fields); err != nil {
+		return err
+	}
+	var err error
+	account.Nonce = s.Nonce
+	account.StakeLastPayoutTs = s.StakeLastPayoutTs
+	account.NextUnbondingID = s.NextUnbondingID
+	account.Username = s.Username
+	account.DelegatedValidator = nil
+	if s.DelegatedValidator != "" {
+		addr, err := ParseBech32Account(s.DelegatedValidator)
+		if err != nil {
+			return fmt.Errorf("delegatedValidator: %w", err)
+		}
+		account.DelegatedValidator = addr[:]
+	}
+	account.RewardBeneficiary = nil
+	if s.RewardBeneficiary != "" {
+		addr, err := ParseBech32Account(s.RewardBeneficiary)
+		if err != nil {
+			return fmt.Errorf("rewardBeneficiary: %w", err)
+		}
+		account.RewardBeneficiary = addr[:]
+	}
+	account.PendingUnbonds = nil
+	for i, unbond := range s.PendingUnbonds {
+		entry := types.StakeUnbond{ID: unbond.ID, ReleaseTime: unbond.ReleaseTime}
+		if unbond.Validator != "" {
+			addr, err := ParseBech32Account(unbond.Validator)
+			if err != nil {
+				return fmt.Errorf("pendingUnbonds[%d].validator: %w", i, err)
+			}
+			entry.Validator = addr[:]
+		}
+		if entry.Amount, err = parseOptionalAmount(unbond.Amount); err != nil {
+			return fmt.Errorf("pendingUnbonds[%d].amount: %w", i, err)
+		}
+		account.PendingUnbonds = append(account.PendingUnbonds, entry)
+	}
+	if e := s.Engagement; e != nil {
+		account.EngagementScore = e.Score
+		account.EngagementDay = e.Day
+		account.EngagementMinutes = e.Minutes
+		account.EngagementTxCount = e.TxCount
+		account.EngagementEscrowEvents = e.EscrowEvents
+		account.EngagementGovEvents = e.GovEvents
+		account.EngagementLastHeartbeat = e.LastHeartbeat
+	}
+	return nil
+}
+
+func (s *StakingRewardsSpec) rewards() (*types.StakingRewards, error) {
+	accrued, err := parseOptionalAmount(s.AccruedZNHB)
+	if err != nil {
+		return nil, fmt.Errorf("accruedZNHB: %w", err)
+	}
+	index, err := parseHexBytes(s.LastIndex)
+	if err != nil {
+		return nil, fmt.Errorf("lastIndex: %w", err)
+	}
+	return &types.StakingRewards{
+		AccruedZNHB:        accrued,
+		LastIndexUQ128x128: types.Uint128x128FromBytes(index),
+		LastPayoutUnix:     s.LastPayoutUnix,
+	}, nil
+}
+
+func applyStakingState(manager *state.Manager, spec *StakingStateSpec) error {
+	index, err := parseHexBytes(spec.GlobalIndex)
+	if err != nil {
+		return fmt.Errorf("globalIndex: %w", err)
+	}
+	ytd, err := parseOptionalAmount(spec.YTDEmissions)
+	if err != nil {
+		return fmt.Errorf("ytdEmissions: %w", err)
+	}
+	if err := manager.PutGlobalIndex(&state.GlobalIndex{UQ128x128: index, LastUpdateUnix: spec.LastIndexUpdate, YTDEmissions: ytd}); err != nil {
+		return fmt.Errorf("globalIndex: %w", err)
+	}
+	if spec.EmissionYear == 0 {
+		return nil
+	}
+	if spec.StakingEmissionYTD != "" {
+		total, err := parseAmountString(spec.StakingEmissionYTD)
+		if err != nil {
+			return fmt.Errorf("stakingEmissionYtd: %w", err)
+		}
+		if err := manager.SetStakingEmissionYTD(spec.EmissionYear, total); err != nil {
+			return fmt.Errorf("stakingEmissionYtd: %w", err)
+		}
+	}
+	for _, token := range sortedKeys(spec.MintEmissionYTD) {
+		total, err := parseAmountString(spec.MintEmissionYTD[token])
+		if err != nil {
+			return fmt.Errorf("mintEmissionYtd[%q]: %w", token, err)
+		}
+		if err := manager.SetMintEmissionYTD(token, spec.EmissionYear, total); err != nil {
+			return fmt.Errorf("mintEmissionYtd[%q]: %w", token, err)
+		}
+	}
+	return nil
+}
+
+func (s *RealmFeeScheduleSpec) schedule() (*escrow.RealmFeeSchedule, error) {
+	if s == nil {
+		return nil, nil
+	}
+	recipient, err := parseOptionalAddress(s.Recipient)
+	if err != nil {
+		return nil, fmt.Errorf("recipient: %w", err)
+	}
+	return &escrow.RealmFeeSchedule{FeeBps: s.FeeBps, Recipient: recipient, ArbitratorFeeBps: s.ArbitratorFeeBps}, nil
+}
+
+func (s *ArbitratorSetSpec) set() (*escrow.ArbitratorSet, error) {
+	if s == nil {
+		return nil, nil
+	}
+	members, err := parseMembers(s.Members)
+	if err != nil {
+		return nil, err
+	}
+	return &escrow.ArbitratorSet{
+		Scheme:    escrow.ArbitrationScheme(s.Scheme),
+		Threshold: s.Threshold,
+		Members:   members,
+	}, nil
+}
+
+func (s *RealmMetadataSpec) metadata() *escrow.EscrowRealmMetadata {
+	if s == nil {
+		return nil
+	}
+	return &escrow.EscrowRealmMetadata{
+		Scope:              escrow.EscrowRealmScope(s.Scope),
+		ProviderProfile:    s.ProviderProfile,
+		ArbitrationFeeBps:  s.ArbitrationFeeBps,
+		FeeRecipientBech32: s.FeeRecipient,
+	}
+}
+
+func parseMembers(members []string) ([][20]byte, error) {
+	out := make([][20]byte, 0, len(members))
+	for i, member := range members {
+		addr, err := ParseBech32Account(member)
+		if err != nil {
+			return nil, fmt.Errorf("members[%d]: %w", i, err)
+		}
+		out = append(out, addr)
+	}
+	return out, nil
+}
+
+func applyEscrowState(manager *state.Manager, spec *EscrowStateSpec) error {
+	for i, realmSpec := range spec.Realms {
+		members, err := parseMembers(realmSpec.Members)
+		if err != nil {
+			return fmt.Errorf("realms[%d]: %w", i, err)
+		}
+		fees, err := realmSpec.FeeSchedule.schedule()
+		if err != nil {
+			return fmt.Errorf("realms[%d].feeSchedule: %w", i, err)
+		}
+		fallback, err := realmSpec.Fallback.set()
+		if err != nil {
+			return fmt.Errorf("realms[%d].fallback: %w", i, err)
+		}
+		realm := &escrow.EscrowRealm{
+			ID:              realmSpec.ID,
+			Version:         realmSpec.Version,
+			NextPolicyNonce: realmSpec.NextPolicyNonce,
+			CreatedAt:       realmSpec.CreatedAt,
+			UpdatedAt:       realmSpec.UpdatedAt,
+			Arbitrators: &escrow.ArbitratorSet{
+				Scheme:    escrow.ArbitrationScheme(realmSpec.Scheme),
+				Threshold: realmSpec.Threshold,
+				Members:   members,
+			},
+			FeeSchedule: fees,
+			Metadata:    realmSpec.Metadata.metadata(),
+
+			EvidenceWindowSeconds: realmSpec.EvidenceWindowSeconds,
+			ArbitrationSLASeconds: realmSpec.ArbitrationSLASeconds,
+			FallbackArbitrators:   fallback,
+			MinReputationScore:    realmSpec.MinReputationScore,
+		}
+		if err := manager.EscrowRealmPut(realm); err != nil {
+			return fmt.Errorf("realms[%d]: %w", i, err)
+		}
+	}
+	for i, record := range spec.Escrows {
+		e, err := record.escrow()
+		if err != nil {
+			return fmt.Errorf("escrows[%d]: %w", i, err)
+		}
+		if err := manager.EscrowPut(e); err != nil {
+			return fmt.Errorf("escrows[%d]: %w", i, err)
+		}
+		if e.FrozenArb != nil {
+			if err := manager.EscrowFrozenPolicyPut(e.ID, e.FrozenArb); err != nil {
+				return fmt.Errorf("escrows[%d].frozenArb: %w", i, err)
+			}
+		}
+		for _, token := range sortedKeys(record.Balances) {
+			amount, err := parseAmountString(record.Balances[token])
+			if err != nil {
+				return fmt.Errorf("escrows[%d].balances[%q]: %w", i, token, err)
+			}
+			if err := manager.EscrowCredit(e.ID, token, amount); err != nil {
+				return fmt.Errorf("escrows[%d].balances[%q]: %w", i, token, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *EscrowRecordSpec) escrow() (*escrow.Escrow, error) {
+	id, err := parseHash32(s.ID)
+	if err != nil {
+		return nil, fmt.Errorf("id: %w", err)
+	}
+	payer, err := ParseBech32Account(s.Payer)
+	if err != nil {
+		return nil, fmt.Errorf("payer: %w", err)
+	}
+	payee, err := ParseBech32Account(s.Payee)
+	if err != nil {
+		return nil, fmt.Errorf("payee: %w", err)
+	}
+	mediator, err := parseOptionalAddress(s.Mediator)
+	if err != nil {
+		return nil, fmt.Errorf("mediator: %w", err)
+	}
+	amount, err := parseOptionalAmount(s.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("amount: %w", err)
+	}
+	metaHash, err := parseHash32(s.MetaHash)
+	if err != nil {
+		return nil, fmt.Errorf("metaHash: %w", err)
+	}
+	resolution, err := parseHash32(s.ResolutionHash)
+	if err != nil {
+		return nil, fmt.Errorf("resolutionHash: %w", err)
+	}
+	e := &escrow.Escrow{
+		ID:             id,
+		Payer:          payer,
+		Payee:          payee,
+		Mediator:       mediator,
+		Token:          s.Token,
+		Amount:         amount,
+		FeeBps:         s.FeeBps,
+		Deadline:       s.Deadline,
+		CreatedAt:      s.CreatedAt,
+		Nonce:          s.Nonce,
+		MetaHash:       metaHash,
+		Status:         escrow.EscrowStatus(s.Status),
+		RealmID:        s.RealmID,
+		ResolutionHash: resolution,
+		DisputeReason:  s.DisputeReason,
+		DisputedAt:     s.DisputedAt,
+		EscalatedAt:    s.EscalatedAt,
+	}
+	for i, item := range s.Evidence {
+		submitter, err := ParseBech32Account(item.Submitter)
+		if err != nil {
+			return nil, fmt.Errorf("evidence[%d].submitter: %w", i, err)
+		}
+		hash, err := parseHash32(item.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("evidence[%d].hash: %w", i, err)
+		}
+		e.Evidence = append(e.Evidence, escrow.EscrowEvidence{
+			Submitter:   submitter,
+			Hash:        hash,
+			URI:         item.URI,
+			SubmittedAt: item.SubmittedAt,
+		})
+	}
+	if f := s.FrozenArb; f != nil {
+		members, err := parseMembers(f.Members)
+		if err != nil {
+			return nil, fmt.Errorf("frozenArb: %w", err)
+		}
+		fees, err := f.FeeSchedule.schedule()
+		if err != nil {
+			return nil, fmt.Errorf("frozenArb.feeSchedule: %w", err)
+		}
+		fallback, err := f.Fallback.set()
+		if err != nil {
+			return nil, fmt.Errorf("frozenArb.fallback: %w", err)
+		}
+		e.FrozenArb = &escrow.FrozenArb{
+			RealmID:      f.RealmID,
+			RealmVersion: f.RealmVersion,
+			PolicyNonce:  f.PolicyNonce,
+			Scheme:       escrow.ArbitrationScheme(f.Scheme),
+			Threshold:    f.Threshold,
+			Members:      members,
+			FrozenAt:     f.FrozenAt,
+			FeeSchedule:  fees,
+			Metadata:     f.Metadata.metadata(),
+
+			EvidenceWindowSeconds: f.EvidenceWindowSeconds,
+			ArbitrationSLASeconds: f.ArbitrationSLASeconds,
+			Fallback:              fallback,
+		}
+	}
+	return e, nil
+}
+
+func applyLoyaltyState(manager *state.Manager, spec *LoyaltyStateSpec) error {
+	if c := spec.Controller; c != nil {
+		ytd, err := parseOptionalAmount(c.YtdEmissionsZNHB)
+		if err != nil {
+			return fmt.Errorf("controller.ytdEmissionsZNHB: %w", err)
+		}
+		yearlyCap, err := parseOptionalAmount(c.YearlyCapZNHB)
+		if err != nil {
+			return fmt.Errorf("controller.yearlyCapZNHB: %w", err)
+		}
+		if err := manager.SetLoyaltyDynamicState(&state.LoyaltyEngineState{
+			EffectiveBps:     c.EffectiveBps,
+			TargetBps:        c.TargetBps,
+			MinBps:           c.MinBps,
+			MaxBps:           c.MaxBps,
+			SmoothingStepBps: c.SmoothingStepBps,
+			YtdEmissionsZNHB: ytd,
+			YearlyCapZNHB:    yearlyCap,
+		}); err != nil {
+			return err
+		}
+	}
+	registry := loyalty.NewRegistry(manager)
+	for i, businessSpec := range spec.Businesses {
+		business, err := businessSpec.business()
+		if err != nil {
+			return fmt.Errorf("businesses[%d]: %w", i, err)
+		}
+		if err := registry.RestoreBusiness(business); err != nil {
+			return fmt.Errorf("businesses[%d]: %w", i, err)
+		}
+		if businessSpec.ActivePaymaster {
+			if err := registry.RestoreActivePaymaster(business.Owner, business.ID); err != nil {
+				return fmt.Errorf("businesses[%d]: %w", i, err)
+			}
+		}
+	}
+	for i, programSpec := range spec.Programs {
+		program, err := programSpec.program()
+		if err != nil {
+			return fmt.Errorf("programs[%d]: %w", i, err)
+		}
+		if err := registry.RestoreProgram(program); err != nil {
+			return fmt.Errorf("programs[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s *LoyaltyBusinessSpec) business() (*loyalty.Business, error) {
+	id, err := parseHash32(s.ID)
+	if err != nil {
+		return nil, fmt.Errorf("id: %w", err)
+	}
+	owner, err := ParseBech32Account(s.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("owner: %w", err)
+	}
+	paymaster, err := parseOptionalAddress(s.Paymaster)
+	if err != nil {
+		return nil, fmt.Errorf("paymaster: %w", err)
+	}
+	merchants, err := parseMembers(s.Merchants)
+	if err != nil {
+		return nil, fmt.Errorf("merchants: %w", err)
+	}
+	business := &loyalty.Business{
+		ID:        loyalty.BusinessID(id),
+		Owner:     owner,
+		Name:      s.Name,
+		Paymaster: paymaster,
+		Merchants: merchants,
+	}
+	if err := parseAmountFields([]amountField{
+		{"paymasterReserveMin", s.PaymasterReserveMin, &business.PaymasterReserveMin},
+	}); err != nil {
+		return nil, err
+	}
+	return business, nil
+}
+
+func (s *LoyaltyProgramSpec) program() (*loyalty.Program, error) {
+	id, err := parseHash32(s.ID)
+	if err != nil {
+		return nil, fmt.Errorf("id: %w", err)
+	}
+	owner, err := ParseBech32Account(s.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("owner: %w", err)
+	}
+	pool, err := parseOptionalAddress(s.Pool)
+	if err != nil {
+		return nil, fmt.Errorf("pool: %w", err)
+	}
+	program := &loyalty.Program{
+		ID:                 loyalty.ProgramID(id),
+		Owner:              owner,
+		Pool:               pool,
+		TokenSymbol:        s.TokenSymbol,
+		AccrualBps:         s.AccrualBps,
+		EpochLengthSeconds: s.EpochLengthSeconds,
+		StartTime:          s.StartTime,
+		EndTime:            s.EndTime,
+		Active:             s.Active,
+		TierWindowDays:     s.TierWindowDays,
+		RewardExpiryDays:   s.RewardExpiryDays,
+	}
+	if err := parseAmountFields([]amountField{
+		{"minSpendWei", s.MinSpendWei, &program.MinSpendWei},
+		{"capPerTx", s.CapPerTx, &program.CapPerTx},
+		{"dailyCapUser", s.DailyCapUser, &program.DailyCapUser},
+		{"dailyCapProgram", s.DailyCapProgram, &program.DailyCapProgram},
+		{"epochCapProgram", s.EpochCapProgram, &program.EpochCapProgram},
+		{"issuanceCapUser", s.IssuanceCapUser, &program.IssuanceCapUser},
+	}); err != nil {
+		return nil, err
+	}
+	for i, tierSpec := range s.Tiers {
+		tier := loyalty.Tier{Name: tierSpec.Name, AccrualBps: tierSpec.AccrualBps}
+		if err := parseAmountFields([]amountField{
+			{fmt.Sprintf("tiers[%d].minSpendWei", i), tierSpec.MinSpendWei, &tier.MinSpendWei},
+		}); err != nil {
+			return nil, err
+		}
+		program.Tiers = append(program.Tiers, tier)
+	}
+	return program, nil
+}
+
+func applyLendingState(manager *state.Manager, spec *LendingStateSpec) error {
+	for i, marketSpec := range spec.Markets {
+		market := &lending.Market{
+			PoolID:              marketSpec.PoolID,
+			DeveloperFeeBps:     marketSpec.DeveloperFeeBps,
+			LastUpdateBlock:     marketSpec.LastUpdateBlock,
+			ReserveFactor:       marketSpec.ReserveFactor,
+			BorrowAsset:         marketSpec.BorrowAsset,
+			BorrowOracleFeed:    marketSpec.BorrowOracleFeed,
+			OracleMaxAgeSeconds: marketSpec.OracleMaxAgeSeconds,
+		}
+		for j, collateralSpec := range marketSpec.Collateral {
+			if !lending.ValidAsset(collateralSpec.Asset) {
+				return fmt.Errorf("markets[%d].collateral[%d].asset: unsupported asset %q", i, j, collateralSpec.Asset)
+			}
+			supplyCap, err := parseOptionalAmount(collateralSpec.SupplyCap)
+			if err != nil {
+				return fmt.Errorf("markets[%d].collateral[%d].supplyCap: %w", i, j, err)
+			}
+			market.Collateral = append(market.Collateral, lending.CollateralAsset{
+				Asset:                lending.NormalizeAsset(collateralSpec.Asset),
+				MaxLTV:               collateralSpec.MaxLTV,
+				LiquidationThreshold: collateralSpec.LiquidationThreshold,
+				OracleFeed:           collateralSpec.OracleFeed,
+				SupplyCap:            supplyCap,
+			})
+		}
+		totals, err := parseLendingAssetAmounts(marketSpec.TotalCollateral)
+		if err != nil {
+			return fmt.Errorf("markets[%d].totalCollateral%w", i, err)
+		}
+		market.TotalCollateral = totals
+		for _, field := range []struct {
+			name  string
+			value string
+			dst   *crypto.Address
+		}{
+			{"developerOwner", marketSpec.DeveloperOwner, &market.DeveloperOwner},
+			{"developerFeeCollector", marketSpec.DeveloperFeeCollector, &market.DeveloperFeeCollector},
+		} {
+			if field.value == "" {
+				continue
+			}
+			addr, err := ParseBech32Account(field.value)
+			if err != nil {
+				return fmt.Errorf("markets[%d].%s: %w", i, field.name, err)
+			}
+			*field.dst = crypto.MustNewAddress(crypto.NHBPrefix, addr[:])
+		}
+		var fees lending.FeeAccrual
+		if err := parseAmountFields([]amountField{
+			{"totalNHBSupplied", marketSpec.TotalNHBSupplied, &market.TotalNHBSupplied},
+			{"totalSupplyShares", marketSpec.TotalSupplyShares, &market.TotalSupplyShares},
+			{"totalNHBBorrowed", marketSpec.TotalNHBBorrowed, &market.TotalNHBBorrowed},
+			{"supplyIndex", marketSpec.SupplyIndex, &market.SupplyIndex},
+			{"borrowIndex", marketSpec.BorrowIndex, &market.BorrowIndex},
+			{"protocolFees", marketSpec.ProtocolFees, &fees.ProtocolFeesWei},
+			{"developerFees", marketSpec.DeveloperFees, &fees.DeveloperFeesWei},
+		}); err != nil {
+			return fmt.Errorf("markets[%d].%w", i, err)
+		}
+		if err := manager.LendingPutMarket(market.PoolID, market); err != nil {
+			return fmt.Errorf("markets[%d]: %w", i, err)
+		}
+		if err := manager.LendingPutFeeAccrual(market.PoolID, &fees); err != nil {
+			return fmt.Errorf("markets[%d]: %w", i, err)
+		}
+		for j, userSpec := range marketSpec.Users {
+			addr, err := ParseBech32Account(userSpec.Address)
+			if err != nil {
+				return fmt.Errorf("markets[%d].users[%d]: %w", i, j, err)
+			}
+			user := &lending.UserAccount{Address: crypto.MustNewAddress(crypto.NHBPrefix, addr[:])}
+			collateral, err := parseLendingAssetAmounts(userSpec.Collateral)
+			if err != nil {
+				return fmt.Errorf("markets[%d].users[%d].collateral%w", i, j, err)
+			}
+			user.Collateral = collateral
+			var legacyCollateral *big.Int
+			if err := parseAmountFields([]amountField{
+				{"collateralZNHB", userSpec.CollateralZNHB, &legacyCollateral},
+				{"supplyShares", userSpec.SupplyShares, &user.SupplyShares},
+				{"debtNHB", userSpec.DebtNHB, &user.DebtNHB},
+				{"scaledDebt", userSpec.ScaledDebt, &user.ScaledDebt},
+			}); err != nil {
+				return fmt.Errorf("markets[%d].users[%d].%w", i, j, err)
+			}
+			if legacyCollateral.Sign() > 0 {
+				user.SetCollateral(lending.AssetZNHB, new(big.Int).Add(user.CollateralOf(lending.AssetZNHB), legacyCollateral))
+			}
+			if err := manager.LendingPutUserAccount(market.PoolID, user); err != nil {
+				return fmt.Errorf("markets[%d].users[%d]: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+func parseLendingAssetAmounts(values map[string]string) (map[string]*big.Int, error) {
+	amounts := make(map[string]*big.Int, len(values))
+	for _, asset := range sortedKeys(values) {
+		amount, err := parseAmountString(values[asset])
+		if err != nil {
+			return nil, fmt.Errorf("[%q]: %w", asset, err)
+		}
+		amounts[lending.NormalizeAsset(asset)] = amount
+	}
+	return amounts, nil
+}
+
+func applyGovernanceState(manager *state.Manager, spec *GovernanceStateSpec) error {
+	if spec.ProposalSequence > 0 {
+		if err := manager.KVPut(state.GovernanceSequenceKey(), spec.ProposalSequence); err != nil {
+			return fmt.Errorf("proposalSequence: %w", err)
+		}
+	}
+	for i, p := range spec.Proposals {
+		submitter, err := ParseBech32Account(p.Submitter)
+		if err != nil {
+			return fmt.Errorf("proposals[%d].submitter: %w", i, err)
+		}
+		deposit, err := parseOptionalAmount(p.Deposit)
+		if err != nil {
+			return fmt.Errorf("proposals[%d].deposit: %w", i, err)
+		}
+		proposal := &governance.Proposal{
+			ID:             p.ID,
+			Title:          p.Title,
+			Summary:        p.Summary,
+			MetadataURI:    p.MetadataURI,
+			Submitter:      crypto.MustNewAddress(crypto.NHBPrefix, submitter[:]),
+			Status:         governance.ProposalStatus(p.Status),
+			Deposit:        deposit,
+			SubmitTime:     time.Unix(p.SubmitTime, 0).UTC(),
+			VotingStart:    time.Unix(p.VotingStart, 0).UTC(),
+			VotingEnd:      time.Unix(p.VotingEnd, 0).UTC(),
+			TimelockEnd:    time.Unix(p.TimelockEnd, 0).UTC(),
+			Target:         p.Target,
+			ProposedChange: p.ProposedChange,
+			Queued:         p.Queued,
+		}
+		if err := manager.GovernancePutProposal(proposal); err != nil {
+			return fmt.Errorf("proposals[%d]: %w", i, err)
+		}
+		for j, v := range p.Votes {
+			voter, err := ParseBech32Account(v.Voter)
+			if err != nil {
+				return fmt.Errorf("proposals[%d].votes[%d]: %w", i, j, err)
+			}
+			vote := &governance.Vote{
+				ProposalID: p.ID,
+				Voter:      crypto.MustNewAddress(crypto.NHBPrefix, voter[:]),
+				Choice:     governance.VoteChoice(v.Choice),
+				PowerBps:   v.PowerBps,
+				Timestamp:  time.Unix(v.Timestamp, 0).UTC(),
+			}
+			if err := manager.GovernancePutVote(vote); err != nil {
+				return fmt.Errorf("proposals[%d].votes[%d]: %w", i, j, err)
+			}
+		}
+	}
+	for _, name := range sortedKeys(spec.Params) {
+		value, err := parseHexBytes(spec.Params[name])
+		if err != nil {
+			return fmt.Errorf("params[%q]: %w", name, err)
+		}
+		if err := manager.ParamStoreSet(name, value); err != nil {
+			return fmt.Errorf("params[%q]: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyIssuedAssets registers each issued asset and credits its holders.
+// SetBalance rebuilds the holder index and the running held total.
+func applyIssuedAssets(manager *state.Manager, assets []IssuedAssetSpec) error {
+	for i, a := range assets {
+		meta := &state.TokenMetadata{
+			Symbol:     a.Symbol,
+			Name:       a.Name,
+			Decimals:   a.Decimals,
+			MintPaused: a.MintPaused,
+		}
+		for _, field := range []struct {
+			name  string
+			value string
+			dst   *[]byte
+		}{
+			{"issuer", a.Issuer, &meta.Issuer},
+			{"mintAuthority", a.MintAuthority, &meta.MintAuthority},
+			{"freezeAuthority", a.FreezeAuthority, &meta.FreezeAuthority},
+		} {
+			if field.value == "" {
+				continue
+			}
+			addr, err := ParseBech32Account(field.value)
+			if err != nil {
+				return fmt.Errorf("issuedAssets[%d].%s: %w", i, field.name, err)
+			}
+			*field.dst = append([]byte(nil), addr[:]...)
+		}
+		var supply *big.Int
+		if err := parseAmountFields([]amountField{
+			{"supplyCap", a.SupplyCap, &meta.SupplyCap},
+			{"minted", a.Minted, &meta.Minted},
+			{"burned", a.Burned, &meta.Burned},
+			{"supply", a.Supply, &supply},
+		}); err != nil {
+			return fmt.Errorf("issuedAssets[%d].%w", i, err)
+		}
+		if err := manager.RestoreAsset(meta); err != nil {
+			return fmt.Errorf("issuedAssets[%d]: %w", i, err)
+		}
+		if err := manager.SetTokenSupply(meta.Symbol, supply); err != nil {
+			return fmt.Errorf("issuedAssets[%d].supply: %w", i, err)
+		}
+		for j, holder := range a.Holders {
+			addr, err := ParseBech32Account(holder.Address)
+			if err != nil {
+				return fmt.Errorf("issuedAssets[%d].holders[%d]: %w", i, j, err)
+			}
+			balance, err := parseAmountString(holder.Balance)
+			if err != nil {
+				return fmt.Errorf("issuedAssets[%d].holders[%d]: %w", i, j, err)
+			}
+			if err := manager.SetBalance(addr[:], meta.Symbol, balance); err != nil {
+				return fmt.Errorf("issuedAssets[%d].holders[%d]: %w", i, j, err)
+			}
+		}
+		for j, frozen := range a.Frozen {
+			addr, err := ParseBech32Account(frozen)
+			if err != nil {
+				return fmt.Errorf("issuedAssets[%d].frozen[%d]: %w", i, j, err)
+			}
+			if err := manager.SetAssetFrozen(meta.Symbol, addr[:], true); err != nil {
+				return fmt.Errorf("issuedAssets[%d].frozen[%d]: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+func applyIdentityState(manager *state.Manager, spec *IdentityStateSpec) error {
+	for i, a := range spec.Aliases {
+		record := &identity.AliasRecord{
+			Alias:     a.Alias,
+			AvatarRef: a.AvatarRef,
+			CreatedAt: a.CreatedAt,
+			UpdatedAt: a.UpdatedAt,
+			ExpiresAt: a.ExpiresAt,
+		}
+		var err error
+		if record.Owner, err = ParseBech32Account(a.Owner); err != nil {
+			return fmt.Errorf("aliases[%d].owner: %w", i, err)
+		}
+		if record.Primary, err = parseOptionalAddress(a.Primary); err != nil {
+			return fmt.Errorf("aliases[%d].primary: %w", i, err)
+		}
+		if record.Addresses, err = parseMembers(a.Addresses); err != nil {
+			return fmt.Errorf("aliases[%d].addresses: %w", i, err)
+		}
+		for _, key := range sortedKeys(a.Records) {
+			record.Records = append(record.Records, identity.TextRecord{Key: key, Value: a.Records[key]})
+		}
+		if err := manager.IdentityRestoreAlias(record); err != nil {
+			return fmt.Errorf("aliases[%d]: %w", i, err)
+		}
+		for j, sub := range a.SubAliases {
+			target, err := ParseBech32Account(sub.Target)
+			if err != nil {
+				return fmt.Errorf("aliases[%d].subAliases[%d].target: %w", i, j, err)
+			}
+			if err := manager.IdentityPutSubAlias(&identity.SubAlias{
+				Label:     sub.Label,
+				Parent:    a.Alias,
+				Target:    target,
+				CreatedAt: sub.CreatedAt,
+				UpdatedAt: sub.UpdatedAt,
+			}); err != nil {
+				return fmt.Errorf("aliases[%d].subAliases[%d]: %w", i, j, err)
+			}
+		}
+		if a.Listing != nil {
+			listing := &identity.Listing{Alias: a.Alias, Nonce: a.Listing.Nonce, ListedAt: a.Listing.ListedAt}
+			if listing.Seller, err = ParseBech32Account(a.Listing.Seller); err != nil {
+				return fmt.Errorf("aliases[%d].listing.seller: %w", i, err)
+			}
+			if listing.Buyer, err = parseOptionalAddress(a.Listing.Buyer); err != nil {
+				return fmt.Errorf("aliases[%d].listing.buyer: %w", i, err)
+			}
+			if listing.Price, err = parseOptionalAmount(a.Listing.Price); err != nil {
+				return fmt.Errorf("aliases[%d].listing.price: %w", i, err)
+			}
+			if err := manager.IdentityRestoreListing(listing); err != nil {
+				return fmt.Errorf("aliases[%d].listing: %w", i, err)
+			}
+		}
+	}
+	if spec.ListingNonce > 0 {
+		if err := manager.IdentitySetListingNonce(spec.ListingNonce); err != nil {
+			return fmt.Errorf("listingNonce: %w", err)
+		}
+	}
+	return nil
+}
+
+func applyInvoices(manager *state.Manager, invoices []InvoiceSpec) error {
+	for i, inv := range invoices {
+		record := &state.StoredInvoice{
+			Asset:        inv.Asset,
+			AllowPartial: inv.AllowPartial,
+			Status:       inv.Status,
+			CreatedAt:    inv.CreatedAt,
+			Expiry:       inv.Expiry,
+			SettledAt:    inv.SettledAt,
+		}
+		var err error
+		if record.ID, err = parseHash32(inv.ID); err != nil {
+			return fmt.Errorf("invoices[%d].id: %w", i, err)
+		}
+		if record.Merchant, err = ParseBech32Account(inv.Merchant); err != nil {
+			return fmt.Errorf("invoices[%d].merchant: %w", i, err)
+		}
+		if record.MemoHash, err = parseHash32(inv.MemoHash); err != nil {
+			return fmt.Errorf("invoices[%d].memoHash: %w", i, err)
+		}
+		if err := parseAmountFields([]amountField{
+			{"amount", inv.Amount, &record.Amount},
+			{"paid", inv.Paid, &record.Paid},
+		}); err != nil {
+			return fmt.Errorf("invoices[%d].%w", i, err)
+		}
+		if err := manager.CreateInvoice(record); err != nil {
+			return fmt.Errorf("invoices[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func applyMandates(manager *state.Manager, mandates []MandateSpec) error {
+	for i, m := range mandates {
+		record := &state.StoredMandate{
+			Asset:          m.Asset,
+			PeriodSeconds:  m.PeriodSeconds,
+			StartAt:        m.StartAt,
+			EndAt:          m.EndAt,
+			CreatedAt:      m.CreatedAt,
+			Status:         m.Status,
+			LastPullPeriod: m.LastPullPeriod,
+			PullCount:      m.PullCount,
+			FailedPulls:    m.FailedPulls,
+			LastFailureAt:  m.LastFailureAt,
+			CancelledAt:    m.CancelledAt,
+		}
+		var err error
+		if record.ID, err = parseHash32(m.ID); err != nil {
+			return fmt.Errorf("mandates[%d].id: %w", i, err)
+		}
+		if record.Payer, err = ParseBech32Account(m.Payer); err != nil {
+			return fmt.Errorf("mandates[%d].payer: %w", i, err)
+		}
+		if record.Merchant, err = ParseBech32Account(m.Merchant); err != nil {
+			return fmt.Errorf("mandates[%d].merchant: %w", i, err)
+		}
+		if err := parseAmountFields([]amountField{
+			{"maxAmount", m.MaxAmount, &record.MaxAmount},
+			{"pulledTotal", m.PulledTotal, &record.PulledTotal},
+		}); err != nil {
+			return fmt.Errorf("mandates[%d].%w", i, err)
+		}
+		if err := manager.CreateMandate(record); err != nil {
+			return fmt.Errorf("mandates[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func applySessionKeys(manager *state.Manager, keys []SessionKeySpec) error {
+	for i, k := range keys {
+		key := &state.SessionKey{
+			ExpiresAt: k.ExpiresAt,
+			CreatedAt: k.CreatedAt,
+			SpentDay:  k.SpentDay,
+		}
+		var err error
+		if key.Key, err = ParseBech32Account(k.Key); err != nil {
+			return fmt.Errorf("sessionKeys[%d].key: %w", i, err)
+		}
+		if key.Account, err = ParseBech32Account(k.Account); err != nil {
+			return fmt.Errorf("sessionKeys[%d].account: %w", i, err)
+		}
+		if key.Counterparties, err = parseMembers(k.Counterparties); err != nil {
+			return fmt.Errorf("sessionKeys[%d].counterparties: %w", i, err)
+		}
+		if len(key.Counterparties) == 0 {
+			key.Counterparties = nil
+		}
+		for _, t := range k.TxTypes {
+			if t > 0xff {
+				return fmt.Errorf("sessionKeys[%d].txTypes: invalid type %d", i, t)
+			}
+			key.TxTypes = append(key.TxTypes, uint8(t))
+		}
+		if err := parseAmountFields([]amountField{
+			{"dailyLimitNHB", k.DailyLimitNHB, &key.DailyLimitNHB},
+			{"dailyLimitZNHB", k.DailyLimitZNHB, &key.DailyLimitZNHB},
+			{"spentNHB", k.SpentNHB, &key.SpentNHB},
+			{"spentZNHB", k.SpentZNHB, &key.SpentZNHB},
+		}); err != nil {
+			return fmt.Errorf("sessionKeys[%d].%w", i, err)
+		}
+		if err := manager.SessionKeyPut(key); err != nil {
+			return fmt.Errorf("sessionKeys[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func applyRecoveryState(manager *state.Manager, spec *RecoveryStateSpec) error {
+	for i, g := range spec.Guardians {
+		account, err := ParseBech32Account(g.Account)
+		if err != nil {
+			return fmt.Errorf("guardians[%d].account: %w", i, err)
+		}
+		guardians, err := parseMembers(g.Guardians)
+		if err != nil {
+			return fmt.Errorf("guardians[%d].guardians: %w", i, err)
+		}
+		if err := manager.RecoveryPutConfig(account, &state.RecoveryConfig{
+			Guardians:    guardians,
+			Threshold:    g.Threshold,
+			DelaySeconds: g.DelaySeconds,
+			UpdatedAt:    g.UpdatedAt,
+		}); err != nil {
+			return fmt.Errorf("guardians[%d]: %w", i, err)
+		}
+	}
+	for i, p := range spec.Pending {
+		req := &state.RecoveryRequest{CreatedAt: p.CreatedAt, ExecutableAt: p.ExecutableAt}
+		var err error
+		if req.Account, err = ParseBech32Account(p.Account); err != nil {
+			return fmt.Errorf("pending[%d].account: %w", i, err)
+		}
+		if req.NewAddress, err = parseOptionalAddress(p.NewAddress); err != nil {
+			return fmt.Errorf("pending[%d].newAddress: %w", i, err)
+		}
+		for j, v := range p.Votes {
+			guardian, err := ParseBech32Account(v.Guardian)
+			if err != nil {
+				return fmt.Errorf("pending[%d].votes[%d].guardian: %w", i, j, err)
+			}
+			newAddress, err := ParseBech32Account(v.NewAddress)
+			if err != nil {
+				return fmt.Errorf("pending[%d].votes[%d].newAddress: %w", i, j, err)
+			}
+			req.SetVote(guardian, newAddress)
+		}
+		if err := manager.RecoveryPutPending(req); err != nil {
+			return fmt.Errorf("pending[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func applyCreatorState(manager *state.Manager, spec *CreatorStateSpec) error {
+	for i, t := range spec.Tiers {
+		owner, err := ParseBech32Account(t.Creator)
+		if err != nil {
+			return fmt.Errorf("tiers[%d].creator: %w", i, err)
+		}
+		price, err := parseOptionalAmount(t.Price)
+		if err != nil {
+			return fmt.Errorf("tiers[%d].price: %w", i, err)
+		}
+		if err := manager.CreatorTierPut(&creator.SubscriptionTier{
+			Creator:       owner,
+			ID:            t.ID,
+			Name:          t.Name,
+			Asset:         t.Asset,
+			Price:         price,
+			PeriodSeconds: t.PeriodSeconds,
+			Active:        t.Active,
+			UpdatedAt:     t.UpdatedAt,
+		}); err != nil {
+			return fmt.Errorf("tiers[%d]: %w", i, err)
+		}
+	}
+	for i, sub := range spec.Subscriptions {
+		owner, err := ParseBech32Account(sub.Creator)
+		if err != nil {
+			return fmt.Errorf("subscriptions[%d].creator: %w", i, err)
+		}
+		fan, err := ParseBech32Account(sub.Fan)
+		if err != nil {
+			return fmt.Errorf("subscriptions[%d].fan: %w", i, err)
+		}
+		price, err := parseOptionalAmount(sub.Price)
+		if err != nil {
+			return fmt.Errorf("subscriptions[%d].price: %w", i, err)
+		}
+		if err := manager.CreatorSubscriptionPut(&creator.Subscription{
+			Creator:       owner,
+			Fan:           fan,
+			TierID:        sub.TierID,
+			Asset:         sub.Asset,
+			Price:         price,
+			PeriodSeconds: sub.PeriodSeconds,
+			StartedAt:     sub.StartedAt,
+			PaidThrough:   sub.PaidThrough,
+			Cancelled:     sub.Cancelled,
+			CancelledAt:   sub.CancelledAt,
+		}); err != nil {
+			return fmt.Errorf("subscriptions[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// applyEngagementDevices registers devices without the per-owner limit; the
+// exported set was admitted under the limit in force when it was registered.
+func applyEngagementDevices(manager *state.Manager, devices []EngagementDeviceSpec) error {
+	for i, d := range devices {
+		owner, err := ParseBech32Account(d.Owner)
+		if err != nil {
+			return fmt.Errorf("engagementDevices[%d].owner: %w", i, err)
+		}
+		tokenHash, err := parseHash32(d.TokenHash)
+		if err != nil {
+			return fmt.Errorf("engagementDevices[%d].tokenHash: %w", i, err)
+		}
+		if err := manager.EngagementDevicePut(&state.EngagementDevice{
+			DeviceID:      d.DeviceID,
+			Owner:         owner,
+			TokenHash:     tokenHash,
+			RegisteredAt:  d.RegisteredAt,
+			LastHeartbeat: d.LastHeartbeat,
+		}, 0); err != nil {
+			return fmt.Errorf("engagementDevices[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func applyReputation(manager *state.Manager, aggregates []ReputationSpec) error {
+	book := reputation.NewScoreBook(manager)
+	for i, r := range aggregates {
+		agg := &reputation.Aggregate{
+			FirstSeen:         r.FirstSeen,
+			UpdatedAt:         r.UpdatedAt,
+			EscrowsCompleted:  r.EscrowsCompleted,
+			EscrowsDisputed:   r.EscrowsDisputed,
+			TradesCompleted:   r.TradesCompleted,
+			TradesDisputed:    r.TradesDisputed,
+			ArbitrationWins:   r.ArbitrationWins,
+			ArbitrationLosses: r.ArbitrationLosses,
+		}
+		var err error
+		if agg.Address, err = ParseBech32Account(r.Address); err != nil {
+			return fmt.Errorf("reputation[%d].address: %w", i, err)
+		}
+		for j, att := range r.Attestations {
+			weight := reputation.AttestationWeight{Weight: att.Weight, ExpiresAt: att.ExpiresAt}
+			if weight.ID, err = parseHash32(att.ID); err != nil {
+				return fmt.Errorf("reputation[%d].attestations[%d].id: %w", i, j, err)
+			}
+			if weight.Verifier, err = ParseBech32Account(att.Verifier); err != nil {
+				return fmt.Errorf("reputation[%d].attestations[%d].verifier: %w", i, j, err)
+			}
+			agg.Attestations = append(agg.Attestations, weight)
+		}
+		if err := book.Restore(agg); err != nil {
+			return fmt.Errorf("reputation[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -43,6 +43,25 @@ type GenesisSpec struct {
 	BuybackSigners         []string `json:"buybackSigners,omitempty"`
 	BuybackSignerThreshold uint32   `json:"buybackSignerThreshold,omitempty"`
 
+	// Module state sections written by ExportSpec (see module_state.go).
+	// Accounts is keyed by bech32 address.
+	Accounts   map[string]*AccountStateSpec `json:"accounts,omitempty"`
+	Staking    *StakingStateSpec            `json:"staking,omitempty"`
+	Escrow     *EscrowStateSpec             `json:"escrow,omitempty"`
+	Loyalty    *LoyaltyStateSpec            `json:"loyalty,omitempty"`
+	Lending    *LendingStateSpec            `json:"lending,omitempty"`
+	Governance *GovernanceStateSpec         `json:"governance,omitempty"`
+
+	IssuedAssets      []IssuedAssetSpec      `json:"issuedAssets,omitempty"`
+	Identity          *IdentityStateSpec     `json:"identity,omitempty"`
+	Invoices          []InvoiceSpec          `json:"invoices,omitempty"`
+	Mandates          []MandateSpec          `json:"mandates,omitempty"`
+	SessionKeys       []SessionKeySpec       `json:"sessionKeys,omitempty"`
+	Recovery          *RecoveryStateSpec     `json:"recovery,omitempty"`
+	Creator           *CreatorStateSpec      `json:"creator,omitempty"`
+	EngagementDevices []EngagementDeviceSpec `json:"engagementDevices,omitempty"`
+	Reputation        []ReputationSpec       `json:"reputation,omitempty"`
+
 	genesisTimestamp       time.Time
 	chainIDValue            uint64
 	hasChainID              bool
@@ -59,6 +78,8 @@ type NativeTokenSpec struct {
 	Decimals          uint8  `json:"decimals"`
 	MintAuthority     string `json:"mintAuthority,omitempty"`
 	InitialMintPaused *bool  `json:"initialMintPaused,omitempty"`
+	// Supply seeds the tracked total supply. Optional.
+	Supply string `json:"supply,omitempty"`
 }
 
 type ValidatorSpec struct {
@@ -98,6 +119,8 @@ type LoyaltyDynamicSpec struct {
 	DailyCapUSD                 float64               `json:"dailyCapUsd"`
 	YearlyCapPctOfInitialSupply float64               `json:"yearlyCapPctOfInitialSupply"`
 	PriceGuard                  LoyaltyPriceGuardSpec `json:"priceGuard"`
+	EnableProRate               *bool                 `json:"enableProRate,omitempty"`
+	EnforceProRate              *bool                 `json:"enforceProRate,omitempty"`
 
 	coverageMaxBps       uint32
 	dailyCapPctBps       uint32
@@ -404,6 +427,9 @@ func (t *NativeTokenSpec) validate() error {
 		}
 	}
 	// InitialMintPaused is optional; no extra check needed.
+	if _, err := parseOptionalAmount(t.Supply); err != nil {
+		return fmt.Errorf("supply: %w", err)
+	}
 	return nil
 }
 
@@ -515,6 +541,14 @@ func (l *LoyaltyGlobalSpec) Config() (*loyalty.GlobalConfig, *big.Int, error) {
 			},
 		},
 	}
+	if l.Dynamic.EnableProRate != nil {
+		cfg.Dynamic.EnableProRate = *l.Dynamic.EnableProRate
+		cfg.Dynamic.EnableProRateSet = true
+	}
+	if l.Dynamic.EnforceProRate != nil {
+		cfg.Dynamic.EnforceProRate = *l.Dynamic.EnforceProRate
+		cfg.Dynamic.EnforceProRateSet = true
+	}
 	cfg = cfg.Normalize()
 	return cfg, new(big.Int).Set(l.seedZNHB), nil
 }
@@ -18,6 +18,14 @@ type AliasRecord struct {
 	AvatarRef string
 	CreatedAt int64
 	UpdatedAt int64
+	// ExpiresAt is the unix time at which the registration term ends. Zero
+	// means the alias never expires.
+	ExpiresAt int64
+	// Records holds the owner-managed text records sorted by key.
+	Records []TextRecord
+	// Parent names the parent alias when the record was resolved from a
+	// sub-alias. Sub-alias records are derived on lookup and never stored.
+	Parent string
 }
 
 func (r *AliasRecord) Clone() *AliasRecord {
@@ -29,6 +37,9 @@ func (r *AliasRecord) Clone() *AliasRecord {
 		clone.Addresses = make([][20]byte, len(r.Addresses))
 		copy(clone.Addresses, r.Addresses)
 	}
+	if len(r.Records) > 0 {
+		clone.Records = append([]TextRecord(nil), r.Records...)
+	}
 	return &clone
 }
 
@@ -68,6 +79,20 @@ var (
 	ErrPrimaryAddressRequired = errors.New("identity: cannot remove primary address")
 	// ErrNotAliasOwner indicates the caller does not control the alias.
 	ErrNotAliasOwner = errors.New("identity: caller is not alias owner")
+	// ErrAliasExpired is returned when an operation requires an alias that is
+	// still within its registration term.
+	ErrAliasExpired = errors.New("identity: alias registration expired")
+	// ErrTermsDisabled is returned when renewing while registration terms are
+	// not configured or for an alias without an expiry.
+	ErrTermsDisabled = errors.New("identity: alias does not have a registration term")
+	// ErrListingNotFound is returned when an alias is not listed for sale.
+	ErrListingNotFound = errors.New("identity: alias not listed for sale")
+	// ErrListingMismatch is returned when a purchase does not match the
+	// listing's price or reserved buyer.
+	ErrListingMismatch = errors.New("identity: purchase does not match listing")
+	// ErrInvalidPrice is returned when a listing price is negative, or zero
+	// without a reserved buyer.
+	ErrInvalidPrice = errors.New("identity: invalid listing price")
 )
 
 // NormalizeAlias lowercases and validates the supplied alias.
@@ -0,0 +1,243 @@
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Well-known text record keys. Wallets and POS terminals read these to decide
+// how to pay an alias and what to show for it.
+const (
+	// RecordPayAsset names the asset the owner prefers to receive: NHB or ZNHB.
+	RecordPayAsset = "pay.asset"
+	// RecordPayMemo is a default memo to attach to payments.
+	RecordPayMemo = "pay.memo"
+	// RecordPayWebhook is an HTTPS endpoint notified about invoices and
+	// payments addressed to the alias.
+	RecordPayWebhook = "pay.webhook"
+	// RecordDisplayName is a human readable name shown next to the alias.
+	RecordDisplayName = "display.name"
+	// RecordKYCAttestation points at a KYC attestation issued for the owner.
+	RecordKYCAttestation = "kyc.attestation"
+	// RecordContactEmail is the salted hash of the owner's email address.
+	RecordContactEmail = "contact.email"
+	// RecordContactPhone is the salted hash of the owner's phone number.
+	RecordContactPhone = "contact.phone"
+)
+
+const (
+	// MaxTextRecords bounds the number of records stored on an alias.
+	MaxTextRecords = 16
+	// MaxTextRecordBytes bounds the combined size of all record values.
+	MaxTextRecordBytes = 2048
+
+	customRecordPrefix   = "x."
+	customRecordMaxValue = 256
+	displayNameMaxRunes  = 64
+	payMemoMaxBytes      = 128
+	recordURLMaxBytes    = 256
+	recordKeyMaxBytes    = 40
+	recordHashHexLength  = 64
+	recordHashPrefix     = "0x"
+)
+
+var (
+	customRecordKeyPattern = regexp.MustCompile(`^x\.[a-z0-9][a-z0-9._-]*$`)
+	hexPattern             = regexp.MustCompile(`^[0-9a-f]+$`)
+
+	// ErrInvalidRecord is returned when a text record key is unknown or its
+	// value fails validation.
+	ErrInvalidRecord = errors.New("identity: invalid text record")
+	// ErrRecordLimit is returned when an update would exceed the record count
+	// or size limits.
+	ErrRecordLimit = errors.New("identity: text record limit exceeded")
+)
+
+// TextRecord is a single key/value entry attached to an alias.
+type TextRecord struct {
+	Key   string
+	Value string
+}
+
+// NormalizeTextRecord validates a record and returns its canonical form. Keys
+// are lowercased. Besides the well-known keys, owners may store free-form
+// values under keys in the "x." namespace.
+func NormalizeTextRecord(key, value string) (TextRecord, error) {
+	normalizedKey, err := NormalizeTextRecordKey(key)
+	if err != nil {
+		return TextRecord{}, err
+	}
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return TextRecord{}, fmt.Errorf("%w: %s value must not be empty", ErrInvalidRecord, normalizedKey)
+	}
+	switch normalizedKey {
+	case RecordPayAsset:
+		asset := strings.ToUpper(trimmed)
+		if asset != "NHB" && asset != "ZNHB" {
+			return TextRecord{}, fmt.Errorf("%w: %s must be NHB or ZNHB", ErrInvalidRecord, normalizedKey)
+		}
+		trimmed = asset
+	case RecordPayMemo:
+		if err := validatePrintable(normalizedKey, trimmed, payMemoMaxBytes); err != nil {
+			return TextRecord{}, err
+		}
+	case RecordPayWebhook:
+		if err := validateRecordURL(normalizedKey, trimmed, "https"); err != nil {
+			return TextRecord{}, err
+		}
+	case RecordDisplayName:
+		if utf8.RuneCountInString(trimmed) > displayNameMaxRunes {
+			return TextRecord{}, fmt.Errorf("%w: %s exceeds %d characters", ErrInvalidRecord, normalizedKey, displayNameMaxRunes)
+		}
+		if err := validatePrintable(normalizedKey, trimmed, 4*displayNameMaxRunes); err != nil {
+			return TextRecord{}, err
+		}
+	case RecordKYCAttestation:
+		// Attestations are referenced either by a 32-byte hash or by a URI
+		// the wallet can fetch them from.
+		if strings.HasPrefix(trimmed, recordHashPrefix) {
+			normalizedHash, err := normalizeRecordHash(normalizedKey, trimmed)
+			if err != nil {
+				return TextRecord{}, err
+			}
+			trimmed = normalizedHash
+			break
+		}
+		if err := validateRecordURL(normalizedKey, trimmed, "https", "ipfs", "blob"); err != nil {
+			return TextRecord{}, err
+		}
+	case RecordContactEmail, RecordContactPhone:
+		normalizedHash, err := normalizeRecordHash(normalizedKey, trimmed)
+		if err != nil {
+			return TextRecord{}, err
+		}
+		trimmed = normalizedHash
+	default:
+		if err := validatePrintable(normalizedKey, trimmed, customRecordMaxValue); err != nil {
+			return TextRecord{}, err
+		}
+	}
+	return TextRecord{Key: normalizedKey, Value: trimmed}, nil
+}
+
+// NormalizeTextRecordKey lowercases key and checks that it is a well-known key
+// or a custom key in the "x." namespace.
+func NormalizeTextRecordKey(key string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(key))
+	switch normalized {
+	case RecordPayAsset, RecordPayMemo, RecordPayWebhook, RecordDisplayName,
+		RecordKYCAttestation, RecordContactEmail, RecordContactPhone:
+		return normalized, nil
+	}
+	if len(normalized) > recordKeyMaxBytes || !customRecordKeyPattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: unknown key %q (custom keys use the %q prefix)", ErrInvalidRecord, key, customRecordPrefix)
+	}
+	return normalized, nil
+}
+
+// ApplyTextRecords returns the records that result from deleting the keys in
+// deletes and then upserting set. The result is sorted by key and checked
+// against MaxTextRecords and MaxTextRecordBytes.
+func ApplyTextRecords(current []TextRecord, set []TextRecord, deletes []string) ([]TextRecord, error) {
+	merged := make(map[string]string, len(current)+len(set))
+	for _, record := range current {
+		merged[record.Key] = record.Value
+	}
+	for _, key := range deletes {
+		normalized, err := NormalizeTextRecordKey(key)
+		if err != nil {
+			return nil, err
+		}
+		delete(merged, normalized)
+	}
+	seen := make(map[string]struct{}, len(set))
+	for _, record := range set {
+		normalized, err := NormalizeTextRecord(record.Key, record.Value)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[normalized.Key]; dup {
+			return nil, fmt.Errorf("%w: duplicate key %s", ErrInvalidRecord, normalized.Key)
+		}
+		seen[normalized.Key] = struct{}{}
+		merged[normalized.Key] = normalized.Value
+	}
+	if len(merged) > MaxTextRecords {
+		return nil, fmt.Errorf("%w: at most %d records", ErrRecordLimit, MaxTextRecords)
+	}
+	out := make([]TextRecord, 0, len(merged))
+	total := 0
+	for key, value := range merged {
+		total += len(value)
+		out = append(out, TextRecord{Key: key, Value: value})
+	}
+	if total > MaxTextRecordBytes {
+		return nil, fmt.Errorf("%w: values exceed %d bytes", ErrRecordLimit, MaxTextRecordBytes)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+// TextRecordValue returns the value stored under key.
+func (r *AliasRecord) TextRecordValue(key string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	normalized := strings.ToLower(strings.TrimSpace(key))
+	for _, record := range r.Records {
+		if record.Key == normalized {
+			return record.Value, true
+		}
+	}
+	return "", false
+}
+
+func validatePrintable(key, value string, maxBytes int) error {
+	if len(value) > maxBytes {
+		return fmt.Errorf("%w: %s exceeds %d bytes", ErrInvalidRecord, key, maxBytes)
+	}
+	if !utf8.ValidString(value) {
+		return fmt.Errorf("%w: %s must be valid UTF-8", ErrInvalidRecord, key)
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%w: %s must not contain control characters", ErrInvalidRecord, key)
+		}
+	}
+	return nil
+}
+
+func validateRecordURL(key, value string, schemes ...string) error {
+	if len(value) > recordURLMaxBytes {
+		return fmt.Errorf("%w: %s exceeds %d bytes", ErrInvalidRecord, key, recordURLMaxBytes)
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("%w: %s must be an absolute URL", ErrInvalidRecord, key)
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(parsed.Scheme, scheme) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s must use one of %s", ErrInvalidRecord, key, strings.Join(schemes, ", "))
+}
+
+func normalizeRecordHash(key, value string) (string, error) {
+	lower := strings.ToLower(value)
+	if !strings.HasPrefix(lower, recordHashPrefix) {
+		return "", fmt.Errorf("%w: %s must be a 0x-prefixed hash", ErrInvalidRecord, key)
+	}
+	digits := strings.TrimPrefix(lower, recordHashPrefix)
+	if len(digits) != recordHashHexLength || !hexPattern.MatchString(digits) {
+		return "", fmt.Errorf("%w: %s must be a 32-byte hex hash", ErrInvalidRecord, key)
+	}
+	return lower, nil
+}
@@ -0,0 +1,87 @@
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxSubAliasesPerParent bounds how many sub-aliases one parent alias may
+	// delegate.
+	MaxSubAliasesPerParent = 256
+
+	subAliasLabelMaxLength = 16
+)
+
+var (
+	subAliasLabelPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+	// ErrSubAliasNotFound is returned when a sub-alias is not registered.
+	ErrSubAliasNotFound = errors.New("identity: sub-alias not found")
+	// ErrSubAliasNamespace is returned when a flat alias would occupy a name
+	// in a registered parent's sub-alias namespace.
+	ErrSubAliasNamespace = errors.New("identity: name is reserved for sub-aliases of its parent")
+	// ErrSubAliasLimit is returned when a parent already delegates
+	// MaxSubAliasesPerParent sub-aliases.
+	ErrSubAliasLimit = errors.New("identity: sub-alias limit reached")
+)
+
+// SubAlias is a handle such as "cashier1.acme" delegated by the owner of the
+// parent alias "acme". Sub-aliases have no owner of their own: whoever owns
+// the parent controls them, and they resolve only while the parent does.
+type SubAlias struct {
+	Label     string
+	Parent    string
+	Target    [20]byte
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// Name returns the full sub-alias name, label.parent.
+func (s *SubAlias) Name() string {
+	if s == nil {
+		return ""
+	}
+	return s.Label + "." + s.Parent
+}
+
+// Clone returns a copy of the sub-alias.
+func (s *SubAlias) Clone() *SubAlias {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	return &clone
+}
+
+// NormalizeSubAliasLabel lowercases and validates a sub-alias label. Labels
+// may not contain dots, so sub-aliases are a single level below their parent.
+func NormalizeSubAliasLabel(label string) (string, error) {
+	lower := strings.ToLower(strings.TrimSpace(label))
+	if lower == "" || len(lower) > subAliasLabelMaxLength {
+		return "", fmt.Errorf("%w: sub-alias label must be between 1 and %d characters", ErrInvalidAlias, subAliasLabelMaxLength)
+	}
+	if !subAliasLabelPattern.MatchString(lower) {
+		return "", fmt.Errorf("%w: sub-alias label allows [a-z0-9_-]", ErrInvalidAlias)
+	}
+	return lower, nil
+}
+
+// SplitSubAlias splits a normalized alias at its first dot into a label and
+// a parent. ok is false when the name cannot be a sub-alias.
+func SplitSubAlias(name string) (label string, parent string, ok bool) {
+	idx := strings.IndexByte(name, '.')
+	if idx <= 0 || idx == len(name)-1 {
+		return "", "", false
+	}
+	label, parent = name[:idx], name[idx+1:]
+	if _, err := NormalizeSubAliasLabel(label); err != nil {
+		return "", "", false
+	}
+	if _, err := NormalizeAlias(parent); err != nil {
+		return "", "", false
+	}
+	return label, parent, true
+}
@@ -0,0 +1,89 @@
+package identity
+
+import (
+	"math/big"
+	"time"
+)
+
+// Terms configures optional fixed-length alias registrations. With a zero Term
+// aliases never expire, matching the behaviour before terms existed.
+type Terms struct {
+	// Term is the length of a registration and of each renewal.
+	Term time.Duration
+	// GracePeriod is how long an expired alias stays reserved for its owner
+	// to renew before anyone else may register it.
+	GracePeriod time.Duration
+	// RenewalFee is charged in NHB wei for every renewal.
+	RenewalFee *big.Int
+}
+
+// Enabled reports whether new registrations receive an expiry.
+func (t Terms) Enabled() bool { return t.Term > 0 }
+
+// ExpiryFrom returns the expiry of a term starting at start.
+func (t Terms) ExpiryFrom(start int64) int64 {
+	return start + int64(t.Term/time.Second)
+}
+
+// Fee returns a copy of the renewal fee, or zero when none is configured.
+func (t Terms) Fee() *big.Int {
+	if t.RenewalFee == nil || t.RenewalFee.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(t.RenewalFee)
+}
+
+// AliasStatus describes where an alias is in its registration lifecycle.
+type AliasStatus string
+
+const (
+	// AliasStatusActive marks an alias within its term or without an expiry.
+	AliasStatusActive AliasStatus = "active"
+	// AliasStatusGrace marks an expired alias that only its owner may renew.
+	AliasStatusGrace AliasStatus = "grace"
+	// AliasStatusReleased marks an alias past its grace period. It no longer
+	// resolves and may be registered by any address.
+	AliasStatusReleased AliasStatus = "released"
+)
+
+// Status returns the lifecycle status of record at now. The grace period is
+// taken from the current terms so operators can extend it for aliases that
+// have already expired.
+func (t Terms) Status(record *AliasRecord, now int64) AliasStatus {
+	if record == nil || record.ExpiresAt == 0 || now < record.ExpiresAt {
+		return AliasStatusActive
+	}
+	if now < record.ExpiresAt+int64(t.GracePeriod/time.Second) {
+		return AliasStatusGrace
+	}
+	return AliasStatusReleased
+}
+
+// Listing offers an alias for sale. A zero Buyer leaves the listing open to
+// any address; a set Buyer reserves it for a single counterparty, which also
+// allows a zero Price for plain two-party transfers.
+type Listing struct {
+	Alias    string
+	Seller   [20]byte
+	Buyer    [20]byte
+	Price    *big.Int
+	Nonce    uint64
+	ListedAt int64
+}
+
+// Clone returns a deep copy of the listing.
+func (l *Listing) Clone() *Listing {
+	if l == nil {
+		return nil
+	}
+	clone := *l
+	if l.Price != nil {
+		clone.Price = new(big.Int).Set(l.Price)
+	}
+	return &clone
+}
+
+// Reserved reports whether the listing is limited to a single buyer.
+func (l *Listing) Reserved() bool {
+	return l != nil && l.Buyer != ([20]byte{})
+}
@@ -0,0 +1,371 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"nhbchain/core/events"
+	"nhbchain/core/identity"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+	"nhbchain/native/fees"
+	"nhbchain/storage"
+)
+
+func newIdentityMarketNode(t *testing.T) *Node {
+	t.Helper()
+	db := storage.NewMemDB()
+	t.Cleanup(func() { db.Close() })
+	validatorKey, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate validator key: %v", err)
+	}
+	node, err := NewNode(db, validatorKey, "", true, false)
+	if err != nil {
+		t.Fatalf("new node: %v", err)
+	}
+	return node
+}
+
+func fundIdentityAccount(t *testing.T, node *Node, addr [20]byte, amount int64) {
+	t.Helper()
+	manager := nhbstate.NewManager(node.state.Trie)
+	account := &types.Account{BalanceNHB: big.NewInt(amount), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0)}
+	if err := manager.PutAccount(addr[:], account); err != nil {
+		t.Fatalf("fund account: %v", err)
+	}
+}
+
+func nhbBalance(t *testing.T, node *Node, addr [20]byte) *big.Int {
+	t.Helper()
+	account, err := nhbstate.NewManager(node.state.Trie).GetAccount(addr[:])
+	if err != nil {
+		t.Fatalf("get account: %v", err)
+	}
+	return account.BalanceNHB
+}
+
+func TestNodeIdentityBuySettlesThroughEscrow(t *testing.T) {
+	node := newIdentityMarketNode(t)
+	node.SetTimeSource(func() time.Time { return time.Unix(1_700_000_000, 0) })
+	var seller, buyer, stranger [20]byte
+	seller[19] = 1
+	buyer[19] = 2
+	stranger[19] = 3
+	fundIdentityAccount(t, node, buyer, 1_000)
+
+	if err := node.IdentitySetAlias(seller, "premium"); err != nil {
+		t.Fatalf("set alias: %v", err)
+	}
+	sellerAcc := &types.Account{BalanceNHB: big.NewInt(0), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0), Username: "premium"}
+	if err := node.state.setAccount(seller[:], sellerAcc); err != nil {
+		t.Fatalf("seed seller username: %v", err)
+	}
+	if _, err := node.IdentityListForSale(stranger, "premium", big.NewInt(400), [20]byte{}); !errors.Is(err, identity.ErrNotAliasOwner) {
+		t.Fatalf("expected non-owner listing to fail, got %v", err)
+	}
+	if _, err := node.IdentityListForSale(seller, "premium", big.NewInt(0), [20]byte{}); !errors.Is(err, identity.ErrInvalidPrice) {
+		t.Fatalf("expected open zero-price listing to fail, got %v", err)
+	}
+	if _, err := node.IdentityListForSale(seller, "premium", big.NewInt(400), [20]byte{}); err != nil {
+		t.Fatalf("list alias: %v", err)
+	}
+	if _, _, err := node.IdentityBuy(buyer, "premium", big.NewInt(300)); !errors.Is(err, identity.ErrListingMismatch) {
+		t.Fatalf("expected price mismatch, got %v", err)
+	}
+
+	record, escrowID, err := node.IdentityBuy(buyer, "premium", big.NewInt(400))
+	if err != nil {
+		t.Fatalf("buy alias: %v", err)
+	}
+	if record.Owner != buyer || record.Primary != buyer {
+		t.Fatalf("alias not transferred to buyer: %+v", record)
+	}
+	if escrowID == ([32]byte{}) {
+		t.Fatalf("expected settlement escrow id")
+	}
+	if got := nhbBalance(t, node, buyer); got.Cmp(big.NewInt(600)) != 0 {
+		t.Fatalf("buyer balance = %s, want 600", got)
+	}
+	if got := nhbBalance(t, node, seller); got.Cmp(big.NewInt(400)) != 0 {
+		t.Fatalf("seller balance = %s, want 400", got)
+	}
+	if _, ok, _ := node.IdentityGetListing("premium"); ok {
+		t.Fatalf("expected listing to be removed after sale")
+	}
+	if alias, ok := node.IdentityReverse(buyer); !ok || alias != "premium" {
+		t.Fatalf("expected buyer reverse alias, got %q", alias)
+	}
+	if addr, ok := node.state.ResolveUsername("premium"); !ok || !bytes.Equal(addr, buyer[:]) {
+		t.Fatalf("expected username to resolve to buyer, got %x", addr)
+	}
+	if acc, _ := node.state.getAccount(seller[:]); acc.Username != "" {
+		t.Fatalf("expected seller username to be cleared, got %q", acc.Username)
+	}
+	if acc, _ := node.state.getAccount(buyer[:]); acc.Username != "premium" {
+		t.Fatalf("expected buyer username, got %q", acc.Username)
+	}
+
+	var sold bool
+	for _, evt := range node.state.Events() {
+		if evt.Type == events.TypeIdentityAliasSold {
+			sold = true
+			if evt.Attributes["price"] != "400" {
+				t.Fatalf("unexpected sold price attribute: %s", evt.Attributes["price"])
+			}
+		}
+	}
+	if !sold {
+		t.Fatalf("expected sold event")
+	}
+}
+
+func TestNodeWithStateRollbackDiscardsPartialWrites(t *testing.T) {
+	node := newIdentityMarketNode(t)
+	var addr [20]byte
+	addr[19] = 7
+	events := len(node.state.Events())
+	err := node.withStateRollback(func() error {
+		account := &types.Account{BalanceNHB: big.NewInt(5), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0), Username: "partial"}
+		if err := node.state.setAccount(addr[:], account); err != nil {
+			return err
+		}
+		node.state.AppendEvent(&types.Event{Type: "test.partial"})
+		return errors.New("boom")
+	})
+	if err == nil {
+		t.Fatalf("expected rollback error")
+	}
+	if got := nhbBalance(t, node, addr); got.Sign() != 0 {
+		t.Fatalf("expected balance write to be discarded, got %s", got)
+	}
+	if _, ok := node.state.ResolveUsername("partial"); ok {
+		t.Fatalf("expected username index to be restored")
+	}
+	if len(node.state.Events()) != events {
+		t.Fatalf("expected events to be discarded")
+	}
+}
+
+func TestRegisterIdentityReclaimClearsPreviousHolder(t *testing.T) {
+	node := newIdentityMarketNode(t)
+	sp := node.state
+	sp.SetIdentityTerms(identity.Terms{Term: 24 * time.Hour, GracePeriod: time.Hour})
+	var first, second [20]byte
+	first[19] = 1
+	second[19] = 2
+	register := func(addr [20]byte, name string, at time.Time) error {
+		sp.BeginBlock(sp.blockHeight()+1, at)
+		defer sp.EndBlock()
+		account, err := sp.getAccount(addr[:])
+		if err != nil {
+			return err
+		}
+		return sp.applyRegisterIdentity(&types.Transaction{Data: []byte(name)}, addr[:], account)
+	}
+	start := time.Unix(1_700_000_000, 0)
+	if err := register(first, "lapsed", start); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := register(second, "lapsed", start.Add(time.Hour)); err == nil {
+		t.Fatalf("expected active username to be taken")
+	}
+	if err := register(second, "lapsed", start.Add(30*time.Hour)); err != nil {
+		t.Fatalf("reclaim released username: %v", err)
+	}
+
+	previous, err := sp.getAccount(first[:])
+	if err != nil {
+		t.Fatalf("get account: %v", err)
+	}
+	if previous.Username != "" {
+		t.Fatalf("expected previous holder username to be cleared, got %q", previous.Username)
+	}
+	// A later write to the previous holder must not hijack the index.
+	previous.BalanceNHB = big.NewInt(1)
+	if err := sp.setAccount(first[:], previous); err != nil {
+		t.Fatalf("set account: %v", err)
+	}
+	if addr, ok := sp.ResolveUsername("lapsed"); !ok || !bytes.Equal(addr, second[:]) {
+		t.Fatalf("expected username to resolve to new holder, got %x", addr)
+	}
+	if err := register(first, "fresh", start.Add(31*time.Hour)); err != nil {
+		t.Fatalf("previous holder register new username: %v", err)
+	}
+}
+
+func TestNodeIdentityReservedTransfer(t *testing.T) {
+	node := newIdentityMarketNode(t)
+	var seller, buyer, stranger [20]byte
+	seller[19] = 1
+	buyer[19] = 2
+	stranger[19] = 3
+
+	if err := node.IdentitySetAlias(seller, "gift"); err != nil {
+		t.Fatalf("set alias: %v", err)
+	}
+	if _, err := node.IdentityListForSale(seller, "gift", big.NewInt(0), buyer); err != nil {
+		t.Fatalf("list alias: %v", err)
+	}
+	if _, _, err := node.IdentityBuy(stranger, "gift", big.NewInt(0)); !errors.Is(err, identity.ErrListingMismatch) {
+		t.Fatalf("expected reserved listing to reject stranger, got %v", err)
+	}
+	record, escrowID, err := node.IdentityBuy(buyer, "gift", big.NewInt(0))
+	if err != nil {
+		t.Fatalf("claim reserved alias: %v", err)
+	}
+	if record.Owner != buyer || escrowID != ([32]byte{}) {
+		t.Fatalf("unexpected reserved transfer result: owner=%x escrow=%x", record.Owner, escrowID)
+	}
+}
+
+func TestNodeIdentityRenewChargesFee(t *testing.T) {
+	node := newIdentityMarketNode(t)
+	now := time.Unix(1_700_000_000, 0)
+	node.SetTimeSource(func() time.Time { return now })
+	var owner, route [20]byte
+	owner[19] = 1
+	route[19] = 9
+	fundIdentityAccount(t, node, owner, 100)
+
+	node.state.SetIdentityTerms(identity.Terms{Term: 24 * time.Hour, GracePeriod: time.Hour, RenewalFee: big.NewInt(25)})
+	if _, _, err := node.IdentityRenew(owner, "renewme"); !errors.Is(err, identity.ErrAliasNotFound) {
+		t.Fatalf("expected unknown alias, got %v", err)
+	}
+	if err := node.IdentitySetAlias(owner, "renewme"); err != nil {
+		t.Fatalf("set alias: %v", err)
+	}
+	if _, _, err := node.IdentityRenew(owner, "renewme"); err == nil {
+		t.Fatalf("expected renewal without a fee route wallet to fail")
+	}
+
+	node.feesMu.Lock()
+	node.feesPolicy = fees.Policy{Domains: map[string]fees.DomainPolicy{
+		fees.DomainIdentity: {Assets: map[string]fees.AssetPolicy{fees.AssetNHB: {OwnerWallet: route}}},
+	}}
+	node.feesMu.Unlock()
+
+	record, fee, err := node.IdentityRenew(owner, "renewme")
+	if err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if fee.Cmp(big.NewInt(25)) != 0 {
+		t.Fatalf("fee = %s, want 25", fee)
+	}
+	if want := now.Add(48 * time.Hour).Unix(); record.ExpiresAt != want {
+		t.Fatalf("expiry = %d, want %d", record.ExpiresAt, want)
+	}
+	if got := nhbBalance(t, node, owner); got.Cmp(big.NewInt(75)) != 0 {
+		t.Fatalf("owner balance = %s, want 75", got)
+	}
+	if got := nhbBalance(t, node, route); got.Cmp(big.NewInt(25)) != 0 {
+		t.Fatalf("route balance = %s, want 25", got)
+	}
+}
+
+func TestNodeIdentityRenewRollsBackFeeOnFailure(t *testing.T) {
+	node := newIdentityMarketNode(t)
+	now := time.Unix(1_700_000_000, 0)
+	node.SetTimeSource(func() time.Time { return now })
+	var owner, route [20]byte
+	owner[19] = 1
+	route[19] = 9
+	fundIdentityAccount(t, node, owner, 100)
+
+	node.state.SetIdentityTerms(identity.Terms{Term: 24 * time.Hour, GracePeriod: time.Hour, RenewalFee: big.NewInt(25)})
+	node.feesMu.Lock()
+	node.feesPolicy = fees.Policy{Domains: map[string]fees.DomainPolicy{
+		fees.DomainIdentity: {Assets: map[string]fees.AssetPolicy{fees.AssetNHB: {OwnerWallet: route}}},
+	}}
+	node.feesMu.Unlock()
+	if err := node.IdentitySetAlias(owner, "renewme"); err != nil {
+		t.Fatalf("set alias: %v", err)
+	}
+	before, ok := node.IdentityResolve("renewme")
+	if !ok {
+		t.Fatalf("alias not registered")
+	}
+
+	// Crediting the route wallet overflows its balance, so the renewal fails
+	// after the owner has already been debited.
+	maxBalance := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	manager := nhbstate.NewManager(node.state.Trie)
+	routeAcc := &types.Account{BalanceNHB: new(big.Int).Sub(maxBalance, big.NewInt(10)), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0)}
+	if err := manager.PutAccount(route[:], routeAcc); err != nil {
+		t.Fatalf("fund route: %v", err)
+	}
+	if _, _, err := node.IdentityRenew(owner, "renewme"); err == nil {
+		t.Fatalf("expected renewal to fail when the fee cannot be credited")
+	}
+	if got := nhbBalance(t, node, owner); got.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("owner balance = %s, want 100 after failed renewal", got)
+	}
+	after, ok := node.IdentityResolve("renewme")
+	if !ok || after.ExpiresAt != before.ExpiresAt {
+		t.Fatalf("expiry changed by failed renewal: before=%d after=%+v", before.ExpiresAt, after)
+	}
+}
+
+func TestNodeIdentitySubAliasDelegation(t *testing.T) {
+	node := newIdentityMarketNode(t)
+	var owner, cashier, replacement, buyer, stranger [20]byte
+	owner[19] = 1
+	cashier[19] = 2
+	replacement[19] = 3
+	buyer[19] = 4
+	stranger[19] = 5
+
+	if err := node.IdentitySetAlias(owner, "acme"); err != nil {
+		t.Fatalf("set alias: %v", err)
+	}
+	if _, err := node.IdentityCreateSubAlias(stranger, "acme", "cashier1", cashier); !errors.Is(err, identity.ErrNotAliasOwner) {
+		t.Fatalf("expected non-owner delegation to fail, got %v", err)
+	}
+	if _, err := node.IdentityCreateSubAlias(owner, "acme", "front.desk", cashier); !errors.Is(err, identity.ErrInvalidAlias) {
+		t.Fatalf("expected dotted label to fail, got %v", err)
+	}
+	sub, err := node.IdentityCreateSubAlias(owner, "acme", "cashier1", cashier)
+	if err != nil {
+		t.Fatalf("create sub-alias: %v", err)
+	}
+	if sub.Name() != "cashier1.acme" {
+		t.Fatalf("unexpected sub-alias name %q", sub.Name())
+	}
+	if record, ok := node.IdentityResolve("cashier1.acme"); !ok || record.Primary != cashier {
+		t.Fatalf("expected sub-alias to resolve to cashier")
+	}
+	if _, err := node.IdentityReassignSubAlias(owner, "cashier1.acme", replacement); err != nil {
+		t.Fatalf("reassign sub-alias: %v", err)
+	}
+	if record, ok := node.IdentityResolve("cashier1.acme"); !ok || record.Primary != replacement {
+		t.Fatalf("expected sub-alias to follow reassignment")
+	}
+
+	// Control of sub-aliases follows the parent alias to its new owner.
+	if _, err := node.IdentityListForSale(owner, "acme", big.NewInt(0), buyer); err != nil {
+		t.Fatalf("list parent: %v", err)
+	}
+	if _, _, err := node.IdentityBuy(buyer, "acme", big.NewInt(0)); err != nil {
+		t.Fatalf("transfer parent: %v", err)
+	}
+	if err := node.IdentityRevokeSubAlias(owner, "cashier1.acme"); !errors.Is(err, identity.ErrNotAliasOwner) {
+		t.Fatalf("expected previous owner to lose control, got %v", err)
+	}
+	if err := node.IdentityRevokeSubAlias(buyer, "cashier1.acme"); err != nil {
+		t.Fatalf("revoke sub-alias: %v", err)
+	}
+	if _, ok := node.IdentityResolve("cashier1.acme"); ok {
+		t.Fatalf("revoked sub-alias should not resolve")
+	}
+	subs, err := node.IdentitySubAliases("acme")
+	if err != nil {
+		t.Fatalf("list sub-aliases: %v", err)
+	}
+	if len(subs) != 0 {
+		t.Fatalf("expected no sub-aliases, got %d", len(subs))
+	}
+}
@@ -20,6 +20,9 @@ var lendingLegacyRay = mustLegacyBigInt("1000000000000000000000000000")
 type lendingNativePayload struct {
 	PoolID          string `json:"poolId,omitempty"`
 	UseDeveloperFee bool   `json:"useDeveloperFee,omitempty"`
+	// Asset selects the collateral asset for collateral deposits and
+	// withdrawals. Empty selects the pool's only collateral asset.
+	Asset string `json:"asset,omitempty"`
 }
 
 func cloneLendingRiskParameters(params lending.RiskParameters) lending.RiskParameters {
@@ -27,6 +30,8 @@ func cloneLendingRiskParameters(params lending.RiskParameters) lending.RiskParam
 		MaxLTV:               params.MaxLTV,
 		LiquidationThreshold: params.LiquidationThreshold,
 		LiquidationBonus:     params.LiquidationBonus,
+		CloseFactorBps:       params.CloseFactorBps,
+		Auction:              params.Auction,
 		CircuitBreakerActive: params.CircuitBreakerActive,
 		DeveloperFeeCapBps:   params.DeveloperFeeCapBps,
 		BorrowCaps:           params.BorrowCaps.Clone(),
@@ -177,6 +182,7 @@ func (sp *StateProcessor) lendingEngine(poolID string) (*lending.Engine, *lendin
 	engine.SetProtocolFeeBps(sp.lendingProtocolFeeBps)
 	engine.SetBlockHeight(sp.blockHeight())
 	engine.SetCollateralRouting(sp.lendingCollateralRouting.Clone())
+	engine.SetPriceSource(adapter.manager.LendingPriceSource(sp.blockTimestamp()))
 	if market != nil {
 		engine.SetDeveloperFee(market.DeveloperFeeBps, market.DeveloperFeeCollector)
 	} else {
@@ -233,7 +239,7 @@ func (sp *StateProcessor) applyLendingDepositZNHB(tx *types.Transaction, sender
 	if err != nil {
 		return err
 	}
-	if err := engine.DepositCollateral(crypto.MustNewAddress(crypto.NHBPrefix, append([]byte(nil), sender...)), tx.Value); err != nil {
+	if err := engine.DepositCollateral(crypto.MustNewAddress(crypto.NHBPrefix, append([]byte(nil), sender...)), payload.Asset, tx.Value); err != nil {
 		return err
 	}
 	return sp.incrementNativeAccountNonce(sender)
@@ -251,7 +257,7 @@ func (sp *StateProcessor) applyLendingWithdrawZNHB(tx *types.Transaction, sender
 	if err != nil {
 		return err
 	}
-	if err := engine.WithdrawCollateral(crypto.MustNewAddress(crypto.NHBPrefix, append([]byte(nil), sender...)), tx.Value); err != nil {
+	if err := engine.WithdrawCollateral(crypto.MustNewAddress(crypto.NHBPrefix, append([]byte(nil), sender...)), payload.Asset, tx.Value); err != nil {
 		return err
 	}
 	return sp.incrementNativeAccountNonce(sender)
@@ -305,6 +311,15 @@ func (sp *StateProcessor) applyLendingRepayNHB(tx *types.Transaction, sender []b
 type lendingLiquidatePayload struct {
 	PoolID   string `json:"poolId,omitempty"`
 	Borrower string `json:"borrower"`
+	// RepayAmount caps the NHB the liquidator repays. Empty repays as much
+	// as the pool's close factor allows.
+	RepayAmount string `json:"repayAmount,omitempty"`
+	// OpenAuction starts a Dutch liquidation auction against the borrower
+	// instead of liquidating.
+	OpenAuction bool `json:"openAuction,omitempty"`
+	// CollateralAsset selects the collateral seized. Empty selects the
+	// pool's only collateral asset.
+	CollateralAsset string `json:"collateralAsset,omitempty"`
 }
 
 func (sp *StateProcessor) decodeLendingLiquidatePayload(data []byte) (*lendingLiquidatePayload, error) {
@@ -333,6 +348,8 @@ func (sp *StateProcessor) decodeLendingLiquidatePayload(data []byte) (*lendingLi
 // a permissionless action against someone else's unhealthy position, so the
 // borrower's own signature is neither required nor meaningful here. The
 // liquidator's signature only authorizes spending the liquidator's own NHB.
+// The same transaction opens a Dutch liquidation auction when the payload
+// sets openAuction, which auction-mode pools require before liquidating.
 func (sp *StateProcessor) applyLendingLiquidate(tx *types.Transaction, sender []byte) error {
 	payload, err := sp.decodeLendingLiquidatePayload(tx.Data)
 	if err != nil {
@@ -345,12 +362,29 @@ func (sp *StateProcessor) applyLendingLiquidate(tx *types.Transaction, sender []
 	if bytes.Equal(borrowerAddr.Bytes(), sender) {
 		return fmt.Errorf("lending: a borrower cannot liquidate their own position; use repay instead")
 	}
+	var repayAmount *big.Int
+	if amount := strings.TrimSpace(payload.RepayAmount); amount != "" {
+		parsed, ok := new(big.Int).SetString(amount, 10)
+		if !ok || parsed.Sign() <= 0 {
+			return fmt.Errorf("invalid lending liquidate repayAmount %q", payload.RepayAmount)
+		}
+		repayAmount = parsed
+	}
 	engine, _, err := sp.lendingEngine(payload.PoolID)
 	if err != nil {
 		return err
 	}
+	if payload.OpenAuction {
+		if repayAmount != nil {
+			return fmt.Errorf("lending: repayAmount cannot be combined with openAuction")
+		}
+		if err := engine.OpenLiquidationAuction(borrowerAddr); err != nil {
+			return err
+		}
+		return sp.incrementNativeAccountNonce(sender)
+	}
 	liquidatorAddr := crypto.MustNewAddress(crypto.NHBPrefix, append([]byte(nil), sender...))
-	if _, _, err := engine.Liquidate(liquidatorAddr, borrowerAddr); err != nil {
+	if _, _, err := engine.Liquidate(liquidatorAddr, borrowerAddr, payload.CollateralAsset, repayAmount); err != nil {
 		return err
 	}
 	return sp.incrementNativeAccountNonce(sender)
@@ -491,11 +525,11 @@ func (a *lendingStateAdapter) legacyLendingPosition(addr crypto.Address, account
 	supplyIndex := normalizedLendingIndexLegacy(account.LendingSnapshot.SupplyIndex)
 	borrowIndex := normalizedLendingIndexLegacy(account.LendingSnapshot.BorrowIndex)
 	user := &lending.UserAccount{
-		Address:        addr,
-		CollateralZNHB: collateral,
-		SupplyShares:   supplyShares,
-		DebtNHB:        debt,
-		ScaledDebt:     scaledDebtFromAmountLegacy(debt, borrowIndex),
+		Address:      addr,
+		Collateral:   map[string]*big.Int{lending.AssetZNHB: collateral},
+		SupplyShares: supplyShares,
+		DebtNHB:      debt,
+		ScaledDebt:   scaledDebtFromAmountLegacy(debt, borrowIndex),
 	}
 	return user, liquidityFromSharesLegacy(supplyShares, supplyIndex), debt, true
 }
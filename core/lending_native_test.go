@@ -111,8 +111,8 @@ func TestStateProcessorPersistsLendingPoolState(t *testing.T) {
 	if !ok || userAccount == nil {
 		t.Fatalf("expected borrower lending account to persist")
 	}
-	if userAccount.CollateralZNHB == nil || userAccount.CollateralZNHB.Cmp(mustBigInt(t, "50000000000000000000000")) != 0 {
-		t.Fatalf("unexpected collateral amount: %v", userAccount.CollateralZNHB)
+	if userAccount.CollateralOf(lending.AssetZNHB).Cmp(mustBigInt(t, "50000000000000000000000")) != 0 {
+		t.Fatalf("unexpected collateral amount: %v", userAccount.CollateralOf(lending.AssetZNHB))
 	}
 	if userAccount.DebtNHB == nil || userAccount.DebtNHB.Sign() <= 0 {
 		t.Fatalf("expected borrower debt to persist, got %v", userAccount.DebtNHB)
@@ -169,8 +169,8 @@ func TestLendingStateAdapterMigratesLegacyAccountState(t *testing.T) {
 	if user == nil {
 		t.Fatalf("expected migrated lending account")
 	}
-	if user.CollateralZNHB.Cmp(account.CollateralBalance) != 0 {
-		t.Fatalf("unexpected collateral after migration: %v", user.CollateralZNHB)
+	if user.CollateralOf(lending.AssetZNHB).Cmp(account.CollateralBalance) != 0 {
+		t.Fatalf("unexpected collateral after migration: %v", user.CollateralOf(lending.AssetZNHB))
 	}
 	if user.DebtNHB.Cmp(account.DebtPrincipal) != 0 {
 		t.Fatalf("unexpected debt after migration: %v", user.DebtNHB)
@@ -348,8 +348,8 @@ func TestApplyLendingLiquidate_SeizesCollateralAndClearsDebt(t *testing.T) {
 		t.Fatalf("expected borrower debt cleared, got %v", userAccount.DebtNHB)
 	}
 	// 800 NHB repaid seizes 800 * 1.10 = 880 ZNHB of the borrower's 1000 ZNHB collateral.
-	if userAccount.CollateralZNHB == nil || userAccount.CollateralZNHB.Cmp(mustBigInt(t, "120000000000000000000")) != 0 {
-		t.Fatalf("unexpected remaining borrower collateral: %v", userAccount.CollateralZNHB)
+	if userAccount.CollateralOf(lending.AssetZNHB).Cmp(mustBigInt(t, "120000000000000000000")) != 0 {
+		t.Fatalf("unexpected remaining borrower collateral: %v", userAccount.CollateralOf(lending.AssetZNHB))
 	}
 
 	market, ok, err := manager.LendingGetMarket("default")
@@ -75,6 +75,7 @@ type Node struct {
 	chain                 *Blockchain
 	syncMgr               *syncmgr.Manager
 	validatorKey          *crypto.PrivateKey
+	creatorAccessKey      *crypto.PrivateKey
 	mempool               []*types.Transaction
 	mempoolMu             sync.Mutex
 	proposedTxs           map[string]struct{}
@@ -155,6 +156,13 @@ type Node struct {
 	// engine's NotifyExternalCommit once both are constructed, mirroring
 	// the SetNetworkBroadcaster wiring pattern below.
 	externalCommitNotifier func()
+	// upgradeHaltHandler, if set, is called once after the block at a
+	// scheduled software upgrade's height commits on a binary without the
+	// upgrade's handler. Wired by cmd/nhb and
+	// cmd/consensusd to shut the process down cleanly so operators can
+	// swap binaries. See core/upgrade.go.
+	upgradeHaltHandler func(governance.UpgradePlan)
+	upgradeHaltOnce    sync.Once
 
 	posStreamMu      sync.RWMutex
 	posStreamSeq     uint64
@@ -346,6 +354,7 @@ func (n *Node) rebuildStateProcessorLocked(root common.Hash) error {
 	if err := stateProcessor.SetEngagementConfig(n.state.EngagementConfig()); err != nil {
 		return err
 	}
+	stateProcessor.SetIdentityTerms(n.state.IdentityTerms())
 	if err := stateProcessor.SetEpochConfig(n.state.EpochConfig()); err != nil {
 		return err
 	}
@@ -521,11 +530,19 @@ func NewNode(db storage.Database, key *crypto.PrivateKey, genesisPath string, al
 
 	pLedger, _ := statepotso.NewLedger(nil, nil)
 
+	// Creator access tokens get their own key. Operators can replace this
+	// per-process key with a persistent one through SetCreatorAccessKey.
+	accessKey, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate creator access key: %w", err)
+	}
+
 	node := &Node{
 		db:                         db,
 		state:                      stateProcessor,
 		chain:                      chain,
 		validatorKey:               key,
+		creatorAccessKey:           accessKey,
 		mempool:                    make([]*types.Transaction, 0),
 		proposedTxs:                make(map[string]struct{}),
 		posArrival:                 make(map[string]time.Time),
@@ -533,7 +550,6 @@ func NewNode(db storage.Database, key *crypto.PrivateKey, genesisPath string, al
 		senderNonces:               make(map[string]map[uint64]time.Time),
 		pendingNonces:              make(map[string]nonceRecord),
 		escrowTreasury:             treasury,
-		engagementMgr:              engagement.NewManager(stateProcessor.EngagementConfig()),
 		swapCfg:                    defaultSwapCfg,
 		swapSanctions:              swap.DefaultSanctionsChecker,
 		swapRefundSink:             treasury,
@@ -648,6 +664,7 @@ func NewNode(db storage.Database, key *crypto.PrivateKey, genesisPath string, al
 		return nil, err
 	}
 
+	node.engagementMgr = engagement.NewManagerWithRegistry(stateProcessor.EngagementConfig(), engagementDeviceView{node: node})
 	return node, nil
 }
 
@@ -1276,9 +1293,31 @@ func (n *Node) SetGlobalConfig(cfg config.Global) error {
 		return err
 	}
 	n.SetTransferGasPolicy(transferPolicy)
+	terms, err := buildIdentityTermsFromConfig(cfg.Identity)
+	if err != nil {
+		return err
+	}
+	n.stateMu.Lock()
+	if n.state != nil {
+		n.state.SetIdentityTerms(terms)
+	}
+	n.stateMu.Unlock()
 	return nil
 }
 
+func buildIdentityTermsFromConfig(cfg config.Identity) (identity.Terms, error) {
+	fee, err := cfg.AliasRenewalFee()
+	if err != nil {
+		return identity.Terms{}, fmt.Errorf("identity: %w", err)
+	}
+	const day = 24 * time.Hour
+	return identity.Terms{
+		Term:        time.Duration(cfg.AliasTermDays) * day,
+		GracePeriod: time.Duration(cfg.AliasGraceDays) * day,
+		RenewalFee:  fee,
+	}, nil
+}
+
 func buildFeePolicyFromConfig(cfg config.Fees) (fees.Policy, error) {
 	policy := fees.Policy{
 		Version: 1,
@@ -1321,7 +1360,7 @@ func buildFeePolicyFromConfig(cfg config.Fees) (fees.Policy, error) {
 		OwnerWallet:           ownerWallet,
 		Assets:                assets,
 	}
-	for _, domain := range []string{fees.DomainPOS, "p2p", "otc"} {
+	for _, domain := range []string{fees.DomainPOS, "p2p", "otc", fees.DomainIdentity} {
 		policy.Domains[domain] = domainPolicy
 	}
 	return policy, nil
@@ -1873,6 +1912,8 @@ func (n *Node) SetLendingRiskParameters(params lending.RiskParameters) {
 		MaxLTV:               params.MaxLTV,
 		LiquidationThreshold: params.LiquidationThreshold,
 		LiquidationBonus:     params.LiquidationBonus,
+		CloseFactorBps:       params.CloseFactorBps,
+		Auction:              params.Auction,
 		CircuitBreakerActive: params.CircuitBreakerActive,
 		DeveloperFeeCapBps:   params.DeveloperFeeCapBps,
 		BorrowCaps:           params.BorrowCaps.Clone(),
@@ -3303,6 +3344,10 @@ func (n *Node) CreateBlock(txs []*types.Transaction) (block *types.Block, err er
 		stateCopy.SetQuotaConfig(n.moduleQuotaSnapshot())
 		blockTime = time.Unix(timestamp, 0).UTC()
 		stateCopy.BeginBlock(height, blockTime)
+		if err := applyScheduledUpgrade(stateCopy, height); err != nil {
+			stateCopy.EndBlock()
+			return nil, nil, nil, err
+		}
 
 		keptTxs := make([]*types.Transaction, 0, len(orderedTxs))
 		attemptPruned := make([]*types.Transaction, 0)
@@ -3352,7 +3397,10 @@ func (n *Node) CreateBlock(txs []*types.Transaction) (block *types.Block, err er
 			stateCopy.EndBlock()
 			return nil, nil, nil, err
 		}
-		stateCopy.FinalizeBlock()
+		if err := stateCopy.FinalizeBlock(); err != nil {
+			stateCopy.EndBlock()
+			return nil, nil, nil, err
+		}
 		return stateCopy, keptTxs, executionGraphRoot, nil
 	}
 
@@ -3447,6 +3495,9 @@ func (n *Node) ValidateBlock(b *types.Block) error {
 	blockTime := time.Unix(b.Header.Timestamp, 0).UTC()
 	stateCopy.BeginBlock(b.Header.Height, blockTime)
 	defer stateCopy.EndBlock()
+	if err := applyScheduledUpgrade(stateCopy, b.Header.Height); err != nil {
+		return err
+	}
 	traceStateRoots := len(b.Transactions) == 0
 	hexRoot := func(root common.Hash) string {
 		return fmt.Sprintf("%x", root.Bytes())
@@ -3486,7 +3537,9 @@ func (n *Node) ValidateBlock(b *types.Block) error {
 	if traceStateRoots {
 		rootAfterEvidence = hexRoot(stateCopy.PendingRoot())
 	}
-	stateCopy.FinalizeBlock()
+	if err := stateCopy.FinalizeBlock(); err != nil {
+		return fmt.Errorf("finalize block: %w", err)
+	}
 	if traceStateRoots {
 		rootAfterFinalize = hexRoot(stateCopy.PendingRoot())
 	}
@@ -3615,6 +3668,9 @@ func (n *Node) commitBlock(b *types.Block, allowHistoricalTimestamp bool) (err e
 	blockTime := time.Unix(b.Header.Timestamp, 0).UTC()
 	stateCopy.BeginBlock(b.Header.Height, blockTime)
 	defer stateCopy.EndBlock()
+	if err := applyScheduledUpgrade(stateCopy, b.Header.Height); err != nil {
+		return err
+	}
 	traceStateRoots := len(b.Transactions) == 0
 	hexRoot := func(root common.Hash) string {
 		return fmt.Sprintf("%x", root.Bytes())
@@ -3670,7 +3726,9 @@ func (n *Node) commitBlock(b *types.Block, allowHistoricalTimestamp bool) (err e
 		rootAfterEvidence = hexRoot(stateCopy.PendingRoot())
 	}
 
-	stateCopy.FinalizeBlock()
+	if err := stateCopy.FinalizeBlock(); err != nil {
+		return fmt.Errorf("finalize block: %w", err)
+	}
 	if traceStateRoots {
 		rootAfterFinalize = hexRoot(stateCopy.PendingRoot())
 	}
@@ -3732,6 +3790,7 @@ func (n *Node) commitBlock(b *types.Block, allowHistoricalTimestamp bool) (err e
 		n.syncMgr.SetHeight(b.Header.Height)
 	}
 	n.publishPOSFinalityFinalized(b)
+	n.signalUpgradeHaltLocked(b.Header.Height, allowHistoricalTimestamp)
 	return nil
 }
 
@@ -4191,6 +4250,109 @@ func (n *Node) GetPOSAuthorizationByIntentRef(intentRef []byte) (*pos.Authorizat
 	return n.state.GetPOSAuthorizationByIntentRef(intentRef)
 }
 
+// GetInvoice returns the native merchant invoice stored under id.
+func (n *Node) GetInvoice(id [32]byte) (*nhbstate.StoredInvoice, error) {
+	if n == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	n.stateMu.RLock()
+	defer n.stateMu.RUnlock()
+	if n.state == nil {
+		return nil, fmt.Errorf("state unavailable")
+	}
+	return n.state.GetInvoice(id)
+}
+
+// ListInvoicesByMerchant returns every invoice opened by merchant in creation
+// order.
+func (n *Node) ListInvoicesByMerchant(merchant [20]byte) ([]*nhbstate.StoredInvoice, error) {
+	if n == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	n.stateMu.RLock()
+	defer n.stateMu.RUnlock()
+	if n.state == nil {
+		return nil, fmt.Errorf("state unavailable")
+	}
+	return n.state.ListInvoicesByMerchant(merchant)
+}
+
+// GetMandate returns the recurring payment mandate stored under id.
+func (n *Node) GetMandate(id [32]byte) (*nhbstate.StoredMandate, error) {
+	if n == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	n.stateMu.RLock()
+	defer n.stateMu.RUnlock()
+	if n.state == nil {
+		return nil, fmt.Errorf("state unavailable")
+	}
+	return n.state.GetMandate(id)
+}
+
+// ListMandatesByPayer returns every mandate signed by payer in creation order.
+func (n *Node) ListMandatesByPayer(payer [20]byte) ([]*nhbstate.StoredMandate, error) {
+	if n == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	n.stateMu.RLock()
+	defer n.stateMu.RUnlock()
+	if n.state == nil {
+		return nil, fmt.Errorf("state unavailable")
+	}
+	return n.state.ListMandatesByPayer(payer)
+}
+
+// ListMandatesByMerchant returns every mandate granted to merchant in creation
+// order.
+func (n *Node) ListMandatesByMerchant(merchant [20]byte) ([]*nhbstate.StoredMandate, error) {
+	if n == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	n.stateMu.RLock()
+	defer n.stateMu.RUnlock()
+	if n.state == nil {
+		return nil, fmt.Errorf("state unavailable")
+	}
+	return n.state.ListMandatesByMerchant(merchant)
+}
+
+// RecoveryStatus returns the guardian set registered for addr and its pending
+// key rotation. Either may be nil.
+func (n *Node) RecoveryStatus(addr [20]byte) (*nhbstate.RecoveryConfig, *nhbstate.RecoveryRequest, error) {
+	if n == nil {
+		return nil, nil, fmt.Errorf("node unavailable")
+	}
+	n.stateMu.RLock()
+	defer n.stateMu.RUnlock()
+	if n.state == nil {
+		return nil, nil, fmt.Errorf("state unavailable")
+	}
+	manager := nhbstate.NewManager(n.state.Trie)
+	cfg, _, err := manager.RecoveryGetConfig(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, _, err := manager.RecoveryGetPending(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, req, nil
+}
+
+// SessionKeys returns the session keys registered by account.
+func (n *Node) SessionKeys(account [20]byte) ([]*nhbstate.SessionKey, error) {
+	if n == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	n.stateMu.RLock()
+	defer n.stateMu.RUnlock()
+	if n.state == nil {
+		return nil, fmt.Errorf("state unavailable")
+	}
+	return nhbstate.NewManager(n.state.Trie).SessionKeysByAccount(account)
+}
+
 func (n *Node) EpochConfig() epoch.Config {
 	if n == nil {
 		return epoch.Config{}
@@ -4800,6 +4962,7 @@ func (n *Node) newEscrowEngine(manager *nhbstate.Manager) *escrow.Engine {
 	engine.SetEmitter(escrowEventEmitter{node: n})
 	engine.SetFeeTreasury(n.escrowTreasury)
 	engine.SetPauses(n)
+	engine.SetReputationScorer(reputationScorer(manager, func() int64 { return n.currentTime().Unix() }))
 	return engine
 }
 
@@ -4899,6 +5062,129 @@ func (n *Node) CreatorPayouts(creatorAddr [20]byte) (*creator.PayoutLedger, erro
 	return engine.Payouts(creatorAddr)
 }
 
+func (n *Node) CreatorSetTier(creatorAddr [20]byte, tier *creator.SubscriptionTier) (*creator.SubscriptionTier, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	engine := n.newCreatorEngine(manager)
+	return engine.SetSubscriptionTier(creatorAddr, tier)
+}
+
+func (n *Node) CreatorTier(creatorAddr [20]byte, id string) (*creator.SubscriptionTier, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	engine := n.newCreatorEngine(manager)
+	return engine.SubscriptionTier(creatorAddr, id)
+}
+
+func (n *Node) CreatorSubscribe(fan [20]byte, creatorAddr [20]byte, tierID string, periods uint32) (*creator.Subscription, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	engine := n.newCreatorEngine(manager)
+	return engine.Subscribe(fan, creatorAddr, tierID, periods)
+}
+
+func (n *Node) CreatorRenewSubscription(fan [20]byte, creatorAddr [20]byte, periods uint32) (*creator.Subscription, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	engine := n.newCreatorEngine(manager)
+	return engine.RenewSubscription(fan, creatorAddr, periods)
+}
+
+func (n *Node) CreatorCancelSubscription(fan [20]byte, creatorAddr [20]byte) (*creator.Subscription, *big.Int, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	engine := n.newCreatorEngine(manager)
+	return engine.CancelSubscription(fan, creatorAddr)
+}
+
+func (n *Node) CreatorSubscription(creatorAddr [20]byte, fan [20]byte) (*creator.Subscription, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	engine := n.newCreatorEngine(manager)
+	return engine.Subscription(creatorAddr, fan)
+}
+
+// SetCreatorAccessKey configures the key used to sign creator access tokens.
+// It must not be the validator key so the token endpoint cannot be used to
+// obtain consensus signatures.
+func (n *Node) SetCreatorAccessKey(key *crypto.PrivateKey) {
+	if n == nil {
+		return
+	}
+	n.creatorAccessKey = key
+}
+
+// CreatorAccessIssuer returns the address that signs this node's creator
+// access tokens.
+func (n *Node) CreatorAccessIssuer() [20]byte {
+	var out [20]byte
+	if n == nil || n.creatorAccessKey == nil {
+		return out
+	}
+	copy(out[:], n.creatorAccessKey.PubKey().Address().Bytes())
+	return out
+}
+
+// CreatorAccessToken issues a short-lived access token for the fan's active
+// subscription, signed with this node's access token key.
+func (n *Node) CreatorAccessToken(fan [20]byte, creatorAddr [20]byte, tierID string) (string, *creator.AccessClaims, error) {
+	if n.creatorAccessKey == nil {
+		return "", nil, fmt.Errorf("creator: access token key not configured")
+	}
+	n.stateMu.Lock()
+	manager := nhbstate.NewManager(n.state.Trie)
+	engine := n.newCreatorEngine(manager)
+	claims, err := engine.AccessClaims(fan, creatorAddr, tierID)
+	n.stateMu.Unlock()
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := creator.SignAccessToken(claims, n.creatorAccessKey.PrivateKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// CreatorVerifyAccessToken checks that the token was issued by this node, has
+// not expired and that the subscription it attests is still active on chain.
+// A token that fails any check is reported as invalid with a reason rather
+// than an error so hosts can surface it to the fan.
+func (n *Node) CreatorVerifyAccessToken(token string) (*creator.AccessClaims, bool, string, error) {
+	claims, issuer, err := creator.ParseAccessToken(token)
+	if err != nil {
+		return nil, false, err.Error(), nil
+	}
+	if n.creatorAccessKey == nil || issuer != n.CreatorAccessIssuer() {
+		return claims, false, "token not issued by this node", nil
+	}
+	now := n.currentTime().Unix()
+	if now >= claims.ExpiresAt {
+		return claims, false, "token expired", nil
+	}
+	creatorAddr, fan, err := claims.Parties()
+	if err != nil {
+		return claims, false, err.Error(), nil
+	}
+	subscription, err := n.CreatorSubscription(creatorAddr, fan)
+	if err != nil || !subscription.ActiveAt(now) || subscription.TierID != claims.TierID {
+		return claims, false, "subscription no longer active", nil
+	}
+	return claims, true, "", nil
+}
+
 func (n *Node) EscrowCreate(payer, payee [20]byte, token string, amount *big.Int, feeBps uint32, deadline int64, nonce uint64, mediator *[20]byte, meta [32]byte, realm string) ([32]byte, error) {
 	n.stateMu.Lock()
 	defer n.stateMu.Unlock()
@@ -4957,6 +5243,28 @@ func (n *Node) EscrowDispute(id [32]byte, caller [20]byte, reason string) error
 	return engine.Dispute(id, caller, reason)
 }
 
+// EscrowSubmitEvidence attaches an evidence hash and optional URI to a
+// disputed escrow on behalf of the payer or payee.
+func (n *Node) EscrowSubmitEvidence(id [32]byte, caller [20]byte, hash [32]byte, uri string) error {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	engine := n.newEscrowEngine(manager)
+	return engine.SubmitEvidence(id, caller, hash, uri)
+}
+
+// EscrowEscalate hands a dispute whose arbitration SLA elapsed to the
+// fallback arbitrators of its frozen policy.
+func (n *Node) EscrowEscalate(id [32]byte) error {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	engine := n.newEscrowEngine(manager)
+	return engine.EscalateDispute(id)
+}
+
 func (n *Node) EscrowResolve(id [32]byte, caller [20]byte, outcome string) error {
 	n.stateMu.Lock()
 	defer n.stateMu.Unlock()
@@ -5458,12 +5766,18 @@ func (n *Node) sweepMilestoneDueLegsLocked(manager *nhbstate.Manager, project *e
 			return nil
 		}
 		originalProject := project.Clone()
-		vault := milestoneVaultAddress(project.ID, leg.ID, leg.Token)
-		var vaultAddr [20]byte
-		copy(vaultAddr[:], vault.Bytes())
-		rollback, err := n.milestoneMoveTokenLocked(manager, vaultAddr, project.Payer, leg.Token, leg.Amount)
-		if err != nil {
-			return err
+		var rollback func() error
+		// Arbitrated projects keep expired funds in the vault so either
+		// party can still dispute the leg.
+		if project.FrozenArb == nil {
+			vault := milestoneVaultAddress(project.ID, leg.ID, leg.Token)
+			var vaultAddr [20]byte
+			copy(vaultAddr[:], vault.Bytes())
+			var err error
+			rollback, err = n.milestoneMoveTokenLocked(manager, vaultAddr, project.Payer, leg.Token, leg.Amount)
+			if err != nil {
+				return err
+			}
 		}
 		expired := engine.ExpireDueLeg(project)
 		if expired == nil {
@@ -5510,6 +5824,21 @@ func (n *Node) EscrowMilestoneCreate(project *escrow.MilestoneProject) (*escrow.
 	n.stateMu.Lock()
 	defer n.stateMu.Unlock()
 	manager := nhbstate.NewManager(n.state.Trie)
+	if realmID := strings.TrimSpace(project.RealmID); realmID != "" {
+		_, registered, err := manager.EscrowRealmGet(realmID)
+		if err != nil {
+			return nil, fmt.Errorf("read realm: %w", err)
+		}
+		if registered {
+			escrowEngine := n.newEscrowEngine(manager)
+			escrowEngine.SetNowFunc(func() int64 { return n.currentTime().Unix() })
+			frozen, err := escrowEngine.FreezeRealmPolicy(realmID)
+			if err != nil {
+				return nil, err
+			}
+			project.FrozenArb = frozen
+		}
+	}
 	if err := putMilestoneProject(manager, project); err != nil {
 		return nil, fmt.Errorf("persist milestone: %w", err)
 	}
@@ -5664,7 +5993,7 @@ func (n *Node) EscrowMilestoneCancel(id [32]byte, legID uint64, caller [20]byte)
 	}
 	originalProject := project.Clone()
 	var rollback func() error
-	if leg.Status == escrow.MilestoneLegFunded {
+	if project.LegHoldsFunds(leg) {
 		vault := milestoneVaultAddress(project.ID, leg.ID, leg.Token)
 		var vaultAddr [20]byte
 		copy(vaultAddr[:], vault.Bytes())
@@ -5694,75 +6023,191 @@ func (n *Node) EscrowMilestoneCancel(id [32]byte, legID uint64, caller [20]byte)
 	return nil
 }
 
-// EscrowMilestoneSubscriptionUpdate updates the subscription toggle for a
-// milestone project.
-func (n *Node) EscrowMilestoneSubscriptionUpdate(id [32]byte, caller [20]byte, active bool) (*escrow.MilestoneProject, error) {
+// EscrowMilestoneDispute flags a funded or expired leg for arbitration by the
+// project's frozen arbitrator committee.
+func (n *Node) EscrowMilestoneDispute(id [32]byte, legID uint64, caller [20]byte, reason string) error {
 	n.stateMu.Lock()
 	defer n.stateMu.Unlock()
 	manager := nhbstate.NewManager(n.state.Trie)
 	project, ok, err := getMilestoneProject(manager, id)
 	if err != nil {
-		return nil, fmt.Errorf("read milestone: %w", err)
+		return fmt.Errorf("read milestone: %w", err)
 	}
 	if !ok {
-		return nil, escrow.ErrMilestoneNotFound
+		return escrow.ErrMilestoneNotFound
 	}
-	if project.Payer != caller {
-		return nil, milestoneUnauthorized("payer")
+	if project.Payer != caller && project.Payee != caller {
+		return milestoneUnauthorized("payer or payee")
 	}
 	if err := n.sweepMilestoneDueLegsLocked(manager, project); err != nil {
-		return nil, err
+		return err
 	}
-
-	if project.Subscription == nil {
-		return nil, fmt.Errorf("escrow: project does not have a subscription")
+	engine := escrow.NewMilestoneEngine(func() time.Time { return n.currentTime() })
+	if err := engine.DisputeLeg(project, legID, caller, reason); err != nil {
+		return err
 	}
-	project.Subscription.Active = active
-	project.UpdatedAt = n.currentTime().Unix()
-
 	if err := putMilestoneProject(manager, project); err != nil {
-		return nil, fmt.Errorf("persist milestone: %w", err)
+		return fmt.Errorf("persist milestone: %w", err)
 	}
-	return project, nil
+	n.emitMilestoneEvent(escrow.NewMilestoneDisputedEvent(project, project.FindLeg(legID), caller))
+	return nil
 }
 
-// ReputationVerifySkill validates the caller's verifier role and records a
-// skill verification.
-func (n *Node) ReputationVerifySkill(verifier, subject [20]byte, skill string, expiresAt int64) (*reputation.SkillVerification, error) {
-	if n == nil {
-		return nil, fmt.Errorf("reputation: node unavailable")
-	}
-	trimmedSkill := strings.TrimSpace(skill)
-	if trimmedSkill == "" {
-		return nil, fmt.Errorf("reputation: skill required")
-	}
-	issuedAt := n.currentTime().Unix()
-	verification := &reputation.SkillVerification{
-		Subject:   subject,
-		Skill:     trimmedSkill,
-		Verifier:  verifier,
-		IssuedAt:  issuedAt,
-		ExpiresAt: expiresAt,
-	}
-	if err := verification.Validate(); err != nil {
-		return nil, err
-	}
-
+// EscrowMilestoneResolve settles a disputed leg using a decision signed by the
+// project's frozen arbitrator committee, paying each share out of the leg
+// vault and charging the realm fee on each share.
+func (n *Node) EscrowMilestoneResolve(id [32]byte, legID uint64, payload []byte, signatures [][]byte) error {
 	n.stateMu.Lock()
 	defer n.stateMu.Unlock()
-	if n.state == nil {
-		return nil, fmt.Errorf("reputation: state unavailable")
-	}
 	manager := nhbstate.NewManager(n.state.Trie)
-	if !manager.HasRole(roleReputationVerifier, verifier[:]) {
-		return nil, ErrReputationVerifierUnauthorized
-	}
-	ledger := reputation.NewLedger(manager)
+	project, ok, err := getMilestoneProject(manager, id)
+	if err != nil {
+		return fmt.Errorf("read milestone: %w", err)
+	}
+	if !ok {
+		return escrow.ErrMilestoneNotFound
+	}
+	if err := n.sweepMilestoneDueLegsLocked(manager, project); err != nil {
+		return err
+	}
+	leg := project.FindLeg(legID)
+	if leg == nil {
+		return escrow.ErrMilestoneNotFound
+	}
+	originalProject := project.Clone()
+	engine := escrow.NewMilestoneEngine(func() time.Time { return n.currentTime() })
+	resolution, err := engine.ResolveLeg(project, legID, payload, signatures)
+	if err != nil {
+		return err
+	}
+	if resolution == nil {
+		return nil
+	}
+	vault := milestoneVaultAddress(project.ID, leg.ID, leg.Token)
+	var vaultAddr [20]byte
+	copy(vaultAddr[:], vault.Bytes())
+	var rollbacks []func() error
+	undo := func() error {
+		var errs []error
+		for i := len(rollbacks) - 1; i >= 0; i-- {
+			if err := rollbacks[i](); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	type milestoneTransfer struct {
+		to     [20]byte
+		amount *big.Int
+	}
+	transfers := []milestoneTransfer{
+		{project.Payee, resolution.PayeeAmount},
+		{project.Payer, resolution.PayerAmount},
+		{resolution.RealmFeeRecipient, resolution.RealmFee},
+	}
+	for _, payout := range resolution.ArbitratorPayouts {
+		transfers = append(transfers, milestoneTransfer{payout.Arbitrator, payout.Amount})
+	}
+	for _, transfer := range transfers {
+		if transfer.amount == nil || transfer.amount.Sign() == 0 {
+			continue
+		}
+		rollback, err := n.milestoneMoveTokenLocked(manager, vaultAddr, transfer.to, leg.Token, transfer.amount)
+		if err != nil {
+			*project = *originalProject
+			if rollbackErr := undo(); rollbackErr != nil {
+				return errors.Join(err, rollbackErr)
+			}
+			return err
+		}
+		if rollback != nil {
+			rollbacks = append(rollbacks, rollback)
+		}
+	}
+	if err := putMilestoneProject(manager, project); err != nil {
+		*project = *originalProject
+		if rollbackErr := undo(); rollbackErr != nil {
+			return errors.Join(fmt.Errorf("persist milestone: %w", err), rollbackErr)
+		}
+		return fmt.Errorf("persist milestone: %w", err)
+	}
+	n.emitMilestoneEvent(escrow.NewMilestoneResolvedEvent(project, project.FindLeg(legID), resolution))
+	return nil
+}
+
+// EscrowMilestoneSubscriptionUpdate updates the subscription toggle for a
+// milestone project.
+func (n *Node) EscrowMilestoneSubscriptionUpdate(id [32]byte, caller [20]byte, active bool) (*escrow.MilestoneProject, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+	manager := nhbstate.NewManager(n.state.Trie)
+	project, ok, err := getMilestoneProject(manager, id)
+	if err != nil {
+		return nil, fmt.Errorf("read milestone: %w", err)
+	}
+	if !ok {
+		return nil, escrow.ErrMilestoneNotFound
+	}
+	if project.Payer != caller {
+		return nil, milestoneUnauthorized("payer")
+	}
+	if err := n.sweepMilestoneDueLegsLocked(manager, project); err != nil {
+		return nil, err
+	}
+
+	if project.Subscription == nil {
+		return nil, fmt.Errorf("escrow: project does not have a subscription")
+	}
+	project.Subscription.Active = active
+	project.UpdatedAt = n.currentTime().Unix()
+
+	if err := putMilestoneProject(manager, project); err != nil {
+		return nil, fmt.Errorf("persist milestone: %w", err)
+	}
+	return project, nil
+}
+
+// ReputationVerifySkill validates the caller's verifier role and records a
+// skill verification.
+func (n *Node) ReputationVerifySkill(verifier, subject [20]byte, skill string, expiresAt int64) (*reputation.SkillVerification, error) {
+	if n == nil {
+		return nil, fmt.Errorf("reputation: node unavailable")
+	}
+	trimmedSkill := strings.TrimSpace(skill)
+	if trimmedSkill == "" {
+		return nil, fmt.Errorf("reputation: skill required")
+	}
+	issuedAt := n.currentTime().Unix()
+	verification := &reputation.SkillVerification{
+		Subject:   subject,
+		Skill:     trimmedSkill,
+		Verifier:  verifier,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+	}
+	if err := verification.Validate(); err != nil {
+		return nil, err
+	}
+
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+	if n.state == nil {
+		return nil, fmt.Errorf("reputation: state unavailable")
+	}
+	manager := nhbstate.NewManager(n.state.Trie)
+	if !manager.HasRole(roleReputationVerifier, verifier[:]) {
+		return nil, ErrReputationVerifierUnauthorized
+	}
+	ledger := reputation.NewLedger(manager)
 	ledger.SetNowFunc(func() int64 { return n.currentTime().Unix() })
 	if err := ledger.Put(verification); err != nil {
 		return nil, err
 	}
-	n.state.AppendEvent(reputation.NewSkillVerifiedEvent(verification))
+	event := reputation.NewSkillVerifiedEvent(verification)
+	if err := reputation.NewScoreBook(manager).Apply([]types.Event{*event}, issuedAt); err != nil {
+		return nil, err
+	}
+	n.state.AppendEvent(event)
 	return verification, nil
 }
 
@@ -5787,10 +6232,29 @@ func (n *Node) ReputationRevokeSkill(verifier [20]byte, attestationID [32]byte,
 	if err != nil {
 		return nil, err
 	}
-	n.state.AppendEvent(reputation.NewSkillRevokedEvent(revocation))
+	event := reputation.NewSkillRevokedEvent(revocation)
+	if err := reputation.NewScoreBook(manager).Apply([]types.Event{*event}, revocation.RevokedAt); err != nil {
+		return nil, err
+	}
+	n.state.AppendEvent(event)
 	return revocation, nil
 }
 
+// ReputationScore returns the reputation aggregate for addr together with its
+// score breakdown at the current time.
+func (n *Node) ReputationScore(addr [20]byte) (*reputation.Aggregate, reputation.ScoreBreakdown, error) {
+	if n == nil {
+		return nil, reputation.ScoreBreakdown{}, fmt.Errorf("reputation: node unavailable")
+	}
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+	if n.state == nil {
+		return nil, reputation.ScoreBreakdown{}, fmt.Errorf("reputation: state unavailable")
+	}
+	manager := nhbstate.NewManager(n.state.Trie)
+	return reputation.NewScoreBook(manager).Score(addr, n.currentTime().Unix())
+}
+
 func (n *Node) P2PCreateTrade(offerID string, buyer, seller [20]byte,
 	baseToken string, baseAmt *big.Int,
 	quoteToken string, quoteAmt *big.Int,
@@ -5898,13 +6362,30 @@ func (n *Node) P2PResolve(id [32]byte, arbitrator [20]byte, outcome string) erro
 	return tradeEngine.TradeResolve(id, outcome)
 }
 
+// P2PResolveSplit settles a disputed trade by splitting each leg between its
+// payee and payer. Only ROLE_ARBITRATOR holders may split a trade.
+func (n *Node) P2PResolveSplit(id [32]byte, arbitrator [20]byte, base, quote escrow.SplitDecision) error {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	if !n.state.HasRole("ROLE_ARBITRATOR", arbitrator[:]) {
+		return fmt.Errorf("trade: caller lacks arbitrator role")
+	}
+	if _, ok := manager.TradeGet(id); !ok {
+		return ErrTradeNotFound
+	}
+	tradeEngine := n.newTradeEngine(manager)
+	return tradeEngine.TradeResolveSplit(id, base, quote)
+}
+
 func (n *Node) IdentitySetAlias(addr [20]byte, alias string) error {
 	n.stateMu.Lock()
 	defer n.stateMu.Unlock()
 
 	manager := nhbstate.NewManager(n.state.Trie)
 	previous, _ := manager.IdentityReverse(addr[:])
-	if err := manager.IdentitySetAlias(addr[:], alias); err != nil {
+	if _, err := manager.IdentityClaimAlias(addr[:], alias, n.state.IdentityTerms(), n.currentTime().Unix()); err != nil {
 		return err
 	}
 	current, ok := manager.IdentityReverse(addr[:])
@@ -6071,6 +6552,11 @@ func (n *Node) IdentityRename(owner [20]byte, alias string, newAlias string) (*i
 		return nil, identity.ErrNotAliasOwner
 	}
 	previousAlias := record.Alias
+	if target, err := identity.NormalizeAlias(newAlias); err == nil {
+		if err := manager.IdentityCheckSubAliasNamespace(target, n.state.IdentityTerms(), n.currentTime().Unix()); err != nil {
+			return nil, err
+		}
+	}
 	updated, err := manager.IdentityRename(record.Alias, newAlias, time.Now().Unix())
 	if err != nil {
 		return nil, err
@@ -6078,6 +6564,11 @@ func (n *Node) IdentityRename(owner [20]byte, alias string, newAlias string) (*i
 	if previousAlias == updated.Alias {
 		return updated, nil
 	}
+	// Listings are keyed by alias, so an open listing would otherwise be
+	// left behind under the old name.
+	if err := manager.IdentityDeleteListing(previousAlias); err != nil {
+		return nil, err
+	}
 	evt := events.IdentityAliasRenamed{OldAlias: previousAlias, NewAlias: updated.Alias, Address: owner}.Event()
 	if evt != nil {
 		n.state.AppendEvent(evt)
@@ -6085,9 +6576,401 @@ func (n *Node) IdentityRename(owner [20]byte, alias string, newAlias string) (*i
 	return updated, nil
 }
 
+// identitySaleEscrowWindow bounds the escrow used to settle an alias sale.
+// Settlement completes within the same call, so the window only matters if
+// the transfer fails and the buyer is refunded.
+const identitySaleEscrowWindow = int64(time.Hour / time.Second)
+
+// IdentityRenew extends an alias registration by one term and charges the
+// configured renewal fee to the owner. Owners may renew during the term and
+// the grace period that follows it.
+func (n *Node) IdentityRenew(owner [20]byte, alias string) (*identity.AliasRecord, *big.Int, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	record, ok := manager.IdentityResolve(alias)
+	if !ok || record == nil {
+		return nil, nil, identity.ErrAliasNotFound
+	}
+	if !aliasRecordOwnedBy(record, owner) {
+		return nil, nil, identity.ErrNotAliasOwner
+	}
+	terms := n.state.IdentityTerms()
+	now := n.currentTime().Unix()
+	if !terms.Enabled() || record.ExpiresAt == 0 {
+		return nil, nil, identity.ErrTermsDisabled
+	}
+	if terms.Status(record, now) == identity.AliasStatusReleased {
+		return nil, nil, identity.ErrAliasExpired
+	}
+	fee := terms.Fee()
+	var updated *identity.AliasRecord
+	err := n.withStateRollback(func() error {
+		if err := n.collectIdentityRenewalFee(manager, owner, fee); err != nil {
+			return err
+		}
+		var err error
+		updated, err = manager.IdentityRenew(record.Alias, terms, now)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	evt := events.IdentityAliasRenewed{Alias: updated.Alias, Owner: owner, ExpiresAt: updated.ExpiresAt, Fee: fee}.Event()
+	if evt != nil {
+		n.state.AppendEvent(evt)
+	}
+	return updated, fee, nil
+}
+
+// collectIdentityRenewalFee moves a renewal fee from payer to the NHB route
+// wallet of the identity fee domain and records it in the fee totals.
+func (n *Node) collectIdentityRenewalFee(manager *nhbstate.Manager, payer [20]byte, fee *big.Int) error {
+	if fee == nil || fee.Sign() <= 0 {
+		return nil
+	}
+	n.feesMu.RLock()
+	policy := n.feesPolicy
+	n.feesMu.RUnlock()
+	var wallet [20]byte
+	if cfg, ok := policy.DomainConfig(fees.DomainIdentity); ok {
+		wallet = cfg.OwnerWallet
+		if asset, ok := cfg.AssetConfig(fees.AssetNHB); ok && asset.OwnerWallet != ([20]byte{}) {
+			wallet = asset.OwnerWallet
+		}
+	}
+	if wallet == ([20]byte{}) {
+		return fmt.Errorf("fees: missing route wallet for %s renewal fees", fees.DomainIdentity)
+	}
+	payerAcc, err := manager.GetAccount(payer[:])
+	if err != nil {
+		return err
+	}
+	if payerAcc.BalanceNHB == nil || payerAcc.BalanceNHB.Cmp(fee) < 0 {
+		return fmt.Errorf("identity: insufficient NHB balance for renewal fee")
+	}
+	payerAcc.BalanceNHB = new(big.Int).Sub(payerAcc.BalanceNHB, fee)
+	if err := manager.PutAccount(payer[:], payerAcc); err != nil {
+		return err
+	}
+	routeAcc, err := manager.GetAccount(wallet[:])
+	if err != nil {
+		return err
+	}
+	if routeAcc.BalanceNHB == nil {
+		routeAcc.BalanceNHB = big.NewInt(0)
+	}
+	routeAcc.BalanceNHB = new(big.Int).Add(routeAcc.BalanceNHB, fee)
+	if err := manager.PutAccount(wallet[:], routeAcc); err != nil {
+		return err
+	}
+	return manager.FeesAccumulateTotals(fees.DomainIdentity, fees.AssetNHB, wallet, fee, fee, big.NewInt(0))
+}
+
+// IdentityListForSale offers an alias for sale at price NHB. A non-zero buyer
+// reserves the listing for that address; reserved listings may use a zero
+// price to transfer the alias without payment. Listing again replaces any
+// existing listing.
+func (n *Node) IdentityListForSale(owner [20]byte, alias string, price *big.Int, buyer [20]byte) (*identity.Listing, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	record, ok := manager.IdentityResolve(alias)
+	if !ok || record == nil {
+		return nil, identity.ErrAliasNotFound
+	}
+	if !aliasRecordOwnedBy(record, owner) {
+		return nil, identity.ErrNotAliasOwner
+	}
+	now := n.currentTime().Unix()
+	if n.state.IdentityTerms().Status(record, now) != identity.AliasStatusActive {
+		return nil, identity.ErrAliasExpired
+	}
+	if price == nil {
+		price = big.NewInt(0)
+	}
+	if price.Sign() < 0 || (price.Sign() == 0 && buyer == ([20]byte{})) {
+		return nil, identity.ErrInvalidPrice
+	}
+	if buyer == owner {
+		return nil, fmt.Errorf("%w: buyer must differ from seller", identity.ErrInvalidAddress)
+	}
+	listing, err := manager.IdentityPutListing(&identity.Listing{
+		Alias:    record.Alias,
+		Seller:   owner,
+		Buyer:    buyer,
+		Price:    price,
+		ListedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	evt := events.IdentityAliasListed{Alias: listing.Alias, Seller: owner, Buyer: buyer, Price: listing.Price}.Event()
+	if evt != nil {
+		n.state.AppendEvent(evt)
+	}
+	return listing, nil
+}
+
+// IdentityCancelListing withdraws the owner's listing for the alias.
+func (n *Node) IdentityCancelListing(owner [20]byte, alias string) error {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	listing, ok, err := manager.IdentityGetListing(alias)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return identity.ErrListingNotFound
+	}
+	if listing.Seller != owner {
+		return identity.ErrNotAliasOwner
+	}
+	if err := manager.IdentityDeleteListing(listing.Alias); err != nil {
+		return err
+	}
+	evt := events.IdentityAliasUnlisted{Alias: listing.Alias, Seller: owner}.Event()
+	if evt != nil {
+		n.state.AppendEvent(evt)
+	}
+	return nil
+}
+
+// IdentityGetListing returns the open sale listing for the alias.
+func (n *Node) IdentityGetListing(alias string) (*identity.Listing, bool, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	return manager.IdentityGetListing(alias)
+}
+
+// IdentityCreateSubAlias delegates label.parent to target on behalf of the
+// parent alias owner.
+func (n *Node) IdentityCreateSubAlias(owner [20]byte, parent, label string, target [20]byte) (*identity.SubAlias, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	record, err := n.identitySubAliasParent(manager, owner, parent)
+	if err != nil {
+		return nil, err
+	}
+	normalizedLabel, err := identity.NormalizeSubAliasLabel(label)
+	if err != nil {
+		return nil, err
+	}
+	name := normalizedLabel + "." + record.Alias
+	if _, exists, err := manager.IdentityGetSubAlias(name); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, identity.ErrAliasTaken
+	}
+	now := n.currentTime().Unix()
+	sub := &identity.SubAlias{Label: normalizedLabel, Parent: record.Alias, Target: target, CreatedAt: now, UpdatedAt: now}
+	if err := manager.IdentityPutSubAlias(sub); err != nil {
+		return nil, err
+	}
+	evt := events.IdentitySubAliasCreated{Alias: sub.Name(), Parent: sub.Parent, Owner: owner, Target: target}.Event()
+	if evt != nil {
+		n.state.AppendEvent(evt)
+	}
+	return sub, nil
+}
+
+// IdentityReassignSubAlias points an existing sub-alias at a new target.
+func (n *Node) IdentityReassignSubAlias(owner [20]byte, name string, target [20]byte) (*identity.SubAlias, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	sub, err := n.identityOwnedSubAlias(manager, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	previous := sub.Target
+	sub.Target = target
+	sub.UpdatedAt = n.currentTime().Unix()
+	if err := manager.IdentityPutSubAlias(sub); err != nil {
+		return nil, err
+	}
+	evt := events.IdentitySubAliasReassigned{Alias: sub.Name(), Parent: sub.Parent, Owner: owner, PreviousTarget: previous, Target: target}.Event()
+	if evt != nil {
+		n.state.AppendEvent(evt)
+	}
+	return sub, nil
+}
+
+// IdentityRevokeSubAlias removes a sub-alias.
+func (n *Node) IdentityRevokeSubAlias(owner [20]byte, name string) error {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	sub, err := n.identityOwnedSubAlias(manager, owner, name)
+	if err != nil {
+		return err
+	}
+	if err := manager.IdentityDeleteSubAlias(sub.Name()); err != nil {
+		return err
+	}
+	evt := events.IdentitySubAliasRevoked{Alias: sub.Name(), Parent: sub.Parent, Owner: owner}.Event()
+	if evt != nil {
+		n.state.AppendEvent(evt)
+	}
+	return nil
+}
+
+// IdentitySubAliases lists the sub-aliases delegated by parent.
+func (n *Node) IdentitySubAliases(parent string) ([]*identity.SubAlias, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	record, ok := manager.IdentityResolveAt(parent, n.state.IdentityTerms(), n.currentTime().Unix())
+	if !ok || record == nil || record.Parent != "" {
+		return nil, identity.ErrAliasNotFound
+	}
+	return manager.IdentitySubAliases(record.Alias)
+}
+
+// identitySubAliasParent loads a flat parent alias that still resolves and
+// checks that owner controls it.
+func (n *Node) identitySubAliasParent(manager *nhbstate.Manager, owner [20]byte, parent string) (*identity.AliasRecord, error) {
+	record, ok := manager.IdentityResolveAt(parent, n.state.IdentityTerms(), n.currentTime().Unix())
+	if !ok || record == nil || record.Parent != "" {
+		return nil, identity.ErrAliasNotFound
+	}
+	if !aliasRecordOwnedBy(record, owner) {
+		return nil, identity.ErrNotAliasOwner
+	}
+	return record, nil
+}
+
+func (n *Node) identityOwnedSubAlias(manager *nhbstate.Manager, owner [20]byte, name string) (*identity.SubAlias, error) {
+	sub, ok, err := manager.IdentityGetSubAlias(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, identity.ErrSubAliasNotFound
+	}
+	if _, err := n.identitySubAliasParent(manager, owner, sub.Parent); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// IdentityBuy purchases a listed alias. The buyer must quote the listed price
+// so a listing changed in the meantime cannot charge more than expected. The
+// price is settled through an NHB escrow that the buyer funds and that is
+// released to the seller, and the alias and its username move to the buyer.
+// Every step runs under withStateRollback, so a failure anywhere leaves the
+// state untouched.
+func (n *Node) IdentityBuy(buyer [20]byte, alias string, price *big.Int) (*identity.AliasRecord, [32]byte, error) {
+	n.stateMu.Lock()
+	defer n.stateMu.Unlock()
+
+	manager := nhbstate.NewManager(n.state.Trie)
+	now := n.currentTime().Unix()
+	terms := n.state.IdentityTerms()
+	record, ok := manager.IdentityResolveAt(alias, terms, now)
+	if !ok || record == nil {
+		return nil, [32]byte{}, identity.ErrAliasNotFound
+	}
+	if terms.Status(record, now) != identity.AliasStatusActive {
+		return nil, [32]byte{}, identity.ErrAliasExpired
+	}
+	listing, ok, err := manager.IdentityGetListing(record.Alias)
+	if err != nil {
+		return nil, [32]byte{}, err
+	}
+	if !ok || !aliasRecordOwnedBy(record, listing.Seller) {
+		return nil, [32]byte{}, identity.ErrListingNotFound
+	}
+	seller := listing.Seller
+	if buyer == seller || (listing.Reserved() && listing.Buyer != buyer) {
+		return nil, [32]byte{}, identity.ErrListingMismatch
+	}
+	if price == nil || price.Cmp(listing.Price) != 0 {
+		return nil, [32]byte{}, identity.ErrListingMismatch
+	}
+	if linked, ok := manager.IdentityReverse(buyer[:]); ok && linked != record.Alias {
+		return nil, [32]byte{}, identity.ErrAddressLinked
+	}
+
+	var (
+		updated  *identity.AliasRecord
+		escrowID [32]byte
+	)
+	err = n.withStateRollback(func() error {
+		if listing.Price.Sign() > 0 {
+			buyerAcc, err := manager.GetAccount(buyer[:])
+			if err != nil {
+				return err
+			}
+			if buyerAcc.BalanceNHB == nil || buyerAcc.BalanceNHB.Cmp(listing.Price) < 0 {
+				return fmt.Errorf("identity: insufficient NHB balance to buy alias")
+			}
+			engine := n.newEscrowEngine(manager)
+			engine.SetNowFunc(func() int64 { return now })
+			esc, err := engine.Create(buyer, seller, "NHB", listing.Price, 0, now+identitySaleEscrowWindow, listing.Nonce, nil, record.AliasID(), "")
+			if err != nil {
+				return err
+			}
+			if err := engine.Fund(esc.ID, buyer); err != nil {
+				return err
+			}
+			if err := engine.Release(esc.ID, seller); err != nil {
+				return err
+			}
+			escrowID = esc.ID
+		}
+		var err error
+		updated, err = manager.IdentityTransfer(record.Alias, buyer, now)
+		if err != nil {
+			return err
+		}
+		return n.state.transferUsername(updated.Alias, buyer[:])
+	})
+	if err != nil {
+		return nil, [32]byte{}, err
+	}
+	evt := events.IdentityAliasSold{Alias: updated.Alias, Seller: seller, Buyer: buyer, Price: listing.Price, EscrowID: escrowID}.Event()
+	if evt != nil {
+		n.state.AppendEvent(evt)
+	}
+	return updated, escrowID, nil
+}
+
+// withStateRollback runs fn against the pending state and discards every
+// trie write, username index change and event it made if fn fails, so a
+// multi-step mutation applies entirely or not at all. Callers must hold
+// stateMu.
+func (n *Node) withStateRollback(fn func() error) error {
+	snapshot, err := n.state.Trie.Copy()
+	if err != nil {
+		return err
+	}
+	eventMark := len(n.state.events)
+	if err := fn(); err != nil {
+		n.state.Trie.Revert(snapshot)
+		n.state.events = n.state.events[:eventMark]
+		if loadErr := n.state.loadUsernameIndex(); loadErr != nil {
+			return fmt.Errorf("%w (restore username index: %v)", err, loadErr)
+		}
+		return err
+	}
+	return nil
+}
+
 func (n *Node) IdentityResolve(alias string) (*identity.AliasRecord, bool) {
 	manager := nhbstate.NewManager(n.state.Trie)
-	return manager.IdentityResolve(alias)
+	return manager.IdentityResolveAt(alias, n.state.IdentityTerms(), n.currentTime().Unix())
 }
 
 func (n *Node) IdentityReverse(addr [20]byte) (string, bool) {
@@ -6095,6 +6978,9 @@ func (n *Node) IdentityReverse(addr [20]byte) (string, bool) {
 	return manager.IdentityReverse(addr[:])
 }
 
+// EngagementRegisterDevice issues a token for deviceID and submits its
+// registration as a TxTypeRegisterDevice transaction signed by the validator
+// key. The device can send heartbeats once the transaction is included.
 func (n *Node) EngagementRegisterDevice(addr [20]byte, deviceID string) (string, error) {
 	if n.engagementMgr == nil {
 		return "", fmt.Errorf("engagement manager unavailable")
@@ -6103,7 +6989,114 @@ func (n *Node) EngagementRegisterDevice(addr [20]byte, deviceID string) (string,
 	if !bytes.Equal(addr[:], validator.Bytes()) {
 		return "", fmt.Errorf("device must register validator address %s", validator.String())
 	}
-	return n.engagementMgr.RegisterDevice(addr, deviceID)
+	token, err := n.engagementMgr.RegisterDevice(addr, deviceID)
+	if err != nil {
+		return "", err
+	}
+	data, err := EncodeEngagementDeviceRegistration(strings.TrimSpace(deviceID), engagement.TokenHash(token))
+	if err != nil {
+		return "", err
+	}
+	if err := n.submitValidatorTx(types.TxTypeRegisterDevice, data); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// EngagementListDevices returns the heartbeat devices registered to addr.
+func (n *Node) EngagementListDevices(addr [20]byte) ([]engagement.Device, error) {
+	if n.engagementMgr == nil {
+		return nil, fmt.Errorf("engagement manager unavailable")
+	}
+	return n.engagementMgr.ListDevices(addr)
+}
+
+// EngagementRevokeDevice submits a TxTypeRevokeDevice transaction for a
+// heartbeat device registered to the validator address.
+func (n *Node) EngagementRevokeDevice(addr [20]byte, deviceID string) error {
+	if n.engagementMgr == nil {
+		return fmt.Errorf("engagement manager unavailable")
+	}
+	validator := n.validatorKey.PubKey().Address()
+	if !bytes.Equal(addr[:], validator.Bytes()) {
+		return fmt.Errorf("device must belong to validator address %s", validator.String())
+	}
+	if err := n.engagementMgr.CheckRevocation(addr, deviceID); err != nil {
+		return err
+	}
+	data, err := EncodeEngagementDeviceRevocation(strings.TrimSpace(deviceID))
+	if err != nil {
+		return err
+	}
+	return n.submitValidatorTx(types.TxTypeRevokeDevice, data)
+}
+
+// submitValidatorTx signs a transaction from the validator key and adds it to
+// the mempool. The nonce follows any of the validator's transactions that are
+// already pending so it does not replace them.
+func (n *Node) submitValidatorTx(txType types.TxType, data []byte) error {
+	validator := n.validatorKey.PubKey().Address()
+	account, err := n.GetAccount(validator.Bytes())
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return fmt.Errorf("validator account not found")
+	}
+	tx := &types.Transaction{
+		ChainID:  types.NHBChainID(),
+		Type:     txType,
+		Nonce:    n.nextPendingNonce(validator.Bytes(), account.Nonce),
+		Data:     data,
+		GasLimit: 21000,
+		GasPrice: big.NewInt(1),
+	}
+	if err := tx.Sign(n.validatorKey.PrivateKey); err != nil {
+		return err
+	}
+	return n.AddTransaction(tx)
+}
+
+// nextPendingNonce returns the first nonce at or above accountNonce that no
+// mempool transaction from addr uses.
+func (n *Node) nextPendingNonce(addr []byte, accountNonce uint64) uint64 {
+	n.mempoolMu.Lock()
+	defer n.mempoolMu.Unlock()
+	used := make(map[uint64]struct{})
+	for _, existing := range n.mempool {
+		if existing == nil || existing.Nonce < accountNonce {
+			continue
+		}
+		sender, err := existing.From()
+		if err != nil || !bytes.Equal(sender, addr) {
+			continue
+		}
+		used[existing.Nonce] = struct{}{}
+	}
+	nonce := accountNonce
+	for {
+		if _, taken := used[nonce]; !taken {
+			return nonce
+		}
+		nonce++
+	}
+}
+
+// engagementDeviceView reads heartbeat devices from the node's current state.
+type engagementDeviceView struct {
+	node *Node
+}
+
+func (v engagementDeviceView) EngagementDeviceGet(deviceID string) (*nhbstate.EngagementDevice, bool, error) {
+	v.node.stateMu.RLock()
+	defer v.node.stateMu.RUnlock()
+	return nhbstate.NewManager(v.node.state.Trie).EngagementDeviceGet(deviceID)
+}
+
+func (v engagementDeviceView) EngagementDevicesByOwner(owner [20]byte) ([]*nhbstate.EngagementDevice, error) {
+	v.node.stateMu.RLock()
+	defer v.node.stateMu.RUnlock()
+	return nhbstate.NewManager(v.node.state.Trie).EngagementDevicesByOwner(owner)
 }
 
 // HeartbeatSubmissionMargin is added on top of the configured
@@ -6124,10 +7117,10 @@ const HeartbeatSubmissionMargin = 15 * time.Second
 // heartbeats, as configured on the node's engagement manager -- which is
 // itself constructed from the same engagement.Config the StateProcessor
 // uses for applyHeartbeat's on-chain rate check (see NewNode's
-// engagement.NewManager(stateProcessor.EngagementConfig()) call), so the
-// two never drift apart. Exposed so callers deciding "is it time to submit
-// another heartbeat" against real chain state don't need to hard-code a
-// duplicate constant.
+// engagement.NewManagerWithRegistry(stateProcessor.EngagementConfig(), ...)
+// call), so the two never drift apart. Exposed so callers deciding "is it
+// time to submit another heartbeat" against real chain state don't need to
+// hard-code a duplicate constant.
 func (n *Node) EngagementHeartbeatInterval() time.Duration {
 	if n == nil || n.engagementMgr == nil {
 		return engagement.DefaultConfig().HeartbeatInterval
@@ -6418,6 +7411,13 @@ func (n *Node) GovernanceExecute(proposalID uint64) (*governance.Proposal, error
 	defer n.stateMu.Unlock()
 
 	manager := nhbstate.NewManager(n.state.Trie)
+	if pending, ok, err := manager.GovernanceGetProposal(proposalID); err != nil {
+		return nil, err
+	} else if ok {
+		if err := n.validateUpgradeProposalLocked(pending); err != nil {
+			return nil, err
+		}
+	}
 	engine := n.newGovernanceEngine(manager)
 	if err := engine.Execute(proposalID); err != nil {
 		return nil, err
@@ -7080,7 +8080,7 @@ func (n *Node) IdentityClaim(id [32]byte, preimage []byte, payee [20]byte) (*cla
 	hint := record.RecipientHint
 	if hint != ([32]byte{}) {
 		if alias, ok := manager.IdentityAliasByID(hint); ok {
-			resolved, ok := manager.IdentityResolve(alias)
+			resolved, ok := manager.IdentityResolveAt(alias, n.state.IdentityTerms(), n.currentTime().Unix())
 			if !ok || resolved == nil {
 				return nil, claimable.ErrUnauthorized
 			}
@@ -36,6 +36,25 @@ func seedValidatorAccount(t *testing.T, node *Node) []byte {
 	return validatorAddr
 }
 
+// includePendingTransactions applies the node's pending mempool transactions
+// to its state, as if a block had included them. Device registrations are
+// transactions, so tests include them before sending heartbeats.
+func includePendingTransactions(t *testing.T, node *Node) {
+	t.Helper()
+	node.mempoolMu.Lock()
+	pending := node.mempool
+	node.mempool = make([]*types.Transaction, 0)
+	node.mempoolMu.Unlock()
+
+	node.stateMu.Lock()
+	defer node.stateMu.Unlock()
+	for _, tx := range pending {
+		if err := node.state.ApplyTransaction(tx); err != nil {
+			t.Fatalf("apply pending transaction: %v", err)
+		}
+	}
+}
+
 func TestEngagementSubmitHeartbeatDoesNotDeadlockStateLock(t *testing.T) {
 	node := newTestNode(t)
 
@@ -66,6 +85,7 @@ func TestEngagementSubmitHeartbeatDoesNotDeadlockStateLock(t *testing.T) {
 	if err != nil {
 		t.Fatalf("register device: %v", err)
 	}
+	includePendingTransactions(t, node)
 
 	done := make(chan error, 1)
 	go func() {
@@ -194,6 +214,7 @@ func TestPendingHeartbeatFeeSurvivesGetMempoolProposalTracking(t *testing.T) {
 	if err != nil {
 		t.Fatalf("register device: %v", err)
 	}
+	includePendingTransactions(t, node)
 
 	base := time.Now().UTC().Unix()
 	if _, err := node.EngagementSubmitHeartbeat("pending-fee-test", token, base); err != nil {
@@ -1,10 +1,15 @@
 package core
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"math/big"
 	"testing"
 	"time"
 
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	nhbstate "nhbchain/core/state"
 	"nhbchain/core/types"
 	"nhbchain/native/escrow"
 )
@@ -266,3 +271,158 @@ func TestEscrowMilestoneGetSweepsDueLegAndRefundsPayer(t *testing.T) {
 		t.Fatalf("missing milestone due event")
 	}
 }
+
+func TestEscrowMilestoneDisputedExpiredLegResolvedBySplit(t *testing.T) {
+	sp := newStakingStateProcessor(t)
+	current := time.Unix(1_700_200_000, 0).UTC()
+	node := &Node{
+		state:      sp,
+		timeSource: func() time.Time { return current },
+	}
+
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generate arbitrator key: %v", err)
+	}
+	var arbitrator [20]byte
+	copy(arbitrator[:], ethcrypto.PubkeyToAddress(key.PublicKey).Bytes())
+	var feeRecipient [20]byte
+	feeRecipient[0] = 0x99
+	manager := nhbstate.NewManager(sp.Trie)
+	if err := manager.EscrowRealmPut(&escrow.EscrowRealm{
+		ID:              "studio",
+		Version:         1,
+		NextPolicyNonce: 1,
+		CreatedAt:       current.Unix(),
+		UpdatedAt:       current.Unix(),
+		Arbitrators: &escrow.ArbitratorSet{
+			Scheme:    escrow.ArbitrationSchemeSingle,
+			Threshold: 1,
+			Members:   [][20]byte{arbitrator},
+		},
+		FeeSchedule: &escrow.RealmFeeSchedule{FeeBps: 100, Recipient: feeRecipient},
+		Metadata:    &escrow.EscrowRealmMetadata{Scope: escrow.EscrowRealmScopePlatform, ProviderProfile: "studio"},
+	}); err != nil {
+		t.Fatalf("put realm: %v", err)
+	}
+
+	var payer [20]byte
+	payer[0] = 0x55
+	var payee [20]byte
+	payee[0] = 0x66
+	writeAccount(t, sp, payer, &types.Account{BalanceNHB: big.NewInt(1000), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0)})
+	writeAccount(t, sp, payee, &types.Account{BalanceNHB: big.NewInt(0), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0)})
+
+	project, err := node.EscrowMilestoneCreate(&escrow.MilestoneProject{
+		Payer:   payer,
+		Payee:   payee,
+		RealmID: "studio",
+		Legs: []*escrow.MilestoneLeg{{
+			ID:       1,
+			Type:     escrow.MilestoneLegTypeDeliverable,
+			Title:    "design",
+			Token:    "NHB",
+			Amount:   big.NewInt(1000),
+			Deadline: current.Add(time.Hour).Unix(),
+			Status:   escrow.MilestoneLegPending,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("create milestone: %v", err)
+	}
+	if project.FrozenArb == nil || project.FrozenArb.PolicyNonce != 1 {
+		t.Fatalf("expected frozen policy nonce 1, got %#v", project.FrozenArb)
+	}
+	if err := node.EscrowMilestoneFund(project.ID, 1, payer); err != nil {
+		t.Fatalf("fund milestone: %v", err)
+	}
+
+	current = current.Add(2 * time.Hour)
+	stored, err := node.EscrowMilestoneGet(project.ID)
+	if err != nil {
+		t.Fatalf("get milestone after deadline: %v", err)
+	}
+	if leg := stored.FindLeg(1); leg == nil || leg.Status != escrow.MilestoneLegExpired {
+		t.Fatalf("expected expired leg, got %#v", leg)
+	}
+	vault := milestoneVaultAddress(project.ID, 1, "NHB")
+	var vaultAddr [20]byte
+	copy(vaultAddr[:], vault.Bytes())
+	vaultAcc, err := sp.GetAccount(vaultAddr[:])
+	if err != nil {
+		t.Fatalf("get vault: %v", err)
+	}
+	if vaultAcc.BalanceNHB.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("expected arbitrated vault to retain funds, got %s", vaultAcc.BalanceNHB)
+	}
+
+	var outsider [20]byte
+	outsider[0] = 0x77
+	if err := node.EscrowMilestoneDispute(project.ID, 1, outsider, "spam"); err == nil {
+		t.Fatalf("expected outsider dispute to fail")
+	}
+	if err := node.EscrowMilestoneDispute(project.ID, 1, payee, "work delivered late but complete"); err != nil {
+		t.Fatalf("dispute milestone: %v", err)
+	}
+	if err := node.EscrowMilestoneCancel(project.ID, 1, payer); err == nil {
+		t.Fatalf("expected cancel of disputed leg to fail")
+	}
+
+	decisionID := escrow.MilestoneDecisionID(project.ID, 1)
+	payload, err := json.Marshal(map[string]interface{}{
+		"escrowId":    hex.EncodeToString(decisionID[:]),
+		"outcome":     "split",
+		"policyNonce": project.FrozenArb.PolicyNonce,
+		"payeeBps":    6000,
+		"payerBps":    4000,
+	})
+	if err != nil {
+		t.Fatalf("marshal decision: %v", err)
+	}
+	sig, err := ethcrypto.Sign(ethcrypto.Keccak256(payload), key)
+	if err != nil {
+		t.Fatalf("sign decision: %v", err)
+	}
+	if err := node.EscrowMilestoneResolve(project.ID, 1, payload, [][]byte{sig}); err != nil {
+		t.Fatalf("resolve milestone: %v", err)
+	}
+	if err := node.EscrowMilestoneResolve(project.ID, 1, payload, [][]byte{sig}); err != nil {
+		t.Fatalf("replayed resolution should be a no-op: %v", err)
+	}
+
+	expectBalance := func(label string, addr [20]byte, want int64) {
+		t.Helper()
+		acc, err := sp.GetAccount(addr[:])
+		if err != nil {
+			t.Fatalf("get %s: %v", label, err)
+		}
+		if acc.BalanceNHB.Cmp(big.NewInt(want)) != 0 {
+			t.Fatalf("unexpected %s balance: got %s want %d", label, acc.BalanceNHB, want)
+		}
+	}
+	expectBalance("payee", payee, 594)
+	expectBalance("payer", payer, 396)
+	expectBalance("realm fee recipient", feeRecipient, 10)
+	expectBalance("vault", vaultAddr, 0)
+
+	stored, err = node.EscrowMilestoneGet(project.ID)
+	if err != nil {
+		t.Fatalf("get milestone after resolve: %v", err)
+	}
+	if stored.Status != escrow.MilestoneStatusCompleted {
+		t.Fatalf("unexpected project status after resolve: %d", stored.Status)
+	}
+	if leg := stored.FindLeg(1); leg == nil || leg.Status != escrow.MilestoneLegSplit {
+		t.Fatalf("expected split leg, got %#v", leg)
+	}
+	resolved := findCoreEventByType(sp.Events(), escrow.EventTypeMilestoneResolved)
+	if resolved == nil {
+		t.Fatalf("missing milestone resolved event")
+	}
+	if resolved.Attributes["decision"] != "split" || resolved.Attributes["realmFee"] != "10" {
+		t.Fatalf("unexpected resolved event attributes: %#v", resolved.Attributes)
+	}
+	if findCoreEventByType(sp.Events(), escrow.EventTypeMilestoneDisputed) == nil {
+		t.Fatalf("missing milestone disputed event")
+	}
+}
@@ -442,6 +442,98 @@ func (sp *StateProcessor) checkPaymasterCaps(assessment *SponsorshipAssessment)
 	return nil
 }
 
+// prepareNativeSponsorship evaluates the paymaster attached to a native
+// module transaction before it executes. It returns nil when the transaction
+// is not sponsored. Rejections emit the sponsorship failure event and return
+// ErrSponsorshipRejected so the event survives the failed transaction.
+func (sp *StateProcessor) prepareNativeSponsorship(tx *types.Transaction, sender []byte) (*sponsorshipRuntime, error) {
+	assessment, err := sp.EvaluateSponsorship(tx)
+	if err != nil {
+		return nil, err
+	}
+	var txHash [32]byte
+	if hash, err := tx.Hash(); err == nil {
+		txHash = bytesToHash32(hash)
+	}
+	switch assessment.Status {
+	case SponsorshipStatusNone:
+		return nil, nil
+	case SponsorshipStatusReady:
+	default:
+		sp.emitSponsorshipFailureEvent(common.BytesToAddress(sender), assessment, txHash)
+		return nil, fmt.Errorf("%w: status=%s reason=%s", ErrSponsorshipRejected, assessment.Status, strings.TrimSpace(assessment.Reason))
+	}
+	ctx := &sponsorshipRuntime{
+		sponsor:  assessment.Sponsor,
+		sender:   common.BytesToAddress(sender),
+		budget:   big.NewInt(0),
+		gasPrice: big.NewInt(0),
+		txHash:   txHash,
+		merchant: assessment.merchant,
+		device:   assessment.deviceID,
+		day:      assessment.day,
+	}
+	if assessment.GasCost != nil {
+		ctx.budget = new(big.Int).Set(assessment.GasCost)
+	}
+	if assessment.GasPrice != nil {
+		ctx.gasPrice = new(big.Int).Set(assessment.GasPrice)
+	}
+	return ctx, nil
+}
+
+// chargeNativeSponsorship debits the sponsored gas budget from the paymaster
+// once the native transaction has applied. The charge is routed to the
+// transfer gas collector exactly like a sponsored native transfer.
+func (sp *StateProcessor) chargeNativeSponsorship(tx *types.Transaction, ctx *sponsorshipRuntime) error {
+	if ctx == nil || len(tx.Paymaster) == 0 {
+		return nil
+	}
+	charge := new(big.Int).Set(ctx.budget)
+	sponsorAcc, err := sp.getAccount(tx.Paymaster)
+	if err != nil {
+		return err
+	}
+	if sponsorAcc.BalanceNHB == nil {
+		sponsorAcc.BalanceNHB = big.NewInt(0)
+	}
+	if sponsorAcc.BalanceNHB.Cmp(charge) < 0 {
+		return fmt.Errorf("%w: status=%s reason=%s", ErrSponsorshipRejected, SponsorshipStatusInsufficientBalance, "paymaster balance below required gas budget")
+	}
+	sponsorAcc.BalanceNHB.Sub(sponsorAcc.BalanceNHB, charge)
+	policy := sp.TransferGasPolicy()
+	if policy.Enabled && bytes.Equal(tx.Paymaster, policy.FeeCollector[:]) {
+		sponsorAcc.BalanceNHB.Add(sponsorAcc.BalanceNHB, charge)
+	} else if policy.Enabled {
+		if err := sp.routeTransferGasFee(charge); err != nil {
+			return err
+		}
+	}
+	topUp, err := sp.maybeAutoTopUpPaymaster(ctx.sponsor, tx.Paymaster, sponsorAcc)
+	if err != nil {
+		return err
+	}
+	if err := sp.setAccount(tx.Paymaster, sponsorAcc); err != nil {
+		if topUp != nil {
+			_ = topUp.Rollback(sp)
+		}
+		return err
+	}
+	sp.emitSponsorshipSuccessEvent(ctx, tx.GasLimit, charge, big.NewInt(0))
+	if err := sp.recordPaymasterUsage(ctx, charge); err != nil {
+		if topUp != nil {
+			if rollbackErr := topUp.Rollback(sp); rollbackErr != nil {
+				return errors.Join(err, rollbackErr)
+			}
+		}
+		return err
+	}
+	if topUp != nil {
+		topUp.Finalize(sp)
+	}
+	return nil
+}
+
 // PaymasterLimits returns the current sponsorship caps applied to the state processor.
 func (sp *StateProcessor) PaymasterLimits() PaymasterLimits {
 	if sp == nil {
@@ -21,6 +21,7 @@ var (
 	accountIndexKey       = ethcrypto.Keccak256([]byte("account-index"))
 	usernameIndexKey      = ethcrypto.Keccak256([]byte("username-index"))
 	validatorSetKey       = ethcrypto.Keccak256([]byte("validator-set"))
+	eligibleValidatorsKey = ethcrypto.Keccak256([]byte("validator-eligible-set"))
 )
 
 var (
@@ -689,6 +690,15 @@ func (m *Manager) ResetUsernameIndex() error {
 	return m.trie.Update(usernameIndexKey, encoded)
 }
 
+// WriteUsernameIndex replaces the stored username mappings with index.
+func (m *Manager) WriteUsernameIndex(index map[string][]byte) error {
+	encoded, err := EncodeUsernameIndex(index)
+	if err != nil {
+		return err
+	}
+	return m.trie.Update(usernameIndexKey, encoded)
+}
+
 // EncodeUsernameIndex serializes the username->address mapping into a
 // deterministic RLP representation.
 func EncodeUsernameIndex(index map[string][]byte) ([]byte, error) {
@@ -802,3 +812,23 @@ func (m *Manager) LoadValidatorSet() (map[string]*big.Int, error) {
 	}
 	return DecodeValidatorSet(data)
 }
+
+// WriteEligibleValidatorSet persists the set of accounts eligible to join the
+// validator set alongside their stake.
+func (m *Manager) WriteEligibleValidatorSet(set map[string]*big.Int) error {
+	encoded, err := EncodeValidatorSet(set)
+	if err != nil {
+		return err
+	}
+	return m.trie.Update(eligibleValidatorsKey, encoded)
+}
+
+// LoadEligibleValidatorSet retrieves the eligible validator set stored in
+// state.
+func (m *Manager) LoadEligibleValidatorSet() (map[string]*big.Int, error) {
+	data, err := m.trie.Get(eligibleValidatorsKey)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeValidatorSet(data)
+}
@@ -0,0 +1,490 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"nhbchain/native/escrow"
+)
+
+var (
+	assetFrozenPrefix  = []byte("asset/frozen/")
+	assetFreezesPrefix = []byte("asset/freezes/")
+	assetHolderPrefix  = []byte("asset/holder/")
+	assetHoldersPrefix = []byte("asset/holders/")
+	assetHeldPrefix    = []byte("asset/held/")
+
+	// ErrAssetNotFound is returned when a symbol is not a registered token.
+	ErrAssetNotFound = errors.New("asset: not registered")
+	// ErrAssetExists is returned when creating an asset whose symbol is
+	// already registered.
+	ErrAssetExists = errors.New("asset: already registered")
+	// ErrAssetFrozen is returned when a frozen account sends or receives an
+	// issued asset.
+	ErrAssetFrozen = errors.New("asset: account frozen")
+	// ErrAssetSupplyCap is returned when a mint would exceed the asset's
+	// supply cap.
+	ErrAssetSupplyCap = errors.New("asset: supply cap exceeded")
+	// ErrAssetInsufficientBalance is returned when an account holds less of
+	// an asset than it tries to move or burn.
+	ErrAssetInsufficientBalance = errors.New("asset: insufficient balance")
+)
+
+// IsNativeToken reports whether symbol is NHB or ZNHB. Their balances live on
+// the account record; every other token uses the per-symbol balance store.
+func IsNativeToken(symbol string) bool {
+	switch strings.ToUpper(strings.TrimSpace(symbol)) {
+	case "NHB", "ZNHB":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsIssuedAsset reports whether the token was created through asset issuance
+// rather than registered at genesis.
+func (t *TokenMetadata) IsIssuedAsset() bool {
+	return t != nil && len(t.Issuer) > 0
+}
+
+func assetFrozenKey(symbol string, addr []byte) []byte {
+	buf := make([]byte, 0, len(assetFrozenPrefix)+len(symbol)+1+len(addr))
+	buf = append(buf, assetFrozenPrefix...)
+	buf = append(buf, symbol...)
+	buf = append(buf, '/')
+	return append(buf, addr...)
+}
+
+func assetHolderKey(symbol string, addr []byte) []byte {
+	buf := make([]byte, 0, len(assetHolderPrefix)+len(symbol)+1+len(addr))
+	buf = append(buf, assetHolderPrefix...)
+	buf = append(buf, symbol...)
+	buf = append(buf, '/')
+	return append(buf, addr...)
+}
+
+func assetHoldersKey(symbol string) []byte {
+	return append(append([]byte(nil), assetHoldersPrefix...), symbol...)
+}
+
+// trackAssetHolder adds addr to the holder index of an issued asset the first
+// time it is credited.
+func (m *Manager) trackAssetHolder(symbol string, addr []byte) error {
+	var seen bool
+	ok, err := m.KVGet(assetHolderKey(symbol, addr), &seen)
+	if err != nil || ok {
+		return err
+	}
+	if err := m.KVPut(assetHolderKey(symbol, addr), true); err != nil {
+		return err
+	}
+	return m.KVAppend(assetHoldersKey(symbol), append([]byte(nil), addr...))
+}
+
+// AssetHolders returns every address that has been credited the issued asset
+// symbol, including module vaults such as escrow and claimable holdings, in
+// the order they first received it.
+func (m *Manager) AssetHolders(symbol string) ([][]byte, error) {
+	var holders [][]byte
+	if err := m.KVGetList(assetHoldersKey(strings.ToUpper(strings.TrimSpace(symbol))), &holders); err != nil {
+		return nil, err
+	}
+	return holders, nil
+}
+
+func assetHeldKey(symbol string) []byte {
+	return append(append([]byte(nil), assetHeldPrefix...), symbol...)
+}
+
+// AssetHeldTotal returns the sum of every balance of the issued asset symbol.
+// SetBalance keeps it current, so the per-block supply invariant does not
+// have to visit each holder.
+func (m *Manager) AssetHeldTotal(symbol string) (*big.Int, error) {
+	total := new(big.Int)
+	if _, err := m.KVGet(assetHeldKey(strings.ToUpper(strings.TrimSpace(symbol))), total); err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+func (m *Manager) adjustAssetHeld(symbol string, delta *big.Int) error {
+	if delta.Sign() == 0 {
+		return nil
+	}
+	total, err := m.AssetHeldTotal(symbol)
+	if err != nil {
+		return err
+	}
+	total.Add(total, delta)
+	if total.Sign() < 0 {
+		return fmt.Errorf("asset %s: held total would become negative", symbol)
+	}
+	return m.KVPut(assetHeldKey(symbol), total)
+}
+
+// AuditAssetHolders sums the balance of every address in the holder index of
+// symbol and checks it against the running total. It reads one balance per
+// address that ever held the asset, so it belongs in exports and offline
+// audits rather than block processing.
+func (m *Manager) AuditAssetHolders(symbol string) (*big.Int, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(symbol))
+	holders, err := m.AssetHolders(normalized)
+	if err != nil {
+		return nil, err
+	}
+	held := big.NewInt(0)
+	for _, holder := range holders {
+		balance, err := m.Balance(holder, normalized)
+		if err != nil {
+			return nil, err
+		}
+		held.Add(held, balance)
+	}
+	total, err := m.AssetHeldTotal(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if held.Cmp(total) != 0 {
+		return nil, fmt.Errorf("asset %s: holders hold %s but the running total is %s", normalized, held, total)
+	}
+	return held, nil
+}
+
+// CreateAsset registers a new issued asset. The metadata must name its issuer
+// and mint authority; the symbol must be well formed and unused.
+func (m *Manager) CreateAsset(meta *TokenMetadata) error {
+	if meta == nil {
+		return fmt.Errorf("asset: nil metadata")
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(meta.Symbol))
+	if !escrow.IsAssetSymbol(symbol) {
+		return fmt.Errorf("asset: invalid symbol %q", meta.Symbol)
+	}
+	if strings.TrimSpace(meta.Name) == "" {
+		return fmt.Errorf("asset %s: name must not be empty", symbol)
+	}
+	if len(meta.Issuer) != 20 || len(meta.MintAuthority) != 20 {
+		return fmt.Errorf("asset %s: issuer and mint authority are required", symbol)
+	}
+	if len(meta.FreezeAuthority) != 0 && len(meta.FreezeAuthority) != 20 {
+		return fmt.Errorf("asset %s: invalid freeze authority", symbol)
+	}
+	if meta.SupplyCap != nil && meta.SupplyCap.Sign() < 0 {
+		return fmt.Errorf("asset %s: supply cap must not be negative", symbol)
+	}
+	if existing, err := m.loadTokenMetadata(symbol); err != nil {
+		return err
+	} else if existing != nil {
+		return fmt.Errorf("%w: %s", ErrAssetExists, symbol)
+	}
+	list, err := m.loadTokenList()
+	if err != nil {
+		return err
+	}
+	list = append(list, symbol)
+	sort.Strings(list)
+	if err := m.writeTokenList(list); err != nil {
+		return err
+	}
+	record := &TokenMetadata{
+		Symbol:          symbol,
+		Name:            strings.TrimSpace(meta.Name),
+		Decimals:        meta.Decimals,
+		MintAuthority:   append([]byte(nil), meta.MintAuthority...),
+		FreezeAuthority: append([]byte(nil), meta.FreezeAuthority...),
+		SupplyCap:       cloneBigInt(meta.SupplyCap),
+		Minted:          big.NewInt(0),
+		Burned:          big.NewInt(0),
+		Issuer:          append([]byte(nil), meta.Issuer...),
+	}
+	return m.writeTokenMetadata(symbol, record)
+}
+
+// RestoreAsset registers an issued asset together with its recorded mint and
+// burn counters and pause flag. It is meant for genesis import; transactions
+// go through CreateAsset.
+func (m *Manager) RestoreAsset(meta *TokenMetadata) error {
+	if err := m.CreateAsset(meta); err != nil {
+		return err
+	}
+	record, err := m.issuedAsset(meta.Symbol)
+	if err != nil {
+		return err
+	}
+	if meta.Minted != nil {
+		record.Minted = new(big.Int).Set(meta.Minted)
+	}
+	if meta.Burned != nil {
+		record.Burned = new(big.Int).Set(meta.Burned)
+	}
+	record.MintPaused = meta.MintPaused
+	return m.writeTokenMetadata(record.Symbol, record)
+}
+
+// normalizeRegisteredToken returns the canonical form of token when it is NHB,
+// ZNHB or a registered issued asset.
+func (m *Manager) normalizeRegisteredToken(token string) (string, error) {
+	normalized, err := escrow.NormalizeAsset(token)
+	if err != nil {
+		return "", err
+	}
+	if IsNativeToken(normalized) {
+		return normalized, nil
+	}
+	if !m.TokenExists(normalized) {
+		return "", fmt.Errorf("%w: %s", ErrAssetNotFound, normalized)
+	}
+	return normalized, nil
+}
+
+// AssetFrozen reports whether addr is frozen for the issued asset symbol.
+func (m *Manager) AssetFrozen(symbol string, addr []byte) (bool, error) {
+	var frozen bool
+	normalized := strings.ToUpper(strings.TrimSpace(symbol))
+	if _, err := m.KVGet(assetFrozenKey(normalized, addr), &frozen); err != nil {
+		return false, err
+	}
+	return frozen, nil
+}
+
+// SetAssetFrozen freezes or unfreezes addr for the issued asset symbol and
+// keeps the per-asset list of frozen accounts in step.
+func (m *Manager) SetAssetFrozen(symbol string, addr []byte, frozen bool) error {
+	normalized := strings.ToUpper(strings.TrimSpace(symbol))
+	if len(addr) != 20 {
+		return fmt.Errorf("asset: address must be 20 bytes")
+	}
+	current, err := m.AssetFrozen(normalized, addr)
+	if err != nil || current == frozen {
+		return err
+	}
+	accounts, err := m.AssetFrozenAccounts(normalized)
+	if err != nil {
+		return err
+	}
+	if !frozen {
+		remaining := accounts[:0]
+		for _, account := range accounts {
+			if !bytes.Equal(account, addr) {
+				remaining = append(remaining, account)
+			}
+		}
+		if len(remaining) == 0 {
+			if err := m.KVDelete(assetFreezesKey(normalized)); err != nil {
+				return err
+			}
+		} else if err := m.KVPut(assetFreezesKey(normalized), remaining); err != nil {
+			return err
+		}
+		return m.KVDelete(assetFrozenKey(normalized, addr))
+	}
+	if err := m.KVPut(assetFreezesKey(normalized), append(accounts, append([]byte(nil), addr...))); err != nil {
+		return err
+	}
+	return m.KVPut(assetFrozenKey(normalized, addr), true)
+}
+
+func assetFreezesKey(symbol string) []byte {
+	return append(append([]byte(nil), assetFreezesPrefix...), symbol...)
+}
+
+// AssetFrozenAccounts returns the accounts currently frozen for the issued
+// asset symbol in the order they were frozen.
+func (m *Manager) AssetFrozenAccounts(symbol string) ([][]byte, error) {
+	var accounts [][]byte
+	if _, err := m.KVGet(assetFreezesKey(strings.ToUpper(strings.TrimSpace(symbol))), &accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// AssetBalance returns the balance of symbol held by addr, reading NHB and
+// ZNHB from the account record.
+func (m *Manager) AssetBalance(addr []byte, symbol string) (*big.Int, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(symbol))
+	if !IsNativeToken(normalized) {
+		return m.Balance(addr, normalized)
+	}
+	account, err := m.GetAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+	if normalized == "NHB" {
+		return cloneBigInt(account.BalanceNHB), nil
+	}
+	return cloneBigInt(account.BalanceZNHB), nil
+}
+
+// AssetTransfer moves amount of symbol from one address to another. NHB and
+// ZNHB move between account balances; issued assets move in the balance store
+// and are refused when either side is frozen.
+func (m *Manager) AssetTransfer(from, to []byte, symbol string, amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return fmt.Errorf("asset: amount must be positive")
+	}
+	normalized, err := m.normalizeRegisteredToken(symbol)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(from, to) {
+		return nil
+	}
+	if IsNativeToken(normalized) {
+		return m.transferNativeToken(from, to, normalized, amount)
+	}
+	for _, addr := range [][]byte{from, to} {
+		frozen, err := m.AssetFrozen(normalized, addr)
+		if err != nil {
+			return err
+		}
+		if frozen {
+			return ErrAssetFrozen
+		}
+	}
+	fromBalance, err := m.Balance(from, normalized)
+	if err != nil {
+		return err
+	}
+	if fromBalance.Cmp(amount) < 0 {
+		return ErrAssetInsufficientBalance
+	}
+	toBalance, err := m.Balance(to, normalized)
+	if err != nil {
+		return err
+	}
+	if err := m.SetBalance(from, normalized, new(big.Int).Sub(fromBalance, amount)); err != nil {
+		return err
+	}
+	return m.SetBalance(to, normalized, new(big.Int).Add(toBalance, amount))
+}
+
+func (m *Manager) transferNativeToken(from, to []byte, symbol string, amount *big.Int) error {
+	fromAcc, err := m.GetAccount(from)
+	if err != nil {
+		return err
+	}
+	toAcc, err := m.GetAccount(to)
+	if err != nil {
+		return err
+	}
+	fromBalance, toBalance := &fromAcc.BalanceNHB, &toAcc.BalanceNHB
+	if symbol == "ZNHB" {
+		fromBalance, toBalance = &fromAcc.BalanceZNHB, &toAcc.BalanceZNHB
+	}
+	if cloneBigInt(*fromBalance).Cmp(amount) < 0 {
+		return ErrAssetInsufficientBalance
+	}
+	*fromBalance = new(big.Int).Sub(*fromBalance, amount)
+	*toBalance = new(big.Int).Add(cloneBigInt(*toBalance), amount)
+	if err := m.PutAccount(from, fromAcc); err != nil {
+		return err
+	}
+	return m.PutAccount(to, toAcc)
+}
+
+// MintAsset credits amount of an issued asset to addr, enforcing the supply
+// cap and the recipient's freeze. It returns the new total supply.
+func (m *Manager) MintAsset(symbol string, addr []byte, amount *big.Int) (*big.Int, error) {
+	meta, err := m.issuedAsset(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("asset: amount must be positive")
+	}
+	if frozen, err := m.AssetFrozen(meta.Symbol, addr); err != nil {
+		return nil, err
+	} else if frozen {
+		return nil, ErrAssetFrozen
+	}
+	supply, err := m.TokenSupply(meta.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	updated := new(big.Int).Add(supply, amount)
+	if meta.SupplyCap != nil && meta.SupplyCap.Sign() > 0 && updated.Cmp(meta.SupplyCap) > 0 {
+		return nil, ErrAssetSupplyCap
+	}
+	balance, err := m.Balance(addr, meta.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.SetBalance(addr, meta.Symbol, new(big.Int).Add(balance, amount)); err != nil {
+		return nil, err
+	}
+	meta.Minted = new(big.Int).Add(cloneBigInt(meta.Minted), amount)
+	if err := m.writeTokenMetadata(meta.Symbol, meta); err != nil {
+		return nil, err
+	}
+	if err := m.writeTokenSupply(meta.Symbol, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// BurnAsset destroys amount of an issued asset held by addr and returns the
+// new total supply. Frozen accounts cannot burn.
+func (m *Manager) BurnAsset(symbol string, addr []byte, amount *big.Int) (*big.Int, error) {
+	meta, err := m.issuedAsset(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("asset: amount must be positive")
+	}
+	if frozen, err := m.AssetFrozen(meta.Symbol, addr); err != nil {
+		return nil, err
+	} else if frozen {
+		return nil, ErrAssetFrozen
+	}
+	balance, err := m.Balance(addr, meta.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, ErrAssetInsufficientBalance
+	}
+	if err := m.SetBalance(addr, meta.Symbol, new(big.Int).Sub(balance, amount)); err != nil {
+		return nil, err
+	}
+	meta.Burned = new(big.Int).Add(cloneBigInt(meta.Burned), amount)
+	if err := m.writeTokenMetadata(meta.Symbol, meta); err != nil {
+		return nil, err
+	}
+	return m.AdjustTokenSupply(meta.Symbol, new(big.Int).Neg(amount))
+}
+
+// IssuedAssets returns the metadata of every issued asset in symbol order.
+func (m *Manager) IssuedAssets() ([]*TokenMetadata, error) {
+	list, err := m.loadTokenList()
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]*TokenMetadata, 0, len(list))
+	for _, symbol := range list {
+		meta, err := m.loadTokenMetadata(symbol)
+		if err != nil {
+			return nil, err
+		}
+		if meta.IsIssuedAsset() {
+			assets = append(assets, meta)
+		}
+	}
+	return assets, nil
+}
+
+func (m *Manager) issuedAsset(symbol string) (*TokenMetadata, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(symbol))
+	meta, err := m.loadTokenMetadata(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.IsIssuedAsset() {
+		return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, normalized)
+	}
+	return meta, nil
+}
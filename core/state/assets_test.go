@@ -0,0 +1,151 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func createTestAsset(t *testing.T, manager *Manager, issuer, freezer [20]byte, supplyCap int64) {
+	t.Helper()
+	meta := &TokenMetadata{
+		Symbol:          "usdx",
+		Name:            "USD Stable",
+		Decimals:        6,
+		MintAuthority:   issuer[:],
+		FreezeAuthority: freezer[:],
+		SupplyCap:       big.NewInt(supplyCap),
+		Issuer:          issuer[:],
+	}
+	if err := manager.CreateAsset(meta); err != nil {
+		t.Fatalf("create asset: %v", err)
+	}
+}
+
+func TestAssetIssuanceLifecycle(t *testing.T) {
+	manager := newTestManager(t)
+	var issuer, alice, bob [20]byte
+	issuer[19], alice[19], bob[19] = 1, 2, 3
+	createTestAsset(t, manager, issuer, issuer, 1000)
+
+	if err := manager.CreateAsset(&TokenMetadata{Symbol: "USDX", Name: "dup", MintAuthority: issuer[:], Issuer: issuer[:]}); !errors.Is(err, ErrAssetExists) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	if !manager.TokenExists("USDX") {
+		t.Fatalf("expected USDX to be registered")
+	}
+
+	total, err := manager.MintAsset("USDX", alice[:], big.NewInt(800))
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	if total.Cmp(big.NewInt(800)) != 0 {
+		t.Fatalf("unexpected supply %s", total)
+	}
+	if _, err := manager.MintAsset("USDX", alice[:], big.NewInt(201)); !errors.Is(err, ErrAssetSupplyCap) {
+		t.Fatalf("expected cap error, got %v", err)
+	}
+
+	if err := manager.AssetTransfer(alice[:], bob[:], "usdx", big.NewInt(300)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if err := manager.SetAssetFrozen("USDX", bob[:], true); err != nil {
+		t.Fatalf("freeze: %v", err)
+	}
+	if frozen, err := manager.AssetFrozenAccounts("USDX"); err != nil || len(frozen) != 1 || !bytes.Equal(frozen[0], bob[:]) {
+		t.Fatalf("frozen accounts not listed: %x (err=%v)", frozen, err)
+	}
+	if err := manager.AssetTransfer(bob[:], alice[:], "USDX", big.NewInt(1)); !errors.Is(err, ErrAssetFrozen) {
+		t.Fatalf("expected frozen sender error, got %v", err)
+	}
+	if err := manager.AssetTransfer(alice[:], bob[:], "USDX", big.NewInt(1)); !errors.Is(err, ErrAssetFrozen) {
+		t.Fatalf("expected frozen recipient error, got %v", err)
+	}
+	if _, err := manager.BurnAsset("USDX", bob[:], big.NewInt(1)); !errors.Is(err, ErrAssetFrozen) {
+		t.Fatalf("expected frozen burn error, got %v", err)
+	}
+	if err := manager.SetAssetFrozen("USDX", bob[:], false); err != nil {
+		t.Fatalf("unfreeze: %v", err)
+	}
+	if frozen, err := manager.AssetFrozenAccounts("USDX"); err != nil || len(frozen) != 0 {
+		t.Fatalf("unfrozen account still listed: %x (err=%v)", frozen, err)
+	}
+
+	total, err = manager.BurnAsset("USDX", bob[:], big.NewInt(100))
+	if err != nil {
+		t.Fatalf("burn: %v", err)
+	}
+	if total.Cmp(big.NewInt(700)) != 0 {
+		t.Fatalf("unexpected supply after burn %s", total)
+	}
+	if _, err := manager.BurnAsset("USDX", bob[:], big.NewInt(201)); !errors.Is(err, ErrAssetInsufficientBalance) {
+		t.Fatalf("expected insufficient balance, got %v", err)
+	}
+
+	aliceBal, _ := manager.AssetBalance(alice[:], "USDX")
+	bobBal, _ := manager.AssetBalance(bob[:], "USDX")
+	if aliceBal.Cmp(big.NewInt(500)) != 0 || bobBal.Cmp(big.NewInt(200)) != 0 {
+		t.Fatalf("unexpected balances alice=%s bob=%s", aliceBal, bobBal)
+	}
+
+	assets, err := manager.IssuedAssets()
+	if err != nil {
+		t.Fatalf("issued assets: %v", err)
+	}
+	if len(assets) != 1 || assets[0].Minted.Int64() != 800 || assets[0].Burned.Int64() != 100 {
+		t.Fatalf("unexpected issued asset metadata: %+v", assets)
+	}
+}
+
+func TestAssetTransferNativeToken(t *testing.T) {
+	manager := newTestManager(t)
+	var alice, bob [20]byte
+	alice[19], bob[19] = 1, 2
+	fundAccount(t, manager, alice, 100, 50)
+
+	if err := manager.AssetTransfer(alice[:], bob[:], "ZNHB", big.NewInt(20)); err != nil {
+		t.Fatalf("transfer ZNHB: %v", err)
+	}
+	if err := manager.AssetTransfer(alice[:], bob[:], "NHB", big.NewInt(101)); !errors.Is(err, ErrAssetInsufficientBalance) {
+		t.Fatalf("expected insufficient NHB, got %v", err)
+	}
+	if err := manager.AssetTransfer(alice[:], bob[:], "EURX", big.NewInt(1)); !errors.Is(err, ErrAssetNotFound) {
+		t.Fatalf("expected unregistered asset error, got %v", err)
+	}
+	bobZNHB, err := manager.AssetBalance(bob[:], "ZNHB")
+	if err != nil {
+		t.Fatalf("balance: %v", err)
+	}
+	if bobZNHB.Cmp(big.NewInt(20)) != 0 {
+		t.Fatalf("unexpected ZNHB balance %s", bobZNHB)
+	}
+}
+
+func TestClaimableIssuedAsset(t *testing.T) {
+	manager := newTestManager(t)
+	var issuer, payer, payee [20]byte
+	issuer[19], payer[19], payee[19] = 1, 2, 3
+	createTestAsset(t, manager, issuer, [20]byte{}, 0)
+	if _, err := manager.MintAsset("USDX", payer[:], big.NewInt(50)); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+
+	preimage := []byte("asset-claim")
+	var hashLock [32]byte
+	copy(hashLock[:], ethcrypto.Keccak256(preimage))
+	claim, err := manager.CreateClaimable(payer, "USDX", big.NewInt(40), hashLock, 100, [32]byte{}, "test-chain")
+	if err != nil {
+		t.Fatalf("create claimable: %v", err)
+	}
+	if _, _, err := manager.ClaimableClaim(claim.ID, preimage, payee); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	payeeBal, _ := manager.AssetBalance(payee[:], "USDX")
+	payerBal, _ := manager.AssetBalance(payer[:], "USDX")
+	if payeeBal.Int64() != 40 || payerBal.Int64() != 10 {
+		t.Fatalf("unexpected balances payee=%s payer=%s", payeeBal, payerBal)
+	}
+}
@@ -74,7 +74,7 @@ func (s *storedClaimable) toClaimable() (*claimable.Claimable, error) {
 	if s == nil {
 		return nil, fmt.Errorf("claimable: nil storage record")
 	}
-	normalized, err := escrow.NormalizeToken(s.Token)
+	normalized, err := escrow.NormalizeAsset(s.Token)
 	if err != nil {
 		return nil, claimable.ErrInvalidToken
 	}
@@ -170,7 +170,7 @@ func (m *Manager) ClaimablePut(c *claimable.Claimable) error {
 	if !c.Status.Valid() {
 		return fmt.Errorf("claimable: invalid status")
 	}
-	normalized, err := escrow.NormalizeToken(c.Token)
+	normalized, err := m.normalizeRegisteredToken(c.Token)
 	if err != nil {
 		return claimable.ErrInvalidToken
 	}
@@ -215,7 +215,7 @@ func (m *Manager) ClaimableCredit(payer [20]byte, token string, amt *big.Int) er
 	if amt == nil || amt.Sign() <= 0 {
 		return claimable.ErrInvalidAmount
 	}
-	normalized, err := escrow.NormalizeToken(token)
+	normalized, err := m.normalizeRegisteredToken(token)
 	if err != nil {
 		return claimable.ErrInvalidToken
 	}
@@ -276,7 +276,7 @@ func (m *Manager) ClaimableCredit(payer [20]byte, token string, amt *big.Int) er
 		}
 		rollbacks = append(rollbacks, rollback)
 	default:
-		return claimable.ErrInvalidToken
+		return claimableAssetTransfer(m, payer[:], vault[:], normalized, amt)
 	}
 	if err := m.PutAccount(payer[:], payerAcc); err != nil {
 		revert()
@@ -299,7 +299,7 @@ func (m *Manager) ClaimableDebit(token string, amt *big.Int, recipient [20]byte)
 	if amt == nil || amt.Sign() <= 0 {
 		return claimable.ErrInvalidAmount
 	}
-	normalized, err := escrow.NormalizeToken(token)
+	normalized, err := m.normalizeRegisteredToken(token)
 	if err != nil {
 		return claimable.ErrInvalidToken
 	}
@@ -360,7 +360,7 @@ func (m *Manager) ClaimableDebit(token string, amt *big.Int, recipient [20]byte)
 		}
 		rollbacks = append(rollbacks, rollback)
 	default:
-		return claimable.ErrInvalidToken
+		return claimableAssetTransfer(m, vault[:], recipient[:], normalized, amt)
 	}
 	if err := m.PutAccount(vault[:], vaultAcc); err != nil {
 		revert()
@@ -379,11 +379,21 @@ func (m *Manager) ClaimableDebit(token string, amt *big.Int, recipient [20]byte)
 	return nil
 }
 
+// claimableAssetTransfer moves an issued asset into or out of the claimable
+// vault, mapping a short balance to the claimable error callers expect.
+func claimableAssetTransfer(m *Manager, from, to []byte, symbol string, amt *big.Int) error {
+	err := m.AssetTransfer(from, to, symbol, amt)
+	if errors.Is(err, ErrAssetInsufficientBalance) {
+		return claimable.ErrInsufficientFunds
+	}
+	return err
+}
+
 func (m *Manager) CreateClaimable(payer [20]byte, token string, amount *big.Int, hashLock [32]byte, deadline int64, hint [32]byte, chainID string) (*claimable.Claimable, error) {
 	if amount == nil || amount.Sign() <= 0 {
 		return nil, claimable.ErrInvalidAmount
 	}
-	normalized, err := escrow.NormalizeToken(token)
+	normalized, err := m.normalizeRegisteredToken(token)
 	if err != nil {
 		return nil, claimable.ErrInvalidToken
 	}
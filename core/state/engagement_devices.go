@@ -0,0 +1,182 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	engagementDevicePrefix      = []byte("engagement/device/")
+	engagementDeviceOwnerPrefix = []byte("engagement/device-owner/")
+
+	// ErrEngagementDeviceNotFound is returned for device identifiers that are
+	// not registered.
+	ErrEngagementDeviceNotFound = errors.New("engagement: device not found")
+	// ErrEngagementDeviceLimit is returned when registering a device would
+	// take its owner past the configured device limit.
+	ErrEngagementDeviceLimit = errors.New("engagement: device limit reached")
+)
+
+// EngagementDevice is a heartbeat device registered on chain. Only the
+// SHA-256 digest of the device's bearer token is stored.
+type EngagementDevice struct {
+	DeviceID      string
+	Owner         [20]byte
+	TokenHash     [32]byte
+	RegisteredAt  uint64
+	LastHeartbeat uint64
+}
+
+func engagementDeviceKey(deviceID string) []byte {
+	buf := make([]byte, len(engagementDevicePrefix)+len(deviceID))
+	copy(buf, engagementDevicePrefix)
+	copy(buf[len(engagementDevicePrefix):], deviceID)
+	return buf
+}
+
+func engagementDeviceOwnerKey(owner [20]byte) []byte {
+	buf := make([]byte, len(engagementDeviceOwnerPrefix)+len(owner))
+	copy(buf, engagementDeviceOwnerPrefix)
+	copy(buf[len(engagementDeviceOwnerPrefix):], owner[:])
+	return buf
+}
+
+// EngagementDeviceGet returns the device registered under deviceID.
+func (m *Manager) EngagementDeviceGet(deviceID string) (*EngagementDevice, bool, error) {
+	deviceID = strings.TrimSpace(deviceID)
+	if deviceID == "" {
+		return nil, false, nil
+	}
+	var stored EngagementDevice
+	ok, err := m.KVGet(engagementDeviceKey(deviceID), &stored)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &stored, true, nil
+}
+
+// EngagementDevicePut creates or updates a device and indexes it under its
+// owner. A new device is rejected once the owner holds limit devices; a limit
+// of zero disables the check.
+func (m *Manager) EngagementDevicePut(device *EngagementDevice, limit uint64) error {
+	if device == nil || strings.TrimSpace(device.DeviceID) == "" {
+		return fmt.Errorf("engagement: device id required")
+	}
+	existing, found, err := m.EngagementDeviceGet(device.DeviceID)
+	if err != nil {
+		return err
+	}
+	if found && existing.Owner != device.Owner {
+		return fmt.Errorf("engagement: device registered to another address")
+	}
+	if !found {
+		ids, err := m.engagementDeviceIndex(device.Owner)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && uint64(len(ids)) >= limit {
+			return fmt.Errorf("%w: %d devices", ErrEngagementDeviceLimit, limit)
+		}
+		if err := m.KVPut(engagementDeviceOwnerKey(device.Owner), append(ids, device.DeviceID)); err != nil {
+			return err
+		}
+	}
+	return m.KVPut(engagementDeviceKey(device.DeviceID), device)
+}
+
+// EngagementDeviceDelete removes a device and its index entry, freeing the
+// owner's slot.
+func (m *Manager) EngagementDeviceDelete(deviceID string) error {
+	existing, found, err := m.EngagementDeviceGet(deviceID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrEngagementDeviceNotFound
+	}
+	ids, err := m.engagementDeviceIndex(existing.Owner)
+	if err != nil {
+		return err
+	}
+	remaining := ids[:0]
+	for _, id := range ids {
+		if id != existing.DeviceID {
+			remaining = append(remaining, id)
+		}
+	}
+	if len(remaining) == 0 {
+		if err := m.KVDelete(engagementDeviceOwnerKey(existing.Owner)); err != nil {
+			return err
+		}
+	} else if err := m.KVPut(engagementDeviceOwnerKey(existing.Owner), remaining); err != nil {
+		return err
+	}
+	return m.KVDelete(engagementDeviceKey(existing.DeviceID))
+}
+
+// EngagementDevicesByOwner returns the devices of owner in registration
+// order.
+func (m *Manager) EngagementDevicesByOwner(owner [20]byte) ([]*EngagementDevice, error) {
+	ids, err := m.engagementDeviceIndex(owner)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*EngagementDevice, 0, len(ids))
+	for _, id := range ids {
+		device, ok, err := m.EngagementDeviceGet(id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, device)
+		}
+	}
+	return out, nil
+}
+
+func (m *Manager) engagementDeviceIndex(owner [20]byte) ([]string, error) {
+	var ids []string
+	if _, err := m.KVGet(engagementDeviceOwnerKey(owner), &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// EngagementDevices returns every registered device grouped by owner in
+// registration order. Devices are only indexed per owner, so the call walks
+// the entire trie and is intended for offline tooling such as genesis export.
+func (m *Manager) EngagementDevices() ([]*EngagementDevice, error) {
+	owners := make(map[[20]byte]struct{})
+	err := m.trie.ForEach(func(key, value []byte) error {
+		stored := new(EngagementDevice)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if stored.DeviceID != "" && bytes.Equal(key, kvKey(engagementDeviceKey(stored.DeviceID))) {
+			owners[stored.Owner] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([][20]byte, 0, len(owners))
+	for owner := range owners {
+		ordered = append(ordered, owner)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return bytes.Compare(ordered[i][:], ordered[j][:]) < 0 })
+	var out []*EngagementDevice
+	for _, owner := range ordered {
+		devices, err := m.EngagementDevicesByOwner(owner)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, devices...)
+	}
+	return out, nil
+}
@@ -0,0 +1,495 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/identity"
+)
+
+var (
+	identityListingPrefix = []byte("identity/listing/")
+	identitySaleNonceKey  = []byte("identity/listing-nonce")
+)
+
+type storedAliasListing struct {
+	Alias    string
+	Seller   [20]byte
+	Buyer    [20]byte
+	Price    *big.Int
+	Nonce    uint64
+	ListedAt uint64
+}
+
+func identityListingKey(alias string) []byte {
+	buf := make([]byte, len(identityListingPrefix)+len(alias))
+	copy(buf, identityListingPrefix)
+	copy(buf[len(identityListingPrefix):], alias)
+	return buf
+}
+
+// IdentityClaimAlias registers alias for addr under the supplied registration
+// terms. An alias whose grace period has ended is cleared first so a new
+// owner can claim it, and registrations by addresses without an alias receive
+// an expiry when terms are enabled.
+func (m *Manager) IdentityClaimAlias(addr []byte, alias string, terms identity.Terms, now int64) (*identity.AliasRecord, error) {
+	normalized, err := identity.NormalizeAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+	if len(addr) != 20 {
+		return nil, fmt.Errorf("identity: address must be 20 bytes")
+	}
+	var address [20]byte
+	copy(address[:], addr)
+	existing, exists, err := m.identityGetAlias(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if exists && !aliasRecordOwner(existing, address) && terms.Status(existing, now) == identity.AliasStatusReleased {
+		if err := m.IdentityRelease(normalized); err != nil {
+			return nil, err
+		}
+		exists = false
+	}
+	if !exists {
+		if err := m.IdentityCheckSubAliasNamespace(normalized, terms, now); err != nil {
+			return nil, err
+		}
+	}
+	// Renaming keeps the record, including its expiry, so only addresses
+	// without an alias start a new term.
+	_, hadAlias := m.IdentityReverse(addr)
+	if err := m.IdentitySetAlias(addr, normalized); err != nil {
+		return nil, err
+	}
+	record, ok, err := m.identityGetAlias(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || record == nil {
+		return nil, fmt.Errorf("identity: failed to persist alias")
+	}
+	if !exists && !hadAlias && terms.Enabled() {
+		record.ExpiresAt = terms.ExpiryFrom(now)
+		if err := m.identityPersistRecord(record, record.Alias, copyAliasAddresses(record.Addresses)); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+// IdentityResolveAt resolves an alias like IdentityResolve but treats aliases
+// whose grace period has ended as unregistered. Names that are not flat
+// aliases are resolved as sub-aliases of their parent.
+func (m *Manager) IdentityResolveAt(alias string, terms identity.Terms, now int64) (*identity.AliasRecord, bool) {
+	record, ok := m.IdentityResolve(alias)
+	if !ok {
+		normalized, err := identity.NormalizeAlias(alias)
+		if err != nil {
+			return nil, false
+		}
+		return m.identityResolveSubAlias(normalized, terms, now)
+	}
+	if terms.Status(record, now) == identity.AliasStatusReleased {
+		return nil, false
+	}
+	return record, true
+}
+
+// IdentityRenew extends the alias registration by one term from the later of
+// its current expiry and now. Fee collection is left to the caller.
+func (m *Manager) IdentityRenew(alias string, terms identity.Terms, now int64) (*identity.AliasRecord, error) {
+	normalized, err := identity.NormalizeAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+	record, ok, err := m.identityGetAlias(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || record == nil {
+		return nil, identity.ErrAliasNotFound
+	}
+	if !terms.Enabled() || record.ExpiresAt == 0 {
+		return nil, identity.ErrTermsDisabled
+	}
+	if terms.Status(record, now) == identity.AliasStatusReleased {
+		return nil, identity.ErrAliasExpired
+	}
+	start := record.ExpiresAt
+	if now > start {
+		start = now
+	}
+	record.ExpiresAt = terms.ExpiryFrom(start)
+	record.UpdatedAt = now
+	if err := m.identityPersistRecord(record, normalized, copyAliasAddresses(record.Addresses)); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// IdentitySetTextRecords replaces the text records stored on the alias. The
+// records must already be validated with identity.ApplyTextRecords.
+func (m *Manager) IdentitySetTextRecords(alias string, records []identity.TextRecord, now int64) (*identity.AliasRecord, error) {
+	normalized, err := identity.NormalizeAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+	record, ok, err := m.identityGetAlias(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || record == nil {
+		return nil, identity.ErrAliasNotFound
+	}
+	record.Records = append([]identity.TextRecord(nil), records...)
+	record.UpdatedAt = now
+	if err := m.identityPersistRecord(record, normalized, copyAliasAddresses(record.Addresses)); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// IdentityTransfer hands the alias to newOwner, who becomes its owner and only
+// linked address. The registration term and avatar carry over. Text records
+// describe the previous owner's payment setup, so they are cleared along with
+// any sale listing.
+func (m *Manager) IdentityTransfer(alias string, newOwner [20]byte, now int64) (*identity.AliasRecord, error) {
+	normalized, err := identity.NormalizeAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+	if newOwner == ([20]byte{}) {
+		return nil, fmt.Errorf("%w: must not be zero", identity.ErrInvalidAddress)
+	}
+	record, ok, err := m.identityGetAlias(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || record == nil {
+		return nil, identity.ErrAliasNotFound
+	}
+	if linked, ok := m.IdentityReverse(newOwner[:]); ok && linked != normalized {
+		return nil, identity.ErrAddressLinked
+	}
+	previousAddresses := copyAliasAddresses(record.Addresses)
+	record.Owner = newOwner
+	record.Primary = newOwner
+	record.Addresses = [][20]byte{newOwner}
+	record.Records = nil
+	if now == 0 {
+		now = time.Now().Unix()
+	}
+	record.UpdatedAt = now
+	if err := m.identityPersistRecord(record, normalized, previousAddresses); err != nil {
+		return nil, err
+	}
+	if err := m.IdentityDeleteListing(normalized); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// IdentityReassignOwner replaces from with to in every alias record from
+// owns or is linked to: owner, primary and linked addresses, and the reverse
+// mapping. Sub-aliases below those aliases that point at from are retargeted
+// and a sale listing by from is handed to to. Unlike IdentityTransfer,
+// records and other linked addresses stay in place. It returns the alias from
+// was linked to, or "" when it had none.
+func (m *Manager) IdentityReassignOwner(from, to [20]byte, now int64) (string, error) {
+	linked, hasLinked := m.IdentityReverse(from[:])
+	if hasLinked {
+		if current, ok := m.IdentityReverse(to[:]); ok && current != linked {
+			return "", identity.ErrAddressLinked
+		}
+	}
+	records, err := m.IdentityAliases()
+	if err != nil {
+		return "", err
+	}
+	for _, record := range records {
+		if record.Owner != from && record.Primary != from && !containsAliasAddress(record.Addresses, from) {
+			continue
+		}
+		previous := copyAliasAddresses(record.Addresses)
+		if record.Owner == from {
+			record.Owner = to
+		}
+		if record.Primary == from {
+			record.Primary = to
+		}
+		addresses := make([][20]byte, 0, len(record.Addresses))
+		for _, addr := range record.Addresses {
+			if addr == from {
+				addr = to
+			}
+			if !containsAliasAddress(addresses, addr) {
+				addresses = append(addresses, addr)
+			}
+		}
+		record.Addresses = addresses
+		record.UpdatedAt = now
+		if err := m.identityPersistRecord(record, record.Alias, previous); err != nil {
+			return "", err
+		}
+		if err := m.identityRetargetSubAliases(record.Alias, from, to, now); err != nil {
+			return "", err
+		}
+		if err := m.identityReassignListing(record.Alias, from, to); err != nil {
+			return "", err
+		}
+	}
+	if !hasLinked {
+		return "", nil
+	}
+	return linked, nil
+}
+
+// IdentityAliases returns every stored alias record in alias order. It scans
+// the state trie and is meant for recovery, export and audits rather than
+// per-transaction lookups.
+func (m *Manager) IdentityAliases() ([]*identity.AliasRecord, error) {
+	var records []*identity.AliasRecord
+	err := m.trie.ForEach(func(key, value []byte) error {
+		stored := new(storedAliasRecord)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if stored.Alias == "" || !bytes.Equal(key, identityAliasKey(stored.Alias)) {
+			return nil
+		}
+		record, err := stored.toAliasRecord()
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Alias < records[j].Alias })
+	return records, nil
+}
+
+// IdentityRestoreAlias writes an exported alias record and links every one of
+// its addresses back to it. It is meant for genesis import.
+func (m *Manager) IdentityRestoreAlias(record *identity.AliasRecord) error {
+	if record == nil {
+		return fmt.Errorf("identity: nil record")
+	}
+	normalized, err := identity.NormalizeAlias(record.Alias)
+	if err != nil {
+		return err
+	}
+	restored := *record
+	restored.Alias = normalized
+	restored.Addresses = copyAliasAddresses(record.Addresses)
+	restored.Records = append([]identity.TextRecord(nil), record.Records...)
+	return m.identityPersistRecord(&restored, "", nil)
+}
+
+// IdentityListingNonce returns the nonce assigned to the most recent sale
+// listing.
+func (m *Manager) IdentityListingNonce() (uint64, error) {
+	var nonce uint64
+	if _, err := m.KVGet(identitySaleNonceKey, &nonce); err != nil {
+		return 0, err
+	}
+	return nonce, nil
+}
+
+// IdentityRestoreListing writes an exported sale listing with its original
+// nonce and raises the listing nonce to match. It is meant for genesis import.
+func (m *Manager) IdentityRestoreListing(listing *identity.Listing) error {
+	if listing == nil {
+		return fmt.Errorf("identity: nil listing")
+	}
+	normalized, err := identity.NormalizeAlias(listing.Alias)
+	if err != nil {
+		return err
+	}
+	nonce, err := m.IdentityListingNonce()
+	if err != nil {
+		return err
+	}
+	if listing.Nonce > nonce {
+		if err := m.KVPut(identitySaleNonceKey, listing.Nonce); err != nil {
+			return err
+		}
+	}
+	price := listing.Price
+	if price == nil {
+		price = big.NewInt(0)
+	}
+	return m.KVPut(identityListingKey(normalized), &storedAliasListing{
+		Alias:    normalized,
+		Seller:   listing.Seller,
+		Buyer:    listing.Buyer,
+		Price:    new(big.Int).Set(price),
+		Nonce:    listing.Nonce,
+		ListedAt: uint64(listing.ListedAt),
+	})
+}
+
+// IdentitySetListingNonce overwrites the sale listing nonce. It is meant for
+// genesis import.
+func (m *Manager) IdentitySetListingNonce(nonce uint64) error {
+	return m.KVPut(identitySaleNonceKey, nonce)
+}
+
+func (m *Manager) identityRetargetSubAliases(parent string, from, to [20]byte, now int64) error {
+	subs, err := m.IdentitySubAliases(parent)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if sub.Target != from {
+			continue
+		}
+		sub.Target = to
+		sub.UpdatedAt = now
+		if err := m.IdentityPutSubAlias(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// identityReassignListing hands a listing by from to to. A listing reserved
+// for to is withdrawn because to cannot buy from itself.
+func (m *Manager) identityReassignListing(alias string, from, to [20]byte) error {
+	listing, ok, err := m.IdentityGetListing(alias)
+	if err != nil || !ok || listing.Seller != from {
+		return err
+	}
+	if listing.Buyer == to {
+		return m.IdentityDeleteListing(alias)
+	}
+	listing.Seller = to
+	_, err = m.IdentityPutListing(listing)
+	return err
+}
+
+// IdentityRelease removes an alias together with its reverse mappings,
+// sub-aliases and any sale listing.
+func (m *Manager) IdentityRelease(alias string) error {
+	normalized, err := identity.NormalizeAlias(alias)
+	if err != nil {
+		return err
+	}
+	record, ok, err := m.identityGetAlias(normalized)
+	if err != nil {
+		return err
+	}
+	if !ok || record == nil {
+		return identity.ErrAliasNotFound
+	}
+	for _, addr := range record.Addresses {
+		if current, ok := m.IdentityReverse(addr[:]); ok && current == normalized {
+			if err := m.trie.Update(identityReverseKey(addr[:]), nil); err != nil {
+				return err
+			}
+		}
+	}
+	if err := m.trie.Update(identityAliasKey(normalized), nil); err != nil {
+		return err
+	}
+	if err := m.trie.Update(identityAliasIDKey(identity.DeriveAliasID(normalized)), nil); err != nil {
+		return err
+	}
+	if err := m.identityDeleteSubAliases(normalized); err != nil {
+		return err
+	}
+	return m.IdentityDeleteListing(normalized)
+}
+
+// IdentityPutListing stores a sale listing, assigning it the next listing
+// nonce. The nonce keeps settlement escrow identifiers unique when an alias is
+// listed more than once.
+func (m *Manager) IdentityPutListing(listing *identity.Listing) (*identity.Listing, error) {
+	if listing == nil {
+		return nil, fmt.Errorf("identity: nil listing")
+	}
+	normalized, err := identity.NormalizeAlias(listing.Alias)
+	if err != nil {
+		return nil, err
+	}
+	var nonce uint64
+	if _, err := m.KVGet(identitySaleNonceKey, &nonce); err != nil {
+		return nil, err
+	}
+	nonce++
+	if err := m.KVPut(identitySaleNonceKey, nonce); err != nil {
+		return nil, err
+	}
+	stored := listing.Clone()
+	stored.Alias = normalized
+	stored.Nonce = nonce
+	if stored.Price == nil {
+		stored.Price = big.NewInt(0)
+	}
+	record := &storedAliasListing{
+		Alias:    stored.Alias,
+		Seller:   stored.Seller,
+		Buyer:    stored.Buyer,
+		Price:    stored.Price,
+		Nonce:    stored.Nonce,
+		ListedAt: uint64(stored.ListedAt),
+	}
+	if err := m.KVPut(identityListingKey(normalized), record); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// IdentityGetListing returns the sale listing for the alias, if any.
+func (m *Manager) IdentityGetListing(alias string) (*identity.Listing, bool, error) {
+	normalized, err := identity.NormalizeAlias(alias)
+	if err != nil {
+		return nil, false, err
+	}
+	var stored storedAliasListing
+	ok, err := m.KVGet(identityListingKey(normalized), &stored)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	listing := &identity.Listing{
+		Alias:    stored.Alias,
+		Seller:   stored.Seller,
+		Buyer:    stored.Buyer,
+		Price:    stored.Price,
+		Nonce:    stored.Nonce,
+		ListedAt: int64(stored.ListedAt),
+	}
+	if listing.Price == nil {
+		listing.Price = big.NewInt(0)
+	}
+	return listing, true, nil
+}
+
+// IdentityDeleteListing removes the sale listing for the alias.
+func (m *Manager) IdentityDeleteListing(alias string) error {
+	normalized, err := identity.NormalizeAlias(alias)
+	if err != nil {
+		return err
+	}
+	return m.KVDelete(identityListingKey(normalized))
+}
+
+func aliasRecordOwner(record *identity.AliasRecord, addr [20]byte) bool {
+	if record == nil {
+		return false
+	}
+	owner := record.Owner
+	if owner == ([20]byte{}) {
+		owner = record.Primary
+	}
+	return owner == addr
+}
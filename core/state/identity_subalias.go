@@ -0,0 +1,282 @@
+package state
+
+import (
+	"fmt"
+
+	"nhbchain/core/identity"
+)
+
+var (
+	identitySubAliasPrefix      = []byte("identity/subalias/")
+	identitySubAliasIndexPrefix = []byte("identity/subalias-index/")
+)
+
+type storedSubAlias struct {
+	Label     string
+	Parent    string
+	Target    [20]byte
+	CreatedAt uint64
+	UpdatedAt uint64
+}
+
+func identitySubAliasKey(name string) []byte {
+	buf := make([]byte, len(identitySubAliasPrefix)+len(name))
+	copy(buf, identitySubAliasPrefix)
+	copy(buf[len(identitySubAliasPrefix):], name)
+	return buf
+}
+
+func identitySubAliasIndexKey(parent string) []byte {
+	buf := make([]byte, len(identitySubAliasIndexPrefix)+len(parent))
+	copy(buf, identitySubAliasIndexPrefix)
+	copy(buf[len(identitySubAliasIndexPrefix):], parent)
+	return buf
+}
+
+// IdentityGetSubAlias returns the sub-alias stored under its full name.
+func (m *Manager) IdentityGetSubAlias(name string) (*identity.SubAlias, bool, error) {
+	normalized, err := identity.NormalizeAlias(name)
+	if err != nil {
+		return nil, false, err
+	}
+	var stored storedSubAlias
+	ok, err := m.KVGet(identitySubAliasKey(normalized), &stored)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &identity.SubAlias{
+		Label:     stored.Label,
+		Parent:    stored.Parent,
+		Target:    stored.Target,
+		CreatedAt: int64(stored.CreatedAt),
+		UpdatedAt: int64(stored.UpdatedAt),
+	}, true, nil
+}
+
+// IdentitySubAliases returns the sub-aliases delegated by parent in creation
+// order.
+func (m *Manager) IdentitySubAliases(parent string) ([]*identity.SubAlias, error) {
+	normalized, err := identity.NormalizeAlias(parent)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := m.identitySubAliasLabels(normalized)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*identity.SubAlias, 0, len(labels))
+	for _, label := range labels {
+		sub, ok, err := m.IdentityGetSubAlias(label + "." + normalized)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, sub)
+		}
+	}
+	return out, nil
+}
+
+// IdentityPutSubAlias creates or updates a sub-alias. Callers authorise the
+// parent owner; this only enforces naming, uniqueness against flat aliases
+// and the per-parent limit.
+func (m *Manager) IdentityPutSubAlias(sub *identity.SubAlias) error {
+	if sub == nil {
+		return fmt.Errorf("identity: nil sub-alias")
+	}
+	label, err := identity.NormalizeSubAliasLabel(sub.Label)
+	if err != nil {
+		return err
+	}
+	parent, err := identity.NormalizeAlias(sub.Parent)
+	if err != nil {
+		return err
+	}
+	name, err := identity.NormalizeAlias(label + "." + parent)
+	if err != nil {
+		return err
+	}
+	if sub.Target == ([20]byte{}) {
+		return fmt.Errorf("%w: target must not be zero", identity.ErrInvalidAddress)
+	}
+	if _, exists, err := m.identityGetAlias(name); err != nil {
+		return err
+	} else if exists {
+		return identity.ErrAliasTaken
+	}
+	var existing storedSubAlias
+	found, err := m.KVGet(identitySubAliasKey(name), &existing)
+	if err != nil {
+		return err
+	}
+	if !found {
+		labels, err := m.identitySubAliasLabels(parent)
+		if err != nil {
+			return err
+		}
+		if len(labels) >= identity.MaxSubAliasesPerParent {
+			return identity.ErrSubAliasLimit
+		}
+		if err := m.KVPut(identitySubAliasIndexKey(parent), append(labels, label)); err != nil {
+			return err
+		}
+	}
+	return m.KVPut(identitySubAliasKey(name), &storedSubAlias{
+		Label:     label,
+		Parent:    parent,
+		Target:    sub.Target,
+		CreatedAt: uint64(sub.CreatedAt),
+		UpdatedAt: uint64(sub.UpdatedAt),
+	})
+}
+
+// IdentityDeleteSubAlias removes a sub-alias and its index entry.
+func (m *Manager) IdentityDeleteSubAlias(name string) error {
+	sub, ok, err := m.IdentityGetSubAlias(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return identity.ErrSubAliasNotFound
+	}
+	labels, err := m.identitySubAliasLabels(sub.Parent)
+	if err != nil {
+		return err
+	}
+	remaining := labels[:0]
+	for _, label := range labels {
+		if label != sub.Label {
+			remaining = append(remaining, label)
+		}
+	}
+	if err := m.putSubAliasLabels(sub.Parent, remaining); err != nil {
+		return err
+	}
+	return m.KVDelete(identitySubAliasKey(sub.Name()))
+}
+
+// identityResolveSubAlias derives an alias record for a sub-alias name from
+// its parent. The record carries the parent's owner and expiry and points at
+// the sub-alias target.
+func (m *Manager) identityResolveSubAlias(name string, terms identity.Terms, now int64) (*identity.AliasRecord, bool) {
+	_, parentName, ok := identity.SplitSubAlias(name)
+	if !ok {
+		return nil, false
+	}
+	parent, ok := m.IdentityResolveAt(parentName, terms, now)
+	if !ok || parent == nil || parent.Parent != "" {
+		return nil, false
+	}
+	sub, ok, err := m.IdentityGetSubAlias(name)
+	if err != nil || !ok {
+		return nil, false
+	}
+	owner := parent.Owner
+	if owner == ([20]byte{}) {
+		owner = parent.Primary
+	}
+	return &identity.AliasRecord{
+		Alias:     sub.Name(),
+		Owner:     owner,
+		Primary:   sub.Target,
+		Addresses: [][20]byte{sub.Target},
+		CreatedAt: sub.CreatedAt,
+		UpdatedAt: sub.UpdatedAt,
+		ExpiresAt: parent.ExpiresAt,
+		Parent:    parent.Alias,
+	}, true
+}
+
+// IdentityCheckSubAliasNamespace rejects flat registrations of names such as
+// "cashier1.acme" while "acme" is registered, so only the owner of "acme" can
+// hand out names below it.
+func (m *Manager) IdentityCheckSubAliasNamespace(name string, terms identity.Terms, now int64) error {
+	_, parentName, ok := identity.SplitSubAlias(name)
+	if !ok {
+		return nil
+	}
+	parent, exists, err := m.identityGetAlias(parentName)
+	if err != nil {
+		return err
+	}
+	if exists && terms.Status(parent, now) != identity.AliasStatusReleased {
+		return identity.ErrSubAliasNamespace
+	}
+	return nil
+}
+
+// identityMoveSubAliases re-keys every sub-alias of oldParent under
+// newParent when the parent alias is renamed.
+func (m *Manager) identityMoveSubAliases(oldParent, newParent string) error {
+	if oldParent == "" || oldParent == newParent {
+		return nil
+	}
+	labels, err := m.identitySubAliasLabels(oldParent)
+	if err != nil || len(labels) == 0 {
+		return err
+	}
+	moved := make([]string, 0, len(labels))
+	for _, label := range labels {
+		var stored storedSubAlias
+		ok, err := m.KVGet(identitySubAliasKey(label+"."+oldParent), &stored)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := m.KVDelete(identitySubAliasKey(label + "." + oldParent)); err != nil {
+			return err
+		}
+		// Names that would no longer be valid under the new parent, for
+		// example because they become too long, are dropped.
+		name, err := identity.NormalizeAlias(label + "." + newParent)
+		if err != nil {
+			continue
+		}
+		if _, exists, err := m.identityGetAlias(name); err != nil {
+			return err
+		} else if exists {
+			continue
+		}
+		stored.Parent = newParent
+		if err := m.KVPut(identitySubAliasKey(name), &stored); err != nil {
+			return err
+		}
+		moved = append(moved, label)
+	}
+	if err := m.putSubAliasLabels(oldParent, nil); err != nil {
+		return err
+	}
+	return m.putSubAliasLabels(newParent, moved)
+}
+
+// identityDeleteSubAliases removes every sub-alias of parent. It runs when the
+// parent registration is released.
+func (m *Manager) identityDeleteSubAliases(parent string) error {
+	labels, err := m.identitySubAliasLabels(parent)
+	if err != nil {
+		return err
+	}
+	for _, label := range labels {
+		if err := m.KVDelete(identitySubAliasKey(label + "." + parent)); err != nil {
+			return err
+		}
+	}
+	return m.putSubAliasLabels(parent, nil)
+}
+
+func (m *Manager) identitySubAliasLabels(parent string) ([]string, error) {
+	var labels []string
+	if _, err := m.KVGet(identitySubAliasIndexKey(parent), &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (m *Manager) putSubAliasLabels(parent string, labels []string) error {
+	if len(labels) == 0 {
+		return m.KVDelete(identitySubAliasIndexKey(parent))
+	}
+	return m.KVPut(identitySubAliasIndexKey(parent), labels)
+}
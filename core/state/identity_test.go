@@ -2,7 +2,9 @@ package state
 
 import (
 	"errors"
+	"math/big"
 	"testing"
+	"time"
 
 	"nhbchain/core/identity"
 	"nhbchain/storage"
@@ -321,3 +323,198 @@ func TestIdentityRenamePreservesMetadata(t *testing.T) {
 		t.Fatalf("expected ErrAliasTaken when renaming to existing alias, got %v", err)
 	}
 }
+
+func TestIdentityClaimAliasExpiryAndRelease(t *testing.T) {
+	manager := newTestManager(t)
+	terms := identity.Terms{Term: 100 * time.Second, GracePeriod: 50 * time.Second}
+	var owner, other [20]byte
+	owner[19] = 1
+	other[19] = 2
+
+	record, err := manager.IdentityClaimAlias(owner[:], "expiring", terms, 1000)
+	if err != nil {
+		t.Fatalf("claim alias: %v", err)
+	}
+	if record.ExpiresAt != 1100 {
+		t.Fatalf("unexpected expiry: got %d want 1100", record.ExpiresAt)
+	}
+	if _, err := manager.IdentityClaimAlias(other[:], "expiring", terms, 1120); !errors.Is(err, identity.ErrAliasTaken) {
+		t.Fatalf("expected alias held during grace, got %v", err)
+	}
+	if _, ok := manager.IdentityResolveAt("expiring", terms, 1120); !ok {
+		t.Fatalf("expected alias to resolve during grace")
+	}
+	if _, ok := manager.IdentityResolveAt("expiring", terms, 1150); ok {
+		t.Fatalf("expected released alias not to resolve")
+	}
+
+	claimed, err := manager.IdentityClaimAlias(other[:], "expiring", terms, 1150)
+	if err != nil {
+		t.Fatalf("claim released alias: %v", err)
+	}
+	if claimed.Primary != other || claimed.ExpiresAt != 1250 {
+		t.Fatalf("unexpected claimed record: primary=%x expires=%d", claimed.Primary, claimed.ExpiresAt)
+	}
+	if _, ok := manager.IdentityReverse(owner[:]); ok {
+		t.Fatalf("expected previous owner reverse mapping to be cleared")
+	}
+}
+
+func TestIdentityClaimAliasWithoutTermsNeverExpires(t *testing.T) {
+	manager := newTestManager(t)
+	var owner [20]byte
+	owner[19] = 1
+
+	record, err := manager.IdentityClaimAlias(owner[:], "forever", identity.Terms{}, 1000)
+	if err != nil {
+		t.Fatalf("claim alias: %v", err)
+	}
+	if record.ExpiresAt != 0 {
+		t.Fatalf("expected no expiry, got %d", record.ExpiresAt)
+	}
+	if _, err := manager.IdentityRenew("forever", identity.Terms{Term: time.Hour}, 2000); !errors.Is(err, identity.ErrTermsDisabled) {
+		t.Fatalf("expected renewal of perpetual alias to fail, got %v", err)
+	}
+}
+
+func TestIdentityRenewExtendsTerm(t *testing.T) {
+	manager := newTestManager(t)
+	terms := identity.Terms{Term: 100 * time.Second, GracePeriod: 50 * time.Second}
+	var owner [20]byte
+	owner[19] = 1
+	if _, err := manager.IdentityClaimAlias(owner[:], "renewer", terms, 1000); err != nil {
+		t.Fatalf("claim alias: %v", err)
+	}
+
+	renewed, err := manager.IdentityRenew("renewer", terms, 1050)
+	if err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if renewed.ExpiresAt != 1200 {
+		t.Fatalf("renewal within term should extend from expiry, got %d", renewed.ExpiresAt)
+	}
+	renewed, err = manager.IdentityRenew("renewer", terms, 1230)
+	if err != nil {
+		t.Fatalf("renew during grace: %v", err)
+	}
+	if renewed.ExpiresAt != 1330 {
+		t.Fatalf("renewal during grace should extend from now, got %d", renewed.ExpiresAt)
+	}
+	if _, err := manager.IdentityRenew("renewer", terms, 1400); !errors.Is(err, identity.ErrAliasExpired) {
+		t.Fatalf("expected released alias renewal to fail, got %v", err)
+	}
+}
+
+func TestIdentityListingAndTransfer(t *testing.T) {
+	manager := newTestManager(t)
+	var seller, buyer [20]byte
+	seller[19] = 1
+	buyer[19] = 2
+	if err := manager.IdentitySetAlias(seller[:], "forsale"); err != nil {
+		t.Fatalf("set alias: %v", err)
+	}
+
+	first, err := manager.IdentityPutListing(&identity.Listing{Alias: "ForSale", Seller: seller, Price: big.NewInt(10)})
+	if err != nil {
+		t.Fatalf("put listing: %v", err)
+	}
+	second, err := manager.IdentityPutListing(&identity.Listing{Alias: "forsale", Seller: seller, Buyer: buyer, Price: big.NewInt(5)})
+	if err != nil {
+		t.Fatalf("replace listing: %v", err)
+	}
+	if second.Nonce <= first.Nonce {
+		t.Fatalf("expected increasing listing nonces, got %d then %d", first.Nonce, second.Nonce)
+	}
+	stored, ok, err := manager.IdentityGetListing("forsale")
+	if err != nil || !ok {
+		t.Fatalf("get listing: ok=%v err=%v", ok, err)
+	}
+	if stored.Buyer != buyer || stored.Price.Cmp(big.NewInt(5)) != 0 || !stored.Reserved() {
+		t.Fatalf("unexpected listing: %+v", stored)
+	}
+
+	record, err := manager.IdentityTransfer("forsale", buyer, 2000)
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if record.Owner != buyer || record.Primary != buyer || len(record.Addresses) != 1 {
+		t.Fatalf("unexpected transferred record: %+v", record)
+	}
+	if alias, ok := manager.IdentityReverse(buyer[:]); !ok || alias != "forsale" {
+		t.Fatalf("expected buyer reverse mapping, got %q", alias)
+	}
+	if _, ok := manager.IdentityReverse(seller[:]); ok {
+		t.Fatalf("expected seller reverse mapping to be cleared")
+	}
+	if _, ok, _ := manager.IdentityGetListing("forsale"); ok {
+		t.Fatalf("expected listing to be removed after transfer")
+	}
+}
+
+func TestIdentityClaimAliasRenameKeepsExpiry(t *testing.T) {
+	manager := newTestManager(t)
+	terms := identity.Terms{Term: 100 * time.Second}
+	var owner [20]byte
+	owner[19] = 1
+	if _, err := manager.IdentityClaimAlias(owner[:], "before", terms, 1000); err != nil {
+		t.Fatalf("claim alias: %v", err)
+	}
+	renamed, err := manager.IdentityClaimAlias(owner[:], "after", terms, 1050)
+	if err != nil {
+		t.Fatalf("rename alias: %v", err)
+	}
+	if renamed.ExpiresAt != 1100 {
+		t.Fatalf("rename should keep the original expiry, got %d", renamed.ExpiresAt)
+	}
+}
+
+func TestIdentitySubAliasResolutionAndNamespace(t *testing.T) {
+	manager := newTestManager(t)
+	terms := identity.Terms{Term: 100 * time.Second}
+	var owner, cashier, squatter [20]byte
+	owner[19] = 1
+	cashier[19] = 2
+	squatter[19] = 3
+	if _, err := manager.IdentityClaimAlias(owner[:], "acme", terms, 1000); err != nil {
+		t.Fatalf("claim parent: %v", err)
+	}
+	if err := manager.IdentityPutSubAlias(&identity.SubAlias{Label: "Cashier1", Parent: "acme", Target: cashier, CreatedAt: 1000, UpdatedAt: 1000}); err != nil {
+		t.Fatalf("put sub-alias: %v", err)
+	}
+
+	record, ok := manager.IdentityResolveAt("cashier1.acme", terms, 1050)
+	if !ok {
+		t.Fatalf("expected sub-alias to resolve")
+	}
+	if record.Primary != cashier || record.Owner != owner || record.Parent != "acme" || record.ExpiresAt != 1100 {
+		t.Fatalf("unexpected sub-alias record: %+v", record)
+	}
+	if _, err := manager.IdentityClaimAlias(squatter[:], "cashier2.acme", terms, 1050); !errors.Is(err, identity.ErrSubAliasNamespace) {
+		t.Fatalf("expected namespace rejection, got %v", err)
+	}
+
+	renamed, err := manager.IdentityRename("acme", "acmecorp", 1060)
+	if err != nil {
+		t.Fatalf("rename parent: %v", err)
+	}
+	if _, ok := manager.IdentityResolveAt("cashier1.acme", terms, 1060); ok {
+		t.Fatalf("old sub-alias name should not resolve after parent rename")
+	}
+	if record, ok := manager.IdentityResolveAt("cashier1."+renamed.Alias, terms, 1060); !ok || record.Primary != cashier {
+		t.Fatalf("expected sub-alias to follow parent rename")
+	}
+	if _, ok := manager.IdentityResolveAt("cashier1.acmecorp", terms, 1100); ok {
+		t.Fatalf("sub-alias should stop resolving once the parent is released")
+	}
+
+	if _, err := manager.IdentityClaimAlias(squatter[:], "acmecorp", terms, 1100); err != nil {
+		t.Fatalf("claim released parent: %v", err)
+	}
+	subs, err := manager.IdentitySubAliases("acmecorp")
+	if err != nil {
+		t.Fatalf("list sub-aliases: %v", err)
+	}
+	if len(subs) != 0 {
+		t.Fatalf("expected sub-aliases to be removed with the released parent, got %d", len(subs))
+	}
+}
@@ -0,0 +1,240 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	invoiceRecordPrefix   = []byte("invoice/record/")
+	invoiceMerchantPrefix = []byte("invoice/merchant/")
+
+	// InvoiceIntentPrefix marks an IntentRef that settles a native invoice.
+	// The prefix is followed by the 32-byte invoice ID and an optional
+	// client-chosen suffix so that each partial payment can carry its own
+	// single-use intent reference while still resolving to the same invoice.
+	InvoiceIntentPrefix = []byte("invoice:")
+
+	// ErrInvoiceNotFound is returned when an invoice ID has no record.
+	ErrInvoiceNotFound = errors.New("invoice: not found")
+)
+
+// maxInvoiceIntentSuffix keeps invoice references inside the intent
+// registry's 64-byte limit.
+const maxInvoiceIntentSuffix = maxIntentRefLen - 8 - 32
+
+// InvoiceStatus captures the lifecycle of a native merchant invoice.
+type InvoiceStatus string
+
+const (
+	// InvoiceStatusOpen marks an invoice that has not received any payment.
+	InvoiceStatusOpen InvoiceStatus = "open"
+	// InvoiceStatusPartiallyPaid marks an invoice that accepts partial
+	// payments and has received less than its full amount.
+	InvoiceStatusPartiallyPaid InvoiceStatus = "partially_paid"
+	// InvoiceStatusPaid marks an invoice whose full amount has been settled.
+	InvoiceStatusPaid InvoiceStatus = "paid"
+	// InvoiceStatusCancelled marks an invoice withdrawn by its merchant.
+	InvoiceStatusCancelled InvoiceStatus = "cancelled"
+)
+
+// Accepting reports whether an invoice in this status can still be paid.
+func (s InvoiceStatus) Accepting() bool {
+	return s == InvoiceStatusOpen || s == InvoiceStatusPartiallyPaid
+}
+
+// StoredInvoice is the RLP-encoded, on-chain representation of a merchant
+// invoice.
+type StoredInvoice struct {
+	ID           [32]byte
+	Merchant     [20]byte
+	Asset        string
+	Amount       *big.Int
+	Paid         *big.Int
+	MemoHash     [32]byte
+	AllowPartial bool
+	Status       string
+	CreatedAt    uint64
+	Expiry       uint64
+	SettledAt    uint64
+}
+
+// Remaining returns the amount still owed on the invoice.
+func (inv *StoredInvoice) Remaining() *big.Int {
+	if inv == nil || inv.Amount == nil {
+		return big.NewInt(0)
+	}
+	paid := inv.Paid
+	if paid == nil {
+		paid = big.NewInt(0)
+	}
+	remaining := new(big.Int).Sub(inv.Amount, paid)
+	if remaining.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return remaining
+}
+
+// Expired reports whether the invoice expiry has passed at the supplied unix
+// timestamp. Invoices without an expiry never expire.
+func (inv *StoredInvoice) Expired(now uint64) bool {
+	return inv != nil && inv.Expiry != 0 && now >= inv.Expiry
+}
+
+func invoiceRecordKey(id [32]byte) []byte {
+	buf := make([]byte, len(invoiceRecordPrefix)+len(id))
+	copy(buf, invoiceRecordPrefix)
+	copy(buf[len(invoiceRecordPrefix):], id[:])
+	return ethcrypto.Keccak256(buf)
+}
+
+func invoiceMerchantKey(merchant [20]byte) []byte {
+	buf := make([]byte, len(invoiceMerchantPrefix)+len(merchant))
+	copy(buf, invoiceMerchantPrefix)
+	copy(buf[len(invoiceMerchantPrefix):], merchant[:])
+	return ethcrypto.Keccak256(buf)
+}
+
+// InvoiceID derives the canonical invoice ID from the creating transaction's
+// hash so IDs cannot be chosen or collided by the merchant.
+func InvoiceID(txHash []byte) [32]byte {
+	var id [32]byte
+	copy(id[:], ethcrypto.Keccak256(invoiceRecordPrefix, txHash))
+	return id
+}
+
+// InvoiceIntentRef builds the IntentRef a payer attaches to a transfer to
+// settle the invoice. The suffix may be empty for single payments.
+func InvoiceIntentRef(id [32]byte, suffix []byte) ([]byte, error) {
+	if len(suffix) > maxInvoiceIntentSuffix {
+		return nil, fmt.Errorf("invoice: intent suffix exceeds %d bytes", maxInvoiceIntentSuffix)
+	}
+	ref := make([]byte, 0, len(InvoiceIntentPrefix)+len(id)+len(suffix))
+	ref = append(ref, InvoiceIntentPrefix...)
+	ref = append(ref, id[:]...)
+	return append(ref, suffix...), nil
+}
+
+// ParseInvoiceIntentRef extracts the invoice ID from an IntentRef. The
+// boolean is false when the reference does not target an invoice.
+func ParseInvoiceIntentRef(ref []byte) ([32]byte, bool) {
+	var id [32]byte
+	if !bytes.HasPrefix(ref, InvoiceIntentPrefix) || len(ref) < len(InvoiceIntentPrefix)+len(id) {
+		return id, false
+	}
+	copy(id[:], ref[len(InvoiceIntentPrefix):])
+	return id, true
+}
+
+// CreateInvoice persists a new invoice and indexes it under its merchant.
+// Invoices are create-once; updates go through PutInvoice.
+func (m *Manager) CreateInvoice(inv *StoredInvoice) error {
+	if inv == nil {
+		return fmt.Errorf("invoice: record must not be nil")
+	}
+	key := invoiceRecordKey(inv.ID)
+	ok, err := m.KVGet(key, nil)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf("invoice: %x already exists", inv.ID)
+	}
+	if err := m.KVPut(key, inv); err != nil {
+		return err
+	}
+	return m.KVAppend(invoiceMerchantKey(inv.Merchant), inv.ID[:])
+}
+
+// PutInvoice overwrites an existing invoice record.
+func (m *Manager) PutInvoice(inv *StoredInvoice) error {
+	if inv == nil {
+		return fmt.Errorf("invoice: record must not be nil")
+	}
+	key := invoiceRecordKey(inv.ID)
+	ok, err := m.KVGet(key, nil)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvoiceNotFound
+	}
+	return m.KVPut(key, inv)
+}
+
+// GetInvoice loads an invoice by ID.
+func (m *Manager) GetInvoice(id [32]byte) (*StoredInvoice, bool, error) {
+	var stored StoredInvoice
+	ok, err := m.KVGet(invoiceRecordKey(id), &stored)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	if stored.Paid == nil {
+		stored.Paid = big.NewInt(0)
+	}
+	stored.Asset = strings.ToUpper(stored.Asset)
+	return &stored, true, nil
+}
+
+// ListInvoicesByMerchant returns the merchant's invoices in creation order.
+func (m *Manager) ListInvoicesByMerchant(merchant [20]byte) ([]*StoredInvoice, error) {
+	var ids [][]byte
+	if err := m.KVGetList(invoiceMerchantKey(merchant), &ids); err != nil {
+		return nil, err
+	}
+	out := make([]*StoredInvoice, 0, len(ids))
+	for _, raw := range ids {
+		var id [32]byte
+		copy(id[:], raw)
+		inv, ok, err := m.GetInvoice(id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, inv)
+		}
+	}
+	return out, nil
+}
+
+// Invoices returns every stored invoice ordered by creation time and ID.
+// Invoices are only indexed per merchant, so the call walks the entire trie
+// and is intended for offline tooling such as genesis export.
+func (m *Manager) Invoices() ([]*StoredInvoice, error) {
+	var out []*StoredInvoice
+	err := m.trie.ForEach(func(key, value []byte) error {
+		stored := new(StoredInvoice)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if !bytes.Equal(key, kvKey(invoiceRecordKey(stored.ID))) {
+			return nil
+		}
+		if stored.Paid == nil {
+			stored.Paid = big.NewInt(0)
+		}
+		stored.Asset = strings.ToUpper(stored.Asset)
+		out = append(out, stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return bytes.Compare(out[i].ID[:], out[j].ID[:]) < 0
+	})
+	return out, nil
+}
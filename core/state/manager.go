@@ -46,6 +46,14 @@ type TokenMetadata struct {
 	Decimals      uint8
 	MintAuthority []byte
 	MintPaused    bool
+
+	// Issued asset settings; genesis tokens leave them empty. A zero
+	// SupplyCap means the supply is uncapped.
+	FreezeAuthority []byte   `rlp:"optional"`
+	SupplyCap       *big.Int `rlp:"optional"`
+	Minted          *big.Int `rlp:"optional"`
+	Burned          *big.Int `rlp:"optional"`
+	Issuer          []byte   `rlp:"optional"`
 }
 
 var (
@@ -62,6 +70,11 @@ var (
 	loyaltyProgramDailyTotalPrefix = []byte("loyalty-meter:program-daily-total:")
 	loyaltyProgramEpochPrefix      = []byte("loyalty-meter:program-epoch:")
 	loyaltyProgramIssuancePrefix   = []byte("loyalty-meter:program-issuance:")
+	loyaltyProgramSpendPrefix      = []byte("loyalty-meter:program-spend:")
+	loyaltyRewardLotsPrefix        = []byte("loyalty-meter:reward-lots:")
+	loyaltyRewardExpiryPrefix      = []byte("loyalty-meter:reward-expiry:")
+	loyaltyRewardExpiryCursorKey   = []byte("loyalty-meter:reward-expiry-cursor")
+	loyaltyRewardVaultSeed         = "module/loyalty/reward/vault"
 	loyaltyBusinessPrefix          = []byte("loyalty/business/")
 	loyaltyBusinessOwnerPrefix     = []byte("loyalty/business-owner/")
 	loyaltyMerchantIndexPrefix     = []byte("loyalty/merchant-index/")
@@ -77,6 +90,8 @@ var (
 	creatorStakePrefix             = []byte("creator/stake/")
 	creatorLedgerPrefix            = []byte("creator/ledger/")
 	creatorRateLimitPrefix         = []byte("creator/rate-limit")
+	creatorTierPrefix              = []byte("creator/tier/")
+	creatorSubscriptionPrefix      = []byte("creator/subscription/")
 	claimableRecordPrefix          = []byte("claimable/record/")
 	claimableNoncePrefix           = []byte("claimable/nonce/")
 	tradeRecordPrefix              = []byte("trade/record/")
@@ -1047,6 +1062,35 @@ func LoyaltyProgramEpochKey(id loyalty.ProgramID, epoch uint64) []byte {
 	return ethcrypto.Keccak256(buf)
 }
 
+func LoyaltyProgramSpendKey(id loyalty.ProgramID, addr []byte, day uint64) []byte {
+	dayStr := strconv.FormatUint(day, 10)
+	buf := make([]byte, 0, len(loyaltyProgramSpendPrefix)+len(id)+len(addr)+len(dayStr)+2)
+	buf = append(buf, loyaltyProgramSpendPrefix...)
+	buf = append(buf, id[:]...)
+	buf = append(buf, ':')
+	buf = append(buf, addr...)
+	buf = append(buf, ':')
+	buf = append(buf, dayStr...)
+	return ethcrypto.Keccak256(buf)
+}
+
+func LoyaltyRewardLotsKey(id loyalty.ProgramID, addr []byte) []byte {
+	buf := make([]byte, len(loyaltyRewardLotsPrefix)+len(id)+1+len(addr))
+	copy(buf, loyaltyRewardLotsPrefix)
+	copy(buf[len(loyaltyRewardLotsPrefix):], id[:])
+	buf[len(loyaltyRewardLotsPrefix)+len(id)] = ':'
+	copy(buf[len(loyaltyRewardLotsPrefix)+len(id)+1:], addr)
+	return ethcrypto.Keccak256(buf)
+}
+
+func loyaltyRewardExpiryKey(day uint64) []byte {
+	dayStr := strconv.FormatUint(day, 10)
+	buf := make([]byte, 0, len(loyaltyRewardExpiryPrefix)+len(dayStr))
+	buf = append(buf, loyaltyRewardExpiryPrefix...)
+	buf = append(buf, dayStr...)
+	return buf
+}
+
 func LoyaltyProgramIssuanceKey(id loyalty.ProgramID, addr []byte) []byte {
 	buf := make([]byte, len(loyaltyProgramIssuancePrefix)+len(id)+1+len(addr))
 	copy(buf, loyaltyProgramIssuancePrefix)
@@ -1248,6 +1292,56 @@ type storedLendingMarket struct {
 	BorrowIndex        *big.Int
 	LastUpdateBlock    uint64
 	ReserveFactor      uint64
+	BadDebtNHB         *big.Int                  `rlp:"optional"`
+	BorrowAsset        string                    `rlp:"optional"`
+	BorrowOracleFeed   string                    `rlp:"optional"`
+	OracleMaxAgeSecs   uint64                    `rlp:"optional"`
+	Collateral         []storedLendingCollateral `rlp:"optional"`
+	TotalCollateral    []storedLendingAmount     `rlp:"optional"`
+}
+
+type storedLendingCollateral struct {
+	Asset                string
+	MaxLTV               uint64
+	LiquidationThreshold uint64
+	OracleFeed           string
+	SupplyCap            *big.Int
+}
+
+// storedLendingAmount pairs an asset symbol with an amount so per-asset maps
+// encode in a deterministic order.
+type storedLendingAmount struct {
+	Asset  string
+	Amount *big.Int
+}
+
+func newStoredLendingAmounts(amounts map[string]*big.Int) []storedLendingAmount {
+	if len(amounts) == 0 {
+		return nil
+	}
+	assets := make([]string, 0, len(amounts))
+	for asset, amount := range amounts {
+		if amount != nil && amount.Sign() > 0 {
+			assets = append(assets, asset)
+		}
+	}
+	sort.Strings(assets)
+	stored := make([]storedLendingAmount, 0, len(assets))
+	for _, asset := range assets {
+		stored = append(stored, storedLendingAmount{Asset: asset, Amount: new(big.Int).Set(amounts[asset])})
+	}
+	return stored
+}
+
+func storedLendingAmountsToMap(stored []storedLendingAmount) map[string]*big.Int {
+	amounts := make(map[string]*big.Int, len(stored))
+	for _, entry := range stored {
+		if entry.Amount == nil || entry.Amount.Sign() <= 0 {
+			continue
+		}
+		amounts[entry.Asset] = new(big.Int).Set(entry.Amount)
+	}
+	return amounts
 }
 
 type storedLendingFees struct {
@@ -1260,10 +1354,26 @@ func newStoredLendingMarket(market *lending.Market) *storedLendingMarket {
 		return nil
 	}
 	stored := &storedLendingMarket{
-		PoolID:          strings.TrimSpace(market.PoolID),
-		LastUpdateBlock: market.LastUpdateBlock,
-		ReserveFactor:   market.ReserveFactor,
-		DeveloperFeeBps: market.DeveloperFeeBps,
+		PoolID:           strings.TrimSpace(market.PoolID),
+		LastUpdateBlock:  market.LastUpdateBlock,
+		ReserveFactor:    market.ReserveFactor,
+		DeveloperFeeBps:  market.DeveloperFeeBps,
+		BorrowAsset:      market.BorrowAsset,
+		BorrowOracleFeed: market.BorrowOracleFeed,
+		OracleMaxAgeSecs: market.OracleMaxAgeSeconds,
+		TotalCollateral:  newStoredLendingAmounts(market.TotalCollateral),
+	}
+	for _, cfg := range market.Collateral {
+		entry := storedLendingCollateral{
+			Asset:                cfg.Asset,
+			MaxLTV:               cfg.MaxLTV,
+			LiquidationThreshold: cfg.LiquidationThreshold,
+			OracleFeed:           cfg.OracleFeed,
+		}
+		if cfg.SupplyCap != nil {
+			entry.SupplyCap = new(big.Int).Set(cfg.SupplyCap)
+		}
+		stored.Collateral = append(stored.Collateral, entry)
 	}
 	if market.DeveloperOwner.Bytes() != nil {
 		copy(stored.DeveloperOwner[:], market.DeveloperOwner.Bytes())
@@ -1286,6 +1396,9 @@ func newStoredLendingMarket(market *lending.Market) *storedLendingMarket {
 	if market.BorrowIndex != nil {
 		stored.BorrowIndex = new(big.Int).Set(market.BorrowIndex)
 	}
+	if market.BadDebtNHB != nil && market.BadDebtNHB.Sign() > 0 {
+		stored.BadDebtNHB = new(big.Int).Set(market.BadDebtNHB)
+	}
 	return stored
 }
 
@@ -1294,10 +1407,26 @@ func (s *storedLendingMarket) toMarket() *lending.Market {
 		return nil
 	}
 	market := &lending.Market{
-		PoolID:          strings.TrimSpace(s.PoolID),
-		LastUpdateBlock: s.LastUpdateBlock,
-		ReserveFactor:   s.ReserveFactor,
-		DeveloperFeeBps: s.DeveloperFeeBps,
+		PoolID:              strings.TrimSpace(s.PoolID),
+		LastUpdateBlock:     s.LastUpdateBlock,
+		ReserveFactor:       s.ReserveFactor,
+		DeveloperFeeBps:     s.DeveloperFeeBps,
+		BorrowAsset:         s.BorrowAsset,
+		BorrowOracleFeed:    s.BorrowOracleFeed,
+		OracleMaxAgeSeconds: s.OracleMaxAgeSecs,
+		TotalCollateral:     storedLendingAmountsToMap(s.TotalCollateral),
+	}
+	for _, entry := range s.Collateral {
+		cfg := lending.CollateralAsset{
+			Asset:                entry.Asset,
+			MaxLTV:               entry.MaxLTV,
+			LiquidationThreshold: entry.LiquidationThreshold,
+			OracleFeed:           entry.OracleFeed,
+		}
+		if entry.SupplyCap != nil && entry.SupplyCap.Sign() > 0 {
+			cfg.SupplyCap = new(big.Int).Set(entry.SupplyCap)
+		}
+		market.Collateral = append(market.Collateral, cfg)
 	}
 	var zeroAddr [20]byte
 	if !bytes.Equal(s.DeveloperOwner[:], zeroAddr[:]) {
@@ -1321,6 +1450,9 @@ func (s *storedLendingMarket) toMarket() *lending.Market {
 	if s.BorrowIndex != nil {
 		market.BorrowIndex = new(big.Int).Set(s.BorrowIndex)
 	}
+	if s.BadDebtNHB != nil {
+		market.BadDebtNHB = new(big.Int).Set(s.BadDebtNHB)
+	}
 	return market
 }
 
@@ -1352,23 +1484,28 @@ func (s *storedLendingFees) toFeeAccrual() *lending.FeeAccrual {
 	return fees
 }
 
+// storedLendingUser keeps the CollateralZNHB slot from the single-asset
+// layout. Records written before multi-asset markets carry their collateral
+// there; newer records leave it empty and use Collateral instead.
 type storedLendingUser struct {
 	Address        [20]byte
 	CollateralZNHB *big.Int
 	SupplyShares   *big.Int
 	DebtNHB        *big.Int
 	ScaledDebt     *big.Int
+	AuctionStart   uint64                `rlp:"optional"`
+	Collateral     []storedLendingAmount `rlp:"optional"`
 }
 
 func newStoredLendingUser(account *lending.UserAccount) *storedLendingUser {
 	if account == nil {
 		return nil
 	}
-	stored := &storedLendingUser{}
-	copy(stored.Address[:], account.Address.Bytes())
-	if account.CollateralZNHB != nil {
-		stored.CollateralZNHB = new(big.Int).Set(account.CollateralZNHB)
+	stored := &storedLendingUser{
+		AuctionStart: account.LiquidationAuctionStart,
+		Collateral:   newStoredLendingAmounts(account.Collateral),
 	}
+	copy(stored.Address[:], account.Address.Bytes())
 	if account.SupplyShares != nil {
 		stored.SupplyShares = new(big.Int).Set(account.SupplyShares)
 	}
@@ -1386,10 +1523,12 @@ func (s *storedLendingUser) toUserAccount() *lending.UserAccount {
 		return nil
 	}
 	account := &lending.UserAccount{
-		Address: crypto.MustNewAddress(crypto.NHBPrefix, append([]byte(nil), s.Address[:]...)),
+		Address:                 crypto.MustNewAddress(crypto.NHBPrefix, append([]byte(nil), s.Address[:]...)),
+		LiquidationAuctionStart: s.AuctionStart,
+		Collateral:              storedLendingAmountsToMap(s.Collateral),
 	}
-	if s.CollateralZNHB != nil {
-		account.CollateralZNHB = new(big.Int).Set(s.CollateralZNHB)
+	if len(s.Collateral) == 0 && s.CollateralZNHB != nil && s.CollateralZNHB.Sign() > 0 {
+		account.Collateral[lending.AssetZNHB] = new(big.Int).Set(s.CollateralZNHB)
 	}
 	if s.SupplyShares != nil {
 		account.SupplyShares = new(big.Int).Set(s.SupplyShares)
@@ -1495,6 +1634,36 @@ func (m *Manager) LendingGetMarket(poolID string) (*lending.Market, bool, error)
 	return market, true, nil
 }
 
+// LendingPriceSource returns the oracle lending markets value collateral with.
+// Feeds resolve to the last swap price proof recorded for the feed symbol, the
+// same deterministic source loyalty pricing uses, and ages are measured
+// against now.
+func (m *Manager) LendingPriceSource(now time.Time) lending.PriceSource {
+	return lendingPriceSource{manager: m, now: now}
+}
+
+type lendingPriceSource struct {
+	manager *Manager
+	now     time.Time
+}
+
+func (p lendingPriceSource) FeedPrice(feed string, maxAgeSeconds uint64) (*big.Rat, bool, error) {
+	record, ok, err := p.manager.SwapLastPriceProof(feed)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok || record == nil || record.Rate == nil || record.Rate.Sign() <= 0 {
+		return nil, false, nil
+	}
+	if maxAgeSeconds > 0 {
+		cutoff := p.now.UTC().Add(-time.Duration(maxAgeSeconds) * time.Second)
+		if record.Timestamp.IsZero() || record.Timestamp.Before(cutoff) {
+			return nil, false, nil
+		}
+	}
+	return new(big.Rat).Set(record.Rate), true, nil
+}
+
 // LendingPutMarket persists the supplied lending market snapshot.
 func (m *Manager) LendingPutMarket(poolID string, market *lending.Market) error {
 	if market == nil {
@@ -1584,6 +1753,16 @@ func (m *Manager) LendingPutUserAccount(poolID string, account *lending.UserAcco
 	return m.KVPut(lendingUserKey(normalized, addr.Bytes()), newStoredLendingUser(account))
 }
 
+// LendingDeleteUserAccount removes the lending position tracked for the
+// supplied address within the provided pool.
+func (m *Manager) LendingDeleteUserAccount(poolID string, addr [20]byte) error {
+	normalized, err := normalizePoolID(poolID)
+	if err != nil {
+		return err
+	}
+	return m.KVDelete(lendingUserKey(normalized, addr[:]))
+}
+
 func potsoStakeTotalKey(owner []byte) []byte {
 	buf := make([]byte, len(potsoStakeTotalPrefix)+len(owner))
 	copy(buf, potsoStakeTotalPrefix)
@@ -2549,6 +2728,8 @@ type storedAliasRecord struct {
 	AvatarRef string
 	CreatedAt *big.Int
 	UpdatedAt *big.Int
+	ExpiresAt *big.Int              `rlp:"optional"`
+	Records   []identity.TextRecord `rlp:"optional"`
 }
 
 func newStoredAliasRecord(record *identity.AliasRecord) *storedAliasRecord {
@@ -2565,6 +2746,12 @@ func newStoredAliasRecord(record *identity.AliasRecord) *storedAliasRecord {
 		CreatedAt: big.NewInt(normalized.CreatedAt),
 		UpdatedAt: big.NewInt(normalized.UpdatedAt),
 	}
+	if normalized.ExpiresAt != 0 {
+		stored.ExpiresAt = big.NewInt(normalized.ExpiresAt)
+	}
+	if len(normalized.Records) > 0 {
+		stored.Records = append([]identity.TextRecord(nil), normalized.Records...)
+	}
 	if len(normalized.Addresses) > 0 {
 		stored.Addresses = make([][]byte, len(normalized.Addresses))
 		for i, addr := range normalized.Addresses {
@@ -2592,6 +2779,12 @@ func (s *storedAliasRecord) toAliasRecord() (*identity.AliasRecord, error) {
 	if s.UpdatedAt != nil {
 		record.UpdatedAt = s.UpdatedAt.Int64()
 	}
+	if s.ExpiresAt != nil {
+		record.ExpiresAt = s.ExpiresAt.Int64()
+	}
+	if len(s.Records) > 0 {
+		record.Records = append([]identity.TextRecord(nil), s.Records...)
+	}
 	if len(s.Addresses) > 0 {
 		record.Addresses = make([][20]byte, len(s.Addresses))
 		for i, raw := range s.Addresses {
@@ -2702,6 +2895,9 @@ func (m *Manager) identityPersistRecord(record *identity.AliasRecord, previousAl
 		if err := m.trie.Update(identityAliasIDKey(oldID), nil); err != nil {
 			return err
 		}
+		if err := m.identityMoveSubAliases(previousAlias, record.Alias); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -2733,7 +2929,7 @@ func tradeEscrowIndexKey(escrowID [32]byte) []byte {
 }
 
 func escrowModuleAddress(token string) ([20]byte, error) {
-	normalized, err := escrow.NormalizeToken(token)
+	normalized, err := escrow.NormalizeAsset(token)
 	if err != nil {
 		return [20]byte{}, err
 	}
@@ -2835,6 +3031,12 @@ type storedEscrowRealm struct {
 	FeeBps          uint32
 	FeeRecipient    [20]byte
 	Metadata        *storedEscrowRealmMetadata
+
+	ArbitratorFeeBps      uint32               `rlp:"optional"`
+	EvidenceWindowSeconds uint64               `rlp:"optional"`
+	ArbitrationSLASeconds uint64               `rlp:"optional"`
+	FallbackArbitrators   *storedArbitratorSet `rlp:"nil,optional"`
+	MinReputationScore    uint32               `rlp:"optional"`
 }
 
 func newStoredEscrowRealm(r *escrow.EscrowRealm) *storedEscrowRealm {
@@ -2843,11 +3045,12 @@ func newStoredEscrowRealm(r *escrow.EscrowRealm) *storedEscrowRealm {
 	}
 	created := big.NewInt(r.CreatedAt)
 	updated := big.NewInt(r.UpdatedAt)
-	var feeBps uint32
+	var feeBps, arbitratorFeeBps uint32
 	var feeRecipient [20]byte
 	if r.FeeSchedule != nil {
 		feeBps = r.FeeSchedule.FeeBps
 		feeRecipient = r.FeeSchedule.Recipient
+		arbitratorFeeBps = r.FeeSchedule.ArbitratorFeeBps
 	}
 	return &storedEscrowRealm{
 		ID:              strings.TrimSpace(r.ID),
@@ -2859,6 +3062,12 @@ func newStoredEscrowRealm(r *escrow.EscrowRealm) *storedEscrowRealm {
 		FeeBps:          feeBps,
 		FeeRecipient:    feeRecipient,
 		Metadata:        newStoredEscrowRealmMetadata(r.Metadata),
+
+		ArbitratorFeeBps:      arbitratorFeeBps,
+		EvidenceWindowSeconds: uint64(r.EvidenceWindowSeconds),
+		ArbitrationSLASeconds: uint64(r.ArbitrationSLASeconds),
+		FallbackArbitrators:   newStoredArbitratorSet(r.FallbackArbitrators),
+		MinReputationScore:    r.MinReputationScore,
 	}
 }
 
@@ -2885,8 +3094,18 @@ func (s *storedEscrowRealm) toEscrowRealm() (*escrow.EscrowRealm, error) {
 		return nil, err
 	}
 	realm.Arbitrators = set
-	if s.FeeBps > 0 || s.FeeRecipient != ([20]byte{}) {
-		realm.FeeSchedule = &escrow.RealmFeeSchedule{FeeBps: s.FeeBps, Recipient: s.FeeRecipient}
+	if s.FeeBps > 0 || s.FeeRecipient != ([20]byte{}) || s.ArbitratorFeeBps > 0 {
+		realm.FeeSchedule = &escrow.RealmFeeSchedule{FeeBps: s.FeeBps, Recipient: s.FeeRecipient, ArbitratorFeeBps: s.ArbitratorFeeBps}
+	}
+	realm.EvidenceWindowSeconds = int64(s.EvidenceWindowSeconds)
+	realm.ArbitrationSLASeconds = int64(s.ArbitrationSLASeconds)
+	realm.MinReputationScore = s.MinReputationScore
+	if s.FallbackArbitrators != nil {
+		fallback, err := s.FallbackArbitrators.toArbitratorSet()
+		if err != nil {
+			return nil, err
+		}
+		realm.FallbackArbitrators = fallback
 	}
 	if s.Metadata == nil {
 		return nil, fmt.Errorf("escrow: realm missing metadata")
@@ -2914,6 +3133,11 @@ type storedFrozenArb struct {
 	FeeBps       uint32
 	FeeRecipient [20]byte
 	Metadata     *storedEscrowRealmMetadata
+
+	ArbitratorFeeBps      uint32               `rlp:"optional"`
+	EvidenceWindowSeconds uint64               `rlp:"optional"`
+	ArbitrationSLASeconds uint64               `rlp:"optional"`
+	Fallback              *storedArbitratorSet `rlp:"optional"`
 }
 
 func newStoredFrozenArb(f *escrow.FrozenArb) *storedFrozenArb {
@@ -2922,11 +3146,12 @@ func newStoredFrozenArb(f *escrow.FrozenArb) *storedFrozenArb {
 	}
 	members := make([][20]byte, len(f.Members))
 	copy(members, f.Members)
-	var feeBps uint32
+	var feeBps, arbitratorFeeBps uint32
 	var feeRecipient [20]byte
 	if f.FeeSchedule != nil {
 		feeBps = f.FeeSchedule.FeeBps
 		feeRecipient = f.FeeSchedule.Recipient
+		arbitratorFeeBps = f.FeeSchedule.ArbitratorFeeBps
 	}
 	return &storedFrozenArb{
 		RealmID:      strings.TrimSpace(f.RealmID),
@@ -2939,6 +3164,11 @@ func newStoredFrozenArb(f *escrow.FrozenArb) *storedFrozenArb {
 		FeeBps:       feeBps,
 		FeeRecipient: feeRecipient,
 		Metadata:     newStoredEscrowRealmMetadata(f.Metadata),
+
+		ArbitratorFeeBps:      arbitratorFeeBps,
+		EvidenceWindowSeconds: uint64(f.EvidenceWindowSeconds),
+		ArbitrationSLASeconds: uint64(f.ArbitrationSLASeconds),
+		Fallback:              newStoredArbitratorSet(f.Fallback),
 	}
 }
 
@@ -2960,8 +3190,17 @@ func (s *storedFrozenArb) toFrozenArb() (*escrow.FrozenArb, error) {
 	if s.FrozenAt != nil {
 		frozen.FrozenAt = s.FrozenAt.Int64()
 	}
-	if s.FeeBps > 0 || s.FeeRecipient != ([20]byte{}) {
-		frozen.FeeSchedule = &escrow.RealmFeeSchedule{FeeBps: s.FeeBps, Recipient: s.FeeRecipient}
+	if s.FeeBps > 0 || s.FeeRecipient != ([20]byte{}) || s.ArbitratorFeeBps > 0 {
+		frozen.FeeSchedule = &escrow.RealmFeeSchedule{FeeBps: s.FeeBps, Recipient: s.FeeRecipient, ArbitratorFeeBps: s.ArbitratorFeeBps}
+	}
+	frozen.EvidenceWindowSeconds = int64(s.EvidenceWindowSeconds)
+	frozen.ArbitrationSLASeconds = int64(s.ArbitrationSLASeconds)
+	if s.Fallback != nil {
+		fallback, err := s.Fallback.toArbitratorSet()
+		if err != nil {
+			return nil, err
+		}
+		frozen.Fallback = fallback
 	}
 	if s.Metadata == nil {
 		return nil, fmt.Errorf("escrow: frozen policy missing metadata")
@@ -2994,6 +3233,17 @@ type storedEscrow struct {
 	RealmID       string
 	DecisionHash  [32]byte
 	DisputeReason string
+
+	DisputedAt  uint64                 `rlp:"optional"`
+	EscalatedAt uint64                 `rlp:"optional"`
+	Evidence    []storedEscrowEvidence `rlp:"optional"`
+}
+
+type storedEscrowEvidence struct {
+	Submitter   [20]byte
+	Hash        [32]byte
+	URI         string
+	SubmittedAt uint64
 }
 
 // EscrowRealmPut stores the provided realm definition after sanitising it.
@@ -3078,6 +3328,15 @@ func newStoredEscrow(e *escrow.Escrow) *storedEscrow {
 	deadline := big.NewInt(e.Deadline)
 	created := big.NewInt(e.CreatedAt)
 	nonce := new(big.Int).SetUint64(e.Nonce)
+	var evidence []storedEscrowEvidence
+	for _, item := range e.Evidence {
+		evidence = append(evidence, storedEscrowEvidence{
+			Submitter:   item.Submitter,
+			Hash:        item.Hash,
+			URI:         item.URI,
+			SubmittedAt: uint64(item.SubmittedAt),
+		})
+	}
 	return &storedEscrow{
 		ID:            e.ID,
 		Payer:         e.Payer,
@@ -3094,6 +3353,9 @@ func newStoredEscrow(e *escrow.Escrow) *storedEscrow {
 		RealmID:       strings.TrimSpace(e.RealmID),
 		DecisionHash:  e.ResolutionHash,
 		DisputeReason: strings.TrimSpace(e.DisputeReason),
+		DisputedAt:    uint64(e.DisputedAt),
+		EscalatedAt:   uint64(e.EscalatedAt),
+		Evidence:      evidence,
 	}
 }
 
@@ -3119,6 +3381,16 @@ func (s *storedEscrow) toEscrow() (*escrow.Escrow, error) {
 		RealmID:        strings.TrimSpace(s.RealmID),
 		ResolutionHash: s.DecisionHash,
 		DisputeReason:  strings.TrimSpace(s.DisputeReason),
+		DisputedAt:     int64(s.DisputedAt),
+		EscalatedAt:    int64(s.EscalatedAt),
+	}
+	for _, item := range s.Evidence {
+		out.Evidence = append(out.Evidence, escrow.EscrowEvidence{
+			Submitter:   item.Submitter,
+			Hash:        item.Hash,
+			URI:         item.URI,
+			SubmittedAt: int64(item.SubmittedAt),
+		})
 	}
 	if s.Deadline != nil {
 		out.Deadline = s.Deadline.Int64()
@@ -3368,11 +3640,27 @@ func (m *Manager) SetBalance(addr []byte, symbol string, amount *big.Int) error
 	if normalized == "" {
 		return fmt.Errorf("token symbol must not be empty")
 	}
-	if meta, err := m.loadTokenMetadata(normalized); err != nil {
+	meta, err := m.loadTokenMetadata(normalized)
+	if err != nil {
 		return err
-	} else if meta == nil {
+	}
+	if meta == nil {
 		return fmt.Errorf("token %s not registered", normalized)
 	}
+	if meta.IsIssuedAsset() {
+		previous, err := m.Balance(addr, normalized)
+		if err != nil {
+			return err
+		}
+		if err := m.adjustAssetHeld(normalized, new(big.Int).Sub(amount, previous)); err != nil {
+			return err
+		}
+		if amount.Sign() > 0 {
+			if err := m.trackAssetHolder(normalized, addr); err != nil {
+				return err
+			}
+		}
+	}
 
 	key := balanceKey(addr, normalized)
 	encoded, err := rlp.EncodeToBytes(amount)
@@ -3580,6 +3868,116 @@ func (m *Manager) LoyaltyProgramIssuanceAccrued(id loyalty.ProgramID, addr []byt
 	return m.loadBigInt(LoyaltyProgramIssuanceKey(id, addr))
 }
 
+// SetLoyaltyProgramSpend stores the address's spend with the program on the
+// provided UTC day.
+func (m *Manager) SetLoyaltyProgramSpend(id loyalty.ProgramID, addr []byte, day uint64, amount *big.Int) error {
+	if len(addr) == 0 {
+		return fmt.Errorf("address must not be empty")
+	}
+	return m.writeBigInt(LoyaltyProgramSpendKey(id, addr, day), amount)
+}
+
+// LoyaltyProgramSpend returns the address's spend with the program on the
+// provided UTC day.
+func (m *Manager) LoyaltyProgramSpend(id loyalty.ProgramID, addr []byte, day uint64) (*big.Int, error) {
+	if len(addr) == 0 {
+		return nil, fmt.Errorf("address must not be empty")
+	}
+	return m.loadBigInt(LoyaltyProgramSpendKey(id, addr, day))
+}
+
+// SetLoyaltyRewardLots stores the address's unexpired reward lots for the
+// program, oldest first, and schedules the lots for the expiry sweep on the
+// day the earliest of them expires.
+func (m *Manager) SetLoyaltyRewardLots(id loyalty.ProgramID, addr []byte, lots []loyalty.RewardLot) error {
+	if len(addr) == 0 {
+		return fmt.Errorf("address must not be empty")
+	}
+	if lots == nil {
+		lots = []loyalty.RewardLot{}
+	}
+	if err := m.KVPut(LoyaltyRewardLotsKey(id, addr), lots); err != nil {
+		return err
+	}
+	var earliest uint64
+	for _, lot := range lots {
+		if lot.ExpiresAt != 0 && (earliest == 0 || lot.ExpiresAt < earliest) {
+			earliest = lot.ExpiresAt
+		}
+	}
+	if earliest == 0 {
+		return nil
+	}
+	ref := loyalty.RewardLotRef{ProgramID: id}
+	copy(ref.Addr[:], addr)
+	return m.scheduleLoyaltyRewardExpiry(loyalty.DayIndex(earliest), ref)
+}
+
+// LoyaltyRewardLots returns the address's reward lots for the program.
+func (m *Manager) LoyaltyRewardLots(id loyalty.ProgramID, addr []byte) ([]loyalty.RewardLot, error) {
+	if len(addr) == 0 {
+		return nil, fmt.Errorf("address must not be empty")
+	}
+	var lots []loyalty.RewardLot
+	if _, err := m.KVGet(LoyaltyRewardLotsKey(id, addr), &lots); err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+// LoyaltyRewardVault returns the deterministic module vault that holds the
+// unredeemed rewards of expiring programs.
+func (m *Manager) LoyaltyRewardVault() [20]byte {
+	hash := ethcrypto.Keccak256([]byte(loyaltyRewardVaultSeed))
+	var addr [20]byte
+	copy(addr[:], hash[len(hash)-20:])
+	return addr
+}
+
+func (m *Manager) scheduleLoyaltyRewardExpiry(day uint64, ref loyalty.RewardLotRef) error {
+	refs, err := m.LoyaltyRewardExpiries(day)
+	if err != nil {
+		return err
+	}
+	for _, existing := range refs {
+		if existing == ref {
+			return nil
+		}
+	}
+	return m.KVPut(loyaltyRewardExpiryKey(day), append(refs, ref))
+}
+
+// LoyaltyRewardExpiries returns the reward lots scheduled to expire on the
+// provided UTC day.
+func (m *Manager) LoyaltyRewardExpiries(day uint64) ([]loyalty.RewardLotRef, error) {
+	var refs []loyalty.RewardLotRef
+	if _, err := m.KVGet(loyaltyRewardExpiryKey(day), &refs); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// ClearLoyaltyRewardExpiries drops the schedule for a swept day.
+func (m *Manager) ClearLoyaltyRewardExpiries(day uint64) error {
+	return m.KVDelete(loyaltyRewardExpiryKey(day))
+}
+
+// LoyaltyRewardExpiryCursor returns the first UTC day the expiry sweep has
+// not processed yet.
+func (m *Manager) LoyaltyRewardExpiryCursor() (uint64, bool, error) {
+	var day uint64
+	ok, err := m.KVGet(loyaltyRewardExpiryCursorKey, &day)
+	if err != nil {
+		return 0, false, err
+	}
+	return day, ok, nil
+}
+
+// SetLoyaltyRewardExpiryCursor records the first UTC day left to sweep.
+func (m *Manager) SetLoyaltyRewardExpiryCursor(day uint64) error {
+	return m.KVPut(loyaltyRewardExpiryCursorKey, day)
+}
+
 // SetRole associates an address with the specified role. Duplicate assignments
 // are ignored while the stored list remains sorted for determinism.
 func (m *Manager) SetRole(role string, addr []byte) error {
@@ -3730,6 +4128,9 @@ func (m *Manager) EscrowPut(e *escrow.Escrow) error {
 	if err != nil {
 		return err
 	}
+	if _, err := m.normalizeRegisteredToken(sanitized.Token); err != nil {
+		return err
+	}
 	record := newStoredEscrow(sanitized)
 	encoded, err := rlp.EncodeToBytes(record)
 	if err != nil {
@@ -3768,6 +4169,53 @@ func (m *Manager) EscrowGet(id [32]byte) (*escrow.Escrow, bool) {
 	return sanitized, true
 }
 
+// EscrowList returns the identifiers of every escrow stored in state sorted in
+// ascending order. Escrows are not indexed, so the call walks the entire trie
+// and is intended for offline tooling such as genesis export.
+func (m *Manager) EscrowList() ([][32]byte, error) {
+	var ids [][32]byte
+	err := m.trie.ForEach(func(key, value []byte) error {
+		stored := new(storedEscrow)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if !bytes.Equal(key, escrowStorageKey(stored.ID)) {
+			return nil
+		}
+		ids = append(ids, stored.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+	return ids, nil
+}
+
+// EscrowRealmList returns the identifiers of all stored realms in sorted
+// order. Realms are not indexed, so the list is built by walking the trie.
+func (m *Manager) EscrowRealmList() ([]string, error) {
+	var ids []string
+	err := m.trie.ForEach(func(key, value []byte) error {
+		stored := new(storedEscrowRealm)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if stored.ID == "" || !bytes.Equal(key, kvKey(escrowRealmKey(stored.ID))) {
+			return nil
+		}
+		ids = append(ids, stored.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
 // EscrowVaultAddress returns the deterministic module address that holds funds
 // for escrows denominated in the supplied token.
 func (m *Manager) EscrowVaultAddress(token string) ([20]byte, error) {
@@ -3784,7 +4232,7 @@ func (m *Manager) EscrowCredit(id [32]byte, token string, amt *big.Int) error {
 	if amt.Sign() < 0 {
 		return fmt.Errorf("escrow: negative credit")
 	}
-	normalized, err := escrow.NormalizeToken(token)
+	normalized, err := m.normalizeRegisteredToken(token)
 	if err != nil {
 		return err
 	}
@@ -3815,7 +4263,7 @@ func (m *Manager) EscrowDebit(id [32]byte, token string, amt *big.Int) error {
 	if amt.Sign() < 0 {
 		return fmt.Errorf("escrow: negative debit")
 	}
-	normalized, err := escrow.NormalizeToken(token)
+	normalized, err := m.normalizeRegisteredToken(token)
 	if err != nil {
 		return err
 	}
@@ -3837,7 +4285,7 @@ func (m *Manager) EscrowDebit(id [32]byte, token string, amt *big.Int) error {
 // EscrowBalance returns the tracked vault balance for the specified escrow
 // identifier and token symbol.
 func (m *Manager) EscrowBalance(id [32]byte, token string) (*big.Int, error) {
-	normalized, err := escrow.NormalizeToken(token)
+	normalized, err := m.normalizeRegisteredToken(token)
 	if err != nil {
 		return nil, err
 	}
@@ -3996,6 +4444,12 @@ func (m *Manager) IdentitySetAlias(addr []byte, alias string) error {
 			if baseRecord.AvatarRef == "" {
 				baseRecord.AvatarRef = oldRecord.AvatarRef
 			}
+			if baseRecord.ExpiresAt == 0 {
+				baseRecord.ExpiresAt = oldRecord.ExpiresAt
+			}
+			if len(baseRecord.Records) == 0 {
+				baseRecord.Records = oldRecord.Records
+			}
 			if len(baseRecord.Addresses) == 0 {
 				baseRecord.Addresses = oldRecord.Addresses
 			}
@@ -4007,6 +4461,9 @@ func (m *Manager) IdentitySetAlias(addr []byte, alias string) error {
 		if err := m.trie.Update(identityAliasIDKey(oldID), nil); err != nil {
 			return err
 		}
+		if err := m.identityMoveSubAliases(currentAlias, normalized); err != nil {
+			return err
+		}
 	}
 
 	baseRecord.Alias = normalized
@@ -4320,6 +4777,24 @@ func (m *Manager) KVGet(key []byte, out interface{}) (bool, error) {
 	return true, nil
 }
 
+// KVScan walks the entire trie and returns every value written with KVPut
+// whose key keyOf can reconstruct. keyOf decodes a candidate value and
+// returns the key it would be stored under, or nil to skip it. It is intended
+// for offline tooling such as genesis export.
+func (m *Manager) KVScan(keyOf func(value []byte) []byte) ([][]byte, error) {
+	var out [][]byte
+	err := m.trie.ForEach(func(key, value []byte) error {
+		if original := keyOf(value); len(original) > 0 && bytes.Equal(key, kvKey(original)) {
+			out = append(out, append([]byte(nil), value...))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // KVAppend appends the provided value to the RLP-encoded byte slice list stored
 // under the supplied key. Duplicate values are ignored to keep the index
 // deterministic.
@@ -4399,6 +4874,14 @@ func creatorRateLimitKey() []byte {
 	return append([]byte(nil), creatorRateLimitPrefix...)
 }
 
+func creatorTierKey(creator [20]byte, id string) []byte {
+	return []byte(fmt.Sprintf("%s%x/%s", creatorTierPrefix, creator, strings.TrimSpace(id)))
+}
+
+func creatorSubscriptionKey(creator [20]byte, fan [20]byte) []byte {
+	return []byte(fmt.Sprintf("%s%x/%x", creatorSubscriptionPrefix, creator, fan))
+}
+
 type storedCreatorContent struct {
 	ID          string
 	Creator     [20]byte
@@ -4516,6 +4999,11 @@ type storedCreatorLedger struct {
 	TotalAssets         *big.Int
 	TotalShares         *big.Int
 	IndexRay            *big.Int
+	// Subscription revenue counters were added after launch; ledgers
+	// written before then decode with them unset.
+	TotalSubscriptions      *big.Int `rlp:"optional"`
+	TotalSubscriptionsZNHB  *big.Int `rlp:"optional"`
+	PendingDistributionZNHB *big.Int `rlp:"optional"`
 }
 
 func newStoredCreatorLedger(ledger *creator.PayoutLedger) *storedCreatorLedger {
@@ -4550,6 +5038,9 @@ func newStoredCreatorLedger(ledger *creator.PayoutLedger) *storedCreatorLedger {
 	if ledger.IndexRay != nil {
 		stored.IndexRay = new(big.Int).Set(ledger.IndexRay)
 	}
+	stored.TotalSubscriptions = cloneBigInt(ledger.TotalSubscriptions)
+	stored.TotalSubscriptionsZNHB = cloneBigInt(ledger.TotalSubscriptionsZNHB)
+	stored.PendingDistributionZNHB = cloneBigInt(ledger.PendingDistributionZNHB)
 	return stored
 }
 
@@ -4585,6 +5076,9 @@ func (s *storedCreatorLedger) toLedger() *creator.PayoutLedger {
 	if s.IndexRay != nil {
 		ledger.IndexRay = new(big.Int).Set(s.IndexRay)
 	}
+	ledger.TotalSubscriptions = cloneBigInt(s.TotalSubscriptions)
+	ledger.TotalSubscriptionsZNHB = cloneBigInt(s.TotalSubscriptionsZNHB)
+	ledger.PendingDistributionZNHB = cloneBigInt(s.PendingDistributionZNHB)
 	return ledger
 }
 
@@ -4804,3 +5298,205 @@ func (m *Manager) CreatorRateLimitGet() (*creator.RateLimitSnapshot, bool, error
 	}
 	return stored.toSnapshot(), true, nil
 }
+
+type storedCreatorTier struct {
+	Creator       [20]byte
+	ID            string
+	Name          string
+	Asset         string
+	Price         *big.Int
+	PeriodSeconds uint64
+	Active        bool
+	UpdatedAt     uint64
+}
+
+type storedCreatorSubscription struct {
+	Creator       [20]byte
+	Fan           [20]byte
+	TierID        string
+	Asset         string
+	Price         *big.Int
+	PeriodSeconds uint64
+	StartedAt     uint64
+	PaidThrough   uint64
+	Cancelled     bool
+	CancelledAt   uint64
+}
+
+// CreatorTierPut stores a creator subscription tier.
+func (m *Manager) CreatorTierPut(tier *creator.SubscriptionTier) error {
+	if tier == nil {
+		return fmt.Errorf("creator: nil tier")
+	}
+	encoded, err := rlp.EncodeToBytes(&storedCreatorTier{
+		Creator:       tier.Creator,
+		ID:            strings.TrimSpace(tier.ID),
+		Name:          tier.Name,
+		Asset:         tier.Asset,
+		Price:         cloneBigInt(tier.Price),
+		PeriodSeconds: uint64(tier.PeriodSeconds),
+		Active:        tier.Active,
+		UpdatedAt:     uint64(tier.UpdatedAt),
+	})
+	if err != nil {
+		return err
+	}
+	return m.trie.Update(creatorTierKey(tier.Creator, tier.ID), encoded)
+}
+
+// CreatorTierGet loads a creator subscription tier by identifier.
+func (m *Manager) CreatorTierGet(creatorAddr [20]byte, id string) (*creator.SubscriptionTier, bool, error) {
+	data, err := m.trie.Get(creatorTierKey(creatorAddr, id))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) == 0 {
+		return nil, false, nil
+	}
+	stored := new(storedCreatorTier)
+	if err := rlp.DecodeBytes(data, stored); err != nil {
+		return nil, false, err
+	}
+	return &creator.SubscriptionTier{
+		Creator:       stored.Creator,
+		ID:            stored.ID,
+		Name:          stored.Name,
+		Asset:         stored.Asset,
+		Price:         cloneBigInt(stored.Price),
+		PeriodSeconds: int64(stored.PeriodSeconds),
+		Active:        stored.Active,
+		UpdatedAt:     int64(stored.UpdatedAt),
+	}, true, nil
+}
+
+// CreatorSubscriptionPut stores a fan's subscription to a creator.
+func (m *Manager) CreatorSubscriptionPut(subscription *creator.Subscription) error {
+	if subscription == nil {
+		return fmt.Errorf("creator: nil subscription")
+	}
+	encoded, err := rlp.EncodeToBytes(&storedCreatorSubscription{
+		Creator:       subscription.Creator,
+		Fan:           subscription.Fan,
+		TierID:        subscription.TierID,
+		Asset:         subscription.Asset,
+		Price:         cloneBigInt(subscription.Price),
+		PeriodSeconds: uint64(subscription.PeriodSeconds),
+		StartedAt:     uint64(subscription.StartedAt),
+		PaidThrough:   uint64(subscription.PaidThrough),
+		Cancelled:     subscription.Cancelled,
+		CancelledAt:   uint64(subscription.CancelledAt),
+	})
+	if err != nil {
+		return err
+	}
+	return m.trie.Update(creatorSubscriptionKey(subscription.Creator, subscription.Fan), encoded)
+}
+
+// CreatorTiers returns every subscription tier ordered by creator and ID. The
+// call walks the entire trie and is intended for offline tooling such as
+// genesis export.
+func (m *Manager) CreatorTiers() ([]*creator.SubscriptionTier, error) {
+	var out []*creator.SubscriptionTier
+	err := m.trie.ForEach(func(key, value []byte) error {
+		if !bytes.HasPrefix(key, creatorTierPrefix) {
+			return nil
+		}
+		stored := new(storedCreatorTier)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if !bytes.Equal(key, creatorTierKey(stored.Creator, stored.ID)) {
+			return nil
+		}
+		out = append(out, &creator.SubscriptionTier{
+			Creator:       stored.Creator,
+			ID:            stored.ID,
+			Name:          stored.Name,
+			Asset:         stored.Asset,
+			Price:         cloneBigInt(stored.Price),
+			PeriodSeconds: int64(stored.PeriodSeconds),
+			Active:        stored.Active,
+			UpdatedAt:     int64(stored.UpdatedAt),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if c := bytes.Compare(out[i].Creator[:], out[j].Creator[:]); c != 0 {
+			return c < 0
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// CreatorSubscriptions returns every fan subscription ordered by creator and
+// fan. The call walks the entire trie and is intended for offline tooling
+// such as genesis export.
+func (m *Manager) CreatorSubscriptions() ([]*creator.Subscription, error) {
+	var out []*creator.Subscription
+	err := m.trie.ForEach(func(key, value []byte) error {
+		if !bytes.HasPrefix(key, creatorSubscriptionPrefix) {
+			return nil
+		}
+		stored := new(storedCreatorSubscription)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if !bytes.Equal(key, creatorSubscriptionKey(stored.Creator, stored.Fan)) {
+			return nil
+		}
+		out = append(out, &creator.Subscription{
+			Creator:       stored.Creator,
+			Fan:           stored.Fan,
+			TierID:        stored.TierID,
+			Asset:         stored.Asset,
+			Price:         cloneBigInt(stored.Price),
+			PeriodSeconds: int64(stored.PeriodSeconds),
+			StartedAt:     int64(stored.StartedAt),
+			PaidThrough:   int64(stored.PaidThrough),
+			Cancelled:     stored.Cancelled,
+			CancelledAt:   int64(stored.CancelledAt),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if c := bytes.Compare(out[i].Creator[:], out[j].Creator[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(out[i].Fan[:], out[j].Fan[:]) < 0
+	})
+	return out, nil
+}
+
+// CreatorSubscriptionGet loads the fan's subscription to a creator.
+func (m *Manager) CreatorSubscriptionGet(creatorAddr [20]byte, fan [20]byte) (*creator.Subscription, bool, error) {
+	data, err := m.trie.Get(creatorSubscriptionKey(creatorAddr, fan))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) == 0 {
+		return nil, false, nil
+	}
+	stored := new(storedCreatorSubscription)
+	if err := rlp.DecodeBytes(data, stored); err != nil {
+		return nil, false, err
+	}
+	return &creator.Subscription{
+		Creator:       stored.Creator,
+		Fan:           stored.Fan,
+		TierID:        stored.TierID,
+		Asset:         stored.Asset,
+		Price:         cloneBigInt(stored.Price),
+		PeriodSeconds: int64(stored.PeriodSeconds),
+		StartedAt:     int64(stored.StartedAt),
+		PaidThrough:   int64(stored.PaidThrough),
+		Cancelled:     stored.Cancelled,
+		CancelledAt:   int64(stored.CancelledAt),
+	}, true, nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"nhbchain/native/governance"
+	"nhbchain/native/lending"
 	"nhbchain/native/potso"
 	"nhbchain/storage"
 	"nhbchain/storage/trie"
@@ -169,3 +170,47 @@ func TestSnapshotPotsoWeightsRoundTrip(t *testing.T) {
 		t.Fatalf("expected epoch 8 to remain unwritten")
 	}
 }
+
+func TestLendingUserAccountMigratesLegacyCollateral(t *testing.T) {
+	db := storage.NewMemDB()
+	defer db.Close()
+
+	trie, err := trie.NewTrie(db, nil)
+	if err != nil {
+		t.Fatalf("new trie: %v", err)
+	}
+	manager := NewManager(trie)
+
+	var addr [20]byte
+	addr[19] = 0x01
+	legacy := struct {
+		Address        [20]byte
+		CollateralZNHB *big.Int
+		SupplyShares   *big.Int
+		DebtNHB        *big.Int
+		ScaledDebt     *big.Int
+	}{Address: addr, CollateralZNHB: big.NewInt(900), SupplyShares: big.NewInt(0), DebtNHB: big.NewInt(100), ScaledDebt: big.NewInt(100)}
+	if err := manager.KVPut(lendingUserKey("default", addr[:]), legacy); err != nil {
+		t.Fatalf("put legacy user: %v", err)
+	}
+
+	account, ok, err := manager.LendingGetUserAccount("default", addr)
+	if err != nil || !ok {
+		t.Fatalf("get user: ok=%v err=%v", ok, err)
+	}
+	if got := account.CollateralOf(lending.AssetZNHB); got.Cmp(big.NewInt(900)) != 0 {
+		t.Fatalf("unexpected migrated collateral: %s", got)
+	}
+
+	account.SetCollateral(lending.AssetNHB, big.NewInt(50))
+	if err := manager.LendingPutUserAccount("default", account); err != nil {
+		t.Fatalf("put user: %v", err)
+	}
+	reloaded, _, err := manager.LendingGetUserAccount("default", addr)
+	if err != nil {
+		t.Fatalf("reload user: %v", err)
+	}
+	if reloaded.CollateralOf(lending.AssetZNHB).Cmp(big.NewInt(900)) != 0 || reloaded.CollateralOf(lending.AssetNHB).Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("unexpected collateral after round trip: %v", reloaded.Collateral)
+	}
+}
@@ -0,0 +1,222 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	mandateRecordPrefix   = []byte("mandate/record/")
+	mandatePayerPrefix    = []byte("mandate/payer/")
+	mandateMerchantPrefix = []byte("mandate/merchant/")
+
+	// ErrMandateNotFound is returned when a mandate ID has no record.
+	ErrMandateNotFound = errors.New("mandate: not found")
+)
+
+// MandateStatus captures the lifecycle of a recurring payment mandate.
+type MandateStatus string
+
+const (
+	// MandateStatusActive marks a mandate the merchant may pull against.
+	MandateStatusActive MandateStatus = "active"
+	// MandateStatusCancelled marks a mandate revoked by its payer or
+	// merchant.
+	MandateStatusCancelled MandateStatus = "cancelled"
+)
+
+// StoredMandate is the RLP-encoded, on-chain representation of a recurring
+// payment mandate. LastPullPeriod stores the index of the last pulled period
+// plus one so that zero means no pull has happened yet.
+type StoredMandate struct {
+	ID             [32]byte
+	Payer          [20]byte
+	Merchant       [20]byte
+	Asset          string
+	MaxAmount      *big.Int
+	PeriodSeconds  uint64
+	StartAt        uint64
+	EndAt          uint64
+	CreatedAt      uint64
+	Status         string
+	LastPullPeriod uint64
+	PulledTotal    *big.Int
+	PullCount      uint64
+	FailedPulls    uint64
+	LastFailureAt  uint64
+	CancelledAt    uint64
+}
+
+// Ended reports whether the mandate end date has passed at the supplied unix
+// timestamp. Mandates without an end date never end.
+func (m *StoredMandate) Ended(now uint64) bool {
+	return m != nil && m.EndAt != 0 && now >= m.EndAt
+}
+
+// Period returns the zero-based billing period containing now. The boolean
+// is false before the mandate starts.
+func (m *StoredMandate) Period(now uint64) (uint64, bool) {
+	if m == nil || m.PeriodSeconds == 0 || now < m.StartAt {
+		return 0, false
+	}
+	return (now - m.StartAt) / m.PeriodSeconds, true
+}
+
+// PulledInPeriod reports whether a pull already succeeded in period.
+func (m *StoredMandate) PulledInPeriod(period uint64) bool {
+	return m != nil && m.LastPullPeriod == period+1
+}
+
+func mandateRecordKey(id [32]byte) []byte {
+	buf := make([]byte, len(mandateRecordPrefix)+len(id))
+	copy(buf, mandateRecordPrefix)
+	copy(buf[len(mandateRecordPrefix):], id[:])
+	return ethcrypto.Keccak256(buf)
+}
+
+func mandateIndexKey(prefix []byte, addr [20]byte) []byte {
+	buf := make([]byte, len(prefix)+len(addr))
+	copy(buf, prefix)
+	copy(buf[len(prefix):], addr[:])
+	return ethcrypto.Keccak256(buf)
+}
+
+// MandateID derives the canonical mandate ID from the creating transaction's
+// hash so IDs cannot be chosen or collided by the payer.
+func MandateID(txHash []byte) [32]byte {
+	var id [32]byte
+	copy(id[:], ethcrypto.Keccak256(mandateRecordPrefix, txHash))
+	return id
+}
+
+// CreateMandate persists a new mandate and indexes it under its payer and
+// merchant. Mandates are create-once; updates go through PutMandate.
+func (m *Manager) CreateMandate(mandate *StoredMandate) error {
+	if mandate == nil {
+		return fmt.Errorf("mandate: record must not be nil")
+	}
+	key := mandateRecordKey(mandate.ID)
+	ok, err := m.KVGet(key, nil)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf("mandate: %x already exists", mandate.ID)
+	}
+	if err := m.KVPut(key, mandate); err != nil {
+		return err
+	}
+	if err := m.KVAppend(mandateIndexKey(mandatePayerPrefix, mandate.Payer), mandate.ID[:]); err != nil {
+		return err
+	}
+	return m.KVAppend(mandateIndexKey(mandateMerchantPrefix, mandate.Merchant), mandate.ID[:])
+}
+
+// PutMandate overwrites an existing mandate record.
+func (m *Manager) PutMandate(mandate *StoredMandate) error {
+	if mandate == nil {
+		return fmt.Errorf("mandate: record must not be nil")
+	}
+	key := mandateRecordKey(mandate.ID)
+	ok, err := m.KVGet(key, nil)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMandateNotFound
+	}
+	return m.KVPut(key, mandate)
+}
+
+// GetMandate loads a mandate by ID.
+func (m *Manager) GetMandate(id [32]byte) (*StoredMandate, bool, error) {
+	var stored StoredMandate
+	ok, err := m.KVGet(mandateRecordKey(id), &stored)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	if stored.MaxAmount == nil {
+		stored.MaxAmount = big.NewInt(0)
+	}
+	if stored.PulledTotal == nil {
+		stored.PulledTotal = big.NewInt(0)
+	}
+	stored.Asset = strings.ToUpper(stored.Asset)
+	return &stored, true, nil
+}
+
+// ListMandatesByPayer returns the mandates signed by payer in creation order.
+func (m *Manager) ListMandatesByPayer(payer [20]byte) ([]*StoredMandate, error) {
+	return m.listMandates(mandateIndexKey(mandatePayerPrefix, payer))
+}
+
+// ListMandatesByMerchant returns the mandates granted to merchant in creation
+// order.
+func (m *Manager) ListMandatesByMerchant(merchant [20]byte) ([]*StoredMandate, error) {
+	return m.listMandates(mandateIndexKey(mandateMerchantPrefix, merchant))
+}
+
+func (m *Manager) listMandates(indexKey []byte) ([]*StoredMandate, error) {
+	var ids [][]byte
+	if err := m.KVGetList(indexKey, &ids); err != nil {
+		return nil, err
+	}
+	out := make([]*StoredMandate, 0, len(ids))
+	for _, raw := range ids {
+		var id [32]byte
+		copy(id[:], raw)
+		mandate, ok, err := m.GetMandate(id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, mandate)
+		}
+	}
+	return out, nil
+}
+
+// Mandates returns every stored mandate ordered by creation time and ID. The
+// call walks the entire trie and is intended for offline tooling such as
+// genesis export.
+func (m *Manager) Mandates() ([]*StoredMandate, error) {
+	var out []*StoredMandate
+	err := m.trie.ForEach(func(key, value []byte) error {
+		stored := new(StoredMandate)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if !bytes.Equal(key, kvKey(mandateRecordKey(stored.ID))) {
+			return nil
+		}
+		if stored.MaxAmount == nil {
+			stored.MaxAmount = big.NewInt(0)
+		}
+		if stored.PulledTotal == nil {
+			stored.PulledTotal = big.NewInt(0)
+		}
+		stored.Asset = strings.ToUpper(stored.Asset)
+		out = append(out, stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return bytes.Compare(out[i].ID[:], out[j].ID[:]) < 0
+	})
+	return out, nil
+}
@@ -0,0 +1,248 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	recoveryConfigPrefix  = []byte("recovery/config/")
+	recoveryPendingPrefix = []byte("recovery/pending/")
+
+	// ErrRecoveryNotConfigured is returned when an account has no guardian
+	// set.
+	ErrRecoveryNotConfigured = errors.New("recovery: no guardians configured")
+	// ErrRecoveryNotPending is returned when an account has no pending key
+	// rotation.
+	ErrRecoveryNotPending = errors.New("recovery: no pending rotation")
+)
+
+const (
+	// MaxRecoveryGuardians bounds the size of a guardian set.
+	MaxRecoveryGuardians = 16
+	// MinRecoveryDelaySeconds is the shortest delay an owner may choose
+	// between a rotation reaching its threshold and becoming executable.
+	MinRecoveryDelaySeconds = 24 * 60 * 60
+	// MaxRecoveryDelaySeconds is the longest delay an owner may choose.
+	MaxRecoveryDelaySeconds = 90 * 24 * 60 * 60
+)
+
+// RecoveryConfig is the guardian set an account registered for social
+// recovery. Threshold guardians must approve a rotation, which then waits
+// DelaySeconds before it can execute.
+type RecoveryConfig struct {
+	Guardians    [][20]byte
+	Threshold    uint32
+	DelaySeconds uint64
+	UpdatedAt    uint64
+}
+
+// Validate checks the guardian set against owner, which may not guard itself.
+func (c *RecoveryConfig) Validate(owner [20]byte) error {
+	if c == nil {
+		return fmt.Errorf("recovery: nil config")
+	}
+	if len(c.Guardians) == 0 {
+		return fmt.Errorf("recovery: at least one guardian is required")
+	}
+	if len(c.Guardians) > MaxRecoveryGuardians {
+		return fmt.Errorf("recovery: at most %d guardians", MaxRecoveryGuardians)
+	}
+	seen := make(map[[20]byte]struct{}, len(c.Guardians))
+	for _, guardian := range c.Guardians {
+		if guardian == ([20]byte{}) {
+			return fmt.Errorf("recovery: guardian must not be zero")
+		}
+		if guardian == owner {
+			return fmt.Errorf("recovery: an account cannot guard itself")
+		}
+		if _, dup := seen[guardian]; dup {
+			return fmt.Errorf("recovery: duplicate guardian")
+		}
+		seen[guardian] = struct{}{}
+	}
+	if c.Threshold == 0 || int(c.Threshold) > len(c.Guardians) {
+		return fmt.Errorf("recovery: threshold must be between 1 and %d", len(c.Guardians))
+	}
+	if c.DelaySeconds < MinRecoveryDelaySeconds || c.DelaySeconds > MaxRecoveryDelaySeconds {
+		return fmt.Errorf("recovery: delay must be between %d and %d seconds", MinRecoveryDelaySeconds, MaxRecoveryDelaySeconds)
+	}
+	return nil
+}
+
+// IsGuardian reports whether addr belongs to the guardian set.
+func (c *RecoveryConfig) IsGuardian(addr [20]byte) bool {
+	if c == nil {
+		return false
+	}
+	for _, guardian := range c.Guardians {
+		if guardian == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// RecoveryVote is a guardian's approval of rotating an account to
+// NewAddress.
+type RecoveryVote struct {
+	Guardian   [20]byte
+	NewAddress [20]byte
+}
+
+// RecoveryRequest collects guardian approvals for rotating an account. Each
+// guardian backs one new address at a time and approvals are counted per
+// address, so a single guardian cannot pin the rotation to an address of its
+// choosing. NewAddress and ExecutableAt are set once an address reaches the
+// threshold and cleared if guardians move their approvals away again; from
+// then on the owner has until ExecutableAt to cancel.
+type RecoveryRequest struct {
+	Account      [20]byte
+	NewAddress   [20]byte
+	Votes        []RecoveryVote
+	CreatedAt    uint64
+	ExecutableAt uint64
+}
+
+// Vote returns the address guardian currently approves.
+func (r *RecoveryRequest) Vote(guardian [20]byte) ([20]byte, bool) {
+	if r == nil {
+		return [20]byte{}, false
+	}
+	for _, vote := range r.Votes {
+		if vote.Guardian == guardian {
+			return vote.NewAddress, true
+		}
+	}
+	return [20]byte{}, false
+}
+
+// SetVote records guardian's approval of newAddress, replacing any earlier
+// approval by the same guardian.
+func (r *RecoveryRequest) SetVote(guardian, newAddress [20]byte) {
+	for i := range r.Votes {
+		if r.Votes[i].Guardian == guardian {
+			r.Votes[i].NewAddress = newAddress
+			return
+		}
+	}
+	r.Votes = append(r.Votes, RecoveryVote{Guardian: guardian, NewAddress: newAddress})
+}
+
+// Approvals returns the guardians approving newAddress in approval order.
+func (r *RecoveryRequest) Approvals(newAddress [20]byte) [][20]byte {
+	if r == nil {
+		return nil
+	}
+	var guardians [][20]byte
+	for _, vote := range r.Votes {
+		if vote.NewAddress == newAddress {
+			guardians = append(guardians, vote.Guardian)
+		}
+	}
+	return guardians
+}
+
+// Candidates returns the addresses guardians approve, in the order they were
+// first proposed.
+func (r *RecoveryRequest) Candidates() [][20]byte {
+	if r == nil {
+		return nil
+	}
+	var out [][20]byte
+	for _, vote := range r.Votes {
+		seen := false
+		for _, addr := range out {
+			if addr == vote.NewAddress {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			out = append(out, vote.NewAddress)
+		}
+	}
+	return out
+}
+
+func recoveryConfigKey(addr [20]byte) []byte {
+	buf := make([]byte, len(recoveryConfigPrefix)+len(addr))
+	copy(buf, recoveryConfigPrefix)
+	copy(buf[len(recoveryConfigPrefix):], addr[:])
+	return buf
+}
+
+func recoveryPendingKey(addr [20]byte) []byte {
+	buf := make([]byte, len(recoveryPendingPrefix)+len(addr))
+	copy(buf, recoveryPendingPrefix)
+	copy(buf[len(recoveryPendingPrefix):], addr[:])
+	return buf
+}
+
+// RecoveryGetConfig returns the guardian set registered for addr.
+func (m *Manager) RecoveryGetConfig(addr [20]byte) (*RecoveryConfig, bool, error) {
+	var cfg RecoveryConfig
+	ok, err := m.KVGet(recoveryConfigKey(addr), &cfg)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &cfg, true, nil
+}
+
+// RecoveryPutConfig stores the guardian set for addr. A nil config removes it.
+func (m *Manager) RecoveryPutConfig(addr [20]byte, cfg *RecoveryConfig) error {
+	if cfg == nil {
+		return m.KVDelete(recoveryConfigKey(addr))
+	}
+	return m.KVPut(recoveryConfigKey(addr), cfg)
+}
+
+// RecoveryGetPending returns the pending rotation for addr, if any.
+func (m *Manager) RecoveryGetPending(addr [20]byte) (*RecoveryRequest, bool, error) {
+	var req RecoveryRequest
+	ok, err := m.KVGet(recoveryPendingKey(addr), &req)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &req, true, nil
+}
+
+// RecoveryPutPending stores the pending rotation for its account.
+func (m *Manager) RecoveryPutPending(req *RecoveryRequest) error {
+	if req == nil {
+		return fmt.Errorf("recovery: nil request")
+	}
+	return m.KVPut(recoveryPendingKey(req.Account), req)
+}
+
+// RecoveryDeletePending removes the pending rotation for addr.
+func (m *Manager) RecoveryDeletePending(addr [20]byte) error {
+	return m.KVDelete(recoveryPendingKey(addr))
+}
+
+// RecoveryPendingList returns every pending rotation ordered by account. The
+// call walks the entire trie and is intended for offline tooling such as
+// genesis export. Guardian sets are keyed by account alone and are read with
+// RecoveryGetConfig.
+func (m *Manager) RecoveryPendingList() ([]*RecoveryRequest, error) {
+	var out []*RecoveryRequest
+	err := m.trie.ForEach(func(key, value []byte) error {
+		stored := new(RecoveryRequest)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if bytes.Equal(key, kvKey(recoveryPendingKey(stored.Account))) {
+			out = append(out, stored)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Account[:], out[j].Account[:]) < 0 })
+	return out, nil
+}
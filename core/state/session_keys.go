@@ -0,0 +1,273 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	sessionKeyPrefix      = []byte("session/key/")
+	sessionKeyIndexPrefix = []byte("session/account/")
+
+	// ErrSessionKeyNotFound is returned when an address is not registered as
+	// a session key.
+	ErrSessionKeyNotFound = errors.New("session: key not found")
+	// ErrSessionKeyLimit is returned when an account already has
+	// MaxSessionKeysPerAccount session keys.
+	ErrSessionKeyLimit = errors.New("session: key limit reached")
+)
+
+// MaxSessionKeysPerAccount bounds how many session keys one account may hold.
+const MaxSessionKeysPerAccount = 32
+
+// SessionKey is a key allowed to sign a restricted set of transactions on
+// behalf of Account until ExpiresAt. Daily limits of zero forbid spending the
+// asset. An empty Counterparties list allows any counterparty.
+type SessionKey struct {
+	Key            [20]byte
+	Account        [20]byte
+	TxTypes        []uint8
+	DailyLimitNHB  *big.Int
+	DailyLimitZNHB *big.Int
+	Counterparties [][20]byte
+	ExpiresAt      uint64
+	CreatedAt      uint64
+
+	// SpentDay is the UTC day (YYYY-MM-DD) the spend meters refer to.
+	SpentDay  string
+	SpentNHB  *big.Int
+	SpentZNHB *big.Int
+}
+
+// AllowsType reports whether the key may sign transactions of type t.
+func (k *SessionKey) AllowsType(t uint8) bool {
+	if k == nil {
+		return false
+	}
+	for _, allowed := range k.TxTypes {
+		if allowed == t {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsCounterparty reports whether the key may transact with addr.
+func (k *SessionKey) AllowsCounterparty(addr [20]byte) bool {
+	if k == nil {
+		return false
+	}
+	if len(k.Counterparties) == 0 {
+		return true
+	}
+	for _, allowed := range k.Counterparties {
+		if allowed == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Spent returns the amount of asset spent on day. Meters for earlier days
+// read as zero.
+func (k *SessionKey) Spent(asset, day string) *big.Int {
+	if k == nil || k.SpentDay != day {
+		return big.NewInt(0)
+	}
+	var spent *big.Int
+	switch asset {
+	case "NHB":
+		spent = k.SpentNHB
+	case "ZNHB":
+		spent = k.SpentZNHB
+	}
+	if spent == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(spent)
+}
+
+// DailyLimit returns the per-day cap for asset.
+func (k *SessionKey) DailyLimit(asset string) *big.Int {
+	var limit *big.Int
+	if k != nil {
+		switch asset {
+		case "NHB":
+			limit = k.DailyLimitNHB
+		case "ZNHB":
+			limit = k.DailyLimitZNHB
+		}
+	}
+	if limit == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(limit)
+}
+
+// RecordSpend adds amount of asset to the meter for day, resetting both
+// meters when the day has rolled over.
+func (k *SessionKey) RecordSpend(asset, day string, amount *big.Int) {
+	if k == nil || amount == nil || amount.Sign() <= 0 {
+		return
+	}
+	if k.SpentDay != day {
+		k.SpentDay = day
+		k.SpentNHB = big.NewInt(0)
+		k.SpentZNHB = big.NewInt(0)
+	}
+	switch asset {
+	case "NHB":
+		k.SpentNHB = new(big.Int).Add(k.Spent(asset, day), amount)
+	case "ZNHB":
+		k.SpentZNHB = new(big.Int).Add(k.Spent(asset, day), amount)
+	}
+}
+
+func sessionKeyKey(key [20]byte) []byte {
+	buf := make([]byte, len(sessionKeyPrefix)+len(key))
+	copy(buf, sessionKeyPrefix)
+	copy(buf[len(sessionKeyPrefix):], key[:])
+	return buf
+}
+
+func sessionKeyIndexKey(account [20]byte) []byte {
+	buf := make([]byte, len(sessionKeyIndexPrefix)+len(account))
+	copy(buf, sessionKeyIndexPrefix)
+	copy(buf[len(sessionKeyIndexPrefix):], account[:])
+	return buf
+}
+
+// SessionKeyGet returns the session key registered under key.
+func (m *Manager) SessionKeyGet(key [20]byte) (*SessionKey, bool, error) {
+	var stored SessionKey
+	ok, err := m.KVGet(sessionKeyKey(key), &stored)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &stored, true, nil
+}
+
+// SessionKeyPut creates or updates a session key and indexes it under its
+// account. Callers validate the policy; this enforces that a key belongs to a
+// single account and the per-account limit.
+func (m *Manager) SessionKeyPut(key *SessionKey) error {
+	if key == nil {
+		return fmt.Errorf("session: nil key")
+	}
+	existing, found, err := m.SessionKeyGet(key.Key)
+	if err != nil {
+		return err
+	}
+	if found && existing.Account != key.Account {
+		return fmt.Errorf("session: key is registered to another account")
+	}
+	if !found {
+		keys, err := m.sessionKeyIndex(key.Account)
+		if err != nil {
+			return err
+		}
+		if len(keys) >= MaxSessionKeysPerAccount {
+			return ErrSessionKeyLimit
+		}
+		if err := m.KVPut(sessionKeyIndexKey(key.Account), append(keys, key.Key)); err != nil {
+			return err
+		}
+	}
+	return m.KVPut(sessionKeyKey(key.Key), key)
+}
+
+// SessionKeyDelete removes a session key and its index entry.
+func (m *Manager) SessionKeyDelete(key [20]byte) error {
+	existing, found, err := m.SessionKeyGet(key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrSessionKeyNotFound
+	}
+	keys, err := m.sessionKeyIndex(existing.Account)
+	if err != nil {
+		return err
+	}
+	remaining := keys[:0]
+	for _, candidate := range keys {
+		if candidate != key {
+			remaining = append(remaining, candidate)
+		}
+	}
+	if len(remaining) == 0 {
+		if err := m.KVDelete(sessionKeyIndexKey(existing.Account)); err != nil {
+			return err
+		}
+	} else if err := m.KVPut(sessionKeyIndexKey(existing.Account), remaining); err != nil {
+		return err
+	}
+	return m.KVDelete(sessionKeyKey(key))
+}
+
+// SessionKeysByAccount returns the session keys of account in registration
+// order.
+func (m *Manager) SessionKeysByAccount(account [20]byte) ([]*SessionKey, error) {
+	keys, err := m.sessionKeyIndex(account)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*SessionKey, 0, len(keys))
+	for _, key := range keys {
+		record, ok, err := m.SessionKeyGet(key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, record)
+		}
+	}
+	return out, nil
+}
+
+func (m *Manager) sessionKeyIndex(account [20]byte) ([][20]byte, error) {
+	var keys [][20]byte
+	if _, err := m.KVGet(sessionKeyIndexKey(account), &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SessionKeys returns every registered session key grouped by account in
+// registration order. Keys are only indexed per account, so the call walks
+// the entire trie and is intended for offline tooling such as genesis export.
+func (m *Manager) SessionKeys() ([]*SessionKey, error) {
+	accounts := make(map[[20]byte]struct{})
+	err := m.trie.ForEach(func(key, value []byte) error {
+		stored := new(SessionKey)
+		if err := rlp.DecodeBytes(value, stored); err != nil {
+			return nil
+		}
+		if bytes.Equal(key, kvKey(sessionKeyKey(stored.Key))) {
+			accounts[stored.Account] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([][20]byte, 0, len(accounts))
+	for account := range accounts {
+		ordered = append(ordered, account)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return bytes.Compare(ordered[i][:], ordered[j][:]) < 0 })
+	var out []*SessionKey
+	for _, account := range ordered {
+		keys, err := m.SessionKeysByAccount(account)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, keys...)
+	}
+	return out, nil
+}
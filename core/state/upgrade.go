@@ -0,0 +1,84 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"nhbchain/native/governance"
+)
+
+var (
+	upgradePlanKey       = ethcrypto.Keccak256([]byte("upgrade/plan"))
+	upgradeAppliedPrefix = []byte("upgrade/applied/")
+)
+
+func upgradeAppliedKey(name string) []byte {
+	return ethcrypto.Keccak256(append(append([]byte(nil), upgradeAppliedPrefix...), strings.TrimSpace(name)...))
+}
+
+// UpgradeSchedule stores the pending software upgrade plan, replacing any
+// plan that has not been applied yet.
+func (m *Manager) UpgradeSchedule(plan *governance.UpgradePlan) error {
+	if plan == nil {
+		return fmt.Errorf("upgrade: plan must not be nil")
+	}
+	if strings.TrimSpace(plan.Name) == "" {
+		return fmt.Errorf("upgrade: plan name required")
+	}
+	if plan.Height == 0 {
+		return fmt.Errorf("upgrade: plan height required")
+	}
+	return m.KVPut(upgradePlanKey, plan)
+}
+
+// UpgradePlan returns the pending software upgrade plan, if any.
+func (m *Manager) UpgradePlan() (*governance.UpgradePlan, bool, error) {
+	var plan governance.UpgradePlan
+	ok, err := m.KVGet(upgradePlanKey, &plan)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	return &plan, true, nil
+}
+
+// UpgradeClearPlan removes the pending software upgrade plan.
+func (m *Manager) UpgradeClearPlan() error {
+	return m.KVDelete(upgradePlanKey)
+}
+
+// UpgradeApplied reports whether the named upgrade has already run.
+func (m *Manager) UpgradeApplied(name string) (bool, error) {
+	_, ok, err := m.UpgradeAppliedHeight(name)
+	return ok, err
+}
+
+// UpgradeAppliedHeight returns the chain height at which the named upgrade's
+// migration ran.
+func (m *Manager) UpgradeAppliedHeight(name string) (uint64, bool, error) {
+	if strings.TrimSpace(name) == "" {
+		return 0, false, fmt.Errorf("upgrade: name required")
+	}
+	var height uint64
+	ok, err := m.KVGet(upgradeAppliedKey(name), &height)
+	if err != nil {
+		return 0, false, err
+	}
+	return height, ok, nil
+}
+
+// UpgradeMarkApplied records that the named upgrade's migration ran at
+// height and clears the pending plan.
+func (m *Manager) UpgradeMarkApplied(name string, height uint64) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("upgrade: name required")
+	}
+	if err := m.KVPut(upgradeAppliedKey(name), height); err != nil {
+		return err
+	}
+	return m.UpgradeClearPlan()
+}
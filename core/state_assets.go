@@ -0,0 +1,265 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+)
+
+// roleAssetIssuer is the role allowed to create issued assets.
+const roleAssetIssuer = "ROLE_ASSET_ISSUER"
+
+// ErrAssetUnauthorized is returned when the sender is not the authority an
+// asset operation requires.
+var ErrAssetUnauthorized = errors.New("asset: sender is not authorized")
+
+// assetCreatePayload is the RLP payload carried by TxTypeCreateAsset. A zero
+// FreezeAuthority creates an asset whose accounts can never be frozen; a zero
+// or nil SupplyCap leaves the supply uncapped.
+type assetCreatePayload struct {
+	Symbol          string
+	Name            string
+	Decimals        uint8
+	MintAuthority   [20]byte
+	FreezeAuthority [20]byte
+	SupplyCap       *big.Int
+}
+
+// assetMintPayload is the RLP payload carried by TxTypeMintAsset.
+type assetMintPayload struct {
+	Symbol string
+	To     [20]byte
+	Amount *big.Int
+}
+
+// assetBurnPayload is the RLP payload carried by TxTypeBurnAsset.
+type assetBurnPayload struct {
+	Symbol string
+	Amount *big.Int
+}
+
+// assetFreezePayload is the RLP payload carried by TxTypeFreezeAsset.
+type assetFreezePayload struct {
+	Symbol  string
+	Account [20]byte
+	Frozen  bool
+}
+
+// assetTransferPayload is the RLP payload carried by TxTypeTransferAsset.
+type assetTransferPayload struct {
+	Symbol string
+	To     [20]byte
+	Amount *big.Int
+}
+
+// applyCreateAsset registers a new issued asset. Only holders of
+// ROLE_ASSET_ISSUER may create assets; the sender is recorded as the issuer.
+func (sp *StateProcessor) applyCreateAsset(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload assetCreatePayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("createAsset: decode payload: %w", err)
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	if !manager.HasRole(roleAssetIssuer, sender) {
+		return fmt.Errorf("createAsset: %w", ErrAssetUnauthorized)
+	}
+	if payload.MintAuthority == ([20]byte{}) {
+		return fmt.Errorf("createAsset: mint authority required")
+	}
+	meta := &nhbstate.TokenMetadata{
+		Symbol:        payload.Symbol,
+		Name:          payload.Name,
+		Decimals:      payload.Decimals,
+		MintAuthority: payload.MintAuthority[:],
+		SupplyCap:     payload.SupplyCap,
+		Issuer:        sender,
+	}
+	if payload.FreezeAuthority != ([20]byte{}) {
+		meta.FreezeAuthority = payload.FreezeAuthority[:]
+	}
+	if err := manager.CreateAsset(meta); err != nil {
+		return fmt.Errorf("createAsset: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("createAsset: persist account: %w", err)
+	}
+	var issuer [20]byte
+	copy(issuer[:], sender)
+	sp.AppendEvent(events.AssetCreated{
+		Symbol:          strings.ToUpper(strings.TrimSpace(payload.Symbol)),
+		Name:            strings.TrimSpace(payload.Name),
+		Decimals:        payload.Decimals,
+		Issuer:          issuer,
+		MintAuthority:   payload.MintAuthority,
+		FreezeAuthority: payload.FreezeAuthority,
+		SupplyCap:       nonNilAmount(payload.SupplyCap),
+	}.Event())
+	return nil
+}
+
+// applyMintAsset issues new units of an asset. The sender must be the asset's
+// mint authority and minting must not be paused.
+func (sp *StateProcessor) applyMintAsset(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload assetMintPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("mintAsset: decode payload: %w", err)
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	meta, err := sp.issuedAssetFor(manager, payload.Symbol)
+	if err != nil {
+		return fmt.Errorf("mintAsset: %w", err)
+	}
+	if !bytes.Equal(meta.MintAuthority, sender) {
+		return fmt.Errorf("mintAsset: %w", ErrAssetUnauthorized)
+	}
+	if meta.MintPaused {
+		return fmt.Errorf("mintAsset: %w", ErrMintPaused)
+	}
+	if payload.To == ([20]byte{}) {
+		return fmt.Errorf("mintAsset: recipient required")
+	}
+	total, err := manager.MintAsset(meta.Symbol, payload.To[:], payload.Amount)
+	if err != nil {
+		return fmt.Errorf("mintAsset: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("mintAsset: persist account: %w", err)
+	}
+	sp.AppendEvent(events.AssetMinted{Symbol: meta.Symbol, To: payload.To, Amount: payload.Amount}.Event())
+	sp.recordTokenSupplyChange(meta.Symbol, payload.Amount, total, events.SupplyReasonMint)
+	return nil
+}
+
+// applyBurnAsset destroys units of an issued asset held by the sender.
+func (sp *StateProcessor) applyBurnAsset(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload assetBurnPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("burnAsset: decode payload: %w", err)
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	meta, err := sp.issuedAssetFor(manager, payload.Symbol)
+	if err != nil {
+		return fmt.Errorf("burnAsset: %w", err)
+	}
+	total, err := manager.BurnAsset(meta.Symbol, sender, payload.Amount)
+	if err != nil {
+		return fmt.Errorf("burnAsset: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("burnAsset: persist account: %w", err)
+	}
+	var from [20]byte
+	copy(from[:], sender)
+	sp.AppendEvent(events.AssetBurned{Symbol: meta.Symbol, From: from, Amount: payload.Amount}.Event())
+	sp.recordTokenSupplyChange(meta.Symbol, new(big.Int).Neg(payload.Amount), total, events.SupplyReasonBurn)
+	return nil
+}
+
+// applyFreezeAsset freezes or unfreezes one account for an asset. Only the
+// asset's freeze authority may do so.
+func (sp *StateProcessor) applyFreezeAsset(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload assetFreezePayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("freezeAsset: decode payload: %w", err)
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	meta, err := sp.issuedAssetFor(manager, payload.Symbol)
+	if err != nil {
+		return fmt.Errorf("freezeAsset: %w", err)
+	}
+	if len(meta.FreezeAuthority) == 0 || !bytes.Equal(meta.FreezeAuthority, sender) {
+		return fmt.Errorf("freezeAsset: %w", ErrAssetUnauthorized)
+	}
+	if err := manager.SetAssetFrozen(meta.Symbol, payload.Account[:], payload.Frozen); err != nil {
+		return fmt.Errorf("freezeAsset: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("freezeAsset: persist account: %w", err)
+	}
+	sp.AppendEvent(events.AssetFreeze{Symbol: meta.Symbol, Account: payload.Account, Frozen: payload.Frozen}.Event())
+	return nil
+}
+
+// applyTransferAsset moves any registered asset named in the payload from the
+// sender to the recipient.
+func (sp *StateProcessor) applyTransferAsset(tx *types.Transaction, sender []byte) error {
+	var payload assetTransferPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("transferAsset: decode payload: %w", err)
+	}
+	if payload.To == ([20]byte{}) {
+		return fmt.Errorf("transferAsset: recipient required")
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(payload.Symbol))
+	manager := nhbstate.NewManager(sp.Trie)
+	if err := manager.AssetTransfer(sender, payload.To[:], symbol, payload.Amount); err != nil {
+		return fmt.Errorf("transferAsset: %w", err)
+	}
+	// NHB and ZNHB transfers rewrite the sender account, so the nonce is
+	// bumped on a fresh copy rather than on senderAccount.
+	if err := sp.incrementNativeAccountNonce(sender); err != nil {
+		return fmt.Errorf("transferAsset: persist account: %w", err)
+	}
+	var from [20]byte
+	copy(from[:], sender)
+	sp.AppendEvent(events.AssetTransferred{Symbol: symbol, From: from, To: payload.To, Amount: payload.Amount}.Event())
+	return nil
+}
+
+func (sp *StateProcessor) issuedAssetFor(manager *nhbstate.Manager, symbol string) (*nhbstate.TokenMetadata, error) {
+	meta, err := manager.Token(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.IsIssuedAsset() {
+		return nil, fmt.Errorf("%w: %s", nhbstate.ErrAssetNotFound, strings.ToUpper(strings.TrimSpace(symbol)))
+	}
+	return meta, nil
+}
+
+// CheckAssetSupplyInvariant asserts that every issued asset's recorded supply
+// equals the units minted minus the units burned, that it matches the running
+// total of the balances held by every holder, module vaults included, and
+// that it stays within its cap. Like CheckZNHBSupplyInvariant it runs every
+// block and a violation halts the block. The work is constant per asset; the
+// full holder walk lives in nhbstate.Manager.AuditAssetHolders.
+func (sp *StateProcessor) CheckAssetSupplyInvariant() error {
+	manager := nhbstate.NewManager(sp.Trie)
+	assets, err := manager.IssuedAssets()
+	if err != nil {
+		return fmt.Errorf("asset: load issued assets: %w", err)
+	}
+	for _, meta := range assets {
+		supply, err := manager.TokenSupply(meta.Symbol)
+		if err != nil {
+			return fmt.Errorf("asset %s: load supply: %w", meta.Symbol, err)
+		}
+		expected := new(big.Int).Sub(nonNilAmount(meta.Minted), nonNilAmount(meta.Burned))
+		if supply.Cmp(expected) != 0 {
+			return fmt.Errorf("asset %s: supply invariant violated -- supply %s, minted %s - burned %s = %s", meta.Symbol, supply, nonNilAmount(meta.Minted), nonNilAmount(meta.Burned), expected)
+		}
+		held, err := manager.AssetHeldTotal(meta.Symbol)
+		if err != nil {
+			return fmt.Errorf("asset %s: load held total: %w", meta.Symbol, err)
+		}
+		if held.Cmp(supply) != 0 {
+			return fmt.Errorf("asset %s: supply invariant violated -- supply %s, but holders hold %s", meta.Symbol, supply, held)
+		}
+		if meta.SupplyCap != nil && meta.SupplyCap.Sign() > 0 && supply.Cmp(meta.SupplyCap) > 0 {
+			return fmt.Errorf("asset %s: supply %s exceeds cap %s", meta.Symbol, supply, meta.SupplyCap)
+		}
+	}
+	return nil
+}
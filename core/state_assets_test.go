@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+)
+
+func encodeAssetPayload(t *testing.T, payload interface{}) []byte {
+	t.Helper()
+	data, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+	return data
+}
+
+func TestAssetTransactionsLifecycle(t *testing.T) {
+	fx := newSessionKeyFixture(t)
+	owner := fx.owner.PubKey().Address().Bytes()
+	var issuer [20]byte
+	copy(issuer[:], owner)
+	holder := [20]byte{0x42}
+	manager := nhbstate.NewManager(fx.sp.Trie)
+
+	create := assetCreatePayload{Symbol: "USDX", Name: "USD Stable", Decimals: 6, MintAuthority: issuer, FreezeAuthority: issuer, SupplyCap: big.NewInt(1_000)}
+	if err := fx.sign(t, fx.owner, &types.Transaction{Type: types.TxTypeCreateAsset, Nonce: 0, Data: encodeAssetPayload(t, create)}); !errors.Is(err, ErrAssetUnauthorized) {
+		t.Fatalf("expected unauthorized create, got %v", err)
+	}
+	if err := manager.SetRole(roleAssetIssuer, owner); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	if err := fx.sign(t, fx.owner, &types.Transaction{Type: types.TxTypeCreateAsset, Nonce: 0, Data: encodeAssetPayload(t, create)}); err != nil {
+		t.Fatalf("create asset: %v", err)
+	}
+
+	mint := assetMintPayload{Symbol: "USDX", To: issuer, Amount: big.NewInt(600)}
+	if err := fx.sign(t, fx.owner, &types.Transaction{Type: types.TxTypeMintAsset, Nonce: 1, Data: encodeAssetPayload(t, mint)}); err != nil {
+		t.Fatalf("mint asset: %v", err)
+	}
+	transfer := assetTransferPayload{Symbol: "USDX", To: holder, Amount: big.NewInt(250)}
+	if err := fx.sign(t, fx.owner, &types.Transaction{Type: types.TxTypeTransferAsset, Nonce: 2, Data: encodeAssetPayload(t, transfer)}); err != nil {
+		t.Fatalf("transfer asset: %v", err)
+	}
+	freeze := assetFreezePayload{Symbol: "USDX", Account: holder, Frozen: true}
+	if err := fx.sign(t, fx.owner, &types.Transaction{Type: types.TxTypeFreezeAsset, Nonce: 3, Data: encodeAssetPayload(t, freeze)}); err != nil {
+		t.Fatalf("freeze asset: %v", err)
+	}
+	if err := manager.AssetTransfer(holder[:], issuer[:], "USDX", big.NewInt(1)); !errors.Is(err, nhbstate.ErrAssetFrozen) {
+		t.Fatalf("expected frozen holder, got %v", err)
+	}
+	burn := assetBurnPayload{Symbol: "USDX", Amount: big.NewInt(100)}
+	if err := fx.sign(t, fx.owner, &types.Transaction{Type: types.TxTypeBurnAsset, Nonce: 4, Data: encodeAssetPayload(t, burn)}); err != nil {
+		t.Fatalf("burn asset: %v", err)
+	}
+	native := assetTransferPayload{Symbol: "znhb", To: holder, Amount: big.NewInt(10)}
+	if err := fx.sign(t, fx.owner, &types.Transaction{Type: types.TxTypeTransferAsset, Nonce: 5, Data: encodeAssetPayload(t, native)}); err != nil {
+		t.Fatalf("transfer ZNHB: %v", err)
+	}
+
+	supply, err := manager.TokenSupply("USDX")
+	if err != nil {
+		t.Fatalf("supply: %v", err)
+	}
+	if supply.Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("unexpected supply %s", supply)
+	}
+	holderZNHB, err := manager.AssetBalance(holder[:], "ZNHB")
+	if err != nil {
+		t.Fatalf("balance: %v", err)
+	}
+	if holderZNHB.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("unexpected ZNHB balance %s", holderZNHB)
+	}
+	account, err := fx.sp.getAccount(owner)
+	if err != nil {
+		t.Fatalf("load owner: %v", err)
+	}
+	if account.Nonce != 6 {
+		t.Fatalf("unexpected owner nonce %d", account.Nonce)
+	}
+	if err := fx.sp.CheckAssetSupplyInvariant(); err != nil {
+		t.Fatalf("supply invariant: %v", err)
+	}
+	if held, err := manager.AuditAssetHolders("USDX"); err != nil || held.Cmp(supply) != 0 {
+		t.Fatalf("holder audit: held=%v err=%v", held, err)
+	}
+
+	// A balance credited outside mint breaks the invariant even though the
+	// minted and burned counters still agree with the supply.
+	if err := manager.SetBalance(holder[:], "USDX", big.NewInt(1_000)); err != nil {
+		t.Fatalf("set balance: %v", err)
+	}
+	if err := fx.sp.CheckAssetSupplyInvariant(); err == nil {
+		t.Fatalf("expected a balance outside the supply to violate the invariant")
+	}
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+)
+
+// maxEngagementDeviceIDLength bounds the device identifiers stored on chain.
+const maxEngagementDeviceIDLength = 128
+
+// engagementDeviceRegisterPayload is the RLP payload carried by
+// TxTypeRegisterDevice. The node that issued the bearer token submits only
+// its SHA-256 digest.
+type engagementDeviceRegisterPayload struct {
+	DeviceID  string
+	TokenHash [32]byte
+}
+
+// engagementDeviceRevokePayload is the RLP payload carried by
+// TxTypeRevokeDevice.
+type engagementDeviceRevokePayload struct {
+	DeviceID string
+}
+
+// EncodeEngagementDeviceRegistration returns the TxTypeRegisterDevice payload.
+func EncodeEngagementDeviceRegistration(deviceID string, tokenHash [32]byte) ([]byte, error) {
+	return rlp.EncodeToBytes(engagementDeviceRegisterPayload{DeviceID: deviceID, TokenHash: tokenHash})
+}
+
+// EncodeEngagementDeviceRevocation returns the TxTypeRevokeDevice payload.
+func EncodeEngagementDeviceRevocation(deviceID string) ([]byte, error) {
+	return rlp.EncodeToBytes(engagementDeviceRevokePayload{DeviceID: deviceID})
+}
+
+// applyRegisterDevice registers a heartbeat device under the sender, or
+// rotates the token hash of a device the sender already owns. New devices
+// count against engagement.Config.MaxDevicesPerAccount.
+func (sp *StateProcessor) applyRegisterDevice(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload engagementDeviceRegisterPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("registerDevice: decode payload: %w", err)
+	}
+	deviceID := strings.TrimSpace(payload.DeviceID)
+	if deviceID == "" || deviceID != payload.DeviceID || len(deviceID) > maxEngagementDeviceIDLength {
+		return fmt.Errorf("registerDevice: device id must be 1-%d characters without surrounding spaces", maxEngagementDeviceIDLength)
+	}
+	if payload.TokenHash == ([32]byte{}) {
+		return fmt.Errorf("registerDevice: token hash required")
+	}
+	var owner [20]byte
+	copy(owner[:], sender)
+	manager := nhbstate.NewManager(sp.Trie)
+	device := &nhbstate.EngagementDevice{
+		DeviceID:     deviceID,
+		Owner:        owner,
+		TokenHash:    payload.TokenHash,
+		RegisteredAt: uint64(sp.blockTimestamp().Unix()),
+	}
+	if existing, ok, err := manager.EngagementDeviceGet(deviceID); err != nil {
+		return fmt.Errorf("registerDevice: %w", err)
+	} else if ok {
+		device.RegisteredAt = existing.RegisteredAt
+		device.LastHeartbeat = existing.LastHeartbeat
+	}
+	if err := manager.EngagementDevicePut(device, sp.engagementConfig.MaxDevicesPerAccount); err != nil {
+		return fmt.Errorf("registerDevice: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("registerDevice: persist account: %w", err)
+	}
+	sp.AppendEvent(events.EngagementDeviceRegistered{Address: owner, DeviceID: deviceID}.Event())
+	return nil
+}
+
+// applyRevokeDevice removes one of the sender's heartbeat devices and frees
+// its slot.
+func (sp *StateProcessor) applyRevokeDevice(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload engagementDeviceRevokePayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("revokeDevice: decode payload: %w", err)
+	}
+	var owner [20]byte
+	copy(owner[:], sender)
+	manager := nhbstate.NewManager(sp.Trie)
+	device, ok, err := manager.EngagementDeviceGet(payload.DeviceID)
+	if err != nil {
+		return fmt.Errorf("revokeDevice: %w", err)
+	}
+	if !ok || device.Owner != owner {
+		return fmt.Errorf("revokeDevice: %w", nhbstate.ErrEngagementDeviceNotFound)
+	}
+	if err := manager.EngagementDeviceDelete(device.DeviceID); err != nil {
+		return fmt.Errorf("revokeDevice: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("revokeDevice: persist account: %w", err)
+	}
+	sp.AppendEvent(events.EngagementDeviceRevoked{Address: owner, DeviceID: device.DeviceID}.Event())
+	return nil
+}
+
+// stampEngagementDevice records a heartbeat against the registered device it
+// names. Heartbeats may not name a device registered to another address.
+// Device IDs that are not registered are accepted unchanged.
+func (sp *StateProcessor) stampEngagementDevice(sender []byte, deviceID string, timestamp int64) error {
+	manager := nhbstate.NewManager(sp.Trie)
+	device, ok, err := manager.EngagementDeviceGet(deviceID)
+	if err != nil || !ok {
+		return err
+	}
+	var owner [20]byte
+	copy(owner[:], sender)
+	if device.Owner != owner {
+		return fmt.Errorf("heartbeat: device %q is registered to another address", deviceID)
+	}
+	device.LastHeartbeat = uint64(timestamp)
+	return manager.EngagementDevicePut(device, 0)
+}
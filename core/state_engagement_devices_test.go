@@ -0,0 +1,122 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+func TestEngagementDeviceRegistryEnforcesLimitOnChain(t *testing.T) {
+	sp := newStakingStateProcessor(t)
+	now := time.Unix(1_700_000_000, 0).UTC()
+	sp.nowFunc = func() time.Time { return now }
+	sp.BeginBlock(1, now)
+	t.Cleanup(func() { sp.EndBlock() })
+	sp.engagementConfig.MaxDevicesPerAccount = 2
+
+	var owner, other *crypto.PrivateKey
+	for _, key := range []**crypto.PrivateKey{&owner, &other} {
+		priv, err := crypto.GeneratePrivateKey()
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		*key = priv
+		if err := sp.setAccount(priv.PubKey().Address().Bytes(), &types.Account{
+			BalanceNHB: big.NewInt(10_000), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0),
+		}); err != nil {
+			t.Fatalf("seed account: %v", err)
+		}
+	}
+	apply := func(key *crypto.PrivateKey, txType types.TxType, data []byte) error {
+		t.Helper()
+		account, err := sp.getAccount(key.PubKey().Address().Bytes())
+		if err != nil {
+			t.Fatalf("load account: %v", err)
+		}
+		tx := &types.Transaction{ChainID: types.NHBChainID(), Type: txType, Nonce: account.Nonce, Data: data, GasLimit: 25_000, GasPrice: big.NewInt(1)}
+		if err := tx.Sign(key.PrivateKey); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return sp.ApplyTransaction(tx)
+	}
+	register := func(key *crypto.PrivateKey, deviceID string, hash byte) error {
+		t.Helper()
+		data, err := EncodeEngagementDeviceRegistration(deviceID, [32]byte{hash})
+		if err != nil {
+			t.Fatalf("encode registration: %v", err)
+		}
+		return apply(key, types.TxTypeRegisterDevice, data)
+	}
+	heartbeat := func(key *crypto.PrivateKey, deviceID string, ts int64) error {
+		t.Helper()
+		data, err := json.Marshal(types.HeartbeatPayload{DeviceID: deviceID, Timestamp: ts})
+		if err != nil {
+			t.Fatalf("encode heartbeat: %v", err)
+		}
+		return apply(key, types.TxTypeHeartbeat, data)
+	}
+
+	if err := register(owner, "device-a", 0x01); err != nil {
+		t.Fatalf("register device-a: %v", err)
+	}
+	if err := register(owner, "device-b", 0x02); err != nil {
+		t.Fatalf("register device-b: %v", err)
+	}
+	if err := register(owner, "device-c", 0x03); !errors.Is(err, nhbstate.ErrEngagementDeviceLimit) {
+		t.Fatalf("expected device limit error, got %v", err)
+	}
+	if err := register(owner, "device-a", 0x04); err != nil {
+		t.Fatalf("rotate device-a: %v", err)
+	}
+	if err := register(other, "device-a", 0x05); err == nil {
+		t.Fatalf("expected error registering another owner's device")
+	}
+
+	manager := nhbstate.NewManager(sp.Trie)
+	device, ok, err := manager.EngagementDeviceGet("device-a")
+	if err != nil || !ok {
+		t.Fatalf("load device-a: ok=%v err=%v", ok, err)
+	}
+	if device.TokenHash != ([32]byte{0x04}) || device.RegisteredAt != uint64(now.Unix()) {
+		t.Fatalf("unexpected device-a record: %+v", device)
+	}
+
+	if err := heartbeat(other, "device-b", now.Unix()); err == nil {
+		t.Fatalf("expected heartbeat naming another owner's device to fail")
+	}
+	if err := heartbeat(owner, "device-b", now.Unix()); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if device, _, _ := manager.EngagementDeviceGet("device-b"); device.LastHeartbeat != uint64(now.Unix()) {
+		t.Fatalf("heartbeat not stamped on device-b: %+v", device)
+	}
+
+	revoke, err := EncodeEngagementDeviceRevocation("device-a")
+	if err != nil {
+		t.Fatalf("encode revocation: %v", err)
+	}
+	if err := apply(other, types.TxTypeRevokeDevice, revoke); !errors.Is(err, nhbstate.ErrEngagementDeviceNotFound) {
+		t.Fatalf("expected not found revoking another owner's device, got %v", err)
+	}
+	if err := apply(owner, types.TxTypeRevokeDevice, revoke); err != nil {
+		t.Fatalf("revoke device-a: %v", err)
+	}
+	if err := register(owner, "device-c", 0x03); err != nil {
+		t.Fatalf("revocation should free a device slot: %v", err)
+	}
+	var ownerAddr [20]byte
+	copy(ownerAddr[:], owner.PubKey().Address().Bytes())
+	devices, err := manager.EngagementDevicesByOwner(ownerAddr)
+	if err != nil {
+		t.Fatalf("list devices: %v", err)
+	}
+	if len(devices) != 2 || devices[0].DeviceID != "device-b" || devices[1].DeviceID != "device-c" {
+		t.Fatalf("unexpected devices: %+v", devices)
+	}
+}
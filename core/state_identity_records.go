@@ -0,0 +1,81 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	"nhbchain/core/identity"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+)
+
+// identityRecordsPayload is the RLP payload carried by
+// TxTypeSetIdentityRecords. Deletes apply before Set, so a key listed in both
+// ends up with the value from Set.
+type identityRecordsPayload struct {
+	Alias  string
+	Set    []identity.TextRecord
+	Delete []string
+}
+
+// applySetIdentityRecords updates the text records of an alias owned by the
+// sender. Records are validated per key and bounded in count and total size.
+// One event is emitted for each record that changes.
+func (sp *StateProcessor) applySetIdentityRecords(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload identityRecordsPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("identityRecords: decode payload: %w", err)
+	}
+	if len(payload.Set) == 0 && len(payload.Delete) == 0 {
+		return fmt.Errorf("identityRecords: no records to set or delete")
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	now := sp.blockTimestamp().Unix()
+	record, ok := manager.IdentityResolveAt(payload.Alias, sp.identityTerms, now)
+	if !ok || record == nil {
+		return fmt.Errorf("identityRecords: %w", identity.ErrAliasNotFound)
+	}
+	if record.Parent != "" {
+		return fmt.Errorf("identityRecords: sub-aliases carry no records; set them on %s", record.Parent)
+	}
+	owner := record.Owner
+	if owner == ([20]byte{}) {
+		owner = record.Primary
+	}
+	if !bytes.Equal(owner[:], sender) {
+		return fmt.Errorf("identityRecords: %w", identity.ErrNotAliasOwner)
+	}
+	updated, err := identity.ApplyTextRecords(record.Records, payload.Set, payload.Delete)
+	if err != nil {
+		return fmt.Errorf("identityRecords: %w", err)
+	}
+	previous := make(map[string]string, len(record.Records))
+	for _, entry := range record.Records {
+		previous[entry.Key] = entry.Value
+	}
+	stored, err := manager.IdentitySetTextRecords(record.Alias, updated, now)
+	if err != nil {
+		return fmt.Errorf("identityRecords: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("identityRecords: persist owner: %w", err)
+	}
+	for _, entry := range stored.Records {
+		if value, ok := previous[entry.Key]; ok && value == entry.Value {
+			delete(previous, entry.Key)
+			continue
+		}
+		delete(previous, entry.Key)
+		sp.AppendEvent(events.IdentityRecordSet{Alias: stored.Alias, Owner: owner, Key: entry.Key, Value: entry.Value}.Event())
+	}
+	for _, entry := range record.Records {
+		if _, removed := previous[entry.Key]; removed {
+			sp.AppendEvent(events.IdentityRecordDeleted{Alias: stored.Alias, Owner: owner, Key: entry.Key}.Event())
+		}
+	}
+	return nil
+}
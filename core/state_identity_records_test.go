@@ -0,0 +1,171 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	"nhbchain/core/identity"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+func newIdentityRecordsFixture(t *testing.T) (*StateProcessor, *crypto.PrivateKey) {
+	t.Helper()
+	sp := newStakingStateProcessor(t)
+	fixed := time.Unix(1_700_000_000, 0).UTC()
+	sp.nowFunc = func() time.Time { return fixed }
+	sp.BeginBlock(1, fixed)
+	t.Cleanup(func() { sp.EndBlock() })
+
+	owner, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	if err := manager.IdentitySetAlias(owner.PubKey().Address().Bytes(), "merchant"); err != nil {
+		t.Fatalf("set alias: %v", err)
+	}
+	return sp, owner
+}
+
+func applyIdentityRecordsTx(t *testing.T, sp *StateProcessor, key *crypto.PrivateKey, nonce uint64, payload identityRecordsPayload) error {
+	t.Helper()
+	data, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+	tx := &types.Transaction{
+		ChainID:  types.NHBChainID(),
+		Type:     types.TxTypeSetIdentityRecords,
+		Nonce:    nonce,
+		GasLimit: 25_000,
+		GasPrice: big.NewInt(1),
+		Data:     data,
+	}
+	if err := tx.Sign(key.PrivateKey); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return sp.ApplyTransaction(tx)
+}
+
+func TestSetIdentityRecordsLifecycle(t *testing.T) {
+	sp, owner := newIdentityRecordsFixture(t)
+
+	err := applyIdentityRecordsTx(t, sp, owner, 0, identityRecordsPayload{
+		Alias: "merchant",
+		Set: []identity.TextRecord{
+			{Key: "pay.asset", Value: "znhb"},
+			{Key: "Display.Name", Value: "Corner Cafe"},
+			{Key: "pay.webhook", Value: "https://cafe.example/hooks/nhb"},
+			{Key: "contact.email", Value: "0x" + strings.Repeat("AB", 32)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("set records: %v", err)
+	}
+	record, ok := nhbstate.NewManager(sp.Trie).IdentityResolve("merchant")
+	if !ok {
+		t.Fatalf("alias missing")
+	}
+	if len(record.Records) != 4 {
+		t.Fatalf("expected 4 records, got %d", len(record.Records))
+	}
+	if value, _ := record.TextRecordValue("pay.asset"); value != "ZNHB" {
+		t.Fatalf("pay.asset = %q, want ZNHB", value)
+	}
+	if value, _ := record.TextRecordValue("contact.email"); value != "0x"+strings.Repeat("ab", 32) {
+		t.Fatalf("contact hash not normalised: %q", value)
+	}
+	if record.Records[0].Key != "contact.email" {
+		t.Fatalf("records not sorted by key: %+v", record.Records)
+	}
+
+	start := len(sp.Events())
+	err = applyIdentityRecordsTx(t, sp, owner, 1, identityRecordsPayload{
+		Alias:  "merchant",
+		Set:    []identity.TextRecord{{Key: "pay.asset", Value: "NHB"}, {Key: "display.name", Value: "Corner Cafe"}},
+		Delete: []string{"pay.webhook"},
+	})
+	if err != nil {
+		t.Fatalf("update records: %v", err)
+	}
+	emitted := sp.Events()[start:]
+	if len(emitted) != 2 {
+		t.Fatalf("expected one set and one delete event, got %d", len(emitted))
+	}
+	if emitted[0].Type != events.TypeIdentityRecordSet || emitted[0].Attributes["key"] != "pay.asset" || emitted[0].Attributes["value"] != "NHB" {
+		t.Fatalf("unexpected set event: %+v", emitted[0])
+	}
+	if emitted[1].Type != events.TypeIdentityRecordDeleted || emitted[1].Attributes["key"] != "pay.webhook" {
+		t.Fatalf("unexpected delete event: %+v", emitted[1])
+	}
+}
+
+func TestSetIdentityRecordsRejectsInvalidUpdates(t *testing.T) {
+	sp, owner := newIdentityRecordsFixture(t)
+	stranger, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		key     *crypto.PrivateKey
+		payload identityRecordsPayload
+		wantErr error
+	}{
+		{
+			name:    "not owner",
+			key:     stranger,
+			payload: identityRecordsPayload{Alias: "merchant", Set: []identity.TextRecord{{Key: "pay.asset", Value: "NHB"}}},
+			wantErr: identity.ErrNotAliasOwner,
+		},
+		{
+			name:    "unknown key",
+			key:     owner,
+			payload: identityRecordsPayload{Alias: "merchant", Set: []identity.TextRecord{{Key: "twitter", Value: "@cafe"}}},
+			wantErr: identity.ErrInvalidRecord,
+		},
+		{
+			name:    "bad asset",
+			key:     owner,
+			payload: identityRecordsPayload{Alias: "merchant", Set: []identity.TextRecord{{Key: "pay.asset", Value: "BTC"}}},
+			wantErr: identity.ErrInvalidRecord,
+		},
+		{
+			name:    "plain http webhook",
+			key:     owner,
+			payload: identityRecordsPayload{Alias: "merchant", Set: []identity.TextRecord{{Key: "pay.webhook", Value: "http://cafe.example"}}},
+			wantErr: identity.ErrInvalidRecord,
+		},
+		{
+			name:    "too many records",
+			key:     owner,
+			payload: identityRecordsPayload{Alias: "merchant", Set: tooManyTextRecords()},
+			wantErr: identity.ErrRecordLimit,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := applyIdentityRecordsTx(t, sp, tc.key, 0, tc.payload)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func tooManyTextRecords() []identity.TextRecord {
+	records := make([]identity.TextRecord, 0, identity.MaxTextRecords+1)
+	for i := 0; i <= identity.MaxTextRecords; i++ {
+		records = append(records, identity.TextRecord{Key: "x.key" + string(rune('a'+i)), Value: "v"})
+	}
+	return records
+}
@@ -0,0 +1,315 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+)
+
+const (
+	invoiceAssetNHB  = "NHB"
+	invoiceAssetZNHB = "ZNHB"
+)
+
+var (
+	// ErrInvoiceNotPayable is returned when a transfer references an invoice
+	// that is paid, cancelled or expired.
+	ErrInvoiceNotPayable = errors.New("invoice: not payable")
+	// ErrInvoiceMismatch is returned when a transfer referencing an invoice
+	// targets the wrong merchant or asset.
+	ErrInvoiceMismatch = errors.New("invoice: transfer does not match invoice")
+)
+
+// invoiceCreatePayload is the RLP payload carried by TxTypeCreateInvoice.
+type invoiceCreatePayload struct {
+	Asset        string
+	Amount       *big.Int
+	Expiry       uint64
+	MemoHash     [32]byte
+	AllowPartial bool
+}
+
+// invoiceCancelPayload is the RLP payload carried by TxTypeCancelInvoice.
+type invoiceCancelPayload struct {
+	InvoiceID [32]byte
+}
+
+// invoiceSettlement carries a pre-validated invoice from executeTransaction
+// into settleInvoicePayment once the underlying transfer has applied.
+type invoiceSettlement struct {
+	invoice *nhbstate.StoredInvoice
+	payer   [20]byte
+}
+
+// applyCreateInvoice opens a native invoice owned by the signing merchant. The
+// invoice ID is derived from the transaction hash.
+func (sp *StateProcessor) applyCreateInvoice(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload invoiceCreatePayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("createInvoice: decode payload: %w", err)
+	}
+	asset := strings.ToUpper(strings.TrimSpace(payload.Asset))
+	if asset != invoiceAssetNHB && asset != invoiceAssetZNHB {
+		return fmt.Errorf("createInvoice: asset must be NHB or ZNHB")
+	}
+	if payload.Amount == nil || payload.Amount.Sign() <= 0 {
+		return fmt.Errorf("createInvoice: amount must be positive")
+	}
+	now := uint64(sp.blockTimestamp().Unix())
+	if payload.Expiry != 0 && payload.Expiry <= now {
+		return fmt.Errorf("createInvoice: expiry must be in the future")
+	}
+	txHash, err := tx.Hash()
+	if err != nil {
+		return fmt.Errorf("createInvoice: compute tx hash: %w", err)
+	}
+	var merchant [20]byte
+	copy(merchant[:], sender)
+	invoice := &nhbstate.StoredInvoice{
+		ID:           nhbstate.InvoiceID(txHash),
+		Merchant:     merchant,
+		Asset:        asset,
+		Amount:       new(big.Int).Set(payload.Amount),
+		Paid:         big.NewInt(0),
+		MemoHash:     payload.MemoHash,
+		AllowPartial: payload.AllowPartial,
+		Status:       string(nhbstate.InvoiceStatusOpen),
+		CreatedAt:    now,
+		Expiry:       payload.Expiry,
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	if err := manager.CreateInvoice(invoice); err != nil {
+		return fmt.Errorf("createInvoice: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("createInvoice: persist merchant: %w", err)
+	}
+	sp.AppendEvent(events.InvoiceCreated{
+		InvoiceID:    invoice.ID,
+		Merchant:     invoice.Merchant,
+		Asset:        invoice.Asset,
+		Amount:       invoice.Amount,
+		MemoHash:     invoice.MemoHash,
+		AllowPartial: invoice.AllowPartial,
+		Expiry:       invoice.Expiry,
+	}.Event())
+	return nil
+}
+
+// applyCancelInvoice withdraws an invoice that has not received any payment.
+// Partially paid invoices cannot be cancelled because the merchant already
+// holds the payer's funds.
+func (sp *StateProcessor) applyCancelInvoice(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload invoiceCancelPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("cancelInvoice: decode payload: %w", err)
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	invoice, ok, err := manager.GetInvoice(payload.InvoiceID)
+	if err != nil {
+		return fmt.Errorf("cancelInvoice: %w", err)
+	}
+	if !ok {
+		return nhbstate.ErrInvoiceNotFound
+	}
+	if !bytes.Equal(invoice.Merchant[:], sender) {
+		return fmt.Errorf("cancelInvoice: caller is not the invoice merchant")
+	}
+	if nhbstate.InvoiceStatus(invoice.Status) != nhbstate.InvoiceStatusOpen {
+		return fmt.Errorf("cancelInvoice: invoice is %s", invoice.Status)
+	}
+	invoice.Status = string(nhbstate.InvoiceStatusCancelled)
+	invoice.SettledAt = uint64(sp.blockTimestamp().Unix())
+	if err := manager.PutInvoice(invoice); err != nil {
+		return fmt.Errorf("cancelInvoice: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("cancelInvoice: persist merchant: %w", err)
+	}
+	sp.AppendEvent(events.InvoiceCancelled{InvoiceID: invoice.ID, Merchant: invoice.Merchant}.Event())
+	return nil
+}
+
+// prepareInvoicePayment validates a transfer whose IntentRef references an
+// invoice before any balances move. It returns nil when the transaction does
+// not reference an invoice.
+func (sp *StateProcessor) prepareInvoicePayment(tx *types.Transaction, sender []byte) (*invoiceSettlement, error) {
+	if tx == nil || (tx.Type != types.TxTypeTransfer && tx.Type != types.TxTypeTransferZNHB) {
+		return nil, nil
+	}
+	id, ok := nhbstate.ParseInvoiceIntentRef(tx.IntentRef)
+	if !ok {
+		return nil, nil
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	invoice, exists, err := manager.GetInvoice(id)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nhbstate.ErrInvoiceNotFound
+	}
+	if !nhbstate.InvoiceStatus(invoice.Status).Accepting() {
+		return nil, fmt.Errorf("%w: invoice is %s", ErrInvoiceNotPayable, invoice.Status)
+	}
+	if invoice.Expired(uint64(sp.blockTimestamp().Unix())) {
+		return nil, fmt.Errorf("%w: invoice expired", ErrInvoiceNotPayable)
+	}
+	if !bytes.Equal(tx.To, invoice.Merchant[:]) {
+		return nil, fmt.Errorf("%w: recipient is not the invoice merchant", ErrInvoiceMismatch)
+	}
+	asset := invoiceAssetNHB
+	if tx.Type == types.TxTypeTransferZNHB {
+		asset = invoiceAssetZNHB
+	}
+	if asset != invoice.Asset {
+		return nil, fmt.Errorf("%w: invoice is denominated in %s", ErrInvoiceMismatch, invoice.Asset)
+	}
+	if bytes.Equal(sender, invoice.Merchant[:]) {
+		return nil, fmt.Errorf("%w: merchant cannot pay its own invoice", ErrInvoiceMismatch)
+	}
+	settlement := &invoiceSettlement{invoice: invoice}
+	copy(settlement.payer[:], sender)
+	return settlement, nil
+}
+
+// settleInvoicePayment applies the refund rule once the transfer has credited
+// the merchant:
+//
+//   - a payment covering the remaining amount marks the invoice paid and any
+//     excess is refunded to the payer;
+//   - a smaller payment accrues when the invoice allows partial payments;
+//   - otherwise the whole payment is refunded and the invoice stays open.
+func (sp *StateProcessor) settleInvoicePayment(tx *types.Transaction, settlement *invoiceSettlement) error {
+	invoice := settlement.invoice
+	amount := big.NewInt(0)
+	if tx.Value != nil {
+		amount = new(big.Int).Set(tx.Value)
+	}
+	txHash, err := tx.Hash()
+	if err != nil {
+		return fmt.Errorf("invoice: compute tx hash: %w", err)
+	}
+	remaining := invoice.Remaining()
+	applied := amount
+	refund := big.NewInt(0)
+	reason := ""
+	switch {
+	case amount.Cmp(remaining) >= 0:
+		applied = remaining
+		refund = new(big.Int).Sub(amount, remaining)
+		reason = "overpayment"
+	case invoice.AllowPartial:
+	default:
+		applied = big.NewInt(0)
+		refund = amount
+		reason = "underpayment"
+	}
+	if refund.Sign() > 0 {
+		if err := sp.refundInvoicePayment(invoice, settlement.payer, refund); err != nil {
+			return err
+		}
+		sp.AppendEvent(events.InvoiceRefunded{
+			InvoiceID: invoice.ID,
+			Merchant:  invoice.Merchant,
+			Payer:     settlement.payer,
+			Asset:     invoice.Asset,
+			Amount:    refund,
+			Reason:    reason,
+			TxHash:    txHash,
+		}.Event())
+	}
+	if applied.Sign() == 0 {
+		return nil
+	}
+	invoice.Paid = new(big.Int).Add(invoice.Paid, applied)
+	final := invoice.Remaining().Sign() == 0
+	if final {
+		invoice.Status = string(nhbstate.InvoiceStatusPaid)
+		invoice.SettledAt = uint64(sp.blockTimestamp().Unix())
+	} else {
+		invoice.Status = string(nhbstate.InvoiceStatusPartiallyPaid)
+	}
+	if err := nhbstate.NewManager(sp.Trie).PutInvoice(invoice); err != nil {
+		return err
+	}
+	sp.AppendEvent(events.InvoicePayment{
+		InvoiceID: invoice.ID,
+		Merchant:  invoice.Merchant,
+		Payer:     settlement.payer,
+		Asset:     invoice.Asset,
+		Amount:    applied,
+		TotalPaid: invoice.Paid,
+		Final:     final,
+		TxHash:    txHash,
+	}.Event())
+	return nil
+}
+
+func (sp *StateProcessor) refundInvoicePayment(invoice *nhbstate.StoredInvoice, payer [20]byte, amount *big.Int) error {
+	merchantAccount, err := sp.getAccount(invoice.Merchant[:])
+	if err != nil {
+		return err
+	}
+	payerAccount, err := sp.getAccount(payer[:])
+	if err != nil {
+		return err
+	}
+	switch invoice.Asset {
+	case invoiceAssetZNHB:
+		if merchantAccount.BalanceZNHB == nil || merchantAccount.BalanceZNHB.Cmp(amount) < 0 {
+			return fmt.Errorf("invoice: merchant balance insufficient for refund")
+		}
+		merchantAccount.BalanceZNHB = new(big.Int).Sub(merchantAccount.BalanceZNHB, amount)
+		if payerAccount.BalanceZNHB == nil {
+			payerAccount.BalanceZNHB = big.NewInt(0)
+		}
+		payerAccount.BalanceZNHB = new(big.Int).Add(payerAccount.BalanceZNHB, amount)
+	default:
+		if merchantAccount.BalanceNHB == nil || merchantAccount.BalanceNHB.Cmp(amount) < 0 {
+			return fmt.Errorf("invoice: merchant balance insufficient for refund")
+		}
+		merchantAccount.BalanceNHB = new(big.Int).Sub(merchantAccount.BalanceNHB, amount)
+		if payerAccount.BalanceNHB == nil {
+			payerAccount.BalanceNHB = big.NewInt(0)
+		}
+		payerAccount.BalanceNHB = new(big.Int).Add(payerAccount.BalanceNHB, amount)
+	}
+	if err := sp.setAccount(invoice.Merchant[:], merchantAccount); err != nil {
+		return err
+	}
+	return sp.setAccount(payer[:], payerAccount)
+}
+
+// GetInvoice returns the invoice stored under id.
+func (sp *StateProcessor) GetInvoice(id [32]byte) (*nhbstate.StoredInvoice, error) {
+	if sp == nil {
+		return nil, fmt.Errorf("state processor unavailable")
+	}
+	invoice, ok, err := nhbstate.NewManager(sp.Trie).GetInvoice(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nhbstate.ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// ListInvoicesByMerchant returns every invoice opened by merchant.
+func (sp *StateProcessor) ListInvoicesByMerchant(merchant [20]byte) ([]*nhbstate.StoredInvoice, error) {
+	if sp == nil {
+		return nil, fmt.Errorf("state processor unavailable")
+	}
+	return nhbstate.NewManager(sp.Trie).ListInvoicesByMerchant(merchant)
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	nativecommon "nhbchain/native/common"
+	"nhbchain/native/loyalty"
+)
+
+// loyaltyCoalitionSettlePayload is the RLP payload carried by
+// TxTypeLoyaltyCoalitionSettle.
+type loyaltyCoalitionSettlePayload struct {
+	CoalitionID loyalty.CoalitionID
+	Period      uint64
+}
+
+// applyLoyaltyCoalitionSettle net-settles a closed period of a
+// net_settlement coalition. Anyone may submit it once the period has ended.
+// Each transfer moves ZNHB from the debtor program's business paymaster to
+// the creditor's; when a paymaster cannot cover its debt the available
+// balance is paid and the remainder is reported as shortfall. The stored
+// report backs loyalty_coalitionReport.
+func (sp *StateProcessor) applyLoyaltyCoalitionSettle(tx *types.Transaction, sender []byte) error {
+	if err := nativecommon.Guard(sp.pauses, moduleLoyalty); err != nil {
+		return err
+	}
+	var payload loyaltyCoalitionSettlePayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("loyaltyCoalitionSettle: decode payload: %w", err)
+	}
+	registry := loyalty.NewRegistry(nhbstate.NewManager(sp.Trie))
+	coalition, ok, err := registry.Coalition(payload.CoalitionID)
+	if err != nil {
+		return fmt.Errorf("loyaltyCoalitionSettle: %w", err)
+	}
+	if !ok {
+		return loyalty.ErrCoalitionNotFound
+	}
+	if coalition.Shared() {
+		return fmt.Errorf("loyaltyCoalitionSettle: shared_pool coalitions do not settle")
+	}
+	now := uint64(sp.blockTimestamp().Unix())
+	if now < coalition.PeriodEnd(payload.Period) {
+		return loyalty.ErrPeriodOpen
+	}
+	if _, settled, err := registry.CoalitionSettlement(coalition.ID, payload.Period); err != nil {
+		return fmt.Errorf("loyaltyCoalitionSettle: %w", err)
+	} else if settled {
+		return loyalty.ErrPeriodSettled
+	}
+	ledger, err := registry.CoalitionLedger(coalition.ID, payload.Period)
+	if err != nil {
+		return fmt.Errorf("loyaltyCoalitionSettle: %w", err)
+	}
+	transfers := loyalty.ComputeSettlement(ledger)
+
+	// Resolve every paymaster before moving funds so that a lookup failure
+	// leaves the state untouched.
+	paymasters := make(map[loyalty.ProgramID][20]byte)
+	for _, transfer := range transfers {
+		for _, id := range []loyalty.ProgramID{transfer.From, transfer.To} {
+			if _, done := paymasters[id]; done {
+				continue
+			}
+			paymaster, err := sp.coalitionPaymaster(id)
+			if err != nil {
+				return fmt.Errorf("loyaltyCoalitionSettle: %w", err)
+			}
+			paymasters[id] = paymaster
+		}
+	}
+
+	settled := big.NewInt(0)
+	shortfall := big.NewInt(0)
+	for i := range transfers {
+		transfer := &transfers[i]
+		from, to := paymasters[transfer.From], paymasters[transfer.To]
+		switch {
+		case from == ([20]byte{}) || to == ([20]byte{}):
+			// A member without a paymaster cannot pay or be paid.
+		case from == to:
+			transfer.Paid = new(big.Int).Set(transfer.Amount)
+		default:
+			fromAccount, err := sp.getAccount(from[:])
+			if err != nil {
+				return fmt.Errorf("loyaltyCoalitionSettle: load paymaster: %w", err)
+			}
+			if fromAccount.BalanceZNHB == nil {
+				fromAccount.BalanceZNHB = big.NewInt(0)
+			}
+			paid := new(big.Int).Set(transfer.Amount)
+			if fromAccount.BalanceZNHB.Cmp(paid) < 0 {
+				paid.Set(fromAccount.BalanceZNHB)
+			}
+			if paid.Sign() > 0 {
+				fromAccount.BalanceZNHB = new(big.Int).Sub(fromAccount.BalanceZNHB, paid)
+				if err := sp.setAccount(from[:], fromAccount); err != nil {
+					return fmt.Errorf("loyaltyCoalitionSettle: persist paymaster: %w", err)
+				}
+				toAccount, err := sp.getAccount(to[:])
+				if err != nil {
+					return fmt.Errorf("loyaltyCoalitionSettle: load paymaster: %w", err)
+				}
+				if toAccount.BalanceZNHB == nil {
+					toAccount.BalanceZNHB = big.NewInt(0)
+				}
+				toAccount.BalanceZNHB = new(big.Int).Add(toAccount.BalanceZNHB, paid)
+				if err := sp.setAccount(to[:], toAccount); err != nil {
+					return fmt.Errorf("loyaltyCoalitionSettle: persist paymaster: %w", err)
+				}
+			}
+			transfer.Paid = paid
+		}
+		settled.Add(settled, transfer.Paid)
+		shortfall.Add(shortfall, new(big.Int).Sub(transfer.Amount, transfer.Paid))
+	}
+
+	report := &loyalty.CoalitionSettlement{
+		CoalitionID: coalition.ID,
+		Period:      payload.Period,
+		Entries:     ledger.Entries,
+		Transfers:   transfers,
+		SettledAt:   now,
+	}
+	if err := registry.RecordCoalitionSettlement(report); err != nil {
+		return fmt.Errorf("loyaltyCoalitionSettle: %w", err)
+	}
+	// The sender may be one of the paymasters, so reload it before bumping
+	// the nonce.
+	if err := sp.incrementNativeAccountNonce(sender); err != nil {
+		return fmt.Errorf("loyaltyCoalitionSettle: persist account: %w", err)
+	}
+	sp.AppendEvent(events.LoyaltyCoalitionSettled{
+		CoalitionID: coalition.ID,
+		Period:      payload.Period,
+		Transfers:   len(transfers),
+		Settled:     settled,
+		Shortfall:   shortfall,
+	}.Event())
+	return nil
+}
+
+// coalitionPaymaster returns the paymaster of the business that owns the
+// program, or the zero address when none is configured.
+func (sp *StateProcessor) coalitionPaymaster(id loyalty.ProgramID) ([20]byte, error) {
+	program, ok, err := sp.LoyaltyProgramByID(id)
+	if err != nil || !ok {
+		return [20]byte{}, err
+	}
+	business, ok, err := sp.LoyaltyBusinessByMerchant(program.Owner)
+	if err != nil || !ok {
+		return [20]byte{}, err
+	}
+	return business.Paymaster, nil
+}
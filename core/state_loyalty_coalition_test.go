@@ -0,0 +1,167 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/native/loyalty"
+)
+
+func TestLoyaltyCoalitionRedeemAcrossMembersAndSettle(t *testing.T) {
+	fx := newMandateFixture(t)
+	manager := nhbstate.NewManager(fx.sp.Trie)
+	if !manager.TokenExists("ZNHB") {
+		if err := manager.RegisterToken("ZNHB", "ZapNHB", 18); err != nil {
+			t.Fatalf("register ZNHB: %v", err)
+		}
+	}
+	if err := manager.SetTokenSupply("ZNHB", big.NewInt(1_000)); err != nil {
+		t.Fatalf("set supply: %v", err)
+	}
+	registry := loyalty.NewRegistry(manager)
+	var ownerA, ownerB, paymasterA, paymasterB, user, admin [20]byte
+	copy(ownerA[:], fx.merchant.PubKey().Address().Bytes())
+	copy(user[:], fx.payer.PubKey().Address().Bytes())
+	ownerB[19], paymasterA[19], paymasterB[19], admin[19] = 0xB0, 0xA1, 0xB1, 0xAD
+
+	// Two businesses, each with a paymaster and a program. Rewards expire
+	// at the first so that redemption at the second has lots to consume.
+	var programA, programB loyalty.ProgramID
+	programA[31], programB[31] = 0xA0, 0xB0
+	for _, setup := range []struct {
+		owner, paymaster [20]byte
+		program          loyalty.ProgramID
+		expiryDays       uint32
+	}{
+		{ownerA, paymasterA, programA, 30},
+		{ownerB, paymasterB, programB, 0},
+	} {
+		businessID, err := registry.RegisterBusiness(setup.owner, "shop")
+		if err != nil {
+			t.Fatalf("register business: %v", err)
+		}
+		if err := registry.AddMerchantAddress(businessID, setup.owner); err != nil {
+			t.Fatalf("add merchant: %v", err)
+		}
+		if err := registry.SetPaymaster(businessID, setup.owner, setup.paymaster); err != nil {
+			t.Fatalf("set paymaster: %v", err)
+		}
+		if err := registry.CreateProgram(setup.owner, &loyalty.Program{
+			ID:               setup.program,
+			Owner:            setup.owner,
+			Pool:             setup.paymaster,
+			TokenSymbol:      "ZNHB",
+			AccrualBps:       100,
+			DailyCapProgram:  big.NewInt(10_000),
+			RewardExpiryDays: setup.expiryDays,
+			Active:           true,
+		}); err != nil {
+			t.Fatalf("create program: %v", err)
+		}
+	}
+
+	var coalitionID loyalty.CoalitionID
+	coalitionID[31] = 0xC0
+	day := uint64(24 * time.Hour / time.Second)
+	if err := registry.CreateCoalition(admin, &loyalty.Coalition{ID: coalitionID, Name: "Old Town", PeriodSeconds: day}); err != nil {
+		t.Fatalf("create coalition: %v", err)
+	}
+	for _, member := range []struct {
+		owner   [20]byte
+		program loyalty.ProgramID
+	}{{ownerA, programA}, {ownerB, programB}} {
+		if err := registry.InviteCoalitionMember(admin, coalitionID, member.program); err != nil {
+			t.Fatalf("invite: %v", err)
+		}
+		if err := registry.JoinCoalition(member.owner, coalitionID, member.program); err != nil {
+			t.Fatalf("join: %v", err)
+		}
+	}
+	if err := registry.SetCatalogueItem(ownerB, &loyalty.CatalogueItem{ProgramID: programB, ItemID: "bagel", CostWei: big.NewInt(60), Active: true}); err != nil {
+		t.Fatalf("set catalogue item: %v", err)
+	}
+
+	// The user earned 100 ZNHB at the first business during this period.
+	now := uint64(fx.now.Unix())
+	coalition, _, _ := registry.Coalition(coalitionID)
+	period := coalition.Period(now)
+	if err := fx.sp.RecordLoyaltyCoalitionIssued(coalition, programA, now, big.NewInt(100)); err != nil {
+		t.Fatalf("record issued: %v", err)
+	}
+	vaultAddr := fx.sp.LoyaltyRewardVault()
+	vault, err := fx.sp.getAccount(vaultAddr[:])
+	if err != nil {
+		t.Fatalf("load vault: %v", err)
+	}
+	vault.BalanceZNHB = big.NewInt(100)
+	if err := fx.sp.setAccount(vaultAddr[:], vault); err != nil {
+		t.Fatalf("seed vault: %v", err)
+	}
+	if err := manager.SetLoyaltyRewardLots(programA, user[:], []loyalty.RewardLot{
+		{Amount: big.NewInt(100), Remaining: big.NewInt(100), AccruedAt: now, ExpiresAt: now + 30*day},
+	}); err != nil {
+		t.Fatalf("seed lots: %v", err)
+	}
+	paymaster, err := fx.sp.getAccount(paymasterA[:])
+	if err != nil {
+		t.Fatalf("load paymaster: %v", err)
+	}
+	paymaster.BalanceZNHB = big.NewInt(50)
+	if err := fx.sp.setAccount(paymasterA[:], paymaster); err != nil {
+		t.Fatalf("seed paymaster: %v", err)
+	}
+
+	// ...and spends 60 of it at the second.
+	redeem := fx.tx(t, fx.payer, types.TxTypeLoyaltyRedeem, loyaltyRedeemPayload{ProgramID: programB, ItemID: "bagel", Quantity: 1})
+	if err := fx.sp.ApplyTransaction(redeem); err != nil {
+		t.Fatalf("redeem: %v", err)
+	}
+	lots, _ := manager.LoyaltyRewardLots(programA, user[:])
+	if len(lots) != 1 || lots[0].Remaining.Int64() != 40 {
+		t.Fatalf("expected the member lot to be consumed, got %+v", lots)
+	}
+
+	settle := func() error {
+		return fx.sp.ApplyTransaction(fx.tx(t, fx.payer, types.TxTypeLoyaltyCoalitionSettle, loyaltyCoalitionSettlePayload{CoalitionID: coalitionID, Period: period}))
+	}
+	if err := settle(); !errors.Is(err, loyalty.ErrPeriodOpen) {
+		t.Fatalf("expected open period to be rejected, got %v", err)
+	}
+	fx.advance(time.Unix(int64(coalition.PeriodEnd(period)), 0).UTC())
+	if err := settle(); err != nil {
+		t.Fatalf("settle: %v", err)
+	}
+
+	// The first business owes its 60 share of the redemptions but its
+	// paymaster only holds 50.
+	for addr, want := range map[[20]byte]int64{paymasterA: 0, paymasterB: 50} {
+		acc, _ := fx.sp.getAccount(addr[:])
+		if acc.BalanceZNHB.Int64() != want {
+			t.Fatalf("paymaster %x balance = %s, want %d", addr, acc.BalanceZNHB, want)
+		}
+	}
+	report, ok, err := registry.CoalitionSettlement(coalitionID, period)
+	if err != nil || !ok {
+		t.Fatalf("load settlement: ok=%v err=%v", ok, err)
+	}
+	if len(report.Transfers) != 1 || report.Transfers[0].From != programA || report.Transfers[0].Amount.Int64() != 60 || report.Transfers[0].Paid.Int64() != 50 {
+		t.Fatalf("unexpected settlement transfers: %+v", report.Transfers)
+	}
+	var settled *types.Event
+	for i := range fx.sp.events {
+		if fx.sp.events[i].Type == events.TypeLoyaltyCoalitionSettled {
+			settled = &fx.sp.events[i]
+		}
+	}
+	if settled == nil || settled.Attributes["settled"] != "50" || settled.Attributes["shortfall"] != "10" {
+		t.Fatalf("unexpected settlement event: %+v", settled)
+	}
+	if err := settle(); !errors.Is(err, loyalty.ErrPeriodSettled) {
+		t.Fatalf("expected a second settlement to be rejected, got %v", err)
+	}
+}
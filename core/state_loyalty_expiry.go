@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/native/loyalty"
+)
+
+// sweepExpiredRewardLots returns the expired reward lots scheduled on every
+// UTC day before the block's day to their program pools. Expiry therefore
+// happens on schedule whether or not the holder ever redeems. A chain that
+// has never swept starts from the current day.
+func (sp *StateProcessor) sweepExpiredRewardLots(now time.Time) error {
+	manager := nhbstate.NewManager(sp.Trie)
+	today := loyalty.DayIndex(uint64(now.Unix()))
+	day, ok, err := manager.LoyaltyRewardExpiryCursor()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return manager.SetLoyaltyRewardExpiryCursor(today)
+	}
+	if day >= today {
+		return nil
+	}
+	registry := loyalty.NewRegistry(manager)
+	for ; day < today; day++ {
+		refs, err := manager.LoyaltyRewardExpiries(day)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			if err := sp.expireRewardLots(registry, ref, uint64(now.Unix())); err != nil {
+				return err
+			}
+		}
+		if err := manager.ClearLoyaltyRewardExpiries(day); err != nil {
+			return err
+		}
+	}
+	return manager.SetLoyaltyRewardExpiryCursor(today)
+}
+
+// expireRewardLots expires one holder's lots for a program. Members of a
+// shared-pool coalition return expired rewards to the coalition pool, as
+// they do at redemption.
+func (sp *StateProcessor) expireRewardLots(registry *loyalty.Registry, ref loyalty.RewardLotRef, now uint64) error {
+	program, ok, err := sp.LoyaltyProgramByID(ref.ProgramID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	coalition, inCoalition, err := registry.CoalitionForProgram(program.ID)
+	if err != nil {
+		return err
+	}
+	if inCoalition && coalition.Active && coalition.Shared() {
+		shared := *program
+		shared.Pool = coalition.Pool
+		program = &shared
+	}
+	expired, _, err := loyalty.ExpireRewards(sp, program, ref.Addr[:], now)
+	if err != nil {
+		return fmt.Errorf("expire rewards of %x: %w", ref.Addr, err)
+	}
+	if expired.Sign() > 0 {
+		sp.AppendEvent(loyalty.NewRewardsExpiredEvent(program, ref.Addr[:], expired))
+	}
+	return nil
+}
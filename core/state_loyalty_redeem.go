@@ -0,0 +1,263 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	nativecommon "nhbchain/native/common"
+	"nhbchain/native/loyalty"
+)
+
+// loyaltyRedeemPayload is the RLP payload carried by TxTypeLoyaltyRedeem.
+type loyaltyRedeemPayload struct {
+	ProgramID loyalty.ProgramID
+	ItemID    string
+	Quantity  uint64
+}
+
+// applyLoyaltyRedeem spends the sender's program rewards on a catalogue item.
+// Live reward lots held in the reward vault are spent first and any remainder
+// comes from the sender's ZNHB balance. Expired lots are returned to the
+// program pool first so they cannot be redeemed. Depending on the item's mode
+// the cost is burned or returned to the program pool, and the stored receipt
+// together with the loyalty.redemption.receipt event lets the point of sale
+// verify the redemption. When the program belongs to a coalition, rewards
+// earned at any member are spent, and the redemption is recorded against the
+// redeeming program for settlement.
+func (sp *StateProcessor) applyLoyaltyRedeem(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	if err := nativecommon.Guard(sp.pauses, moduleLoyalty); err != nil {
+		return err
+	}
+	var payload loyaltyRedeemPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("loyaltyRedeem: decode payload: %w", err)
+	}
+	if payload.Quantity == 0 {
+		return fmt.Errorf("loyaltyRedeem: quantity must be positive")
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	registry := loyalty.NewRegistry(manager)
+	program, ok, err := sp.LoyaltyProgramByID(payload.ProgramID)
+	if err != nil {
+		return fmt.Errorf("loyaltyRedeem: %w", err)
+	}
+	if !ok {
+		return loyalty.ErrProgramNotFound
+	}
+	if !program.Active {
+		return fmt.Errorf("loyaltyRedeem: program inactive")
+	}
+	token := strings.ToUpper(strings.TrimSpace(program.TokenSymbol))
+	if token != "ZNHB" {
+		return fmt.Errorf("loyaltyRedeem: reward token %s not supported", program.TokenSymbol)
+	}
+	item, ok, err := registry.CatalogueItem(program.ID, payload.ItemID)
+	if err != nil {
+		return fmt.Errorf("loyaltyRedeem: %w", err)
+	}
+	if !ok {
+		return loyalty.ErrItemNotFound
+	}
+	if !item.Available(payload.Quantity) {
+		return loyalty.ErrItemUnavailable
+	}
+	coalition, inCoalition, err := registry.CoalitionForProgram(program.ID)
+	if err != nil {
+		return fmt.Errorf("loyaltyRedeem: %w", err)
+	}
+	inCoalition = inCoalition && coalition.Active
+	// Lots are consumed from the redeeming program first and then from the
+	// other coalition members. Shared-pool members reclaim into the
+	// coalition pool.
+	sources := []*loyalty.Program{program}
+	if inCoalition {
+		for _, memberID := range coalition.Members {
+			if memberID == program.ID {
+				continue
+			}
+			member, ok, err := sp.LoyaltyProgramByID(memberID)
+			if err != nil {
+				return fmt.Errorf("loyaltyRedeem: %w", err)
+			}
+			if ok {
+				sources = append(sources, member)
+			}
+		}
+		if coalition.Shared() {
+			for i, source := range sources {
+				shared := *source
+				shared.Pool = coalition.Pool
+				sources[i] = &shared
+			}
+		}
+	}
+	mode := loyalty.RedemptionMode(item.Mode)
+	if mode == loyalty.RedemptionModeTransfer && sources[0].Pool == ([20]byte{}) {
+		return fmt.Errorf("loyaltyRedeem: program has no pool")
+	}
+	cost := new(big.Int).Mul(item.CostWei, new(big.Int).SetUint64(payload.Quantity))
+	now := uint64(sp.blockTimestamp().Unix())
+	if senderAccount.BalanceZNHB == nil {
+		senderAccount.BalanceZNHB = big.NewInt(0)
+	}
+
+	// Rewards of expiring programs are held as lots in the reward vault and
+	// are spent first; the rest of the cost comes from the sender's own
+	// balance. Validate the spend before any state is written.
+	spendable := new(big.Int).Set(senderAccount.BalanceZNHB)
+	for _, source := range sources {
+		if !source.Expiring() {
+			continue
+		}
+		lots, err := sp.LoyaltyRewardLots(source.ID, sender)
+		if err != nil {
+			return fmt.Errorf("loyaltyRedeem: %w", err)
+		}
+		live, _ := loyalty.ExpireLots(lots, now)
+		spendable.Add(spendable, sumRemaining(live))
+	}
+	if spendable.Cmp(cost) < 0 {
+		return fmt.Errorf("loyaltyRedeem: insufficient %s balance: have %s, need %s", token, spendable, cost)
+	}
+	txHash, err := tx.Hash()
+	if err != nil {
+		return fmt.Errorf("loyaltyRedeem: compute tx hash: %w", err)
+	}
+
+	var burnedSupply *big.Int
+	if mode == loyalty.RedemptionModeBurn {
+		if burnedSupply, err = manager.AdjustTokenSupply(token, new(big.Int).Neg(cost)); err != nil {
+			return fmt.Errorf("loyaltyRedeem: %w", err)
+		}
+	}
+	reclaimed := big.NewInt(0)
+	fromBalance := new(big.Int).Set(cost)
+	for _, source := range sources {
+		if !source.Expiring() {
+			continue
+		}
+		expired, lots, err := loyalty.ExpireRewards(sp, source, sender, now)
+		if err != nil {
+			return fmt.Errorf("loyaltyRedeem: %w", err)
+		}
+		reclaimed.Add(reclaimed, expired)
+		consume := new(big.Int).Set(fromBalance)
+		if held := sumRemaining(lots); held.Cmp(consume) < 0 {
+			consume = held
+		}
+		fromBalance.Sub(fromBalance, consume)
+		if err := sp.SetLoyaltyRewardLots(source.ID, sender, loyalty.ConsumeLots(lots, consume)); err != nil {
+			return fmt.Errorf("loyaltyRedeem: %w", err)
+		}
+	}
+	// Expiry pays into the sender when it is a program pool.
+	current, err := sp.getAccount(sender)
+	if err != nil {
+		return fmt.Errorf("loyaltyRedeem: reload account: %w", err)
+	}
+	senderAccount.BalanceZNHB = current.BalanceZNHB
+	if senderAccount.BalanceZNHB == nil {
+		senderAccount.BalanceZNHB = big.NewInt(0)
+	}
+	fromVault := new(big.Int).Sub(cost, fromBalance)
+	if fromVault.Sign() > 0 {
+		vaultAddr := sp.LoyaltyRewardVault()
+		vault, err := sp.getAccount(vaultAddr[:])
+		if err != nil {
+			return fmt.Errorf("loyaltyRedeem: load reward vault: %w", err)
+		}
+		if vault.BalanceZNHB == nil || vault.BalanceZNHB.Cmp(fromVault) < 0 {
+			return fmt.Errorf("loyaltyRedeem: reward vault holds less than the redeemed lots")
+		}
+		vault.BalanceZNHB = new(big.Int).Sub(vault.BalanceZNHB, fromVault)
+		if err := sp.setAccount(vaultAddr[:], vault); err != nil {
+			return fmt.Errorf("loyaltyRedeem: persist reward vault: %w", err)
+		}
+	}
+	var pool [20]byte
+	if mode == loyalty.RedemptionModeTransfer {
+		pool = sources[0].Pool
+	}
+	switch {
+	case mode == loyalty.RedemptionModeBurn:
+		senderAccount.BalanceZNHB = new(big.Int).Sub(senderAccount.BalanceZNHB, fromBalance)
+	case bytes.Equal(pool[:], sender):
+		senderAccount.BalanceZNHB = new(big.Int).Add(senderAccount.BalanceZNHB, fromVault)
+	default:
+		senderAccount.BalanceZNHB = new(big.Int).Sub(senderAccount.BalanceZNHB, fromBalance)
+		poolAccount, err := sp.getAccount(pool[:])
+		if err != nil {
+			return fmt.Errorf("loyaltyRedeem: load pool: %w", err)
+		}
+		if poolAccount.BalanceZNHB == nil {
+			poolAccount.BalanceZNHB = big.NewInt(0)
+		}
+		poolAccount.BalanceZNHB = new(big.Int).Add(poolAccount.BalanceZNHB, cost)
+		if err := sp.setAccount(pool[:], poolAccount); err != nil {
+			return fmt.Errorf("loyaltyRedeem: persist pool: %w", err)
+		}
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("loyaltyRedeem: persist account: %w", err)
+	}
+
+	var user [20]byte
+	copy(user[:], sender)
+	item.Redeemed += payload.Quantity
+	receipt := &loyalty.RedemptionReceipt{
+		ID:         loyalty.RedemptionID(txHash),
+		ProgramID:  program.ID,
+		ItemID:     item.ItemID,
+		User:       user,
+		Quantity:   payload.Quantity,
+		CostWei:    cost,
+		Token:      token,
+		Mode:       item.Mode,
+		RedeemedAt: now,
+	}
+	if err := registry.RecordRedemption(item, receipt); err != nil {
+		return fmt.Errorf("loyaltyRedeem: %w", err)
+	}
+	var coalitionID loyalty.CoalitionID
+	if inCoalition {
+		coalitionID = coalition.ID
+		if err := registry.RecordCoalitionActivity(coalition.ID, coalition.Period(now), program.ID, nil, cost); err != nil {
+			return fmt.Errorf("loyaltyRedeem: %w", err)
+		}
+	}
+	sp.AppendEvent(events.LoyaltyRedemption{
+		ReceiptID:   receipt.ID,
+		ProgramID:   receipt.ProgramID,
+		ItemID:      receipt.ItemID,
+		User:        user,
+		Quantity:    receipt.Quantity,
+		Cost:        cost,
+		Token:       token,
+		Mode:        receipt.Mode,
+		Pool:        pool,
+		Expired:     reclaimed,
+		CoalitionID: coalitionID,
+	}.Event())
+	if burnedSupply != nil {
+		sp.recordTokenSupplyChange(token, new(big.Int).Neg(cost), burnedSupply, events.SupplyReasonBurn)
+	}
+	return nil
+}
+
+func sumRemaining(lots []loyalty.RewardLot) *big.Int {
+	total := big.NewInt(0)
+	for _, lot := range lots {
+		if lot.Remaining != nil {
+			total.Add(total, lot.Remaining)
+		}
+	}
+	return total
+}
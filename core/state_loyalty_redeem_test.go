@@ -0,0 +1,214 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/native/loyalty"
+)
+
+func TestLoyaltyRedeemReclaimsExpiredLotsAndBurns(t *testing.T) {
+	fx := newMandateFixture(t)
+	manager := nhbstate.NewManager(fx.sp.Trie)
+	if !manager.TokenExists("ZNHB") {
+		if err := manager.RegisterToken("ZNHB", "ZapNHB", 18); err != nil {
+			t.Fatalf("register ZNHB: %v", err)
+		}
+	}
+	if err := manager.SetTokenSupply("ZNHB", big.NewInt(1_000)); err != nil {
+		t.Fatalf("set supply: %v", err)
+	}
+	var owner, user, pool [20]byte
+	copy(owner[:], fx.merchant.PubKey().Address().Bytes())
+	copy(user[:], fx.payer.PubKey().Address().Bytes())
+	pool[19] = 0x77
+
+	var programID loyalty.ProgramID
+	programID[31] = 0x47
+	registry := loyalty.NewRegistry(manager)
+	if err := registry.CreateProgram(owner, &loyalty.Program{
+		ID:               programID,
+		Owner:            owner,
+		Pool:             pool,
+		TokenSymbol:      "ZNHB",
+		AccrualBps:       100,
+		DailyCapProgram:  big.NewInt(10_000),
+		RewardExpiryDays: 10,
+		Active:           true,
+	}); err != nil {
+		t.Fatalf("create program: %v", err)
+	}
+	for _, item := range []*loyalty.CatalogueItem{
+		{ProgramID: programID, ItemID: "coffee", CostWei: big.NewInt(40), Mode: "burn", Stock: 2, Active: true},
+		{ProgramID: programID, ItemID: "tote", CostWei: big.NewInt(100), Mode: "transfer", Active: true},
+	} {
+		if err := registry.SetCatalogueItem(owner, item); err != nil {
+			t.Fatalf("set catalogue item %s: %v", item.ItemID, err)
+		}
+	}
+	if err := registry.SetCatalogueItem(user, &loyalty.CatalogueItem{ProgramID: programID, ItemID: "free", CostWei: big.NewInt(1), Active: true}); !errors.Is(err, loyalty.ErrUnauthorized) {
+		t.Fatalf("expected non-owner catalogue change to be rejected, got %v", err)
+	}
+
+	// The vault holds 200 ZNHB for the user in two lots, one of which has
+	// expired. The user also holds 10 ZNHB of their own.
+	now := uint64(fx.now.Unix())
+	day := uint64(24 * time.Hour / time.Second)
+	vaultAddr := fx.sp.LoyaltyRewardVault()
+	for addr, balance := range map[[20]byte]int64{vaultAddr: 200, user: 10} {
+		account, err := fx.sp.getAccount(addr[:])
+		if err != nil {
+			t.Fatalf("load account: %v", err)
+		}
+		account.BalanceZNHB = big.NewInt(balance)
+		if err := fx.sp.setAccount(addr[:], account); err != nil {
+			t.Fatalf("seed account: %v", err)
+		}
+	}
+	if err := manager.SetLoyaltyRewardLots(programID, user[:], []loyalty.RewardLot{
+		{Amount: big.NewInt(100), Remaining: big.NewInt(100), AccruedAt: now - 20*day, ExpiresAt: now - 10*day},
+		{Amount: big.NewInt(100), Remaining: big.NewInt(100), AccruedAt: now - day, ExpiresAt: now + 9*day},
+	}); err != nil {
+		t.Fatalf("seed lots: %v", err)
+	}
+
+	redeem := func(itemID string, quantity uint64) (*types.Transaction, error) {
+		tx := fx.tx(t, fx.payer, types.TxTypeLoyaltyRedeem, loyaltyRedeemPayload{ProgramID: programID, ItemID: itemID, Quantity: quantity})
+		return tx, fx.sp.ApplyTransaction(tx)
+	}
+	tx, err := redeem("coffee", 2)
+	if err != nil {
+		t.Fatalf("redeem coffee: %v", err)
+	}
+	var receiptEvent *types.Event
+	for i := range fx.sp.events {
+		if fx.sp.events[i].Type == events.TypeLoyaltyRedemption {
+			receiptEvent = &fx.sp.events[i]
+		}
+	}
+	if receiptEvent == nil || receiptEvent.Attributes["cost"] != "80" || receiptEvent.Attributes["expired"] != "100" {
+		t.Fatalf("unexpected redemption event: %+v", receiptEvent)
+	}
+	hash, err := tx.Hash()
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	receipt, ok, err := registry.Redemption(loyalty.RedemptionID(hash))
+	if err != nil || !ok {
+		t.Fatalf("load receipt: ok=%v err=%v", ok, err)
+	}
+	if receipt.User != user || receipt.Quantity != 2 || receipt.CostWei.Int64() != 80 {
+		t.Fatalf("unexpected receipt: %+v", receipt)
+	}
+	// The expired lot is never taken from the user's own balance.
+	account, _ := fx.sp.getAccount(user[:])
+	if account.BalanceZNHB.Int64() != 10 {
+		t.Fatalf("user balance = %s, want 10", account.BalanceZNHB)
+	}
+	vault, _ := fx.sp.getAccount(vaultAddr[:])
+	if vault.BalanceZNHB.Int64() != 20 {
+		t.Fatalf("vault balance = %s, want 20", vault.BalanceZNHB)
+	}
+	poolAccount, _ := fx.sp.getAccount(pool[:])
+	if poolAccount.BalanceZNHB.Int64() != 100 {
+		t.Fatalf("pool balance = %s, want 100", poolAccount.BalanceZNHB)
+	}
+	supply, err := manager.TokenSupply("ZNHB")
+	if err != nil || supply.Int64() != 920 {
+		t.Fatalf("supply = %v (err %v), want 920", supply, err)
+	}
+	lots, _ := manager.LoyaltyRewardLots(programID, user[:])
+	if len(lots) != 1 || lots[0].Remaining.Int64() != 20 {
+		t.Fatalf("unexpected lots: %+v", lots)
+	}
+
+	if _, err := redeem("coffee", 1); !errors.Is(err, loyalty.ErrItemUnavailable) {
+		t.Fatalf("expected sold-out item to be rejected, got %v", err)
+	}
+	if _, err := redeem("tote", 1); err == nil {
+		t.Fatalf("expected redemption above the balance to fail")
+	}
+}
+
+func TestFinalizeBlockExpiresRewardLotsWithoutRedemption(t *testing.T) {
+	fx := newMandateFixture(t)
+	manager := nhbstate.NewManager(fx.sp.Trie)
+	if !manager.TokenExists("ZNHB") {
+		if err := manager.RegisterToken("ZNHB", "ZapNHB", 18); err != nil {
+			t.Fatalf("register ZNHB: %v", err)
+		}
+	}
+	var owner, user, pool [20]byte
+	copy(owner[:], fx.merchant.PubKey().Address().Bytes())
+	copy(user[:], fx.payer.PubKey().Address().Bytes())
+	pool[19] = 0x78
+	var programID loyalty.ProgramID
+	programID[31] = 0x48
+	if err := loyalty.NewRegistry(manager).CreateProgram(owner, &loyalty.Program{
+		ID:               programID,
+		Owner:            owner,
+		Pool:             pool,
+		TokenSymbol:      "ZNHB",
+		AccrualBps:       100,
+		DailyCapProgram:  big.NewInt(10_000),
+		RewardExpiryDays: 10,
+		Active:           true,
+	}); err != nil {
+		t.Fatalf("create program: %v", err)
+	}
+	if err := fx.sp.FinalizeBlock(); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+
+	now := uint64(fx.now.Unix())
+	day := uint64(24 * time.Hour / time.Second)
+	vaultAddr := fx.sp.LoyaltyRewardVault()
+	vault, err := fx.sp.getAccount(vaultAddr[:])
+	if err != nil {
+		t.Fatalf("load vault: %v", err)
+	}
+	vault.BalanceZNHB = big.NewInt(150)
+	if err := fx.sp.setAccount(vaultAddr[:], vault); err != nil {
+		t.Fatalf("seed vault: %v", err)
+	}
+	if err := manager.SetLoyaltyRewardLots(programID, user[:], []loyalty.RewardLot{
+		{Amount: big.NewInt(100), Remaining: big.NewInt(100), AccruedAt: now, ExpiresAt: now + day},
+		{Amount: big.NewInt(50), Remaining: big.NewInt(50), AccruedAt: now, ExpiresAt: now + 5*day},
+	}); err != nil {
+		t.Fatalf("seed lots: %v", err)
+	}
+
+	// The first lot expires on the next day's sweep while the user never
+	// redeems.
+	fx.advance(fx.now.Add(2 * 24 * time.Hour))
+	if err := fx.sp.FinalizeBlock(); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	poolAccount, _ := fx.sp.getAccount(pool[:])
+	if poolAccount.BalanceZNHB == nil || poolAccount.BalanceZNHB.Int64() != 100 {
+		t.Fatalf("pool balance = %v, want 100", poolAccount.BalanceZNHB)
+	}
+	lots, _ := manager.LoyaltyRewardLots(programID, user[:])
+	if len(lots) != 1 || lots[0].Remaining.Int64() != 50 {
+		t.Fatalf("unexpected lots after sweep: %+v", lots)
+	}
+
+	// The remaining lot was rescheduled and expires on its own day.
+	fx.advance(fx.now.Add(5 * 24 * time.Hour))
+	if err := fx.sp.FinalizeBlock(); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	poolAccount, _ = fx.sp.getAccount(pool[:])
+	vault, _ = fx.sp.getAccount(vaultAddr[:])
+	if poolAccount.BalanceZNHB.Int64() != 150 || vault.BalanceZNHB.Sign() != 0 {
+		t.Fatalf("pool = %s, vault = %s after the second sweep", poolAccount.BalanceZNHB, vault.BalanceZNHB)
+	}
+	if lots, _ := manager.LoyaltyRewardLots(programID, user[:]); len(lots) != 0 {
+		t.Fatalf("expected every lot to expire, got %+v", lots)
+	}
+}
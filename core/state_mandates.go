@@ -0,0 +1,297 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+)
+
+var (
+	// ErrMandateNotPullable is returned when a pull targets a mandate that
+	// is cancelled, not yet started, ended or already pulled this period.
+	ErrMandateNotPullable = errors.New("mandate: not pullable")
+	// ErrMandateUnauthorized is returned when the sender is not the party a
+	// mandate operation requires.
+	ErrMandateUnauthorized = errors.New("mandate: sender is not authorized")
+)
+
+// mandateCreatePayload is the RLP payload carried by TxTypeCreateMandate. A
+// zero StartAt starts the first period at the block time; a zero EndAt leaves
+// the mandate open until cancelled.
+type mandateCreatePayload struct {
+	Merchant      [20]byte
+	Asset         string
+	MaxAmount     *big.Int
+	PeriodSeconds uint64
+	StartAt       uint64
+	EndAt         uint64
+}
+
+// mandatePullPayload is the RLP payload carried by TxTypePullMandate.
+type mandatePullPayload struct {
+	MandateID [32]byte
+	Amount    *big.Int
+}
+
+// mandateCancelPayload is the RLP payload carried by TxTypeCancelMandate.
+type mandateCancelPayload struct {
+	MandateID [32]byte
+}
+
+// applyCreateMandate records a recurring payment mandate signed once by the
+// payer. The mandate ID is derived from the transaction hash.
+func (sp *StateProcessor) applyCreateMandate(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload mandateCreatePayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("createMandate: decode payload: %w", err)
+	}
+	if payload.Merchant == ([20]byte{}) {
+		return fmt.Errorf("createMandate: merchant required")
+	}
+	if bytes.Equal(payload.Merchant[:], sender) {
+		return fmt.Errorf("createMandate: payer cannot be the merchant")
+	}
+	asset := strings.ToUpper(strings.TrimSpace(payload.Asset))
+	manager := nhbstate.NewManager(sp.Trie)
+	if !nhbstate.IsNativeToken(asset) && !manager.TokenExists(asset) {
+		return fmt.Errorf("createMandate: %w: %s", nhbstate.ErrAssetNotFound, asset)
+	}
+	if payload.MaxAmount == nil || payload.MaxAmount.Sign() <= 0 {
+		return fmt.Errorf("createMandate: max amount must be positive")
+	}
+	if payload.PeriodSeconds == 0 {
+		return fmt.Errorf("createMandate: period must be positive")
+	}
+	now := uint64(sp.blockTimestamp().Unix())
+	start := payload.StartAt
+	if start == 0 {
+		start = now
+	}
+	if start < now {
+		return fmt.Errorf("createMandate: start must not be in the past")
+	}
+	if payload.EndAt != 0 && payload.EndAt <= start {
+		return fmt.Errorf("createMandate: end must be after start")
+	}
+	txHash, err := tx.Hash()
+	if err != nil {
+		return fmt.Errorf("createMandate: compute tx hash: %w", err)
+	}
+	var payer [20]byte
+	copy(payer[:], sender)
+	mandate := &nhbstate.StoredMandate{
+		ID:            nhbstate.MandateID(txHash),
+		Payer:         payer,
+		Merchant:      payload.Merchant,
+		Asset:         asset,
+		MaxAmount:     new(big.Int).Set(payload.MaxAmount),
+		PeriodSeconds: payload.PeriodSeconds,
+		StartAt:       start,
+		EndAt:         payload.EndAt,
+		CreatedAt:     now,
+		Status:        string(nhbstate.MandateStatusActive),
+		PulledTotal:   big.NewInt(0),
+	}
+	if err := manager.CreateMandate(mandate); err != nil {
+		return fmt.Errorf("createMandate: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("createMandate: persist payer: %w", err)
+	}
+	sp.AppendEvent(events.MandateCreated{
+		MandateID:     mandate.ID,
+		Payer:         mandate.Payer,
+		Merchant:      mandate.Merchant,
+		Asset:         mandate.Asset,
+		MaxAmount:     mandate.MaxAmount,
+		PeriodSeconds: mandate.PeriodSeconds,
+		StartAt:       mandate.StartAt,
+		EndAt:         mandate.EndAt,
+	}.Event())
+	return nil
+}
+
+// applyPullMandate collects up to the mandate's per-period maximum from the
+// payer on behalf of the signing merchant. At most one pull succeeds per
+// period. A pull the payer cannot fund does not fail the transaction: it
+// records the attempt, emits mandate.pull_failed for dunning and leaves the
+// period open so the merchant can retry. The pull may be paymaster sponsored.
+func (sp *StateProcessor) applyPullMandate(tx *types.Transaction, sender []byte) error {
+	var payload mandatePullPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("pullMandate: decode payload: %w", err)
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	mandate, ok, err := manager.GetMandate(payload.MandateID)
+	if err != nil {
+		return fmt.Errorf("pullMandate: %w", err)
+	}
+	if !ok {
+		return nhbstate.ErrMandateNotFound
+	}
+	if !bytes.Equal(mandate.Merchant[:], sender) {
+		return fmt.Errorf("pullMandate: %w", ErrMandateUnauthorized)
+	}
+	if payload.Amount == nil || payload.Amount.Sign() <= 0 {
+		return fmt.Errorf("pullMandate: amount must be positive")
+	}
+	if payload.Amount.Cmp(mandate.MaxAmount) > 0 {
+		return fmt.Errorf("pullMandate: amount exceeds per-period maximum %s", mandate.MaxAmount)
+	}
+	if nhbstate.MandateStatus(mandate.Status) != nhbstate.MandateStatusActive {
+		return fmt.Errorf("%w: mandate is %s", ErrMandateNotPullable, mandate.Status)
+	}
+	now := uint64(sp.blockTimestamp().Unix())
+	if mandate.Ended(now) {
+		return fmt.Errorf("%w: mandate ended", ErrMandateNotPullable)
+	}
+	period, started := mandate.Period(now)
+	if !started {
+		return fmt.Errorf("%w: mandate not started", ErrMandateNotPullable)
+	}
+	if mandate.PulledInPeriod(period) {
+		return fmt.Errorf("%w: period %d already pulled", ErrMandateNotPullable, period)
+	}
+	sponsorship, err := sp.prepareNativeSponsorship(tx, sender)
+	if err != nil {
+		return err
+	}
+	txHash, err := tx.Hash()
+	if err != nil {
+		return fmt.Errorf("pullMandate: compute tx hash: %w", err)
+	}
+	transferErr := manager.AssetTransfer(mandate.Payer[:], mandate.Merchant[:], mandate.Asset, payload.Amount)
+	reason := ""
+	switch {
+	case transferErr == nil:
+	case errors.Is(transferErr, nhbstate.ErrAssetInsufficientBalance):
+		reason = "insufficient_balance"
+	case errors.Is(transferErr, nhbstate.ErrAssetFrozen):
+		reason = "account_frozen"
+	default:
+		return fmt.Errorf("pullMandate: %w", transferErr)
+	}
+	if reason != "" {
+		mandate.FailedPulls++
+		mandate.LastFailureAt = now
+	} else {
+		mandate.LastPullPeriod = period + 1
+		mandate.PulledTotal = new(big.Int).Add(mandate.PulledTotal, payload.Amount)
+		mandate.PullCount++
+		mandate.FailedPulls = 0
+	}
+	if err := manager.PutMandate(mandate); err != nil {
+		return fmt.Errorf("pullMandate: %w", err)
+	}
+	// The transfer rewrites the merchant account, so the nonce is bumped on
+	// a fresh copy.
+	if err := sp.incrementNativeAccountNonce(sender); err != nil {
+		return fmt.Errorf("pullMandate: persist merchant: %w", err)
+	}
+	if reason != "" {
+		sp.AppendEvent(events.MandatePullFailed{
+			MandateID: mandate.ID,
+			Payer:     mandate.Payer,
+			Merchant:  mandate.Merchant,
+			Asset:     mandate.Asset,
+			Amount:    payload.Amount,
+			Period:    period,
+			Attempts:  mandate.FailedPulls,
+			Reason:    reason,
+			TxHash:    txHash,
+		}.Event())
+	} else {
+		sp.AppendEvent(events.MandatePulled{
+			MandateID:   mandate.ID,
+			Payer:       mandate.Payer,
+			Merchant:    mandate.Merchant,
+			Asset:       mandate.Asset,
+			Amount:      payload.Amount,
+			Period:      period,
+			PulledTotal: mandate.PulledTotal,
+			TxHash:      txHash,
+		}.Event())
+	}
+	return sp.chargeNativeSponsorship(tx, sponsorship)
+}
+
+// applyCancelMandate revokes a mandate. The payer may cancel at any time; the
+// merchant may also end a mandate it no longer intends to pull.
+func (sp *StateProcessor) applyCancelMandate(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload mandateCancelPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("cancelMandate: decode payload: %w", err)
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	mandate, ok, err := manager.GetMandate(payload.MandateID)
+	if err != nil {
+		return fmt.Errorf("cancelMandate: %w", err)
+	}
+	if !ok {
+		return nhbstate.ErrMandateNotFound
+	}
+	if !bytes.Equal(mandate.Payer[:], sender) && !bytes.Equal(mandate.Merchant[:], sender) {
+		return fmt.Errorf("cancelMandate: %w", ErrMandateUnauthorized)
+	}
+	if nhbstate.MandateStatus(mandate.Status) != nhbstate.MandateStatusActive {
+		return fmt.Errorf("cancelMandate: mandate is %s", mandate.Status)
+	}
+	mandate.Status = string(nhbstate.MandateStatusCancelled)
+	mandate.CancelledAt = uint64(sp.blockTimestamp().Unix())
+	if err := manager.PutMandate(mandate); err != nil {
+		return fmt.Errorf("cancelMandate: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("cancelMandate: persist account: %w", err)
+	}
+	var cancelledBy [20]byte
+	copy(cancelledBy[:], sender)
+	sp.AppendEvent(events.MandateCancelled{
+		MandateID:   mandate.ID,
+		Payer:       mandate.Payer,
+		Merchant:    mandate.Merchant,
+		CancelledBy: cancelledBy,
+	}.Event())
+	return nil
+}
+
+// GetMandate returns the mandate stored under id.
+func (sp *StateProcessor) GetMandate(id [32]byte) (*nhbstate.StoredMandate, error) {
+	if sp == nil {
+		return nil, fmt.Errorf("state processor unavailable")
+	}
+	mandate, ok, err := nhbstate.NewManager(sp.Trie).GetMandate(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nhbstate.ErrMandateNotFound
+	}
+	return mandate, nil
+}
+
+// ListMandatesByPayer returns every mandate signed by payer.
+func (sp *StateProcessor) ListMandatesByPayer(payer [20]byte) ([]*nhbstate.StoredMandate, error) {
+	if sp == nil {
+		return nil, fmt.Errorf("state processor unavailable")
+	}
+	return nhbstate.NewManager(sp.Trie).ListMandatesByPayer(payer)
+}
+
+// ListMandatesByMerchant returns every mandate granted to merchant.
+func (sp *StateProcessor) ListMandatesByMerchant(merchant [20]byte) ([]*nhbstate.StoredMandate, error) {
+	if sp == nil {
+		return nil, fmt.Errorf("state processor unavailable")
+	}
+	return nhbstate.NewManager(sp.Trie).ListMandatesByMerchant(merchant)
+}
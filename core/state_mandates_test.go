@@ -0,0 +1,247 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+type mandateFixture struct {
+	sp       *StateProcessor
+	now      time.Time
+	height   uint64
+	payer    *crypto.PrivateKey
+	merchant *crypto.PrivateKey
+}
+
+func newMandateFixture(t *testing.T) *mandateFixture {
+	t.Helper()
+	sp := newStakingStateProcessor(t)
+	fx := &mandateFixture{sp: sp}
+	fx.advance(time.Unix(1_700_000_000, 0).UTC())
+	t.Cleanup(func() { sp.EndBlock() })
+	for _, key := range []**crypto.PrivateKey{&fx.payer, &fx.merchant} {
+		priv, err := crypto.GeneratePrivateKey()
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		*key = priv
+	}
+	if err := sp.setAccount(fx.payer.PubKey().Address().Bytes(), &types.Account{
+		BalanceNHB: big.NewInt(250), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0),
+	}); err != nil {
+		t.Fatalf("seed payer: %v", err)
+	}
+	if err := sp.setAccount(fx.merchant.PubKey().Address().Bytes(), &types.Account{
+		BalanceNHB: big.NewInt(0), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0),
+	}); err != nil {
+		t.Fatalf("seed merchant: %v", err)
+	}
+	return fx
+}
+
+func (fx *mandateFixture) advance(now time.Time) {
+	fx.now = now
+	fx.height++
+	fx.sp.nowFunc = func() time.Time { return now }
+	fx.sp.BeginBlock(fx.height, now)
+}
+
+func (fx *mandateFixture) tx(t *testing.T, key *crypto.PrivateKey, txType types.TxType, payload interface{}) *types.Transaction {
+	t.Helper()
+	data, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+	account, err := fx.sp.getAccount(key.PubKey().Address().Bytes())
+	if err != nil {
+		t.Fatalf("load account: %v", err)
+	}
+	tx := &types.Transaction{
+		ChainID:  types.NHBChainID(),
+		Type:     txType,
+		Nonce:    account.Nonce,
+		GasLimit: 25_000,
+		GasPrice: big.NewInt(1),
+		Data:     data,
+	}
+	if err := tx.Sign(key.PrivateKey); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return tx
+}
+
+func (fx *mandateFixture) create(t *testing.T, payload mandateCreatePayload) [32]byte {
+	t.Helper()
+	tx := fx.tx(t, fx.payer, types.TxTypeCreateMandate, payload)
+	if err := fx.sp.ApplyTransaction(tx); err != nil {
+		t.Fatalf("create mandate: %v", err)
+	}
+	hash, err := tx.Hash()
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	return nhbstate.MandateID(hash)
+}
+
+func (fx *mandateFixture) pull(t *testing.T, id [32]byte, amount int64) error {
+	t.Helper()
+	tx := fx.tx(t, fx.merchant, types.TxTypePullMandate, mandatePullPayload{MandateID: id, Amount: big.NewInt(amount)})
+	return fx.sp.ApplyTransaction(tx)
+}
+
+func (fx *mandateFixture) balanceNHB(t *testing.T, key *crypto.PrivateKey) int64 {
+	t.Helper()
+	account, err := fx.sp.getAccount(key.PubKey().Address().Bytes())
+	if err != nil {
+		t.Fatalf("load account: %v", err)
+	}
+	return account.BalanceNHB.Int64()
+}
+
+func (fx *mandateFixture) lastEvent(t *testing.T) types.Event {
+	t.Helper()
+	if len(fx.sp.events) == 0 {
+		t.Fatalf("expected events")
+	}
+	return fx.sp.events[len(fx.sp.events)-1]
+}
+
+func TestMandatePullsOncePerPeriodAndReportsFailures(t *testing.T) {
+	fx := newMandateFixture(t)
+	var merchant [20]byte
+	copy(merchant[:], fx.merchant.PubKey().Address().Bytes())
+	id := fx.create(t, mandateCreatePayload{
+		Merchant:      merchant,
+		Asset:         "nhb",
+		MaxAmount:     big.NewInt(100),
+		PeriodSeconds: 3600,
+	})
+	if evt := fx.lastEvent(t); evt.Type != events.TypeMandateCreated {
+		t.Fatalf("expected %s, got %s", events.TypeMandateCreated, evt.Type)
+	}
+
+	if err := fx.pull(t, id, 101); err == nil {
+		t.Fatalf("expected pull above the per-period maximum to fail")
+	}
+	if err := fx.pull(t, id, 100); err != nil {
+		t.Fatalf("first pull: %v", err)
+	}
+	if got := fx.balanceNHB(t, fx.merchant); got != 100 {
+		t.Fatalf("merchant balance = %d, want 100", got)
+	}
+	if err := fx.pull(t, id, 10); !errors.Is(err, ErrMandateNotPullable) {
+		t.Fatalf("expected second pull in the same period to fail, got %v", err)
+	}
+
+	fx.advance(fx.now.Add(time.Hour))
+	if err := fx.pull(t, id, 100); err != nil {
+		t.Fatalf("second period pull: %v", err)
+	}
+
+	fx.advance(fx.now.Add(time.Hour))
+	if err := fx.pull(t, id, 100); err != nil {
+		t.Fatalf("failed pull must not fail the transaction: %v", err)
+	}
+	evt := fx.lastEvent(t)
+	if evt.Type != events.TypeMandatePullFailed {
+		t.Fatalf("expected %s, got %s", events.TypeMandatePullFailed, evt.Type)
+	}
+	if evt.Attributes["reason"] != "insufficient_balance" || evt.Attributes["attempts"] != "1" {
+		t.Fatalf("unexpected failure attributes: %v", evt.Attributes)
+	}
+	if got := fx.balanceNHB(t, fx.payer); got != 50 {
+		t.Fatalf("payer balance = %d, want 50", got)
+	}
+	// The failed period stays open for a retry at a lower amount.
+	if err := fx.pull(t, id, 50); err != nil {
+		t.Fatalf("retry pull: %v", err)
+	}
+	mandate, err := fx.sp.GetMandate(id)
+	if err != nil {
+		t.Fatalf("load mandate: %v", err)
+	}
+	if mandate.PullCount != 3 || mandate.PulledTotal.Int64() != 250 || mandate.FailedPulls != 0 {
+		t.Fatalf("unexpected mandate state: %+v", mandate)
+	}
+
+	cancel := fx.tx(t, fx.payer, types.TxTypeCancelMandate, mandateCancelPayload{MandateID: id})
+	if err := fx.sp.ApplyTransaction(cancel); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	fx.advance(fx.now.Add(time.Hour))
+	if err := fx.pull(t, id, 1); !errors.Is(err, ErrMandateNotPullable) {
+		t.Fatalf("expected pull after cancel to fail, got %v", err)
+	}
+}
+
+func TestMandateRejectsForeignPullerAndEndedMandate(t *testing.T) {
+	fx := newMandateFixture(t)
+	var merchant [20]byte
+	copy(merchant[:], fx.merchant.PubKey().Address().Bytes())
+	id := fx.create(t, mandateCreatePayload{
+		Merchant:      merchant,
+		Asset:         "NHB",
+		MaxAmount:     big.NewInt(10),
+		PeriodSeconds: 60,
+		EndAt:         uint64(fx.now.Add(2 * time.Minute).Unix()),
+	})
+	foreign := fx.tx(t, fx.payer, types.TxTypePullMandate, mandatePullPayload{MandateID: id, Amount: big.NewInt(10)})
+	if err := fx.sp.ApplyTransaction(foreign); !errors.Is(err, ErrMandateUnauthorized) {
+		t.Fatalf("expected payer pull to be unauthorized, got %v", err)
+	}
+	fx.advance(fx.now.Add(2 * time.Minute))
+	if err := fx.pull(t, id, 10); !errors.Is(err, ErrMandateNotPullable) {
+		t.Fatalf("expected pull after end date to fail, got %v", err)
+	}
+}
+
+func TestMandatePullSponsoredByPaymaster(t *testing.T) {
+	fx := newMandateFixture(t)
+	fx.sp.SetPaymasterEnabled(true)
+	paymasterKey, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate paymaster: %v", err)
+	}
+	paymaster := paymasterKey.PubKey().Address().Bytes()
+	if err := fx.sp.setAccount(paymaster, &types.Account{
+		BalanceNHB: big.NewInt(100_000), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0),
+	}); err != nil {
+		t.Fatalf("seed paymaster: %v", err)
+	}
+	var merchant [20]byte
+	copy(merchant[:], fx.merchant.PubKey().Address().Bytes())
+	id := fx.create(t, mandateCreatePayload{
+		Merchant:      merchant,
+		Asset:         "NHB",
+		MaxAmount:     big.NewInt(40),
+		PeriodSeconds: 3600,
+	})
+
+	tx := fx.tx(t, fx.merchant, types.TxTypePullMandate, mandatePullPayload{MandateID: id, Amount: big.NewInt(40)})
+	tx.Paymaster = append([]byte(nil), paymaster...)
+	if err := tx.Sign(fx.merchant.PrivateKey); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	signPaymaster(t, tx, paymasterKey)
+	if err := fx.sp.ApplyTransaction(tx); err != nil {
+		t.Fatalf("sponsored pull: %v", err)
+	}
+	if got := fx.balanceNHB(t, paymasterKey); got != 75_000 {
+		t.Fatalf("paymaster balance = %d, want 75000", got)
+	}
+	if got := fx.balanceNHB(t, fx.merchant); got != 40 {
+		t.Fatalf("merchant balance = %d, want 40", got)
+	}
+	if evt := fx.lastEvent(t); evt.Type != events.TypeTxSponsorshipApplied {
+		t.Fatalf("expected %s, got %s", events.TypeTxSponsorshipApplied, evt.Type)
+	}
+}
@@ -55,7 +55,7 @@ func (sp *StateProcessor) applyPOSAuthorize(tx *types.Transaction) error {
 	copy(payer[:], payerDecoded.Bytes())
 	copy(merchant[:], merchantDecoded.Bytes())
 
-	_, err = lifecycle.Authorize(payer, merchant, amount, msg.GetExpiry(), msg.GetIntentRef())
+	_, err = lifecycle.AuthorizeAsset(payer, merchant, msg.GetToken(), amount, msg.GetExpiry(), msg.GetIntentRef())
 	return err
 }
 
@@ -84,14 +84,11 @@ func (sp *StateProcessor) applyPOSCapture(tx *types.Transaction) error {
 	lifecycle.SetEmitter(stateProcessorEmitter{sp: sp})
 	lifecycle.SetNowFunc(func() time.Time { return sp.blockTimestamp().UTC() })
 
-	var authID [32]byte
-	copy(authID[:], []byte(msg.GetAuthorizationId()))
-	if len(msg.GetAuthorizationId()) == 64 {
-		// assuming hex encoded
-		parsed, _ := common.ParseHexOrString(msg.GetAuthorizationId())
-		copy(authID[:], parsed)
+	authID := posAuthorizationID(msg.GetAuthorizationId())
+	if msg.GetPartial() {
+		_, err = lifecycle.CapturePartial(authID, amount, caller)
+		return err
 	}
-
 	_, err = lifecycle.Capture(authID, amount, caller)
 	return err
 }
@@ -116,17 +113,79 @@ func (sp *StateProcessor) applyPOSVoid(tx *types.Transaction) error {
 	lifecycle.SetEmitter(stateProcessorEmitter{sp: sp})
 	lifecycle.SetNowFunc(func() time.Time { return sp.blockTimestamp().UTC() })
 
-	var authID [32]byte
-	copy(authID[:], []byte(msg.GetAuthorizationId()))
-	if len(msg.GetAuthorizationId()) == 64 {
-		parsed, _ := common.ParseHexOrString(msg.GetAuthorizationId())
-		copy(authID[:], parsed)
+	_, err = lifecycle.Void(posAuthorizationID(msg.GetAuthorizationId()), msg.GetReason(), caller)
+	return err
+}
+
+// applyPOSIncrement locks more funds on a pending authorization. Only the
+// payer may increment it, enforced against the transaction's recovered
+// signer since the extra funds come out of the signer's balance.
+func (sp *StateProcessor) applyPOSIncrement(tx *types.Transaction) error {
+	var msg posv1.MsgIncrementAuthorization
+	if err := proto.Unmarshal(tx.Data, &msg); err != nil {
+		return fmt.Errorf("pos: decode increment msg: %w", err)
+	}
+	signer, err := tx.From()
+	if err != nil {
+		return fmt.Errorf("pos: recover signer: %w", err)
+	}
+	var caller [20]byte
+	copy(caller[:], signer)
+
+	amount, ok := new(big.Int).SetString(msg.GetAmount(), 10)
+	if !ok || amount.Sign() <= 0 {
+		return fmt.Errorf("pos: invalid amount")
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	lifecycle := pos.NewLifecycle(manager)
+	lifecycle.SetEmitter(stateProcessorEmitter{sp: sp})
+	lifecycle.SetNowFunc(func() time.Time { return sp.blockTimestamp().UTC() })
+
+	_, err = lifecycle.Increment(posAuthorizationID(msg.GetAuthorizationId()), amount, msg.GetExpiry(), caller)
+	return err
+}
+
+// applyPOSRefund returns captured funds of an authorization to the payer.
+// Only the merchant on that authorization may refund, enforced against the
+// transaction's recovered signer, same as applyPOSCapture.
+func (sp *StateProcessor) applyPOSRefund(tx *types.Transaction) error {
+	var msg posv1.MsgRefundPayment
+	if err := proto.Unmarshal(tx.Data, &msg); err != nil {
+		return fmt.Errorf("pos: decode refund msg: %w", err)
+	}
+	signer, err := tx.From()
+	if err != nil {
+		return fmt.Errorf("pos: recover signer: %w", err)
+	}
+	var caller [20]byte
+	copy(caller[:], signer)
+
+	amount, ok := new(big.Int).SetString(msg.GetAmount(), 10)
+	if !ok || amount.Sign() <= 0 {
+		return fmt.Errorf("pos: invalid amount")
 	}
+	manager := nhbstate.NewManager(sp.Trie)
+	lifecycle := pos.NewLifecycle(manager)
+	lifecycle.SetEmitter(stateProcessorEmitter{sp: sp})
+	lifecycle.SetNowFunc(func() time.Time { return sp.blockTimestamp().UTC() })
 
-	_, err = lifecycle.Void(authID, msg.GetReason(), caller)
+	_, err = lifecycle.Refund(posAuthorizationID(msg.GetAuthorizationId()), amount, msg.GetReason(), caller)
 	return err
 }
 
+// posAuthorizationID decodes the authorization identifier carried by POS
+// messages. 64-character values are treated as hex; anything else is copied
+// as raw bytes.
+func posAuthorizationID(raw string) [32]byte {
+	var authID [32]byte
+	copy(authID[:], []byte(raw))
+	if len(raw) == 64 {
+		parsed, _ := common.ParseHexOrString(raw)
+		copy(authID[:], parsed)
+	}
+	return authID
+}
+
 // GetPOSAuthorization returns the authorization record for the given ID, if
 // one exists. Read-only: safe to call outside of transaction application.
 func (sp *StateProcessor) GetPOSAuthorization(id [32]byte) (*pos.Authorization, error) {
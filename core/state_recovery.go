@@ -0,0 +1,534 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+	"nhbchain/native/loyalty"
+)
+
+// recoveryGuardiansPayload is the RLP payload carried by
+// TxTypeSetRecoveryGuardians. An empty guardian list clears the set.
+type recoveryGuardiansPayload struct {
+	Guardians    [][20]byte
+	Threshold    uint32
+	DelaySeconds uint64
+}
+
+// recoveryApprovePayload is the RLP payload carried by TxTypeApproveRecovery.
+type recoveryApprovePayload struct {
+	Account    [20]byte
+	NewAddress [20]byte
+}
+
+// recoveryExecutePayload is the RLP payload carried by TxTypeExecuteRecovery.
+type recoveryExecutePayload struct {
+	Account [20]byte
+}
+
+// applySetRecoveryGuardians registers, replaces or clears the sender's
+// guardian set. The set cannot change while a rotation is pending; the owner
+// cancels it first.
+func (sp *StateProcessor) applySetRecoveryGuardians(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload recoveryGuardiansPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("setGuardians: decode payload: %w", err)
+	}
+	var owner [20]byte
+	copy(owner[:], sender)
+	manager := nhbstate.NewManager(sp.Trie)
+	if _, pending, err := manager.RecoveryGetPending(owner); err != nil {
+		return fmt.Errorf("setGuardians: %w", err)
+	} else if pending {
+		return fmt.Errorf("setGuardians: cancel the pending rotation first")
+	}
+	var cfg *nhbstate.RecoveryConfig
+	if len(payload.Guardians) > 0 {
+		cfg = &nhbstate.RecoveryConfig{
+			Guardians:    append([][20]byte(nil), payload.Guardians...),
+			Threshold:    payload.Threshold,
+			DelaySeconds: payload.DelaySeconds,
+			UpdatedAt:    uint64(sp.blockTimestamp().Unix()),
+		}
+		if err := cfg.Validate(owner); err != nil {
+			return fmt.Errorf("setGuardians: %w", err)
+		}
+	} else if _, ok, err := manager.RecoveryGetConfig(owner); err != nil {
+		return fmt.Errorf("setGuardians: %w", err)
+	} else if !ok {
+		return fmt.Errorf("setGuardians: %w", nhbstate.ErrRecoveryNotConfigured)
+	}
+	if err := manager.RecoveryPutConfig(owner, cfg); err != nil {
+		return fmt.Errorf("setGuardians: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("setGuardians: persist owner: %w", err)
+	}
+	evt := events.RecoveryGuardiansSet{Account: owner}
+	if cfg != nil {
+		evt.Guardians = cfg.Guardians
+		evt.Threshold = cfg.Threshold
+		evt.DelaySeconds = cfg.DelaySeconds
+	}
+	sp.AppendEvent(evt.Event())
+	return nil
+}
+
+// applyApproveRecovery records a guardian's approval to rotate an account to
+// a new address. The first approval opens the request. Guardians may back
+// different addresses and move their approval; the delay starts once one
+// address reaches the threshold and restarts if another address overtakes it.
+func (sp *StateProcessor) applyApproveRecovery(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload recoveryApprovePayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("approveRecovery: decode payload: %w", err)
+	}
+	if payload.NewAddress == ([20]byte{}) || payload.NewAddress == payload.Account {
+		return fmt.Errorf("approveRecovery: new address must differ from the account")
+	}
+	var guardian [20]byte
+	copy(guardian[:], sender)
+	manager := nhbstate.NewManager(sp.Trie)
+	cfg, ok, err := manager.RecoveryGetConfig(payload.Account)
+	if err != nil {
+		return fmt.Errorf("approveRecovery: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("approveRecovery: %w", nhbstate.ErrRecoveryNotConfigured)
+	}
+	if !cfg.IsGuardian(guardian) {
+		return fmt.Errorf("approveRecovery: sender is not a guardian of the account")
+	}
+	now := uint64(sp.blockTimestamp().Unix())
+	req, pending, err := manager.RecoveryGetPending(payload.Account)
+	if err != nil {
+		return fmt.Errorf("approveRecovery: %w", err)
+	}
+	if !pending {
+		req = &nhbstate.RecoveryRequest{Account: payload.Account, CreatedAt: now}
+	}
+	if current, ok := req.Vote(guardian); ok && current == payload.NewAddress {
+		return fmt.Errorf("approveRecovery: guardian already approved")
+	}
+	req.SetVote(guardian, payload.NewAddress)
+	threshold := int(cfg.Threshold)
+	if req.ExecutableAt != 0 && len(req.Approvals(req.NewAddress)) < threshold {
+		req.NewAddress = [20]byte{}
+		req.ExecutableAt = 0
+	}
+	approvals := len(req.Approvals(payload.NewAddress))
+	if req.ExecutableAt == 0 && approvals >= threshold {
+		req.NewAddress = payload.NewAddress
+		req.ExecutableAt = now + cfg.DelaySeconds
+	}
+	if err := manager.RecoveryPutPending(req); err != nil {
+		return fmt.Errorf("approveRecovery: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("approveRecovery: persist guardian: %w", err)
+	}
+	evt := events.RecoveryApproved{
+		Account:    req.Account,
+		NewAddress: payload.NewAddress,
+		Guardian:   guardian,
+		Approvals:  approvals,
+		Threshold:  cfg.Threshold,
+	}
+	if req.NewAddress == payload.NewAddress {
+		evt.ExecutableAt = req.ExecutableAt
+	}
+	sp.AppendEvent(evt.Event())
+	return nil
+}
+
+// applyCancelRecovery lets the account owner drop a pending rotation at any
+// point before it executes.
+func (sp *StateProcessor) applyCancelRecovery(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var owner [20]byte
+	copy(owner[:], sender)
+	manager := nhbstate.NewManager(sp.Trie)
+	req, ok, err := manager.RecoveryGetPending(owner)
+	if err != nil {
+		return fmt.Errorf("cancelRecovery: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("cancelRecovery: %w", nhbstate.ErrRecoveryNotPending)
+	}
+	if err := manager.RecoveryDeletePending(owner); err != nil {
+		return fmt.Errorf("cancelRecovery: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("cancelRecovery: persist owner: %w", err)
+	}
+	sp.AppendEvent(events.RecoveryCancelled{Account: owner, NewAddress: req.NewAddress}.Event())
+	return nil
+}
+
+// applyExecuteRecovery completes a rotation whose delay has passed. Anyone may
+// submit it. The account's balances, issued assets, staking position,
+// delegation, pending unbonds, lending positions, loyalty rewards, username
+// and every alias it owns or is linked to move to the new address, as does
+// the guardian set.
+func (sp *StateProcessor) applyExecuteRecovery(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload recoveryExecutePayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("executeRecovery: decode payload: %w", err)
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	req, ok, err := manager.RecoveryGetPending(payload.Account)
+	if err != nil {
+		return fmt.Errorf("executeRecovery: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("executeRecovery: %w", nhbstate.ErrRecoveryNotPending)
+	}
+	now := sp.blockTimestamp().Unix()
+	if req.ExecutableAt == 0 {
+		return fmt.Errorf("executeRecovery: rotation has not reached its approval threshold")
+	}
+	if uint64(now) < req.ExecutableAt {
+		return fmt.Errorf("executeRecovery: rotation executable at %d", req.ExecutableAt)
+	}
+	cfg, _, err := manager.RecoveryGetConfig(req.Account)
+	if err != nil {
+		return fmt.Errorf("executeRecovery: %w", err)
+	}
+
+	from, err := sp.getAccount(req.Account[:])
+	if err != nil {
+		return fmt.Errorf("executeRecovery: load account: %w", err)
+	}
+	to, err := sp.getAccount(req.NewAddress[:])
+	if err != nil {
+		return fmt.Errorf("executeRecovery: load new address: %w", err)
+	}
+	if err := sp.checkRecoveryTarget(manager, req, from, to); err != nil {
+		return fmt.Errorf("executeRecovery: %w", err)
+	}
+	// The submitter may be either side of the rotation, in which case its
+	// nonce is bumped on the account that is about to be written.
+	switch {
+	case bytes.Equal(sender, req.Account[:]):
+		from.Nonce++
+	case bytes.Equal(sender, req.NewAddress[:]):
+		to.Nonce++
+	default:
+		senderAccount.Nonce++
+		if err := sp.setAccount(sender, senderAccount); err != nil {
+			return fmt.Errorf("executeRecovery: persist sender: %w", err)
+		}
+	}
+
+	migrateRecoveredAccount(from, to)
+	if err := sp.setAccount(req.Account[:], from); err != nil {
+		return fmt.Errorf("executeRecovery: persist account: %w", err)
+	}
+	if err := sp.setAccount(req.NewAddress[:], to); err != nil {
+		return fmt.Errorf("executeRecovery: persist new address: %w", err)
+	}
+	if err := manager.PutAccountStakingRewards(req.NewAddress[:], &to.StakingRewards); err != nil {
+		return fmt.Errorf("executeRecovery: move staking rewards: %w", err)
+	}
+	if err := manager.PutAccountStakingRewards(req.Account[:], &from.StakingRewards); err != nil {
+		return fmt.Errorf("executeRecovery: clear staking rewards: %w", err)
+	}
+	alias, err := manager.IdentityReassignOwner(req.Account, req.NewAddress, now)
+	if err != nil {
+		return fmt.Errorf("executeRecovery: move alias: %w", err)
+	}
+	if err := migrateRecoveredPositions(manager, req.Account, req.NewAddress, uint64(now)); err != nil {
+		return fmt.Errorf("executeRecovery: %w", err)
+	}
+	if err := manager.RecoveryDeletePending(req.Account); err != nil {
+		return fmt.Errorf("executeRecovery: %w", err)
+	}
+	if err := manager.RecoveryPutConfig(req.Account, nil); err != nil {
+		return fmt.Errorf("executeRecovery: %w", err)
+	}
+	if cfg != nil && !cfg.IsGuardian(req.NewAddress) {
+		if err := manager.RecoveryPutConfig(req.NewAddress, cfg); err != nil {
+			return fmt.Errorf("executeRecovery: %w", err)
+		}
+	}
+	sp.AppendEvent(events.RecoveryExecuted{Account: req.Account, NewAddress: req.NewAddress, Alias: alias}.Event())
+	return nil
+}
+
+// checkRecoveryTarget rejects rotations that would merge two positions the
+// chain cannot combine. The new address may hold NHB and ZNHB, which are
+// added to the migrated balances, but nothing else. Accounts with validator
+// stake are excluded because delegators reference the validator address.
+func (sp *StateProcessor) checkRecoveryTarget(manager *nhbstate.Manager, req *nhbstate.RecoveryRequest, from, to *types.Account) error {
+	if from.Stake.Sign() > 0 {
+		return fmt.Errorf("accounts holding validator stake cannot be recovered")
+	}
+	if _, ok := sp.ValidatorSet[string(req.Account[:])]; ok {
+		return fmt.Errorf("validators cannot be recovered")
+	}
+	if to.Stake.Sign() > 0 || to.LockedZNHB.Sign() > 0 || to.StakeShares.Sign() > 0 ||
+		len(to.DelegatedValidator) > 0 || len(to.PendingUnbonds) > 0 ||
+		to.StakingRewards.AccruedZNHB.Sign() > 0 {
+		return fmt.Errorf("new address has a staking position")
+	}
+	if to.CollateralBalance.Sign() > 0 || to.DebtPrincipal.Sign() > 0 || to.SupplyShares.Sign() > 0 {
+		return fmt.Errorf("new address has a lending position")
+	}
+	if open, err := hasLendingPosition(manager, req.NewAddress); err != nil {
+		return err
+	} else if open {
+		return fmt.Errorf("new address has a lending position")
+	}
+	if from.Username != "" && to.Username != "" {
+		return fmt.Errorf("new address already has a username")
+	}
+	if len(to.CodeHash) > 0 && !bytes.Equal(to.CodeHash, gethtypes.EmptyCodeHash.Bytes()) {
+		return fmt.Errorf("new address is a contract")
+	}
+	if alias, owns := manager.IdentityReverse(req.Account[:]); owns {
+		if linked, ok := manager.IdentityReverse(req.NewAddress[:]); ok && linked != alias {
+			return fmt.Errorf("new address is linked to alias %s", linked)
+		}
+	}
+	if _, ok, err := manager.RecoveryGetPending(req.NewAddress); err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("new address has a pending rotation")
+	}
+	return nil
+}
+
+// migrateRecoveredAccount moves the state of from onto to and leaves from
+// empty apart from its nonce. to keeps its own nonce.
+func migrateRecoveredAccount(from, to *types.Account) {
+	to.BalanceNHB = new(big.Int).Add(to.BalanceNHB, from.BalanceNHB)
+	to.BalanceZNHB = new(big.Int).Add(to.BalanceZNHB, from.BalanceZNHB)
+	to.StakeShares = from.StakeShares
+	to.StakeLastIndex = from.StakeLastIndex
+	to.StakeLastPayoutTs = from.StakeLastPayoutTs
+	to.LockedZNHB = from.LockedZNHB
+	to.StakingRewards = from.StakingRewards
+	to.DelegatedValidator = from.DelegatedValidator
+	to.RewardBeneficiary = from.RewardBeneficiary
+	to.PendingUnbonds = from.PendingUnbonds
+	to.NextUnbondingID = from.NextUnbondingID
+	if from.Username != "" {
+		to.Username = from.Username
+	}
+	to.EngagementScore = from.EngagementScore
+	to.EngagementDay = from.EngagementDay
+	to.EngagementMinutes = from.EngagementMinutes
+	to.EngagementTxCount = from.EngagementTxCount
+	to.EngagementEscrowEvents = from.EngagementEscrowEvents
+	to.EngagementGovEvents = from.EngagementGovEvents
+	to.EngagementLastHeartbeat = from.EngagementLastHeartbeat
+	to.CollateralBalance = from.CollateralBalance
+	to.DebtPrincipal = from.DebtPrincipal
+	to.SupplyShares = from.SupplyShares
+	to.LendingSnapshot = from.LendingSnapshot
+	to.LendingBreaker = from.LendingBreaker
+
+	*from = types.Account{
+		Nonce:       from.Nonce,
+		CodeHash:    from.CodeHash,
+		StorageRoot: from.StorageRoot,
+	}
+	ensureAccountDefaults(from)
+}
+
+// migrateRecoveredPositions moves the state kept outside the account record:
+// the lending position in every pool, issued-asset balances together with any
+// freeze, and for every loyalty program the reward lots, lifetime issuance
+// and the spend inside the tier window.
+func migrateRecoveredPositions(manager *nhbstate.Manager, from, to [20]byte, now uint64) error {
+	if err := migrateLendingPositions(manager, from, to); err != nil {
+		return fmt.Errorf("move lending position: %w", err)
+	}
+	if err := migrateAssetBalances(manager, from, to); err != nil {
+		return fmt.Errorf("move issued assets: %w", err)
+	}
+	if err := migrateLoyaltyState(manager, from, to, now); err != nil {
+		return fmt.Errorf("move loyalty state: %w", err)
+	}
+	return nil
+}
+
+// hasLendingPosition reports whether addr holds collateral, supply or debt in
+// any lending pool.
+func hasLendingPosition(manager *nhbstate.Manager, addr [20]byte) (bool, error) {
+	poolIDs, err := manager.LendingListPoolIDs()
+	if err != nil {
+		return false, err
+	}
+	for _, poolID := range poolIDs {
+		position, ok, err := manager.LendingGetUserAccount(poolID, addr)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			continue
+		}
+		for _, asset := range position.CollateralAssets() {
+			if position.CollateralOf(asset).Sign() > 0 {
+				return true, nil
+			}
+		}
+		for _, amount := range []*big.Int{position.SupplyShares, position.DebtNHB, position.ScaledDebt} {
+			if amount != nil && amount.Sign() > 0 {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func migrateLendingPositions(manager *nhbstate.Manager, from, to [20]byte) error {
+	poolIDs, err := manager.LendingListPoolIDs()
+	if err != nil {
+		return err
+	}
+	for _, poolID := range poolIDs {
+		position, ok, err := manager.LendingGetUserAccount(poolID, from)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		moved := *position
+		moved.Address = crypto.MustNewAddress(crypto.NHBPrefix, to[:])
+		if err := manager.LendingPutUserAccount(poolID, &moved); err != nil {
+			return err
+		}
+		if err := manager.LendingDeleteUserAccount(poolID, from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateAssetBalances adds the issued-asset balances of from to those of to.
+// A freeze moves with the holder so that recovery cannot lift it.
+func migrateAssetBalances(manager *nhbstate.Manager, from, to [20]byte) error {
+	assets, err := manager.IssuedAssets()
+	if err != nil {
+		return err
+	}
+	for _, meta := range assets {
+		balance, err := manager.Balance(from[:], meta.Symbol)
+		if err != nil {
+			return err
+		}
+		if balance.Sign() > 0 {
+			held, err := manager.Balance(to[:], meta.Symbol)
+			if err != nil {
+				return err
+			}
+			if err := manager.SetBalance(to[:], meta.Symbol, new(big.Int).Add(held, balance)); err != nil {
+				return err
+			}
+			if err := manager.SetBalance(from[:], meta.Symbol, big.NewInt(0)); err != nil {
+				return err
+			}
+		}
+		frozen, err := manager.AssetFrozen(meta.Symbol, from[:])
+		if err != nil {
+			return err
+		}
+		if !frozen {
+			continue
+		}
+		if err := manager.SetAssetFrozen(meta.Symbol, to[:], true); err != nil {
+			return err
+		}
+		if err := manager.SetAssetFrozen(meta.Symbol, from[:], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLoyaltyState moves the per-program loyalty ledger. The reward lots
+// are merged oldest first; their funds stay in the reward vault.
+func migrateLoyaltyState(manager *nhbstate.Manager, from, to [20]byte, now uint64) error {
+	registry := loyalty.NewRegistry(manager)
+	ids, err := registry.ListPrograms()
+	if err != nil {
+		return err
+	}
+	today := loyalty.DayIndex(now)
+	for _, id := range ids {
+		program, ok := registry.GetProgram(id)
+		if !ok {
+			continue
+		}
+		lots, err := manager.LoyaltyRewardLots(id, from[:])
+		if err != nil {
+			return err
+		}
+		if len(lots) > 0 {
+			held, err := manager.LoyaltyRewardLots(id, to[:])
+			if err != nil {
+				return err
+			}
+			merged := append(held, lots...)
+			sort.SliceStable(merged, func(i, j int) bool { return merged[i].AccruedAt < merged[j].AccruedAt })
+			if err := manager.SetLoyaltyRewardLots(id, to[:], merged); err != nil {
+				return err
+			}
+			if err := manager.SetLoyaltyRewardLots(id, from[:], nil); err != nil {
+				return err
+			}
+		}
+		if err := moveLoyaltyMeter(from, to, func(addr []byte) (*big.Int, error) {
+			return manager.LoyaltyProgramIssuanceAccrued(id, addr)
+		}, func(addr []byte, amount *big.Int) error {
+			return manager.SetLoyaltyProgramIssuanceAccrued(id, addr, amount)
+		}); err != nil {
+			return err
+		}
+		for i := uint64(0); i < uint64(program.TierWindowDays) && i <= today; i++ {
+			day := today - i
+			if err := moveLoyaltyMeter(from, to, func(addr []byte) (*big.Int, error) {
+				return manager.LoyaltyProgramSpend(id, addr, day)
+			}, func(addr []byte, amount *big.Int) error {
+				return manager.SetLoyaltyProgramSpend(id, addr, day, amount)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func moveLoyaltyMeter(from, to [20]byte, load func([]byte) (*big.Int, error), store func([]byte, *big.Int) error) error {
+	amount, err := load(from[:])
+	if err != nil {
+		return err
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil
+	}
+	held, err := load(to[:])
+	if err != nil {
+		return err
+	}
+	if held == nil {
+		held = big.NewInt(0)
+	}
+	if err := store(to[:], new(big.Int).Add(held, amount)); err != nil {
+		return err
+	}
+	return store(from[:], big.NewInt(0))
+}
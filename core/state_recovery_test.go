@@ -0,0 +1,521 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	"nhbchain/core/identity"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+	"nhbchain/native/lending"
+	"nhbchain/native/loyalty"
+)
+
+type recoveryFixture struct {
+	sp        *StateProcessor
+	now       time.Time
+	height    uint64
+	owner     *crypto.PrivateKey
+	newKey    *crypto.PrivateKey
+	guardians []*crypto.PrivateKey
+	nonces    map[[20]byte]uint64
+}
+
+func newRecoveryFixture(t *testing.T) *recoveryFixture {
+	t.Helper()
+	sp := newStakingStateProcessor(t)
+	fixed := time.Unix(1_700_000_000, 0).UTC()
+	sp.nowFunc = func() time.Time { return fixed }
+	sp.BeginBlock(1, fixed)
+	t.Cleanup(func() { sp.EndBlock() })
+
+	fx := &recoveryFixture{sp: sp, now: fixed, height: 1, nonces: make(map[[20]byte]uint64)}
+	keys := []**crypto.PrivateKey{&fx.owner, &fx.newKey}
+	fx.guardians = make([]*crypto.PrivateKey, 3)
+	for i := range fx.guardians {
+		keys = append(keys, &fx.guardians[i])
+	}
+	for _, key := range keys {
+		priv, err := crypto.GeneratePrivateKey()
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		*key = priv
+	}
+	return fx
+}
+
+func recoveryAddr(key *crypto.PrivateKey) [20]byte {
+	var addr [20]byte
+	copy(addr[:], key.PubKey().Address().Bytes())
+	return addr
+}
+
+func (fx *recoveryFixture) advance(d time.Duration) {
+	fx.sp.EndBlock()
+	fx.now = fx.now.Add(d)
+	fx.height++
+	fx.sp.BeginBlock(fx.height, fx.now)
+}
+
+func (fx *recoveryFixture) apply(t *testing.T, key *crypto.PrivateKey, txType types.TxType, payload interface{}) error {
+	t.Helper()
+	var data []byte
+	if payload != nil {
+		encoded, err := rlp.EncodeToBytes(payload)
+		if err != nil {
+			t.Fatalf("encode payload: %v", err)
+		}
+		data = encoded
+	}
+	addr := recoveryAddr(key)
+	tx := &types.Transaction{
+		ChainID:  types.NHBChainID(),
+		Type:     txType,
+		Nonce:    fx.nonces[addr],
+		GasLimit: 25_000,
+		GasPrice: big.NewInt(1),
+		Data:     data,
+	}
+	if err := tx.Sign(key.PrivateKey); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := fx.sp.ApplyTransaction(tx); err != nil {
+		return err
+	}
+	fx.nonces[addr]++
+	return nil
+}
+
+func (fx *recoveryFixture) setGuardians(t *testing.T, threshold uint32) {
+	t.Helper()
+	guardians := make([][20]byte, len(fx.guardians))
+	for i, key := range fx.guardians {
+		guardians[i] = recoveryAddr(key)
+	}
+	if err := fx.apply(t, fx.owner, types.TxTypeSetRecoveryGuardians, recoveryGuardiansPayload{
+		Guardians:    guardians,
+		Threshold:    threshold,
+		DelaySeconds: nhbstate.MinRecoveryDelaySeconds,
+	}); err != nil {
+		t.Fatalf("set guardians: %v", err)
+	}
+}
+
+func (fx *recoveryFixture) approve(t *testing.T, guardian *crypto.PrivateKey) error {
+	t.Helper()
+	return fx.apply(t, guardian, types.TxTypeApproveRecovery, recoveryApprovePayload{
+		Account:    recoveryAddr(fx.owner),
+		NewAddress: recoveryAddr(fx.newKey),
+	})
+}
+
+func TestRecoveryRotatesAccountAfterDelay(t *testing.T) {
+	fx := newRecoveryFixture(t)
+	owner := recoveryAddr(fx.owner)
+	newAddr := recoveryAddr(fx.newKey)
+	validator := [20]byte{0x42}
+	if err := fx.sp.setAccount(owner[:], &types.Account{
+		BalanceNHB:         big.NewInt(500),
+		BalanceZNHB:        big.NewInt(300),
+		Stake:              big.NewInt(0),
+		LockedZNHB:         big.NewInt(200),
+		DelegatedValidator: validator[:],
+		PendingUnbonds:     []types.StakeUnbond{{ID: 1, Validator: validator[:], Amount: big.NewInt(50), ReleaseTime: 1_700_100_000}},
+		NextUnbondingID:    1,
+		Username:           "alice",
+	}); err != nil {
+		t.Fatalf("seed owner: %v", err)
+	}
+	manager := nhbstate.NewManager(fx.sp.Trie)
+	if err := manager.IdentitySetAlias(owner[:], "alice"); err != nil {
+		t.Fatalf("set alias: %v", err)
+	}
+	fx.setGuardians(t, 2)
+
+	if err := fx.approve(t, fx.guardians[0]); err != nil {
+		t.Fatalf("first approval: %v", err)
+	}
+	execute := recoveryExecutePayload{Account: owner}
+	if err := fx.apply(t, fx.guardians[2], types.TxTypeExecuteRecovery, execute); err == nil {
+		t.Fatalf("expected execute below threshold to fail")
+	}
+	if err := fx.approve(t, fx.guardians[1]); err != nil {
+		t.Fatalf("second approval: %v", err)
+	}
+	req, ok, err := manager.RecoveryGetPending(owner)
+	if err != nil || !ok {
+		t.Fatalf("pending rotation: ok=%v err=%v", ok, err)
+	}
+	wantExecutable := uint64(fx.now.Unix()) + nhbstate.MinRecoveryDelaySeconds
+	if req.ExecutableAt != wantExecutable {
+		t.Fatalf("executableAt = %d, want %d", req.ExecutableAt, wantExecutable)
+	}
+	if err := fx.apply(t, fx.guardians[2], types.TxTypeExecuteRecovery, execute); err == nil {
+		t.Fatalf("expected execute during the delay to fail")
+	}
+
+	fx.advance(nhbstate.MinRecoveryDelaySeconds * time.Second)
+	if err := fx.apply(t, fx.guardians[2], types.TxTypeExecuteRecovery, execute); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	rotated, err := fx.sp.getAccount(newAddr[:])
+	if err != nil {
+		t.Fatalf("load new account: %v", err)
+	}
+	if rotated.BalanceNHB.Cmp(big.NewInt(500)) != 0 || rotated.BalanceZNHB.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("balances not migrated: nhb=%s znhb=%s", rotated.BalanceNHB, rotated.BalanceZNHB)
+	}
+	if rotated.LockedZNHB.Cmp(big.NewInt(200)) != 0 || string(rotated.DelegatedValidator) != string(validator[:]) {
+		t.Fatalf("delegation not migrated: locked=%s validator=%x", rotated.LockedZNHB, rotated.DelegatedValidator)
+	}
+	if len(rotated.PendingUnbonds) != 1 || rotated.PendingUnbonds[0].Amount.Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("unbonds not migrated: %+v", rotated.PendingUnbonds)
+	}
+	if rotated.Username != "alice" {
+		t.Fatalf("username = %q, want alice", rotated.Username)
+	}
+	old, err := fx.sp.getAccount(owner[:])
+	if err != nil {
+		t.Fatalf("load old account: %v", err)
+	}
+	if old.BalanceNHB.Sign() != 0 || old.LockedZNHB.Sign() != 0 || len(old.PendingUnbonds) != 0 || old.Username != "" {
+		t.Fatalf("old account not cleared: %+v", old)
+	}
+	if old.Nonce != fx.nonces[owner] {
+		t.Fatalf("old nonce = %d, want %d", old.Nonce, fx.nonces[owner])
+	}
+
+	record, ok := manager.IdentityResolve("alice")
+	if !ok || record.Owner != newAddr || record.Primary != newAddr {
+		t.Fatalf("alias not moved: %+v", record)
+	}
+	if _, linked := manager.IdentityReverse(owner[:]); linked {
+		t.Fatalf("old address still linked to alias")
+	}
+	if alias, _ := manager.IdentityReverse(newAddr[:]); alias != "alice" {
+		t.Fatalf("reverse lookup = %q, want alice", alias)
+	}
+	if _, ok, _ := manager.RecoveryGetConfig(owner); ok {
+		t.Fatalf("guardian set left on old address")
+	}
+	if cfg, ok, _ := manager.RecoveryGetConfig(newAddr); !ok || cfg.Threshold != 2 {
+		t.Fatalf("guardian set not moved: %+v", cfg)
+	}
+
+	evts := fx.sp.Events()
+	last := evts[len(evts)-1]
+	if last.Type != events.TypeRecoveryExecuted || last.Attributes["alias"] != "alice" {
+		t.Fatalf("unexpected final event: %+v", last)
+	}
+}
+
+func TestRecoveryMovesPositionsOutsideTheAccount(t *testing.T) {
+	fx := newRecoveryFixture(t)
+	owner := recoveryAddr(fx.owner)
+	newAddr := recoveryAddr(fx.newKey)
+	manager := nhbstate.NewManager(fx.sp.Trie)
+
+	if err := manager.LendingPutMarket("default", &lending.Market{PoolID: "default"}); err != nil {
+		t.Fatalf("put market: %v", err)
+	}
+	if err := manager.LendingPutUserAccount("default", &lending.UserAccount{
+		Address:    crypto.MustNewAddress(crypto.NHBPrefix, owner[:]),
+		Collateral: map[string]*big.Int{lending.AssetZNHB: big.NewInt(900)},
+		DebtNHB:    big.NewInt(150),
+		ScaledDebt: big.NewInt(149),
+	}); err != nil {
+		t.Fatalf("put lending position: %v", err)
+	}
+	if err := manager.CreateAsset(&nhbstate.TokenMetadata{
+		Symbol:        "GOLD",
+		Name:          "Gold",
+		Issuer:        owner[:],
+		MintAuthority: owner[:],
+	}); err != nil {
+		t.Fatalf("create asset: %v", err)
+	}
+	if _, err := manager.MintAsset("GOLD", owner[:], big.NewInt(70)); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	if err := manager.SetAssetFrozen("GOLD", owner[:], true); err != nil {
+		t.Fatalf("freeze: %v", err)
+	}
+	if !manager.TokenExists("ZNHB") {
+		if err := manager.RegisterToken("ZNHB", "ZapNHB", 18); err != nil {
+			t.Fatalf("register ZNHB: %v", err)
+		}
+	}
+	var programID loyalty.ProgramID
+	programID[31] = 0x37
+	var pool [20]byte
+	pool[19] = 0x37
+	if err := loyalty.NewRegistry(manager).CreateProgram(pool, &loyalty.Program{
+		ID:               programID,
+		Owner:            pool,
+		Pool:             pool,
+		TokenSymbol:      "ZNHB",
+		AccrualBps:       100,
+		DailyCapProgram:  big.NewInt(10_000),
+		RewardExpiryDays: 30,
+		Active:           true,
+	}); err != nil {
+		t.Fatalf("create program: %v", err)
+	}
+	now := uint64(fx.now.Unix())
+	if err := manager.SetLoyaltyRewardLots(programID, owner[:], []loyalty.RewardLot{
+		{Amount: big.NewInt(40), Remaining: big.NewInt(40), AccruedAt: now, ExpiresAt: now + 30*24*3600},
+	}); err != nil {
+		t.Fatalf("seed lots: %v", err)
+	}
+	if err := manager.SetLoyaltyProgramIssuanceAccrued(programID, owner[:], big.NewInt(40)); err != nil {
+		t.Fatalf("seed issuance: %v", err)
+	}
+
+	fx.setGuardians(t, 1)
+	if err := fx.approve(t, fx.guardians[0]); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	fx.advance(nhbstate.MinRecoveryDelaySeconds * time.Second)
+	if err := fx.apply(t, fx.guardians[1], types.TxTypeExecuteRecovery, recoveryExecutePayload{Account: owner}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	position, ok, err := manager.LendingGetUserAccount("default", newAddr)
+	if err != nil || !ok || position.DebtNHB.Int64() != 150 || position.CollateralOf(lending.AssetZNHB).Int64() != 900 {
+		t.Fatalf("lending position not moved: ok=%v err=%v position=%+v", ok, err, position)
+	}
+	if !bytes.Equal(position.Address.Bytes(), newAddr[:]) {
+		t.Fatalf("lending position address = %x, want %x", position.Address.Bytes(), newAddr)
+	}
+	if _, ok, _ := manager.LendingGetUserAccount("default", owner); ok {
+		t.Fatalf("lending position left on the old address")
+	}
+	if balance, _ := manager.Balance(newAddr[:], "GOLD"); balance.Int64() != 70 {
+		t.Fatalf("asset balance = %s, want 70", balance)
+	}
+	if balance, _ := manager.Balance(owner[:], "GOLD"); balance.Sign() != 0 {
+		t.Fatalf("asset balance left on the old address: %s", balance)
+	}
+	if frozen, _ := manager.AssetFrozen("GOLD", newAddr[:]); !frozen {
+		t.Fatalf("expected the freeze to follow the holder")
+	}
+	lots, _ := manager.LoyaltyRewardLots(programID, newAddr[:])
+	if len(lots) != 1 || lots[0].Remaining.Int64() != 40 {
+		t.Fatalf("reward lots not moved: %+v", lots)
+	}
+	if lots, _ := manager.LoyaltyRewardLots(programID, owner[:]); len(lots) != 0 {
+		t.Fatalf("reward lots left on the old address: %+v", lots)
+	}
+	if issued, _ := manager.LoyaltyProgramIssuanceAccrued(programID, newAddr[:]); issued.Int64() != 40 {
+		t.Fatalf("issuance meter = %s, want 40", issued)
+	}
+}
+
+func TestRecoveryRejectsTargetWithLendingPosition(t *testing.T) {
+	fx := newRecoveryFixture(t)
+	owner := recoveryAddr(fx.owner)
+	newAddr := recoveryAddr(fx.newKey)
+	manager := nhbstate.NewManager(fx.sp.Trie)
+	if err := manager.LendingPutMarket("default", &lending.Market{PoolID: "default"}); err != nil {
+		t.Fatalf("put market: %v", err)
+	}
+	if err := manager.LendingPutUserAccount("default", &lending.UserAccount{
+		Address:      crypto.MustNewAddress(crypto.NHBPrefix, newAddr[:]),
+		SupplyShares: big.NewInt(5),
+	}); err != nil {
+		t.Fatalf("put lending position: %v", err)
+	}
+	fx.setGuardians(t, 1)
+	if err := fx.approve(t, fx.guardians[0]); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	fx.advance(nhbstate.MinRecoveryDelaySeconds * time.Second)
+	if err := fx.apply(t, fx.guardians[1], types.TxTypeExecuteRecovery, recoveryExecutePayload{Account: owner}); err == nil {
+		t.Fatalf("expected a target with a lending position to be rejected")
+	}
+}
+
+func TestRecoveryOwnerCancelsDuringDelay(t *testing.T) {
+	fx := newRecoveryFixture(t)
+	owner := recoveryAddr(fx.owner)
+	fx.setGuardians(t, 1)
+	if err := fx.approve(t, fx.guardians[0]); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if err := fx.apply(t, fx.owner, types.TxTypeSetRecoveryGuardians, recoveryGuardiansPayload{}); err == nil {
+		t.Fatalf("expected guardian change during a pending rotation to fail")
+	}
+	if err := fx.apply(t, fx.owner, types.TxTypeCancelRecovery, nil); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	fx.advance(nhbstate.MinRecoveryDelaySeconds * time.Second)
+	err := fx.apply(t, fx.guardians[1], types.TxTypeExecuteRecovery, recoveryExecutePayload{Account: owner})
+	if !errors.Is(err, nhbstate.ErrRecoveryNotPending) {
+		t.Fatalf("expected ErrRecoveryNotPending, got %v", err)
+	}
+	if err := fx.apply(t, fx.owner, types.TxTypeCancelRecovery, nil); !errors.Is(err, nhbstate.ErrRecoveryNotPending) {
+		t.Fatalf("expected second cancel to fail, got %v", err)
+	}
+}
+
+func TestRecoveryApprovalChecks(t *testing.T) {
+	fx := newRecoveryFixture(t)
+	owner := recoveryAddr(fx.owner)
+	if err := fx.approve(t, fx.guardians[0]); !errors.Is(err, nhbstate.ErrRecoveryNotConfigured) {
+		t.Fatalf("expected ErrRecoveryNotConfigured, got %v", err)
+	}
+	if err := fx.apply(t, fx.owner, types.TxTypeSetRecoveryGuardians, recoveryGuardiansPayload{
+		Guardians:    [][20]byte{owner},
+		Threshold:    1,
+		DelaySeconds: nhbstate.MinRecoveryDelaySeconds,
+	}); err == nil {
+		t.Fatalf("expected self-guardian to be rejected")
+	}
+	fx.setGuardians(t, 2)
+
+	if err := fx.approve(t, fx.newKey); err == nil {
+		t.Fatalf("expected approval from a non-guardian to fail")
+	}
+	if err := fx.approve(t, fx.guardians[0]); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if err := fx.approve(t, fx.guardians[0]); err == nil {
+		t.Fatalf("expected duplicate approval to fail")
+	}
+	other := recoveryApprovePayload{Account: owner, NewAddress: [20]byte{0x99}}
+	if err := fx.apply(t, fx.guardians[1], types.TxTypeApproveRecovery, other); err != nil {
+		t.Fatalf("approval for a competing address: %v", err)
+	}
+	req, ok, err := nhbstate.NewManager(fx.sp.Trie).RecoveryGetPending(owner)
+	if err != nil || !ok {
+		t.Fatalf("pending rotation: ok=%v err=%v", ok, err)
+	}
+	if req.ExecutableAt != 0 || len(req.Candidates()) != 2 {
+		t.Fatalf("split approvals must not reach the threshold: %+v", req)
+	}
+}
+
+func TestRecoverySingleGuardianCannotPinRotation(t *testing.T) {
+	fx := newRecoveryFixture(t)
+	owner := recoveryAddr(fx.owner)
+	newAddr := recoveryAddr(fx.newKey)
+	attacker := [20]byte{0x66}
+	manager := nhbstate.NewManager(fx.sp.Trie)
+	fx.setGuardians(t, 2)
+
+	// A rogue guardian opens the request for its own address first.
+	if err := fx.apply(t, fx.guardians[0], types.TxTypeApproveRecovery, recoveryApprovePayload{Account: owner, NewAddress: attacker}); err != nil {
+		t.Fatalf("rogue approval: %v", err)
+	}
+	if err := fx.approve(t, fx.guardians[1]); err != nil {
+		t.Fatalf("honest approval: %v", err)
+	}
+	if req, _, _ := manager.RecoveryGetPending(owner); req.ExecutableAt != 0 {
+		t.Fatalf("rotation executable without a quorum: %+v", req)
+	}
+	if err := fx.approve(t, fx.guardians[2]); err != nil {
+		t.Fatalf("second honest approval: %v", err)
+	}
+	req, _, err := manager.RecoveryGetPending(owner)
+	if err != nil || req.NewAddress != newAddr || req.ExecutableAt == 0 {
+		t.Fatalf("honest quorum did not win: req=%+v err=%v", req, err)
+	}
+
+	// Moving an approval away from the winning address drops it below the
+	// threshold and stops the clock.
+	if err := fx.apply(t, fx.guardians[2], types.TxTypeApproveRecovery, recoveryApprovePayload{Account: owner, NewAddress: [20]byte{0x67}}); err != nil {
+		t.Fatalf("switch approval: %v", err)
+	}
+	if req, _, _ := manager.RecoveryGetPending(owner); req.ExecutableAt != 0 || req.NewAddress != ([20]byte{}) {
+		t.Fatalf("expected rotation to lose its quorum: %+v", req)
+	}
+	if err := fx.approve(t, fx.guardians[2]); err != nil {
+		t.Fatalf("restore approval: %v", err)
+	}
+	fx.advance(nhbstate.MinRecoveryDelaySeconds * time.Second)
+	if err := fx.apply(t, fx.guardians[1], types.TxTypeExecuteRecovery, recoveryExecutePayload{Account: owner}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if _, ok, _ := manager.RecoveryGetConfig(newAddr); !ok {
+		t.Fatalf("rotation did not reach the honest address")
+	}
+}
+
+func TestRecoveryMovesEveryOwnedAlias(t *testing.T) {
+	fx := newRecoveryFixture(t)
+	owner := recoveryAddr(fx.owner)
+	newAddr := recoveryAddr(fx.newKey)
+	till := [20]byte{0x71}
+	manager := nhbstate.NewManager(fx.sp.Trie)
+	now := fx.now.Unix()
+
+	// "shop" is owned by the account but resolves to a till address, so the
+	// reverse lookup of the account points at "alice" only.
+	if err := manager.IdentitySetAlias(owner[:], "shop"); err != nil {
+		t.Fatalf("set shop alias: %v", err)
+	}
+	if _, err := manager.IdentityAddAddress("shop", till[:], now); err != nil {
+		t.Fatalf("add till: %v", err)
+	}
+	if _, err := manager.IdentitySetPrimary("shop", till[:], now); err != nil {
+		t.Fatalf("set primary: %v", err)
+	}
+	if _, err := manager.IdentityRemoveAddress("shop", owner[:], now); err != nil {
+		t.Fatalf("unlink owner: %v", err)
+	}
+	if err := manager.IdentitySetAlias(owner[:], "alice"); err != nil {
+		t.Fatalf("set alice alias: %v", err)
+	}
+	if err := manager.IdentityPutSubAlias(&identity.SubAlias{Label: "tips", Parent: "shop", Target: owner, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("put sub-alias: %v", err)
+	}
+	if _, err := manager.IdentityPutListing(&identity.Listing{Alias: "shop", Seller: owner, Price: big.NewInt(10), ListedAt: now}); err != nil {
+		t.Fatalf("list shop: %v", err)
+	}
+
+	fx.setGuardians(t, 1)
+	if err := fx.approve(t, fx.guardians[0]); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	fx.advance(nhbstate.MinRecoveryDelaySeconds * time.Second)
+	if err := fx.apply(t, fx.guardians[1], types.TxTypeExecuteRecovery, recoveryExecutePayload{Account: owner}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if record, ok := manager.IdentityResolve("alice"); !ok || record.Owner != newAddr || record.Primary != newAddr {
+		t.Fatalf("linked alias not moved: %+v", record)
+	}
+	shop, ok := manager.IdentityResolve("shop")
+	if !ok || shop.Owner != newAddr || shop.Primary != till {
+		t.Fatalf("owned alias not moved: %+v", shop)
+	}
+	if alias, _ := manager.IdentityReverse(till[:]); alias != "shop" {
+		t.Fatalf("till reverse lookup = %q, want shop", alias)
+	}
+	sub, ok, err := manager.IdentityGetSubAlias("tips.shop")
+	if err != nil || !ok || sub.Target != newAddr {
+		t.Fatalf("sub-alias not retargeted: sub=%+v ok=%v err=%v", sub, ok, err)
+	}
+	listing, ok, err := manager.IdentityGetListing("shop")
+	if err != nil || !ok || listing.Seller != newAddr {
+		t.Fatalf("listing not moved: listing=%+v ok=%v err=%v", listing, ok, err)
+	}
+	records, err := manager.IdentityAliases()
+	if err != nil {
+		t.Fatalf("list aliases: %v", err)
+	}
+	for _, record := range records {
+		if record.Owner == owner || record.Primary == owner {
+			t.Fatalf("alias %q still held by the old address", record.Alias)
+		}
+	}
+}
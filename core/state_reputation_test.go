@@ -0,0 +1,87 @@
+package core
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/native/escrow"
+	"nhbchain/native/reputation"
+)
+
+func TestFinalizeBlockAggregatesReputationAndGatesRealms(t *testing.T) {
+	sp := newStakingStateProcessor(t)
+	now := time.Unix(1_700_000_000, 0).UTC()
+	sp.nowFunc = func() time.Time { return now }
+	sp.BeginBlock(1, now)
+	t.Cleanup(func() { sp.EndBlock() })
+
+	var payer, payee, arbitrator [20]byte
+	payer[0], payee[0], arbitrator[0] = 0x11, 0x22, 0x33
+	manager := nhbstate.NewManager(sp.Trie)
+	if err := manager.EscrowRealmPut(&escrow.EscrowRealm{
+		ID:              "vetted",
+		Version:         1,
+		NextPolicyNonce: 1,
+		CreatedAt:       now.Unix(),
+		UpdatedAt:       now.Unix(),
+		Arbitrators: &escrow.ArbitratorSet{
+			Scheme:    escrow.ArbitrationSchemeSingle,
+			Threshold: 1,
+			Members:   [][20]byte{arbitrator},
+		},
+		Metadata:           &escrow.EscrowRealmMetadata{Scope: escrow.EscrowRealmScopePlatform, ProviderProfile: "vetted"},
+		MinReputationScore: 75,
+	}); err != nil {
+		t.Fatalf("put realm: %v", err)
+	}
+	stored, ok, err := manager.EscrowRealmGet("vetted")
+	if err != nil || !ok || stored.MinReputationScore != 75 {
+		t.Fatalf("realm round trip: ok=%v err=%v realm=%+v", ok, err, stored)
+	}
+
+	create := func(nonce uint64) error {
+		sp.configureTradeEngine()
+		_, err := sp.EscrowEngine.Create(payer, payee, "NHB", big.NewInt(10), 0, now.Unix()+3_600, nonce, nil, [32]byte{}, "vetted")
+		return err
+	}
+	if err := create(1); err == nil {
+		t.Fatalf("expected an unknown payee to fall short of the realm minimum")
+	}
+
+	for i := byte(1); i <= 10; i++ {
+		var id [32]byte
+		id[31] = i
+		sp.AppendEvent(&types.Event{Type: escrow.EventTypeEscrowReleased, Attributes: map[string]string{
+			"id":    hex.EncodeToString(id[:]),
+			"payer": hex.EncodeToString(payer[:]),
+			"payee": hex.EncodeToString(payee[:]),
+		}})
+	}
+	if err := sp.FinalizeBlock(); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	// The next block only folds in its own events.
+	sp.EndBlock()
+	sp.BeginBlock(2, now)
+	if err := sp.FinalizeBlock(); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+
+	agg, score, err := reputation.NewScoreBook(manager).Score(payee, now.Unix())
+	if err != nil {
+		t.Fatalf("score: %v", err)
+	}
+	if agg.EscrowsCompleted != 10 {
+		t.Fatalf("expected 10 completed escrows, got %d", agg.EscrowsCompleted)
+	}
+	if score.Total < 75 {
+		t.Fatalf("expected payee to clear the realm minimum, got %+v", score)
+	}
+	if err := create(2); err != nil {
+		t.Fatalf("create after reputation update: %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"google.golang.org/protobuf/proto"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+	posv1 "nhbchain/proto/pos"
+)
+
+// ErrSessionKeyDenied is returned when a session key signs a transaction its
+// policy does not allow.
+var ErrSessionKeyDenied = errors.New("session: transaction not allowed for session key")
+
+// sessionKeyTxTypes lists the transaction types a session key may be granted.
+// Each of them either moves no funds out of the account or moves a single
+// asset whose amount and counterparty are known before execution, so the
+// daily caps and counterparty list can be enforced up front.
+var sessionKeyTxTypes = map[types.TxType]struct{}{
+	types.TxTypeTransfer:      {},
+	types.TxTypeTransferZNHB:  {},
+	types.TxTypeHeartbeat:     {},
+	types.TxTypePOSAuthorize:  {},
+	types.TxTypePOSCapture:    {},
+	types.TxTypePOSVoid:       {},
+	types.TxTypeCreateInvoice: {},
+	types.TxTypeCancelInvoice: {},
+}
+
+// sessionKeyRegisterPayload is the RLP payload carried by
+// TxTypeRegisterSessionKey.
+type sessionKeyRegisterPayload struct {
+	Key            [20]byte
+	TxTypes        []uint8
+	DailyLimitNHB  *big.Int
+	DailyLimitZNHB *big.Int
+	Counterparties [][20]byte
+	ExpiresAt      uint64
+}
+
+// sessionKeyRevokePayload is the RLP payload carried by
+// TxTypeRevokeSessionKey.
+type sessionKeyRevokePayload struct {
+	Key [20]byte
+}
+
+// sessionAuthorization carries a session key that passed its policy checks
+// from validateSenderAccount to settleSessionKey.
+type sessionAuthorization struct {
+	key          *nhbstate.SessionKey
+	asset        string
+	amount       *big.Int
+	accountNonce uint64
+}
+
+// applyRegisterSessionKey adds or updates a session key of the sender. Updating
+// an existing key keeps its spend meters for the day.
+func (sp *StateProcessor) applyRegisterSessionKey(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload sessionKeyRegisterPayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("registerSessionKey: decode payload: %w", err)
+	}
+	var account [20]byte
+	copy(account[:], sender)
+	now := uint64(sp.blockTimestamp().Unix())
+	if payload.Key == ([20]byte{}) || payload.Key == account {
+		return fmt.Errorf("registerSessionKey: key must be a separate, non-zero address")
+	}
+	if payload.ExpiresAt <= now {
+		return fmt.Errorf("registerSessionKey: expiry must be in the future")
+	}
+	if len(payload.TxTypes) == 0 {
+		return fmt.Errorf("registerSessionKey: at least one transaction type is required")
+	}
+	seen := make(map[uint8]struct{}, len(payload.TxTypes))
+	for _, t := range payload.TxTypes {
+		if _, ok := sessionKeyTxTypes[types.TxType(t)]; !ok {
+			return fmt.Errorf("registerSessionKey: transaction type 0x%02x cannot be delegated", t)
+		}
+		if _, dup := seen[t]; dup {
+			return fmt.Errorf("registerSessionKey: duplicate transaction type 0x%02x", t)
+		}
+		seen[t] = struct{}{}
+	}
+	for _, limit := range []*big.Int{payload.DailyLimitNHB, payload.DailyLimitZNHB} {
+		if limit != nil && limit.Sign() < 0 {
+			return fmt.Errorf("registerSessionKey: daily limits must not be negative")
+		}
+	}
+	for _, counterparty := range payload.Counterparties {
+		if counterparty == ([20]byte{}) {
+			return fmt.Errorf("registerSessionKey: counterparty must not be zero")
+		}
+	}
+
+	// A session key address cannot also be used as a regular account: every
+	// transaction it signs is executed for the account it is bound to.
+	keyAccount, err := sp.getAccount(payload.Key[:])
+	if err != nil {
+		return fmt.Errorf("registerSessionKey: load key account: %w", err)
+	}
+	if keyAccount.BalanceNHB.Sign() > 0 || keyAccount.BalanceZNHB.Sign() > 0 || keyAccount.Stake.Sign() > 0 || keyAccount.LockedZNHB.Sign() > 0 {
+		return fmt.Errorf("registerSessionKey: key address must not hold funds")
+	}
+	manager := nhbstate.NewManager(sp.Trie)
+	if keys, err := manager.SessionKeysByAccount(payload.Key); err != nil {
+		return fmt.Errorf("registerSessionKey: %w", err)
+	} else if len(keys) > 0 {
+		return fmt.Errorf("registerSessionKey: key address has session keys of its own")
+	}
+
+	key := &nhbstate.SessionKey{
+		Key:            payload.Key,
+		Account:        account,
+		TxTypes:        append([]uint8(nil), payload.TxTypes...),
+		DailyLimitNHB:  nonNilAmount(payload.DailyLimitNHB),
+		DailyLimitZNHB: nonNilAmount(payload.DailyLimitZNHB),
+		Counterparties: append([][20]byte(nil), payload.Counterparties...),
+		ExpiresAt:      payload.ExpiresAt,
+		CreatedAt:      now,
+		SpentNHB:       big.NewInt(0),
+		SpentZNHB:      big.NewInt(0),
+	}
+	if existing, ok, err := manager.SessionKeyGet(payload.Key); err != nil {
+		return fmt.Errorf("registerSessionKey: %w", err)
+	} else if ok {
+		key.CreatedAt = existing.CreatedAt
+		key.SpentDay = existing.SpentDay
+		key.SpentNHB = nonNilAmount(existing.SpentNHB)
+		key.SpentZNHB = nonNilAmount(existing.SpentZNHB)
+	}
+	if err := manager.SessionKeyPut(key); err != nil {
+		return fmt.Errorf("registerSessionKey: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("registerSessionKey: persist account: %w", err)
+	}
+	sp.AppendEvent(events.SessionKeyRegistered{
+		Account:        account,
+		Key:            key.Key,
+		TxTypes:        key.TxTypes,
+		DailyLimitNHB:  key.DailyLimitNHB,
+		DailyLimitZNHB: key.DailyLimitZNHB,
+		Counterparties: len(key.Counterparties),
+		ExpiresAt:      key.ExpiresAt,
+	}.Event())
+	return nil
+}
+
+// applyRevokeSessionKey removes one of the sender's session keys. Transactions
+// the key signed afterwards are rejected.
+func (sp *StateProcessor) applyRevokeSessionKey(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
+	var payload sessionKeyRevokePayload
+	if err := rlp.DecodeBytes(tx.Data, &payload); err != nil {
+		return fmt.Errorf("revokeSessionKey: decode payload: %w", err)
+	}
+	var account [20]byte
+	copy(account[:], sender)
+	manager := nhbstate.NewManager(sp.Trie)
+	key, ok, err := manager.SessionKeyGet(payload.Key)
+	if err != nil {
+		return fmt.Errorf("revokeSessionKey: %w", err)
+	}
+	if !ok || key.Account != account {
+		return fmt.Errorf("revokeSessionKey: %w", nhbstate.ErrSessionKeyNotFound)
+	}
+	if err := manager.SessionKeyDelete(payload.Key); err != nil {
+		return fmt.Errorf("revokeSessionKey: %w", err)
+	}
+	senderAccount.Nonce++
+	if err := sp.setAccount(sender, senderAccount); err != nil {
+		return fmt.Errorf("revokeSessionKey: persist account: %w", err)
+	}
+	sp.AppendEvent(events.SessionKeyRevoked{Account: account, Key: payload.Key}.Event())
+	return nil
+}
+
+// authorizeSessionKey checks a transaction signed by signer against the
+// signer's session key policy. It returns nil when signer is not a session
+// key.
+func (sp *StateProcessor) authorizeSessionKey(tx *types.Transaction, signer []byte) (*sessionAuthorization, error) {
+	if len(signer) != 20 {
+		return nil, nil
+	}
+	var keyAddr [20]byte
+	copy(keyAddr[:], signer)
+	manager := nhbstate.NewManager(sp.Trie)
+	key, ok, err := manager.SessionKeyGet(keyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	now := sp.blockTimestamp()
+	if uint64(now.Unix()) >= key.ExpiresAt {
+		return nil, fmt.Errorf("%w: key expired", ErrSessionKeyDenied)
+	}
+	if !key.AllowsType(uint8(tx.Type)) {
+		return nil, fmt.Errorf("%w: type 0x%02x", ErrSessionKeyDenied, uint8(tx.Type))
+	}
+	asset, amount, counterparty, err := sessionKeySpend(tx)
+	if err != nil {
+		return nil, err
+	}
+	if counterparty != nil && !key.AllowsCounterparty(*counterparty) {
+		return nil, fmt.Errorf("%w: counterparty %s", ErrSessionKeyDenied, crypto.MustNewAddress(crypto.NHBPrefix, counterparty[:]).String())
+	}
+	fee, err := sp.sessionKeyTransferFee(tx, key.Account, asset, amount)
+	if err != nil {
+		return nil, err
+	}
+	if fee.Sign() > 0 {
+		amount = new(big.Int).Add(amount, fee)
+	}
+	if amount != nil && amount.Sign() > 0 {
+		day := now.UTC().Format("2006-01-02")
+		total := new(big.Int).Add(key.Spent(asset, day), amount)
+		if total.Cmp(key.DailyLimit(asset)) > 0 {
+			return nil, fmt.Errorf("%w: daily %s limit exceeded", ErrSessionKeyDenied, asset)
+		}
+	}
+	account, err := sp.getAccount(key.Account[:])
+	if err != nil {
+		return nil, fmt.Errorf("session: load account: %w", err)
+	}
+	return &sessionAuthorization{key: key, asset: asset, amount: amount, accountNonce: account.Nonce}, nil
+}
+
+// settleSessionKey runs after a transaction signed by a session key succeeded.
+// The key spends its own nonce rather than the account's, so the account's
+// nonce is put back and the key address's nonce advances instead. The amount
+// spent is added to the key's daily meter.
+func (sp *StateProcessor) settleSessionKey(auth *sessionAuthorization) error {
+	account, err := sp.getAccount(auth.key.Account[:])
+	if err != nil {
+		return fmt.Errorf("session: load account: %w", err)
+	}
+	if account.Nonce != auth.accountNonce {
+		account.Nonce = auth.accountNonce
+		if err := sp.setAccount(auth.key.Account[:], account); err != nil {
+			return fmt.Errorf("session: restore account nonce: %w", err)
+		}
+	}
+	keyAccount, err := sp.getAccount(auth.key.Key[:])
+	if err != nil {
+		return fmt.Errorf("session: load key account: %w", err)
+	}
+	keyAccount.Nonce++
+	if err := sp.setAccount(auth.key.Key[:], keyAccount); err != nil {
+		return fmt.Errorf("session: persist key nonce: %w", err)
+	}
+	if auth.amount == nil || auth.amount.Sign() <= 0 {
+		return nil
+	}
+	auth.key.RecordSpend(auth.asset, sp.blockTimestamp().UTC().Format("2006-01-02"), auth.amount)
+	manager := nhbstate.NewManager(sp.Trie)
+	if err := manager.SessionKeyPut(auth.key); err != nil {
+		return fmt.Errorf("session: record spend: %w", err)
+	}
+	return nil
+}
+
+// sessionKeyTransferFee returns the transfer fee a transfer signed by a
+// session key costs the account, so the fee counts towards the key's daily
+// limit alongside the value. Sponsored transfers are metered as if the
+// account paid the fee, keeping the limit an upper bound on what the key can
+// move out of the account.
+func (sp *StateProcessor) sessionKeyTransferFee(tx *types.Transaction, account [20]byte, asset string, amount *big.Int) (*big.Int, error) {
+	if tx.Type != types.TxTypeTransfer && tx.Type != types.TxTypeTransferZNHB {
+		return big.NewInt(0), nil
+	}
+	policy := sp.TransferGasPolicy()
+	fee := policy.ComputeFee(amount)
+	if fee.Sign() == 0 || !policy.Enabled {
+		return fee, nil
+	}
+	status, err := sp.transferGasStatus(account[:], asset)
+	if err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+	if status.Eligible {
+		return big.NewInt(0), nil
+	}
+	return fee, nil
+}
+
+// sessionKeySpend reports the asset and amount a transaction moves out of the
+// account and the counterparty receiving it. Captures, voids, invoices and
+// heartbeats move nothing out of the account and have no counterparty.
+func sessionKeySpend(tx *types.Transaction) (string, *big.Int, *[20]byte, error) {
+	switch tx.Type {
+	case types.TxTypeTransfer, types.TxTypeTransferZNHB:
+		asset := "NHB"
+		if tx.Type == types.TxTypeTransferZNHB {
+			asset = "ZNHB"
+		}
+		if len(tx.To) != 20 {
+			return "", nil, nil, fmt.Errorf("%w: recipient required", ErrSessionKeyDenied)
+		}
+		var to [20]byte
+		copy(to[:], tx.To)
+		return asset, nonNilAmount(tx.Value), &to, nil
+	case types.TxTypePOSAuthorize:
+		var msg posv1.MsgAuthorizePayment
+		if err := proto.Unmarshal(tx.Data, &msg); err != nil {
+			return "", nil, nil, fmt.Errorf("pos: decode authorize msg: %w", err)
+		}
+		merchant, err := crypto.DecodeAddress(msg.GetMerchant())
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("pos: invalid merchant: %w", err)
+		}
+		amount, ok := new(big.Int).SetString(msg.GetAmount(), 10)
+		if !ok || amount.Sign() <= 0 {
+			return "", nil, nil, fmt.Errorf("pos: invalid amount")
+		}
+		var counterparty [20]byte
+		copy(counterparty[:], merchant.Bytes())
+		asset := strings.ToUpper(strings.TrimSpace(msg.GetToken()))
+		if asset == "" {
+			asset = "ZNHB"
+		}
+		return asset, amount, &counterparty, nil
+	default:
+		return "", nil, nil, nil
+	}
+}
+
+func nonNilAmount(v *big.Int) *big.Int {
+	if v == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(v)
+}
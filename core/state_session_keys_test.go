@@ -0,0 +1,239 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+type sessionKeyFixture struct {
+	sp       *StateProcessor
+	now      time.Time
+	height   uint64
+	owner    *crypto.PrivateKey
+	session  *crypto.PrivateKey
+	merchant [20]byte
+}
+
+func newSessionKeyFixture(t *testing.T) *sessionKeyFixture {
+	t.Helper()
+	sp := newStakingStateProcessor(t)
+	fixed := time.Unix(1_700_000_000, 0).UTC()
+	sp.nowFunc = func() time.Time { return fixed }
+	sp.BeginBlock(1, fixed)
+	t.Cleanup(func() { sp.EndBlock() })
+
+	fx := &sessionKeyFixture{sp: sp, now: fixed, height: 1, merchant: [20]byte{0x77}}
+	for _, key := range []**crypto.PrivateKey{&fx.owner, &fx.session} {
+		priv, err := crypto.GeneratePrivateKey()
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		*key = priv
+	}
+	if err := sp.setAccount(fx.owner.PubKey().Address().Bytes(), &types.Account{
+		BalanceNHB: big.NewInt(10_000), BalanceZNHB: big.NewInt(10_000), Stake: big.NewInt(0),
+	}); err != nil {
+		t.Fatalf("seed owner: %v", err)
+	}
+	return fx
+}
+
+func (fx *sessionKeyFixture) advance(d time.Duration) {
+	fx.sp.EndBlock()
+	fx.now = fx.now.Add(d)
+	fx.height++
+	fx.sp.BeginBlock(fx.height, fx.now)
+}
+
+func (fx *sessionKeyFixture) sign(t *testing.T, key *crypto.PrivateKey, tx *types.Transaction) error {
+	t.Helper()
+	tx.ChainID = types.NHBChainID()
+	tx.GasLimit = 25_000
+	tx.GasPrice = big.NewInt(1)
+	if err := tx.Sign(key.PrivateKey); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return fx.sp.ApplyTransaction(tx)
+}
+
+func (fx *sessionKeyFixture) register(t *testing.T, nonce uint64, payload sessionKeyRegisterPayload) error {
+	t.Helper()
+	data, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+	return fx.sign(t, fx.owner, &types.Transaction{Type: types.TxTypeRegisterSessionKey, Nonce: nonce, Data: data})
+}
+
+func (fx *sessionKeyFixture) sessionTransfer(t *testing.T, txType types.TxType, nonce uint64, to [20]byte, amount int64) error {
+	t.Helper()
+	return fx.sign(t, fx.session, &types.Transaction{Type: txType, Nonce: nonce, To: to[:], Value: big.NewInt(amount)})
+}
+
+func (fx *sessionKeyFixture) account(t *testing.T, addr []byte) *types.Account {
+	t.Helper()
+	account, err := fx.sp.getAccount(addr)
+	if err != nil {
+		t.Fatalf("load account: %v", err)
+	}
+	return account
+}
+
+func TestSessionKeyEnforcesPolicy(t *testing.T) {
+	fx := newSessionKeyFixture(t)
+	ownerAddr := fx.owner.PubKey().Address().Bytes()
+	var keyAddr [20]byte
+	copy(keyAddr[:], fx.session.PubKey().Address().Bytes())
+
+	if err := fx.register(t, 0, sessionKeyRegisterPayload{
+		Key:            keyAddr,
+		TxTypes:        []uint8{uint8(types.TxTypeTransferZNHB)},
+		DailyLimitZNHB: big.NewInt(100),
+		Counterparties: [][20]byte{fx.merchant},
+		ExpiresAt:      uint64(fx.now.Add(7 * 24 * time.Hour).Unix()),
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	if err := fx.sessionTransfer(t, types.TxTypeTransferZNHB, 0, fx.merchant, 60); err != nil {
+		t.Fatalf("session transfer: %v", err)
+	}
+	if got := fx.account(t, fx.merchant[:]).BalanceZNHB; got.Cmp(big.NewInt(60)) != 0 {
+		t.Fatalf("merchant balance = %s, want 60", got)
+	}
+	owner := fx.account(t, ownerAddr)
+	if owner.BalanceZNHB.Cmp(big.NewInt(10_000-60)) > 0 {
+		t.Fatalf("owner balance not debited: %s", owner.BalanceZNHB)
+	}
+	if owner.Nonce != 1 {
+		t.Fatalf("owner nonce = %d, want 1 (only the registration)", owner.Nonce)
+	}
+	if got := fx.account(t, keyAddr[:]).Nonce; got != 1 {
+		t.Fatalf("session key nonce = %d, want 1", got)
+	}
+
+	if err := fx.sessionTransfer(t, types.TxTypeTransferZNHB, 1, fx.merchant, 50); !errors.Is(err, ErrSessionKeyDenied) {
+		t.Fatalf("expected daily limit to deny, got %v", err)
+	}
+	if err := fx.sessionTransfer(t, types.TxTypeTransferZNHB, 1, [20]byte{0x55}, 10); !errors.Is(err, ErrSessionKeyDenied) {
+		t.Fatalf("expected counterparty to deny, got %v", err)
+	}
+	if err := fx.sessionTransfer(t, types.TxTypeTransfer, 1, fx.merchant, 10); !errors.Is(err, ErrSessionKeyDenied) {
+		t.Fatalf("expected NHB transfer to deny, got %v", err)
+	}
+
+	fx.advance(24 * time.Hour)
+	if err := fx.sessionTransfer(t, types.TxTypeTransferZNHB, 1, fx.merchant, 50); err != nil {
+		t.Fatalf("transfer after day rollover: %v", err)
+	}
+	manager := nhbstate.NewManager(fx.sp.Trie)
+	key, ok, err := manager.SessionKeyGet(keyAddr)
+	if err != nil || !ok {
+		t.Fatalf("load key: ok=%v err=%v", ok, err)
+	}
+	if got := key.Spent("ZNHB", fx.now.Format("2006-01-02")); got.Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("spent today = %s, want 50", got)
+	}
+
+	revoke, err := rlp.EncodeToBytes(sessionKeyRevokePayload{Key: keyAddr})
+	if err != nil {
+		t.Fatalf("encode revoke: %v", err)
+	}
+	if err := fx.sign(t, fx.owner, &types.Transaction{Type: types.TxTypeRevokeSessionKey, Nonce: 1, Data: revoke}); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	before := fx.account(t, ownerAddr).BalanceZNHB
+	if err := fx.sessionTransfer(t, types.TxTypeTransferZNHB, 2, fx.merchant, 10); err == nil {
+		t.Fatalf("expected revoked key to lose access to the account")
+	}
+	if after := fx.account(t, ownerAddr).BalanceZNHB; after.Cmp(before) != 0 {
+		t.Fatalf("owner balance changed after revocation: %s -> %s", before, after)
+	}
+}
+
+func TestSessionKeyLimitIncludesTransferFee(t *testing.T) {
+	fx := newSessionKeyFixture(t)
+	fx.sp.SetTransferGasPolicy(TransferGasPolicy{Enabled: true, FeeCollector: [20]byte{0x99}, FeeBps: 1_000})
+	ownerAddr := fx.owner.PubKey().Address().Bytes()
+	var keyAddr [20]byte
+	copy(keyAddr[:], fx.session.PubKey().Address().Bytes())
+	if err := fx.register(t, 0, sessionKeyRegisterPayload{
+		Key:            keyAddr,
+		TxTypes:        []uint8{uint8(types.TxTypeTransferZNHB)},
+		DailyLimitZNHB: big.NewInt(100),
+		ExpiresAt:      uint64(fx.now.Add(24 * time.Hour).Unix()),
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	// 60 plus a 10% fee meters 66.
+	if err := fx.sessionTransfer(t, types.TxTypeTransferZNHB, 0, fx.merchant, 60); err != nil {
+		t.Fatalf("session transfer: %v", err)
+	}
+	if got := fx.account(t, ownerAddr).BalanceZNHB; got.Cmp(big.NewInt(10_000-66)) != 0 {
+		t.Fatalf("owner balance = %s, want %d", got, 10_000-66)
+	}
+	// Metering only the value would leave room for exactly 40 more.
+	if err := fx.sessionTransfer(t, types.TxTypeTransferZNHB, 1, fx.merchant, 40); !errors.Is(err, ErrSessionKeyDenied) {
+		t.Fatalf("expected value plus fee to exceed the limit, got %v", err)
+	}
+	if err := fx.sessionTransfer(t, types.TxTypeTransferZNHB, 1, fx.merchant, 30); err != nil {
+		t.Fatalf("transfer within limit: %v", err)
+	}
+	key, ok, err := nhbstate.NewManager(fx.sp.Trie).SessionKeyGet(keyAddr)
+	if err != nil || !ok {
+		t.Fatalf("load key: ok=%v err=%v", ok, err)
+	}
+	if got := key.Spent("ZNHB", fx.now.Format("2006-01-02")); got.Cmp(big.NewInt(99)) != 0 {
+		t.Fatalf("spent today = %s, want 99", got)
+	}
+}
+
+func TestSessionKeyExpires(t *testing.T) {
+	fx := newSessionKeyFixture(t)
+	var keyAddr [20]byte
+	copy(keyAddr[:], fx.session.PubKey().Address().Bytes())
+	if err := fx.register(t, 0, sessionKeyRegisterPayload{
+		Key:            keyAddr,
+		TxTypes:        []uint8{uint8(types.TxTypeTransferZNHB)},
+		DailyLimitZNHB: big.NewInt(100),
+		ExpiresAt:      uint64(fx.now.Add(time.Hour).Unix()),
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	fx.advance(time.Hour)
+	if err := fx.sessionTransfer(t, types.TxTypeTransferZNHB, 0, fx.merchant, 10); !errors.Is(err, ErrSessionKeyDenied) {
+		t.Fatalf("expected expired key to be denied, got %v", err)
+	}
+}
+
+func TestRegisterSessionKeyValidation(t *testing.T) {
+	fx := newSessionKeyFixture(t)
+	var keyAddr [20]byte
+	copy(keyAddr[:], fx.session.PubKey().Address().Bytes())
+	expiry := uint64(fx.now.Add(time.Hour).Unix())
+
+	cases := map[string]sessionKeyRegisterPayload{
+		"undelegable type": {Key: keyAddr, TxTypes: []uint8{uint8(types.TxTypeRegisterSessionKey)}, ExpiresAt: expiry},
+		"no types":         {Key: keyAddr, ExpiresAt: expiry},
+		"past expiry":      {Key: keyAddr, TxTypes: []uint8{uint8(types.TxTypePOSCapture)}, ExpiresAt: uint64(fx.now.Unix())},
+		"self":             {Key: [20]byte(fx.owner.PubKey().Address().Bytes()), TxTypes: []uint8{uint8(types.TxTypePOSCapture)}, ExpiresAt: expiry},
+		"funded key":       {Key: fx.merchant, TxTypes: []uint8{uint8(types.TxTypePOSCapture)}, ExpiresAt: expiry},
+	}
+	if err := fx.sp.setAccount(fx.merchant[:], &types.Account{BalanceNHB: big.NewInt(1), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0)}); err != nil {
+		t.Fatalf("seed merchant: %v", err)
+	}
+	for name, payload := range cases {
+		if err := fx.register(t, 0, payload); err == nil {
+			t.Fatalf("%s: expected registration to fail", name)
+		}
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"nhbchain/native/loyalty"
 	"nhbchain/native/pos"
 	"nhbchain/native/potso"
+	"nhbchain/native/reputation"
 	swap "nhbchain/native/swap"
 	systemquotas "nhbchain/native/system/quotas"
 	swapv1 "nhbchain/proto/swap/v1"
@@ -136,6 +137,10 @@ var (
 type blockExecutionContext struct {
 	height    uint64
 	timestamp time.Time
+	// eventStart is the length of the event log when the block began, so
+	// end-of-block hooks can tell the block's own events apart from earlier
+	// ones.
+	eventStart int
 }
 
 // BlockCtx captures per-block runtime state used while processing
@@ -165,6 +170,7 @@ type StateProcessor struct {
 	nowFunc                    func() time.Time
 	execContext                *blockExecutionContext
 	engagementConfig           engagement.Config
+	identityTerms              identity.Terms
 	epochConfig                epoch.Config
 	epochHistory               []epoch.Snapshot
 	rewardConfig               rewards.Config
@@ -950,21 +956,44 @@ func (sp *StateProcessor) BeginBlock(height uint64, timestamp time.Time) {
 		return
 	}
 	sp.execContext = &blockExecutionContext{
-		height:    height,
-		timestamp: timestamp.UTC(),
+		height:     height,
+		timestamp:  timestamp.UTC(),
+		eventStart: len(sp.events),
 	}
 	sp.blockCtx.PendingRewards.ClearPendingRewards()
 }
 
 // FinalizeBlock applies end-of-block state transitions that must be included in
 // the canonical state root before a block is sealed or committed.
-func (sp *StateProcessor) FinalizeBlock() {
+func (sp *StateProcessor) FinalizeBlock() error {
 	if sp == nil {
-		return
+		return nil
 	}
 	now := sp.blockTimestamp()
 	_, _ = sp.SweepExpiredPOSAuthorizations(now)
 	sp.EndBlockRewards(now)
+	if err := sp.sweepExpiredRewardLots(now); err != nil {
+		return fmt.Errorf("loyalty expiry: %w", err)
+	}
+	if err := sp.applyReputationEvents(now); err != nil {
+		return fmt.Errorf("reputation: %w", err)
+	}
+	return nil
+}
+
+// applyReputationEvents folds the events emitted by the current block into the
+// reputation score book so aggregates stay current without replaying history.
+// Only the block's own events are used, so every validator derives the same
+// update from the same block.
+func (sp *StateProcessor) applyReputationEvents(now time.Time) error {
+	if sp.execContext == nil {
+		return nil
+	}
+	start := sp.execContext.eventStart
+	if start > len(sp.events) {
+		start = len(sp.events)
+	}
+	return reputation.NewScoreBook(nhbstate.NewManager(sp.Trie)).Apply(sp.events[start:], now.Unix())
 }
 
 // EndBlock clears any active block execution context.
@@ -1244,6 +1273,20 @@ func (sp *StateProcessor) SetEngagementConfig(cfg engagement.Config) error {
 	return nil
 }
 
+// IdentityTerms returns the alias registration terms applied to identity
+// registrations.
+func (sp *StateProcessor) IdentityTerms() identity.Terms {
+	return sp.identityTerms
+}
+
+// SetIdentityTerms replaces the alias registration terms.
+func (sp *StateProcessor) SetIdentityTerms(terms identity.Terms) {
+	if terms.RenewalFee != nil {
+		terms.RenewalFee = new(big.Int).Set(terms.RenewalFee)
+	}
+	sp.identityTerms = terms
+}
+
 // EpochConfig returns the active epoch configuration.
 func (sp *StateProcessor) EpochConfig() epoch.Config {
 	return sp.epochConfig
@@ -1769,6 +1812,7 @@ func (sp *StateProcessor) Copy() (*StateProcessor, error) {
 		events:                     eventsCopy,
 		nowFunc:                    sp.nowFunc,
 		engagementConfig:           sp.engagementConfig,
+		identityTerms:              sp.identityTerms,
 		epochConfig:                sp.epochConfig,
 		epochHistory:               historyCopy,
 		rewardConfig:               sp.rewardConfig.Clone(),
@@ -1858,13 +1902,21 @@ func (sp *StateProcessor) executeTransaction(tx *types.Transaction) (*Simulation
 	var (
 		sender        []byte
 		senderAccount *types.Account
+		session       *sessionAuthorization
 		err           error
 	)
 	if tx.Type != types.TxTypeMint && tx.Type != types.TxTypeSwapVoucherMint && tx.Type != types.TxTypeBuybackRefPrice {
-		sender, senderAccount, err = sp.validateSenderAccount(tx)
+		sender, senderAccount, session, err = sp.validateSenderAccount(tx)
 		if err != nil {
 			return nil, err
 		}
+		if session != nil {
+			defer tx.ActAs(nil)
+		}
+	}
+	invoicePayment, err := sp.prepareInvoicePayment(tx, sender)
+	if err != nil {
+		return nil, err
 	}
 	start := len(sp.events)
 	var result *SimulationResult
@@ -1892,6 +1944,22 @@ func (sp *StateProcessor) executeTransaction(tx *types.Transaction) (*Simulation
 		}
 		return nil, err
 	}
+	if invoicePayment != nil {
+		if err := sp.settleInvoicePayment(tx, invoicePayment); err != nil {
+			if len(sp.events) > start {
+				sp.events = sp.events[:start]
+			}
+			return nil, err
+		}
+	}
+	if session != nil {
+		if err := sp.settleSessionKey(session); err != nil {
+			if len(sp.events) > start {
+				sp.events = sp.events[:start]
+			}
+			return nil, err
+		}
+	}
 	if recordIntent {
 		if intentManager == nil {
 			intentManager = nhbstate.NewManager(sp.Trie)
@@ -1940,22 +2008,36 @@ type sponsorshipRuntime struct {
 	day      string
 }
 
-func (sp *StateProcessor) validateSenderAccount(tx *types.Transaction) ([]byte, *types.Account, error) {
-	sender, err := tx.From()
+// validateSenderAccount checks the nonce of the signing address. When the
+// signer is a session key whose policy allows the transaction, the returned
+// sender is the key's account and tx.From reports that account until the
+// caller resets it with tx.ActAs(nil).
+func (sp *StateProcessor) validateSenderAccount(tx *types.Transaction) ([]byte, *types.Account, *sessionAuthorization, error) {
+	sender, err := tx.Signer()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
+	tx.ActAs(nil)
 	account, err := sp.getAccount(sender)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if tx.Nonce != account.Nonce {
 		if tx.Nonce < account.Nonce {
-			return nil, nil, fmt.Errorf("%w: %w: account=%d tx=%d", ErrNonceMismatch, ErrNonceTooLow, account.Nonce, tx.Nonce)
+			return nil, nil, nil, fmt.Errorf("%w: %w: account=%d tx=%d", ErrNonceMismatch, ErrNonceTooLow, account.Nonce, tx.Nonce)
 		}
-		return nil, nil, fmt.Errorf("%w: %w: account=%d tx=%d", ErrNonceMismatch, ErrNonceTooHigh, account.Nonce, tx.Nonce)
+		return nil, nil, nil, fmt.Errorf("%w: %w: account=%d tx=%d", ErrNonceMismatch, ErrNonceTooHigh, account.Nonce, tx.Nonce)
+	}
+	session, err := sp.authorizeSessionKey(tx, sender)
+	if err != nil || session == nil {
+		return sender, account, nil, err
+	}
+	owner, err := sp.getAccount(session.key.Account[:])
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	return sender, account, nil
+	tx.ActAs(session.key.Account[:])
+	return append([]byte(nil), session.key.Account[:]...), owner, session, nil
 }
 
 // --- EVM path (Geth v1.16.x) ---
@@ -2275,18 +2357,7 @@ func (sp *StateProcessor) applyEvmTransaction(tx *types.Transaction) (*Simulatio
 		toAddrPtr = &addr
 	}
 
-	blockCtx := gethvm.BlockContext{
-		CanTransfer: gethcore.CanTransfer,
-		Transfer:    gethcore.Transfer,
-		GetHash: func(uint64) common.Hash {
-			return common.Hash{}
-		},
-		Coinbase:    transferGasCollector,
-		BlockNumber: new(big.Int).SetUint64(sp.blockHeight()),
-		Time:        uint64(blockTime.Unix()),
-		Difficulty:  big.NewInt(0),
-		BaseFee:     big.NewInt(0),
-	}
+	blockCtx := sp.evmBlockContext(transferGasCollector)
 
 	assessment, err := sp.EvaluateSponsorship(tx)
 	if err != nil {
@@ -2774,7 +2845,7 @@ func (sp *StateProcessor) applyMintTransaction(tx *types.Transaction) error {
 	if decoded, err := crypto.DecodeAddress(recipientRef); err == nil {
 		copy(recipient[:], decoded.Bytes())
 	} else {
-		resolved, ok := manager.IdentityResolve(recipientRef)
+		resolved, ok := manager.IdentityResolveAt(recipientRef, sp.identityTerms, blockTime.Unix())
 		if !ok || resolved == nil {
 			// Identity registration is mutable on-chain state -- an alias
 			// registered after this transaction was submitted could resolve
@@ -2910,12 +2981,58 @@ func (sp *StateProcessor) handleNativeTransaction(tx *types.Transaction, sender
 		return sp.applyPOSCapture(tx)
 	case types.TxTypePOSVoid:
 		return sp.applyPOSVoid(tx)
+	case types.TxTypePOSIncrement:
+		return sp.applyPOSIncrement(tx)
+	case types.TxTypePOSRefund:
+		return sp.applyPOSRefund(tx)
 	case types.TxTypePOSRegistry:
 		return sp.applyPOSRegistry(tx)
 	case types.TxTypeRedeemNHB:
 		return sp.applyRedeemNHB(tx, sender, senderAccount)
 	case types.TxTypeAttestRedemption:
 		return sp.applyAttestRedemption(tx, sender, senderAccount)
+	case types.TxTypeCreateInvoice:
+		return sp.applyCreateInvoice(tx, sender, senderAccount)
+	case types.TxTypeCancelInvoice:
+		return sp.applyCancelInvoice(tx, sender, senderAccount)
+	case types.TxTypeCreateMandate:
+		return sp.applyCreateMandate(tx, sender, senderAccount)
+	case types.TxTypePullMandate:
+		return sp.applyPullMandate(tx, sender)
+	case types.TxTypeCancelMandate:
+		return sp.applyCancelMandate(tx, sender, senderAccount)
+	case types.TxTypeLoyaltyRedeem:
+		return sp.applyLoyaltyRedeem(tx, sender, senderAccount)
+	case types.TxTypeLoyaltyCoalitionSettle:
+		return sp.applyLoyaltyCoalitionSettle(tx, sender)
+	case types.TxTypeSetIdentityRecords:
+		return sp.applySetIdentityRecords(tx, sender, senderAccount)
+	case types.TxTypeSetRecoveryGuardians:
+		return sp.applySetRecoveryGuardians(tx, sender, senderAccount)
+	case types.TxTypeApproveRecovery:
+		return sp.applyApproveRecovery(tx, sender, senderAccount)
+	case types.TxTypeCancelRecovery:
+		return sp.applyCancelRecovery(tx, sender, senderAccount)
+	case types.TxTypeExecuteRecovery:
+		return sp.applyExecuteRecovery(tx, sender, senderAccount)
+	case types.TxTypeRegisterSessionKey:
+		return sp.applyRegisterSessionKey(tx, sender, senderAccount)
+	case types.TxTypeRevokeSessionKey:
+		return sp.applyRevokeSessionKey(tx, sender, senderAccount)
+	case types.TxTypeRegisterDevice:
+		return sp.applyRegisterDevice(tx, sender, senderAccount)
+	case types.TxTypeRevokeDevice:
+		return sp.applyRevokeDevice(tx, sender, senderAccount)
+	case types.TxTypeCreateAsset:
+		return sp.applyCreateAsset(tx, sender, senderAccount)
+	case types.TxTypeMintAsset:
+		return sp.applyMintAsset(tx, sender, senderAccount)
+	case types.TxTypeBurnAsset:
+		return sp.applyBurnAsset(tx, sender, senderAccount)
+	case types.TxTypeFreezeAsset:
+		return sp.applyFreezeAsset(tx, sender, senderAccount)
+	case types.TxTypeTransferAsset:
+		return sp.applyTransferAsset(tx, sender)
 
 	// --- NEW DISPUTE RESOLUTION CASES ---
 	case types.TxTypeLockEscrow:
@@ -3057,16 +3174,25 @@ func (sp *StateProcessor) applyRegisterIdentity(tx *types.Transaction, sender []
 	if err != nil {
 		return fmt.Errorf("username: %w", err)
 	}
+	manager := nhbstate.NewManager(sp.Trie)
+	now := sp.blockTimestamp().Unix()
 	if _, ok := sp.usernameToAddr[normalized]; ok {
-		return fmt.Errorf("username '%s' taken", normalized)
+		// A username whose alias registration has lapsed past its grace
+		// period is free to claim again.
+		record, exists := manager.IdentityResolve(normalized)
+		if !exists || sp.identityTerms.Status(record, now) != identity.AliasStatusReleased {
+			return fmt.Errorf("username '%s' taken", normalized)
+		}
 	}
-	if senderAccount.Username != "" {
+	if senderAccount.Username != "" && sp.usernameHeldBy(manager, senderAccount.Username, sender, now) {
 		return fmt.Errorf("account already has username")
 	}
-	manager := nhbstate.NewManager(sp.Trie)
-	if err := manager.IdentitySetAlias(sender, normalized); err != nil {
+	if _, err := manager.IdentityClaimAlias(sender, normalized, sp.identityTerms, now); err != nil {
 		return fmt.Errorf("username: %w", err)
 	}
+	if err := sp.clearUsernameHolder(normalized, sender); err != nil {
+		return err
+	}
 	senderAccount.Username = normalized
 	senderAccount.Nonce++
 	if err := sp.setAccount(sender, senderAccount); err != nil {
@@ -3075,6 +3201,56 @@ func (sp *StateProcessor) applyRegisterIdentity(tx *types.Transaction, sender []
 	return nil
 }
 
+// usernameHeldBy reports whether addr still holds username: its alias must
+// be owned by addr and not yet released. A username whose alias lapsed or
+// was sold no longer blocks the account from registering another.
+func (sp *StateProcessor) usernameHeldBy(manager *nhbstate.Manager, username string, addr []byte, now int64) bool {
+	record, ok := manager.IdentityResolve(username)
+	if !ok || !aliasRecordOwnedBy(record, bytesToAddress(addr)) {
+		return false
+	}
+	return sp.identityTerms.Status(record, now) != identity.AliasStatusReleased
+}
+
+// clearUsernameHolder drops username from the account that currently holds
+// it, unless that account is keep. Without this the previous holder's stale
+// Account.Username would re-point the username index at it on its next
+// write.
+func (sp *StateProcessor) clearUsernameHolder(username string, keep []byte) error {
+	holder, ok := sp.usernameToAddr[username]
+	if !ok || bytes.Equal(holder, keep) {
+		return nil
+	}
+	holder = append([]byte(nil), holder...)
+	account, err := sp.getAccount(holder)
+	if err != nil {
+		return err
+	}
+	if account.Username != username {
+		return nil
+	}
+	account.Username = ""
+	return sp.setAccount(holder, account)
+}
+
+// transferUsername moves username to newOwner, clearing it from the
+// previous holder. It is used when an alias changes hands outside
+// applyRegisterIdentity, such as a marketplace sale.
+func (sp *StateProcessor) transferUsername(username string, newOwner []byte) error {
+	if err := sp.clearUsernameHolder(username, newOwner); err != nil {
+		return err
+	}
+	account, err := sp.getAccount(newOwner)
+	if err != nil {
+		return err
+	}
+	if account.Username == username {
+		return nil
+	}
+	account.Username = username
+	return sp.setAccount(newOwner, account)
+}
+
 func (sp *StateProcessor) applyCreateEscrow(tx *types.Transaction, sender []byte, senderAccount *types.Account) error {
 	tradeEngine, _ := sp.configureTradeEngine()
 	_ = tradeEngine
@@ -4465,6 +4641,12 @@ func (sp *StateProcessor) applyHeartbeat(tx *types.Transaction, sender []byte, s
 		}
 	}
 
+	if payload.DeviceID != "" {
+		if err := sp.stampEngagementDevice(sender, payload.DeviceID, payload.Timestamp); err != nil {
+			return err
+		}
+	}
+
 	minutes := uint64(1)
 	if senderAccount.EngagementLastHeartbeat != 0 {
 		delta := payload.Timestamp - int64(senderAccount.EngagementLastHeartbeat)
@@ -4837,7 +5019,11 @@ func (sp *StateProcessor) setAccount(addr []byte, account *types.Account) error
 	}
 
 	if prevUsername != "" && prevUsername != account.Username {
-		delete(sp.usernameToAddr, prevUsername)
+		// The name may already have moved to a new holder; only drop the
+		// index entry while it still points here.
+		if holder, ok := sp.usernameToAddr[prevUsername]; ok && bytes.Equal(holder, addr) {
+			delete(sp.usernameToAddr, prevUsername)
+		}
 	}
 	if account.Username != "" {
 		sp.usernameToAddr[account.Username] = append([]byte(nil), addr...)
@@ -5465,6 +5651,7 @@ func (sp *StateProcessor) loadUsernameIndex() error {
 		return err
 	}
 	if len(data) == 0 {
+		sp.usernameToAddr = make(map[string][]byte)
 		return nil
 	}
 	var entries []usernameIndexEntry
@@ -6123,6 +6310,7 @@ func (sp *StateProcessor) configureTradeEngine() (*escrow.TradeEngine, *nhbstate
 	sp.EscrowEngine.SetEmitter(stateProcessorEmitter{sp: sp})
 	sp.EscrowEngine.SetFeeTreasury(sp.escrowFeeTreasury)
 	sp.EscrowEngine.SetNowFunc(func() int64 { return sp.now().Unix() })
+	sp.EscrowEngine.SetReputationScorer(reputationScorer(manager, func() int64 { return sp.now().Unix() }))
 	if sp.TradeEngine == nil {
 		sp.TradeEngine = escrow.NewTradeEngine(sp.EscrowEngine)
 	}
@@ -6132,6 +6320,18 @@ func (sp *StateProcessor) configureTradeEngine() (*escrow.TradeEngine, *nhbstate
 	return sp.TradeEngine, manager
 }
 
+// reputationScorer exposes the stored reputation aggregates to the escrow
+// engine for realm minimum checks.
+func reputationScorer(manager *nhbstate.Manager, now func() int64) escrow.ReputationScorer {
+	return func(addr [20]byte) (uint32, error) {
+		_, score, err := reputation.NewScoreBook(manager).Score(addr, now())
+		if err != nil {
+			return 0, err
+		}
+		return score.Total, nil
+	}
+}
+
 type stateProcessorEmitter struct {
 	sp *StateProcessor
 }
@@ -6385,6 +6585,39 @@ func (sp *StateProcessor) SetLoyaltyProgramIssuanceAccrued(programID loyalty.Pro
 	return manager.SetLoyaltyProgramIssuanceAccrued(programID, addr, amount)
 }
 
+func (sp *StateProcessor) LoyaltyProgramSpend(programID loyalty.ProgramID, addr []byte, day uint64) (*big.Int, error) {
+	manager := nhbstate.NewManager(sp.Trie)
+	return manager.LoyaltyProgramSpend(programID, addr, day)
+}
+
+func (sp *StateProcessor) SetLoyaltyProgramSpend(programID loyalty.ProgramID, addr []byte, day uint64, amount *big.Int) error {
+	manager := nhbstate.NewManager(sp.Trie)
+	return manager.SetLoyaltyProgramSpend(programID, addr, day, amount)
+}
+
+func (sp *StateProcessor) LoyaltyRewardLots(programID loyalty.ProgramID, addr []byte) ([]loyalty.RewardLot, error) {
+	manager := nhbstate.NewManager(sp.Trie)
+	return manager.LoyaltyRewardLots(programID, addr)
+}
+
+func (sp *StateProcessor) SetLoyaltyRewardLots(programID loyalty.ProgramID, addr []byte, lots []loyalty.RewardLot) error {
+	manager := nhbstate.NewManager(sp.Trie)
+	return manager.SetLoyaltyRewardLots(programID, addr, lots)
+}
+
+func (sp *StateProcessor) LoyaltyRewardVault() [20]byte {
+	return nhbstate.NewManager(sp.Trie).LoyaltyRewardVault()
+}
+
+func (sp *StateProcessor) LoyaltyCoalitionForProgram(id loyalty.ProgramID) (*loyalty.Coalition, bool, error) {
+	return loyalty.NewRegistry(nhbstate.NewManager(sp.Trie)).CoalitionForProgram(id)
+}
+
+func (sp *StateProcessor) RecordLoyaltyCoalitionIssued(coalition *loyalty.Coalition, programID loyalty.ProgramID, timestamp uint64, amount *big.Int) error {
+	registry := loyalty.NewRegistry(nhbstate.NewManager(sp.Trie))
+	return registry.RecordCoalitionActivity(coalition.ID, coalition.Period(timestamp), programID, amount, nil)
+}
+
 func (sp *StateProcessor) MintToken(symbol string, addr []byte, amount *big.Int) error {
 	if len(addr) != 20 {
 		return fmt.Errorf("mint: address must be 20 bytes")
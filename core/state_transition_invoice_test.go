@@ -0,0 +1,241 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/events"
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+type invoiceFixture struct {
+	sp       *StateProcessor
+	now      time.Time
+	merchant *crypto.PrivateKey
+	payer    *crypto.PrivateKey
+}
+
+func newInvoiceFixture(t *testing.T) *invoiceFixture {
+	t.Helper()
+	sp := newStakingStateProcessor(t)
+	fixed := time.Unix(1_700_000_000, 0).UTC()
+	sp.nowFunc = func() time.Time { return fixed }
+	sp.BeginBlock(1, fixed)
+	t.Cleanup(func() { sp.EndBlock() })
+
+	fx := &invoiceFixture{sp: sp, now: fixed}
+	for _, key := range []**crypto.PrivateKey{&fx.merchant, &fx.payer} {
+		priv, err := crypto.GeneratePrivateKey()
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		*key = priv
+	}
+	if err := sp.setAccount(fx.merchant.PubKey().Address().Bytes(), &types.Account{
+		BalanceNHB: big.NewInt(0), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0),
+	}); err != nil {
+		t.Fatalf("seed merchant: %v", err)
+	}
+	if err := sp.setAccount(fx.payer.PubKey().Address().Bytes(), &types.Account{
+		BalanceNHB: big.NewInt(1_000), BalanceZNHB: big.NewInt(1_000), Stake: big.NewInt(0),
+	}); err != nil {
+		t.Fatalf("seed payer: %v", err)
+	}
+	return fx
+}
+
+func (fx *invoiceFixture) createInvoice(t *testing.T, nonce uint64, amount int64, allowPartial bool) [32]byte {
+	t.Helper()
+	data, err := rlp.EncodeToBytes(invoiceCreatePayload{
+		Asset:        "znhb",
+		Amount:       big.NewInt(amount),
+		Expiry:       uint64(fx.now.Add(time.Hour).Unix()),
+		AllowPartial: allowPartial,
+	})
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+	tx := &types.Transaction{
+		ChainID:  types.NHBChainID(),
+		Type:     types.TxTypeCreateInvoice,
+		Nonce:    nonce,
+		GasLimit: 25_000,
+		GasPrice: big.NewInt(1),
+		Data:     data,
+	}
+	if err := tx.Sign(fx.merchant.PrivateKey); err != nil {
+		t.Fatalf("sign create: %v", err)
+	}
+	if err := fx.sp.ApplyTransaction(tx); err != nil {
+		t.Fatalf("create invoice: %v", err)
+	}
+	hash, err := tx.Hash()
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	return nhbstate.InvoiceID(hash)
+}
+
+func (fx *invoiceFixture) pay(t *testing.T, id [32]byte, nonce uint64, amount int64, suffix string) error {
+	t.Helper()
+	ref, err := nhbstate.InvoiceIntentRef(id, []byte(suffix))
+	if err != nil {
+		t.Fatalf("intent ref: %v", err)
+	}
+	tx := &types.Transaction{
+		ChainID:      types.NHBChainID(),
+		Type:         types.TxTypeTransferZNHB,
+		Nonce:        nonce,
+		To:           fx.merchant.PubKey().Address().Bytes(),
+		Value:        big.NewInt(amount),
+		GasLimit:     25_000,
+		GasPrice:     big.NewInt(1),
+		IntentRef:    ref,
+		IntentExpiry: uint64(fx.now.Add(time.Hour).Unix()),
+	}
+	if err := tx.Sign(fx.payer.PrivateKey); err != nil {
+		t.Fatalf("sign payment: %v", err)
+	}
+	return fx.sp.ApplyTransaction(tx)
+}
+
+func (fx *invoiceFixture) merchantZNHB(t *testing.T) *big.Int {
+	t.Helper()
+	account, err := fx.sp.getAccount(fx.merchant.PubKey().Address().Bytes())
+	if err != nil {
+		t.Fatalf("load merchant: %v", err)
+	}
+	return account.BalanceZNHB
+}
+
+func (fx *invoiceFixture) countEvents(eventType string) int {
+	count := 0
+	for _, evt := range fx.sp.events {
+		if evt.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestInvoiceOverpaymentSettlesAndRefundsExcess(t *testing.T) {
+	fx := newInvoiceFixture(t)
+	id := fx.createInvoice(t, 0, 100, false)
+
+	if err := fx.pay(t, id, 0, 150, ""); err != nil {
+		t.Fatalf("pay invoice: %v", err)
+	}
+	if got := fx.merchantZNHB(t); got.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("expected merchant to keep 100 ZNHB, got %s", got)
+	}
+	invoice, err := fx.sp.GetInvoice(id)
+	if err != nil {
+		t.Fatalf("get invoice: %v", err)
+	}
+	if invoice.Status != string(nhbstate.InvoiceStatusPaid) || invoice.Paid.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected invoice state: status=%s paid=%s", invoice.Status, invoice.Paid)
+	}
+	if fx.countEvents(events.TypeInvoicePaid) != 1 || fx.countEvents(events.TypeInvoiceRefunded) != 1 {
+		t.Fatalf("expected paid and refunded events, got %+v", fx.sp.events)
+	}
+
+	if err := fx.pay(t, id, 1, 10, "again"); !errors.Is(err, ErrInvoiceNotPayable) {
+		t.Fatalf("expected paid invoice to reject payments, got %v", err)
+	}
+}
+
+func TestInvoiceUnderpaymentRefundedWithoutPartialPolicy(t *testing.T) {
+	fx := newInvoiceFixture(t)
+	id := fx.createInvoice(t, 0, 100, false)
+
+	if err := fx.pay(t, id, 0, 40, ""); err != nil {
+		t.Fatalf("pay invoice: %v", err)
+	}
+	if got := fx.merchantZNHB(t); got.Sign() != 0 {
+		t.Fatalf("expected underpayment to be refunded, merchant holds %s", got)
+	}
+	invoice, err := fx.sp.GetInvoice(id)
+	if err != nil {
+		t.Fatalf("get invoice: %v", err)
+	}
+	if invoice.Status != string(nhbstate.InvoiceStatusOpen) || invoice.Paid.Sign() != 0 {
+		t.Fatalf("expected invoice to remain open, got status=%s paid=%s", invoice.Status, invoice.Paid)
+	}
+}
+
+func TestInvoicePartialPaymentsAccrue(t *testing.T) {
+	fx := newInvoiceFixture(t)
+	id := fx.createInvoice(t, 0, 100, true)
+
+	if err := fx.pay(t, id, 0, 40, "1"); err != nil {
+		t.Fatalf("first payment: %v", err)
+	}
+	invoice, err := fx.sp.GetInvoice(id)
+	if err != nil {
+		t.Fatalf("get invoice: %v", err)
+	}
+	if invoice.Status != string(nhbstate.InvoiceStatusPartiallyPaid) || invoice.Remaining().Cmp(big.NewInt(60)) != 0 {
+		t.Fatalf("unexpected partial state: status=%s remaining=%s", invoice.Status, invoice.Remaining())
+	}
+	if err := fx.pay(t, id, 1, 60, "2"); err != nil {
+		t.Fatalf("second payment: %v", err)
+	}
+	invoice, err = fx.sp.GetInvoice(id)
+	if err != nil {
+		t.Fatalf("get invoice: %v", err)
+	}
+	if invoice.Status != string(nhbstate.InvoiceStatusPaid) {
+		t.Fatalf("expected invoice paid, got %s", invoice.Status)
+	}
+	if got := fx.merchantZNHB(t); got.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("expected merchant to hold 100 ZNHB, got %s", got)
+	}
+
+	list, err := fx.sp.ListInvoicesByMerchant(invoice.Merchant)
+	if err != nil {
+		t.Fatalf("list invoices: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != id {
+		t.Fatalf("unexpected merchant listing: %+v", list)
+	}
+}
+
+func TestInvoiceCancelledRejectsPayment(t *testing.T) {
+	fx := newInvoiceFixture(t)
+	id := fx.createInvoice(t, 0, 100, false)
+
+	data, err := rlp.EncodeToBytes(invoiceCancelPayload{InvoiceID: id})
+	if err != nil {
+		t.Fatalf("encode cancel: %v", err)
+	}
+	cancel := &types.Transaction{
+		ChainID:  types.NHBChainID(),
+		Type:     types.TxTypeCancelInvoice,
+		Nonce:    0,
+		GasLimit: 25_000,
+		GasPrice: big.NewInt(1),
+		Data:     data,
+	}
+	if err := cancel.Sign(fx.payer.PrivateKey); err != nil {
+		t.Fatalf("sign cancel: %v", err)
+	}
+	if err := fx.sp.ApplyTransaction(cancel); err == nil {
+		t.Fatalf("expected non-merchant cancel to fail")
+	}
+	cancel.Nonce = 1
+	if err := cancel.Sign(fx.merchant.PrivateKey); err != nil {
+		t.Fatalf("sign cancel: %v", err)
+	}
+	if err := fx.sp.ApplyTransaction(cancel); err != nil {
+		t.Fatalf("cancel invoice: %v", err)
+	}
+	if err := fx.pay(t, id, 0, 100, ""); !errors.Is(err, ErrInvoiceNotPayable) {
+		t.Fatalf("expected cancelled invoice to reject payment, got %v", err)
+	}
+}
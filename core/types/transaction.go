@@ -96,6 +96,92 @@ const (
 	// not a single envelope signature. 0x25 is the next free byte after
 	// TxTypeBuybackAsk (0x24).
 	TxTypeBuybackRefPrice TxType = 0x25
+	// TxTypeCreateInvoice opens a native merchant invoice signed by the
+	// merchant. Transfers settle it by carrying the invoice reference in
+	// IntentRef (core/state_invoice.go). 0x26 is the next free byte after
+	// TxTypeBuybackRefPrice (0x25).
+	TxTypeCreateInvoice TxType = 0x26
+	// TxTypeCancelInvoice lets a merchant withdraw one of its unpaid
+	// invoices. 0x27 is the next free byte after TxTypeCreateInvoice (0x26).
+	TxTypeCancelInvoice TxType = 0x27
+	// TxTypeSetIdentityRecords lets an alias owner add, change or delete the
+	// text records stored on the alias (core/state_identity_records.go). 0x28
+	// is the next free byte after TxTypeCancelInvoice (0x27).
+	TxTypeSetIdentityRecords TxType = 0x28
+	// TxTypeSetRecoveryGuardians registers, replaces or clears the guardian
+	// set used for social recovery (core/state_recovery.go). 0x29 is the next
+	// free byte after TxTypeSetIdentityRecords (0x28).
+	TxTypeSetRecoveryGuardians TxType = 0x29
+	// TxTypeApproveRecovery is signed by a guardian to approve rotating an
+	// account to a new address. 0x2A is the next free byte after
+	// TxTypeSetRecoveryGuardians (0x29).
+	TxTypeApproveRecovery TxType = 0x2A
+	// TxTypeCancelRecovery lets the account owner cancel a pending rotation.
+	// 0x2B is the next free byte after TxTypeApproveRecovery (0x2A).
+	TxTypeCancelRecovery TxType = 0x2B
+	// TxTypeExecuteRecovery completes a rotation once its delay has passed and
+	// may be sent by anyone. 0x2C is the next free byte after
+	// TxTypeCancelRecovery (0x2B).
+	TxTypeExecuteRecovery TxType = 0x2C
+	// TxTypeRegisterSessionKey lets an account add or update a scoped session
+	// key (core/state_session_keys.go). 0x2D is the next free byte after
+	// TxTypeExecuteRecovery (0x2C).
+	TxTypeRegisterSessionKey TxType = 0x2D
+	// TxTypeRevokeSessionKey removes one of the account's session keys. 0x2E
+	// is the next free byte after TxTypeRegisterSessionKey (0x2D).
+	TxTypeRevokeSessionKey TxType = 0x2E
+	// TxTypeCreateAsset registers a new issued asset with its mint and
+	// freeze authorities and supply cap (core/state_assets.go). 0x2F is the
+	// next free byte after TxTypeRevokeSessionKey (0x2E).
+	TxTypeCreateAsset TxType = 0x2F
+	// TxTypeMintAsset is signed by an asset's mint authority to issue new
+	// units. 0x30 is the next free byte after TxTypeCreateAsset (0x2F).
+	TxTypeMintAsset TxType = 0x30
+	// TxTypeBurnAsset destroys units of an issued asset held by the sender.
+	// 0x31 is the next free byte after TxTypeMintAsset (0x30).
+	TxTypeBurnAsset TxType = 0x31
+	// TxTypeFreezeAsset is signed by an asset's freeze authority to freeze or
+	// unfreeze one account. 0x32 is the next free byte after
+	// TxTypeBurnAsset (0x31).
+	TxTypeFreezeAsset TxType = 0x32
+	// TxTypeTransferAsset moves any registered asset, NHB and ZNHB included,
+	// named by symbol in the payload. 0x33 is the next free byte after
+	// TxTypeFreezeAsset (0x32).
+	TxTypeTransferAsset TxType = 0x33
+	// TxTypePOSIncrement is signed by the payer to lock more funds on a
+	// pending POS authorization, e.g. for a tip. 0x34 is the next free byte
+	// after TxTypeTransferAsset (0x33).
+	TxTypePOSIncrement TxType = 0x34
+	// TxTypePOSRefund is signed by the merchant to return captured funds of
+	// a POS authorization to the payer. 0x35 is the next free byte after
+	// TxTypePOSIncrement (0x34).
+	TxTypePOSRefund TxType = 0x35
+	// TxTypeCreateMandate is signed once by a payer to let a merchant pull
+	// recurring payments (core/state_mandates.go). 0x36 is the next free byte
+	// after TxTypePOSRefund (0x35).
+	TxTypeCreateMandate TxType = 0x36
+	// TxTypePullMandate is signed by the merchant to collect one period's
+	// payment under a mandate and may be paymaster sponsored. 0x37 is the
+	// next free byte after TxTypeCreateMandate (0x36).
+	TxTypePullMandate TxType = 0x37
+	// TxTypeCancelMandate revokes a mandate. 0x38 is the next free byte after
+	// TxTypePullMandate (0x37).
+	TxTypeCancelMandate TxType = 0x38
+	// TxTypeLoyaltyRedeem spends loyalty rewards on a program catalogue item
+	// (core/state_loyalty_redeem.go). 0x39 is the next free byte after
+	// TxTypeCancelMandate (0x38).
+	TxTypeLoyaltyRedeem TxType = 0x39
+	// TxTypeLoyaltyCoalitionSettle net-settles a closed coalition period
+	// between member paymasters (core/state_loyalty_coalition.go). 0x3A is
+	// the next free byte after TxTypeLoyaltyRedeem (0x39).
+	TxTypeLoyaltyCoalitionSettle TxType = 0x3A
+	// TxTypeRegisterDevice registers a heartbeat device under the sender or
+	// rotates its token hash (core/state_engagement_devices.go). 0x3B is the
+	// next free byte after TxTypeLoyaltyCoalitionSettle (0x3A).
+	TxTypeRegisterDevice TxType = 0x3B
+	// TxTypeRevokeDevice removes one of the sender's heartbeat devices. 0x3C
+	// is the next free byte after TxTypeRegisterDevice (0x3B).
+	TxTypeRevokeDevice TxType = 0x3C
 )
 
 // RequiresSignature reports whether the transaction type must carry an
@@ -140,7 +226,8 @@ type Transaction struct {
 	PaymasterS *big.Int `json:"paymasterS,omitempty"`
 	PaymasterV *big.Int `json:"paymasterV,omitempty"`
 
-	from []byte
+	from   []byte
+	signer []byte
 }
 
 var (
@@ -337,13 +424,31 @@ func (tx *Transaction) Sign(privKey *ecdsa.PrivateKey) error {
 	tx.S = new(big.Int).SetBytes(sig[32:64])
 	tx.V = new(big.Int).SetBytes([]byte{sig[64] + 27})
 	tx.from = nil
+	tx.signer = nil
 	return nil
 }
 
+// From returns the account the transaction acts for. This is the recovered
+// signer unless the state transition bound the signer, a session key, to its
+// account with ActAs.
 func (tx *Transaction) From() ([]byte, error) {
 	if tx.from != nil {
 		return tx.from, nil
 	}
+	signer, err := tx.Signer()
+	if err != nil {
+		return nil, err
+	}
+	tx.from = signer
+	return tx.from, nil
+}
+
+// Signer recovers the address whose key signed the transaction. It differs
+// from From only while a session key acts for an account.
+func (tx *Transaction) Signer() ([]byte, error) {
+	if tx.signer != nil {
+		return tx.signer, nil
+	}
 	if tx.R == nil || tx.S == nil || tx.V == nil {
 		return nil, fmt.Errorf("transaction missing signature")
 	}
@@ -362,8 +467,19 @@ func (tx *Transaction) From() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	tx.from = crypto.PubkeyToAddress(*pubKey).Bytes()
-	return tx.from, nil
+	tx.signer = crypto.PubkeyToAddress(*pubKey).Bytes()
+	return tx.signer, nil
+}
+
+// ActAs makes From report account instead of the signer. The state
+// transition uses it while executing a transaction signed by a session key;
+// passing nil restores the signer.
+func (tx *Transaction) ActAs(account []byte) {
+	if len(account) == 0 {
+		tx.from = tx.signer
+		return
+	}
+	tx.from = append([]byte(nil), account...)
 }
 
 // PaymasterSponsor recovers the sponsoring paymaster address from the
@@ -5,6 +5,8 @@ import (
 	"math/big"
 	"strings"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func TestTransactionHashBindsIntentRef(t *testing.T) {
@@ -52,3 +54,32 @@ func TestTransactionHashRejectsOversizedAddress(t *testing.T) {
 		t.Fatalf("expected address length validation error, got %v", err)
 	}
 }
+
+func TestTransactionActAsKeepsSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tx := &Transaction{ChainID: NHBChainID(), Type: TxTypeTransferZNHB, Value: big.NewInt(1), GasPrice: big.NewInt(1)}
+	if err := tx.Sign(key); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	signer := crypto.PubkeyToAddress(key.PublicKey).Bytes()
+	account := bytes.Repeat([]byte{0x42}, 20)
+
+	tx.ActAs(account)
+	from, err := tx.From()
+	if err != nil || !bytes.Equal(from, account) {
+		t.Fatalf("From = %x, %v; want %x", from, err, account)
+	}
+	recovered, err := tx.Signer()
+	if err != nil || !bytes.Equal(recovered, signer) {
+		t.Fatalf("Signer = %x, %v; want %x", recovered, err, signer)
+	}
+
+	tx.ActAs(nil)
+	from, err = tx.From()
+	if err != nil || !bytes.Equal(from, signer) {
+		t.Fatalf("From after reset = %x, %v; want %x", from, err, signer)
+	}
+}
@@ -0,0 +1,217 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/native/governance"
+)
+
+var (
+	// ErrUpgradeHalt is returned when a block above a scheduled software
+	// upgrade's height is executed by a binary that cannot apply it.
+	ErrUpgradeHalt = errors.New("upgrade: chain halted for software upgrade")
+	// ErrUpgradeHandlerMissing is returned at startup when the chain has
+	// reached a scheduled upgrade's height but this binary registers no
+	// migration handler for it, i.e. the node is still running the old
+	// release.
+	ErrUpgradeHandlerMissing = errors.New("upgrade: binary has no handler for scheduled upgrade")
+	// ErrUpgradeChecksumMismatch is returned at startup when the binary
+	// registers the scheduled upgrade's handler but its digest differs from
+	// the plan checksum.
+	ErrUpgradeChecksumMismatch = errors.New("upgrade: binary does not match scheduled upgrade checksum")
+)
+
+// UpgradeHandler migrates state for a governance-scheduled software upgrade.
+// Handlers run once, while executing the first block above the plan height,
+// and their writes are part of that block's state root, so every validator
+// must produce the same writes.
+type UpgradeHandler func(m *nhbstate.Manager, plan governance.UpgradePlan) error
+
+var (
+	upgradeHandlersMu sync.RWMutex
+	upgradeHandlers   = make(map[string]UpgradeHandler)
+)
+
+// RegisterUpgradeHandler registers the migration for the named upgrade.
+// Releases call it from an init function. Registering a name twice panics.
+func RegisterUpgradeHandler(name string, handler UpgradeHandler) {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" || handler == nil {
+		panic("upgrade: handler name and function required")
+	}
+	upgradeHandlersMu.Lock()
+	defer upgradeHandlersMu.Unlock()
+	if _, exists := upgradeHandlers[trimmed]; exists {
+		panic(fmt.Sprintf("upgrade: handler %q already registered", trimmed))
+	}
+	upgradeHandlers[trimmed] = handler
+}
+
+// UpgradeHandlerRegistered reports whether this binary carries the migration
+// for the named upgrade.
+func UpgradeHandlerRegistered(name string) bool {
+	_, ok := lookupUpgradeHandler(name)
+	return ok
+}
+
+func lookupUpgradeHandler(name string) (UpgradeHandler, bool) {
+	upgradeHandlersMu.RLock()
+	defer upgradeHandlersMu.RUnlock()
+	handler, ok := upgradeHandlers[strings.TrimSpace(name)]
+	return handler, ok
+}
+
+// BinaryChecksum returns the hex-encoded SHA-256 digest of the file at path
+// so operators can compare the running binary against an upgrade plan.
+func BinaryChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// SetUpgradeHaltHandler installs the callback invoked once the block at a
+// scheduled upgrade's height has committed on a binary that has no handler
+// for it. The callback runs on its own goroutine after the commit completes.
+func (n *Node) SetUpgradeHaltHandler(handler func(governance.UpgradePlan)) {
+	if n == nil {
+		return
+	}
+	n.upgradeHaltHandler = handler
+}
+
+// PendingUpgrade returns the scheduled software upgrade plan, if any.
+func (n *Node) PendingUpgrade() (*governance.UpgradePlan, error) {
+	if n == nil {
+		return nil, fmt.Errorf("node unavailable")
+	}
+	n.stateMu.RLock()
+	defer n.stateMu.RUnlock()
+	if n.state == nil {
+		return nil, fmt.Errorf("state unavailable")
+	}
+	plan, ok, err := nhbstate.NewManager(n.state.Trie).UpgradePlan()
+	if err != nil || !ok {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// CheckPendingUpgrade verifies at startup that this binary can continue past
+// a scheduled software upgrade. It returns ErrUpgradeHandlerMissing once the
+// chain has reached the plan height without a registered handler, and
+// ErrUpgradeChecksumMismatch when the binary at executable registers the
+// handler but does not match the plan checksum. It returns the pending plan,
+// if any.
+func (n *Node) CheckPendingUpgrade(executable string) (*governance.UpgradePlan, error) {
+	if n == nil || n.chain == nil {
+		return nil, fmt.Errorf("node not initialised")
+	}
+	plan, err := n.PendingUpgrade()
+	if err != nil || plan == nil {
+		return nil, err
+	}
+	if !UpgradeHandlerRegistered(plan.Name) {
+		if height := n.chain.Height(); height >= plan.Height {
+			return nil, fmt.Errorf("%w: %q scheduled at height %d (chain height %d)", ErrUpgradeHandlerMissing, plan.Name, plan.Height, height)
+		}
+		return plan, nil
+	}
+	if plan.Checksum == "" {
+		return plan, nil
+	}
+	sum, err := BinaryChecksum(executable)
+	if err != nil {
+		return nil, fmt.Errorf("upgrade %q: checksum binary: %w", plan.Name, err)
+	}
+	if !strings.EqualFold(sum, plan.Checksum) {
+		return nil, fmt.Errorf("%w: %q expects %s, binary is %s", ErrUpgradeChecksumMismatch, plan.Name, plan.Checksum, sum)
+	}
+	return plan, nil
+}
+
+// applyScheduledUpgrade runs the scheduled upgrade's migration at the start
+// of the first block above the plan height and marks the plan applied, so
+// the writes are part of that block's state root on every validator. A
+// binary without a handler for the plan refuses the block with
+// ErrUpgradeHalt.
+func applyScheduledUpgrade(sp *StateProcessor, height uint64) error {
+	manager := nhbstate.NewManager(sp.Trie)
+	plan, ok, err := manager.UpgradePlan()
+	if err != nil {
+		return err
+	}
+	if !ok || height <= plan.Height {
+		return nil
+	}
+	handler, ok := lookupUpgradeHandler(plan.Name)
+	if !ok {
+		return fmt.Errorf("%w: %q at height %d", ErrUpgradeHalt, plan.Name, plan.Height)
+	}
+	if err := handler(manager, *plan); err != nil {
+		return fmt.Errorf("upgrade %q: %w", plan.Name, err)
+	}
+	if err := manager.UpgradeMarkApplied(plan.Name, height); err != nil {
+		return err
+	}
+	slog.Info("software upgrade applied",
+		slog.String("name", plan.Name),
+		slog.Uint64("plan_height", plan.Height),
+		slog.Uint64("height", height),
+		slog.Uint64("proposal_id", plan.ProposalID))
+	return nil
+}
+
+// signalUpgradeHaltLocked notifies the halt handler when the block just
+// committed is the last one before a scheduled upgrade this binary cannot
+// apply. Nodes catching up on historical blocks keep syncing; they stop at
+// the upgrade gate instead. Callers must hold stateMu.
+func (n *Node) signalUpgradeHaltLocked(height uint64, catchingUp bool) {
+	plan, ok, err := nhbstate.NewManager(n.state.Trie).UpgradePlan()
+	if err != nil || !ok || plan.Height != height || UpgradeHandlerRegistered(plan.Name) {
+		return
+	}
+	slog.Warn("chain halted for software upgrade",
+		slog.String("name", plan.Name),
+		slog.Uint64("height", plan.Height),
+		slog.String("checksum", plan.Checksum))
+	handler := n.upgradeHaltHandler
+	if handler == nil || catchingUp {
+		return
+	}
+	n.upgradeHaltOnce.Do(func() {
+		go handler(*plan)
+	})
+}
+
+// validateUpgradeProposalLocked rejects executing a software upgrade whose
+// height the chain has already reached. Callers must hold stateMu.
+func (n *Node) validateUpgradeProposalLocked(proposal *governance.Proposal) error {
+	if proposal == nil || strings.TrimSpace(proposal.Target) != governance.ProposalKindSoftwareUpgrade {
+		return nil
+	}
+	var payload governance.SoftwareUpgradePayload
+	if err := json.Unmarshal([]byte(proposal.ProposedChange), &payload); err != nil {
+		return fmt.Errorf("governance: invalid payload: %w", err)
+	}
+	if height := n.chain.Height(); payload.Height <= height {
+		return fmt.Errorf("governance: upgrade height %d must be above chain height %d", payload.Height, height)
+	}
+	return nil
+}
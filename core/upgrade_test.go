@@ -0,0 +1,231 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+	"nhbchain/native/governance"
+	"nhbchain/storage"
+)
+
+func commitEmptyBlock(t *testing.T, node *Node) error {
+	t.Helper()
+	block, err := node.CreateBlock(nil)
+	if err != nil {
+		t.Fatalf("create block: %v", err)
+	}
+	return node.CommitBlock(block)
+}
+
+func scheduleUpgrade(t *testing.T, node *Node, plan governance.UpgradePlan) {
+	t.Helper()
+	if err := node.WithState(func(m *nhbstate.Manager) error {
+		return m.UpgradeSchedule(&plan)
+	}); err != nil {
+		t.Fatalf("schedule upgrade: %v", err)
+	}
+}
+
+func registerMarkerMigration(name string, marker [20]byte) {
+	RegisterUpgradeHandler(name, func(m *nhbstate.Manager, plan governance.UpgradePlan) error {
+		return m.PutAccount(marker[:], &types.Account{
+			BalanceNHB:  big.NewInt(int64(plan.Height)),
+			BalanceZNHB: big.NewInt(0),
+			Stake:       big.NewInt(0),
+		})
+	})
+}
+
+func requireUpgradeApplied(t *testing.T, node *Node, name string, marker [20]byte, planHeight uint64) {
+	t.Helper()
+	account, err := node.GetAccount(marker[:])
+	if err != nil {
+		t.Fatalf("get migrated account: %v", err)
+	}
+	if account.BalanceNHB.Cmp(big.NewInt(int64(planHeight))) != 0 {
+		t.Fatalf("expected migration write to persist, got balance %s", account.BalanceNHB)
+	}
+	if err := node.WithState(func(m *nhbstate.Manager) error {
+		if _, ok, err := m.UpgradePlan(); err != nil || ok {
+			return fmt.Errorf("expected plan to be cleared (ok=%v err=%v)", ok, err)
+		}
+		height, ok, err := m.UpgradeAppliedHeight(name)
+		if err != nil || !ok || height != planHeight+1 {
+			return fmt.Errorf("unexpected applied height %d (ok=%v err=%v)", height, ok, err)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("post-upgrade state: %v", err)
+	}
+}
+
+func TestSoftwareUpgradeHaltsAndResumesAfterMigration(t *testing.T) {
+	node := newTestNode(t)
+	planHeight := node.chain.Height() + 1
+	const name = "test-halt-and-resume"
+	scheduleUpgrade(t, node, governance.UpgradePlan{Name: name, Height: planHeight, Checksum: strings.Repeat("00", 32)})
+
+	halted := make(chan governance.UpgradePlan, 1)
+	node.SetUpgradeHaltHandler(func(plan governance.UpgradePlan) { halted <- plan })
+
+	if err := commitEmptyBlock(t, node); err != nil {
+		t.Fatalf("commit block at upgrade height: %v", err)
+	}
+	if plan := <-halted; plan.Name != name || plan.Height != planHeight {
+		t.Fatalf("unexpected halt plan: %+v", plan)
+	}
+	if _, err := node.CreateBlock(nil); !errors.Is(err, ErrUpgradeHalt) {
+		t.Fatalf("expected old binary to refuse blocks past the upgrade height, got %v", err)
+	}
+	if _, err := node.CheckPendingUpgrade(""); !errors.Is(err, ErrUpgradeHandlerMissing) {
+		t.Fatalf("expected missing handler error, got %v", err)
+	}
+
+	var marker [20]byte
+	marker[0] = 0x77
+	registerMarkerMigration(name, marker)
+	if err := commitEmptyBlock(t, node); err != nil {
+		t.Fatalf("commit block after upgrade: %v", err)
+	}
+	requireUpgradeApplied(t, node, name, marker, planHeight)
+}
+
+func TestSoftwareUpgradeMigratesInsideBlockOnEveryValidator(t *testing.T) {
+	t.Setenv("NHB_ENV", "dev")
+	validatorKey, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate validator key: %v", err)
+	}
+	now := time.Unix(1_776_115_000, 0).UTC()
+	newNode := func() *Node {
+		db := storage.NewMemDB()
+		t.Cleanup(func() { db.Close() })
+		node, err := NewNode(db, validatorKey, "", true, false)
+		if err != nil {
+			t.Fatalf("new node: %v", err)
+		}
+		node.SetTimeSource(func() time.Time { return now })
+		return node
+	}
+	proposer := newNode()
+	follower := newNode()
+
+	const name = "test-two-validators"
+	planHeight := proposer.chain.Height() + 2
+	plan := governance.UpgradePlan{Name: name, Height: planHeight}
+	// Both validators stage the same governance write and build the same
+	// block, so the plan is part of committed state on each of them.
+	for _, node := range []*Node{proposer, follower} {
+		scheduleUpgrade(t, node, plan)
+		if err := commitEmptyBlock(t, node); err != nil {
+			t.Fatalf("commit scheduling block: %v", err)
+		}
+	}
+	if proposer.chain.Tip() == nil || !bytes.Equal(proposer.chain.Tip(), follower.chain.Tip()) {
+		t.Fatalf("validators diverged before the upgrade")
+	}
+
+	relay := func() {
+		t.Helper()
+		block, err := proposer.CreateBlock(nil)
+		if err != nil {
+			t.Fatalf("proposer create block: %v", err)
+		}
+		if err := follower.ValidateBlock(block); err != nil {
+			t.Fatalf("follower validate block %d: %v", block.Header.Height, err)
+		}
+		if err := proposer.CommitBlock(block); err != nil {
+			t.Fatalf("proposer commit block %d: %v", block.Header.Height, err)
+		}
+		if err := follower.CommitBlock(block); err != nil {
+			t.Fatalf("follower commit block %d: %v", block.Header.Height, err)
+		}
+	}
+	relay()
+
+	var marker [20]byte
+	marker[0] = 0x78
+	registerMarkerMigration(name, marker)
+	relay()
+	relay()
+
+	for _, node := range []*Node{proposer, follower} {
+		requireUpgradeApplied(t, node, name, marker, planHeight)
+	}
+	if !bytes.Equal(proposer.chain.Tip(), follower.chain.Tip()) {
+		t.Fatalf("validators diverged after the upgrade")
+	}
+}
+
+func TestCheckPendingUpgradeRejectsChecksumMismatch(t *testing.T) {
+	node := newTestNode(t)
+	binary := filepath.Join(t.TempDir(), "nhb")
+	if err := os.WriteFile(binary, []byte("release"), 0o600); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+	sum, err := BinaryChecksum(binary)
+	if err != nil {
+		t.Fatalf("checksum: %v", err)
+	}
+	const name = "test-checksum"
+	registerMarkerMigration(name, [20]byte{0x79})
+
+	scheduleUpgrade(t, node, governance.UpgradePlan{Name: name, Height: node.chain.Height() + 5, Checksum: strings.Repeat("ab", 32)})
+	if _, err := node.CheckPendingUpgrade(binary); !errors.Is(err, ErrUpgradeChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	scheduleUpgrade(t, node, governance.UpgradePlan{Name: name, Height: node.chain.Height() + 5, Checksum: sum})
+	if plan, err := node.CheckPendingUpgrade(binary); err != nil || plan == nil {
+		t.Fatalf("expected matching binary to pass, got plan=%v err=%v", plan, err)
+	}
+}
+
+func TestSoftwareUpgradeProposalRejectsReachedHeight(t *testing.T) {
+	node := newTestNode(t)
+	if err := commitEmptyBlock(t, node); err != nil {
+		t.Fatalf("commit block: %v", err)
+	}
+
+	var proposer [20]byte
+	proposer[0] = 0x51
+	if err := node.WithState(func(m *nhbstate.Manager) error {
+		return m.PutAccount(proposer[:], &types.Account{
+			BalanceZNHB: big.NewInt(1_000_000),
+			BalanceNHB:  big.NewInt(0),
+			Stake:       big.NewInt(0),
+		})
+	}); err != nil {
+		t.Fatalf("seed proposer: %v", err)
+	}
+
+	payload := fmt.Sprintf(`{"name":"stale","height":%d,"checksum":"%s"}`, node.chain.Height(), strings.Repeat("ab", 32))
+	proposalID, err := node.GovernancePropose(proposer, governance.ProposalKindSoftwareUpgrade, payload, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("submit upgrade proposal: %v", err)
+	}
+	markProposalPassed(t, node, proposalID)
+	if _, err := node.GovernanceQueue(proposalID); err != nil {
+		t.Fatalf("queue proposal: %v", err)
+	}
+	clearProposalTimelock(t, node, proposalID)
+	if _, err := node.GovernanceExecute(proposalID); err == nil {
+		t.Fatalf("expected upgrade at a reached height to be rejected")
+	}
+	pending, err := node.PendingUpgrade()
+	if err != nil {
+		t.Fatalf("pending upgrade: %v", err)
+	}
+	if pending != nil {
+		t.Fatalf("expected no scheduled upgrade, got %+v", pending)
+	}
+}
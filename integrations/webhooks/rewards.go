@@ -5,13 +5,19 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	_ "modernc.org/sqlite"
+
+	"nhbchain/services/webhook/outbox"
 )
 
 // EventType represents the logical webhook topic.
@@ -49,7 +55,9 @@ type RewardsPaidPayload struct {
 	DeliveryID string    `json:"deliveryId"`
 }
 
-// Dispatcher orchestrates webhook deliveries with retry and exponential backoff.
+// Dispatcher orchestrates webhook deliveries with retry and exponential
+// backoff. Deliveries are persisted in an outbox before they are attempted so
+// a restart or a long receiver outage does not lose them.
 type Dispatcher struct {
 	endpoint    string
 	secret      []byte
@@ -58,17 +66,16 @@ type Dispatcher struct {
 	minBackoff  time.Duration
 	maxBackoff  time.Duration
 
+	outbox     *outbox.Outbox
+	outboxDB   *sql.DB
+	outboxPath string
+	ownDB      *sql.DB
+
 	ctx    context.Context
 	cancel context.CancelFunc
-	queue  chan delivery
 	wg     sync.WaitGroup
 }
 
-type delivery struct {
-	eventType EventType
-	body      []byte
-}
-
 // Option mutates dispatcher configuration.
 type Option func(*Dispatcher)
 
@@ -96,7 +103,27 @@ func WithRetryPolicy(maxAttempts int, minBackoff, maxBackoff time.Duration) Opti
 	}
 }
 
-// NewDispatcher constructs a dispatcher and spawns the worker goroutine.
+// WithOutboxDB persists deliveries in the supplied SQLite database. The
+// caller keeps ownership of the handle.
+func WithOutboxDB(db *sql.DB) Option {
+	return func(d *Dispatcher) {
+		if db != nil {
+			d.outboxDB = db
+		}
+	}
+}
+
+// WithOutboxPath persists deliveries in the SQLite database at path. The
+// dispatcher opens the file and closes it on Close.
+func WithOutboxPath(path string) Option {
+	return func(d *Dispatcher) {
+		d.outboxPath = strings.TrimSpace(path)
+	}
+}
+
+// NewDispatcher constructs a dispatcher and spawns the worker goroutine. An
+// outbox must be configured with WithOutboxPath or WithOutboxDB so queued
+// deliveries survive a restart.
 func NewDispatcher(endpoint string, secret []byte, opts ...Option) (*Dispatcher, error) {
 	endpoint = string(bytes.TrimSpace([]byte(endpoint)))
 	if endpoint == "" {
@@ -105,7 +132,6 @@ func NewDispatcher(endpoint string, secret []byte, opts ...Option) (*Dispatcher,
 	if len(secret) == 0 {
 		return nil, errors.New("webhook: secret required")
 	}
-	ctx, cancel := context.WithCancel(context.Background())
 	dispatcher := &Dispatcher{
 		endpoint:    endpoint,
 		secret:      append([]byte(nil), secret...),
@@ -113,25 +139,62 @@ func NewDispatcher(endpoint string, secret []byte, opts ...Option) (*Dispatcher,
 		maxAttempts: defaultMaxAttempts,
 		minBackoff:  defaultMinBackoff,
 		maxBackoff:  defaultMaxBackoff,
-		ctx:         ctx,
-		cancel:      cancel,
-		queue:       make(chan delivery, 32),
 	}
 	for _, opt := range opts {
 		opt(dispatcher)
 	}
+	db := dispatcher.outboxDB
+	if db == nil {
+		if dispatcher.outboxPath == "" {
+			return nil, errors.New("webhook: outbox database path required")
+		}
+		var err error
+		if db, err = sql.Open("sqlite", dispatcher.outboxPath); err != nil {
+			return nil, fmt.Errorf("webhook: open outbox: %w", err)
+		}
+		// The enqueue path and the worker share the file; a single
+		// connection serialises their writes instead of racing for locks.
+		db.SetMaxOpenConns(1)
+		dispatcher.ownDB = db
+	}
+	ob, err := outbox.New(db,
+		outbox.WithRetryPolicy(outbox.RetryPolicy{
+			MaxAttempts: dispatcher.maxAttempts,
+			MinBackoff:  dispatcher.minBackoff,
+			MaxBackoff:  dispatcher.maxBackoff,
+		}),
+		outbox.WithPollInterval(dispatcher.minBackoff),
+	)
+	if err != nil {
+		if dispatcher.ownDB != nil {
+			_ = dispatcher.ownDB.Close()
+		}
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	dispatcher.outbox = ob
+	dispatcher.ctx, dispatcher.cancel = context.WithCancel(context.Background())
 	dispatcher.wg.Add(1)
 	go dispatcher.worker()
 	return dispatcher, nil
 }
 
 // Close stops the dispatcher and waits for inflight deliveries to complete.
+// Undelivered messages remain in the outbox.
 func (d *Dispatcher) Close() {
 	if d == nil {
 		return
 	}
 	d.cancel()
 	d.wg.Wait()
+	if d.ownDB != nil {
+		_ = d.ownDB.Close()
+	}
+}
+
+// Outbox exposes the dispatcher's delivery queue, e.g. to mount
+// outbox.NewAdminHandler.
+func (d *Dispatcher) Outbox() *outbox.Outbox {
+	return d.outbox
 }
 
 // EnqueueReady sends a ready event asynchronously.
@@ -162,61 +225,32 @@ func (d *Dispatcher) enqueue(eventType EventType, body interface{}) error {
 	if d == nil {
 		return errors.New("webhook: dispatcher not initialised")
 	}
+	if d.ctx.Err() != nil {
+		return errors.New("webhook: dispatcher closed")
+	}
 	data, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
-	select {
-	case d.queue <- delivery{eventType: eventType, body: data}:
-		return nil
-	case <-d.ctx.Done():
-		return errors.New("webhook: dispatcher closed")
-	}
+	_, err = d.outbox.Enqueue(d.ctx, d.endpoint, string(eventType), data)
+	return err
 }
 
 func (d *Dispatcher) worker() {
 	defer d.wg.Done()
-	for {
-		select {
-		case job := <-d.queue:
-			d.process(job)
-		case <-d.ctx.Done():
-			return
-		}
-	}
-}
-
-func (d *Dispatcher) process(job delivery) {
-	attempt := 0
-	backoff := d.minBackoff
-	for {
-		attempt++
-		ctx, cancel := context.WithTimeout(d.ctx, d.client.Timeout)
-		err := d.send(ctx, job)
-		cancel()
-		if err == nil {
-			return
-		}
-		if attempt >= d.maxAttempts {
-			return
-		}
-		select {
-		case <-time.After(backoff):
-		case <-d.ctx.Done():
-			return
-		}
-		backoff = nextBackoff(backoff, d.maxBackoff)
-	}
+	_ = d.outbox.Run(d.ctx, d.deliver)
 }
 
-func (d *Dispatcher) send(ctx context.Context, job delivery) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.endpoint, bytes.NewReader(job.body))
+func (d *Dispatcher) deliver(ctx context.Context, msg outbox.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, d.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.endpoint, bytes.NewReader(msg.Payload))
 	if err != nil {
-		return err
+		return outbox.Permanent(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-NHB-Event", string(job.eventType))
-	req.Header.Set("X-NHB-Signature", d.sign(job.body))
+	req.Header.Set("X-NHB-Event", msg.EventType)
+	req.Header.Set("X-NHB-Signature", d.sign(msg.Payload))
 	resp, err := d.client.Do(req)
 	if err != nil {
 		return err
@@ -234,14 +268,3 @@ func (d *Dispatcher) sign(body []byte) string {
 	sum := mac.Sum(nil)
 	return "sha256=" + hex.EncodeToString(sum)
 }
-
-func nextBackoff(current, max time.Duration) time.Duration {
-	next := current * 2
-	if next > max {
-		return max
-	}
-	if next < current {
-		return max
-	}
-	return next
-}
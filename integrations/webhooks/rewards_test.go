@@ -1,9 +1,11 @@
 package webhooks
 
 import (
+	"database/sql"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -21,7 +23,7 @@ func TestDispatcherSignsPayload(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer server.Close()
-	dispatcher, err := NewDispatcher(server.URL, []byte("secret"))
+	dispatcher, err := NewDispatcher(server.URL, []byte("secret"), WithOutboxPath(filepath.Join(t.TempDir(), "outbox.db")))
 	if err != nil {
 		t.Fatalf("dispatcher: %v", err)
 	}
@@ -48,7 +50,7 @@ func TestDispatcherRetries(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer server.Close()
-	dispatcher, err := NewDispatcher(server.URL, []byte("secret"), WithRetryPolicy(5, time.Millisecond*10, time.Millisecond*20))
+	dispatcher, err := NewDispatcher(server.URL, []byte("secret"), WithOutboxPath(filepath.Join(t.TempDir(), "outbox.db")), WithRetryPolicy(5, time.Millisecond*10, time.Millisecond*20))
 	if err != nil {
 		t.Fatalf("dispatcher: %v", err)
 	}
@@ -62,6 +64,52 @@ func TestDispatcherRetries(t *testing.T) {
 	}
 }
 
+func TestDispatcherRequiresOutbox(t *testing.T) {
+	if _, err := NewDispatcher("https://example.invalid", []byte("secret")); err == nil {
+		t.Fatalf("expected dispatcher without an outbox to be rejected")
+	}
+}
+
+func TestDispatcherRedeliversAfterRestart(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "outbox.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+
+	var healthy atomic.Bool
+	var delivered atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		delivered.Store(r.Header.Get("X-NHB-Event"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	first, err := NewDispatcher(server.URL, []byte("secret"), WithOutboxDB(db), WithRetryPolicy(10, time.Millisecond*10, time.Millisecond*20))
+	if err != nil {
+		t.Fatalf("dispatcher: %v", err)
+	}
+	if err := first.EnqueuePaid(RewardsPaidPayload{Epoch: 3, Count: 1, TxRef: "tx"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	first.Close()
+
+	healthy.Store(true)
+	second, err := NewDispatcher(server.URL, []byte("secret"), WithOutboxDB(db), WithRetryPolicy(10, time.Millisecond*10, time.Millisecond*20))
+	if err != nil {
+		t.Fatalf("dispatcher: %v", err)
+	}
+	defer second.Close()
+	waitFor(func() bool { return delivered.Load() != nil }, time.Second)
+	if got, _ := delivered.Load().(string); got != string(EventRewardsPaid) {
+		t.Fatalf("expected persisted paid event to be delivered after restart, got %q", got)
+	}
+}
+
 func waitFor(cond func() bool, timeout time.Duration) {
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
@@ -0,0 +1,139 @@
+package creator
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// AccessTokenTTLSeconds bounds how long an access token stays valid. Tokens
+// never outlive the subscription's paid-through time.
+const AccessTokenTTLSeconds = int64(300)
+
+// accessTokenDomain separates access token signatures from any other message
+// the issuing key might sign.
+const accessTokenDomain = "nhb/creator-access-token/v1"
+
+var (
+	errNoAccess           = errors.New("creator engine: fan has no active subscription")
+	errMalformedToken     = errors.New("creator engine: malformed access token")
+	errInvalidTokenIssuer = errors.New("creator engine: access token signature invalid")
+)
+
+// AccessClaims is the payload of a creator access token. Off-chain hosts
+// check the issuer and expiry, then confirm the subscription is still active
+// on chain before serving gated content.
+type AccessClaims struct {
+	Creator     string `json:"creator"`
+	Fan         string `json:"fan"`
+	TierID      string `json:"tierId"`
+	PaidThrough int64  `json:"paidThrough"`
+	IssuedAt    int64  `json:"issuedAt"`
+	ExpiresAt   int64  `json:"expiresAt"`
+}
+
+// Parties decodes the creator and fan addresses named in the claims.
+func (c *AccessClaims) Parties() (creator [20]byte, fan [20]byte, err error) {
+	if c == nil {
+		return creator, fan, errMalformedToken
+	}
+	for _, field := range []struct {
+		value string
+		out   *[20]byte
+	}{{c.Creator, &creator}, {c.Fan, &fan}} {
+		raw, decodeErr := hex.DecodeString(strings.TrimPrefix(field.value, "0x"))
+		if decodeErr != nil || len(raw) != 20 {
+			return creator, fan, errMalformedToken
+		}
+		copy(field.out[:], raw)
+	}
+	return creator, fan, nil
+}
+
+// AccessClaims builds the claims for a fan's active subscription to the
+// creator. When tierID is non-empty the subscription must be for that tier.
+func (e *Engine) AccessClaims(fan [20]byte, creator [20]byte, tierID string) (*AccessClaims, error) {
+	subscription, err := e.Subscription(creator, fan)
+	if errors.Is(err, errSubscriptionNotFound) {
+		return nil, errNoAccess
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := e.now()
+	if !subscription.ActiveAt(now) {
+		return nil, errNoAccess
+	}
+	if tier := strings.TrimSpace(tierID); tier != "" && tier != subscription.TierID {
+		return nil, errNoAccess
+	}
+	expires := now + AccessTokenTTLSeconds
+	if expires > subscription.PaidThrough {
+		expires = subscription.PaidThrough
+	}
+	return &AccessClaims{
+		Creator:     hexAddr(creator),
+		Fan:         hexAddr(fan),
+		TierID:      subscription.TierID,
+		PaidThrough: subscription.PaidThrough,
+		IssuedAt:    now,
+		ExpiresAt:   expires,
+	}, nil
+}
+
+// SignAccessToken encodes the claims and signs them with the issuing node's
+// access token key. The token is "<base64url claims>.<base64url signature>"
+// where the signature is a recoverable secp256k1 signature over
+// keccak256(accessTokenDomain || claims JSON).
+func SignAccessToken(claims *AccessClaims, key *ecdsa.PrivateKey) (string, error) {
+	if claims == nil || key == nil {
+		return "", errMalformedToken
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	sig, err := ethcrypto.Sign(accessTokenDigest(payload), key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// ParseAccessToken decodes a token and recovers the address that signed it.
+// It does not check expiry or on-chain state; callers compare the issuer
+// with the node they trust and re-check the subscription.
+func ParseAccessToken(token string) (*AccessClaims, [20]byte, error) {
+	var issuer [20]byte
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 2 {
+		return nil, issuer, errMalformedToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, issuer, errMalformedToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(sig) != 65 {
+		return nil, issuer, errMalformedToken
+	}
+	claims := new(AccessClaims)
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, issuer, errMalformedToken
+	}
+	pub, err := ethcrypto.SigToPub(accessTokenDigest(payload), sig)
+	if err != nil {
+		return nil, issuer, errInvalidTokenIssuer
+	}
+	copy(issuer[:], ethcrypto.PubkeyToAddress(*pub).Bytes())
+	return claims, issuer, nil
+}
+
+func accessTokenDigest(payload []byte) []byte {
+	return ethcrypto.Keccak256([]byte(accessTokenDomain), payload)
+}
@@ -35,6 +35,12 @@ var (
 	errTipRateLimited         = errors.New("creator engine: tip rate limit exceeded")
 	errInvalidURI             = errors.New("creator engine: invalid content uri")
 	errInvalidMetadata        = errors.New("creator engine: invalid content metadata")
+	errInvalidTier            = errors.New("creator engine: invalid subscription tier")
+	errTierNotFound           = errors.New("creator engine: subscription tier not found")
+	errTierInactive           = errors.New("creator engine: subscription tier inactive")
+	errInvalidPeriods         = errors.New("creator engine: invalid number of periods")
+	errSubscriptionActive     = errors.New("creator engine: subscription already active")
+	errSubscriptionNotFound   = errors.New("creator engine: subscription not found")
 )
 
 const stakingAccrualBps = 250 // 2.5% accrual when staking behind a creator.
@@ -61,6 +67,10 @@ type engineState interface {
 	CreatorPayoutLedgerPut(ledger *PayoutLedger) error
 	CreatorRateLimitGet() (*RateLimitSnapshot, bool, error)
 	CreatorRateLimitPut(snapshot *RateLimitSnapshot) error
+	CreatorTierGet(creator [20]byte, id string) (*SubscriptionTier, bool, error)
+	CreatorTierPut(tier *SubscriptionTier) error
+	CreatorSubscriptionGet(creator [20]byte, fan [20]byte) (*Subscription, bool, error)
+	CreatorSubscriptionPut(subscription *Subscription) error
 	GetAccount(addr []byte) (*types.Account, error)
 	PutAccount(addr []byte, account *types.Account) error
 }
@@ -235,6 +245,15 @@ func ensureLedgerFields(ledger *PayoutLedger) *PayoutLedger {
 	if ledger.IndexRay == nil {
 		ledger.IndexRay = new(big.Int).Set(oneRay)
 	}
+	if ledger.TotalSubscriptions == nil {
+		ledger.TotalSubscriptions = big.NewInt(0)
+	}
+	if ledger.TotalSubscriptionsZNHB == nil {
+		ledger.TotalSubscriptionsZNHB = big.NewInt(0)
+	}
+	if ledger.PendingDistributionZNHB == nil {
+		ledger.PendingDistributionZNHB = big.NewInt(0)
+	}
 	return ledger
 }
 
@@ -301,14 +320,17 @@ func isZeroAddress(addr [20]byte) bool {
 
 func newLedger(creator [20]byte) *PayoutLedger {
 	return &PayoutLedger{
-		Creator:             creator,
-		TotalTips:           big.NewInt(0),
-		TotalStakingYield:   big.NewInt(0),
-		PendingDistribution: big.NewInt(0),
-		LastPayout:          0,
-		TotalAssets:         big.NewInt(0),
-		TotalShares:         big.NewInt(0),
-		IndexRay:            new(big.Int).Set(oneRay),
+		Creator:                 creator,
+		TotalTips:               big.NewInt(0),
+		TotalStakingYield:       big.NewInt(0),
+		PendingDistribution:     big.NewInt(0),
+		LastPayout:              0,
+		TotalAssets:             big.NewInt(0),
+		TotalShares:             big.NewInt(0),
+		IndexRay:                new(big.Int).Set(oneRay),
+		TotalSubscriptions:      big.NewInt(0),
+		TotalSubscriptionsZNHB:  big.NewInt(0),
+		PendingDistributionZNHB: big.NewInt(0),
 	}
 }
 
@@ -765,7 +787,9 @@ func (e *Engine) UnstakeCreator(fan [20]byte, creator [20]byte, amount *big.Int)
 	return stake, nil
 }
 
-// ClaimPayouts settles the pending distribution for the creator and credits their balance.
+// ClaimPayouts settles the pending distribution for the creator and credits
+// their balance. Pending ZNHB subscription revenue is paid out alongside the
+// NHB distribution; the returned amount is the NHB portion.
 func (e *Engine) ClaimPayouts(creator [20]byte) (*PayoutLedger, *big.Int, error) {
 	if e == nil || e.state == nil {
 		return nil, nil, errNilState
@@ -780,7 +804,8 @@ func (e *Engine) ClaimPayouts(creator [20]byte) (*PayoutLedger, *big.Int, error)
 		ledger = ensureLedgerFields(ledger)
 	}
 	pending := newBigInt(ledger.PendingDistribution)
-	if pending.Sign() == 0 {
+	pendingZNHB := newBigInt(ledger.PendingDistributionZNHB)
+	if pending.Sign() == 0 && pendingZNHB.Sign() == 0 {
 		return ledger.Clone(), big.NewInt(0), nil
 	}
 	if isZeroAddress(e.payoutVault) {
@@ -796,11 +821,13 @@ func (e *Engine) ClaimPayouts(creator [20]byte) (*PayoutLedger, *big.Int, error)
 		return nil, nil, err
 	}
 	vaultAccount = ensureAccount(vaultAccount)
-	if vaultAccount.BalanceNHB.Cmp(pending) < 0 {
+	if vaultAccount.BalanceNHB.Cmp(pending) < 0 || vaultAccount.BalanceZNHB.Cmp(pendingZNHB) < 0 {
 		return nil, nil, errPayoutVaultUnderfunded
 	}
 	creatorAccount.BalanceNHB = new(big.Int).Add(creatorAccount.BalanceNHB, pending)
 	vaultAccount.BalanceNHB = new(big.Int).Sub(vaultAccount.BalanceNHB, pending)
+	creatorAccount.BalanceZNHB = new(big.Int).Add(creatorAccount.BalanceZNHB, pendingZNHB)
+	vaultAccount.BalanceZNHB = new(big.Int).Sub(vaultAccount.BalanceZNHB, pendingZNHB)
 	if err := e.state.PutAccount(e.payoutVault[:], vaultAccount); err != nil {
 		return nil, nil, err
 	}
@@ -808,6 +835,7 @@ func (e *Engine) ClaimPayouts(creator [20]byte) (*PayoutLedger, *big.Int, error)
 		return nil, nil, err
 	}
 	ledger.PendingDistribution = big.NewInt(0)
+	ledger.PendingDistributionZNHB = big.NewInt(0)
 	ledger.LastPayout = e.now()
 	if err := e.state.CreatorPayoutLedgerPut(ledger); err != nil {
 		return nil, nil, err
@@ -14,6 +14,8 @@ type mockState struct {
 	ledgers  map[string]*PayoutLedger
 	accounts map[string]*types.Account
 	rate     *RateLimitSnapshot
+	tiers    map[string]*SubscriptionTier
+	subs     map[string]*Subscription
 }
 
 func newMockState() *mockState {
@@ -22,6 +24,8 @@ func newMockState() *mockState {
 		stakes:   make(map[string]*Stake),
 		ledgers:  make(map[string]*PayoutLedger),
 		accounts: make(map[string]*types.Account),
+		tiers:    make(map[string]*SubscriptionTier),
+		subs:     make(map[string]*Subscription),
 	}
 }
 
@@ -128,6 +132,40 @@ func (m *mockState) CreatorRateLimitPut(snapshot *RateLimitSnapshot) error {
 	return nil
 }
 
+func (m *mockState) CreatorTierGet(creator [20]byte, id string) (*SubscriptionTier, bool, error) {
+	tier, ok := m.tiers[string(creator[:])+id]
+	if !ok {
+		return nil, false, nil
+	}
+	clone := *tier
+	clone.Price = newBigInt(tier.Price)
+	return &clone, true, nil
+}
+
+func (m *mockState) CreatorTierPut(tier *SubscriptionTier) error {
+	clone := *tier
+	clone.Price = newBigInt(tier.Price)
+	m.tiers[string(tier.Creator[:])+tier.ID] = &clone
+	return nil
+}
+
+func (m *mockState) CreatorSubscriptionGet(creator [20]byte, fan [20]byte) (*Subscription, bool, error) {
+	sub, ok := m.subs[stakeKey(creator, fan)]
+	if !ok {
+		return nil, false, nil
+	}
+	clone := *sub
+	clone.Price = newBigInt(sub.Price)
+	return &clone, true, nil
+}
+
+func (m *mockState) CreatorSubscriptionPut(sub *Subscription) error {
+	clone := *sub
+	clone.Price = newBigInt(sub.Price)
+	m.subs[stakeKey(sub.Creator, sub.Fan)] = &clone
+	return nil
+}
+
 func (m *mockState) GetAccount(addr []byte) (*types.Account, error) {
 	if acc, ok := m.accounts[string(addr)]; ok && acc != nil {
 		return cloneAccount(acc), nil
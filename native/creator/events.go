@@ -1,6 +1,8 @@
 package creator
 
 import (
+	"strconv"
+
 	"nhbchain/core/events"
 	"nhbchain/core/types"
 )
@@ -16,6 +18,14 @@ const (
 	EventTypeCreatorUnstaked = "creator.fan.unstaked"
 	// EventTypeCreatorPayoutAccrued is emitted when payouts accrue for a creator.
 	EventTypeCreatorPayoutAccrued = "creator.payout.accrued"
+	// EventTypeSubscriptionTierUpdated is emitted when a creator creates or edits a tier.
+	EventTypeSubscriptionTierUpdated = "creator.subscription.tier_updated"
+	// EventTypeSubscriptionStarted is emitted when a fan subscribes to a tier.
+	EventTypeSubscriptionStarted = "creator.subscription.started"
+	// EventTypeSubscriptionRenewed is emitted when a fan extends a subscription.
+	EventTypeSubscriptionRenewed = "creator.subscription.renewed"
+	// EventTypeSubscriptionCancelled is emitted when a fan cancels a subscription.
+	EventTypeSubscriptionCancelled = "creator.subscription.cancelled"
 )
 
 type eventEnvelope struct {
@@ -96,3 +106,56 @@ func CreatorPayoutAccruedEvent(creator string, pending string, totalTips string,
 		},
 	}
 }
+
+// SubscriptionTierUpdatedEvent captures a tier being created or edited.
+func SubscriptionTierUpdatedEvent(creator string, tierID string, asset string, price string, periodSeconds int64, active bool) *types.Event {
+	return &types.Event{
+		Type: EventTypeSubscriptionTierUpdated,
+		Attributes: map[string]string{
+			"creator":       creator,
+			"tierId":        tierID,
+			"asset":         asset,
+			"price":         price,
+			"periodSeconds": strconv.FormatInt(periodSeconds, 10),
+			"active":        strconv.FormatBool(active),
+		},
+	}
+}
+
+// SubscriptionStartedEvent captures a fan subscribing to a tier.
+func SubscriptionStartedEvent(creator string, fan string, tierID string, asset string, amount string, paidThrough int64) *types.Event {
+	return subscriptionPaymentEvent(EventTypeSubscriptionStarted, creator, fan, tierID, asset, amount, paidThrough)
+}
+
+// SubscriptionRenewedEvent captures a fan paying for further periods.
+func SubscriptionRenewedEvent(creator string, fan string, tierID string, asset string, amount string, paidThrough int64) *types.Event {
+	return subscriptionPaymentEvent(EventTypeSubscriptionRenewed, creator, fan, tierID, asset, amount, paidThrough)
+}
+
+// SubscriptionCancelledEvent captures a cancellation and the refund paid to the fan.
+func SubscriptionCancelledEvent(creator string, fan string, tierID string, asset string, refund string) *types.Event {
+	return &types.Event{
+		Type: EventTypeSubscriptionCancelled,
+		Attributes: map[string]string{
+			"creator": creator,
+			"fan":     fan,
+			"tierId":  tierID,
+			"asset":   asset,
+			"refund":  refund,
+		},
+	}
+}
+
+func subscriptionPaymentEvent(eventType string, creator string, fan string, tierID string, asset string, amount string, paidThrough int64) *types.Event {
+	return &types.Event{
+		Type: eventType,
+		Attributes: map[string]string{
+			"creator":     creator,
+			"fan":         fan,
+			"tierId":      tierID,
+			"asset":       asset,
+			"amount":      amount,
+			"paidThrough": strconv.FormatInt(paidThrough, 10),
+		},
+	}
+}
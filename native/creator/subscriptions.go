@@ -0,0 +1,363 @@
+package creator
+
+import (
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"nhbchain/core/types"
+)
+
+const (
+	// SubscriptionAssetNHB and SubscriptionAssetZNHB are the assets a tier
+	// may be priced in.
+	SubscriptionAssetNHB  = "NHB"
+	SubscriptionAssetZNHB = "ZNHB"
+
+	minSubscriptionPeriodSeconds = int64(3600)
+	maxSubscriptionPeriodSeconds = int64(366 * 24 * 3600)
+	maxSubscriptionPeriods       = 12
+	maxTierIDLength              = 64
+	maxTierNameLength            = 128
+)
+
+func normalizeSubscriptionAsset(asset string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(asset)) {
+	case "", SubscriptionAssetNHB:
+		return SubscriptionAssetNHB, nil
+	case SubscriptionAssetZNHB:
+		return SubscriptionAssetZNHB, nil
+	default:
+		return "", errInvalidTier
+	}
+}
+
+func sanitizeTierID(id string) (string, error) {
+	trimmed := strings.TrimSpace(id)
+	if trimmed == "" || len(trimmed) > maxTierIDLength || strings.ContainsAny(trimmed, "/ ") {
+		return "", errInvalidTier
+	}
+	return trimmed, nil
+}
+
+func assetBalance(acc *types.Account, asset string) **big.Int {
+	if asset == SubscriptionAssetZNHB {
+		return &acc.BalanceZNHB
+	}
+	return &acc.BalanceNHB
+}
+
+// ledgerAssetFields returns the pending and cumulative revenue counters the
+// subscription asset accrues into.
+func ledgerAssetFields(ledger *PayoutLedger, asset string) (pending **big.Int, total **big.Int) {
+	if asset == SubscriptionAssetZNHB {
+		return &ledger.PendingDistributionZNHB, &ledger.TotalSubscriptionsZNHB
+	}
+	return &ledger.PendingDistribution, &ledger.TotalSubscriptions
+}
+
+// SetSubscriptionTier creates or updates one of the creator's tiers. Edits
+// only affect new subscriptions: existing subscribers keep the price and
+// period they signed up for. Deactivating a tier stops new subscriptions and
+// renewals while paid-up subscribers keep access until their period ends.
+func (e *Engine) SetSubscriptionTier(creator [20]byte, tier *SubscriptionTier) (*SubscriptionTier, error) {
+	if e == nil || e.state == nil {
+		return nil, errNilState
+	}
+	if tier == nil {
+		return nil, errInvalidTier
+	}
+	id, err := sanitizeTierID(tier.ID)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(tier.Name)
+	if len(name) > maxTierNameLength || !utf8.ValidString(name) {
+		return nil, errInvalidTier
+	}
+	asset, err := normalizeSubscriptionAsset(tier.Asset)
+	if err != nil {
+		return nil, err
+	}
+	if tier.Price == nil || tier.Price.Sign() <= 0 {
+		return nil, errInvalidAmount
+	}
+	if tier.PeriodSeconds < minSubscriptionPeriodSeconds || tier.PeriodSeconds > maxSubscriptionPeriodSeconds {
+		return nil, errInvalidTier
+	}
+	stored := &SubscriptionTier{
+		Creator:       creator,
+		ID:            id,
+		Name:          name,
+		Asset:         asset,
+		Price:         new(big.Int).Set(tier.Price),
+		PeriodSeconds: tier.PeriodSeconds,
+		Active:        tier.Active,
+		UpdatedAt:     e.now(),
+	}
+	if err := e.state.CreatorTierPut(stored); err != nil {
+		return nil, err
+	}
+	e.emit(SubscriptionTierUpdatedEvent(hexAddr(creator), stored.ID, stored.Asset, stored.Price.String(), stored.PeriodSeconds, stored.Active))
+	return stored, nil
+}
+
+// SubscriptionTier returns a creator's tier by identifier.
+func (e *Engine) SubscriptionTier(creator [20]byte, id string) (*SubscriptionTier, error) {
+	if e == nil || e.state == nil {
+		return nil, errNilState
+	}
+	sanitized, err := sanitizeTierID(id)
+	if err != nil {
+		return nil, err
+	}
+	tier, ok, err := e.state.CreatorTierGet(creator, sanitized)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || tier == nil {
+		return nil, errTierNotFound
+	}
+	return tier, nil
+}
+
+// Subscription returns the fan's subscription to the creator, including
+// expired and cancelled ones.
+func (e *Engine) Subscription(creator [20]byte, fan [20]byte) (*Subscription, error) {
+	if e == nil || e.state == nil {
+		return nil, errNilState
+	}
+	subscription, ok, err := e.state.CreatorSubscriptionGet(creator, fan)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || subscription == nil {
+		return nil, errSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+// Subscribe charges the fan for the requested number of periods of the tier
+// and starts a subscription. The payment is held in the payout vault and
+// accrues to the creator's payout ledger. A fan holds at most one
+// subscription per creator; switching tiers requires cancelling first.
+func (e *Engine) Subscribe(fan [20]byte, creator [20]byte, tierID string, periods uint32) (*Subscription, error) {
+	if e == nil || e.state == nil {
+		return nil, errNilState
+	}
+	if periods == 0 || periods > maxSubscriptionPeriods {
+		return nil, errInvalidPeriods
+	}
+	tier, err := e.SubscriptionTier(creator, tierID)
+	if err != nil {
+		return nil, err
+	}
+	if !tier.Active {
+		return nil, errTierInactive
+	}
+	now := e.now()
+	if existing, ok, err := e.state.CreatorSubscriptionGet(creator, fan); err != nil {
+		return nil, err
+	} else if ok && existing.ActiveAt(now) {
+		return nil, errSubscriptionActive
+	}
+	amount := new(big.Int).Mul(tier.Price, big.NewInt(int64(periods)))
+	ledger, err := e.chargeSubscription(fan, creator, tier.Asset, amount)
+	if err != nil {
+		return nil, err
+	}
+	subscription := &Subscription{
+		Creator:       creator,
+		Fan:           fan,
+		TierID:        tier.ID,
+		Asset:         tier.Asset,
+		Price:         new(big.Int).Set(tier.Price),
+		PeriodSeconds: tier.PeriodSeconds,
+		StartedAt:     now,
+		PaidThrough:   now + tier.PeriodSeconds*int64(periods),
+	}
+	if err := e.state.CreatorSubscriptionPut(subscription); err != nil {
+		return nil, err
+	}
+	e.emit(SubscriptionStartedEvent(hexAddr(creator), hexAddr(fan), subscription.TierID, subscription.Asset, amount.String(), subscription.PaidThrough))
+	e.emit(CreatorPayoutAccruedEvent(hexAddr(creator), ledger.PendingDistribution.String(), ledger.TotalTips.String(), ledger.TotalStakingYield.String()))
+	return subscription, nil
+}
+
+// RenewSubscription pays for further periods at the subscription's original
+// price. Renewing before expiry extends the paid-through time; renewing a
+// lapsed subscription restarts it from now. Cancelled subscriptions and
+// subscriptions to deactivated tiers cannot be renewed.
+func (e *Engine) RenewSubscription(fan [20]byte, creator [20]byte, periods uint32) (*Subscription, error) {
+	if e == nil || e.state == nil {
+		return nil, errNilState
+	}
+	if periods == 0 || periods > maxSubscriptionPeriods {
+		return nil, errInvalidPeriods
+	}
+	subscription, err := e.Subscription(creator, fan)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Cancelled {
+		return nil, errSubscriptionNotFound
+	}
+	tier, err := e.SubscriptionTier(creator, subscription.TierID)
+	if err != nil {
+		return nil, err
+	}
+	if !tier.Active {
+		return nil, errTierInactive
+	}
+	now := e.now()
+	start := subscription.PaidThrough
+	if start < now {
+		start = now
+		subscription.StartedAt = now
+	}
+	amount := new(big.Int).Mul(subscription.Price, big.NewInt(int64(periods)))
+	ledger, err := e.chargeSubscription(fan, creator, subscription.Asset, amount)
+	if err != nil {
+		return nil, err
+	}
+	subscription.PaidThrough = start + subscription.PeriodSeconds*int64(periods)
+	if err := e.state.CreatorSubscriptionPut(subscription); err != nil {
+		return nil, err
+	}
+	e.emit(SubscriptionRenewedEvent(hexAddr(creator), hexAddr(fan), subscription.TierID, subscription.Asset, amount.String(), subscription.PaidThrough))
+	e.emit(CreatorPayoutAccruedEvent(hexAddr(creator), ledger.PendingDistribution.String(), ledger.TotalTips.String(), ledger.TotalStakingYield.String()))
+	return subscription, nil
+}
+
+// CancelSubscription ends an active subscription immediately and refunds the
+// unused time pro rata. The refund is paid from the creator's unclaimed
+// revenue in the payout vault, so it is capped at the pending balance for the
+// subscription asset when the creator has already claimed.
+func (e *Engine) CancelSubscription(fan [20]byte, creator [20]byte) (*Subscription, *big.Int, error) {
+	if e == nil || e.state == nil {
+		return nil, nil, errNilState
+	}
+	subscription, err := e.Subscription(creator, fan)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := e.now()
+	if !subscription.ActiveAt(now) {
+		return nil, nil, errSubscriptionNotFound
+	}
+	refund := big.NewInt(0)
+	if subscription.PeriodSeconds > 0 && subscription.Price != nil {
+		refund.Mul(subscription.Price, big.NewInt(subscription.PaidThrough-now))
+		refund.Quo(refund, big.NewInt(subscription.PeriodSeconds))
+	}
+	ledger, ok, err := e.state.CreatorPayoutLedgerGet(creator)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok || ledger == nil {
+		ledger = newLedger(creator)
+	} else {
+		ledger = ensureLedgerFields(ledger)
+	}
+	pending, total := ledgerAssetFields(ledger, subscription.Asset)
+	if refund.Cmp(*pending) > 0 {
+		refund.Set(*pending)
+	}
+	if refund.Sign() > 0 {
+		if isZeroAddress(e.payoutVault) {
+			return nil, nil, errPayoutVaultNotSet
+		}
+		vaultAccount, err := e.state.GetAccount(e.payoutVault[:])
+		if err != nil {
+			return nil, nil, err
+		}
+		vaultAccount = ensureAccount(vaultAccount)
+		vaultBalance := assetBalance(vaultAccount, subscription.Asset)
+		if (*vaultBalance).Cmp(refund) < 0 {
+			return nil, nil, errPayoutVaultUnderfunded
+		}
+		fanAccount, err := e.state.GetAccount(fan[:])
+		if err != nil {
+			return nil, nil, err
+		}
+		fanAccount = ensureAccount(fanAccount)
+		fanBalance := assetBalance(fanAccount, subscription.Asset)
+		*vaultBalance = new(big.Int).Sub(*vaultBalance, refund)
+		*fanBalance = new(big.Int).Add(*fanBalance, refund)
+		if err := e.state.PutAccount(e.payoutVault[:], vaultAccount); err != nil {
+			return nil, nil, err
+		}
+		if err := e.state.PutAccount(fan[:], fanAccount); err != nil {
+			return nil, nil, err
+		}
+		*pending = new(big.Int).Sub(*pending, refund)
+		*total = new(big.Int).Sub(*total, refund)
+		if (*total).Sign() < 0 {
+			*total = big.NewInt(0)
+		}
+		if err := e.state.CreatorPayoutLedgerPut(ledger); err != nil {
+			return nil, nil, err
+		}
+	}
+	subscription.Cancelled = true
+	subscription.CancelledAt = now
+	subscription.PaidThrough = now
+	if err := e.state.CreatorSubscriptionPut(subscription); err != nil {
+		return nil, nil, err
+	}
+	e.emit(SubscriptionCancelledEvent(hexAddr(creator), hexAddr(fan), subscription.TierID, subscription.Asset, refund.String()))
+	if refund.Sign() > 0 {
+		e.emit(CreatorPayoutAccruedEvent(hexAddr(creator), ledger.PendingDistribution.String(), ledger.TotalTips.String(), ledger.TotalStakingYield.String()))
+	}
+	return subscription, refund, nil
+}
+
+// chargeSubscription moves a subscription payment from the fan into the
+// payout vault and accrues it to the creator's payout ledger.
+func (e *Engine) chargeSubscription(fan [20]byte, creator [20]byte, asset string, amount *big.Int) (*PayoutLedger, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, errInvalidAmount
+	}
+	if isZeroAddress(e.payoutVault) {
+		return nil, errPayoutVaultNotSet
+	}
+	fanAccount, err := e.state.GetAccount(fan[:])
+	if err != nil {
+		return nil, err
+	}
+	fanAccount = ensureAccount(fanAccount)
+	fanBalance := assetBalance(fanAccount, asset)
+	if (*fanBalance).Cmp(amount) < 0 {
+		return nil, errInsufficientFunds
+	}
+	ledger, ok, err := e.state.CreatorPayoutLedgerGet(creator)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || ledger == nil {
+		ledger = newLedger(creator)
+	} else {
+		ledger = ensureLedgerFields(ledger)
+	}
+	*fanBalance = new(big.Int).Sub(*fanBalance, amount)
+	if err := e.state.PutAccount(fan[:], fanAccount); err != nil {
+		return nil, err
+	}
+	vaultAccount, err := e.state.GetAccount(e.payoutVault[:])
+	if err != nil {
+		return nil, err
+	}
+	vaultAccount = ensureAccount(vaultAccount)
+	vaultBalance := assetBalance(vaultAccount, asset)
+	*vaultBalance = new(big.Int).Add(*vaultBalance, amount)
+	if err := e.state.PutAccount(e.payoutVault[:], vaultAccount); err != nil {
+		return nil, err
+	}
+	pending, total := ledgerAssetFields(ledger, asset)
+	*pending = new(big.Int).Add(*pending, amount)
+	*total = new(big.Int).Add(*total, amount)
+	if err := e.state.CreatorPayoutLedgerPut(ledger); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
@@ -0,0 +1,199 @@
+package creator
+
+import (
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func newSubscriptionEngine(t *testing.T, now *int64) (*Engine, *mockState, [20]byte, [20]byte, [20]byte) {
+	t.Helper()
+	state := newMockState()
+	engine := NewEngine()
+	engine.SetState(state)
+	engine.SetNowFunc(func() int64 { return *now })
+	creatorAddr, fan, vault := addr(0x10), addr(0x20), addr(0x30)
+	engine.SetPayoutVault(vault)
+	state.setAccount(fan, 1_000)
+	if _, err := engine.SetSubscriptionTier(creatorAddr, &SubscriptionTier{ID: "gold", Name: "Gold", Price: big.NewInt(300), PeriodSeconds: 3_600, Active: true}); err != nil {
+		t.Fatalf("set tier: %v", err)
+	}
+	return engine, state, creatorAddr, fan, vault
+}
+
+func TestSubscribeRenewAndCancelWithProratedRefund(t *testing.T) {
+	now := int64(1_000)
+	engine, state, creatorAddr, fan, vault := newSubscriptionEngine(t, &now)
+
+	sub, err := engine.Subscribe(fan, creatorAddr, "gold", 1)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if sub.PaidThrough != 4_600 {
+		t.Fatalf("unexpected paid-through: %d", sub.PaidThrough)
+	}
+	if _, err := engine.Subscribe(fan, creatorAddr, "gold", 1); !errors.Is(err, errSubscriptionActive) {
+		t.Fatalf("expected duplicate subscription to fail, got %v", err)
+	}
+
+	// Price changes only apply to new subscribers.
+	if _, err := engine.SetSubscriptionTier(creatorAddr, &SubscriptionTier{ID: "gold", Name: "Gold", Price: big.NewInt(900), PeriodSeconds: 3_600, Active: true}); err != nil {
+		t.Fatalf("update tier: %v", err)
+	}
+	sub, err = engine.RenewSubscription(fan, creatorAddr, 1)
+	if err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if sub.PaidThrough != 8_200 {
+		t.Fatalf("expected renewal to extend the paid period, got %d", sub.PaidThrough)
+	}
+	ledger, _ := engine.Payouts(creatorAddr)
+	if ledger.PendingDistribution.Int64() != 600 || ledger.TotalSubscriptions.Int64() != 600 {
+		t.Fatalf("unexpected ledger after renewal: %+v", ledger)
+	}
+
+	// Half of the second period remains unused.
+	now = 6_400
+	_, refund, err := engine.CancelSubscription(fan, creatorAddr)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if refund.Int64() != 150 {
+		t.Fatalf("expected a 150 refund, got %s", refund)
+	}
+	if got := state.account(fan).BalanceNHB.Int64(); got != 550 {
+		t.Fatalf("unexpected fan balance: %d", got)
+	}
+	if got := state.account(vault).BalanceNHB.Int64(); got != 450 {
+		t.Fatalf("unexpected vault balance: %d", got)
+	}
+	ledger, _ = engine.Payouts(creatorAddr)
+	if ledger.PendingDistribution.Int64() != 450 || ledger.TotalSubscriptions.Int64() != 450 {
+		t.Fatalf("unexpected ledger after cancellation: %+v", ledger)
+	}
+	if _, err := engine.AccessClaims(fan, creatorAddr, ""); !errors.Is(err, errNoAccess) {
+		t.Fatalf("expected cancelled subscription to lose access, got %v", err)
+	}
+	if _, err := engine.RenewSubscription(fan, creatorAddr, 1); !errors.Is(err, errSubscriptionNotFound) {
+		t.Fatalf("expected cancelled subscription renewal to fail, got %v", err)
+	}
+}
+
+func TestCancelRefundCappedAtUnclaimedRevenue(t *testing.T) {
+	now := int64(0)
+	engine, state, creatorAddr, fan, _ := newSubscriptionEngine(t, &now)
+	if _, err := engine.Subscribe(fan, creatorAddr, "gold", 2); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, paid, err := engine.ClaimPayouts(creatorAddr); err != nil || paid.Int64() != 600 {
+		t.Fatalf("claim: paid=%v err=%v", paid, err)
+	}
+	now = 3_600
+	_, refund, err := engine.CancelSubscription(fan, creatorAddr)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if refund.Sign() != 0 {
+		t.Fatalf("expected no refund once revenue is claimed, got %s", refund)
+	}
+	if got := state.account(fan).BalanceNHB.Int64(); got != 400 {
+		t.Fatalf("unexpected fan balance: %d", got)
+	}
+}
+
+func TestZNHBSubscriptionRevenueIsClaimed(t *testing.T) {
+	now := int64(0)
+	engine, state, creatorAddr, fan, vault := newSubscriptionEngine(t, &now)
+	if _, err := engine.SetSubscriptionTier(creatorAddr, &SubscriptionTier{ID: "zap", Asset: "znhb", Price: big.NewInt(40), PeriodSeconds: 7_200, Active: true}); err != nil {
+		t.Fatalf("set tier: %v", err)
+	}
+	if _, err := engine.Subscribe(fan, creatorAddr, "zap", 1); !errors.Is(err, errInsufficientFunds) {
+		t.Fatalf("expected ZNHB balance to be required, got %v", err)
+	}
+	acc := state.account(fan)
+	acc.BalanceZNHB = big.NewInt(100)
+	state.accounts[string(fan[:])] = acc
+	if _, err := engine.Subscribe(fan, creatorAddr, "zap", 1); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, _, err := engine.ClaimPayouts(creatorAddr); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if got := state.account(creatorAddr).BalanceZNHB.Int64(); got != 40 {
+		t.Fatalf("unexpected creator ZNHB balance: %d", got)
+	}
+	if got := state.account(vault).BalanceZNHB.Int64(); got != 0 {
+		t.Fatalf("unexpected vault ZNHB balance: %d", got)
+	}
+	ledger, _ := engine.Payouts(creatorAddr)
+	if ledger.PendingDistributionZNHB.Sign() != 0 || ledger.TotalSubscriptionsZNHB.Int64() != 40 {
+		t.Fatalf("unexpected ledger: %+v", ledger)
+	}
+}
+
+func TestAccessTokenRoundTrip(t *testing.T) {
+	now := int64(1_000)
+	engine, _, creatorAddr, fan, _ := newSubscriptionEngine(t, &now)
+	if _, err := engine.AccessClaims(fan, creatorAddr, ""); !errors.Is(err, errNoAccess) {
+		t.Fatalf("expected no access before subscribing, got %v", err)
+	}
+	if _, err := engine.Subscribe(fan, creatorAddr, "gold", 1); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := engine.AccessClaims(fan, creatorAddr, "silver"); !errors.Is(err, errNoAccess) {
+		t.Fatalf("expected tier mismatch to deny access, got %v", err)
+	}
+	// Close to expiry the token is capped at the paid-through time.
+	now = 4_500
+	claims, err := engine.AccessClaims(fan, creatorAddr, "gold")
+	if err != nil {
+		t.Fatalf("claims: %v", err)
+	}
+	if claims.ExpiresAt != 4_600 {
+		t.Fatalf("expected expiry capped at paid-through, got %d", claims.ExpiresAt)
+	}
+
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	token, err := SignAccessToken(claims, key)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	parsed, issuer, err := ParseAccessToken(token)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if *parsed != *claims {
+		t.Fatalf("claims mismatch: %+v != %+v", parsed, claims)
+	}
+	var want [20]byte
+	copy(want[:], ethcrypto.PubkeyToAddress(key.PublicKey).Bytes())
+	if issuer != want {
+		t.Fatalf("unexpected issuer %x", issuer)
+	}
+
+	// A signature over the bare claims hash is not accepted as a token.
+	payload, _ := base64.RawURLEncoding.DecodeString(strings.SplitN(token, ".", 2)[0])
+	bare, err := ethcrypto.Sign(ethcrypto.Keccak256(payload), key)
+	if err != nil {
+		t.Fatalf("sign bare: %v", err)
+	}
+	undomained := strings.SplitN(token, ".", 2)[0] + "." + base64.RawURLEncoding.EncodeToString(bare)
+	if _, issuer, err := ParseAccessToken(undomained); err == nil && issuer == want {
+		t.Fatalf("expected a signature without the token domain to be rejected")
+	}
+
+	// Extending the expiry without re-signing changes the recovered issuer.
+	claims.ExpiresAt += 3_600
+	forged, _ := SignAccessToken(claims, key)
+	tampered := strings.SplitN(forged, ".", 2)[0] + "." + strings.SplitN(token, ".", 2)[1]
+	if _, issuer, err := ParseAccessToken(tampered); err == nil && issuer == want {
+		t.Fatalf("expected tampered token to fail verification")
+	}
+}
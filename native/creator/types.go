@@ -43,6 +43,13 @@ type PayoutLedger struct {
 	TotalAssets         *big.Int `json:"totalAssets"`
 	TotalShares         *big.Int `json:"totalShares"`
 	IndexRay            *big.Int `json:"indexRay"`
+	// TotalSubscriptions and TotalSubscriptionsZNHB accumulate net
+	// subscription revenue per asset. NHB revenue is added to
+	// PendingDistribution; ZNHB revenue is tracked separately in
+	// PendingDistributionZNHB.
+	TotalSubscriptions      *big.Int `json:"totalSubscriptions"`
+	TotalSubscriptionsZNHB  *big.Int `json:"totalSubscriptionsZnhb"`
+	PendingDistributionZNHB *big.Int `json:"pendingDistributionZnhb"`
 }
 
 // Clone returns a deep copy of the payout ledger.
@@ -69,9 +76,53 @@ func (p *PayoutLedger) Clone() *PayoutLedger {
 	if p.IndexRay != nil {
 		clone.IndexRay = new(big.Int).Set(p.IndexRay)
 	}
+	if p.TotalSubscriptions != nil {
+		clone.TotalSubscriptions = new(big.Int).Set(p.TotalSubscriptions)
+	}
+	if p.TotalSubscriptionsZNHB != nil {
+		clone.TotalSubscriptionsZNHB = new(big.Int).Set(p.TotalSubscriptionsZNHB)
+	}
+	if p.PendingDistributionZNHB != nil {
+		clone.PendingDistributionZNHB = new(big.Int).Set(p.PendingDistributionZNHB)
+	}
 	return &clone
 }
 
+// SubscriptionTier is a creator-defined recurring access level. Price is
+// charged in Asset once per PeriodSeconds.
+type SubscriptionTier struct {
+	Creator       [20]byte `json:"creator"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Asset         string   `json:"asset"`
+	Price         *big.Int `json:"price"`
+	PeriodSeconds int64    `json:"periodSeconds"`
+	Active        bool     `json:"active"`
+	UpdatedAt     int64    `json:"updatedAt"`
+}
+
+// Subscription records a fan's paid access to one of a creator's tiers. The
+// price, asset and period are fixed when the fan subscribes so renewals and
+// refunds are unaffected by later tier edits.
+type Subscription struct {
+	Creator       [20]byte `json:"creator"`
+	Fan           [20]byte `json:"fan"`
+	TierID        string   `json:"tierId"`
+	Asset         string   `json:"asset"`
+	Price         *big.Int `json:"price"`
+	PeriodSeconds int64    `json:"periodSeconds"`
+	StartedAt     int64    `json:"startedAt"`
+	PaidThrough   int64    `json:"paidThrough"`
+	Cancelled     bool     `json:"cancelled"`
+	CancelledAt   int64    `json:"cancelledAt"`
+}
+
+// ActiveAt reports whether the subscription grants access at the supplied
+// unix timestamp.
+func (s *Subscription) ActiveAt(now int64) bool {
+	return s != nil && !s.Cancelled && now < s.PaidThrough
+}
+
 // StakeRateLimitWindow captures the staking activity tracked for a fan within a
 // rolling window.
 type StakeRateLimitWindow struct {
@@ -24,6 +24,7 @@ var (
 	errEscrowNotFound = errors.New("escrow engine: escrow not found")
 	errRealmNotFound  = errors.New("escrow engine: realm not found")
 	errRealmConfig    = errors.New("escrow engine: invalid realm configuration")
+	errReputationLow  = errors.New("escrow engine: payee reputation below realm minimum")
 )
 
 const moduleName = "escrow"
@@ -37,6 +38,8 @@ type engineState interface {
 	EscrowVaultAddress(token string) ([20]byte, error)
 	GetAccount(addr []byte) (*types.Account, error)
 	PutAccount(addr []byte, account *types.Account) error
+	TokenExists(symbol string) bool
+	AssetTransfer(from, to []byte, symbol string, amount *big.Int) error
 	EscrowRealmPut(*EscrowRealm) error
 	EscrowRealmGet(id string) (*EscrowRealm, bool, error)
 	EscrowFrozenPolicyPut(id [32]byte, policy *FrozenArb) error
@@ -67,13 +70,20 @@ type Engine struct {
 	feeTreasury [20]byte
 	nowFn       func() int64
 	pauses      nativecommon.PauseView
+	reputation  ReputationScorer
 }
 
+// ReputationScorer reports the current reputation score of an address. It is
+// consulted when a realm requires a minimum score from the payee.
+type ReputationScorer func(addr [20]byte) (uint32, error)
+
 type decisionEnvelope struct {
 	EscrowID    string `json:"escrowId"`
 	Outcome     string `json:"outcome"`
 	Metadata    string `json:"metadata,omitempty"`
 	PolicyNonce uint64 `json:"policyNonce"`
+	PayeeBps    uint32 `json:"payeeBps,omitempty"`
+	PayerBps    uint32 `json:"payerBps,omitempty"`
 }
 
 // NewEngine creates an escrow engine with a no-op emitter. Callers can override
@@ -99,6 +109,15 @@ func (e *Engine) SetPauses(p nativecommon.PauseView) {
 	e.pauses = p
 }
 
+// SetReputationScorer wires the lookup used to enforce realm reputation
+// minimums. Without a scorer, realms that demand a minimum reject new escrows.
+func (e *Engine) SetReputationScorer(scorer ReputationScorer) {
+	if e == nil {
+		return
+	}
+	e.reputation = scorer
+}
+
 // SetNowFunc overrides the time source used by the engine. Primarily intended
 // for tests to provide deterministic timestamps.
 func (e *Engine) SetNowFunc(now func() int64) {
@@ -191,13 +210,16 @@ func (e *Engine) transferToken(from, to [20]byte, token string, amount *big.Int)
 	if amt.Sign() == 0 {
 		return nil
 	}
-	normalized, err := NormalizeToken(token)
+	normalized, err := NormalizeAsset(token)
 	if err != nil {
 		return err
 	}
 	if amt.Sign() < 0 {
 		return fmt.Errorf("escrow: negative transfer amount")
 	}
+	if !isNativeToken(normalized) {
+		return e.state.AssetTransfer(from[:], to[:], normalized, amt)
+	}
 	fromAcc, err := e.state.GetAccount(from[:])
 	if err != nil {
 		return err
@@ -243,34 +265,99 @@ func (e *Engine) ensureTreasuryConfigured() error {
 	return nil
 }
 
-func (e *Engine) computeDisputePayouts(esc *Escrow) (*big.Int, *big.Int, *big.Int, [20]byte, error) {
-	var zeroAddr [20]byte
+// disputeShare is one share of a disputed escrow split into the amount paid
+// to the party and the fees taken from it.
+type disputeShare struct {
+	payout        *big.Int
+	fee           *big.Int
+	realmFee      *big.Int
+	arbitratorFee *big.Int
+	realmPayee    [20]byte
+}
+
+func (e *Engine) computeDisputePayouts(esc *Escrow, arbitrated bool) (*disputeShare, error) {
 	if esc == nil {
-		return nil, nil, nil, zeroAddr, fmt.Errorf("escrow: nil escrow")
+		return nil, fmt.Errorf("escrow: nil escrow")
 	}
 	total := cloneBigInt(esc.Amount)
 	if total.Sign() <= 0 {
-		return nil, nil, nil, zeroAddr, fmt.Errorf("escrow: amount must be positive")
+		return nil, fmt.Errorf("escrow: amount must be positive")
+	}
+	return computeSharePayouts(esc, total, arbitrated)
+}
+
+// releaseShare computes the payee share of a release. Undisputed releases only
+// pay the escrow fee.
+func (e *Engine) releaseShare(esc *Escrow, total *big.Int, arbitrated bool) (*disputeShare, error) {
+	if esc.Status == EscrowDisputed {
+		return e.computeDisputePayouts(esc, arbitrated)
 	}
 	fee := calculateFee(total, esc.FeeBps)
-	var realmFee *big.Int
-	var recipient [20]byte
+	return &disputeShare{payout: new(big.Int).Sub(total, fee), fee: fee}, nil
+}
+
+// computeSharePayouts applies the escrow fee and the frozen realm fee schedule
+// to one share of a disputed escrow. The arbitrator fee is only charged when
+// the share is settled by a signed arbitrator decision.
+func computeSharePayouts(esc *Escrow, total *big.Int, arbitrated bool) (*disputeShare, error) {
+	share := &disputeShare{
+		fee:           calculateFee(total, esc.FeeBps),
+		realmFee:      big.NewInt(0),
+		arbitratorFee: big.NewInt(0),
+	}
 	if esc.FrozenArb != nil && esc.FrozenArb.FeeSchedule != nil {
 		schedule := esc.FrozenArb.FeeSchedule
-		realmFee = calculateFee(total, schedule.FeeBps)
-		recipient = schedule.Recipient
-		if realmFee.Sign() > 0 && recipient == ([20]byte{}) {
-			return nil, nil, nil, zeroAddr, fmt.Errorf("escrow: realm fee recipient missing")
+		share.realmFee = calculateFee(total, schedule.FeeBps)
+		share.realmPayee = schedule.Recipient
+		if share.realmFee.Sign() > 0 && share.realmPayee == ([20]byte{}) {
+			return nil, fmt.Errorf("escrow: realm fee recipient missing")
+		}
+		if arbitrated {
+			share.arbitratorFee = calculateFee(total, schedule.ArbitratorFeeBps)
 		}
-	} else {
-		realmFee = big.NewInt(0)
 	}
-	payout := new(big.Int).Sub(total, fee)
-	payout.Sub(payout, realmFee)
-	if payout.Sign() < 0 {
-		return nil, nil, nil, zeroAddr, fmt.Errorf("escrow: dispute fees exceed escrow amount")
+	share.payout = new(big.Int).Sub(total, share.fee)
+	share.payout.Sub(share.payout, share.realmFee)
+	share.payout.Sub(share.payout, share.arbitratorFee)
+	if share.payout.Sign() < 0 {
+		return nil, fmt.Errorf("escrow: dispute fees exceed escrow amount")
 	}
-	return payout, fee, realmFee, recipient, nil
+	return share, nil
+}
+
+// payDisputeFees moves the escrow fee, realm fee and arbitrator fee of a
+// settled escrow out of the vault. The arbitrator fee is shared by signers.
+func (e *Engine) payDisputeFees(vault [20]byte, esc *Escrow, share *disputeShare, signers [][20]byte) error {
+	if share.fee != nil && share.fee.Sign() > 0 {
+		if err := e.ensureTreasuryConfigured(); err != nil {
+			return err
+		}
+		if err := e.transferToken(vault, e.feeTreasury, esc.Token, share.fee); err != nil {
+			return err
+		}
+	}
+	if share.realmFee != nil && share.realmFee.Sign() > 0 {
+		if share.realmPayee == ([20]byte{}) {
+			return fmt.Errorf("escrow: realm fee recipient missing")
+		}
+		if err := e.transferToken(vault, share.realmPayee, esc.Token, share.realmFee); err != nil {
+			return err
+		}
+	}
+	if share.arbitratorFee == nil || share.arbitratorFee.Sign() <= 0 {
+		return nil
+	}
+	payouts := SplitArbitratorFee(share.arbitratorFee, signers)
+	if len(payouts) == 0 {
+		return fmt.Errorf("escrow: arbitrator fee requires decision signers")
+	}
+	for _, payout := range payouts {
+		if err := e.transferToken(vault, payout.Arbitrator, esc.Token, payout.Amount); err != nil {
+			return err
+		}
+	}
+	e.emit(NewArbitratorFeePaidEvent(esc, share.arbitratorFee, payouts))
+	return nil
 }
 
 func defaultRealmSchemeMap() map[ArbitrationScheme]struct{} {
@@ -440,14 +527,23 @@ func (e *Engine) prepareFrozenPolicy(realmID string, now int64) (*EscrowRealm, *
 		return nil, nil, errRealmConfig
 	}
 	frozen := &FrozenArb{
-		RealmID:      sanitizedRealm.ID,
-		RealmVersion: sanitizedRealm.Version,
-		PolicyNonce:  sanitizedRealm.NextPolicyNonce,
-		Scheme:       sanitizedSet.Scheme,
-		Threshold:    sanitizedSet.Threshold,
-		Members:      append([][20]byte(nil), sanitizedSet.Members...),
-		FrozenAt:     now,
-		FeeSchedule:  sanitizedRealm.FeeSchedule.Clone(),
+		RealmID:               sanitizedRealm.ID,
+		RealmVersion:          sanitizedRealm.Version,
+		PolicyNonce:           sanitizedRealm.NextPolicyNonce,
+		Scheme:                sanitizedSet.Scheme,
+		Threshold:             sanitizedSet.Threshold,
+		Members:               append([][20]byte(nil), sanitizedSet.Members...),
+		FrozenAt:              now,
+		FeeSchedule:           sanitizedRealm.FeeSchedule.Clone(),
+		EvidenceWindowSeconds: sanitizedRealm.EvidenceWindowSeconds,
+		ArbitrationSLASeconds: sanitizedRealm.ArbitrationSLASeconds,
+	}
+	if sanitizedRealm.FallbackArbitrators != nil {
+		fallback, err := e.validateArbitratorSetBounds(sanitizedRealm.FallbackArbitrators)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fallback arbitrators: %w", err)
+		}
+		frozen.Fallback = fallback
 	}
 	if sanitizedRealm.Metadata != nil {
 		frozen.Metadata = sanitizedRealm.Metadata.Clone()
@@ -457,6 +553,47 @@ func (e *Engine) prepareFrozenPolicy(realmID string, now int64) (*EscrowRealm, *
 	return sanitizedRealm, frozen, nil
 }
 
+// FreezeRealmPolicy snapshots the realm's current arbitrator policy for use by
+// another module, consuming a policy nonce exactly like escrow creation does.
+func (e *Engine) FreezeRealmPolicy(realmID string) (*FrozenArb, error) {
+	if e == nil || e.state == nil {
+		return nil, errNilState
+	}
+	if err := nativecommon.Guard(e.pauses, moduleName); err != nil {
+		return nil, err
+	}
+	realmUpdate, frozen, err := e.prepareFrozenPolicy(realmID, e.now())
+	if err != nil {
+		return nil, err
+	}
+	if frozen == nil {
+		return nil, errRealmNotFound
+	}
+	if err := e.state.EscrowRealmPut(realmUpdate); err != nil {
+		return nil, err
+	}
+	return frozen, nil
+}
+
+// checkPayeeReputation enforces the realm's minimum reputation score on the
+// payee of a new escrow.
+func (e *Engine) checkPayeeReputation(realm *EscrowRealm, payee [20]byte) error {
+	if realm == nil || realm.MinReputationScore == 0 {
+		return nil
+	}
+	if e.reputation == nil {
+		return errReputationLow
+	}
+	score, err := e.reputation(payee)
+	if err != nil {
+		return err
+	}
+	if score < realm.MinReputationScore {
+		return fmt.Errorf("%w: have %d, need %d", errReputationLow, score, realm.MinReputationScore)
+	}
+	return nil
+}
+
 // CreateRealm persists a new arbitration realm using the configured governance
 // bounds for validation.
 func (e *Engine) CreateRealm(realm *EscrowRealm) (*EscrowRealm, error) {
@@ -482,20 +619,28 @@ func (e *Engine) CreateRealm(realm *EscrowRealm) (*EscrowRealm, error) {
 	if err != nil {
 		return nil, err
 	}
+	fallback, err := e.validateFallbackSet(realm.FallbackArbitrators)
+	if err != nil {
+		return nil, err
+	}
 	now := e.now()
 	var schedule *RealmFeeSchedule
 	if realm.FeeSchedule != nil {
 		schedule = realm.FeeSchedule.Clone()
 	}
 	candidate := &EscrowRealm{
-		ID:              trimmed,
-		Version:         1,
-		NextPolicyNonce: 1,
-		CreatedAt:       now,
-		UpdatedAt:       now,
-		Arbitrators:     sanitizedSet,
-		FeeSchedule:     schedule,
-		Metadata:        realm.Metadata.Clone(),
+		ID:                    trimmed,
+		Version:               1,
+		NextPolicyNonce:       1,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+		Arbitrators:           sanitizedSet,
+		FeeSchedule:           schedule,
+		EvidenceWindowSeconds: realm.EvidenceWindowSeconds,
+		ArbitrationSLASeconds: realm.ArbitrationSLASeconds,
+		FallbackArbitrators:   fallback,
+		MinReputationScore:    realm.MinReputationScore,
+		Metadata:              realm.Metadata.Clone(),
 	}
 	sanitizedRealm, err := SanitizeEscrowRealm(candidate)
 	if err != nil {
@@ -539,8 +684,16 @@ func (e *Engine) UpdateRealm(realm *EscrowRealm) (*EscrowRealm, error) {
 	if err != nil {
 		return nil, err
 	}
+	fallback, err := e.validateFallbackSet(realm.FallbackArbitrators)
+	if err != nil {
+		return nil, err
+	}
 	sanitizedCurrent.Version++
 	sanitizedCurrent.Arbitrators = sanitizedSet
+	sanitizedCurrent.EvidenceWindowSeconds = realm.EvidenceWindowSeconds
+	sanitizedCurrent.ArbitrationSLASeconds = realm.ArbitrationSLASeconds
+	sanitizedCurrent.FallbackArbitrators = fallback
+	sanitizedCurrent.MinReputationScore = realm.MinReputationScore
 	if realm.FeeSchedule != nil {
 		sanitizedCurrent.FeeSchedule = realm.FeeSchedule.Clone()
 	} else {
@@ -565,6 +718,15 @@ func (e *Engine) UpdateRealm(realm *EscrowRealm) (*EscrowRealm, error) {
 	return sanitizedRealm.Clone(), nil
 }
 
+// validateFallbackSet applies the committee bounds to an optional fallback
+// arbitrator set. A nil set is returned unchanged.
+func (e *Engine) validateFallbackSet(set *ArbitratorSet) (*ArbitratorSet, error) {
+	if set == nil {
+		return nil, nil
+	}
+	return e.validateArbitratorSetBounds(set)
+}
+
 // GetRealm resolves the latest definition for the provided realm identifier.
 func (e *Engine) GetRealm(id string) (*EscrowRealm, bool, error) {
 	if e == nil || e.state == nil {
@@ -601,10 +763,13 @@ func (e *Engine) Create(payer, payee [20]byte, token string, amount *big.Int, fe
 	if nonce == 0 {
 		return nil, fmt.Errorf("escrow: nonce must be positive")
 	}
-	normalizedToken, err := NormalizeToken(token)
+	normalizedToken, err := NormalizeAsset(token)
 	if err != nil {
 		return nil, err
 	}
+	if !isNativeToken(normalizedToken) && !e.state.TokenExists(normalizedToken) {
+		return nil, fmt.Errorf("unsupported escrow token: %s", token)
+	}
 	amt := cloneBigInt(amount)
 	if amt.Sign() <= 0 {
 		return nil, fmt.Errorf("escrow: amount must be positive")
@@ -641,6 +806,9 @@ func (e *Engine) Create(payer, payee [20]byte, token string, amount *big.Int, fe
 		if err != nil {
 			return nil, err
 		}
+		if err := e.checkPayeeReputation(realmUpdate, payee); err != nil {
+			return nil, err
+		}
 	}
 	esc := &Escrow{
 		ID:        id,
@@ -747,43 +915,17 @@ func (e *Engine) Release(id [32]byte, caller [20]byte) error {
 	if total.Sign() <= 0 {
 		return fmt.Errorf("escrow: amount must be positive")
 	}
-	var (
-		payout     *big.Int
-		fee        *big.Int
-		realmFee   *big.Int
-		realmPayee [20]byte
-	)
-	if esc.Status == EscrowDisputed {
-		var err error
-		payout, fee, realmFee, realmPayee, err = e.computeDisputePayouts(esc)
-		if err != nil {
-			return err
-		}
-	} else {
-		fee = calculateFee(total, esc.FeeBps)
-		payout = new(big.Int).Sub(total, fee)
-		realmFee = big.NewInt(0)
-	}
-	if payout.Sign() > 0 {
-		if err := e.transferToken(vault, esc.Payee, esc.Token, payout); err != nil {
-			return err
-		}
+	share, err := e.releaseShare(esc, total, false)
+	if err != nil {
+		return err
 	}
-	if fee.Sign() > 0 {
-		if err := e.ensureTreasuryConfigured(); err != nil {
-			return err
-		}
-		if err := e.transferToken(vault, e.feeTreasury, esc.Token, fee); err != nil {
+	if share.payout.Sign() > 0 {
+		if err := e.transferToken(vault, esc.Payee, esc.Token, share.payout); err != nil {
 			return err
 		}
 	}
-	if realmFee != nil && realmFee.Sign() > 0 {
-		if realmPayee == ([20]byte{}) {
-			return fmt.Errorf("escrow: realm fee recipient missing")
-		}
-		if err := e.transferToken(vault, realmPayee, esc.Token, realmFee); err != nil {
-			return err
-		}
+	if err := e.payDisputeFees(vault, esc, share, nil); err != nil {
+		return err
 	}
 	if err := e.state.EscrowDebit(id, esc.Token, total); err != nil {
 		return err
@@ -874,6 +1016,7 @@ func (e *Engine) Dispute(id [32]byte, caller [20]byte, reason string) error {
 		return fmt.Errorf("escrow: unauthorized dispute caller")
 	}
 	esc.Status = EscrowDisputed
+	esc.DisputedAt = e.now()
 	if trimmedReason != "" {
 		esc.DisputeReason = trimmedReason
 	}
@@ -884,54 +1027,154 @@ func (e *Engine) Dispute(id [32]byte, caller [20]byte, reason string) error {
 	return nil
 }
 
-func parseDecisionPayload(id [32]byte, frozen *FrozenArb, payload []byte) (DecisionOutcome, [32]byte, [32]byte, error) {
+// SubmitEvidence attaches an evidence hash and optional URI to a disputed
+// escrow. Only the payer or payee may submit, and only within the evidence
+// window of the frozen policy when one is configured.
+func (e *Engine) SubmitEvidence(id [32]byte, caller [20]byte, hash [32]byte, uri string) error {
+	if err := nativecommon.Guard(e.pauses, moduleName); err != nil {
+		return err
+	}
+	esc, err := e.loadEscrow(id)
+	if err != nil {
+		return err
+	}
+	if esc.Status != EscrowDisputed {
+		return fmt.Errorf("escrow: evidence requires a disputed escrow")
+	}
+	if caller != esc.Payer && caller != esc.Payee {
+		return fmt.Errorf("escrow: unauthorized evidence submitter")
+	}
+	if hash == ([32]byte{}) {
+		return fmt.Errorf("escrow: evidence hash required")
+	}
+	trimmedURI := strings.TrimSpace(uri)
+	if len(trimmedURI) > MaxEvidenceURILength {
+		return fmt.Errorf("escrow: evidence uri exceeds %d bytes", MaxEvidenceURILength)
+	}
+	now := e.now()
+	if esc.FrozenArb != nil && esc.FrozenArb.EvidenceWindowSeconds > 0 {
+		if now > esc.DisputedAt+esc.FrozenArb.EvidenceWindowSeconds {
+			return fmt.Errorf("escrow: evidence window closed")
+		}
+	}
+	for _, existing := range esc.Evidence {
+		if existing.Hash == hash {
+			return nil
+		}
+	}
+	if len(esc.Evidence) >= MaxEscrowEvidence {
+		return fmt.Errorf("escrow: evidence limit of %d reached", MaxEscrowEvidence)
+	}
+	evidence := EscrowEvidence{Submitter: caller, Hash: hash, URI: trimmedURI, SubmittedAt: now}
+	esc.Evidence = append(esc.Evidence, evidence)
+	if err := e.storeEscrow(esc); err != nil {
+		return err
+	}
+	e.emit(NewEvidenceSubmittedEvent(esc, evidence))
+	return nil
+}
+
+// EscalateDispute hands a dispute to the fallback arbitrators once the frozen
+// arbitration SLA has elapsed without a decision. Anyone may trigger the
+// escalation and repeated calls are no-ops.
+func (e *Engine) EscalateDispute(id [32]byte) error {
+	if err := nativecommon.Guard(e.pauses, moduleName); err != nil {
+		return err
+	}
+	esc, err := e.loadEscrow(id)
+	if err != nil {
+		return err
+	}
+	if esc.Status != EscrowDisputed {
+		return fmt.Errorf("escrow: cannot escalate in status %d", esc.Status)
+	}
+	if esc.EscalatedAt != 0 {
+		return nil
+	}
+	if esc.FrozenArb == nil || esc.FrozenArb.Fallback == nil || esc.FrozenArb.ArbitrationSLASeconds <= 0 {
+		return fmt.Errorf("escrow: frozen policy has no fallback arbitrators")
+	}
+	now := e.now()
+	if now < esc.DisputedAt+esc.FrozenArb.ArbitrationSLASeconds {
+		return fmt.Errorf("escrow: arbitration sla has not elapsed")
+	}
+	esc.EscalatedAt = now
+	if err := e.storeEscrow(esc); err != nil {
+		return err
+	}
+	e.emit(NewDisputeEscalatedEvent(esc))
+	return nil
+}
+
+// decisionPolicy returns the arbitrator policy whose signatures settle the
+// escrow: the frozen committee, or its fallback set once escalated.
+func decisionPolicy(esc *Escrow) *FrozenArb {
+	if esc.FrozenArb == nil || esc.EscalatedAt == 0 || esc.FrozenArb.Fallback == nil {
+		return esc.FrozenArb
+	}
+	policy := esc.FrozenArb.Clone()
+	policy.Scheme = esc.FrozenArb.Fallback.Scheme
+	policy.Threshold = esc.FrozenArb.Fallback.Threshold
+	policy.Members = append([][20]byte(nil), esc.FrozenArb.Fallback.Members...)
+	return policy
+}
+
+func parseDecisionPayload(id [32]byte, frozen *FrozenArb, payload []byte) (DecisionOutcome, SplitDecision, [32]byte, [32]byte, error) {
 	var zero [32]byte
 	if len(payload) == 0 {
-		return DecisionOutcomeUnknown, zero, zero, fmt.Errorf("escrow: decision payload required")
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: decision payload required")
 	}
 	if frozen == nil {
-		return DecisionOutcomeUnknown, zero, zero, fmt.Errorf("escrow: missing frozen arbitrator policy")
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: missing frozen arbitrator policy")
 	}
 	var envelope decisionEnvelope
 	if err := json.Unmarshal(payload, &envelope); err != nil {
-		return DecisionOutcomeUnknown, zero, zero, fmt.Errorf("escrow: invalid decision payload: %w", err)
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: invalid decision payload: %w", err)
 	}
 	trimmedID := strings.TrimSpace(envelope.EscrowID)
 	if trimmedID == "" {
-		return DecisionOutcomeUnknown, zero, zero, fmt.Errorf("escrow: decision escrowId required")
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: decision escrowId required")
 	}
 	decodedID, err := decodeFixedHex(trimmedID, len(id))
 	if err != nil {
-		return DecisionOutcomeUnknown, zero, zero, fmt.Errorf("escrow: invalid decision escrowId: %w", err)
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: invalid decision escrowId: %w", err)
 	}
 	var payloadID [32]byte
 	copy(payloadID[:], decodedID)
 	if payloadID != id {
-		return DecisionOutcomeUnknown, zero, zero, fmt.Errorf("escrow: decision escrowId mismatch")
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: decision escrowId mismatch")
 	}
 	if envelope.PolicyNonce == 0 {
-		return DecisionOutcomeUnknown, zero, zero, fmt.Errorf("escrow: decision policyNonce required")
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: decision policyNonce required")
 	}
 	if frozen.PolicyNonce != envelope.PolicyNonce {
-		return DecisionOutcomeUnknown, zero, zero, fmt.Errorf("escrow: decision policyNonce mismatch")
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: decision policyNonce mismatch")
 	}
 	outcome, err := ParseDecisionOutcome(envelope.Outcome)
 	if err != nil {
-		return DecisionOutcomeUnknown, zero, zero, err
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, err
+	}
+	split := SplitDecision{PayeeBps: envelope.PayeeBps, PayerBps: envelope.PayerBps}
+	if outcome == DecisionOutcomeSplit {
+		if err := split.Validate(); err != nil {
+			return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, err
+		}
+	} else if split != (SplitDecision{}) {
+		return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: split shares require a split outcome")
 	}
 	var meta [32]byte
 	trimmedMeta := strings.TrimSpace(envelope.Metadata)
 	if trimmedMeta != "" {
 		decodedMeta, err := decodeFixedHex(trimmedMeta, len(meta))
 		if err != nil {
-			return DecisionOutcomeUnknown, zero, zero, fmt.Errorf("escrow: invalid decision metadata: %w", err)
+			return DecisionOutcomeUnknown, SplitDecision{}, zero, zero, fmt.Errorf("escrow: invalid decision metadata: %w", err)
 		}
 		copy(meta[:], decodedMeta)
 	}
 	hash := ethcrypto.Keccak256Hash(payload)
 	var digest [32]byte
 	copy(digest[:], hash[:])
-	return outcome, meta, digest, nil
+	return outcome, split, meta, digest, nil
 }
 
 func decodeFixedHex(value string, length int) ([]byte, error) {
@@ -1000,7 +1243,7 @@ func verifyDecisionSignatures(frozen *FrozenArb, digest [32]byte, signatures [][
 	return unique, nil
 }
 
-func (e *Engine) arbitratedRelease(esc *Escrow) error {
+func (e *Engine) arbitratedRelease(esc *Escrow, signers [][20]byte) error {
 	if esc == nil {
 		return fmt.Errorf("escrow: nil escrow")
 	}
@@ -1018,43 +1261,17 @@ func (e *Engine) arbitratedRelease(esc *Escrow) error {
 	if total.Sign() <= 0 {
 		return fmt.Errorf("escrow: amount must be positive")
 	}
-	var (
-		payout     *big.Int
-		fee        *big.Int
-		realmFee   *big.Int
-		realmPayee [20]byte
-	)
-	if esc.Status == EscrowDisputed {
-		var err error
-		payout, fee, realmFee, realmPayee, err = e.computeDisputePayouts(esc)
-		if err != nil {
-			return err
-		}
-	} else {
-		fee = calculateFee(total, esc.FeeBps)
-		payout = new(big.Int).Sub(total, fee)
-		realmFee = big.NewInt(0)
-	}
-	if payout.Sign() > 0 {
-		if err := e.transferToken(vault, esc.Payee, esc.Token, payout); err != nil {
-			return err
-		}
+	share, err := e.releaseShare(esc, total, len(signers) > 0)
+	if err != nil {
+		return err
 	}
-	if fee.Sign() > 0 {
-		if err := e.ensureTreasuryConfigured(); err != nil {
-			return err
-		}
-		if err := e.transferToken(vault, e.feeTreasury, esc.Token, fee); err != nil {
+	if share.payout.Sign() > 0 {
+		if err := e.transferToken(vault, esc.Payee, esc.Token, share.payout); err != nil {
 			return err
 		}
 	}
-	if realmFee != nil && realmFee.Sign() > 0 {
-		if realmPayee == ([20]byte{}) {
-			return fmt.Errorf("escrow: realm fee recipient missing")
-		}
-		if err := e.transferToken(vault, realmPayee, esc.Token, realmFee); err != nil {
-			return err
-		}
+	if err := e.payDisputeFees(vault, esc, share, signers); err != nil {
+		return err
 	}
 	if err := e.state.EscrowDebit(esc.ID, esc.Token, total); err != nil {
 		return err
@@ -1067,7 +1284,7 @@ func (e *Engine) arbitratedRelease(esc *Escrow) error {
 	return nil
 }
 
-func (e *Engine) arbitratedRefund(esc *Escrow) error {
+func (e *Engine) arbitratedRefund(esc *Escrow, signers [][20]byte) error {
 	if esc == nil {
 		return fmt.Errorf("escrow: nil escrow")
 	}
@@ -1085,43 +1302,20 @@ func (e *Engine) arbitratedRefund(esc *Escrow) error {
 	if total.Sign() <= 0 {
 		return fmt.Errorf("escrow: amount must be positive")
 	}
-	var (
-		payout     *big.Int
-		fee        *big.Int
-		realmFee   *big.Int
-		realmPayee [20]byte
-	)
+	share := &disputeShare{payout: total}
 	if esc.Status == EscrowDisputed {
-		var computeErr error
-		payout, fee, realmFee, realmPayee, computeErr = e.computeDisputePayouts(esc)
-		if computeErr != nil {
-			return computeErr
-		}
-	} else {
-		payout = cloneBigInt(total)
-		fee = big.NewInt(0)
-		realmFee = big.NewInt(0)
-	}
-	if payout.Sign() > 0 {
-		if err := e.transferToken(vault, esc.Payer, esc.Token, payout); err != nil {
+		share, err = e.computeDisputePayouts(esc, len(signers) > 0)
+		if err != nil {
 			return err
 		}
 	}
-	if fee != nil && fee.Sign() > 0 {
-		if err := e.ensureTreasuryConfigured(); err != nil {
-			return err
-		}
-		if err := e.transferToken(vault, e.feeTreasury, esc.Token, fee); err != nil {
+	if share.payout.Sign() > 0 {
+		if err := e.transferToken(vault, esc.Payer, esc.Token, share.payout); err != nil {
 			return err
 		}
 	}
-	if realmFee != nil && realmFee.Sign() > 0 {
-		if realmPayee == ([20]byte{}) {
-			return fmt.Errorf("escrow: realm fee recipient missing")
-		}
-		if err := e.transferToken(vault, realmPayee, esc.Token, realmFee); err != nil {
-			return err
-		}
+	if err := e.payDisputeFees(vault, esc, share, signers); err != nil {
+		return err
 	}
 	if err := e.state.EscrowDebit(esc.ID, esc.Token, total); err != nil {
 		return err
@@ -1134,6 +1328,71 @@ func (e *Engine) arbitratedRefund(esc *Escrow) error {
 	return nil
 }
 
+// arbitratedSplit divides the escrow between payee and payer according to the
+// split shares. Each share pays its own part of the escrow fee and of the realm
+// fee schedule before the remainder is transferred. The arbitrator fee is only
+// charged when signers are supplied.
+func (e *Engine) arbitratedSplit(esc *Escrow, split SplitDecision, signers [][20]byte) (*SplitPayout, error) {
+	if esc == nil {
+		return nil, fmt.Errorf("escrow: nil escrow")
+	}
+	if err := split.Validate(); err != nil {
+		return nil, err
+	}
+	if esc.Status != EscrowFunded && esc.Status != EscrowDisputed {
+		return nil, fmt.Errorf("escrow: cannot split in status %d", esc.Status)
+	}
+	vault, err := e.state.EscrowVaultAddress(esc.Token)
+	if err != nil {
+		return nil, err
+	}
+	total := cloneBigInt(esc.Amount)
+	if total.Sign() <= 0 {
+		return nil, fmt.Errorf("escrow: amount must be positive")
+	}
+	payeeShare := calculateFee(total, split.PayeeBps)
+	payerShare := new(big.Int).Sub(total, payeeShare)
+	result := &SplitPayout{Fee: big.NewInt(0), RealmFee: big.NewInt(0), ArbitratorFee: big.NewInt(0)}
+	fees := &disputeShare{fee: result.Fee, realmFee: result.RealmFee, arbitratorFee: result.ArbitratorFee}
+	shares := []struct {
+		amount *big.Int
+		to     [20]byte
+		out    **big.Int
+	}{
+		{payeeShare, esc.Payee, &result.PayeeAmount},
+		{payerShare, esc.Payer, &result.PayerAmount},
+	}
+	for _, share := range shares {
+		computed, err := computeSharePayouts(esc, share.amount, len(signers) > 0)
+		if err != nil {
+			return nil, err
+		}
+		if computed.payout.Sign() > 0 {
+			if err := e.transferToken(vault, share.to, esc.Token, computed.payout); err != nil {
+				return nil, err
+			}
+		}
+		*share.out = computed.payout
+		fees.fee.Add(fees.fee, computed.fee)
+		fees.realmFee.Add(fees.realmFee, computed.realmFee)
+		fees.arbitratorFee.Add(fees.arbitratorFee, computed.arbitratorFee)
+		if computed.realmPayee != ([20]byte{}) {
+			fees.realmPayee = computed.realmPayee
+		}
+	}
+	if err := e.payDisputeFees(vault, esc, fees, signers); err != nil {
+		return nil, err
+	}
+	if err := e.state.EscrowDebit(esc.ID, esc.Token, total); err != nil {
+		return nil, err
+	}
+	esc.Status = EscrowSplit
+	if err := e.storeEscrow(esc); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // Resolve settles a disputed escrow according to the mediator-determined
 // outcome. Valid outcomes are "release" and "refund".
 func (e *Engine) Resolve(id [32]byte, caller [20]byte, outcome string) error {
@@ -1144,7 +1403,7 @@ func (e *Engine) Resolve(id [32]byte, caller [20]byte, outcome string) error {
 	if err != nil {
 		return err
 	}
-	if esc.Status == EscrowReleased || esc.Status == EscrowRefunded || esc.Status == EscrowExpired {
+	if esc.Status == EscrowReleased || esc.Status == EscrowRefunded || esc.Status == EscrowExpired || esc.Status == EscrowSplit {
 		return nil
 	}
 	if esc.Status != EscrowDisputed {
@@ -1163,9 +1422,11 @@ func (e *Engine) Resolve(id [32]byte, caller [20]byte, outcome string) error {
 			return err
 		}
 	case DecisionOutcomeRefund:
-		if err := e.arbitratedRefund(esc); err != nil {
+		if err := e.arbitratedRefund(esc, nil); err != nil {
 			return err
 		}
+	case DecisionOutcomeSplit:
+		return fmt.Errorf("escrow: split outcome requires a signed arbitrator decision")
 	default:
 		return fmt.Errorf("escrow: invalid resolution outcome %s", outcome)
 	}
@@ -1187,13 +1448,13 @@ func (e *Engine) ResolveWithSignatures(id [32]byte, decisionPayload []byte, sign
 	if err != nil {
 		return err
 	}
-	if esc.Status == EscrowReleased || esc.Status == EscrowRefunded || esc.Status == EscrowExpired {
+	if esc.Status == EscrowReleased || esc.Status == EscrowRefunded || esc.Status == EscrowExpired || esc.Status == EscrowSplit {
 		return nil
 	}
 	if esc.Status != EscrowDisputed {
 		return fmt.Errorf("escrow: cannot resolve in status %d", esc.Status)
 	}
-	outcome, metaHash, digest, err := parseDecisionPayload(id, esc.FrozenArb, decisionPayload)
+	outcome, split, metaHash, digest, err := parseDecisionPayload(id, esc.FrozenArb, decisionPayload)
 	if err != nil {
 		return err
 	}
@@ -1203,7 +1464,7 @@ func (e *Engine) ResolveWithSignatures(id [32]byte, decisionPayload []byte, sign
 	if esc.ResolutionHash != ([32]byte{}) && esc.ResolutionHash != digest {
 		return fmt.Errorf("escrow: conflicting decision payload")
 	}
-	signers, err := verifyDecisionSignatures(esc.FrozenArb, digest, signatures)
+	signers, err := verifyDecisionSignatures(decisionPolicy(esc), digest, signatures)
 	if err != nil {
 		return err
 	}
@@ -1211,15 +1472,23 @@ func (e *Engine) ResolveWithSignatures(id [32]byte, decisionPayload []byte, sign
 	esc.ResolutionHash = digest
 	switch outcome {
 	case DecisionOutcomeRelease:
-		if err := e.arbitratedRelease(esc); err != nil {
+		if err := e.arbitratedRelease(esc, signers); err != nil {
 			esc.ResolutionHash = prevHash
 			return err
 		}
 	case DecisionOutcomeRefund:
-		if err := e.arbitratedRefund(esc); err != nil {
+		if err := e.arbitratedRefund(esc, signers); err != nil {
+			esc.ResolutionHash = prevHash
+			return err
+		}
+	case DecisionOutcomeSplit:
+		payout, err := e.arbitratedSplit(esc, split, signers)
+		if err != nil {
 			esc.ResolutionHash = prevHash
 			return err
 		}
+		e.emit(NewSplitResolvedEvent(esc, split, payout, metaHash, signers))
+		return nil
 	default:
 		esc.ResolutionHash = prevHash
 		return fmt.Errorf("escrow: unsupported decision outcome")
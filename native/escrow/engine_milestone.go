@@ -3,6 +3,8 @@ package escrow
 import (
 	"errors"
 	"fmt"
+	"math/big"
+	"strings"
 	"time"
 )
 
@@ -12,6 +14,26 @@ var ErrMilestoneNotFound = errors.New("escrow: milestone leg not found")
 // ErrMilestoneInvalidTransition marks invalid status transitions.
 var ErrMilestoneInvalidTransition = errors.New("escrow: invalid milestone transition")
 
+// ErrMilestoneNotArbitrated is returned when disputing a leg of a project that
+// has no frozen arbitrator policy.
+var ErrMilestoneNotArbitrated = errors.New("escrow: milestone project has no arbitrator policy")
+
+// MilestoneResolution describes how a disputed leg is paid out. The amounts
+// are net of the realm and arbitrator fees, which are charged on each share
+// separately.
+type MilestoneResolution struct {
+	Outcome           DecisionOutcome
+	Split             SplitDecision
+	PayeeAmount       *big.Int
+	PayerAmount       *big.Int
+	RealmFee          *big.Int
+	RealmFeeRecipient [20]byte
+	ArbitratorFee     *big.Int
+	ArbitratorPayouts []ArbitratorPayout
+	MetaHash          [32]byte
+	Signers           [][20]byte
+}
+
 // MilestoneEngine orchestrates state transitions for milestone projects.
 type MilestoneEngine struct {
 	now func() time.Time
@@ -100,6 +122,9 @@ func (e *MilestoneEngine) CancelLeg(project *MilestoneProject, legID uint64) err
 	if leg.Status == MilestoneLegCancelled {
 		return nil
 	}
+	if leg.Status == MilestoneLegDisputed || leg.Status == MilestoneLegSplit {
+		return fmt.Errorf("%w: leg %d is under arbitration", ErrMilestoneInvalidTransition, legID)
+	}
 	now := e.now().Unix()
 	leg.Status = MilestoneLegCancelled
 	leg.CancelledAt = now
@@ -110,6 +135,128 @@ func (e *MilestoneEngine) CancelLeg(project *MilestoneProject, legID uint64) err
 	return nil
 }
 
+// DisputeLeg flags a funded or expired leg as disputed so the project's frozen
+// arbitrator committee can settle it. Only the payer or payee may dispute and
+// repeated disputes are no-ops.
+func (e *MilestoneEngine) DisputeLeg(project *MilestoneProject, legID uint64, caller [20]byte, reason string) error {
+	leg := project.FindLeg(legID)
+	if leg == nil {
+		return ErrMilestoneNotFound
+	}
+	if project.FrozenArb == nil {
+		return ErrMilestoneNotArbitrated
+	}
+	if caller != project.Payer && caller != project.Payee {
+		return fmt.Errorf("escrow: unauthorized milestone dispute caller")
+	}
+	if leg.Status == MilestoneLegDisputed {
+		return nil
+	}
+	if leg.Status != MilestoneLegFunded && leg.Status != MilestoneLegExpired {
+		return fmt.Errorf("%w: leg %d must be funded or expired", ErrMilestoneInvalidTransition, legID)
+	}
+	now := e.now().Unix()
+	leg.Status = MilestoneLegDisputed
+	leg.DisputedAt = now
+	leg.DisputeReason = strings.TrimSpace(reason)
+	project.UpdatedAt = now
+	if project.Status != MilestoneStatusActive {
+		project.Status = MilestoneStatusActive
+	}
+	return nil
+}
+
+// ResolveLeg settles a disputed leg after verifying a quorum of signatures
+// from the project's frozen arbitrator policy. The decision payload uses the
+// same format as Engine.ResolveWithSignatures with MilestoneDecisionID as the
+// escrowId. Replaying an applied decision returns a nil resolution.
+func (e *MilestoneEngine) ResolveLeg(project *MilestoneProject, legID uint64, payload []byte, signatures [][]byte) (*MilestoneResolution, error) {
+	leg := project.FindLeg(legID)
+	if leg == nil {
+		return nil, ErrMilestoneNotFound
+	}
+	if project.FrozenArb == nil {
+		return nil, ErrMilestoneNotArbitrated
+	}
+	outcome, split, metaHash, digest, err := parseDecisionPayload(MilestoneDecisionID(project.ID, legID), project.FrozenArb, payload)
+	if err != nil {
+		return nil, err
+	}
+	if leg.Status != MilestoneLegDisputed {
+		if leg.ResolutionHash == digest {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: leg %d is not disputed", ErrMilestoneInvalidTransition, legID)
+	}
+	signers, err := verifyDecisionSignatures(project.FrozenArb, digest, signatures)
+	if err != nil {
+		return nil, err
+	}
+	amount := cloneBigInt(leg.Amount)
+	resolution := &MilestoneResolution{
+		Outcome:       outcome,
+		Split:         split,
+		PayeeAmount:   big.NewInt(0),
+		PayerAmount:   big.NewInt(0),
+		RealmFee:      big.NewInt(0),
+		ArbitratorFee: big.NewInt(0),
+		MetaHash:      metaHash,
+		Signers:       signers,
+	}
+	var feeBps, arbitratorFeeBps uint32
+	if schedule := project.FrozenArb.FeeSchedule; schedule != nil {
+		feeBps = schedule.FeeBps
+		arbitratorFeeBps = schedule.ArbitratorFeeBps
+		resolution.RealmFeeRecipient = schedule.Recipient
+	}
+	payeeShare := big.NewInt(0)
+	switch outcome {
+	case DecisionOutcomeRelease:
+		payeeShare = amount
+	case DecisionOutcomeRefund:
+	case DecisionOutcomeSplit:
+		payeeShare = calculateFee(amount, split.PayeeBps)
+	default:
+		return nil, fmt.Errorf("escrow: unsupported decision outcome")
+	}
+	payerShare := new(big.Int).Sub(amount, payeeShare)
+	for _, share := range []struct {
+		amount *big.Int
+		out    *big.Int
+	}{{payeeShare, resolution.PayeeAmount}, {payerShare, resolution.PayerAmount}} {
+		fee := calculateFee(share.amount, feeBps)
+		arbitratorFee := calculateFee(share.amount, arbitratorFeeBps)
+		share.out.Sub(share.amount, fee)
+		share.out.Sub(share.out, arbitratorFee)
+		resolution.RealmFee.Add(resolution.RealmFee, fee)
+		resolution.ArbitratorFee.Add(resolution.ArbitratorFee, arbitratorFee)
+	}
+	resolution.ArbitratorPayouts = SplitArbitratorFee(resolution.ArbitratorFee, signers)
+	if resolution.RealmFee.Sign() > 0 && resolution.RealmFeeRecipient == ([20]byte{}) {
+		return nil, fmt.Errorf("escrow: realm fee recipient missing")
+	}
+	now := e.now().Unix()
+	switch outcome {
+	case DecisionOutcomeRelease:
+		leg.Status = MilestoneLegReleased
+		leg.ReleasedAt = now
+	case DecisionOutcomeRefund:
+		leg.Status = MilestoneLegCancelled
+		leg.CancelledAt = now
+	case DecisionOutcomeSplit:
+		leg.Status = MilestoneLegSplit
+		leg.ReleasedAt = now
+	}
+	leg.ResolutionHash = digest
+	project.UpdatedAt = now
+	if e.allLegsReleased(project) {
+		project.Status = MilestoneStatusCompleted
+	} else if !e.hasOpenLegs(project) {
+		project.Status = MilestoneStatusCancelled
+	}
+	return resolution, nil
+}
+
 // AdvanceSubscription increments the subscription schedule if active.
 func (e *MilestoneEngine) AdvanceSubscription(project *MilestoneProject) {
 	if project == nil || project.Subscription == nil {
@@ -151,7 +298,7 @@ func (e *MilestoneEngine) allLegsReleased(project *MilestoneProject) bool {
 		if leg == nil {
 			continue
 		}
-		if leg.Status != MilestoneLegReleased && leg.Status != MilestoneLegCancelled {
+		if leg.Status != MilestoneLegReleased && leg.Status != MilestoneLegCancelled && leg.Status != MilestoneLegSplit {
 			return false
 		}
 	}
@@ -166,7 +313,10 @@ func (e *MilestoneEngine) hasOpenLegs(project *MilestoneProject) bool {
 		if leg == nil {
 			continue
 		}
-		if leg.Status == MilestoneLegPending || leg.Status == MilestoneLegFunded {
+		if leg.Status == MilestoneLegPending || leg.Status == MilestoneLegFunded || leg.Status == MilestoneLegDisputed {
+			return true
+		}
+		if leg.Status == MilestoneLegExpired && project.LegHoldsFunds(leg) {
 			return true
 		}
 	}
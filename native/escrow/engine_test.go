@@ -28,6 +28,8 @@ type mockState struct {
 	realms        map[string]*EscrowRealm
 	frozen        map[[32]byte]*FrozenArb
 	params        map[string][]byte
+	tokens        map[string]bool
+	assetBalances map[string]map[[20]byte]*big.Int
 }
 
 func newMockState() *mockState {
@@ -41,9 +43,11 @@ func newMockState() *mockState {
 			"NHB":  newTestAddress(0xAA),
 			"ZNHB": newTestAddress(0xBB),
 		},
-		realms: make(map[string]*EscrowRealm),
-		frozen: make(map[[32]byte]*FrozenArb),
-		params: make(map[string][]byte),
+		realms:        make(map[string]*EscrowRealm),
+		frozen:        make(map[[32]byte]*FrozenArb),
+		params:        make(map[string][]byte),
+		tokens:        make(map[string]bool),
+		assetBalances: make(map[string]map[[20]byte]*big.Int),
 	}
 }
 
@@ -158,7 +162,7 @@ func (m *mockState) ParamStoreGet(name string) ([]byte, bool, error) {
 }
 
 func (m *mockState) EscrowCredit(id [32]byte, token string, amt *big.Int) error {
-	normalized, err := NormalizeToken(token)
+	normalized, err := NormalizeAsset(token)
 	if err != nil {
 		return err
 	}
@@ -187,7 +191,7 @@ func (m *mockState) EscrowCredit(id [32]byte, token string, amt *big.Int) error
 }
 
 func (m *mockState) EscrowBalance(id [32]byte, token string) (*big.Int, error) {
-	normalized, err := NormalizeToken(token)
+	normalized, err := NormalizeAsset(token)
 	if err != nil {
 		return nil, err
 	}
@@ -200,7 +204,7 @@ func (m *mockState) EscrowBalance(id [32]byte, token string) (*big.Int, error) {
 }
 
 func (m *mockState) EscrowDebit(id [32]byte, token string, amt *big.Int) error {
-	normalized, err := NormalizeToken(token)
+	normalized, err := NormalizeAsset(token)
 	if err != nil {
 		return err
 	}
@@ -235,7 +239,7 @@ func (m *mockState) EscrowDebit(id [32]byte, token string, amt *big.Int) error {
 }
 
 func (m *mockState) EscrowVaultAddress(token string) ([20]byte, error) {
-	normalized, err := NormalizeToken(token)
+	normalized, err := NormalizeAsset(token)
 	if err != nil {
 		return [20]byte{}, err
 	}
@@ -263,6 +267,36 @@ func (m *mockState) PutAccount(addr []byte, account *types.Account) error {
 	return nil
 }
 
+func (m *mockState) TokenExists(symbol string) bool {
+	return m.tokens[symbol]
+}
+
+func (m *mockState) assetBalance(addr [20]byte, symbol string) *big.Int {
+	if balance, ok := m.assetBalances[symbol][addr]; ok {
+		return new(big.Int).Set(balance)
+	}
+	return big.NewInt(0)
+}
+
+func (m *mockState) AssetTransfer(from, to []byte, symbol string, amount *big.Int) error {
+	if !m.tokens[symbol] {
+		return fmt.Errorf("token %s not registered", symbol)
+	}
+	var src, dst [20]byte
+	copy(src[:], from)
+	copy(dst[:], to)
+	balance := m.assetBalance(src, symbol)
+	if balance.Cmp(amount) < 0 {
+		return fmt.Errorf("insufficient balance")
+	}
+	if m.assetBalances[symbol] == nil {
+		m.assetBalances[symbol] = make(map[[20]byte]*big.Int)
+	}
+	m.assetBalances[symbol][src] = balance.Sub(balance, amount)
+	m.assetBalances[symbol][dst] = new(big.Int).Add(m.assetBalance(dst, symbol), amount)
+	return nil
+}
+
 func (m *mockState) setAccount(addr [20]byte, acc *types.Account) {
 	m.accounts[addr] = cloneAccount(acc)
 }
@@ -700,6 +734,43 @@ func TestFundTransfersToVaultAndIsIdempotent(t *testing.T) {
 	}
 }
 
+func TestEscrowSettlesIssuedAsset(t *testing.T) {
+	state := newMockState()
+	state.tokens["USDX"] = true
+	state.vaultAddrs["USDX"] = newTestAddress(0xDD)
+	engine := newTestEngine(state)
+	payer := newTestAddress(0x25)
+	payee := newTestAddress(0x26)
+	meta := [32]byte{}
+	if _, err := engine.Create(payer, payee, "EURX", big.NewInt(10), 0, 1_700_001_000, 33, nil, meta, ""); err == nil {
+		t.Fatalf("expected unregistered asset to be rejected")
+	}
+	esc, err := engine.Create(payer, payee, "usdx", big.NewInt(400), 0, 1_700_001_000, 34, nil, meta, "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if esc.Token != "USDX" {
+		t.Fatalf("unexpected token %q", esc.Token)
+	}
+	state.assetBalances["USDX"] = map[[20]byte]*big.Int{payer: big.NewInt(1_000)}
+
+	if err := engine.Fund(esc.ID, payer); err != nil {
+		t.Fatalf("fund: %v", err)
+	}
+	if got := state.assetBalance(newTestAddress(0xDD), "USDX"); got.Cmp(big.NewInt(400)) != 0 {
+		t.Fatalf("unexpected vault balance: %s", got)
+	}
+	if err := engine.Release(esc.ID, payee); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if got := state.assetBalance(payee, "USDX"); got.Cmp(big.NewInt(400)) != 0 {
+		t.Fatalf("unexpected payee balance: %s", got)
+	}
+	if got := state.assetBalance(payer, "USDX"); got.Cmp(big.NewInt(600)) != 0 {
+		t.Fatalf("unexpected payer balance: %s", got)
+	}
+}
+
 func TestFundRejectsWrongCaller(t *testing.T) {
 	state := newMockState()
 	engine := newTestEngine(state)
@@ -1105,6 +1176,117 @@ func TestResolveWithSignaturesRefundRoutesFees(t *testing.T) {
 	}
 }
 
+func buildSplitDecisionPayload(t *testing.T, id [32]byte, nonce uint64, outcome string, payeeBps, payerBps uint32) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{
+		"escrowId":    hex.EncodeToString(id[:]),
+		"outcome":     outcome,
+		"policyNonce": nonce,
+		"payeeBps":    payeeBps,
+		"payerBps":    payerBps,
+	})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return data
+}
+
+func TestResolveWithSignaturesSplitAppliesFeesPerShare(t *testing.T) {
+	state := newMockState()
+	engine := newTestEngine(state)
+	emitter := &capturingEmitter{}
+	engine.SetEmitter(emitter)
+
+	keyA, addrA := mustGenerateArbitrator(t)
+	keyB, addrB := mustGenerateArbitrator(t)
+
+	arbRecipient := newTestAddress(0xAF)
+	realm := &EscrowRealm{
+		ID: "realm-split",
+		Arbitrators: &ArbitratorSet{
+			Scheme:    ArbitrationSchemeCommittee,
+			Threshold: 2,
+			Members:   [][20]byte{addrA, addrB},
+		},
+		FeeSchedule: &RealmFeeSchedule{FeeBps: 100, Recipient: arbRecipient},
+		Metadata:    testRealmMetadata(),
+	}
+	if _, err := engine.CreateRealm(realm); err != nil {
+		t.Fatalf("create realm: %v", err)
+	}
+
+	payer := newTestAddress(0x95)
+	payee := newTestAddress(0x96)
+	esc, err := engine.Create(payer, payee, "NHB", big.NewInt(1_000), 500, 1_700_001_200, 48, nil, [32]byte{}, realm.ID)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	state.setAccount(payer, &types.Account{BalanceNHB: big.NewInt(1_000)})
+	if err := engine.Fund(esc.ID, payer); err != nil {
+		t.Fatalf("fund: %v", err)
+	}
+	if err := engine.Dispute(esc.ID, payer, "partial delivery"); err != nil {
+		t.Fatalf("dispute: %v", err)
+	}
+
+	sign := func(payload []byte) [][]byte {
+		return [][]byte{signDecisionPayload(t, payload, keyA), signDecisionPayload(t, payload, keyB)}
+	}
+	uneven := buildSplitDecisionPayload(t, esc.ID, esc.FrozenArb.PolicyNonce, "split", 6_000, 3_000)
+	if err := engine.ResolveWithSignatures(esc.ID, uneven, sign(uneven)); err == nil {
+		t.Fatalf("expected shares not totalling 10000 bps to be rejected")
+	}
+	mixed := buildSplitDecisionPayload(t, esc.ID, esc.FrozenArb.PolicyNonce, "release", 6_000, 4_000)
+	if err := engine.ResolveWithSignatures(esc.ID, mixed, sign(mixed)); err == nil {
+		t.Fatalf("expected split shares on a release decision to be rejected")
+	}
+
+	payload := buildSplitDecisionPayload(t, esc.ID, esc.FrozenArb.PolicyNonce, "split", 6_000, 4_000)
+	if err := engine.ResolveWithSignatures(esc.ID, payload, sign(payload)); err != nil {
+		t.Fatalf("resolve with signatures: %v", err)
+	}
+
+	stored, _ := state.EscrowGet(esc.ID)
+	if stored.Status != EscrowSplit {
+		t.Fatalf("expected split status, got %d", stored.Status)
+	}
+	// Payee share 600 and payer share 400 each pay 5% escrow fee and 1% realm fee.
+	if got := state.account(payee).BalanceNHB.String(); got != "564" {
+		t.Fatalf("expected payee 564, got %s", got)
+	}
+	if got := state.account(payer).BalanceNHB.String(); got != "376" {
+		t.Fatalf("expected payer 376, got %s", got)
+	}
+	if got := state.account(engine.feeTreasury).BalanceNHB.String(); got != "50" {
+		t.Fatalf("expected treasury 50, got %s", got)
+	}
+	if got := state.account(arbRecipient).BalanceNHB.String(); got != "10" {
+		t.Fatalf("expected arbitrator recipient 10, got %s", got)
+	}
+	if err := engine.ResolveWithSignatures(esc.ID, payload, sign(payload)); err != nil {
+		t.Fatalf("replayed split should be a no-op: %v", err)
+	}
+
+	events := emitter.typesEvents()
+	last := events[len(events)-1]
+	if last.Type != EventTypeEscrowResolved || last.Attributes["decision"] != "split" {
+		t.Fatalf("expected split resolved event, got %s %v", last.Type, last.Attributes)
+	}
+	want := map[string]string{
+		"payeeBps":    "6000",
+		"payerBps":    "4000",
+		"payeeAmount": "564",
+		"payerAmount": "376",
+		"fee":         "50",
+		"realmFee":    "10",
+	}
+	for key, value := range want {
+		if last.Attributes[key] != value {
+			t.Fatalf("expected %s=%s, got %q", key, value, last.Attributes[key])
+		}
+	}
+}
+
 func TestArbitratedReleaseZeroFeeWithoutTreasury(t *testing.T) {
 	state := newMockState()
 	engine := NewEngine()
@@ -1262,3 +1444,117 @@ func TestResolveWithSignaturesReplay(t *testing.T) {
 		t.Fatalf("expected no additional events on replay")
 	}
 }
+
+func TestDisputeEvidenceEscalationAndArbitratorFees(t *testing.T) {
+	state := newMockState()
+	engine := newTestEngine(state)
+	emitter := &capturingEmitter{}
+	engine.SetEmitter(emitter)
+	now := int64(1_700_000_000)
+	engine.SetNowFunc(func() int64 { return now })
+
+	primaryKey, primary := mustGenerateArbitrator(t)
+	keyA, fallbackA := mustGenerateArbitrator(t)
+	keyB, fallbackB := mustGenerateArbitrator(t)
+
+	realmRecipient := newTestAddress(0xB0)
+	realm := &EscrowRealm{
+		ID: "realm-sla",
+		Arbitrators: &ArbitratorSet{
+			Scheme:    ArbitrationSchemeSingle,
+			Threshold: 1,
+			Members:   [][20]byte{primary},
+		},
+		FeeSchedule:           &RealmFeeSchedule{FeeBps: 100, Recipient: realmRecipient, ArbitratorFeeBps: 210},
+		Metadata:              testRealmMetadata(),
+		EvidenceWindowSeconds: 3_600,
+		ArbitrationSLASeconds: 86_400,
+		FallbackArbitrators: &ArbitratorSet{
+			Scheme:    ArbitrationSchemeCommittee,
+			Threshold: 2,
+			Members:   [][20]byte{fallbackA, fallbackB},
+		},
+	}
+	if _, err := engine.CreateRealm(realm); err != nil {
+		t.Fatalf("create realm: %v", err)
+	}
+
+	payer := newTestAddress(0xB1)
+	payee := newTestAddress(0xB2)
+	esc, err := engine.Create(payer, payee, "NHB", big.NewInt(1_000), 0, now+7*86_400, 61, nil, [32]byte{}, realm.ID)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if esc.FrozenArb.Fallback == nil || esc.FrozenArb.ArbitrationSLASeconds != 86_400 {
+		t.Fatalf("expected fallback policy to be frozen, got %#v", esc.FrozenArb)
+	}
+	state.setAccount(payer, &types.Account{BalanceNHB: big.NewInt(1_000)})
+	if err := engine.Fund(esc.ID, payer); err != nil {
+		t.Fatalf("fund: %v", err)
+	}
+	evidenceHash := [32]byte{0xE1}
+	if err := engine.SubmitEvidence(esc.ID, payer, evidenceHash, ""); err == nil {
+		t.Fatalf("expected evidence before a dispute to be rejected")
+	}
+	if err := engine.Dispute(esc.ID, payer, "not delivered"); err != nil {
+		t.Fatalf("dispute: %v", err)
+	}
+	if err := engine.SubmitEvidence(esc.ID, newTestAddress(0xB3), evidenceHash, ""); err == nil {
+		t.Fatalf("expected outsider evidence to be rejected")
+	}
+	if err := engine.SubmitEvidence(esc.ID, payer, evidenceHash, "ipfs://bafy-delivery-log"); err != nil {
+		t.Fatalf("submit evidence: %v", err)
+	}
+	now += 3_601
+	if err := engine.SubmitEvidence(esc.ID, payee, [32]byte{0xE2}, ""); err == nil {
+		t.Fatalf("expected evidence after the window to be rejected")
+	}
+	if err := engine.EscalateDispute(esc.ID); err == nil {
+		t.Fatalf("expected escalation before the sla to be rejected")
+	}
+	now = 1_700_000_000 + 86_400
+	if err := engine.EscalateDispute(esc.ID); err != nil {
+		t.Fatalf("escalate: %v", err)
+	}
+	stored, _ := state.EscrowGet(esc.ID)
+	if len(stored.Evidence) != 1 || stored.Evidence[0].URI != "ipfs://bafy-delivery-log" || stored.EscalatedAt != now {
+		t.Fatalf("unexpected dispute record: %#v", stored)
+	}
+
+	payload := buildDecisionPayload(t, esc.ID, esc.FrozenArb.PolicyNonce, "release", [32]byte{})
+	if err := engine.ResolveWithSignatures(esc.ID, payload, [][]byte{signDecisionPayload(t, payload, primaryKey)}); err == nil {
+		t.Fatalf("expected the primary arbitrator to be replaced after escalation")
+	}
+	sigs := [][]byte{signDecisionPayload(t, payload, keyA), signDecisionPayload(t, payload, keyB)}
+	if err := engine.ResolveWithSignatures(esc.ID, payload, sigs); err != nil {
+		t.Fatalf("resolve with fallback signatures: %v", err)
+	}
+
+	// 1% realm fee and 2.1% arbitrator fee come out of the 1000 NHB escrow;
+	// the odd unit of the arbitrator fee goes to the lower signer address.
+	if got := state.account(payee).BalanceNHB.String(); got != "969" {
+		t.Fatalf("expected payee 969, got %s", got)
+	}
+	if got := state.account(realmRecipient).BalanceNHB.String(); got != "10" {
+		t.Fatalf("expected realm recipient 10, got %s", got)
+	}
+	first, second := fallbackA, fallbackB
+	if bytes.Compare(first[:], second[:]) > 0 {
+		first, second = second, first
+	}
+	if got := state.account(first).BalanceNHB.String(); got != "11" {
+		t.Fatalf("expected first arbitrator 11, got %s", got)
+	}
+	if got := state.account(second).BalanceNHB.String(); got != "10" {
+		t.Fatalf("expected second arbitrator 10, got %s", got)
+	}
+	var paid *types.Event
+	for _, evt := range emitter.typesEvents() {
+		if evt.Type == EventTypeArbitratorFeePaid {
+			paid = evt
+		}
+	}
+	if paid == nil || paid.Attributes["arbitratorFee"] != "21" {
+		t.Fatalf("expected arbitrator fee event, got %#v", paid)
+	}
+}
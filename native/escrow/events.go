@@ -2,6 +2,7 @@ package escrow
 
 import (
 	"encoding/hex"
+	"math/big"
 	"strconv"
 	"strings"
 
@@ -16,6 +17,9 @@ const (
 	EventTypeEscrowExpired      = "escrow.expired"
 	EventTypeEscrowDisputed     = "escrow.disputed"
 	EventTypeEscrowResolved     = "escrow.resolved"
+	EventTypeEvidenceSubmitted  = "escrow.evidence.submitted"
+	EventTypeDisputeEscalated   = "escrow.dispute.escalated"
+	EventTypeArbitratorFeePaid  = "escrow.arbitrator_fee.paid"
 	EventTypeRealmCreated       = "escrow.realm.created"
 	EventTypeRealmUpdated       = "escrow.realm.updated"
 	EventTypeTradeCreated       = "escrow.trade.created"
@@ -30,6 +34,8 @@ const (
 	EventTypeMilestoneReleased  = "escrow.milestone.released"
 	EventTypeMilestoneCancelled = "escrow.milestone.cancelled"
 	EventTypeMilestoneDue       = "escrow.milestone.leg_due"
+	EventTypeMilestoneDisputed  = "escrow.milestone.disputed"
+	EventTypeMilestoneResolved  = "escrow.milestone.resolved"
 )
 
 // NewRealmCreatedEvent emits the canonical payload for a newly created realm.
@@ -66,6 +72,45 @@ func NewExpiredEvent(e *Escrow) *types.Event { return newEscrowEvent(EventTypeEs
 // marked as disputed.
 func NewDisputedEvent(e *Escrow) *types.Event { return newEscrowEvent(EventTypeEscrowDisputed, e) }
 
+// NewEvidenceSubmittedEvent returns the payload emitted when a party attaches
+// evidence to a disputed escrow.
+func NewEvidenceSubmittedEvent(e *Escrow, evidence EscrowEvidence) *types.Event {
+	evt := newEscrowEvent(EventTypeEvidenceSubmitted, e)
+	evt.Attributes["submitter"] = hex.EncodeToString(evidence.Submitter[:])
+	evt.Attributes["evidenceHash"] = hex.EncodeToString(evidence.Hash[:])
+	if evidence.URI != "" {
+		evt.Attributes["evidenceUri"] = evidence.URI
+	}
+	evt.Attributes["evidenceCount"] = strconv.Itoa(len(e.Evidence))
+	return evt
+}
+
+// NewDisputeEscalatedEvent returns the payload emitted when a dispute passes
+// the arbitration SLA and moves to the fallback arbitrators.
+func NewDisputeEscalatedEvent(e *Escrow) *types.Event {
+	evt := newEscrowEvent(EventTypeDisputeEscalated, e)
+	evt.Attributes["disputedAt"] = strconv.FormatInt(e.DisputedAt, 10)
+	evt.Attributes["escalatedAt"] = strconv.FormatInt(e.EscalatedAt, 10)
+	if e.FrozenArb != nil && e.FrozenArb.Fallback != nil {
+		evt.Attributes["fallbackThreshold"] = strconv.FormatUint(uint64(e.FrozenArb.Fallback.Threshold), 10)
+		evt.Attributes["fallbackArbitrators"] = formatArbitratorMembers(e.FrozenArb.Fallback.Members)
+	}
+	return evt
+}
+
+// NewArbitratorFeePaidEvent returns the payload emitted when the arbitrator
+// fee of a decision is paid out to its signers.
+func NewArbitratorFeePaidEvent(e *Escrow, total *big.Int, payouts []ArbitratorPayout) *types.Event {
+	evt := newEscrowEvent(EventTypeArbitratorFeePaid, e)
+	evt.Attributes["arbitratorFee"] = cloneBigInt(total).String()
+	parts := make([]string, 0, len(payouts))
+	for _, payout := range payouts {
+		parts = append(parts, hex.EncodeToString(payout.Arbitrator[:])+":"+cloneBigInt(payout.Amount).String())
+	}
+	evt.Attributes["payouts"] = strings.Join(parts, ",")
+	return evt
+}
+
 // NewResolvedEvent returns the canonical event payload emitted when a dispute is
 // resolved. When supplied, the decision metadata is included for auditability.
 func NewResolvedEvent(e *Escrow, outcome DecisionOutcome, metaHash [32]byte, signers [][20]byte) *types.Event {
@@ -85,6 +130,20 @@ func NewResolvedEvent(e *Escrow, outcome DecisionOutcome, metaHash [32]byte, sig
 	return evt
 }
 
+// NewSplitResolvedEvent returns the resolved event for a split decision. On top
+// of the NewResolvedEvent payload it reports the shares and the net amounts
+// paid to each party.
+func NewSplitResolvedEvent(e *Escrow, split SplitDecision, payout *SplitPayout, metaHash [32]byte, signers [][20]byte) *types.Event {
+	evt := NewResolvedEvent(e, DecisionOutcomeSplit, metaHash, signers)
+	if evt == nil {
+		return nil
+	}
+	evt.Attributes["payeeBps"] = strconv.FormatUint(uint64(split.PayeeBps), 10)
+	evt.Attributes["payerBps"] = strconv.FormatUint(uint64(split.PayerBps), 10)
+	addSplitPayoutAttributes(evt.Attributes, "", payout)
+	return evt
+}
+
 // NewTradeCreatedEvent emits the canonical payload for a newly created trade.
 func NewTradeCreatedEvent(t *Trade) *types.Event {
 	return newTradeEvent(EventTypeTradeCreated, t, "")
@@ -110,6 +169,15 @@ func NewTradeResolvedEvent(t *Trade, outcome string) *types.Event {
 	return newTradeEvent(EventTypeTradeResolved, t, outcome)
 }
 
+// NewTradeSplitResolvedEvent emits the resolved payload for a trade whose legs
+// were split, including the net amounts paid out on each leg.
+func NewTradeSplitResolvedEvent(t *Trade, base, quote *SplitPayout) *types.Event {
+	evt := newTradeEvent(EventTypeTradeResolved, t, DecisionOutcomeSplit.String())
+	addSplitPayoutAttributes(evt.Attributes, "base", base)
+	addSplitPayoutAttributes(evt.Attributes, "quote", quote)
+	return evt
+}
+
 // NewTradeSettledEvent emits the payload when a trade is atomically settled.
 func NewTradeSettledEvent(t *Trade) *types.Event {
 	return newTradeEvent(EventTypeTradeSettled, t, "")
@@ -148,6 +216,37 @@ func NewMilestoneDueEvent(project *MilestoneProject, leg *MilestoneLeg) *types.E
 	return newMilestoneEvent(EventTypeMilestoneDue, project, leg)
 }
 
+// NewMilestoneDisputedEvent emits the payload when a party disputes a leg.
+func NewMilestoneDisputedEvent(project *MilestoneProject, leg *MilestoneLeg, caller [20]byte) *types.Event {
+	evt := newMilestoneEvent(EventTypeMilestoneDisputed, project, leg)
+	evt.Attributes["caller"] = hex.EncodeToString(caller[:])
+	if leg != nil && leg.DisputeReason != "" {
+		evt.Attributes["reason"] = leg.DisputeReason
+	}
+	return evt
+}
+
+// NewMilestoneResolvedEvent emits the payload when the frozen arbitrator
+// committee settles a disputed leg.
+func NewMilestoneResolvedEvent(project *MilestoneProject, leg *MilestoneLeg, res *MilestoneResolution) *types.Event {
+	evt := newMilestoneEvent(EventTypeMilestoneResolved, project, leg)
+	if res == nil {
+		return evt
+	}
+	evt.Attributes["decision"] = res.Outcome.String()
+	evt.Attributes["decisionMetadata"] = hex.EncodeToString(res.MetaHash[:])
+	evt.Attributes["decisionSigners"] = formatArbitratorMembers(res.Signers)
+	if res.Outcome == DecisionOutcomeSplit {
+		evt.Attributes["payeeBps"] = strconv.FormatUint(uint64(res.Split.PayeeBps), 10)
+		evt.Attributes["payerBps"] = strconv.FormatUint(uint64(res.Split.PayerBps), 10)
+	}
+	evt.Attributes["payeeAmount"] = cloneBigInt(res.PayeeAmount).String()
+	evt.Attributes["payerAmount"] = cloneBigInt(res.PayerAmount).String()
+	evt.Attributes["realmFee"] = cloneBigInt(res.RealmFee).String()
+	evt.Attributes["arbitratorFee"] = cloneBigInt(res.ArbitratorFee).String()
+	return evt
+}
+
 func newEscrowEvent(eventType string, e *Escrow) *types.Event {
 	attrs := make(map[string]string)
 	if e == nil {
@@ -304,3 +403,22 @@ func formatArbitratorMembers(members [][20]byte) string {
 	}
 	return strings.Join(parts, ",")
 }
+
+// addSplitPayoutAttributes records a split payout under keys such as
+// payeeAmount, or basePayeeAmount when a prefix is supplied.
+func addSplitPayoutAttributes(attrs map[string]string, prefix string, payout *SplitPayout) {
+	if payout == nil {
+		return
+	}
+	key := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + strings.ToUpper(name[:1]) + name[1:]
+	}
+	attrs[key("payeeAmount")] = cloneBigInt(payout.PayeeAmount).String()
+	attrs[key("payerAmount")] = cloneBigInt(payout.PayerAmount).String()
+	attrs[key("fee")] = cloneBigInt(payout.Fee).String()
+	attrs[key("realmFee")] = cloneBigInt(payout.RealmFee).String()
+	attrs[key("arbitratorFee")] = cloneBigInt(payout.ArbitratorFee).String()
+}
@@ -369,6 +369,47 @@ func (e *TradeEngine) TradeResolve(tradeID [32]byte, outcome string) error {
 	return nil
 }
 
+// TradeResolveSplit settles a disputed trade by splitting each leg between its
+// payee and payer. The base leg pays the buyer and refunds the seller; the
+// quote leg pays the seller and refunds the buyer. Realm fees apply to every
+// share of both legs.
+func (e *TradeEngine) TradeResolveSplit(tradeID [32]byte, base, quote SplitDecision) error {
+	trade, err := e.loadTrade(tradeID)
+	if err != nil {
+		return err
+	}
+	if err := nativecommon.Guard(e.pauses, tradeModuleName); err != nil {
+		return err
+	}
+	if trade.Status == TradeSettled {
+		return nil
+	}
+	if trade.Status != TradeDisputed {
+		return errTradeInvalidStatus
+	}
+	if err := base.Validate(); err != nil {
+		return err
+	}
+	if err := quote.Validate(); err != nil {
+		return err
+	}
+	basePayout, err := e.splitLeg(trade.EscrowBase, base)
+	if err != nil {
+		return fmt.Errorf("trade: split base leg: %w", err)
+	}
+	quotePayout, err := e.splitLeg(trade.EscrowQuote, quote)
+	if err != nil {
+		return fmt.Errorf("trade: split quote leg: %w", err)
+	}
+	trade.Status = TradeSettled
+	trade.FundedAt = 0
+	if err := e.state.TradePut(trade); err != nil {
+		return err
+	}
+	e.emit(NewTradeSplitResolvedEvent(trade, basePayout, quotePayout))
+	return nil
+}
+
 // SettleAtomic releases both legs of the trade atomically once funded.
 func (e *TradeEngine) SettleAtomic(tradeID [32]byte) error {
 	trade, err := e.loadTrade(tradeID)
@@ -576,6 +617,17 @@ func (e *TradeEngine) refundQuoteLeg(trade *Trade) error {
 	return e.escrow.Refund(trade.EscrowQuote, trade.Buyer)
 }
 
+func (e *TradeEngine) splitLeg(id [32]byte, split SplitDecision) (*SplitPayout, error) {
+	esc, err := e.loadEscrow(id)
+	if err != nil {
+		return nil, err
+	}
+	if esc.Status != EscrowFunded && esc.Status != EscrowDisputed {
+		return nil, fmt.Errorf("escrow not funded")
+	}
+	return e.escrow.arbitratedSplit(esc, split, nil)
+}
+
 func (e *TradeEngine) partialRefund(esc *Escrow, recipient [20]byte, amount *big.Int) error {
 	if amount == nil || amount.Sign() == 0 {
 		return nil
@@ -218,6 +218,70 @@ func TestTradeDisputeAndResolve(t *testing.T) {
 	}
 }
 
+func TestTradeResolveSplit(t *testing.T) {
+	tradeEngine, escEngine, state, emitter := setupTradeEnvironment(t)
+	buyer := newTestAddress(0x32)
+	seller := newTestAddress(0x42)
+	ensureBalances(state, buyer, seller)
+	trade, err := tradeEngine.CreateTrade("offer-split", buyer, seller, "ZNHB", big.NewInt(100), "NHB", big.NewInt(150), 4000, 0, [32]byte{0xCD})
+	if err != nil {
+		t.Fatalf("CreateTrade error: %v", err)
+	}
+	if err := escEngine.Fund(trade.EscrowBase, seller); err != nil {
+		t.Fatalf("fund base: %v", err)
+	}
+	if err := escEngine.Fund(trade.EscrowQuote, buyer); err != nil {
+		t.Fatalf("fund quote: %v", err)
+	}
+	if err := tradeEngine.OnFundingProgress(trade.ID); err != nil {
+		t.Fatalf("funding progress: %v", err)
+	}
+	base := SplitDecision{PayeeBps: 6_000, PayerBps: 4_000}
+	quote := SplitDecision{PayeeBps: 5_000, PayerBps: 5_000}
+	if err := tradeEngine.TradeResolveSplit(trade.ID, base, quote); err != errTradeInvalidStatus {
+		t.Fatalf("expected undisputed trade to be rejected, got %v", err)
+	}
+	if err := tradeEngine.TradeDispute(trade.ID, seller); err != nil {
+		t.Fatalf("trade dispute: %v", err)
+	}
+	if err := tradeEngine.TradeResolveSplit(trade.ID, base, SplitDecision{PayeeBps: 5_000}); err == nil {
+		t.Fatalf("expected incomplete quote split to be rejected")
+	}
+	if err := tradeEngine.TradeResolveSplit(trade.ID, base, quote); err != nil {
+		t.Fatalf("trade resolve split: %v", err)
+	}
+	stored, ok := state.TradeGet(trade.ID)
+	if !ok || stored.Status != TradeSettled {
+		t.Fatalf("expected TradeSettled, got %#v", stored)
+	}
+	for _, id := range [][32]byte{trade.EscrowBase, trade.EscrowQuote} {
+		esc, _ := state.EscrowGet(id)
+		if esc.Status != EscrowSplit {
+			t.Fatalf("expected split escrow status, got %d", esc.Status)
+		}
+	}
+	buyerAcc := state.account(buyer)
+	sellerAcc := state.account(seller)
+	if buyerAcc.BalanceNHB.Int64() != 90 || buyerAcc.BalanceZNHB.Int64() != 950 {
+		t.Fatalf("unexpected buyer balances NHB=%s ZNHB=%s", buyerAcc.BalanceNHB, buyerAcc.BalanceZNHB)
+	}
+	if sellerAcc.BalanceNHB.Int64() != 910 || sellerAcc.BalanceZNHB.Int64() != 50 {
+		t.Fatalf("unexpected seller balances NHB=%s ZNHB=%s", sellerAcc.BalanceNHB, sellerAcc.BalanceZNHB)
+	}
+	var resolved *types.Event
+	for _, evt := range emitter.typesEvents() {
+		if evt.Type == EventTypeTradeResolved {
+			resolved = evt
+		}
+	}
+	if resolved == nil {
+		t.Fatalf("expected trade resolved event")
+	}
+	if resolved.Attributes["outcome"] != "split" || resolved.Attributes["basePayeeAmount"] != "90" || resolved.Attributes["quotePayerAmount"] != "50" {
+		t.Fatalf("unexpected resolved attributes: %v", resolved.Attributes)
+	}
+}
+
 func TestTradeTryExpire(t *testing.T) {
 	t.Run("base leg funded", func(t *testing.T) {
 		tradeEngine, escEngine, state, emitter := setupTradeEnvironment(t)
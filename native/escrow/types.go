@@ -92,6 +92,17 @@ type EscrowRealm struct {
 	Arbitrators     *ArbitratorSet
 	FeeSchedule     *RealmFeeSchedule
 	Metadata        *EscrowRealmMetadata
+	// EvidenceWindowSeconds bounds how long after a dispute is opened the
+	// parties may attach evidence. Zero accepts evidence until resolution.
+	EvidenceWindowSeconds int64
+	// ArbitrationSLASeconds is how long the arbitrators have to decide a
+	// dispute before anyone may escalate it to FallbackArbitrators. Zero
+	// disables escalation.
+	ArbitrationSLASeconds int64
+	FallbackArbitrators   *ArbitratorSet
+	// MinReputationScore is the reputation score the payee must hold for an
+	// escrow to be opened in the realm. Zero disables the check.
+	MinReputationScore uint32
 }
 
 // Clone returns a deep copy of the realm definition.
@@ -101,6 +112,7 @@ func (r *EscrowRealm) Clone() *EscrowRealm {
 	}
 	clone := *r
 	clone.Arbitrators = r.Arbitrators.Clone()
+	clone.FallbackArbitrators = r.FallbackArbitrators.Clone()
 	if r.FeeSchedule != nil {
 		clone.FeeSchedule = r.FeeSchedule.Clone()
 	}
@@ -123,6 +135,10 @@ type FrozenArb struct {
 	FrozenAt     int64
 	FeeSchedule  *RealmFeeSchedule
 	Metadata     *EscrowRealmMetadata
+
+	EvidenceWindowSeconds int64
+	ArbitrationSLASeconds int64
+	Fallback              *ArbitratorSet
 }
 
 // Clone returns a deep copy of the frozen arbitrator policy.
@@ -137,6 +153,10 @@ func (f *FrozenArb) Clone() *FrozenArb {
 		Scheme:       f.Scheme,
 		Threshold:    f.Threshold,
 		FrozenAt:     f.FrozenAt,
+
+		EvidenceWindowSeconds: f.EvidenceWindowSeconds,
+		ArbitrationSLASeconds: f.ArbitrationSLASeconds,
+		Fallback:              f.Fallback.Clone(),
 	}
 	if len(f.Members) > 0 {
 		clone.Members = make([][20]byte, len(f.Members))
@@ -193,6 +213,9 @@ func (m *EscrowRealmMetadata) Clone() *EscrowRealmMetadata {
 const (
 	// EscrowRealmMaxProviderProfileLength bounds the provider profile metadata.
 	EscrowRealmMaxProviderProfileLength = 512
+	// MaxRealmReputationScore is the highest minimum a realm may demand. It
+	// matches the top of the reputation score range.
+	MaxRealmReputationScore uint32 = 1000
 )
 
 const (
@@ -214,9 +237,13 @@ var (
 )
 
 // RealmFeeSchedule captures the arbitration fee routing rules for a realm.
+// FeeBps is paid to Recipient, while ArbitratorFeeBps is shared equally by
+// the arbitrators who signed the decision. Both are deducted from the
+// disputed amount.
 type RealmFeeSchedule struct {
-	FeeBps    uint32
-	Recipient [20]byte
+	FeeBps           uint32
+	Recipient        [20]byte
+	ArbitratorFeeBps uint32
 }
 
 // Clone returns a copy safe for callers to mutate.
@@ -251,6 +278,9 @@ const (
 	EscrowRefunded
 	EscrowExpired
 	EscrowDisputed
+	// EscrowSplit marks a dispute settled by dividing the escrow between the
+	// payee and the payer.
+	EscrowSplit
 )
 
 // DecisionOutcome enumerates the possible arbitration outcomes supported by the
@@ -261,13 +291,14 @@ const (
 	DecisionOutcomeUnknown DecisionOutcome = iota
 	DecisionOutcomeRelease
 	DecisionOutcomeRefund
+	DecisionOutcomeSplit
 )
 
 // Valid reports whether the outcome represents a supported arbitration
 // decision.
 func (o DecisionOutcome) Valid() bool {
 	switch o {
-	case DecisionOutcomeRelease, DecisionOutcomeRefund:
+	case DecisionOutcomeRelease, DecisionOutcomeRefund, DecisionOutcomeSplit:
 		return true
 	default:
 		return false
@@ -281,6 +312,8 @@ func (o DecisionOutcome) String() string {
 		return "release"
 	case DecisionOutcomeRefund:
 		return "refund"
+	case DecisionOutcomeSplit:
+		return "split"
 	default:
 		return "unknown"
 	}
@@ -294,11 +327,83 @@ func ParseDecisionOutcome(outcome string) (DecisionOutcome, error) {
 		return DecisionOutcomeRelease, nil
 	case "refund":
 		return DecisionOutcomeRefund, nil
+	case "split":
+		return DecisionOutcomeSplit, nil
 	default:
 		return DecisionOutcomeUnknown, fmt.Errorf("escrow: invalid resolution outcome %s", outcome)
 	}
 }
 
+// SplitDecision divides a disputed escrow between its payee and payer. Both
+// shares are basis points of the escrowed amount and must add up to 10 000.
+type SplitDecision struct {
+	PayeeBps uint32
+	PayerBps uint32
+}
+
+// Validate reports whether the shares cover exactly the whole escrow.
+func (s SplitDecision) Validate() error {
+	if total := uint64(s.PayeeBps) + uint64(s.PayerBps); total != 10_000 {
+		return fmt.Errorf("escrow: split shares must total 10000 bps, got %d", total)
+	}
+	return nil
+}
+
+// SplitPayout reports how a split decision divided an escrow. PayeeAmount and
+// PayerAmount are the net amounts paid out after the escrow fee and the realm
+// fee were taken from each share.
+type SplitPayout struct {
+	PayeeAmount   *big.Int
+	PayerAmount   *big.Int
+	Fee           *big.Int
+	RealmFee      *big.Int
+	ArbitratorFee *big.Int
+}
+
+// ArbitratorPayout records the part of an arbitrator fee paid to one signer.
+type ArbitratorPayout struct {
+	Arbitrator [20]byte
+	Amount     *big.Int
+}
+
+// SplitArbitratorFee divides fee equally among the signers in address order.
+// Any remainder left by the integer division goes to the first signer.
+func SplitArbitratorFee(fee *big.Int, signers [][20]byte) []ArbitratorPayout {
+	if fee == nil || fee.Sign() <= 0 || len(signers) == 0 {
+		return nil
+	}
+	ordered := (&ArbitratorSet{Members: signers}).SortedMembers()
+	share, remainder := new(big.Int).QuoRem(fee, big.NewInt(int64(len(ordered))), new(big.Int))
+	payouts := make([]ArbitratorPayout, 0, len(ordered))
+	for i, signer := range ordered {
+		amount := new(big.Int).Set(share)
+		if i == 0 {
+			amount.Add(amount, remainder)
+		}
+		if amount.Sign() == 0 {
+			continue
+		}
+		payouts = append(payouts, ArbitratorPayout{Arbitrator: signer, Amount: amount})
+	}
+	return payouts
+}
+
+const (
+	// MaxEscrowEvidence bounds the number of evidence entries per escrow.
+	MaxEscrowEvidence = 16
+	// MaxEvidenceURILength bounds the URI attached to an evidence entry.
+	MaxEvidenceURILength = 256
+)
+
+// EscrowEvidence references material a party submitted for a dispute. Only
+// the hash and an optional locator are stored on-chain.
+type EscrowEvidence struct {
+	Submitter   [20]byte
+	Hash        [32]byte
+	URI         string
+	SubmittedAt int64
+}
+
 // Escrow captures the immutable metadata and runtime status of a single escrow
 // agreement managed by the native engine. The identifier is the keccak256 hash
 // of the payer, payee and a caller-supplied nonce, ensuring deterministic IDs
@@ -320,6 +425,11 @@ type Escrow struct {
 	FrozenArb      *FrozenArb
 	ResolutionHash [32]byte
 	DisputeReason  string
+	DisputedAt     int64
+	// EscalatedAt is set once a dispute outlived the arbitration SLA and was
+	// handed to the fallback arbitrators of the frozen policy.
+	EscalatedAt int64
+	Evidence    []EscrowEvidence
 }
 
 // Clone returns a deep copy of the escrow object so callers can safely mutate
@@ -337,13 +447,16 @@ func (e *Escrow) Clone() *Escrow {
 	if e.FrozenArb != nil {
 		clone.FrozenArb = e.FrozenArb.Clone()
 	}
+	if len(e.Evidence) > 0 {
+		clone.Evidence = append([]EscrowEvidence(nil), e.Evidence...)
+	}
 	return &clone
 }
 
 // Valid reports whether the status value is within the supported range.
 func (s EscrowStatus) Valid() bool {
 	switch s {
-	case EscrowInit, EscrowFunded, EscrowReleased, EscrowRefunded, EscrowExpired, EscrowDisputed:
+	case EscrowInit, EscrowFunded, EscrowReleased, EscrowRefunded, EscrowExpired, EscrowDisputed, EscrowSplit:
 		return true
 	default:
 		return false
@@ -392,6 +505,43 @@ func NormalizeToken(symbol string) (string, error) {
 	return defaultTokenRegistry.normalize(symbol)
 }
 
+// isNativeToken reports whether symbol is one of the chain's own tokens, whose
+// balances live on the account rather than in the issued asset ledger.
+func isNativeToken(symbol string) bool {
+	return symbol == "NHB" || symbol == "ZNHB"
+}
+
+// IsAssetSymbol reports whether symbol is a well-formed issued asset symbol:
+// an uppercase letter followed by 1-11 uppercase letters or digits.
+func IsAssetSymbol(symbol string) bool {
+	if len(symbol) < 2 || len(symbol) > 12 {
+		return false
+	}
+	for i, r := range symbol {
+		switch {
+		case r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeAsset accepts NHB, ZNHB or any well-formed issued asset symbol and
+// returns the canonical uppercase form. It does not check that an issued asset
+// is registered; the state layer does that before moving funds.
+func NormalizeAsset(symbol string) (string, error) {
+	if normalized, err := NormalizeToken(symbol); err == nil {
+		return normalized, nil
+	}
+	trimmed := strings.ToUpper(strings.TrimSpace(symbol))
+	if !IsAssetSymbol(trimmed) {
+		return "", fmt.Errorf("unsupported escrow token: %s", symbol)
+	}
+	return trimmed, nil
+}
+
 // SanitizeEscrow validates and normalises the supplied escrow definition,
 // returning a cloned instance with canonical token casing and a non-nil amount
 // field. The function does not mutate the original value.
@@ -400,7 +550,7 @@ func SanitizeEscrow(e *Escrow) (*Escrow, error) {
 		return nil, fmt.Errorf("nil escrow")
 	}
 	clone := e.Clone()
-	token, err := NormalizeToken(clone.Token)
+	token, err := NormalizeAsset(clone.Token)
 	if err != nil {
 		return nil, err
 	}
@@ -542,6 +692,14 @@ func SanitizeEscrowRealm(realm *EscrowRealm) (*EscrowRealm, error) {
 		}
 		clone.FeeSchedule = schedule
 	}
+	fallback, err := sanitizeDisputePolicy(clone.EvidenceWindowSeconds, clone.ArbitrationSLASeconds, clone.FallbackArbitrators)
+	if err != nil {
+		return nil, fmt.Errorf("realm %w", err)
+	}
+	clone.FallbackArbitrators = fallback
+	if clone.MinReputationScore > MaxRealmReputationScore {
+		return nil, fmt.Errorf("realm minimum reputation score exceeds %d", MaxRealmReputationScore)
+	}
 	if clone.Metadata == nil {
 		return nil, fmt.Errorf("realm metadata required")
 	}
@@ -596,6 +754,11 @@ func SanitizeFrozenArb(frozen *FrozenArb) (*FrozenArb, error) {
 		}
 		clone.FeeSchedule = schedule
 	}
+	fallback, err := sanitizeDisputePolicy(clone.EvidenceWindowSeconds, clone.ArbitrationSLASeconds, clone.Fallback)
+	if err != nil {
+		return nil, fmt.Errorf("frozen policy %w", err)
+	}
+	clone.Fallback = fallback
 	if clone.Metadata == nil {
 		return nil, fmt.Errorf("frozen policy metadata required")
 	}
@@ -619,5 +782,33 @@ func SanitizeRealmFeeSchedule(schedule *RealmFeeSchedule) (*RealmFeeSchedule, er
 	if clone.FeeBps > 0 && clone.Recipient == ([20]byte{}) {
 		return nil, fmt.Errorf("realm arbitration fee recipient required")
 	}
+	if total := uint64(clone.FeeBps) + uint64(clone.ArbitratorFeeBps); total > 10_000 {
+		return nil, fmt.Errorf("realm arbitration fees out of range: %d bps", total)
+	}
 	return clone, nil
 }
+
+// sanitizeDisputePolicy validates the evidence window, arbitration SLA and
+// fallback arbitrators shared by realms and frozen policies.
+func sanitizeDisputePolicy(evidenceWindow, sla int64, fallback *ArbitratorSet) (*ArbitratorSet, error) {
+	if evidenceWindow < 0 {
+		return nil, fmt.Errorf("evidence window must not be negative")
+	}
+	if sla < 0 {
+		return nil, fmt.Errorf("arbitration sla must not be negative")
+	}
+	if fallback == nil {
+		if sla > 0 {
+			return nil, fmt.Errorf("arbitration sla requires fallback arbitrators")
+		}
+		return nil, nil
+	}
+	if sla == 0 {
+		return nil, fmt.Errorf("fallback arbitrators require an arbitration sla")
+	}
+	sanitized, err := SanitizeArbitratorSet(fallback)
+	if err != nil {
+		return nil, fmt.Errorf("fallback arbitrators: %w", err)
+	}
+	return sanitized, nil
+}
@@ -1,10 +1,13 @@
 package escrow
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/big"
 	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 )
 
 // MilestoneStatus represents the lifecycle of a milestone project.
@@ -41,8 +44,16 @@ const (
 	// to release.
 	MilestoneLegCancelled
 	// MilestoneLegExpired indicates the leg was not released before the
-	// deadline elapsed and requires dispute handling off-chain.
+	// deadline elapsed. Projects without a frozen arbitrator policy refund the
+	// payer on expiry; arbitrated projects keep the funds in the vault until
+	// the payer cancels the leg or a dispute is resolved.
 	MilestoneLegExpired
+	// MilestoneLegDisputed indicates either party disputed a funded or
+	// expired leg and the funds await an arbitrator decision.
+	MilestoneLegDisputed
+	// MilestoneLegSplit indicates the arbitrators divided the leg between the
+	// payee and the payer.
+	MilestoneLegSplit
 )
 
 // MilestoneLegType describes the semantic meaning of the leg within a project.
@@ -76,6 +87,11 @@ type MilestoneLeg struct {
 	FundedAt    int64
 	ReleasedAt  int64
 	CancelledAt int64
+	// DisputedAt, DisputeReason and ResolutionHash track on-chain disputes.
+	// ResolutionHash is the keccak256 hash of the applied decision payload.
+	DisputedAt     int64
+	DisputeReason  string
+	ResolutionHash [32]byte
 }
 
 // Clone returns a deep copy of the leg to avoid callers mutating shared state.
@@ -122,6 +138,9 @@ type MilestoneProject struct {
 	Legs         []*MilestoneLeg
 	Metadata     []byte
 	Subscription *MilestoneSubscription
+	// FrozenArb is the realm arbitrator policy captured when the project was
+	// created. Only projects with a frozen policy can have legs disputed.
+	FrozenArb *FrozenArb
 }
 
 // Clone returns a deep copy of the project.
@@ -141,6 +160,7 @@ func (p *MilestoneProject) Clone() *MilestoneProject {
 		copy(clone.Metadata, p.Metadata)
 	}
 	clone.Subscription = p.Subscription.Clone()
+	clone.FrozenArb = p.FrozenArb.Clone()
 	return &clone
 }
 
@@ -157,6 +177,29 @@ func (p *MilestoneProject) FindLeg(id uint64) *MilestoneLeg {
 	return nil
 }
 
+// LegHoldsFunds reports whether the leg's vault still holds its amount.
+func (p *MilestoneProject) LegHoldsFunds(leg *MilestoneLeg) bool {
+	if p == nil || leg == nil {
+		return false
+	}
+	switch leg.Status {
+	case MilestoneLegFunded, MilestoneLegDisputed:
+		return true
+	case MilestoneLegExpired:
+		return p.FrozenArb != nil
+	default:
+		return false
+	}
+}
+
+// MilestoneDecisionID returns the identifier arbitrators sign as escrowId in a
+// decision payload for one leg of a milestone project.
+func MilestoneDecisionID(projectID [32]byte, legID uint64) [32]byte {
+	var legBuf [8]byte
+	binary.BigEndian.PutUint64(legBuf[:], legID)
+	return ethcrypto.Keccak256Hash([]byte("milestone"), projectID[:], legBuf[:])
+}
+
 // MilestoneSubscription defines an optional recurring payment contract for a
 // project. Time-boxed legs can be generated from subscription checkpoints.
 type MilestoneSubscription struct {
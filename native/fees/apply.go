@@ -11,6 +11,11 @@ import (
 // DomainPOS identifies point-of-sale payment flows.
 const DomainPOS = "pos"
 
+// DomainIdentity identifies identity alias renewal fees. Renewal fees are
+// flat amounts set by the identity terms; the domain supplies the route
+// wallet and the fee totals they are recorded under.
+const DomainIdentity = "identity"
+
 // Default configuration values applied when policies omit explicit settings.
 const (
 	DefaultFreeTierTxPerMonth = uint64(100)
@@ -2,6 +2,7 @@ package governance
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -75,6 +76,8 @@ type proposalState interface {
 	RemoveRole(role string, addr []byte) error
 	SwapSetPriceSigner(provider string, addr [20]byte) error
 	SwapClearPriceSigner(provider string) error
+	UpgradeSchedule(plan *UpgradePlan) error
+	UpgradeApplied(name string) (bool, error)
 	PotsoRewardsLastProcessedEpoch() (uint64, bool, error)
 	SnapshotPotsoWeights(epoch uint64) (*potso.StoredWeightSnapshot, bool, error)
 }
@@ -181,6 +184,13 @@ type parsedSwapPriceSigner struct {
 	memo     string
 }
 
+type parsedSoftwareUpgrade struct {
+	name     string
+	height   uint64
+	checksum string
+	info     string
+}
+
 type paramValidator func(raw json.RawMessage) error
 
 // SetState wires the engine to the state backend providing persistence helpers.
@@ -1047,6 +1057,50 @@ func parseBuybackParamsPayload(payloadJSON string) (*BuybackParamsPayload, error
 	return &payload, nil
 }
 
+// maxUpgradeNameLen bounds upgrade plan names, which double as the key
+// binaries register their migration handlers under.
+const maxUpgradeNameLen = 64
+
+// parseSoftwareUpgradePayload validates a ProposalKindSoftwareUpgrade
+// payload. Names are restricted to lowercase letters, digits, '.', '-' and
+// '_' so they stay usable as handler keys and in log output. Whether the
+// height is still in the future is checked by the node when the plan is
+// executed, since the engine has no view of the chain height.
+func parseSoftwareUpgradePayload(payloadJSON string) (*parsedSoftwareUpgrade, error) {
+	var payload SoftwareUpgradePayload
+	if err := json.Unmarshal([]byte(payloadJSON), &payload); err != nil {
+		return nil, fmt.Errorf("governance: invalid payload: %w", err)
+	}
+	name := strings.TrimSpace(payload.Name)
+	if name == "" {
+		return nil, fmt.Errorf("governance: upgrade name must not be empty")
+	}
+	if len(name) > maxUpgradeNameLen {
+		return nil, fmt.Errorf("governance: upgrade name must be <= %d characters", maxUpgradeNameLen)
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+		default:
+			return nil, fmt.Errorf("governance: upgrade name contains invalid character %q", r)
+		}
+	}
+	if payload.Height == 0 {
+		return nil, fmt.Errorf("governance: upgrade height must be greater than zero")
+	}
+	checksum := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(payload.Checksum), "0x"))
+	decoded, err := hex.DecodeString(checksum)
+	if err != nil || len(decoded) != sha256.Size {
+		return nil, fmt.Errorf("governance: checksum must be a hex-encoded sha256 digest")
+	}
+	return &parsedSoftwareUpgrade{
+		name:     name,
+		height:   payload.Height,
+		checksum: checksum,
+		info:     strings.TrimSpace(payload.Info),
+	}, nil
+}
+
 func (e *Engine) parseRoleAllowlistPayload(payloadJSON string) (*parsedRoleAllowlist, error) {
 	if len(e.allowedRoles) == 0 {
 		return nil, fmt.Errorf("governance: role allowlist proposals are disabled")
@@ -1289,6 +1343,41 @@ func (e *Engine) applySwapPriceSignerUpdate(parsed *parsedSwapPriceSigner) (map[
 	return detail, nil
 }
 
+// applySoftwareUpgrade persists the upgrade plan. Plans whose name was
+// already applied are rejected so a finished migration can never be
+// scheduled to run twice.
+func (e *Engine) applySoftwareUpgrade(proposalID uint64, parsed *parsedSoftwareUpgrade) (map[string]interface{}, error) {
+	if parsed == nil {
+		return nil, fmt.Errorf("governance: nil software upgrade payload")
+	}
+	applied, err := e.state.UpgradeApplied(parsed.name)
+	if err != nil {
+		return nil, err
+	}
+	if applied {
+		return nil, fmt.Errorf("governance: upgrade %q already applied", parsed.name)
+	}
+	plan := &UpgradePlan{
+		Name:       parsed.name,
+		Height:     parsed.height,
+		Checksum:   parsed.checksum,
+		Info:       parsed.info,
+		ProposalID: proposalID,
+	}
+	if err := e.state.UpgradeSchedule(plan); err != nil {
+		return nil, err
+	}
+	detail := map[string]interface{}{
+		"name":     plan.Name,
+		"height":   plan.Height,
+		"checksum": plan.Checksum,
+	}
+	if plan.Info != "" {
+		detail["info"] = plan.Info
+	}
+	return detail, nil
+}
+
 func (e *Engine) applyTreasuryDirective(parsed *parsedTreasuryDirective) (map[string]interface{}, error) {
 	if parsed == nil {
 		return nil, fmt.Errorf("governance: nil treasury directive")
@@ -1508,6 +1597,10 @@ func (e *Engine) SubmitProposal(proposer [20]byte, kind string, payloadJSON stri
 		if _, err := parseBuybackParamsPayload(payloadJSON); err != nil {
 			return 0, err
 		}
+	case ProposalKindSoftwareUpgrade:
+		if _, err := parseSoftwareUpgradePayload(payloadJSON); err != nil {
+			return 0, err
+		}
 	default:
 		return 0, fmt.Errorf("governance: unsupported proposal kind %q", kind)
 	}
@@ -1969,6 +2062,18 @@ func (e *Engine) Execute(proposalID uint64) error {
 		for k, v := range buybackDetail {
 			detail[k] = v
 		}
+	case ProposalKindSoftwareUpgrade:
+		parsed, err := parseSoftwareUpgradePayload(proposal.ProposedChange)
+		if err != nil {
+			return err
+		}
+		upgradeDetail, err := e.applySoftwareUpgrade(proposalID, parsed)
+		if err != nil {
+			return err
+		}
+		for k, v := range upgradeDetail {
+			detail[k] = v
+		}
 	default:
 		return fmt.Errorf("governance: proposal %d has unsupported target %q", proposalID, proposal.Target)
 	}
@@ -27,6 +27,8 @@ type mockGovernanceState struct {
 	params         map[string][]byte
 	roles          map[string]map[string]struct{}
 	swapSigners    map[string][20]byte
+	upgradePlan    *UpgradePlan
+	upgradesDone   map[string]bool
 	audit          []*AuditRecord
 }
 
@@ -125,6 +127,7 @@ func newMockGovernanceState(initial map[[20]byte]*types.Account) *mockGovernance
 		params:         make(map[string][]byte),
 		roles:          make(map[string]map[string]struct{}),
 		swapSigners:    make(map[string][20]byte),
+		upgradesDone:   make(map[string]bool),
 	}
 }
 
@@ -297,6 +300,19 @@ func (m *mockGovernanceState) SwapClearPriceSigner(provider string) error {
 	return nil
 }
 
+func (m *mockGovernanceState) UpgradeSchedule(plan *UpgradePlan) error {
+	if plan == nil {
+		return fmt.Errorf("plan must not be nil")
+	}
+	clone := *plan
+	m.upgradePlan = &clone
+	return nil
+}
+
+func (m *mockGovernanceState) UpgradeApplied(name string) (bool, error) {
+	return m.upgradesDone[strings.TrimSpace(name)], nil
+}
+
 func (m *mockGovernanceState) GovernanceAppendAudit(r *AuditRecord) (*AuditRecord, error) {
 	if r == nil {
 		return nil, fmt.Errorf("audit record must not be nil")
@@ -1569,6 +1585,92 @@ func TestParseSwapPriceSignerPayloadRequiresProvider(t *testing.T) {
 	}
 }
 
+func TestExecuteSoftwareUpgradeProposal(t *testing.T) {
+	var proposer [20]byte
+	proposer[2] = 9
+
+	state := newMockGovernanceState(map[[20]byte]*types.Account{
+		proposer: &types.Account{BalanceZNHB: big.NewInt(1000), BalanceNHB: big.NewInt(0), Stake: big.NewInt(0)},
+	})
+
+	engine := NewEngine()
+	engine.SetState(state)
+	engine.SetPolicy(ProposalPolicy{
+		MinDepositWei:       big.NewInt(50),
+		VotingPeriodSeconds: 60,
+		TimelockSeconds:     10,
+		AllowedParams:       []string{"fees.baseFee"},
+	})
+	now := time.Unix(1_700_400_000, 0).UTC()
+	engine.SetNowFunc(func() time.Time { return now })
+
+	checksum := strings.Repeat("ab", 32)
+	payload := fmt.Sprintf(`{"name":"v2.1.0","height":5000,"checksum":"0x%s","info":"release notes"}`, checksum)
+	proposalID, err := engine.SubmitProposal(proposer, ProposalKindSoftwareUpgrade, payload, big.NewInt(75))
+	if err != nil {
+		t.Fatalf("submit software upgrade proposal: %v", err)
+	}
+	proposal := state.proposals[proposalID]
+	proposal.Status = ProposalStatusPassed
+	if err := engine.QueueExecution(proposalID); err != nil {
+		t.Fatalf("queue software upgrade proposal: %v", err)
+	}
+	proposal = state.proposals[proposalID]
+	proposal.TimelockEnd = now.Add(-time.Second)
+	engine.SetNowFunc(func() time.Time { return now.Add(time.Minute) })
+	if err := engine.Execute(proposalID); err != nil {
+		t.Fatalf("execute software upgrade proposal: %v", err)
+	}
+
+	plan := state.upgradePlan
+	if plan == nil {
+		t.Fatalf("expected upgrade plan to be scheduled")
+	}
+	if plan.Name != "v2.1.0" || plan.Height != 5000 || plan.Checksum != checksum || plan.ProposalID != proposalID {
+		t.Fatalf("unexpected upgrade plan: %+v", plan)
+	}
+
+	state.upgradesDone["v2.1.0"] = true
+	againID, err := engine.SubmitProposal(proposer, ProposalKindSoftwareUpgrade, payload, big.NewInt(75))
+	if err != nil {
+		t.Fatalf("submit repeat proposal: %v", err)
+	}
+	again := state.proposals[againID]
+	again.Status = ProposalStatusPassed
+	if err := engine.QueueExecution(againID); err != nil {
+		t.Fatalf("queue repeat proposal: %v", err)
+	}
+	state.proposals[againID].TimelockEnd = now.Add(-time.Second)
+	if err := engine.Execute(againID); err == nil {
+		t.Fatalf("expected applied upgrade name to be rejected")
+	}
+}
+
+func TestParseSoftwareUpgradePayloadValidation(t *testing.T) {
+	checksum := strings.Repeat("0f", 32)
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{name: "empty name", payload: fmt.Sprintf(`{"name":"","height":10,"checksum":"%s"}`, checksum)},
+		{name: "invalid name", payload: fmt.Sprintf(`{"name":"V2 Final","height":10,"checksum":"%s"}`, checksum)},
+		{name: "zero height", payload: fmt.Sprintf(`{"name":"v2","height":0,"checksum":"%s"}`, checksum)},
+		{name: "short checksum", payload: `{"name":"v2","height":10,"checksum":"abcd"}`},
+	}
+	for _, tc := range tests {
+		if _, err := parseSoftwareUpgradePayload(tc.payload); err == nil {
+			t.Fatalf("%s: expected validation error", tc.name)
+		}
+	}
+	parsed, err := parseSoftwareUpgradePayload(fmt.Sprintf(`{"name":"v2","height":10,"checksum":"%s"}`, strings.ToUpper(checksum)))
+	if err != nil {
+		t.Fatalf("parse valid payload: %v", err)
+	}
+	if parsed.checksum != checksum {
+		t.Fatalf("expected normalised checksum, got %s", parsed.checksum)
+	}
+}
+
 func TestExecuteTreasuryDirective(t *testing.T) {
 	var proposer [20]byte
 	proposer[8] = 1
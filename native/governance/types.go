@@ -172,6 +172,12 @@ const (
 	// delays that but does not prevent it. See core/tokenomics/buyback's
 	// package doc comment for the full rationale.
 	ProposalKindBuybackParams = "policy.buybackParams"
+	// ProposalKindSoftwareUpgrade schedules a coordinated binary upgrade.
+	// Nodes halt once the block at the plan height is committed; the next
+	// block is only produced by binaries that register a migration handler
+	// for the plan name (core.RegisterUpgradeHandler). Scheduling a new plan
+	// replaces any plan that has not been applied yet.
+	ProposalKindSoftwareUpgrade = "software.upgrade"
 )
 
 const (
@@ -346,6 +352,26 @@ type BuybackParamsPayload struct {
 	Memo            string `json:"memo,omitempty"`
 }
 
+// SoftwareUpgradePayload defines the expected schema for
+// ProposalKindSoftwareUpgrade proposals. Checksum is the hex-encoded SHA-256
+// digest of the release binary operators must install before Height.
+type SoftwareUpgradePayload struct {
+	Name     string `json:"name"`
+	Height   uint64 `json:"height"`
+	Checksum string `json:"checksum"`
+	Info     string `json:"info,omitempty"`
+}
+
+// UpgradePlan is the scheduled software upgrade persisted once a
+// ProposalKindSoftwareUpgrade proposal executes.
+type UpgradePlan struct {
+	Name       string `json:"name"`
+	Height     uint64 `json:"height"`
+	Checksum   string `json:"checksum"`
+	Info       string `json:"info,omitempty"`
+	ProposalID uint64 `json:"proposalId"`
+}
+
 // RoleAddressPair captures a role membership mutation in role allowlist
 // proposals.
 type RoleAddressPair struct {
@@ -0,0 +1,336 @@
+package lending
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"nhbchain/core/types"
+)
+
+const (
+	// AssetNHB identifies the NHB balance of an account.
+	AssetNHB = "NHB"
+	// AssetZNHB identifies the ZNHB balance of an account.
+	AssetZNHB = "ZNHB"
+)
+
+var (
+	errUnsupportedAsset      = errors.New("lending engine: unsupported asset")
+	errUnsupportedCollateral = errors.New("lending engine: asset not accepted as collateral")
+	errCollateralCap         = errors.New("lending engine: collateral deposit exceeds supply cap")
+	errPriceUnavailable      = errors.New("lending engine: oracle price unavailable")
+)
+
+// NormalizeAsset canonicalises an asset symbol. Empty symbols stay empty so
+// callers can apply their own default.
+func NormalizeAsset(asset string) string {
+	return strings.ToUpper(strings.TrimSpace(asset))
+}
+
+// ValidAsset reports whether asset names a balance the lending engine can move.
+func ValidAsset(asset string) bool {
+	switch NormalizeAsset(asset) {
+	case AssetNHB, AssetZNHB:
+		return true
+	default:
+		return false
+	}
+}
+
+// CollateralAsset configures one asset accepted as collateral by a market.
+type CollateralAsset struct {
+	// Asset is the collateral symbol, for example ZNHB.
+	Asset string
+	// MaxLTV bounds borrowing against this asset, expressed in basis points.
+	MaxLTV uint64
+	// LiquidationThreshold is the share of this asset's value that counts
+	// towards the health factor, expressed in basis points.
+	LiquidationThreshold uint64
+	// OracleFeed names the on-chain price feed for the asset. An empty feed
+	// values the asset 1:1 against the market's borrow asset.
+	OracleFeed string
+	// SupplyCap bounds the total amount of the asset pledged to the market.
+	// Nil or zero disables the cap.
+	SupplyCap *big.Int
+}
+
+// Clone returns a deep copy of the collateral configuration.
+func (c CollateralAsset) Clone() CollateralAsset {
+	clone := c
+	if c.SupplyCap != nil {
+		clone.SupplyCap = new(big.Int).Set(c.SupplyCap)
+	}
+	return clone
+}
+
+// ValidateMarketAssets checks the borrow asset and collateral configuration of
+// a market before it is created. Collateral assets must be distinct and each
+// MaxLTV may not exceed its LiquidationThreshold.
+func ValidateMarketAssets(market *Market) error {
+	if market == nil {
+		return errNilMarket
+	}
+	borrow := NormalizeAsset(market.BorrowAsset)
+	if borrow == "" {
+		borrow = AssetNHB
+	}
+	if !ValidAsset(borrow) {
+		return fmt.Errorf("%w: borrow asset %q", errUnsupportedAsset, market.BorrowAsset)
+	}
+	seen := make(map[string]struct{}, len(market.Collateral))
+	for _, cfg := range market.Collateral {
+		asset := NormalizeAsset(cfg.Asset)
+		if !ValidAsset(asset) {
+			return fmt.Errorf("%w: collateral asset %q", errUnsupportedAsset, cfg.Asset)
+		}
+		if _, dup := seen[asset]; dup {
+			return fmt.Errorf("lending: collateral asset %s listed twice", asset)
+		}
+		seen[asset] = struct{}{}
+		if cfg.LiquidationThreshold == 0 || cfg.LiquidationThreshold > 10_000 {
+			return fmt.Errorf("lending: collateral %s liquidation threshold must be within (0, 10000] bps", asset)
+		}
+		if cfg.MaxLTV > cfg.LiquidationThreshold {
+			return fmt.Errorf("lending: collateral %s max LTV exceeds its liquidation threshold", asset)
+		}
+		if cfg.SupplyCap != nil && cfg.SupplyCap.Sign() < 0 {
+			return fmt.Errorf("lending: collateral %s supply cap must not be negative", asset)
+		}
+	}
+	return nil
+}
+
+// PriceSource resolves prices published on-chain for oracle feeds.
+type PriceSource interface {
+	// FeedPrice returns the latest price for feed, or ok=false when none was
+	// published within maxAgeSeconds. A zero maxAgeSeconds disables the age
+	// check.
+	FeedPrice(feed string, maxAgeSeconds uint64) (*big.Rat, bool, error)
+}
+
+// CollateralOf returns the amount of asset pledged as collateral.
+func (u *UserAccount) CollateralOf(asset string) *big.Int {
+	if u == nil || u.Collateral == nil {
+		return big.NewInt(0)
+	}
+	amount := u.Collateral[NormalizeAsset(asset)]
+	if amount == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(amount)
+}
+
+// SetCollateral records amount of asset as pledged collateral. Zero amounts
+// remove the entry.
+func (u *UserAccount) SetCollateral(asset string, amount *big.Int) {
+	if u == nil {
+		return
+	}
+	asset = NormalizeAsset(asset)
+	if amount == nil || amount.Sign() <= 0 {
+		delete(u.Collateral, asset)
+		return
+	}
+	if u.Collateral == nil {
+		u.Collateral = make(map[string]*big.Int)
+	}
+	u.Collateral[asset] = new(big.Int).Set(amount)
+}
+
+// CollateralAssets returns the assets the account has pledged, sorted by
+// symbol.
+func (u *UserAccount) CollateralAssets() []string {
+	if u == nil {
+		return nil
+	}
+	assets := make([]string, 0, len(u.Collateral))
+	for asset, amount := range u.Collateral {
+		if amount != nil && amount.Sign() > 0 {
+			assets = append(assets, asset)
+		}
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// collateralTotal returns the amount of asset pledged across the market.
+func (m *Market) collateralTotal(asset string) *big.Int {
+	if m == nil || m.TotalCollateral == nil {
+		return big.NewInt(0)
+	}
+	total := m.TotalCollateral[NormalizeAsset(asset)]
+	if total == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(total)
+}
+
+func (m *Market) setCollateralTotal(asset string, total *big.Int) {
+	asset = NormalizeAsset(asset)
+	if total == nil || total.Sign() <= 0 {
+		delete(m.TotalCollateral, asset)
+		return
+	}
+	if m.TotalCollateral == nil {
+		m.TotalCollateral = make(map[string]*big.Int)
+	}
+	m.TotalCollateral[asset] = new(big.Int).Set(total)
+}
+
+// withCollateral returns a copy of collateral with asset set to amount, used
+// to check the health of a projected position.
+func withCollateral(collateral map[string]*big.Int, asset string, amount *big.Int) map[string]*big.Int {
+	projected := make(map[string]*big.Int, len(collateral)+1)
+	for key, value := range collateral {
+		projected[key] = value
+	}
+	projected[NormalizeAsset(asset)] = amount
+	return projected
+}
+
+func (e *Engine) borrowAsset(market *Market) string {
+	if asset := NormalizeAsset(market.BorrowAsset); asset != "" {
+		return asset
+	}
+	return AssetNHB
+}
+
+// collateralAssets returns the collateral accepted by market. Markets created
+// before multi-asset support accept ZNHB under the node-wide risk parameters.
+func (e *Engine) collateralAssets(market *Market) []CollateralAsset {
+	if len(market.Collateral) > 0 {
+		return market.Collateral
+	}
+	return []CollateralAsset{{
+		Asset:                AssetZNHB,
+		MaxLTV:               e.params.MaxLTV,
+		LiquidationThreshold: e.params.LiquidationThreshold,
+	}}
+}
+
+// collateralConfig resolves the configuration for asset. An empty asset
+// selects the market's only collateral asset when it accepts exactly one.
+func (e *Engine) collateralConfig(market *Market, asset string) (CollateralAsset, error) {
+	assets := e.collateralAssets(market)
+	asset = NormalizeAsset(asset)
+	if asset == "" && len(assets) == 1 {
+		return assets[0], nil
+	}
+	for _, cfg := range assets {
+		if NormalizeAsset(cfg.Asset) == asset {
+			cfg.Asset = asset
+			return cfg, nil
+		}
+	}
+	return CollateralAsset{}, errUnsupportedCollateral
+}
+
+// collateralRate returns the value of one unit of the collateral asset in
+// units of the market's borrow asset.
+func (e *Engine) collateralRate(market *Market, cfg CollateralAsset) (*big.Rat, error) {
+	price, err := e.feedPrice(market, cfg.OracleFeed)
+	if err != nil {
+		return nil, err
+	}
+	borrowPrice, err := e.feedPrice(market, market.BorrowOracleFeed)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).Quo(price, borrowPrice), nil
+}
+
+func (e *Engine) feedPrice(market *Market, feed string) (*big.Rat, error) {
+	feed = strings.TrimSpace(feed)
+	if feed == "" {
+		return big.NewRat(1, 1), nil
+	}
+	if e.prices == nil {
+		return nil, errPriceUnavailable
+	}
+	price, ok, err := e.prices.FeedPrice(feed, market.OracleMaxAgeSeconds)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || price == nil || price.Sign() <= 0 {
+		return nil, errPriceUnavailable
+	}
+	return price, nil
+}
+
+// weightedCollateral sums the value of the pledged collateral in the borrow
+// asset, each asset scaled by the basis-point weight returned for its
+// configuration. Pledged assets the market no longer accepts count as zero.
+func (e *Engine) weightedCollateral(market *Market, collateral map[string]*big.Int, weight func(CollateralAsset) uint64) (*big.Int, error) {
+	total := big.NewInt(0)
+	for _, cfg := range e.collateralAssets(market) {
+		amount := collateral[NormalizeAsset(cfg.Asset)]
+		if amount == nil || amount.Sign() <= 0 {
+			continue
+		}
+		bps := weight(cfg)
+		if bps == 0 {
+			continue
+		}
+		rate, err := e.collateralRate(market, cfg)
+		if err != nil {
+			return nil, err
+		}
+		value := valueAt(amount, rate)
+		total.Add(total, value.Mul(value, new(big.Int).SetUint64(bps)))
+	}
+	return total, nil
+}
+
+// valueAt converts amount of an asset into borrow-asset units at rate,
+// rounding down.
+func valueAt(amount *big.Int, rate *big.Rat) *big.Int {
+	value := new(big.Int).Mul(amount, rate.Num())
+	return value.Quo(value, rate.Denom())
+}
+
+// unitsAt converts a borrow-asset value into units of an asset priced at
+// rate, rounding down.
+func unitsAt(value *big.Int, rate *big.Rat) *big.Int {
+	units := new(big.Int).Mul(value, rate.Denom())
+	return units.Quo(units, rate.Num())
+}
+
+func accountBalance(acc *types.Account, asset string) (*big.Int, error) {
+	switch NormalizeAsset(asset) {
+	case AssetNHB:
+		return acc.BalanceNHB, nil
+	case AssetZNHB:
+		return acc.BalanceZNHB, nil
+	default:
+		return nil, errUnsupportedAsset
+	}
+}
+
+// requireBalance returns shortfall when acc holds less than amount of asset.
+func requireBalance(acc *types.Account, asset string, amount *big.Int, shortfall error) error {
+	balance, err := accountBalance(acc, asset)
+	if err != nil {
+		return err
+	}
+	if balance == nil || balance.Cmp(amount) < 0 {
+		return shortfall
+	}
+	return nil
+}
+
+func transferBalance(from, to *types.Account, asset string, amount *big.Int) error {
+	switch NormalizeAsset(asset) {
+	case AssetNHB:
+		from.BalanceNHB = new(big.Int).Sub(from.BalanceNHB, amount)
+		to.BalanceNHB = new(big.Int).Add(to.BalanceNHB, amount)
+	case AssetZNHB:
+		from.BalanceZNHB = new(big.Int).Sub(from.BalanceZNHB, amount)
+		to.BalanceZNHB = new(big.Int).Add(to.BalanceZNHB, amount)
+	default:
+		return errUnsupportedAsset
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import "math/big"
 type Config struct {
 	MaxLTVBps               uint64                  `toml:"MaxLTVBps"`
 	LiquidationThresholdBps uint64                  `toml:"LiquidationThresholdBps"`
+	CloseFactorBps          uint64                  `toml:"CloseFactorBps"`
+	Auction                 LiquidationAuction      `toml:"auction"`
 	ReserveFactorBps        uint64                  `toml:"ReserveFactorBps"`
 	Breaker                 BreakerThresholds       `toml:"breaker"`
 	ProtocolFeeBps          uint64                  `toml:"ProtocolFeeBps"`
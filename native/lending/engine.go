@@ -37,6 +37,9 @@ var (
 	errOracleStale           = errors.New("lending engine: oracle quote stale")
 	errOracleDeviation       = errors.New("lending engine: oracle deviation too large")
 	errMaxLTVExceeded        = errors.New("lending engine: borrow would exceed maximum loan-to-value ratio")
+	errAuctionDisabled       = errors.New("lending engine: liquidation auctions disabled")
+	errAuctionNotOpen        = errors.New("lending engine: liquidation auction not open")
+	errAuctionOpen           = errors.New("lending engine: liquidation auction already open")
 )
 
 const blocksPerYear = 31_536_000
@@ -69,6 +72,7 @@ type Engine struct {
 	developerFeeAddr  crypto.Address
 	collateralRouting CollateralRouting
 	pauses            nativecommon.PauseView
+	prices            PriceSource
 }
 
 // NewEngine constructs a lending engine configured with the module treasury
@@ -168,6 +172,15 @@ func (e *Engine) SetCollateralRouting(routing CollateralRouting) {
 	e.collateralRouting = routing.Clone()
 }
 
+// SetPriceSource wires the oracle used to value collateral in markets that
+// configure price feeds.
+func (e *Engine) SetPriceSource(src PriceSource) {
+	if e == nil {
+		return
+	}
+	e.prices = src
+}
+
 // Supply transfers NHB from the supplier into the lending pool and mints LP
 // shares based on the current supply index. The minted share amount is returned
 // to the caller for downstream accounting.
@@ -199,8 +212,9 @@ func (e *Engine) Supply(supplier crypto.Address, amount *big.Int) (*big.Int, err
 	if err != nil {
 		return nil, err
 	}
-	if supplierAcc.BalanceNHB.Cmp(amount) < 0 {
-		return nil, errInsufficientBalance
+	asset := e.borrowAsset(market)
+	if err := requireBalance(supplierAcc, asset, amount, errInsufficientBalance); err != nil {
+		return nil, err
 	}
 
 	moduleAcc, err := e.loadAccount(e.moduleAddress)
@@ -218,8 +232,9 @@ func (e *Engine) Supply(supplier crypto.Address, amount *big.Int) (*big.Int, err
 	}
 
 	// Adjust balances.
-	supplierAcc.BalanceNHB = new(big.Int).Sub(supplierAcc.BalanceNHB, amount)
-	moduleAcc.BalanceNHB = new(big.Int).Add(moduleAcc.BalanceNHB, amount)
+	if err := transferBalance(supplierAcc, moduleAcc, asset, amount); err != nil {
+		return nil, err
+	}
 
 	if err := e.persistAccount(supplier, supplierAcc); err != nil {
 		return nil, err
@@ -302,12 +317,13 @@ func (e *Engine) Withdraw(supplier crypto.Address, amountLP *big.Int) (*big.Int,
 	if err != nil {
 		return nil, err
 	}
-	if moduleAcc.BalanceNHB.Cmp(redeemAmount) < 0 {
-		return nil, errInsufficientLiquidity
+	asset := e.borrowAsset(market)
+	if err := requireBalance(moduleAcc, asset, redeemAmount, errInsufficientLiquidity); err != nil {
+		return nil, err
+	}
+	if err := transferBalance(moduleAcc, supplierAcc, asset, redeemAmount); err != nil {
+		return nil, err
 	}
-
-	moduleAcc.BalanceNHB = new(big.Int).Sub(moduleAcc.BalanceNHB, redeemAmount)
-	supplierAcc.BalanceNHB = new(big.Int).Add(supplierAcc.BalanceNHB, redeemAmount)
 
 	if err := e.persistAccount(e.moduleAddress, moduleAcc); err != nil {
 		return nil, err
@@ -336,9 +352,10 @@ func (e *Engine) Withdraw(supplier crypto.Address, amountLP *big.Int) (*big.Int,
 	return redeemAmount, nil
 }
 
-// DepositCollateral locks ZNHB collateral for a borrower inside the lending
-// module.
-func (e *Engine) DepositCollateral(userAddr crypto.Address, amount *big.Int) error {
+// DepositCollateral locks collateral of the given asset for a borrower inside
+// the lending module. The asset must be accepted by the market and the deposit
+// must fit within its supply cap.
+func (e *Engine) DepositCollateral(userAddr crypto.Address, asset string, amount *big.Int) error {
 	if e == nil || e.state == nil {
 		return errNilState
 	}
@@ -349,20 +366,35 @@ func (e *Engine) DepositCollateral(userAddr crypto.Address, amount *big.Int) err
 		return errInvalidAmount
 	}
 
+	market, err := e.ensureMarket()
+	if err != nil {
+		return err
+	}
+	cfg, err := e.collateralConfig(market, asset)
+	if err != nil {
+		return err
+	}
+	asset = cfg.Asset
+	total := new(big.Int).Add(market.collateralTotal(asset), amount)
+	if cfg.SupplyCap != nil && cfg.SupplyCap.Sign() > 0 && total.Cmp(cfg.SupplyCap) > 0 {
+		return errCollateralCap
+	}
+
 	userAcc, err := e.loadAccount(userAddr)
 	if err != nil {
 		return err
 	}
-	if userAcc.BalanceZNHB.Cmp(amount) < 0 {
-		return errInsufficientBalance
+	if err := requireBalance(userAcc, asset, amount, errInsufficientBalance); err != nil {
+		return err
 	}
 	moduleAcc, err := e.loadAccount(e.collateralAddress)
 	if err != nil {
 		return err
 	}
 
-	userAcc.BalanceZNHB = new(big.Int).Sub(userAcc.BalanceZNHB, amount)
-	moduleAcc.BalanceZNHB = new(big.Int).Add(moduleAcc.BalanceZNHB, amount)
+	if err := transferBalance(userAcc, moduleAcc, asset, amount); err != nil {
+		return err
+	}
 
 	if err := e.persistAccount(userAddr, userAcc); err != nil {
 		return err
@@ -375,14 +407,30 @@ func (e *Engine) DepositCollateral(userAddr crypto.Address, amount *big.Int) err
 	if err != nil {
 		return err
 	}
-	user.CollateralZNHB = new(big.Int).Add(user.CollateralZNHB, amount)
+	user.SetCollateral(asset, new(big.Int).Add(user.CollateralOf(asset), amount))
+	market.setCollateralTotal(asset, total)
+	if user.LiquidationAuctionStart != 0 {
+		// Topping up collateral ends a running auction only once the
+		// position is healthy again.
+		e.syncDebt(user, market)
+		healthy, err := e.positionHealthy(market, user.Collateral, user.DebtNHB)
+		if err != nil {
+			return err
+		}
+		if healthy {
+			user.LiquidationAuctionStart = 0
+		}
+	}
 
-	return e.state.PutUserAccount(e.poolID, user)
+	if err := e.state.PutUserAccount(e.poolID, user); err != nil {
+		return err
+	}
+	return e.state.PutMarket(e.poolID, market)
 }
 
-// WithdrawCollateral releases ZNHB collateral back to the user while ensuring
-// the resulting position remains healthy.
-func (e *Engine) WithdrawCollateral(userAddr crypto.Address, amount *big.Int) error {
+// WithdrawCollateral releases collateral of the given asset back to the user
+// while ensuring the resulting position remains healthy.
+func (e *Engine) WithdrawCollateral(userAddr crypto.Address, asset string, amount *big.Int) error {
 	if e == nil || e.state == nil {
 		return errNilState
 	}
@@ -393,18 +441,30 @@ func (e *Engine) WithdrawCollateral(userAddr crypto.Address, amount *big.Int) er
 		return errInvalidAmount
 	}
 
-	user, err := e.ensureUserAccount(userAddr)
+	market, err := e.ensureMarket()
 	if err != nil {
 		return err
 	}
-	if user.CollateralZNHB.Cmp(amount) < 0 {
-		return errInsufficientBalance
+	// Collateral the market no longer accepts can still be withdrawn, so
+	// only an empty asset needs resolving against the market configuration.
+	asset = NormalizeAsset(asset)
+	if asset == "" {
+		cfg, err := e.collateralConfig(market, asset)
+		if err != nil {
+			return err
+		}
+		asset = cfg.Asset
 	}
-
-	market, err := e.ensureMarket()
+	if !ValidAsset(asset) {
+		return errUnsupportedAsset
+	}
+	user, err := e.ensureUserAccount(userAddr)
 	if err != nil {
 		return err
 	}
+	if user.CollateralOf(asset).Cmp(amount) < 0 {
+		return errInsufficientBalance
+	}
 
 	fees, feesChanged, err := e.accrueInterest(market)
 	if err != nil {
@@ -413,8 +473,12 @@ func (e *Engine) WithdrawCollateral(userAddr crypto.Address, amount *big.Int) er
 
 	e.syncDebt(user, market)
 
-	remaining := new(big.Int).Sub(user.CollateralZNHB, amount)
-	if !e.positionHealthy(remaining, user.DebtNHB) {
+	remaining := new(big.Int).Sub(user.CollateralOf(asset), amount)
+	healthy, err := e.positionHealthy(market, withCollateral(user.Collateral, asset, remaining), user.DebtNHB)
+	if err != nil {
+		return err
+	}
+	if !healthy {
 		return errHealthCheckFailed
 	}
 
@@ -422,8 +486,8 @@ func (e *Engine) WithdrawCollateral(userAddr crypto.Address, amount *big.Int) er
 	if err != nil {
 		return err
 	}
-	if collateralAcc.BalanceZNHB.Cmp(amount) < 0 {
-		return errInsufficientLiquidity
+	if err := requireBalance(collateralAcc, asset, amount, errInsufficientLiquidity); err != nil {
+		return err
 	}
 
 	userAcc, err := e.loadAccount(userAddr)
@@ -431,8 +495,9 @@ func (e *Engine) WithdrawCollateral(userAddr crypto.Address, amount *big.Int) er
 		return err
 	}
 
-	collateralAcc.BalanceZNHB = new(big.Int).Sub(collateralAcc.BalanceZNHB, amount)
-	userAcc.BalanceZNHB = new(big.Int).Add(userAcc.BalanceZNHB, amount)
+	if err := transferBalance(collateralAcc, userAcc, asset, amount); err != nil {
+		return err
+	}
 
 	if err := e.persistAccount(e.collateralAddress, collateralAcc); err != nil {
 		return err
@@ -441,7 +506,8 @@ func (e *Engine) WithdrawCollateral(userAddr crypto.Address, amount *big.Int) er
 		return err
 	}
 
-	user.CollateralZNHB = remaining
+	user.SetCollateral(asset, remaining)
+	market.setCollateralTotal(asset, new(big.Int).Sub(market.collateralTotal(asset), amount))
 
 	if feesChanged {
 		if err := e.state.PutFeeAccrual(e.poolID, fees); err != nil {
@@ -550,21 +616,30 @@ func (e *Engine) Borrow(borrower crypto.Address, amount *big.Int, feeRecipient c
 
 	// Health factor check using the projected debt after borrowing.
 	projectedDebt := new(big.Int).Add(borrowerUser.DebtNHB, totalOut)
-	if !e.positionHealthy(borrowerUser.CollateralZNHB, projectedDebt) {
+	healthy, err := e.positionHealthy(market, borrowerUser.Collateral, projectedDebt)
+	if err != nil {
+		return nil, err
+	}
+	if !healthy {
 		return nil, errHealthCheckFailed
 	}
 	// Borrow-time cap, stricter than and independent of the liquidation
 	// threshold above -- see withinMaxLTV.
-	if !e.withinMaxLTV(borrowerUser.CollateralZNHB, projectedDebt) {
+	withinLTV, err := e.withinMaxLTV(market, borrowerUser.Collateral, projectedDebt)
+	if err != nil {
+		return nil, err
+	}
+	if !withinLTV {
 		return nil, errMaxLTVExceeded
 	}
 
+	asset := e.borrowAsset(market)
 	moduleAcc, err := e.loadAccount(e.moduleAddress)
 	if err != nil {
 		return nil, err
 	}
-	if moduleAcc.BalanceNHB.Cmp(totalOut) < 0 {
-		return nil, errInsufficientLiquidity
+	if err := requireBalance(moduleAcc, asset, totalOut, errInsufficientLiquidity); err != nil {
+		return nil, err
 	}
 
 	borrowerAcc, err := e.loadAccount(borrower)
@@ -579,10 +654,13 @@ func (e *Engine) Borrow(borrower crypto.Address, amount *big.Int, feeRecipient c
 		}
 	}
 
-	moduleAcc.BalanceNHB = new(big.Int).Sub(moduleAcc.BalanceNHB, totalOut)
-	borrowerAcc.BalanceNHB = new(big.Int).Add(borrowerAcc.BalanceNHB, amount)
+	if err := transferBalance(moduleAcc, borrowerAcc, asset, amount); err != nil {
+		return nil, err
+	}
 	if feeAcc != nil {
-		feeAcc.BalanceNHB = new(big.Int).Add(feeAcc.BalanceNHB, feeAmount)
+		if err := transferBalance(moduleAcc, feeAcc, asset, feeAmount); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := e.persistAccount(e.moduleAddress, moduleAcc); err != nil {
@@ -675,8 +753,9 @@ func (e *Engine) Repay(borrower crypto.Address, amount *big.Int) (*big.Int, erro
 	if err != nil {
 		return nil, err
 	}
-	if borrowerAcc.BalanceNHB.Cmp(repayAmount) < 0 {
-		return nil, errInsufficientBalance
+	asset := e.borrowAsset(market)
+	if err := requireBalance(borrowerAcc, asset, repayAmount, errInsufficientBalance); err != nil {
+		return nil, err
 	}
 
 	moduleAcc, err := e.loadAccount(e.moduleAddress)
@@ -684,8 +763,9 @@ func (e *Engine) Repay(borrower crypto.Address, amount *big.Int) (*big.Int, erro
 		return nil, err
 	}
 
-	borrowerAcc.BalanceNHB = new(big.Int).Sub(borrowerAcc.BalanceNHB, repayAmount)
-	moduleAcc.BalanceNHB = new(big.Int).Add(moduleAcc.BalanceNHB, repayAmount)
+	if err := transferBalance(borrowerAcc, moduleAcc, asset, repayAmount); err != nil {
+		return nil, err
+	}
 
 	if err := e.persistAccount(borrower, borrowerAcc); err != nil {
 		return nil, err
@@ -703,6 +783,13 @@ func (e *Engine) Repay(borrower crypto.Address, amount *big.Int) (*big.Int, erro
 	}
 	borrowerUser.ScaledDebt = new(big.Int).Sub(borrowerUser.ScaledDebt, scaledRepay)
 	borrowerUser.DebtNHB = debtFromScaled(borrowerUser.ScaledDebt, market.BorrowIndex)
+	healthy, err := e.positionHealthy(market, borrowerUser.Collateral, borrowerUser.DebtNHB)
+	if err != nil {
+		return nil, err
+	}
+	if healthy {
+		borrowerUser.LiquidationAuctionStart = 0
+	}
 
 	market.TotalNHBBorrowed = new(big.Int).Sub(market.TotalNHBBorrowed, repayAmount)
 
@@ -722,10 +809,80 @@ func (e *Engine) Repay(borrower crypto.Address, amount *big.Int) (*big.Int, erro
 	return repayAmount, nil
 }
 
-// Liquidate allows a third party to repay the borrower's debt in exchange for
-// a discounted amount of their collateral. The repaid debt and seized
-// collateral values are returned.
-func (e *Engine) Liquidate(liquidator, borrower crypto.Address) (*big.Int, *big.Int, error) {
+// OpenLiquidationAuction starts the Dutch-auction clock against an unhealthy
+// borrower. Liquidations in auction mode are rejected until an auction is
+// open, after which the bonus rises with every elapsed block.
+func (e *Engine) OpenLiquidationAuction(borrower crypto.Address) error {
+	if e == nil || e.state == nil {
+		return errNilState
+	}
+	if err := nativecommon.Guard(e.pauses, moduleName); err != nil {
+		return err
+	}
+	if e.params.Pauses.Liquidate {
+		return errLiquidationsPaused
+	}
+	if !e.params.Auction.Enabled {
+		return errAuctionDisabled
+	}
+
+	market, err := e.ensureMarket()
+	if err != nil {
+		return err
+	}
+	fees, feesChanged, err := e.accrueInterest(market)
+	if err != nil {
+		return err
+	}
+
+	borrowerUser, err := e.ensureUserAccount(borrower)
+	if err != nil {
+		return err
+	}
+	e.syncDebt(borrowerUser, market)
+	if borrowerUser.DebtNHB.Sign() == 0 {
+		return errNoDebtToRepay
+	}
+	healthy, err := e.positionHealthy(market, borrowerUser.Collateral, borrowerUser.DebtNHB)
+	if err != nil {
+		return err
+	}
+	if healthy {
+		return errNotLiquidatable
+	}
+	if borrowerUser.LiquidationAuctionStart != 0 {
+		return errAuctionOpen
+	}
+	// Height zero doubles as "no auction", so an auction opened before the
+	// first block starts its clock at height one.
+	borrowerUser.LiquidationAuctionStart = e.blockHeight
+	if borrowerUser.LiquidationAuctionStart == 0 {
+		borrowerUser.LiquidationAuctionStart = 1
+	}
+
+	if err := e.state.PutUserAccount(e.poolID, borrowerUser); err != nil {
+		return err
+	}
+	if err := e.state.PutMarket(e.poolID, market); err != nil {
+		return err
+	}
+	if feesChanged {
+		if err := e.state.PutFeeAccrual(e.poolID, fees); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Liquidate allows a third party to repay part of an unhealthy borrower's
+// debt in exchange for a discounted amount of one of their collateral assets.
+// collateralAsset selects the asset to seize; it may be empty when the market
+// accepts a single collateral asset. maxRepay caps the amount of the borrow
+// asset the liquidator is willing to repay; nil or zero repays as much as the
+// close factor allows. When the seizure exhausts all of the borrower's
+// collateral the remaining debt is written off against protocol reserves.
+// The repaid debt and seized collateral amounts are returned.
+func (e *Engine) Liquidate(liquidator, borrower crypto.Address, collateralAsset string, maxRepay *big.Int) (*big.Int, *big.Int, error) {
 	if e == nil || e.state == nil {
 		return nil, nil, errNilState
 	}
@@ -755,18 +912,60 @@ func (e *Engine) Liquidate(liquidator, borrower crypto.Address) (*big.Int, *big.
 	if borrowerUser.DebtNHB.Sign() == 0 {
 		return nil, nil, errNoDebtToRepay
 	}
-	if e.positionHealthy(borrowerUser.CollateralZNHB, borrowerUser.DebtNHB) {
+	healthy, err := e.positionHealthy(market, borrowerUser.Collateral, borrowerUser.DebtNHB)
+	if err != nil {
+		return nil, nil, err
+	}
+	if healthy {
 		return nil, nil, errNotLiquidatable
 	}
+	if maxRepay != nil && maxRepay.Sign() < 0 {
+		return nil, nil, errInvalidAmount
+	}
+	cfg, err := e.collateralConfig(market, collateralAsset)
+	if err != nil {
+		return nil, nil, err
+	}
+	seizeAsset := cfg.Asset
+	pledged := borrowerUser.CollateralOf(seizeAsset)
+	if pledged.Sign() == 0 {
+		return nil, nil, errInsufficientBalance
+	}
+	rate, err := e.collateralRate(market, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bonus, err := e.liquidationBonus(borrowerUser)
+	if err != nil {
+		return nil, nil, err
+	}
+	bonusFactor := new(big.Int).SetUint64(10_000 + bonus)
 
 	repayAmount := new(big.Int).Set(borrowerUser.DebtNHB)
+	if closeFactor := e.params.CloseFactorBps; closeFactor > 0 && closeFactor < 10_000 {
+		repayAmount.Mul(repayAmount, new(big.Int).SetUint64(closeFactor))
+		repayAmount.Quo(repayAmount, basisPoints)
+	}
+	if maxRepay != nil && maxRepay.Sign() > 0 && maxRepay.Cmp(repayAmount) < 0 {
+		repayAmount = new(big.Int).Set(maxRepay)
+	}
+	// An underwater position cannot pay the full bonus on the requested
+	// amount, so only repay what the seized asset covers.
+	coverable := new(big.Int).Mul(valueAt(pledged, rate), basisPoints)
+	coverable.Quo(coverable, bonusFactor)
+	seizeAll := repayAmount.Cmp(coverable) >= 0
+	if seizeAll {
+		repayAmount = coverable
+	}
 
 	liquidatorAcc, err := e.loadAccount(liquidator)
 	if err != nil {
 		return nil, nil, err
 	}
-	if liquidatorAcc.BalanceNHB.Cmp(repayAmount) < 0 {
-		return nil, nil, errInsufficientBalance
+	asset := e.borrowAsset(market)
+	if err := requireBalance(liquidatorAcc, asset, repayAmount, errInsufficientBalance); err != nil {
+		return nil, nil, err
 	}
 
 	borrowerAcc, err := e.loadAccount(borrower)
@@ -778,15 +977,18 @@ func (e *Engine) Liquidate(liquidator, borrower crypto.Address) (*big.Int, *big.
 		return nil, nil, err
 	}
 
-	// Transfer NHB from liquidator to module to cover the debt.
-	liquidatorAcc.BalanceNHB = new(big.Int).Sub(liquidatorAcc.BalanceNHB, repayAmount)
-	moduleAcc.BalanceNHB = new(big.Int).Add(moduleAcc.BalanceNHB, repayAmount)
+	// Transfer the borrow asset from liquidator to module to cover the debt.
+	if err := transferBalance(liquidatorAcc, moduleAcc, asset, repayAmount); err != nil {
+		return nil, nil, err
+	}
 
-	// Determine collateral seized with liquidation bonus.
-	seizeAmount := new(big.Int).Mul(repayAmount, big.NewInt(int64(10_000+e.params.LiquidationBonus)))
-	seizeAmount = seizeAmount.Quo(seizeAmount, basisPoints)
-	if seizeAmount.Cmp(borrowerUser.CollateralZNHB) > 0 {
-		seizeAmount = new(big.Int).Set(borrowerUser.CollateralZNHB)
+	// Determine collateral seized with liquidation bonus, converted from the
+	// borrow asset into units of the seized asset.
+	seizeValue := new(big.Int).Mul(repayAmount, bonusFactor)
+	seizeValue.Quo(seizeValue, basisPoints)
+	seizeAmount := unitsAt(seizeValue, rate)
+	if seizeAll || seizeAmount.Cmp(pledged) > 0 {
+		seizeAmount = pledged
 	}
 
 	routing := e.collateralRouting
@@ -799,8 +1001,8 @@ func (e *Engine) Liquidate(liquidator, borrower crypto.Address) (*big.Int, *big.
 	if err != nil {
 		return nil, nil, err
 	}
-	if collateralAcc.BalanceZNHB.Cmp(seizeAmount) < 0 {
-		return nil, nil, errInsufficientLiquidity
+	if err := requireBalance(collateralAcc, seizeAsset, seizeAmount, errInsufficientLiquidity); err != nil {
+		return nil, nil, err
 	}
 
 	computeShare := func(amount *big.Int, bps uint64) *big.Int {
@@ -856,8 +1058,9 @@ func (e *Engine) Liquidate(liquidator, borrower crypto.Address) (*big.Int, *big.
 		liquidatorShare = new(big.Int).Add(liquidatorShare, remainder)
 	}
 
-	collateralAcc.BalanceZNHB = new(big.Int).Sub(collateralAcc.BalanceZNHB, seizeAmount)
-	liquidatorAcc.BalanceZNHB = new(big.Int).Add(liquidatorAcc.BalanceZNHB, liquidatorShare)
+	if err := transferBalance(collateralAcc, liquidatorAcc, seizeAsset, liquidatorShare); err != nil {
+		return nil, nil, err
+	}
 
 	var developerAcc *types.Account
 	if developerShare.Sign() > 0 {
@@ -865,7 +1068,9 @@ func (e *Engine) Liquidate(liquidator, borrower crypto.Address) (*big.Int, *big.
 		if err != nil {
 			return nil, nil, err
 		}
-		developerAcc.BalanceZNHB = new(big.Int).Add(developerAcc.BalanceZNHB, developerShare)
+		if err := transferBalance(collateralAcc, developerAcc, seizeAsset, developerShare); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	var protocolAcc *types.Account
@@ -874,7 +1079,9 @@ func (e *Engine) Liquidate(liquidator, borrower crypto.Address) (*big.Int, *big.
 		if err != nil {
 			return nil, nil, err
 		}
-		protocolAcc.BalanceZNHB = new(big.Int).Add(protocolAcc.BalanceZNHB, protocolShare)
+		if err := transferBalance(collateralAcc, protocolAcc, seizeAsset, protocolShare); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	if err := e.persistAccount(liquidator, liquidatorAcc); err != nil {
@@ -900,12 +1107,31 @@ func (e *Engine) Liquidate(liquidator, borrower crypto.Address) (*big.Int, *big.
 		}
 	}
 
-	borrowerUser.DebtNHB = big.NewInt(0)
-	borrowerUser.ScaledDebt = big.NewInt(0)
-	borrowerUser.CollateralZNHB = new(big.Int).Sub(borrowerUser.CollateralZNHB, seizeAmount)
+	scaledRepay := scaledDebtFromAmount(repayAmount, market.BorrowIndex)
+	if scaledRepay.Cmp(borrowerUser.ScaledDebt) > 0 || repayAmount.Cmp(borrowerUser.DebtNHB) == 0 {
+		scaledRepay = new(big.Int).Set(borrowerUser.ScaledDebt)
+	}
+	borrowerUser.ScaledDebt = new(big.Int).Sub(borrowerUser.ScaledDebt, scaledRepay)
+	borrowerUser.DebtNHB = debtFromScaled(borrowerUser.ScaledDebt, market.BorrowIndex)
+	borrowerUser.SetCollateral(seizeAsset, new(big.Int).Sub(pledged, seizeAmount))
+	market.setCollateralTotal(seizeAsset, new(big.Int).Sub(market.collateralTotal(seizeAsset), seizeAmount))
 
 	market.TotalNHBBorrowed = new(big.Int).Sub(market.TotalNHBBorrowed, repayAmount)
 
+	if len(borrowerUser.CollateralAssets()) == 0 && borrowerUser.DebtNHB.Sign() > 0 {
+		e.writeOffBadDebt(market, fees, borrowerUser.DebtNHB)
+		borrowerUser.DebtNHB = big.NewInt(0)
+		borrowerUser.ScaledDebt = big.NewInt(0)
+		feesChanged = true
+	}
+	healthy, err = e.positionHealthy(market, borrowerUser.Collateral, borrowerUser.DebtNHB)
+	if err != nil {
+		return nil, nil, err
+	}
+	if healthy {
+		borrowerUser.LiquidationAuctionStart = 0
+	}
+
 	if err := e.state.PutUserAccount(e.poolID, borrowerUser); err != nil {
 		return nil, nil, err
 	}
@@ -922,6 +1148,48 @@ func (e *Engine) Liquidate(liquidator, borrower crypto.Address) (*big.Int, *big.
 	return repayAmount, seizeAmount, nil
 }
 
+// liquidationBonus returns the collateral bonus paid on a liquidation of
+// user, following the auction schedule when auctions are enabled.
+func (e *Engine) liquidationBonus(user *UserAccount) (uint64, error) {
+	auction := e.params.Auction
+	if !auction.Enabled {
+		return e.params.LiquidationBonus, nil
+	}
+	if user == nil || user.LiquidationAuctionStart == 0 {
+		return 0, errAuctionNotOpen
+	}
+	var elapsed uint64
+	if e.blockHeight > user.LiquidationAuctionStart {
+		elapsed = e.blockHeight - user.LiquidationAuctionStart
+	}
+	return auction.BonusAt(elapsed), nil
+}
+
+// writeOffBadDebt removes debt that no collateral backs any more from the
+// market. Protocol reserves absorb the loss first; the rest is socialised
+// across suppliers by shrinking TotalNHBSupplied.
+func (e *Engine) writeOffBadDebt(market *Market, fees *FeeAccrual, amount *big.Int) {
+	if market == nil || fees == nil || amount == nil || amount.Sign() <= 0 {
+		return
+	}
+	absorbed := new(big.Int).Set(amount)
+	if absorbed.Cmp(fees.ProtocolFeesWei) > 0 {
+		absorbed = new(big.Int).Set(fees.ProtocolFeesWei)
+	}
+	fees.ProtocolFeesWei = new(big.Int).Sub(fees.ProtocolFeesWei, absorbed)
+
+	market.TotalNHBBorrowed = new(big.Int).Sub(market.TotalNHBBorrowed, amount)
+	if market.TotalNHBBorrowed.Sign() < 0 {
+		market.TotalNHBBorrowed = big.NewInt(0)
+	}
+	socialised := new(big.Int).Sub(amount, absorbed)
+	market.TotalNHBSupplied = new(big.Int).Sub(market.TotalNHBSupplied, socialised)
+	if market.TotalNHBSupplied.Sign() < 0 {
+		market.TotalNHBSupplied = big.NewInt(0)
+	}
+	market.BadDebtNHB = new(big.Int).Add(market.BadDebtNHB, amount)
+}
+
 func (e *Engine) ensureMarket() (*Market, error) {
 	if e == nil || e.state == nil {
 		return nil, errNilState
@@ -960,6 +1228,9 @@ func (e *Engine) ensureMarket() (*Market, error) {
 	if market.OraclePrevMedianWei == nil {
 		market.OraclePrevMedianWei = big.NewInt(0)
 	}
+	if market.BadDebtNHB == nil {
+		market.BadDebtNHB = big.NewInt(0)
+	}
 	return market, nil
 }
 
@@ -977,8 +1248,8 @@ func (e *Engine) ensureUserAccount(addr crypto.Address) (*UserAccount, error) {
 	if user == nil {
 		user = &UserAccount{Address: addr}
 	}
-	if user.CollateralZNHB == nil {
-		user.CollateralZNHB = big.NewInt(0)
+	if user.Collateral == nil {
+		user.Collateral = make(map[string]*big.Int)
 	}
 	if user.SupplyShares == nil {
 		user.SupplyShares = big.NewInt(0)
@@ -1053,54 +1324,43 @@ func (e *Engine) guardOracle(market *Market) error {
 	return nil
 }
 
-// positionHealthy compares collateral (ZNHB wei) directly against debt (NHB
-// wei) at an implicit 1:1 exchange rate -- there is currently no price
-// oracle wired into this comparison. RiskParameters.Oracle/Market.
-// OracleMedianWei exist and are read by guardOracle, but nothing in this
-// engine ever WRITES a real price into OracleMedianWei (confirmed: grepped
-// every assignment site, all three just zero-initialize it), and the live
-// config has both Oracle.MaxAgeBlocks and MaxDeviationBps at 0, so
-// guardOracle's checks never fire either. If ZNHB's market value ever
-// diverges materially from NHB's, this 1:1 treatment either lets borrowers
-// draw more real value than their collateral covers (bad debt risk to
-// suppliers) or needlessly under-collateralizes the other way. A real fix
-// means wiring a deterministic on-chain price into OracleMedianWei (the
-// swap.OracleAggregator + core/pricing.DefaultPriceFeed infrastructure
-// already exists and is live for swap/loyalty pricing, but nothing
-// currently publishes its output into lending's Market state) and
-// converting collateral through it here before comparing -- not a small
-// change, and not done yet. Until then, treat any deployment enabling
-// borrowing as implicitly assuming ZNHB tracks NHB 1:1.
-func (e *Engine) positionHealthy(collateral, debt *big.Int) bool {
-	if debt == nil || debt.Sign() == 0 {
-		return true
-	}
-	if collateral == nil || collateral.Sign() == 0 {
-		return false
-	}
-	num := new(big.Int).Mul(collateral, big.NewInt(int64(e.params.LiquidationThreshold)))
-	den := new(big.Int).Mul(debt, basisPoints)
-	return num.Cmp(den) >= 0
+// positionHealthy weighs every collateral asset the user has pledged by its
+// LiquidationThreshold and compares the sum, valued in the market's borrow
+// asset, against debt. Assets are converted through the market's oracle
+// feeds (see collateralRate); assets without a feed, including the legacy
+// ZNHB collateral of markets created before multi-asset support, are still
+// valued 1:1 against the borrow asset. A stale or missing price fails the
+// check rather than guessing, so an oracle outage blocks new borrowing and
+// withdrawals instead of silently mispricing collateral.
+func (e *Engine) positionHealthy(market *Market, collateral map[string]*big.Int, debt *big.Int) (bool, error) {
+	return e.coversDebt(market, collateral, debt, func(cfg CollateralAsset) uint64 {
+		return cfg.LiquidationThreshold
+	})
 }
 
-// withinMaxLTV enforces RiskParameters.MaxLTV as a borrow-time cap, distinct
-// from and stricter than positionHealthy's LiquidationThreshold. The two
-// were previously configured independently (live config already has a real
-// 75%/85% split) but only LiquidationThreshold was ever checked -- MaxLTV
-// was accepted, stored, and silently ignored, letting a borrower go straight
-// to the liquidation edge with zero safety buffer. Same comparison shape as
-// positionHealthy, deliberately, so the two stay easy to reason about
-// side by side.
-func (e *Engine) withinMaxLTV(collateral, debt *big.Int) bool {
+// withinMaxLTV enforces each asset's MaxLTV as a borrow-time cap, distinct
+// from and stricter than positionHealthy's LiquidationThreshold. Same
+// comparison shape as positionHealthy, deliberately, so the two stay easy to
+// reason about side by side.
+func (e *Engine) withinMaxLTV(market *Market, collateral map[string]*big.Int, debt *big.Int) (bool, error) {
+	return e.coversDebt(market, collateral, debt, func(cfg CollateralAsset) uint64 {
+		return cfg.MaxLTV
+	})
+}
+
+func (e *Engine) coversDebt(market *Market, collateral map[string]*big.Int, debt *big.Int, weight func(CollateralAsset) uint64) (bool, error) {
 	if debt == nil || debt.Sign() == 0 {
-		return true
+		return true, nil
 	}
-	if collateral == nil || collateral.Sign() == 0 {
-		return false
+	weighted, err := e.weightedCollateral(market, collateral, weight)
+	if err != nil {
+		return false, err
+	}
+	if weighted.Sign() == 0 {
+		return false, nil
 	}
-	num := new(big.Int).Mul(collateral, big.NewInt(int64(e.params.MaxLTV)))
 	den := new(big.Int).Mul(debt, basisPoints)
-	return num.Cmp(den) >= 0
+	return weighted.Cmp(den) >= 0, nil
 }
 
 func utilisation(borrowed, supplied *big.Int) uint64 {
@@ -1166,8 +1426,9 @@ func (e *Engine) withdrawFees(recipient crypto.Address, amount *big.Int, protoco
 	if err != nil {
 		return nil, err
 	}
-	if moduleAcc.BalanceNHB.Cmp(amount) < 0 {
-		return nil, errInsufficientLiquidity
+	asset := e.borrowAsset(market)
+	if err := requireBalance(moduleAcc, asset, amount, errInsufficientLiquidity); err != nil {
+		return nil, err
 	}
 
 	recipientAcc, err := e.loadAccount(recipient)
@@ -1175,8 +1436,9 @@ func (e *Engine) withdrawFees(recipient crypto.Address, amount *big.Int, protoco
 		return nil, err
 	}
 
-	moduleAcc.BalanceNHB = new(big.Int).Sub(moduleAcc.BalanceNHB, amount)
-	recipientAcc.BalanceNHB = new(big.Int).Add(recipientAcc.BalanceNHB, amount)
+	if err := transferBalance(moduleAcc, recipientAcc, asset, amount); err != nil {
+		return nil, err
+	}
 
 	if err := e.persistAccount(e.moduleAddress, moduleAcc); err != nil {
 		return nil, err
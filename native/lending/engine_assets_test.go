@@ -0,0 +1,116 @@
+package lending
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"nhbchain/core/types"
+	"nhbchain/crypto"
+)
+
+type stubPriceSource map[string]*big.Rat
+
+func (s stubPriceSource) FeedPrice(feed string, _ uint64) (*big.Rat, bool, error) {
+	price, ok := s[feed]
+	return price, ok, nil
+}
+
+func newMultiAssetFixture(t *testing.T, prices stubPriceSource) (*Engine, *mockEngineState, crypto.Address, crypto.Address) {
+	moduleAddr := makeAddress(crypto.NHBPrefix, 0x50)
+	collateralAddr := makeAddress(crypto.ZNHBPrefix, 0x51)
+	liquidator := makeAddress(crypto.NHBPrefix, 0x52)
+	borrower := makeAddress(crypto.NHBPrefix, 0x53)
+
+	engine := NewEngine(moduleAddr, collateralAddr, RiskParameters{LiquidationBonus: 1000})
+	engine.SetPoolID("default")
+	engine.SetPriceSource(prices)
+
+	state := newMockEngineState()
+	state.market = &Market{
+		PoolID:           "default",
+		BorrowAsset:      AssetNHB,
+		TotalNHBSupplied: big.NewInt(10_000),
+		SupplyIndex:      new(big.Int).Set(ray),
+		BorrowIndex:      new(big.Int).Set(ray),
+		Collateral: []CollateralAsset{
+			{Asset: AssetZNHB, MaxLTV: 5000, LiquidationThreshold: 8000, OracleFeed: "ZNHB", SupplyCap: big.NewInt(150)},
+			{Asset: AssetNHB, MaxLTV: 8000, LiquidationThreshold: 9000},
+		},
+	}
+	state.accounts[state.key(moduleAddr)] = &types.Account{BalanceNHB: big.NewInt(10_000)}
+	state.accounts[state.key(collateralAddr)] = &types.Account{}
+	state.accounts[state.key(liquidator)] = &types.Account{BalanceNHB: big.NewInt(5_000)}
+	state.accounts[state.key(borrower)] = &types.Account{BalanceNHB: big.NewInt(500), BalanceZNHB: big.NewInt(500)}
+	engine.SetState(state)
+
+	if err := engine.DepositCollateral(borrower, AssetZNHB, big.NewInt(100)); err != nil {
+		t.Fatalf("deposit ZNHB: %v", err)
+	}
+	if err := engine.DepositCollateral(borrower, AssetNHB, big.NewInt(100)); err != nil {
+		t.Fatalf("deposit NHB: %v", err)
+	}
+	return engine, state, liquidator, borrower
+}
+
+func TestBorrowCountsEveryCollateralAsset(t *testing.T) {
+	engine, state, _, borrower := newMultiAssetFixture(t, stubPriceSource{"ZNHB": big.NewRat(2, 1)})
+
+	// 100 ZNHB at 2 NHB with a 50% LTV plus 100 NHB at 80% supports 180 NHB.
+	if _, err := engine.Borrow(borrower, big.NewInt(181), borrower, 0); !errors.Is(err, errMaxLTVExceeded) {
+		t.Fatalf("expected max LTV error, got %v", err)
+	}
+	if _, err := engine.Borrow(borrower, big.NewInt(180), borrower, 0); err != nil {
+		t.Fatalf("borrow: %v", err)
+	}
+	if err := engine.DepositCollateral(borrower, AssetZNHB, big.NewInt(51)); !errors.Is(err, errCollateralCap) {
+		t.Fatalf("expected supply cap error, got %v", err)
+	}
+	if got := state.market.collateralTotal(AssetZNHB); got.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected ZNHB total: %s", got)
+	}
+	if err := engine.WithdrawCollateral(borrower, AssetZNHB, big.NewInt(1)); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if got := state.market.collateralTotal(AssetZNHB); got.Cmp(big.NewInt(99)) != 0 {
+		t.Fatalf("unexpected ZNHB total after withdrawal: %s", got)
+	}
+
+	engine.SetPriceSource(stubPriceSource{})
+	if _, err := engine.Borrow(borrower, big.NewInt(1), borrower, 0); !errors.Is(err, errPriceUnavailable) {
+		t.Fatalf("expected missing price error, got %v", err)
+	}
+}
+
+func TestLiquidateSeizesChosenAssetAtOraclePrice(t *testing.T) {
+	prices := stubPriceSource{"ZNHB": big.NewRat(2, 1)}
+	engine, state, liquidator, borrower := newMultiAssetFixture(t, prices)
+	if _, err := engine.Borrow(borrower, big.NewInt(180), borrower, 0); err != nil {
+		t.Fatalf("borrow: %v", err)
+	}
+
+	// Halving the ZNHB price drops the weighted collateral to 170 NHB.
+	prices["ZNHB"] = big.NewRat(1, 1)
+	if _, _, err := engine.Liquidate(liquidator, borrower, "", nil); !errors.Is(err, errUnsupportedCollateral) {
+		t.Fatalf("expected ambiguous collateral error, got %v", err)
+	}
+	repaid, seized, err := engine.Liquidate(liquidator, borrower, AssetZNHB, nil)
+	if err != nil {
+		t.Fatalf("liquidate: %v", err)
+	}
+	// 100 ZNHB is worth 100 NHB, which covers 90 NHB of debt plus the 10%
+	// bonus.
+	if repaid.Cmp(big.NewInt(90)) != 0 || seized.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected liquidation: repaid=%s seized=%s", repaid, seized)
+	}
+	user := state.users[state.key(borrower)]
+	if user.DebtNHB.Cmp(big.NewInt(90)) != 0 || user.CollateralOf(AssetZNHB).Sign() != 0 || user.CollateralOf(AssetNHB).Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected borrower state: debt=%s collateral=%v", user.DebtNHB, user.Collateral)
+	}
+	if got := state.accounts[state.key(liquidator)].BalanceZNHB; got.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected liquidator ZNHB: %s", got)
+	}
+	if state.market.BadDebtNHB != nil && state.market.BadDebtNHB.Sign() != 0 {
+		t.Fatalf("unexpected bad debt: %s", state.market.BadDebtNHB)
+	}
+}
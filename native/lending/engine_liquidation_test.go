@@ -45,16 +45,16 @@ func TestLiquidateRoutesCollateral(t *testing.T) {
 	state.accounts[state.key(protocol)] = &types.Account{BalanceZNHB: big.NewInt(0)}
 
 	borrowerAccount := &UserAccount{
-		Address:        borrower,
-		CollateralZNHB: big.NewInt(1_000),
-		DebtNHB:        big.NewInt(800),
-		ScaledDebt:     big.NewInt(800),
+		Address:    borrower,
+		Collateral: map[string]*big.Int{AssetZNHB: big.NewInt(1_000)},
+		DebtNHB:    big.NewInt(800),
+		ScaledDebt: big.NewInt(800),
 	}
 	state.users[state.key(borrower)] = borrowerAccount
 
 	engine.SetState(state)
 
-	repaid, seized, err := engine.Liquidate(liquidator, borrower)
+	repaid, seized, err := engine.Liquidate(liquidator, borrower, AssetZNHB, nil)
 	if err != nil {
 		t.Fatalf("liquidate: %v", err)
 	}
@@ -79,8 +79,8 @@ func TestLiquidateRoutesCollateral(t *testing.T) {
 	}
 
 	borrowerUser := state.users[state.key(borrower)]
-	if borrowerUser.DebtNHB.Sign() != 0 || borrowerUser.CollateralZNHB.Cmp(big.NewInt(120)) != 0 {
-		t.Fatalf("unexpected borrower state: debt=%s collateral=%s", borrowerUser.DebtNHB, borrowerUser.CollateralZNHB)
+	if borrowerUser.DebtNHB.Sign() != 0 || borrowerUser.CollateralOf(AssetZNHB).Cmp(big.NewInt(120)) != 0 {
+		t.Fatalf("unexpected borrower state: debt=%s collateral=%s", borrowerUser.DebtNHB, borrowerUser.CollateralOf(AssetZNHB))
 	}
 	if state.market.TotalNHBBorrowed.Sign() != 0 {
 		t.Fatalf("expected borrowed total to reset, got %s", state.market.TotalNHBBorrowed)
@@ -115,10 +115,10 @@ func TestLiquidateRoutingValidation(t *testing.T) {
 		state.accounts[state.key(borrower)] = &types.Account{}
 		state.accounts[state.key(developer)] = &types.Account{BalanceZNHB: big.NewInt(0)}
 		state.users[state.key(borrower)] = &UserAccount{
-			Address:        borrower,
-			CollateralZNHB: big.NewInt(500),
-			DebtNHB:        big.NewInt(500),
-			ScaledDebt:     big.NewInt(500),
+			Address:    borrower,
+			Collateral: map[string]*big.Int{AssetZNHB: big.NewInt(500)},
+			DebtNHB:    big.NewInt(500),
+			ScaledDebt: big.NewInt(500),
 		}
 		engine.SetState(state)
 		return engine
@@ -126,13 +126,150 @@ func TestLiquidateRoutingValidation(t *testing.T) {
 
 	engine := setup()
 	engine.SetCollateralRouting(CollateralRouting{DeveloperBps: 3000, ProtocolBps: 8000, DeveloperTarget: developer})
-	if _, _, err := engine.Liquidate(liquidator, borrower); err != errCollateralRoutingBps {
+	if _, _, err := engine.Liquidate(liquidator, borrower, AssetZNHB, nil); err != errCollateralRoutingBps {
 		t.Fatalf("expected collateral routing bps error, got %v", err)
 	}
 
 	engine = setup()
 	engine.SetCollateralRouting(CollateralRouting{DeveloperBps: 1000})
-	if _, _, err := engine.Liquidate(liquidator, borrower); err != errDeveloperCollateral {
+	if _, _, err := engine.Liquidate(liquidator, borrower, AssetZNHB, nil); err != errDeveloperCollateral {
 		t.Fatalf("expected developer collateral error, got %v", err)
 	}
 }
+
+func newLiquidationFixture(params RiskParameters, collateral, debt int64) (*Engine, *mockEngineState, crypto.Address, crypto.Address) {
+	moduleAddr := makeAddress(crypto.NHBPrefix, 0x40)
+	collateralAddr := makeAddress(crypto.ZNHBPrefix, 0x41)
+	liquidator := makeAddress(crypto.NHBPrefix, 0x42)
+	borrower := makeAddress(crypto.NHBPrefix, 0x43)
+
+	engine := NewEngine(moduleAddr, collateralAddr, params)
+	engine.SetPoolID("default")
+	engine.SetBlockHeight(10)
+
+	state := newMockEngineState()
+	state.market = &Market{
+		PoolID:           "default",
+		TotalNHBSupplied: big.NewInt(10_000),
+		TotalNHBBorrowed: big.NewInt(debt),
+		SupplyIndex:      new(big.Int).Set(ray),
+		BorrowIndex:      new(big.Int).Set(ray),
+	}
+	state.fees = &FeeAccrual{ProtocolFeesWei: big.NewInt(100), DeveloperFeesWei: big.NewInt(0)}
+	state.accounts[state.key(moduleAddr)] = &types.Account{}
+	state.accounts[state.key(collateralAddr)] = &types.Account{BalanceZNHB: big.NewInt(collateral)}
+	state.accounts[state.key(liquidator)] = &types.Account{BalanceNHB: big.NewInt(5_000)}
+	state.accounts[state.key(borrower)] = &types.Account{}
+	state.users[state.key(borrower)] = &UserAccount{
+		Address:    borrower,
+		Collateral: map[string]*big.Int{AssetZNHB: big.NewInt(collateral)},
+		DebtNHB:    big.NewInt(debt),
+		ScaledDebt: big.NewInt(debt),
+	}
+	engine.SetState(state)
+	return engine, state, liquidator, borrower
+}
+
+func TestLiquidateRespectsCloseFactor(t *testing.T) {
+	engine, state, liquidator, borrower := newLiquidationFixture(RiskParameters{
+		LiquidationThreshold: 7500,
+		LiquidationBonus:     500,
+		CloseFactorBps:       5000,
+	}, 900, 800)
+
+	repaid, seized, err := engine.Liquidate(liquidator, borrower, AssetZNHB, big.NewInt(1_000))
+	if err != nil {
+		t.Fatalf("liquidate: %v", err)
+	}
+	if repaid.Cmp(big.NewInt(400)) != 0 || seized.Cmp(big.NewInt(420)) != 0 {
+		t.Fatalf("unexpected liquidation: repaid=%s seized=%s", repaid, seized)
+	}
+
+	repaid, seized, err = engine.Liquidate(liquidator, borrower, AssetZNHB, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("liquidate with repay amount: %v", err)
+	}
+	if repaid.Cmp(big.NewInt(100)) != 0 || seized.Cmp(big.NewInt(105)) != 0 {
+		t.Fatalf("unexpected liquidation: repaid=%s seized=%s", repaid, seized)
+	}
+
+	user := state.users[state.key(borrower)]
+	if user.DebtNHB.Cmp(big.NewInt(300)) != 0 || user.CollateralOf(AssetZNHB).Cmp(big.NewInt(375)) != 0 {
+		t.Fatalf("unexpected borrower state: debt=%s collateral=%s", user.DebtNHB, user.CollateralOf(AssetZNHB))
+	}
+	if _, _, err := engine.Liquidate(liquidator, borrower, AssetZNHB, big.NewInt(-1)); err != errInvalidAmount {
+		t.Fatalf("expected invalid amount error, got %v", err)
+	}
+}
+
+func TestLiquidateWritesOffBadDebt(t *testing.T) {
+	engine, state, liquidator, borrower := newLiquidationFixture(RiskParameters{
+		LiquidationThreshold: 7500,
+		LiquidationBonus:     1000,
+	}, 550, 800)
+
+	repaid, seized, err := engine.Liquidate(liquidator, borrower, AssetZNHB, nil)
+	if err != nil {
+		t.Fatalf("liquidate: %v", err)
+	}
+	if repaid.Cmp(big.NewInt(500)) != 0 || seized.Cmp(big.NewInt(550)) != 0 {
+		t.Fatalf("unexpected liquidation: repaid=%s seized=%s", repaid, seized)
+	}
+
+	user := state.users[state.key(borrower)]
+	if user.DebtNHB.Sign() != 0 || user.ScaledDebt.Sign() != 0 || user.CollateralOf(AssetZNHB).Sign() != 0 {
+		t.Fatalf("expected position to be closed: %+v", user)
+	}
+	if state.market.BadDebtNHB.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("bad debt = %s, want 300", state.market.BadDebtNHB)
+	}
+	if state.fees.ProtocolFeesWei.Sign() != 0 {
+		t.Fatalf("expected reserves to absorb the loss, got %s", state.fees.ProtocolFeesWei)
+	}
+	if state.market.TotalNHBBorrowed.Sign() != 0 || state.market.TotalNHBSupplied.Cmp(big.NewInt(9_800)) != 0 {
+		t.Fatalf("unexpected market totals: borrowed=%s supplied=%s", state.market.TotalNHBBorrowed, state.market.TotalNHBSupplied)
+	}
+}
+
+func TestLiquidationAuctionBonusRises(t *testing.T) {
+	engine, state, liquidator, borrower := newLiquidationFixture(RiskParameters{
+		LiquidationThreshold: 7500,
+		CloseFactorBps:       2500,
+		Auction: LiquidationAuction{
+			Enabled:       true,
+			StartBonusBps: 100,
+			StepBonusBps:  100,
+			StepBlocks:    5,
+			MaxBonusBps:   300,
+		},
+	}, 1_000, 800)
+
+	if _, _, err := engine.Liquidate(liquidator, borrower, AssetZNHB, nil); err != errAuctionNotOpen {
+		t.Fatalf("expected auction not open error, got %v", err)
+	}
+	if err := engine.OpenLiquidationAuction(borrower); err != nil {
+		t.Fatalf("open auction: %v", err)
+	}
+	if err := engine.OpenLiquidationAuction(borrower); err != errAuctionOpen {
+		t.Fatalf("expected auction open error, got %v", err)
+	}
+
+	engine.SetBlockHeight(20)
+	_, seized, err := engine.Liquidate(liquidator, borrower, AssetZNHB, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("liquidate: %v", err)
+	}
+	if seized.Cmp(big.NewInt(103)) != 0 {
+		t.Fatalf("seized = %s, want 103 at the capped bonus", seized)
+	}
+	if state.users[state.key(borrower)].LiquidationAuctionStart != 10 {
+		t.Fatalf("expected auction to keep running while the position is unhealthy")
+	}
+
+	auction := engine.params.Auction
+	for elapsed, want := range map[uint64]uint64{0: 100, 4: 100, 5: 200, 9: 200, 10: 300, 1 << 62: 300} {
+		if got := auction.BonusAt(elapsed); got != want {
+			t.Fatalf("BonusAt(%d) = %d, want %d", elapsed, got, want)
+		}
+	}
+}
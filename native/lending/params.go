@@ -38,3 +38,35 @@ type OracleConfig struct {
 	MaxAgeBlocks    uint64
 	MaxDeviationBps uint64
 }
+
+// LiquidationAuction configures Dutch-auction liquidations. Once an auction is
+// opened against an unhealthy position the liquidation bonus starts at
+// StartBonusBps and rises by StepBonusBps every StepBlocks blocks, capped at
+// MaxBonusBps, until liquidators fill the position.
+type LiquidationAuction struct {
+	Enabled       bool   `toml:"Enabled"`
+	StartBonusBps uint64 `toml:"StartBonusBps"`
+	StepBonusBps  uint64 `toml:"StepBonusBps"`
+	StepBlocks    uint64 `toml:"StepBlocks"`
+	MaxBonusBps   uint64 `toml:"MaxBonusBps"`
+}
+
+// BonusAt returns the auction bonus after elapsed blocks.
+func (a LiquidationAuction) BonusAt(elapsed uint64) uint64 {
+	bonus := a.StartBonusBps
+	if bonus >= a.MaxBonusBps {
+		return a.MaxBonusBps
+	}
+	if a.StepBlocks == 0 || a.StepBonusBps == 0 {
+		return bonus
+	}
+	steps := elapsed / a.StepBlocks
+	if steps >= (a.MaxBonusBps-bonus)/a.StepBonusBps+1 {
+		return a.MaxBonusBps
+	}
+	bonus += steps * a.StepBonusBps
+	if bonus > a.MaxBonusBps {
+		return a.MaxBonusBps
+	}
+	return bonus
+}
@@ -7,8 +7,9 @@ import (
 )
 
 // Market captures the global accounting state for the lending protocol. Amount
-// values are denominated in wei and expressed as big integers to match on-chain
-// precision.
+// values are denominated in wei of the market's borrow asset and expressed as
+// big integers to match on-chain precision. The NHB in the field names dates
+// from when every market lent NHB.
 type Market struct {
 	// PoolID is the unique identifier for the market instance allowing the
 	// engine to differentiate state for independently operated pools.
@@ -23,6 +24,22 @@ type Market struct {
 	// DeveloperFeeBps captures the developer fee share expressed in basis
 	// points. A zero value disables developer fee accruals.
 	DeveloperFeeBps uint64
+	// BorrowAsset is the asset suppliers deposit and borrowers draw. Empty
+	// means NHB.
+	BorrowAsset string
+	// BorrowOracleFeed names the price feed collateral is valued against.
+	// An empty feed prices the borrow asset at 1.
+	BorrowOracleFeed string
+	// OracleMaxAgeSeconds rejects feed prices older than this many seconds.
+	// Zero disables the check.
+	OracleMaxAgeSeconds uint64
+	// Collateral lists the assets accepted as collateral with their own risk
+	// settings. An empty list means ZNHB collateral governed by the node's
+	// RiskParameters.
+	Collateral []CollateralAsset
+	// TotalCollateral tracks the amount of each collateral asset pledged to
+	// the market, used to enforce CollateralAsset.SupplyCap.
+	TotalCollateral map[string]*big.Int
 	// TotalNHBSupplied is the aggregate NHB liquidity currently deposited by
 	// lenders.
 	TotalNHBSupplied *big.Int
@@ -71,6 +88,10 @@ type Market struct {
 	// InterestModel.BorrowAPR). Like DepositApyBps it is computed on demand
 	// and is not persisted.
 	BorrowApyBps uint64 `json:"borrowApyBps"`
+	// BadDebtNHB accumulates the debt written off after liquidations
+	// exhausted a borrower's collateral. Protocol reserves absorb each
+	// write-off first; any remainder reduces TotalNHBSupplied.
+	BadDebtNHB *big.Int
 }
 
 // CollateralRouting captures the liquidation collateral distribution between
@@ -104,17 +125,22 @@ func (r CollateralRouting) Clone() CollateralRouting {
 type UserAccount struct {
 	// Address is the unique account identifier within the NHB network.
 	Address crypto.Address
-	// CollateralZNHB records the ZNHB amount pledged as collateral for
-	// borrowing.
-	CollateralZNHB *big.Int
+	// Collateral records the amount pledged per collateral asset. The health
+	// factor counts every entry.
+	Collateral map[string]*big.Int
 	// SupplyShares stores the LP token amount minted when supplying
 	// liquidity. Shares are scaled by 1e18 to align with the supply index.
 	SupplyShares *big.Int
-	// DebtNHB stores the principal NHB borrowed before interest accrual.
+	// DebtNHB stores the principal borrowed before interest accrual, in the
+	// market's borrow asset.
 	DebtNHB *big.Int
 	// ScaledDebt reflects the debt adjusted by the borrow index to capture
 	// accrued interest.
 	ScaledDebt *big.Int
+	// LiquidationAuctionStart records the block height at which a Dutch
+	// liquidation auction was opened against the position. Zero means no
+	// auction is running.
+	LiquidationAuctionStart uint64
 }
 
 // RiskParameters groups the governance controlled safety limits governing
@@ -129,6 +155,13 @@ type RiskParameters struct {
 	// LiquidationBonus captures the discount applied to collateral during
 	// liquidation, expressed in basis points.
 	LiquidationBonus uint64
+	// CloseFactorBps bounds the share of a borrower's debt that a single
+	// liquidation may repay, expressed in basis points. A zero value allows
+	// the entire debt to be repaid in one call.
+	CloseFactorBps uint64
+	// Auction configures Dutch-auction liquidations. When enabled the
+	// auction schedule replaces LiquidationBonus.
+	Auction LiquidationAuction
 	// OracleAddress identifies the trusted ZNHB/NHB price feed provider.
 	OracleAddress crypto.Address
 	// CircuitBreakerActive signals whether new borrowing should be halted due
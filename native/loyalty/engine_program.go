@@ -14,6 +14,7 @@ const (
 	eventProgramAccrued       = "loyalty.program.accrued"
 	eventProgramSkipped       = "loyalty.program.skipped"
 	eventProgramPaymasterWarn = "loyalty.program.paymaster_warning"
+	eventProgramExpired       = "loyalty.program.expired"
 
 	resultAccrued             = "accrued"
 	resultThrottledLowReserve = "throttled — low reserve"
@@ -36,6 +37,14 @@ type ProgramRewardState interface {
 	SetLoyaltyProgramIssuanceAccrued(programID ProgramID, addr []byte, amount *big.Int) error
 }
 
+// CoalitionState is implemented by reward states that support coalitions.
+// Members of a shared-pool coalition are funded from the coalition pool, and
+// every member records the rewards it issues for settlement.
+type CoalitionState interface {
+	LoyaltyCoalitionForProgram(id ProgramID) (*Coalition, bool, error)
+	RecordLoyaltyCoalitionIssued(coalition *Coalition, programID ProgramID, timestamp uint64, amount *big.Int) error
+}
+
 // ProgramRewardContext extends the base reward context with optional hints used
 // when resolving programs.
 type ProgramRewardContext struct {
@@ -146,6 +155,77 @@ func (e *Engine) ApplyProgramReward(st ProgramRewardState, ctx *ProgramRewardCon
 		emitProgramSkip(st, ctx, program, business, "reward_token_not_supported", map[string]string{"token": program.TokenSymbol})
 		return "reward_token_not_supported"
 	}
+
+	var coalition *Coalition
+	if coalitionState, ok := st.(CoalitionState); ok {
+		member, found, err := coalitionState.LoyaltyCoalitionForProgram(program.ID)
+		if err != nil {
+			emitProgramSkip(st, ctx, program, business, "coalition_lookup_error", map[string]string{"error": err.Error()})
+			return "coalition_lookup_error"
+		}
+		if found && member.Active {
+			coalition = member
+		}
+	}
+	// Expired rewards of a shared-pool member return to the coalition pool
+	// that funded them.
+	expiryProgram := program
+	if coalition.Shared() {
+		shared := *program
+		shared.Pool = coalition.Pool
+		expiryProgram = &shared
+	}
+
+	// Tier resolution and reward expiry need the optional ledger state; the
+	// spend of this transaction counts towards later tiers, not its own.
+	ledger, _ := st.(ProgramLedgerState)
+	accrualBps := program.AccrualBps
+	var (
+		tier        *Tier
+		tierAttrs   map[string]string
+		liveLots    []RewardLot
+		lotsEnabled = ledger != nil && program.Expiring()
+	)
+	if ledger != nil && program.Tiered() {
+		trailing, err := TrailingSpend(ledger, program, fromAddr, timestamp)
+		if err != nil {
+			emitProgramSkip(st, ctx, program, business, "meter_error", map[string]string{"error": err.Error()})
+			return "meter_error"
+		}
+		tier, accrualBps = program.TierFor(trailing)
+		tierAttrs = map[string]string{
+			"trailingSpend": trailing.String(),
+			"accrualBps":    strconv.FormatUint(uint64(accrualBps), 10),
+		}
+		if tier != nil {
+			tierAttrs["tier"] = tier.Name
+		}
+		day := DayIndex(timestamp)
+		spentToday, err := ledger.LoyaltyProgramSpend(program.ID, fromAddr, day)
+		if err != nil {
+			emitProgramSkip(st, ctx, program, business, "meter_error", map[string]string{"error": err.Error()})
+			return "meter_error"
+		}
+		if spentToday == nil {
+			spentToday = big.NewInt(0)
+		}
+		if err := ledger.SetLoyaltyProgramSpend(program.ID, fromAddr, day, new(big.Int).Add(spentToday, amount)); err != nil {
+			emitProgramSkip(st, ctx, program, business, "meter_error", map[string]string{"error": err.Error()})
+			return "meter_error"
+		}
+	}
+	if lotsEnabled {
+		reclaimed, lots, err := ExpireRewards(programLedger{ProgramRewardState: st, ProgramLedgerState: ledger}, expiryProgram, fromAddr, timestamp)
+		if err != nil {
+			emitProgramSkip(st, ctx, program, business, "meter_error", map[string]string{"error": err.Error()})
+			return "meter_error"
+		}
+		liveLots = lots
+		if reclaimed.Sign() > 0 {
+			emitProgramExpired(st, ctx, expiryProgram, business, reclaimed)
+		}
+	}
+
 	if program.MinSpendWei != nil && amount.Cmp(program.MinSpendWei) < 0 {
 		emitProgramSkip(st, ctx, program, business, "below_min_spend", map[string]string{"minSpend": program.MinSpendWei.String()})
 		return "below_min_spend"
@@ -158,12 +238,12 @@ func (e *Engine) ApplyProgramReward(st ProgramRewardState, ctx *ProgramRewardCon
 		emitProgramSkip(st, ctx, program, business, "program_ended", map[string]string{"endTime": strconv.FormatUint(program.EndTime, 10)})
 		return "program_ended"
 	}
-	if program.AccrualBps == 0 {
-		emitProgramSkip(st, ctx, program, business, "no_reward_rate", nil)
+	if accrualBps == 0 {
+		emitProgramSkip(st, ctx, program, business, "no_reward_rate", tierAttrs)
 		return "no_reward_rate"
 	}
 
-	reward := new(big.Int).Mul(amount, new(big.Int).SetUint64(uint64(program.AccrualBps)))
+	reward := new(big.Int).Mul(amount, new(big.Int).SetUint64(uint64(accrualBps)))
 	reward = reward.Quo(reward, big.NewInt(10_000))
 	if reward.Sign() <= 0 {
 		emitProgramSkip(st, ctx, program, business, "reward_zero", nil)
@@ -271,11 +351,15 @@ func (e *Engine) ApplyProgramReward(st ProgramRewardState, ctx *ProgramRewardCon
 		}
 	}
 
-	if isZeroAddress(business.Paymaster) {
+	funder := business.Paymaster
+	if coalition.Shared() {
+		funder = coalition.Pool
+	}
+	if isZeroAddress(funder) {
 		emitProgramSkip(st, ctx, program, business, "paymaster_missing", nil)
 		return "paymaster_missing"
 	}
-	paymasterAcc, err := st.GetAccount(business.Paymaster[:])
+	paymasterAcc, err := st.GetAccount(funder[:])
 	if err != nil {
 		emitProgramSkip(st, ctx, program, business, "paymaster_error", map[string]string{"error": err.Error()})
 		return "paymaster_error"
@@ -284,7 +368,7 @@ func (e *Engine) ApplyProgramReward(st ProgramRewardState, ctx *ProgramRewardCon
 		paymasterAcc.BalanceZNHB = big.NewInt(0)
 	}
 
-	if business.PaymasterReserveMin != nil && business.PaymasterReserveMin.Sign() > 0 {
+	if !coalition.Shared() && business.PaymasterReserveMin != nil && business.PaymasterReserveMin.Sign() > 0 {
 		projected := new(big.Int).Sub(paymasterAcc.BalanceZNHB, reward)
 		warnThreshold := new(big.Int).Mul(business.PaymasterReserveMin, big.NewInt(120))
 		warnThreshold = warnThreshold.Quo(warnThreshold, big.NewInt(100))
@@ -305,15 +389,34 @@ func (e *Engine) ApplyProgramReward(st ProgramRewardState, ctx *ProgramRewardCon
 	}
 
 	paymasterAcc.BalanceZNHB = new(big.Int).Sub(paymasterAcc.BalanceZNHB, reward)
-	if err := st.PutAccount(business.Paymaster[:], paymasterAcc); err != nil {
+	if err := st.PutAccount(funder[:], paymasterAcc); err != nil {
 		emitProgramSkip(st, ctx, program, business, "paymaster_persist_error", map[string]string{"error": err.Error()})
 		return "paymaster_persist_error"
 	}
 
-	if baseCtx.FromAccount.BalanceZNHB == nil {
-		baseCtx.FromAccount.BalanceZNHB = big.NewInt(0)
+	if lotsEnabled {
+		// Rewards that expire stay in the reward vault until they are
+		// redeemed or expire, so they cannot be moved out of reach of expiry.
+		vault := ledger.LoyaltyRewardVault()
+		vaultAcc, err := st.GetAccount(vault[:])
+		if err != nil {
+			emitProgramSkip(st, ctx, program, business, "meter_error", map[string]string{"error": err.Error()})
+			return "meter_error"
+		}
+		if vaultAcc.BalanceZNHB == nil {
+			vaultAcc.BalanceZNHB = big.NewInt(0)
+		}
+		vaultAcc.BalanceZNHB = new(big.Int).Add(vaultAcc.BalanceZNHB, reward)
+		if err := st.PutAccount(vault[:], vaultAcc); err != nil {
+			emitProgramSkip(st, ctx, program, business, "meter_error", map[string]string{"error": err.Error()})
+			return "meter_error"
+		}
+	} else {
+		if baseCtx.FromAccount.BalanceZNHB == nil {
+			baseCtx.FromAccount.BalanceZNHB = big.NewInt(0)
+		}
+		baseCtx.FromAccount.BalanceZNHB = new(big.Int).Add(baseCtx.FromAccount.BalanceZNHB, reward)
 	}
-	baseCtx.FromAccount.BalanceZNHB = new(big.Int).Add(baseCtx.FromAccount.BalanceZNHB, reward)
 
 	if dayKey != "" {
 		if accruedToday == nil {
@@ -373,7 +476,26 @@ func (e *Engine) ApplyProgramReward(st ProgramRewardState, ctx *ProgramRewardCon
 		}
 	}
 
-	emitProgramAccrued(st, ctx, program, business, reward)
+	if lotsEnabled {
+		liveLots = appendRewardLot(liveLots, reward, timestamp, program.RewardExpiry(timestamp))
+		if err := ledger.SetLoyaltyRewardLots(program.ID, fromAddr, liveLots); err != nil {
+			emitProgramSkip(st, ctx, program, business, "meter_error", map[string]string{"error": err.Error()})
+			return "meter_error"
+		}
+	}
+
+	if coalition != nil {
+		if err := st.(CoalitionState).RecordLoyaltyCoalitionIssued(coalition, program.ID, timestamp, reward); err != nil {
+			emitProgramSkip(st, ctx, program, business, "meter_error", map[string]string{"error": err.Error()})
+			return "meter_error"
+		}
+		if tierAttrs == nil {
+			tierAttrs = make(map[string]string)
+		}
+		tierAttrs["coalitionId"] = hex.EncodeToString(coalition.ID[:])
+	}
+
+	emitProgramAccrued(st, ctx, program, business, reward, tierAttrs)
 	return resultAccrued
 }
 
@@ -499,15 +621,58 @@ func emitProgramSkip(st ProgramRewardState, ctx *ProgramRewardContext, program *
 	st.AppendEvent(&types.Event{Type: eventProgramSkipped, Attributes: attrs})
 }
 
-func emitProgramAccrued(st ProgramRewardState, ctx *ProgramRewardContext, program *Program, business *Business, reward *big.Int) {
+func emitProgramAccrued(st ProgramRewardState, ctx *ProgramRewardContext, program *Program, business *Business, reward *big.Int, extra map[string]string) {
 	if st == nil || ctx == nil || reward == nil {
 		return
 	}
 	attrs := ctx.programEventAttributes(program, business)
+	for k, v := range extra {
+		attrs[k] = v
+	}
 	attrs["reward"] = reward.String()
 	st.AppendEvent(&types.Event{Type: eventProgramAccrued, Attributes: attrs})
 }
 
+func emitProgramExpired(st ProgramRewardState, ctx *ProgramRewardContext, program *Program, business *Business, reclaimed *big.Int) {
+	if st == nil || ctx == nil || reclaimed == nil {
+		return
+	}
+	attrs := ctx.programEventAttributes(program, business)
+	attrs["expired"] = reclaimed.String()
+	if program != nil {
+		attrs["pool"] = hex.EncodeToString(program.Pool[:])
+	}
+	st.AppendEvent(&types.Event{Type: eventProgramExpired, Attributes: attrs})
+}
+
+// programLedger joins the account access of the reward state with its ledger
+// so that expiry can move expired rewards from the vault into the pool.
+type programLedger struct {
+	ProgramRewardState
+	ProgramLedgerState
+}
+
+// appendRewardLot records a new accrual. Rewards accrued on the same UTC day
+// share one lot, which keeps the lot list bounded by the expiry window.
+func appendRewardLot(lots []RewardLot, reward *big.Int, timestamp, expiresAt uint64) []RewardLot {
+	if n := len(lots); n > 0 && DayIndex(lots[n-1].AccruedAt) == DayIndex(timestamp) {
+		last := lots[n-1]
+		lots[n-1] = RewardLot{
+			Amount:    new(big.Int).Add(last.Amount, reward),
+			Remaining: new(big.Int).Add(last.Remaining, reward),
+			AccruedAt: last.AccruedAt,
+			ExpiresAt: last.ExpiresAt,
+		}
+		return lots
+	}
+	return append(lots, RewardLot{
+		Amount:    new(big.Int).Set(reward),
+		Remaining: new(big.Int).Set(reward),
+		AccruedAt: timestamp,
+		ExpiresAt: expiresAt,
+	})
+}
+
 func emitPaymasterWarning(st ProgramRewardState, ctx *ProgramRewardContext, program *Program, business *Business, balance, reserve *big.Int) {
 	if st == nil || ctx == nil {
 		return
@@ -0,0 +1,240 @@
+package loyalty
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"nhbchain/core/types"
+)
+
+type mockLedgerState struct {
+	*mockProgramState
+	spend map[string]*big.Int
+	lots  map[string][]RewardLot
+}
+
+func newMockLedgerState(cfg *GlobalConfig) *mockLedgerState {
+	return &mockLedgerState{
+		mockProgramState: newMockProgramState(cfg),
+		spend:            make(map[string]*big.Int),
+		lots:             make(map[string][]RewardLot),
+	}
+}
+
+func ledgerKey(programID ProgramID, addr []byte) string {
+	return string(programID[:]) + string(addr)
+}
+
+func (m *mockLedgerState) LoyaltyProgramSpend(programID ProgramID, addr []byte, day uint64) (*big.Int, error) {
+	if amt, ok := m.spend[fmt.Sprintf("%s/%d", ledgerKey(programID, addr), day)]; ok {
+		return new(big.Int).Set(amt), nil
+	}
+	return big.NewInt(0), nil
+}
+
+func (m *mockLedgerState) SetLoyaltyProgramSpend(programID ProgramID, addr []byte, day uint64, amount *big.Int) error {
+	m.spend[fmt.Sprintf("%s/%d", ledgerKey(programID, addr), day)] = new(big.Int).Set(amount)
+	return nil
+}
+
+func (m *mockLedgerState) LoyaltyRewardLots(programID ProgramID, addr []byte) ([]RewardLot, error) {
+	return append([]RewardLot(nil), m.lots[ledgerKey(programID, addr)]...), nil
+}
+
+func (m *mockLedgerState) SetLoyaltyRewardLots(programID ProgramID, addr []byte, lots []RewardLot) error {
+	m.lots[ledgerKey(programID, addr)] = append([]RewardLot(nil), lots...)
+	return nil
+}
+
+func (m *mockLedgerState) LoyaltyRewardVault() [20]byte {
+	var vault [20]byte
+	vault[0] = 0xee
+	return vault
+}
+
+func (m *mockLedgerState) lastEvent(t *testing.T, eventType string) types.Event {
+	t.Helper()
+	for i := len(m.events) - 1; i >= 0; i-- {
+		if m.events[i].Type == eventType {
+			return m.events[i]
+		}
+	}
+	t.Fatalf("no %s event emitted", eventType)
+	return types.Event{}
+}
+
+func TestApplyProgramRewardTieredAccrual(t *testing.T) {
+	state := newMockLedgerState(newConfig(0, 0, 0, 0, []byte("treasury")))
+	var from, merchant, paymaster [20]byte
+	from[0], merchant[0], paymaster[0] = 0x51, 0x52, 0x53
+	var programID ProgramID
+	programID[0] = 0x54
+	state.addAccount(paymaster[:], &types.Account{BalanceZNHB: big.NewInt(100_000), BalanceNHB: big.NewInt(0), Stake: big.NewInt(0)})
+	state.addProgram(&Program{
+		ID:          programID,
+		Owner:       merchant,
+		TokenSymbol: "ZNHB",
+		AccrualBps:  100,
+		Active:      true,
+		Tiers: []Tier{
+			{Name: "silver", MinSpendWei: big.NewInt(1_000), AccrualBps: 200},
+			{Name: "gold", MinSpendWei: big.NewInt(5_000), AccrualBps: 500},
+		},
+		TierWindowDays: 30,
+	})
+	state.addBusinessMapping(merchant, &Business{Paymaster: paymaster})
+
+	fromAccount := &types.Account{BalanceNHB: big.NewInt(0), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0)}
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	engine := NewEngine()
+	spend := func(at time.Time, amount int64) string {
+		ctx := &BaseRewardContext{
+			From:        toBytes(from),
+			To:          toBytes(merchant),
+			Token:       "NHB",
+			Amount:      big.NewInt(amount),
+			Timestamp:   at,
+			FromAccount: fromAccount,
+		}
+		return engine.ApplyProgramReward(state, &ProgramRewardContext{BaseRewardContext: ctx})
+	}
+
+	steps := []struct {
+		at      time.Time
+		amount  int64
+		balance string
+		tier    string
+	}{
+		// The first purchase accrues at the base rate; it does not count
+		// towards its own tier.
+		{start, 1_000, "10", ""},
+		{start.Add(time.Hour), 1_000, "30", "silver"},
+		{start.Add(24 * time.Hour), 4_000, "110", "silver"},
+		{start.Add(48 * time.Hour), 1_000, "160", "gold"},
+		// Once the window has rolled past every earlier purchase the user
+		// falls back to the base rate.
+		{start.Add(40 * 24 * time.Hour), 1_000, "170", ""},
+	}
+	for i, step := range steps {
+		if res := spend(step.at, step.amount); res != resultAccrued {
+			t.Fatalf("step %d: expected %q, got %q", i, resultAccrued, res)
+		}
+		if got := fromAccount.BalanceZNHB.String(); got != step.balance {
+			t.Fatalf("step %d: expected balance %s, got %s", i, step.balance, got)
+		}
+		evt := state.lastEvent(t, eventProgramAccrued)
+		if evt.Attributes["tier"] != step.tier {
+			t.Fatalf("step %d: expected tier %q, got %q", i, step.tier, evt.Attributes["tier"])
+		}
+	}
+}
+
+func TestApplyProgramRewardExpiresLotsIntoPool(t *testing.T) {
+	state := newMockLedgerState(newConfig(0, 0, 0, 0, []byte("treasury")))
+	var from, merchant, paymaster, pool [20]byte
+	from[0], merchant[0], paymaster[0], pool[0] = 0x61, 0x62, 0x63, 0x64
+	var programID ProgramID
+	programID[0] = 0x65
+	state.addAccount(paymaster[:], &types.Account{BalanceZNHB: big.NewInt(100_000), BalanceNHB: big.NewInt(0), Stake: big.NewInt(0)})
+	state.addProgram(&Program{
+		ID:               programID,
+		Owner:            merchant,
+		Pool:             pool,
+		TokenSymbol:      "ZNHB",
+		AccrualBps:       1_000,
+		Active:           true,
+		RewardExpiryDays: 10,
+	})
+	state.addBusinessMapping(merchant, &Business{Paymaster: paymaster})
+
+	fromAccount := &types.Account{BalanceNHB: big.NewInt(0), BalanceZNHB: big.NewInt(0), Stake: big.NewInt(0)}
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	engine := NewEngine()
+	spend := func(at time.Time) {
+		ctx := &BaseRewardContext{
+			From:        toBytes(from),
+			To:          toBytes(merchant),
+			Token:       "NHB",
+			Amount:      big.NewInt(1_000),
+			Timestamp:   at,
+			FromAccount: fromAccount,
+		}
+		if res := engine.ApplyProgramReward(state, &ProgramRewardContext{BaseRewardContext: ctx}); res != resultAccrued {
+			t.Fatalf("expected accrual, got %q", res)
+		}
+	}
+
+	spend(start)
+	spend(start.Add(time.Hour))
+	lots, _ := state.LoyaltyRewardLots(programID, from[:])
+	if len(lots) != 1 || lots[0].Remaining.String() != "200" {
+		t.Fatalf("expected one merged lot of 200, got %+v", lots)
+	}
+	// Expiring rewards are held in the vault, not credited to the user.
+	vault := state.LoyaltyRewardVault()
+	vaultAccount, _ := state.GetAccount(vault[:])
+	if vaultAccount.BalanceZNHB.String() != "200" || fromAccount.BalanceZNHB.Sign() != 0 {
+		t.Fatalf("expected the vault to hold 200 and the user nothing, got vault=%s user=%s", vaultAccount.BalanceZNHB, fromAccount.BalanceZNHB)
+	}
+
+	spend(start.Add(11 * 24 * time.Hour))
+	evt := state.lastEvent(t, eventProgramExpired)
+	if evt.Attributes["expired"] != "200" {
+		t.Fatalf("expected 200 expired, got %q", evt.Attributes["expired"])
+	}
+	poolAccount, _ := state.GetAccount(pool[:])
+	if poolAccount.BalanceZNHB.String() != "200" {
+		t.Fatalf("expected pool balance 200, got %s", poolAccount.BalanceZNHB.String())
+	}
+	vaultAccount, _ = state.GetAccount(vault[:])
+	if vaultAccount.BalanceZNHB.String() != "100" || fromAccount.BalanceZNHB.Sign() != 0 {
+		t.Fatalf("expected the vault to hold the new 100 and the user nothing, got vault=%s user=%s", vaultAccount.BalanceZNHB, fromAccount.BalanceZNHB)
+	}
+	lots, _ = state.LoyaltyRewardLots(programID, from[:])
+	if len(lots) != 1 || lots[0].Remaining.String() != "100" || lots[0].ExpiresAt != uint64(start.Add(21*24*time.Hour).Unix()) {
+		t.Fatalf("unexpected lots after expiry: %+v", lots)
+	}
+}
+
+func TestConsumeLotsFirstInFirstOut(t *testing.T) {
+	lots := []RewardLot{
+		{Amount: big.NewInt(50), Remaining: big.NewInt(50), AccruedAt: 1, ExpiresAt: 100},
+		{Amount: big.NewInt(80), Remaining: big.NewInt(80), AccruedAt: 2, ExpiresAt: 200},
+	}
+	live := ConsumeLots(lots, big.NewInt(70))
+	if len(live) != 1 || live[0].AccruedAt != 2 || live[0].Remaining.String() != "60" {
+		t.Fatalf("unexpected lots after consumption: %+v", live)
+	}
+	if lots[1].Remaining.String() != "80" {
+		t.Fatalf("consumption must not mutate the input lots")
+	}
+	live, expired := ExpireLots(lots, 100)
+	if len(live) != 1 || expired.String() != "50" {
+		t.Fatalf("unexpected expiry result: %+v expired=%s", live, expired)
+	}
+}
+
+func TestSanitizeTiersRejectsUnorderedTiers(t *testing.T) {
+	program := &Program{
+		TierWindowDays: 30,
+		Tiers: []Tier{
+			{Name: "gold", MinSpendWei: big.NewInt(5_000), AccrualBps: 500},
+			{Name: "silver", MinSpendWei: big.NewInt(1_000), AccrualBps: 200},
+		},
+	}
+	if err := sanitizeTiers(program); !errors.Is(err, ErrInvalidTier) {
+		t.Fatalf("expected ErrInvalidTier, got %v", err)
+	}
+	program.Tiers[0], program.Tiers[1] = program.Tiers[1], program.Tiers[0]
+	program.TierWindowDays = 0
+	if err := sanitizeTiers(program); !errors.Is(err, ErrInvalidTier) {
+		t.Fatalf("expected missing window to be rejected, got %v", err)
+	}
+	program.TierWindowDays = 30
+	if err := sanitizeTiers(program); err != nil {
+		t.Fatalf("expected ordered tiers to be accepted: %v", err)
+	}
+}
@@ -16,4 +16,16 @@ var (
 	ErrPaymasterConflict  = errors.New("loyalty: paymaster already assigned")
 	ErrMerchantAssigned   = errors.New("loyalty: merchant already assigned")
 	ErrMerchantNotFound   = errors.New("loyalty: merchant not found")
+	ErrInvalidTier        = errors.New("loyalty: invalid tier")
+	ErrInvalidItem        = errors.New("loyalty: invalid catalogue item")
+	ErrItemNotFound       = errors.New("loyalty: catalogue item not found")
+	ErrItemUnavailable    = errors.New("loyalty: catalogue item unavailable")
+	ErrInvalidCoalition   = errors.New("loyalty: invalid coalition")
+	ErrCoalitionExists    = errors.New("loyalty: coalition already exists")
+	ErrCoalitionNotFound  = errors.New("loyalty: coalition not found")
+	ErrCoalitionMember    = errors.New("loyalty: program already in a coalition")
+	ErrNotInvited         = errors.New("loyalty: program not invited to coalition")
+	ErrPeriodOpen         = errors.New("loyalty: settlement period still open")
+	ErrPeriodSettled      = errors.New("loyalty: settlement period already settled")
+	ErrInsufficientFunds  = errors.New("loyalty: insufficient funds")
 )
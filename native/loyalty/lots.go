@@ -0,0 +1,168 @@
+package loyalty
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"nhbchain/core/types"
+)
+
+// ProgramLedgerState is implemented by program reward states that track the
+// per-user spend and reward lots needed for tiered accrual and reward
+// expiry. Programs without tiers or expiry never touch it. Storing lots also
+// schedules them for the expiry sweep.
+type ProgramLedgerState interface {
+	LoyaltyProgramSpend(programID ProgramID, addr []byte, day uint64) (*big.Int, error)
+	SetLoyaltyProgramSpend(programID ProgramID, addr []byte, day uint64, amount *big.Int) error
+	LoyaltyRewardLots(programID ProgramID, addr []byte) ([]RewardLot, error)
+	SetLoyaltyRewardLots(programID ProgramID, addr []byte, lots []RewardLot) error
+	LoyaltyRewardVault() [20]byte
+}
+
+// TrailingSpend sums the user's recorded spend with the program over the
+// TierWindowDays UTC days ending with the day of timestamp.
+func TrailingSpend(st ProgramLedgerState, program *Program, addr []byte, timestamp uint64) (*big.Int, error) {
+	total := big.NewInt(0)
+	if st == nil || !program.Tiered() {
+		return total, nil
+	}
+	today := DayIndex(timestamp)
+	for i := uint64(0); i < uint64(program.TierWindowDays) && i <= today; i++ {
+		spend, err := st.LoyaltyProgramSpend(program.ID, addr, today-i)
+		if err != nil {
+			return nil, err
+		}
+		if spend != nil {
+			total.Add(total, spend)
+		}
+	}
+	return total, nil
+}
+
+// ExpireLots splits lots into those still live at now and the total
+// remaining amount of the lots that expired. Exhausted lots are dropped.
+func ExpireLots(lots []RewardLot, now uint64) ([]RewardLot, *big.Int) {
+	expired := big.NewInt(0)
+	live := make([]RewardLot, 0, len(lots))
+	for _, lot := range lots {
+		if lot.Remaining == nil || lot.Remaining.Sign() <= 0 {
+			continue
+		}
+		if lot.ExpiresAt != 0 && lot.ExpiresAt <= now {
+			expired.Add(expired, lot.Remaining)
+			continue
+		}
+		live = append(live, lot)
+	}
+	return live, expired
+}
+
+// ConsumeLots spends amount from lots oldest first and returns the lots that
+// still hold a balance. Spending more than the lots hold empties them.
+func ConsumeLots(lots []RewardLot, amount *big.Int) []RewardLot {
+	outstanding := new(big.Int)
+	if amount != nil {
+		outstanding.Set(amount)
+	}
+	live := make([]RewardLot, 0, len(lots))
+	for _, lot := range lots {
+		if lot.Remaining == nil || lot.Remaining.Sign() <= 0 {
+			continue
+		}
+		if outstanding.Sign() > 0 {
+			if lot.Remaining.Cmp(outstanding) <= 0 {
+				outstanding.Sub(outstanding, lot.Remaining)
+				continue
+			}
+			lot.Remaining = new(big.Int).Sub(lot.Remaining, outstanding)
+			outstanding.SetInt64(0)
+		}
+		live = append(live, lot)
+	}
+	return live
+}
+
+// RewardVaultState is the account access needed to settle reward lots. The
+// rewards of expiring programs are held in the vault rather than credited to
+// the user, so they can only be redeemed and cannot be moved out of reach of
+// expiry.
+type RewardVaultState interface {
+	ProgramLedgerState
+	GetAccount(addr []byte) (*types.Account, error)
+	PutAccount(addr []byte, account *types.Account) error
+}
+
+// MoveVaultRewards moves amount of ZNHB between the reward vault and the
+// provided account. A positive amount credits the vault from account, a
+// negative amount pays out of the vault into account.
+func MoveVaultRewards(st RewardVaultState, account [20]byte, amount *big.Int) error {
+	if amount == nil || amount.Sign() == 0 {
+		return nil
+	}
+	vaultAddr := st.LoyaltyRewardVault()
+	if vaultAddr == account {
+		return nil
+	}
+	from, to := account, vaultAddr
+	value := new(big.Int).Set(amount)
+	if amount.Sign() < 0 {
+		from, to = vaultAddr, account
+		value.Neg(value)
+	}
+	source, err := st.GetAccount(from[:])
+	if err != nil {
+		return err
+	}
+	if source.BalanceZNHB == nil || source.BalanceZNHB.Cmp(value) < 0 {
+		return fmt.Errorf("%w: reward vault transfer of %s exceeds balance", ErrInsufficientFunds, value)
+	}
+	source.BalanceZNHB = new(big.Int).Sub(source.BalanceZNHB, value)
+	if err := st.PutAccount(from[:], source); err != nil {
+		return err
+	}
+	dest, err := st.GetAccount(to[:])
+	if err != nil {
+		return err
+	}
+	if dest.BalanceZNHB == nil {
+		dest.BalanceZNHB = big.NewInt(0)
+	}
+	dest.BalanceZNHB = new(big.Int).Add(dest.BalanceZNHB, value)
+	return st.PutAccount(to[:], dest)
+}
+
+// ExpireRewards drops the user's expired reward lots and returns their
+// remaining amount from the reward vault to the program pool. The rewards
+// never left the vault, so nothing is taken from the user's own balance. A
+// program without a pool keeps its lots until it has one. It returns the
+// expired amount and the live lots, which have already been saved.
+func ExpireRewards(st RewardVaultState, program *Program, addr []byte, now uint64) (*big.Int, []RewardLot, error) {
+	lots, err := st.LoyaltyRewardLots(program.ID, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	live, expired := ExpireLots(lots, now)
+	if len(live) == len(lots) || isZeroAddress(program.Pool) {
+		return big.NewInt(0), lots, nil
+	}
+	if err := st.SetLoyaltyRewardLots(program.ID, addr, live); err != nil {
+		return nil, nil, err
+	}
+	if err := MoveVaultRewards(st, program.Pool, new(big.Int).Neg(expired)); err != nil {
+		return nil, nil, err
+	}
+	return expired, live, nil
+}
+
+// NewRewardsExpiredEvent describes expired rewards of user that returned to
+// the program pool.
+func NewRewardsExpiredEvent(program *Program, user []byte, expired *big.Int) *types.Event {
+	attrs := map[string]string{
+		"programId": hex.EncodeToString(program.ID[:]),
+		"user":      hex.EncodeToString(user),
+		"expired":   expired.String(),
+		"pool":      hex.EncodeToString(program.Pool[:]),
+	}
+	return &types.Event{Type: eventProgramExpired, Attributes: attrs}
+}
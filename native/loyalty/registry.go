@@ -89,6 +89,9 @@ func (r *Registry) CreateProgram(caller [20]byte, p *Program) error {
 	if err := r.st.KVAppend(merchantIdxKey(sanitized.Owner), sanitized.ID[:]); err != nil {
 		return err
 	}
+	if err := r.st.KVAppend(programIndexKey(), sanitized.ID[:]); err != nil {
+		return err
+	}
 	r.emit(events.LoyaltyProgramCreated{
 		ID:          sanitized.ID,
 		Owner:       sanitized.Owner,
@@ -149,6 +152,9 @@ func (r *Registry) UpdateProgram(caller [20]byte, p *Program) error {
 	existing.Active = sanitized.Active
 	existing.TokenSymbol = sanitized.TokenSymbol
 	existing.Pool = sanitized.Pool
+	existing.Tiers = sanitized.Tiers
+	existing.TierWindowDays = sanitized.TierWindowDays
+	existing.RewardExpiryDays = sanitized.RewardExpiryDays
 
 	if err := r.st.KVPut(programKey(existing.ID), existing); err != nil {
 		return err
@@ -168,10 +174,36 @@ func (r *Registry) UpdateProgram(caller [20]byte, p *Program) error {
 		EndTime:            existing.EndTime,
 		Pool:               existing.Pool,
 		TokenSymbol:        existing.TokenSymbol,
+		TierCount:          len(existing.Tiers),
+		TierWindowDays:     existing.TierWindowDays,
+		RewardExpiryDays:   existing.RewardExpiryDays,
 	})
 	return nil
 }
 
+// RestoreProgram writes a program exported from another chain together with
+// its indexes. It skips the authorization and token checks of CreateProgram
+// because genesis state is trusted.
+func (r *Registry) RestoreProgram(p *Program) error {
+	if p == nil {
+		return ErrNilProgram
+	}
+	exists, err := r.st.KVGet(programKey(p.ID), new(Program))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrProgramExists
+	}
+	if err := r.st.KVPut(programKey(p.ID), p); err != nil {
+		return err
+	}
+	if err := r.st.KVAppend(merchantIdxKey(p.Owner), p.ID[:]); err != nil {
+		return err
+	}
+	return r.st.KVAppend(programIndexKey(), p.ID[:])
+}
+
 // GetProgram retrieves a program by its identifier.
 func (r *Registry) GetProgram(id ProgramID) (*Program, bool) {
 	out := new(Program)
@@ -182,11 +214,20 @@ func (r *Registry) GetProgram(id ProgramID) (*Program, bool) {
 	return out, true
 }
 
+// ListPrograms returns the IDs of every program in deterministic order.
+func (r *Registry) ListPrograms() ([]ProgramID, error) {
+	return r.listProgramIDs(programIndexKey())
+}
+
 // ListProgramsByOwner returns all program IDs owned by the provided address in
 // deterministic order.
 func (r *Registry) ListProgramsByOwner(owner [20]byte) ([]ProgramID, error) {
+	return r.listProgramIDs(merchantIdxKey(owner))
+}
+
+func (r *Registry) listProgramIDs(key []byte) ([]ProgramID, error) {
 	var raw [][]byte
-	if err := r.st.KVGetList(merchantIdxKey(owner), &raw); err != nil {
+	if err := r.st.KVGetList(key, &raw); err != nil {
 		return nil, err
 	}
 	ids := make([]ProgramID, 0, len(raw))
@@ -255,6 +296,9 @@ func sanitizeProgram(p *Program) (*Program, error) {
 	if !hasDailyProgramCap && !hasEpochProgramCap {
 		return nil, fmt.Errorf("%w: at least one program-wide cap (dailyCapProgram or epochCapProgram) is required to bound total payout exposure", ErrInvalidProgram)
 	}
+	if err := sanitizeTiers(&copyProgram); err != nil {
+		return nil, err
+	}
 	copyProgram.MinSpendWei = cloneBigInt(copyProgram.MinSpendWei)
 	copyProgram.CapPerTx = cloneBigInt(copyProgram.CapPerTx)
 	copyProgram.DailyCapUser = cloneBigInt(copyProgram.DailyCapUser)
@@ -162,6 +162,80 @@ func (r *Registry) PrimaryPaymaster(owner [20]byte) ([20]byte, bool) {
 	return zeroAddr, false
 }
 
+// ListBusinesses returns every registered business in ID order. Business IDs
+// are issued from a counter, so the list walks the counter range.
+func (r *Registry) ListBusinesses() ([]*Business, error) {
+	var counter uint64
+	if _, err := r.st.KVGet(businessCounterKey(), &counter); err != nil {
+		return nil, err
+	}
+	businesses := make([]*Business, 0, counter)
+	for i := uint64(1); i <= counter; i++ {
+		var id BusinessID
+		binary.BigEndian.PutUint64(id[len(id)-8:], i)
+		if business, ok := r.getBusiness(id); ok {
+			businesses = append(businesses, business)
+		}
+	}
+	return businesses, nil
+}
+
+// ActivePaymasterBusiness returns the business whose paymaster is the owner's
+// active one.
+func (r *Registry) ActivePaymasterBusiness(owner [20]byte) (BusinessID, bool, error) {
+	var id BusinessID
+	exists, err := r.st.KVGet(ownerPaymasterKey(owner), &id)
+	if err != nil || !exists || id == zeroBusinessID {
+		return zeroBusinessID, false, err
+	}
+	return id, true, nil
+}
+
+// RestoreBusiness writes a business exported from another chain together with
+// its owner and merchant indexes. The ID counter moves past the restored ID so
+// later registrations do not collide with it.
+func (r *Registry) RestoreBusiness(business *Business) error {
+	if business == nil || business.ID == zeroBusinessID {
+		return fmt.Errorf("%w: id required", ErrInvalidBusiness)
+	}
+	if _, exists := r.getBusiness(business.ID); exists {
+		return fmt.Errorf("%w: business %x already exists", ErrInvalidBusiness, business.ID)
+	}
+	if err := r.st.KVPut(businessKey(business.ID), business); err != nil {
+		return err
+	}
+	if err := r.st.KVAppend(businessOwnerKey(business.Owner), business.ID[:]); err != nil {
+		return err
+	}
+	for _, merchant := range business.Merchants {
+		if err := r.st.KVPut(merchantBusinessIndexKey(merchant), business.ID); err != nil {
+			return err
+		}
+	}
+	key := businessCounterKey()
+	var counter uint64
+	if _, err := r.st.KVGet(key, &counter); err != nil {
+		return err
+	}
+	if seq := binary.BigEndian.Uint64(business.ID[len(business.ID)-8:]); seq > counter {
+		return r.st.KVPut(key, seq)
+	}
+	return nil
+}
+
+// RestoreActivePaymaster marks the business's paymaster as the owner's active
+// one.
+func (r *Registry) RestoreActivePaymaster(owner [20]byte, id BusinessID) error {
+	business, ok := r.getBusiness(id)
+	if !ok {
+		return ErrBusinessNotFound
+	}
+	if business.Owner != owner {
+		return ErrUnauthorized
+	}
+	return r.st.KVPut(ownerPaymasterKey(owner), id)
+}
+
 func (r *Registry) IsMerchant(addr [20]byte) (BusinessID, bool) {
 	var id BusinessID
 	exists, err := r.st.KVGet(merchantBusinessIndexKey(addr), &id)
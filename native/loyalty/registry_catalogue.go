@@ -0,0 +1,213 @@
+package loyalty
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"nhbchain/core/events"
+	nativecommon "nhbchain/native/common"
+)
+
+const (
+	maxCatalogueItemIDLen   = 64
+	maxCatalogueItemNameLen = 128
+)
+
+var (
+	cataloguePrefix      = []byte("loyalty/catalogue/")
+	catalogueIndexPrefix = []byte("loyalty/catalogue-index/")
+	redemptionPrefix     = []byte("loyalty/redemption/")
+)
+
+func catalogueItemKey(id ProgramID, itemID string) []byte {
+	key := make([]byte, 0, len(cataloguePrefix)+len(id)+1+len(itemID))
+	key = append(key, cataloguePrefix...)
+	key = append(key, id[:]...)
+	key = append(key, '/')
+	return append(key, itemID...)
+}
+
+func catalogueIndexKey(id ProgramID) []byte {
+	key := make([]byte, len(catalogueIndexPrefix)+len(id))
+	copy(key, catalogueIndexPrefix)
+	copy(key[len(catalogueIndexPrefix):], id[:])
+	return key
+}
+
+func redemptionKey(id [32]byte) []byte {
+	key := make([]byte, len(redemptionPrefix)+len(id))
+	copy(key, redemptionPrefix)
+	copy(key[len(redemptionPrefix):], id[:])
+	return key
+}
+
+// RedemptionID derives the receipt ID of a redemption from the hash of the
+// redeeming transaction.
+func RedemptionID(txHash []byte) [32]byte {
+	var id [32]byte
+	copy(id[:], ethcrypto.Keccak256(redemptionPrefix, txHash))
+	return id
+}
+
+// Available reports whether quantity units of the item can still be
+// redeemed.
+func (item *CatalogueItem) Available(quantity uint64) bool {
+	if item == nil || !item.Active || quantity == 0 {
+		return false
+	}
+	if item.Stock == 0 {
+		return true
+	}
+	return item.Redeemed+quantity >= item.Redeemed && item.Redeemed+quantity <= item.Stock
+}
+
+// SetCatalogueItem adds or replaces an item in a program's redemption
+// catalogue. The program owner or a caller with ROLE_LOYALTY_ADMIN must
+// authorise the change. The redeemed counter is kept across updates.
+func (r *Registry) SetCatalogueItem(caller [20]byte, item *CatalogueItem) error {
+	if item == nil {
+		return ErrInvalidItem
+	}
+	if err := nativecommon.Guard(r.pauses, moduleName); err != nil {
+		return err
+	}
+	program := new(Program)
+	found, err := r.st.KVGet(programKey(item.ProgramID), program)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrProgramNotFound
+	}
+	if caller != program.Owner && !r.st.HasRole(roleLoyaltyAdmin, caller[:]) {
+		return ErrUnauthorized
+	}
+	sanitized, err := sanitizeCatalogueItem(item)
+	if err != nil {
+		return err
+	}
+	existing, exists, err := r.CatalogueItem(sanitized.ProgramID, sanitized.ItemID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		sanitized.Redeemed = existing.Redeemed
+	} else {
+		sanitized.Redeemed = 0
+	}
+	if err := r.st.KVPut(catalogueItemKey(sanitized.ProgramID, sanitized.ItemID), sanitized); err != nil {
+		return err
+	}
+	if !exists {
+		if err := r.st.KVAppend(catalogueIndexKey(sanitized.ProgramID), []byte(sanitized.ItemID)); err != nil {
+			return err
+		}
+	}
+	r.emit(events.LoyaltyCatalogueItemUpdated{
+		ProgramID: sanitized.ProgramID,
+		ItemID:    sanitized.ItemID,
+		Name:      sanitized.Name,
+		CostWei:   cloneBigInt(sanitized.CostWei),
+		Mode:      sanitized.Mode,
+		Stock:     sanitized.Stock,
+		Active:    sanitized.Active,
+	})
+	return nil
+}
+
+// CatalogueItem loads one item of a program's catalogue.
+func (r *Registry) CatalogueItem(programID ProgramID, itemID string) (*CatalogueItem, bool, error) {
+	item := new(CatalogueItem)
+	ok, err := r.st.KVGet(catalogueItemKey(programID, strings.TrimSpace(itemID)), item)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if item.CostWei == nil {
+		item.CostWei = big.NewInt(0)
+	}
+	return item, true, nil
+}
+
+// ListCatalogue returns the program's catalogue in the order items were
+// added.
+func (r *Registry) ListCatalogue(programID ProgramID) ([]*CatalogueItem, error) {
+	var raw [][]byte
+	if err := r.st.KVGetList(catalogueIndexKey(programID), &raw); err != nil {
+		return nil, err
+	}
+	items := make([]*CatalogueItem, 0, len(raw))
+	for _, itemID := range raw {
+		item, ok, err := r.CatalogueItem(programID, string(itemID))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// RecordRedemption persists the item's updated redeemed counter together
+// with the redemption receipt. Receipts are write-once.
+func (r *Registry) RecordRedemption(item *CatalogueItem, receipt *RedemptionReceipt) error {
+	if item == nil || receipt == nil {
+		return ErrInvalidItem
+	}
+	exists, err := r.st.KVGet(redemptionKey(receipt.ID), new(RedemptionReceipt))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("loyalty: redemption %x already recorded", receipt.ID)
+	}
+	if err := r.st.KVPut(catalogueItemKey(item.ProgramID, item.ItemID), item); err != nil {
+		return err
+	}
+	return r.st.KVPut(redemptionKey(receipt.ID), receipt)
+}
+
+// Redemption loads a redemption receipt by ID.
+func (r *Registry) Redemption(id [32]byte) (*RedemptionReceipt, bool, error) {
+	receipt := new(RedemptionReceipt)
+	ok, err := r.st.KVGet(redemptionKey(id), receipt)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if receipt.CostWei == nil {
+		receipt.CostWei = big.NewInt(0)
+	}
+	return receipt, true, nil
+}
+
+func sanitizeCatalogueItem(item *CatalogueItem) (*CatalogueItem, error) {
+	copyItem := *item
+	copyItem.ItemID = strings.TrimSpace(copyItem.ItemID)
+	if copyItem.ItemID == "" || len(copyItem.ItemID) > maxCatalogueItemIDLen {
+		return nil, fmt.Errorf("%w: item id must be 1-%d bytes", ErrInvalidItem, maxCatalogueItemIDLen)
+	}
+	if strings.ContainsAny(copyItem.ItemID, "/ ") {
+		return nil, fmt.Errorf("%w: item id must not contain spaces or '/'", ErrInvalidItem)
+	}
+	copyItem.Name = strings.TrimSpace(copyItem.Name)
+	if len(copyItem.Name) > maxCatalogueItemNameLen {
+		return nil, fmt.Errorf("%w: name must not exceed %d bytes", ErrInvalidItem, maxCatalogueItemNameLen)
+	}
+	if copyItem.CostWei == nil || copyItem.CostWei.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: cost must be positive", ErrInvalidItem)
+	}
+	copyItem.CostWei = new(big.Int).Set(copyItem.CostWei)
+	mode := RedemptionMode(strings.ToLower(strings.TrimSpace(copyItem.Mode)))
+	switch mode {
+	case "":
+		mode = RedemptionModeBurn
+	case RedemptionModeBurn, RedemptionModeTransfer:
+	default:
+		return nil, fmt.Errorf("%w: mode must be burn or transfer", ErrInvalidItem)
+	}
+	copyItem.Mode = string(mode)
+	return &copyItem, nil
+}
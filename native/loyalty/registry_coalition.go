@@ -0,0 +1,483 @@
+package loyalty
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"nhbchain/core/events"
+	nativecommon "nhbchain/native/common"
+)
+
+const (
+	maxCoalitionNameLen = 64
+	maxCoalitionMembers = 32
+	// MinCoalitionPeriodSeconds bounds how often a coalition may settle so
+	// that settlement transactions stay rare.
+	MinCoalitionPeriodSeconds = 3600
+)
+
+var (
+	coalitionPrefix       = []byte("loyalty/coalition/")
+	coalitionMemberPrefix = []byte("loyalty/coalition-member/")
+	coalitionLedgerPrefix = []byte("loyalty/coalition-ledger/")
+	coalitionReportPrefix = []byte("loyalty/coalition-settlement/")
+)
+
+func coalitionKey(id CoalitionID) []byte {
+	key := make([]byte, len(coalitionPrefix)+len(id))
+	copy(key, coalitionPrefix)
+	copy(key[len(coalitionPrefix):], id[:])
+	return key
+}
+
+func coalitionMemberKey(programID ProgramID) []byte {
+	key := make([]byte, len(coalitionMemberPrefix)+len(programID))
+	copy(key, coalitionMemberPrefix)
+	copy(key[len(coalitionMemberPrefix):], programID[:])
+	return key
+}
+
+func coalitionPeriodKey(prefix []byte, id CoalitionID, period uint64) []byte {
+	key := make([]byte, 0, len(prefix)+len(id)+1+20)
+	key = append(key, prefix...)
+	key = append(key, id[:]...)
+	key = append(key, '/')
+	return append(key, fmt.Sprintf("%d", period)...)
+}
+
+// Shared reports whether the coalition funds rewards from its own pool.
+func (c *Coalition) Shared() bool {
+	return c != nil && CoalitionMode(c.Mode) == CoalitionModeSharedPool
+}
+
+// Period returns the settlement period containing the timestamp.
+func (c *Coalition) Period(timestamp uint64) uint64 {
+	if c == nil || c.PeriodSeconds == 0 {
+		return 0
+	}
+	return timestamp / c.PeriodSeconds
+}
+
+// PeriodEnd returns the first timestamp after the supplied period.
+func (c *Coalition) PeriodEnd(period uint64) uint64 {
+	if c == nil {
+		return 0
+	}
+	return (period + 1) * c.PeriodSeconds
+}
+
+// HasMember reports whether the program is a member of the coalition.
+func (c *Coalition) HasMember(programID ProgramID) bool {
+	return c != nil && containsProgram(c.Members, programID)
+}
+
+// CreateCoalition registers a new coalition administered by c.Admin, which
+// defaults to the caller. Only the admin or a caller with ROLE_LOYALTY_ADMIN
+// may create it. Members join by invitation.
+func (r *Registry) CreateCoalition(caller [20]byte, c *Coalition) error {
+	if c == nil {
+		return ErrInvalidCoalition
+	}
+	if err := nativecommon.Guard(r.pauses, moduleName); err != nil {
+		return err
+	}
+	sanitized, err := sanitizeCoalition(c)
+	if err != nil {
+		return err
+	}
+	if isZeroAddress(sanitized.Admin) {
+		sanitized.Admin = caller
+	}
+	if caller != sanitized.Admin && !r.st.HasRole(roleLoyaltyAdmin, caller[:]) {
+		return ErrUnauthorized
+	}
+	exists, err := r.st.KVGet(coalitionKey(sanitized.ID), new(Coalition))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrCoalitionExists
+	}
+	sanitized.Members = nil
+	sanitized.Invited = nil
+	sanitized.Active = true
+	if err := r.st.KVPut(coalitionKey(sanitized.ID), sanitized); err != nil {
+		return err
+	}
+	r.emitCoalition(sanitized, "created", ProgramID{})
+	return nil
+}
+
+// InviteCoalitionMember allows the program to join the coalition. The
+// coalition admin or a caller with ROLE_LOYALTY_ADMIN must authorise it.
+func (r *Registry) InviteCoalitionMember(caller [20]byte, id CoalitionID, programID ProgramID) error {
+	if err := nativecommon.Guard(r.pauses, moduleName); err != nil {
+		return err
+	}
+	coalition, ok, err := r.Coalition(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCoalitionNotFound
+	}
+	if caller != coalition.Admin && !r.st.HasRole(roleLoyaltyAdmin, caller[:]) {
+		return ErrUnauthorized
+	}
+	if found, err := r.st.KVGet(programKey(programID), new(Program)); err != nil {
+		return err
+	} else if !found {
+		return ErrProgramNotFound
+	}
+	if coalition.HasMember(programID) {
+		return ErrCoalitionMember
+	}
+	if containsProgram(coalition.Invited, programID) {
+		return nil
+	}
+	coalition.Invited = append(coalition.Invited, programID)
+	if err := r.st.KVPut(coalitionKey(id), coalition); err != nil {
+		return err
+	}
+	r.emitCoalition(coalition, "invited", programID)
+	return nil
+}
+
+// JoinCoalition accepts an invitation on behalf of the program. Only the
+// program owner may join, and a program belongs to at most one coalition.
+func (r *Registry) JoinCoalition(caller [20]byte, id CoalitionID, programID ProgramID) error {
+	if err := nativecommon.Guard(r.pauses, moduleName); err != nil {
+		return err
+	}
+	coalition, ok, err := r.Coalition(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCoalitionNotFound
+	}
+	program := new(Program)
+	found, err := r.st.KVGet(programKey(programID), program)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrProgramNotFound
+	}
+	if caller != program.Owner {
+		return ErrUnauthorized
+	}
+	if !containsProgram(coalition.Invited, programID) {
+		return ErrNotInvited
+	}
+	if _, member, err := r.CoalitionForProgram(programID); err != nil {
+		return err
+	} else if member {
+		return ErrCoalitionMember
+	}
+	if len(coalition.Members) >= maxCoalitionMembers {
+		return fmt.Errorf("%w: at most %d members", ErrInvalidCoalition, maxCoalitionMembers)
+	}
+	coalition.Invited = removeProgram(coalition.Invited, programID)
+	coalition.Members = append(coalition.Members, programID)
+	if err := r.st.KVPut(coalitionKey(id), coalition); err != nil {
+		return err
+	}
+	if err := r.st.KVPut(coalitionMemberKey(programID), id); err != nil {
+		return err
+	}
+	r.emitCoalition(coalition, "joined", programID)
+	return nil
+}
+
+// LeaveCoalition removes the program from the coalition. The program owner
+// or the coalition admin may remove it. Activity already recorded for open
+// periods is still settled.
+func (r *Registry) LeaveCoalition(caller [20]byte, id CoalitionID, programID ProgramID) error {
+	if err := nativecommon.Guard(r.pauses, moduleName); err != nil {
+		return err
+	}
+	coalition, ok, err := r.Coalition(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCoalitionNotFound
+	}
+	if !coalition.HasMember(programID) {
+		return ErrProgramNotFound
+	}
+	program := new(Program)
+	if _, err := r.st.KVGet(programKey(programID), program); err != nil {
+		return err
+	}
+	if caller != program.Owner && caller != coalition.Admin && !r.st.HasRole(roleLoyaltyAdmin, caller[:]) {
+		return ErrUnauthorized
+	}
+	coalition.Members = removeProgram(coalition.Members, programID)
+	if err := r.st.KVPut(coalitionKey(id), coalition); err != nil {
+		return err
+	}
+	if err := r.st.KVPut(coalitionMemberKey(programID), CoalitionID{}); err != nil {
+		return err
+	}
+	r.emitCoalition(coalition, "left", programID)
+	return nil
+}
+
+// Coalition loads a coalition by ID.
+func (r *Registry) Coalition(id CoalitionID) (*Coalition, bool, error) {
+	coalition := new(Coalition)
+	ok, err := r.st.KVGet(coalitionKey(id), coalition)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return coalition, true, nil
+}
+
+// CoalitionForProgram returns the coalition the program currently belongs
+// to.
+func (r *Registry) CoalitionForProgram(programID ProgramID) (*Coalition, bool, error) {
+	var id CoalitionID
+	ok, err := r.st.KVGet(coalitionMemberKey(programID), &id)
+	if err != nil || !ok || id == (CoalitionID{}) {
+		return nil, false, err
+	}
+	coalition, ok, err := r.Coalition(id)
+	if err != nil || !ok || !coalition.HasMember(programID) {
+		return nil, false, err
+	}
+	return coalition, true, nil
+}
+
+// CoalitionLedger returns the member activity recorded for a period. Periods
+// without activity yield an empty ledger.
+func (r *Registry) CoalitionLedger(id CoalitionID, period uint64) (*CoalitionLedger, error) {
+	ledger := new(CoalitionLedger)
+	ok, err := r.st.KVGet(coalitionPeriodKey(coalitionLedgerPrefix, id, period), ledger)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &CoalitionLedger{CoalitionID: id, Period: period}, nil
+	}
+	for i := range ledger.Entries {
+		if ledger.Entries[i].Issued == nil {
+			ledger.Entries[i].Issued = big.NewInt(0)
+		}
+		if ledger.Entries[i].Redeemed == nil {
+			ledger.Entries[i].Redeemed = big.NewInt(0)
+		}
+	}
+	return ledger, nil
+}
+
+// RecordCoalitionActivity adds rewards issued and redeemed by a member
+// program to the ledger of the period.
+func (r *Registry) RecordCoalitionActivity(id CoalitionID, period uint64, programID ProgramID, issued, redeemed *big.Int) error {
+	ledger, err := r.CoalitionLedger(id, period)
+	if err != nil {
+		return err
+	}
+	idx := -1
+	for i := range ledger.Entries {
+		if ledger.Entries[i].ProgramID == programID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		ledger.Entries = append(ledger.Entries, CoalitionLedgerEntry{ProgramID: programID, Issued: big.NewInt(0), Redeemed: big.NewInt(0)})
+		idx = len(ledger.Entries) - 1
+	}
+	entry := &ledger.Entries[idx]
+	if issued != nil && issued.Sign() > 0 {
+		entry.Issued = new(big.Int).Add(entry.Issued, issued)
+	}
+	if redeemed != nil && redeemed.Sign() > 0 {
+		entry.Redeemed = new(big.Int).Add(entry.Redeemed, redeemed)
+	}
+	return r.st.KVPut(coalitionPeriodKey(coalitionLedgerPrefix, id, period), ledger)
+}
+
+// CoalitionSettlement loads the stored report of a settled period.
+func (r *Registry) CoalitionSettlement(id CoalitionID, period uint64) (*CoalitionSettlement, bool, error) {
+	report := new(CoalitionSettlement)
+	ok, err := r.st.KVGet(coalitionPeriodKey(coalitionReportPrefix, id, period), report)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return report, true, nil
+}
+
+// RecordCoalitionSettlement stores the report of a settled period. Reports
+// are write-once.
+func (r *Registry) RecordCoalitionSettlement(report *CoalitionSettlement) error {
+	if report == nil {
+		return ErrInvalidCoalition
+	}
+	_, settled, err := r.CoalitionSettlement(report.CoalitionID, report.Period)
+	if err != nil {
+		return err
+	}
+	if settled {
+		return ErrPeriodSettled
+	}
+	return r.st.KVPut(coalitionPeriodKey(coalitionReportPrefix, report.CoalitionID, report.Period), report)
+}
+
+// NetPositions returns, for each ledger entry, the member's share of the
+// period's redemptions minus what it redeemed. Each member is entitled to a
+// share proportional to the rewards it issued, so a positive position is
+// owed to the coalition and a negative one is owed by it. Shares round down,
+// leaving dust with the debtor.
+func NetPositions(ledger *CoalitionLedger) []*big.Int {
+	if ledger == nil {
+		return nil
+	}
+	totalIssued := big.NewInt(0)
+	totalRedeemed := big.NewInt(0)
+	for _, entry := range ledger.Entries {
+		totalIssued.Add(totalIssued, valueOrZero(entry.Issued))
+		totalRedeemed.Add(totalRedeemed, valueOrZero(entry.Redeemed))
+	}
+	positions := make([]*big.Int, len(ledger.Entries))
+	for i, entry := range ledger.Entries {
+		share := big.NewInt(0)
+		if totalIssued.Sign() > 0 {
+			share.Mul(totalRedeemed, valueOrZero(entry.Issued))
+			share.Quo(share, totalIssued)
+		}
+		positions[i] = share.Sub(share, valueOrZero(entry.Redeemed))
+	}
+	return positions
+}
+
+// ComputeSettlement derives the transfers that net-settle a period from the
+// members' NetPositions. Debts are matched greedily in program ID order so
+// that every node derives the same transfers. A period without issued
+// rewards has nothing to settle.
+func ComputeSettlement(ledger *CoalitionLedger) []SettlementTransfer {
+	if ledger == nil {
+		return nil
+	}
+	issued := false
+	for _, entry := range ledger.Entries {
+		if entry.Issued != nil && entry.Issued.Sign() > 0 {
+			issued = true
+			break
+		}
+	}
+	if !issued {
+		return nil
+	}
+	type position struct {
+		program ProgramID
+		amount  *big.Int
+	}
+	var debtors, creditors []position
+	for i, net := range NetPositions(ledger) {
+		switch net.Sign() {
+		case 1:
+			debtors = append(debtors, position{program: ledger.Entries[i].ProgramID, amount: net})
+		case -1:
+			creditors = append(creditors, position{program: ledger.Entries[i].ProgramID, amount: net.Neg(net)})
+		}
+	}
+	byProgram := func(list []position) func(i, j int) bool {
+		return func(i, j int) bool {
+			return bytes.Compare(list[i].program[:], list[j].program[:]) < 0
+		}
+	}
+	sort.Slice(debtors, byProgram(debtors))
+	sort.Slice(creditors, byProgram(creditors))
+
+	var transfers []SettlementTransfer
+	for d, c := 0, 0; d < len(debtors) && c < len(creditors); {
+		amount := debtors[d].amount
+		if creditors[c].amount.Cmp(amount) < 0 {
+			amount = creditors[c].amount
+		}
+		transfers = append(transfers, SettlementTransfer{
+			From:   debtors[d].program,
+			To:     creditors[c].program,
+			Amount: new(big.Int).Set(amount),
+			Paid:   big.NewInt(0),
+		})
+		debtors[d].amount = new(big.Int).Sub(debtors[d].amount, amount)
+		creditors[c].amount = new(big.Int).Sub(creditors[c].amount, amount)
+		if debtors[d].amount.Sign() == 0 {
+			d++
+		}
+		if creditors[c].amount.Sign() == 0 {
+			c++
+		}
+	}
+	return transfers
+}
+
+func (r *Registry) emitCoalition(c *Coalition, action string, programID ProgramID) {
+	r.emit(events.LoyaltyCoalitionUpdated{
+		CoalitionID: c.ID,
+		Action:      action,
+		ProgramID:   programID,
+		Mode:        c.Mode,
+		Members:     len(c.Members),
+	})
+}
+
+func sanitizeCoalition(c *Coalition) (*Coalition, error) {
+	copyCoalition := *c
+	if copyCoalition.ID == (CoalitionID{}) {
+		return nil, fmt.Errorf("%w: id must be set", ErrInvalidCoalition)
+	}
+	copyCoalition.Name = strings.TrimSpace(copyCoalition.Name)
+	if copyCoalition.Name == "" || len(copyCoalition.Name) > maxCoalitionNameLen {
+		return nil, fmt.Errorf("%w: name must be 1-%d bytes", ErrInvalidCoalition, maxCoalitionNameLen)
+	}
+	mode := CoalitionMode(strings.ToLower(strings.TrimSpace(copyCoalition.Mode)))
+	switch mode {
+	case "":
+		mode = CoalitionModeNetSettlement
+	case CoalitionModeNetSettlement, CoalitionModeSharedPool:
+	default:
+		return nil, fmt.Errorf("%w: mode must be shared_pool or net_settlement", ErrInvalidCoalition)
+	}
+	copyCoalition.Mode = string(mode)
+	if mode == CoalitionModeSharedPool && isZeroAddress(copyCoalition.Pool) {
+		return nil, fmt.Errorf("%w: shared_pool coalitions require a pool", ErrInvalidCoalition)
+	}
+	if copyCoalition.PeriodSeconds < MinCoalitionPeriodSeconds {
+		return nil, fmt.Errorf("%w: period must be at least %d seconds", ErrInvalidCoalition, MinCoalitionPeriodSeconds)
+	}
+	return &copyCoalition, nil
+}
+
+func containsProgram(list []ProgramID, id ProgramID) bool {
+	for _, candidate := range list {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeProgram(list []ProgramID, id ProgramID) []ProgramID {
+	out := make([]ProgramID, 0, len(list))
+	for _, candidate := range list {
+		if candidate != id {
+			out = append(out, candidate)
+		}
+	}
+	return out
+}
+
+func valueOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return big.NewInt(0)
+	}
+	return v
+}
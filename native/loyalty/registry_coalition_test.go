@@ -0,0 +1,125 @@
+package loyalty_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	loyalty "nhbchain/native/loyalty"
+)
+
+func createCoalitionTestProgram(t *testing.T, registry *loyalty.Registry, owner [20]byte, idByte byte) loyalty.ProgramID {
+	t.Helper()
+	var id loyalty.ProgramID
+	id[31] = idByte
+	var pool [20]byte
+	pool[0] = idByte
+	if err := registry.CreateProgram(owner, &loyalty.Program{
+		ID:              id,
+		Owner:           owner,
+		Pool:            pool,
+		TokenSymbol:     "ZNHB",
+		AccrualBps:      100,
+		DailyCapProgram: big.NewInt(1_000),
+		Active:          true,
+	}); err != nil {
+		t.Fatalf("create program: %v", err)
+	}
+	return id
+}
+
+func TestRegistryCoalitionMembership(t *testing.T) {
+	registry, _ := newTestRegistry(t)
+	var admin, ownerA, ownerB [20]byte
+	admin[19], ownerA[19], ownerB[19] = 0x01, 0x02, 0x03
+	programA := createCoalitionTestProgram(t, registry, ownerA, 0xA1)
+	programB := createCoalitionTestProgram(t, registry, ownerB, 0xB1)
+
+	var id, otherID loyalty.CoalitionID
+	id[31], otherID[31] = 0x10, 0x20
+	if err := registry.CreateCoalition(admin, &loyalty.Coalition{ID: id, Name: "High Street", PeriodSeconds: 60}); !errors.Is(err, loyalty.ErrInvalidCoalition) {
+		t.Fatalf("expected short period to be rejected, got %v", err)
+	}
+	if err := registry.CreateCoalition(admin, &loyalty.Coalition{ID: id, Name: "High Street", Mode: "shared_pool", PeriodSeconds: 86_400}); !errors.Is(err, loyalty.ErrInvalidCoalition) {
+		t.Fatalf("expected shared pool without pool to be rejected, got %v", err)
+	}
+	for _, cid := range []loyalty.CoalitionID{id, otherID} {
+		if err := registry.CreateCoalition(admin, &loyalty.Coalition{ID: cid, Name: "High Street", PeriodSeconds: 86_400}); err != nil {
+			t.Fatalf("create coalition: %v", err)
+		}
+	}
+
+	if err := registry.JoinCoalition(ownerA, id, programA); !errors.Is(err, loyalty.ErrNotInvited) {
+		t.Fatalf("expected uninvited join to fail, got %v", err)
+	}
+	if err := registry.InviteCoalitionMember(ownerA, id, programA); !errors.Is(err, loyalty.ErrUnauthorized) {
+		t.Fatalf("expected non-admin invite to fail, got %v", err)
+	}
+	for _, program := range []loyalty.ProgramID{programA, programB} {
+		if err := registry.InviteCoalitionMember(admin, id, program); err != nil {
+			t.Fatalf("invite: %v", err)
+		}
+	}
+	if err := registry.JoinCoalition(ownerB, id, programA); !errors.Is(err, loyalty.ErrUnauthorized) {
+		t.Fatalf("expected join by another owner to fail, got %v", err)
+	}
+	if err := registry.JoinCoalition(ownerA, id, programA); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if err := registry.InviteCoalitionMember(admin, otherID, programA); err != nil {
+		t.Fatalf("invite to second coalition: %v", err)
+	}
+	if err := registry.JoinCoalition(ownerA, otherID, programA); !errors.Is(err, loyalty.ErrCoalitionMember) {
+		t.Fatalf("expected membership of a second coalition to fail, got %v", err)
+	}
+	coalition, ok, err := registry.CoalitionForProgram(programA)
+	if err != nil || !ok || coalition.ID != id {
+		t.Fatalf("unexpected coalition for program: %+v ok=%v err=%v", coalition, ok, err)
+	}
+
+	if err := registry.LeaveCoalition(ownerB, id, programA); !errors.Is(err, loyalty.ErrUnauthorized) {
+		t.Fatalf("expected leave by another owner to fail, got %v", err)
+	}
+	if err := registry.LeaveCoalition(admin, id, programA); err != nil {
+		t.Fatalf("leave: %v", err)
+	}
+	if _, ok, _ := registry.CoalitionForProgram(programA); ok {
+		t.Fatalf("expected program to have left the coalition")
+	}
+}
+
+func TestComputeSettlementMatchesDebtorsToCreditors(t *testing.T) {
+	var a, b, c loyalty.ProgramID
+	a[31], b[31], c[31] = 1, 2, 3
+	ledger := &loyalty.CoalitionLedger{Entries: []loyalty.CoalitionLedgerEntry{
+		{ProgramID: c, Issued: big.NewInt(0), Redeemed: big.NewInt(10)},
+		{ProgramID: a, Issued: big.NewInt(2), Redeemed: big.NewInt(0)},
+		{ProgramID: b, Issued: big.NewInt(1), Redeemed: big.NewInt(0)},
+	}}
+	// a is entitled to 2/3 of the redemptions and b to 1/3; both shares
+	// round down, so one unit of dust stays unsettled.
+	transfers := loyalty.ComputeSettlement(ledger)
+	if len(transfers) != 2 {
+		t.Fatalf("expected two transfers, got %+v", transfers)
+	}
+	if transfers[0].From != a || transfers[0].To != c || transfers[0].Amount.Int64() != 6 {
+		t.Fatalf("unexpected first transfer: %+v", transfers[0])
+	}
+	if transfers[1].From != b || transfers[1].To != c || transfers[1].Amount.Int64() != 3 {
+		t.Fatalf("unexpected second transfer: %+v", transfers[1])
+	}
+
+	balanced := &loyalty.CoalitionLedger{Entries: []loyalty.CoalitionLedgerEntry{
+		{ProgramID: a, Issued: big.NewInt(50), Redeemed: big.NewInt(50)},
+		{ProgramID: b, Issued: big.NewInt(50), Redeemed: big.NewInt(50)},
+	}}
+	if transfers := loyalty.ComputeSettlement(balanced); len(transfers) != 0 {
+		t.Fatalf("expected a balanced period to need no transfers, got %+v", transfers)
+	}
+	redeemOnly := &loyalty.CoalitionLedger{Entries: []loyalty.CoalitionLedgerEntry{
+		{ProgramID: a, Issued: big.NewInt(0), Redeemed: big.NewInt(50)},
+	}}
+	if transfers := loyalty.ComputeSettlement(redeemOnly); len(transfers) != 0 {
+		t.Fatalf("expected no transfers without issued rewards, got %+v", transfers)
+	}
+}
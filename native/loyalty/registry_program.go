@@ -5,6 +5,7 @@ import nativecommon "nhbchain/native/common"
 var (
 	programPrefix             = []byte("loyalty/program/")
 	programOwnerIndexPref     = []byte("loyalty/merchant/")
+	programIndexKeyBytes      = []byte("loyalty/program-index")
 	businessPrefix            = []byte("loyalty/business/")
 	businessOwnerPrefix       = []byte("loyalty/business-owner/")
 	merchantBusinessIndexPref = []byte("loyalty/merchant-index/")
@@ -26,6 +27,10 @@ func merchantIdxKey(owner [20]byte) []byte {
 	return key
 }
 
+func programIndexKey() []byte {
+	return append([]byte(nil), programIndexKeyBytes...)
+}
+
 // ProgramStorageKey returns the raw storage key used to persist program metadata.
 func ProgramStorageKey(id ProgramID) []byte {
 	return programKey(id)
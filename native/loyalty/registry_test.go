@@ -86,6 +86,9 @@ func TestRegistryCreateAndListPrograms(t *testing.T) {
 	if len(ids) != 1 || ids[0] != id {
 		t.Fatalf("expected one program id, got %v", ids)
 	}
+	if all, err := registry.ListPrograms(); err != nil || len(all) != 1 || all[0] != id {
+		t.Fatalf("expected the program in the global index, got %v (err %v)", all, err)
+	}
 	if len(emitter.events) != 1 {
 		t.Fatalf("expected one event, got %d", len(emitter.events))
 	}
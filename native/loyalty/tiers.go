@@ -0,0 +1,119 @@
+package loyalty
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// MaxTiers bounds the number of tiers a program may define.
+	MaxTiers = 8
+	// MaxTierWindowDays bounds the trailing spend window so that tier
+	// resolution reads at most this many daily spend meters.
+	MaxTierWindowDays = 90
+	// MaxRewardExpiryDays bounds reward expiry to roughly five years.
+	MaxRewardExpiryDays = 5 * 365
+
+	secondsPerDay = 24 * 60 * 60
+)
+
+// TierFor returns the tier earned by trailingSpend together with the accrual
+// rate that applies. Tiers are ordered by ascending MinSpendWei, so the last
+// tier reached wins. The returned tier is nil when the user is below every
+// tier and the program's base AccrualBps applies.
+func (p *Program) TierFor(trailingSpend *big.Int) (*Tier, uint32) {
+	if p == nil {
+		return nil, 0
+	}
+	var (
+		matched *Tier
+		bps     = p.AccrualBps
+	)
+	if trailingSpend == nil {
+		return nil, bps
+	}
+	for i := range p.Tiers {
+		tier := &p.Tiers[i]
+		if tier.MinSpendWei == nil || trailingSpend.Cmp(tier.MinSpendWei) < 0 {
+			break
+		}
+		matched = tier
+		bps = tier.AccrualBps
+	}
+	return matched, bps
+}
+
+// Tiered reports whether the program defines spend tiers.
+func (p *Program) Tiered() bool {
+	return p != nil && len(p.Tiers) > 0 && p.TierWindowDays > 0
+}
+
+// Expiring reports whether the program expires accrued rewards.
+func (p *Program) Expiring() bool {
+	return p != nil && p.RewardExpiryDays > 0
+}
+
+// RewardExpiry returns the unix timestamp at which rewards accrued at
+// timestamp expire, or zero when the program does not expire rewards.
+func (p *Program) RewardExpiry(timestamp uint64) uint64 {
+	if !p.Expiring() {
+		return 0
+	}
+	return timestamp + uint64(p.RewardExpiryDays)*secondsPerDay
+}
+
+// DayIndex converts a unix timestamp into the UTC day number used to key the
+// trailing spend meters.
+func DayIndex(timestamp uint64) uint64 {
+	return timestamp / secondsPerDay
+}
+
+func sanitizeTiers(p *Program) error {
+	if p.TierWindowDays > MaxTierWindowDays {
+		return fmt.Errorf("%w: tier window must not exceed %d days", ErrInvalidTier, MaxTierWindowDays)
+	}
+	if p.RewardExpiryDays > MaxRewardExpiryDays {
+		return fmt.Errorf("%w: reward expiry must not exceed %d days", ErrInvalidProgram, MaxRewardExpiryDays)
+	}
+	if p.RewardExpiryDays > 0 && isZeroAddress(p.Pool) {
+		return fmt.Errorf("%w: reward expiry requires a program pool", ErrInvalidProgram)
+	}
+	if len(p.Tiers) == 0 {
+		p.Tiers = nil
+		return nil
+	}
+	if len(p.Tiers) > MaxTiers {
+		return fmt.Errorf("%w: at most %d tiers allowed", ErrInvalidTier, MaxTiers)
+	}
+	if p.TierWindowDays == 0 {
+		return fmt.Errorf("%w: tier window required when tiers are defined", ErrInvalidTier)
+	}
+	tiers := make([]Tier, len(p.Tiers))
+	seen := make(map[string]struct{}, len(p.Tiers))
+	var previous *big.Int
+	for i, tier := range p.Tiers {
+		name := strings.TrimSpace(tier.Name)
+		if name == "" {
+			return fmt.Errorf("%w: tier %d name required", ErrInvalidTier, i)
+		}
+		key := strings.ToLower(name)
+		if _, dup := seen[key]; dup {
+			return fmt.Errorf("%w: duplicate tier %q", ErrInvalidTier, name)
+		}
+		seen[key] = struct{}{}
+		if tier.MinSpendWei == nil || tier.MinSpendWei.Sign() <= 0 {
+			return fmt.Errorf("%w: tier %q min spend must be positive", ErrInvalidTier, name)
+		}
+		if previous != nil && tier.MinSpendWei.Cmp(previous) <= 0 {
+			return fmt.Errorf("%w: tiers must be ordered by increasing min spend", ErrInvalidTier)
+		}
+		if tier.AccrualBps > 100_000 {
+			return fmt.Errorf("%w: %d", ErrAccrualBpsTooHigh, tier.AccrualBps)
+		}
+		previous = tier.MinSpendWei
+		tiers[i] = Tier{Name: name, MinSpendWei: new(big.Int).Set(tier.MinSpendWei), AccrualBps: tier.AccrualBps}
+	}
+	p.Tiers = tiers
+	return nil
+}
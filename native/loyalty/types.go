@@ -24,6 +24,139 @@ type Program struct {
 	StartTime          uint64
 	EndTime            uint64
 	Active             bool
+	// Tiers raise the accrual rate once a user's spend with the program over
+	// the trailing TierWindowDays reaches a tier's MinSpendWei. Users below
+	// the lowest tier accrue at AccrualBps.
+	Tiers          []Tier `rlp:"optional"`
+	TierWindowDays uint32 `rlp:"optional"`
+	// RewardExpiryDays expires accrued rewards that are still unredeemed
+	// after the given number of days. Zero keeps rewards forever.
+	RewardExpiryDays uint32 `rlp:"optional"`
+}
+
+// Tier is one spend level of a tiered program.
+type Tier struct {
+	Name        string
+	MinSpendWei *big.Int
+	AccrualBps  uint32
+}
+
+// RewardLot records one accrual of an expiring program so that redemptions
+// and expiry consume rewards first-in, first-out.
+type RewardLot struct {
+	Amount    *big.Int
+	Remaining *big.Int
+	AccruedAt uint64
+	ExpiresAt uint64
+}
+
+// RewardLotRef names the reward lots of one user in one program. The expiry
+// sweep schedules refs by the UTC day on which their earliest lot expires.
+type RewardLotRef struct {
+	ProgramID ProgramID
+	Addr      [20]byte
+}
+
+// RedemptionMode selects what happens to rewards spent on a catalogue item.
+type RedemptionMode string
+
+const (
+	// RedemptionModeBurn destroys the redeemed rewards.
+	RedemptionModeBurn RedemptionMode = "burn"
+	// RedemptionModeTransfer returns the redeemed rewards to the program pool.
+	RedemptionModeTransfer RedemptionMode = "transfer"
+)
+
+// CatalogueItem is a merchant-defined reward users can redeem against a
+// program. Stock zero means unlimited.
+type CatalogueItem struct {
+	ProgramID ProgramID
+	ItemID    string
+	Name      string
+	CostWei   *big.Int
+	Mode      string
+	Stock     uint64
+	Redeemed  uint64
+	Active    bool
+}
+
+// RedemptionReceipt is the on-chain record of a catalogue redemption. Points
+// of sale verify a redemption by loading its receipt.
+type RedemptionReceipt struct {
+	ID         [32]byte
+	ProgramID  ProgramID
+	ItemID     string
+	User       [20]byte
+	Quantity   uint64
+	CostWei    *big.Int
+	Token      string
+	Mode       string
+	RedeemedAt uint64
+}
+
+// CoalitionID uniquely identifies a cross-merchant loyalty coalition.
+type CoalitionID [32]byte
+
+// CoalitionMode selects how a coalition funds rewards that are earned at one
+// member and redeemed at another.
+type CoalitionMode string
+
+const (
+	// CoalitionModeSharedPool funds every member's rewards from the
+	// coalition pool and returns transferred redemptions to it.
+	CoalitionModeSharedPool CoalitionMode = "shared_pool"
+	// CoalitionModeNetSettlement keeps each member's paymaster and settles
+	// the difference between rewards issued and redeemed once per period.
+	CoalitionModeNetSettlement CoalitionMode = "net_settlement"
+)
+
+// Coalition groups the programs of several businesses so that rewards earned
+// at any member can be redeemed at every other member. A program belongs to
+// at most one coalition.
+type Coalition struct {
+	ID            CoalitionID
+	Name          string
+	Admin         [20]byte
+	Mode          string
+	Pool          [20]byte
+	PeriodSeconds uint64
+	Members       []ProgramID
+	Invited       []ProgramID
+	Active        bool
+}
+
+// CoalitionLedgerEntry accumulates the rewards a member program issued and
+// accepted for redemption during one settlement period.
+type CoalitionLedgerEntry struct {
+	ProgramID ProgramID
+	Issued    *big.Int
+	Redeemed  *big.Int
+}
+
+// CoalitionLedger is the per-period activity of a coalition's members.
+type CoalitionLedger struct {
+	CoalitionID CoalitionID
+	Period      uint64
+	Entries     []CoalitionLedgerEntry
+}
+
+// SettlementTransfer moves value from a member that issued more rewards than
+// it redeemed to a member that redeemed more than it issued. Paid is lower
+// than Amount when the debtor's paymaster could not cover the full amount.
+type SettlementTransfer struct {
+	From   ProgramID
+	To     ProgramID
+	Amount *big.Int
+	Paid   *big.Int
+}
+
+// CoalitionSettlement is the stored report of a settled period.
+type CoalitionSettlement struct {
+	CoalitionID CoalitionID
+	Period      uint64
+	Entries     []CoalitionLedgerEntry
+	Transfers   []SettlementTransfer
+	SettledAt   uint64
 }
 
 // BusinessID uniquely identifies a registered business entity.
@@ -24,6 +24,7 @@ type lifecycleState interface {
 	KVDelete(key []byte) error
 	GetAccount(addr []byte) (*types.Account, error)
 	PutAccount(addr []byte, account *types.Account) error
+	AssetTransfer(from, to []byte, symbol string, amount *big.Int) error
 }
 
 var (
@@ -31,8 +32,12 @@ var (
 	authorizationNoncePref    = []byte("pos/auth/nonce/")
 	authorizationPendingIndex = []byte("pos/auth/pending")
 	authorizationIntentRefIdx = []byte("pos/auth/intent/")
+	holdAddressSeedPrefix     = "pos/hold/"
 )
 
+// defaultToken is the asset locked by authorizations that do not name one.
+const defaultToken = "ZNHB"
+
 // AuthorizationStatus captures the lifecycle state for a payment authorization.
 type AuthorizationStatus uint8
 
@@ -49,6 +54,9 @@ const (
 	// AuthorizationStatusExpired marks authorizations that expired before
 	// capture. The locked balance has been returned to the payer.
 	AuthorizationStatusExpired
+	// AuthorizationStatusRefunded marks captured authorizations whose
+	// captured amount has been refunded to the payer in full.
+	AuthorizationStatusRefunded
 )
 
 // Authorization represents a locked POS payment authorization tracked on-chain.
@@ -56,6 +64,7 @@ type Authorization struct {
 	ID             [32]byte
 	Payer          [20]byte
 	Merchant       [20]byte
+	Token          string
 	Amount         *big.Int
 	CapturedAmount *big.Int
 	RefundedAmount *big.Int
@@ -65,6 +74,10 @@ type Authorization struct {
 	CreatedAt      uint64
 	UpdatedAt      uint64
 	VoidReason     string
+	// MerchantRefundedAmount is the part of CapturedAmount the merchant has
+	// sent back to the payer through Refund. RefundedAmount only covers the
+	// uncaptured remainder released from the hold.
+	MerchantRefundedAmount *big.Int
 }
 
 // Clone returns a deep copy of the authorization to avoid mutating shared
@@ -83,10 +96,44 @@ func (a *Authorization) Clone() *Authorization {
 	if a.RefundedAmount != nil {
 		clone.RefundedAmount = new(big.Int).Set(a.RefundedAmount)
 	}
+	if a.MerchantRefundedAmount != nil {
+		clone.MerchantRefundedAmount = new(big.Int).Set(a.MerchantRefundedAmount)
+	}
 	clone.IntentRef = append([]byte(nil), a.IntentRef...)
 	return &clone
 }
 
+// RemainingAmount returns the authorized amount that has not been captured
+// yet.
+func (a *Authorization) RemainingAmount() *big.Int {
+	if a == nil || a.Amount == nil {
+		return big.NewInt(0)
+	}
+	remaining := new(big.Int).Set(a.Amount)
+	if a.CapturedAmount != nil {
+		remaining.Sub(remaining, a.CapturedAmount)
+	}
+	if remaining.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return remaining
+}
+
+// RefundableAmount returns the captured amount the merchant can still refund.
+func (a *Authorization) RefundableAmount() *big.Int {
+	if a == nil || a.CapturedAmount == nil {
+		return big.NewInt(0)
+	}
+	refundable := new(big.Int).Set(a.CapturedAmount)
+	if a.MerchantRefundedAmount != nil {
+		refundable.Sub(refundable, a.MerchantRefundedAmount)
+	}
+	if refundable.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return refundable
+}
+
 // Lifecycle orchestrates the authorization/capture/void flow for card-like POS
 // transactions.
 type Lifecycle struct {
@@ -140,11 +187,22 @@ var (
 	errAuthorizationInvalidAmt   = errors.New("pos: amount must be positive")
 	errAuthorizationInsufficient = errors.New("pos: insufficient balance")
 	errAuthorizationUnauthorized = errors.New("pos: caller is not authorized for this authorization")
+	errAuthorizationNotPending   = errors.New("pos: authorization is not pending")
+	errAuthorizationOverCapture  = errors.New("pos: capture exceeds authorization")
+	errAuthorizationOverRefund   = errors.New("pos: refund exceeds captured amount")
+	errAuthorizationBadExpiry    = errors.New("pos: expiry cannot be shortened")
 )
 
 // Authorize locks the supplied ZapNHB amount on the payer account and records a
 // payment authorization that can later be captured or voided.
 func (l *Lifecycle) Authorize(payer, merchant [20]byte, amount *big.Int, expiry uint64, intentRef []byte) (*Authorization, error) {
+	return l.AuthorizeAsset(payer, merchant, defaultToken, amount, expiry, intentRef)
+}
+
+// AuthorizeAsset locks amount of token for the merchant. ZapNHB is locked on
+// the payer account; NHB and issued assets move into the per-asset hold
+// address until the authorization is captured or voided.
+func (l *Lifecycle) AuthorizeAsset(payer, merchant [20]byte, token string, amount *big.Int, expiry uint64, intentRef []byte) (*Authorization, error) {
 	if l == nil || l.state == nil {
 		return nil, errLifecycleUninitialised
 	}
@@ -157,49 +215,38 @@ func (l *Lifecycle) Authorize(payer, merchant [20]byte, amount *big.Int, expiry
 	if amount == nil || amount.Sign() <= 0 {
 		return nil, errAuthorizationInvalidAmt
 	}
+	token = normalizeToken(token)
 	now := l.nowFn().UTC()
 	if expiry == 0 || uint64(now.Unix()) >= expiry {
 		return nil, errAuthorizationExpired
 	}
-	payerAcc, err := l.state.GetAccount(payer[:])
-	if err != nil {
-		return nil, err
-	}
-	payerAcc = cloneAccount(payerAcc)
-	if payerAcc.BalanceZNHB.Cmp(amount) < 0 {
-		return nil, errAuthorizationInsufficient
-	}
-	payerAcc.BalanceZNHB = new(big.Int).Sub(payerAcc.BalanceZNHB, amount)
-	payerAcc.LockedZNHB = new(big.Int).Add(payerAcc.LockedZNHB, amount)
-	if err := l.state.PutAccount(payer[:], payerAcc); err != nil {
+	if err := l.lockFunds(payer, token, amount); err != nil {
 		return nil, err
 	}
 	authID, nonce, err := l.nextAuthorizationID(payer)
 	if err != nil {
 		// restore balance changes before returning
-		payerAcc.BalanceZNHB = new(big.Int).Add(payerAcc.BalanceZNHB, amount)
-		payerAcc.LockedZNHB = new(big.Int).Sub(payerAcc.LockedZNHB, amount)
-		_ = l.state.PutAccount(payer[:], payerAcc)
+		l.unlockFunds(payer, token, amount)
 		return nil, err
 	}
 	rollback := func() {
 		l.revertAuthorizationNonce(payer, nonce)
-		payerAcc.BalanceZNHB = new(big.Int).Add(payerAcc.BalanceZNHB, amount)
-		payerAcc.LockedZNHB = new(big.Int).Sub(payerAcc.LockedZNHB, amount)
-		_ = l.state.PutAccount(payer[:], payerAcc)
+		l.unlockFunds(payer, token, amount)
 	}
 	record := &Authorization{
-		ID:             authID,
-		Payer:          payer,
-		Merchant:       merchant,
-		Amount:         new(big.Int).Set(amount),
-		CapturedAmount: big.NewInt(0),
-		RefundedAmount: big.NewInt(0),
-		Expiry:         expiry,
-		IntentRef:      append([]byte(nil), intentRef...),
-		Status:         AuthorizationStatusPending,
-		CreatedAt:      uint64(now.Unix()),
-		UpdatedAt:      uint64(now.Unix()),
+		ID:                     authID,
+		Payer:                  payer,
+		Merchant:               merchant,
+		Token:                  token,
+		Amount:                 new(big.Int).Set(amount),
+		CapturedAmount:         big.NewInt(0),
+		RefundedAmount:         big.NewInt(0),
+		Expiry:                 expiry,
+		IntentRef:              append([]byte(nil), intentRef...),
+		Status:                 AuthorizationStatusPending,
+		CreatedAt:              uint64(now.Unix()),
+		UpdatedAt:              uint64(now.Unix()),
+		MerchantRefundedAmount: big.NewInt(0),
 	}
 	if err := l.persistAuthorization(record); err != nil {
 		rollback()
@@ -223,6 +270,7 @@ func (l *Lifecycle) Authorize(payer, merchant [20]byte, amount *big.Int, expiry
 		AuthorizationID: authID,
 		Payer:           payer,
 		Merchant:        merchant,
+		Token:           token,
 		Amount:          new(big.Int).Set(amount),
 		Expiry:          expiry,
 		IntentRef:       append([]byte(nil), intentRef...),
@@ -236,7 +284,21 @@ func (l *Lifecycle) Authorize(payer, merchant [20]byte, amount *big.Int, expiry
 // for -- capturing someone else's authorized payment is exactly the kind of
 // unauthorized-payload-trust bug this check exists to prevent (see
 // applyPOSCapture, which passes the transaction's recovered signer here).
+// Capture closes the authorization; use CapturePartial to leave the
+// remainder open for further captures.
 func (l *Lifecycle) Capture(id [32]byte, amount *big.Int, caller [20]byte) (*Authorization, error) {
+	return l.capture(id, amount, caller, true)
+}
+
+// CapturePartial transfers amount of the locked funds to the merchant and
+// keeps the authorization pending so the remainder can be captured later, as
+// with split shipments. The authorization closes once the captured total
+// reaches the authorized amount. The caller rules match Capture.
+func (l *Lifecycle) CapturePartial(id [32]byte, amount *big.Int, caller [20]byte) (*Authorization, error) {
+	return l.capture(id, amount, caller, false)
+}
+
+func (l *Lifecycle) capture(id [32]byte, amount *big.Int, caller [20]byte, final bool) (*Authorization, error) {
 	if l == nil || l.state == nil {
 		return nil, errLifecycleUninitialised
 	}
@@ -251,7 +313,7 @@ func (l *Lifecycle) Capture(id [32]byte, amount *big.Int, caller [20]byte) (*Aut
 		return nil, errAuthorizationUnauthorized
 	}
 	originalAuth := auth.Clone()
-	if auth.Status == AuthorizationStatusCaptured {
+	if auth.Status == AuthorizationStatusCaptured || auth.Status == AuthorizationStatusRefunded {
 		return nil, errAuthorizationConsumed
 	}
 	if auth.Status == AuthorizationStatusVoided {
@@ -268,67 +330,147 @@ func (l *Lifecycle) Capture(id [32]byte, amount *big.Int, caller [20]byte) (*Aut
 		}
 		return updated, errAuthorizationExpired
 	}
-	if amount.Cmp(auth.Amount) > 0 {
-		return nil, fmt.Errorf("pos: capture exceeds authorization")
+	remaining := auth.RemainingAmount()
+	if amount.Cmp(remaining) > 0 {
+		return nil, errAuthorizationOverCapture
 	}
-	payerAcc, err := l.state.GetAccount(auth.Payer[:])
+	release := big.NewInt(0)
+	closing := final || amount.Cmp(remaining) == 0
+	if closing {
+		release = new(big.Int).Sub(remaining, amount)
+	}
+	restore, err := l.settle(auth, amount, release)
 	if err != nil {
 		return nil, err
 	}
-	merchantAcc, err := l.state.GetAccount(auth.Merchant[:])
-	if err != nil {
+	auth.CapturedAmount = new(big.Int).Add(auth.CapturedAmount, amount)
+	if closing {
+		auth.Status = AuthorizationStatusCaptured
+		auth.RefundedAmount = new(big.Int).Set(release)
+	}
+	auth.UpdatedAt = uint64(now.Unix())
+	auth.VoidReason = ""
+	if err := l.persistAuthorization(auth); err != nil {
+		// best-effort rollback; balances restored to previous state
+		restore()
 		return nil, err
 	}
-	payerAcc = cloneAccount(payerAcc)
-	merchantAcc = cloneAccount(merchantAcc)
-	originalPayer := cloneAccount(payerAcc)
-	originalMerchant := cloneAccount(merchantAcc)
-	if payerAcc.LockedZNHB.Cmp(auth.Amount) < 0 {
-		return nil, fmt.Errorf("pos: locked balance inconsistent")
+	if closing {
+		if err := l.removePendingAuthorization(auth.ID); err != nil {
+			restore()
+			if originalAuth != nil {
+				_ = l.persistAuthorization(originalAuth)
+				_ = l.addPendingAuthorization(originalAuth.ID)
+			}
+			return nil, err
+		}
 	}
-	refund := new(big.Int).Sub(auth.Amount, amount)
-	payerAcc.LockedZNHB = new(big.Int).Sub(payerAcc.LockedZNHB, auth.Amount)
-	if refund.Sign() > 0 {
-		payerAcc.BalanceZNHB = new(big.Int).Add(payerAcc.BalanceZNHB, refund)
+	l.emitter.Emit(events.PaymentCaptured{
+		AuthorizationID: auth.ID,
+		Payer:           auth.Payer,
+		Merchant:        auth.Merchant,
+		CapturedAmount:  new(big.Int).Set(amount),
+		RefundedAmount:  new(big.Int).Set(release),
+		TotalCaptured:   new(big.Int).Set(auth.CapturedAmount),
+		Final:           closing,
+	})
+	return auth.Clone(), nil
+}
+
+// Increment locks amount more on a pending authorization, for example to add
+// a tip after the original authorization. Only the payer may increment, since
+// the extra funds come out of their balance. A non-zero expiry extends the
+// authorization; it can never be moved earlier.
+func (l *Lifecycle) Increment(id [32]byte, amount *big.Int, expiry uint64, caller [20]byte) (*Authorization, error) {
+	if l == nil || l.state == nil {
+		return nil, errLifecycleUninitialised
 	}
-	merchantAcc.BalanceZNHB = new(big.Int).Add(merchantAcc.BalanceZNHB, amount)
-	if err := l.state.PutAccount(auth.Payer[:], payerAcc); err != nil {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, errAuthorizationInvalidAmt
+	}
+	auth, err := l.loadAuthorization(id)
+	if err != nil {
 		return nil, err
 	}
-	if err := l.state.PutAccount(auth.Merchant[:], merchantAcc); err != nil {
+	if !bytes.Equal(auth.Payer[:], caller[:]) {
+		return nil, errAuthorizationUnauthorized
+	}
+	if auth.Status != AuthorizationStatusPending {
+		return nil, errAuthorizationNotPending
+	}
+	now := l.nowFn().UTC()
+	if uint64(now.Unix()) >= auth.Expiry {
+		return nil, errAuthorizationExpired
+	}
+	if expiry != 0 && expiry < auth.Expiry {
+		return nil, errAuthorizationBadExpiry
+	}
+	if err := l.lockFunds(auth.Payer, auth.Token, amount); err != nil {
 		return nil, err
 	}
-	auth.Status = AuthorizationStatusCaptured
-	auth.CapturedAmount = new(big.Int).Set(amount)
-	auth.RefundedAmount = new(big.Int).Set(refund)
+	auth.Amount = new(big.Int).Add(auth.Amount, amount)
+	if expiry != 0 {
+		auth.Expiry = expiry
+	}
 	auth.UpdatedAt = uint64(now.Unix())
-	auth.VoidReason = ""
 	if err := l.persistAuthorization(auth); err != nil {
-		// best-effort rollback; balances restored to previous state
-		payerAcc.LockedZNHB = new(big.Int).Add(payerAcc.LockedZNHB, auth.Amount)
-		if refund.Sign() > 0 {
-			payerAcc.BalanceZNHB = new(big.Int).Sub(payerAcc.BalanceZNHB, refund)
-		}
-		_ = l.state.PutAccount(auth.Payer[:], payerAcc)
-		merchantAcc.BalanceZNHB = new(big.Int).Sub(merchantAcc.BalanceZNHB, amount)
-		_ = l.state.PutAccount(auth.Merchant[:], merchantAcc)
+		l.unlockFunds(auth.Payer, auth.Token, amount)
 		return nil, err
 	}
-	if err := l.removePendingAuthorization(auth.ID); err != nil {
-		_ = l.state.PutAccount(auth.Payer[:], originalPayer)
-		_ = l.state.PutAccount(auth.Merchant[:], originalMerchant)
-		if originalAuth != nil {
-			_ = l.persistAuthorization(originalAuth)
-			_ = l.addPendingAuthorization(originalAuth.ID)
-		}
+	l.emitter.Emit(events.PaymentIncremented{
+		AuthorizationID: auth.ID,
+		Payer:           auth.Payer,
+		Merchant:        auth.Merchant,
+		Amount:          new(big.Int).Set(amount),
+		TotalAmount:     new(big.Int).Set(auth.Amount),
+		Expiry:          auth.Expiry,
+	})
+	return auth.Clone(), nil
+}
+
+// Refund sends amount of the captured funds from the merchant back to the
+// payer. Only the merchant may refund, and the refunded total can never
+// exceed what was captured. Refunds are recorded on the authorization so
+// they stay linked to the original payment. An authorization that has been
+// closed and refunded in full moves to AuthorizationStatusRefunded.
+func (l *Lifecycle) Refund(id [32]byte, amount *big.Int, reason string, caller [20]byte) (*Authorization, error) {
+	if l == nil || l.state == nil {
+		return nil, errLifecycleUninitialised
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, errAuthorizationInvalidAmt
+	}
+	auth, err := l.loadAuthorization(id)
+	if err != nil {
 		return nil, err
 	}
-	l.emitter.Emit(events.PaymentCaptured{
+	if !bytes.Equal(auth.Merchant[:], caller[:]) {
+		return nil, errAuthorizationUnauthorized
+	}
+	if amount.Cmp(auth.RefundableAmount()) > 0 {
+		return nil, errAuthorizationOverRefund
+	}
+	restore, err := l.transfer(auth.Merchant, auth.Payer, auth.Token, amount)
+	if err != nil {
+		return nil, err
+	}
+	now := l.nowFn().UTC()
+	auth.MerchantRefundedAmount = new(big.Int).Add(auth.MerchantRefundedAmount, amount)
+	if auth.Status == AuthorizationStatusCaptured && auth.RefundableAmount().Sign() == 0 {
+		auth.Status = AuthorizationStatusRefunded
+	}
+	auth.UpdatedAt = uint64(now.Unix())
+	if err := l.persistAuthorization(auth); err != nil {
+		restore()
+		return nil, err
+	}
+	l.emitter.Emit(events.PaymentRefunded{
 		AuthorizationID: auth.ID,
 		Payer:           auth.Payer,
 		Merchant:        auth.Merchant,
-		CapturedAmount:  new(big.Int).Set(amount),
-		RefundedAmount:  new(big.Int).Set(refund),
+		Amount:          new(big.Int).Set(amount),
+		TotalRefunded:   new(big.Int).Set(auth.MerchantRefundedAmount),
+		Reason:          strings.TrimSpace(reason),
 	})
 	return auth.Clone(), nil
 }
@@ -348,7 +490,7 @@ func (l *Lifecycle) Void(id [32]byte, reason string, caller [20]byte) (*Authoriz
 	if !bytes.Equal(auth.Payer[:], caller[:]) && !bytes.Equal(auth.Merchant[:], caller[:]) {
 		return nil, errAuthorizationUnauthorized
 	}
-	if auth.Status == AuthorizationStatusCaptured {
+	if auth.Status == AuthorizationStatusCaptured || auth.Status == AuthorizationStatusRefunded {
 		return nil, errAuthorizationConsumed
 	}
 	if auth.Status == AuthorizationStatusVoided {
@@ -368,41 +510,30 @@ func (l *Lifecycle) Void(id [32]byte, reason string, caller [20]byte) (*Authoriz
 	return updated.Clone(), nil
 }
 
-// autoVoid releases the locked balance and persists the voided authorization
-// with the provided status and reason.
+// autoVoid releases the uncaptured balance and persists the voided
+// authorization with the provided status and reason. Amounts captured before
+// the void stay with the merchant.
 func (l *Lifecycle) autoVoid(auth *Authorization, status AuthorizationStatus, reason string) (*Authorization, error) {
 	if auth == nil {
 		return nil, errAuthorizationNotFound
 	}
 	originalAuth := auth.Clone()
-	payerAcc, err := l.state.GetAccount(auth.Payer[:])
+	remaining := auth.RemainingAmount()
+	restore, err := l.settle(auth, big.NewInt(0), remaining)
 	if err != nil {
 		return nil, err
 	}
-	payerAcc = cloneAccount(payerAcc)
-	originalPayer := cloneAccount(payerAcc)
-	if payerAcc.LockedZNHB.Cmp(auth.Amount) < 0 {
-		return nil, fmt.Errorf("pos: locked balance inconsistent")
-	}
-	payerAcc.LockedZNHB = new(big.Int).Sub(payerAcc.LockedZNHB, auth.Amount)
-	payerAcc.BalanceZNHB = new(big.Int).Add(payerAcc.BalanceZNHB, auth.Amount)
-	if err := l.state.PutAccount(auth.Payer[:], payerAcc); err != nil {
-		return nil, err
-	}
 	now := l.nowFn().UTC()
 	auth.Status = status
-	auth.CapturedAmount = big.NewInt(0)
-	auth.RefundedAmount = new(big.Int).Set(auth.Amount)
+	auth.RefundedAmount = new(big.Int).Set(remaining)
 	auth.UpdatedAt = uint64(now.Unix())
 	auth.VoidReason = strings.TrimSpace(reason)
 	if err := l.persistAuthorization(auth); err != nil {
-		payerAcc.LockedZNHB = new(big.Int).Add(payerAcc.LockedZNHB, auth.Amount)
-		payerAcc.BalanceZNHB = new(big.Int).Sub(payerAcc.BalanceZNHB, auth.Amount)
-		_ = l.state.PutAccount(auth.Payer[:], payerAcc)
+		restore()
 		return nil, err
 	}
 	if err := l.removePendingAuthorization(auth.ID); err != nil {
-		_ = l.state.PutAccount(auth.Payer[:], originalPayer)
+		restore()
 		if originalAuth != nil {
 			_ = l.persistAuthorization(originalAuth)
 			_ = l.addPendingAuthorization(originalAuth.ID)
@@ -413,13 +544,179 @@ func (l *Lifecycle) autoVoid(auth *Authorization, status AuthorizationStatus, re
 		AuthorizationID: auth.ID,
 		Payer:           auth.Payer,
 		Merchant:        auth.Merchant,
-		RefundedAmount:  new(big.Int).Set(auth.Amount),
+		RefundedAmount:  new(big.Int).Set(remaining),
 		Reason:          auth.VoidReason,
 		Expired:         status == AuthorizationStatusExpired,
 	})
 	return auth, nil
 }
 
+// lockFunds takes amount of token out of the payer's spendable balance.
+func (l *Lifecycle) lockFunds(payer [20]byte, token string, amount *big.Int) error {
+	if token != defaultToken {
+		hold := HoldAddress(token)
+		if err := l.state.AssetTransfer(payer[:], hold[:], token, amount); err != nil {
+			return fmt.Errorf("%w: %v", errAuthorizationInsufficient, err)
+		}
+		return nil
+	}
+	payerAcc, err := l.state.GetAccount(payer[:])
+	if err != nil {
+		return err
+	}
+	payerAcc = cloneAccount(payerAcc)
+	if payerAcc.BalanceZNHB.Cmp(amount) < 0 {
+		return errAuthorizationInsufficient
+	}
+	payerAcc.BalanceZNHB = new(big.Int).Sub(payerAcc.BalanceZNHB, amount)
+	payerAcc.LockedZNHB = new(big.Int).Add(payerAcc.LockedZNHB, amount)
+	return l.state.PutAccount(payer[:], payerAcc)
+}
+
+// unlockFunds reverses lockFunds on a best-effort basis.
+func (l *Lifecycle) unlockFunds(payer [20]byte, token string, amount *big.Int) {
+	if token != defaultToken {
+		hold := HoldAddress(token)
+		_ = l.state.AssetTransfer(hold[:], payer[:], token, amount)
+		return
+	}
+	payerAcc, err := l.state.GetAccount(payer[:])
+	if err != nil {
+		return
+	}
+	payerAcc = cloneAccount(payerAcc)
+	payerAcc.BalanceZNHB = new(big.Int).Add(payerAcc.BalanceZNHB, amount)
+	payerAcc.LockedZNHB = new(big.Int).Sub(payerAcc.LockedZNHB, amount)
+	_ = l.state.PutAccount(payer[:], payerAcc)
+}
+
+// settle releases toMerchant plus toPayer of the funds locked by auth, paying
+// toMerchant to the merchant and toPayer back to the payer. The returned
+// function undoes the settlement on a best-effort basis.
+func (l *Lifecycle) settle(auth *Authorization, toMerchant, toPayer *big.Int) (func(), error) {
+	token := normalizeToken(auth.Token)
+	if token != defaultToken {
+		hold := HoldAddress(token)
+		var undo []func()
+		restore := func() {
+			for i := len(undo) - 1; i >= 0; i-- {
+				undo[i]()
+			}
+		}
+		for _, leg := range []struct {
+			to     [20]byte
+			amount *big.Int
+		}{{auth.Merchant, toMerchant}, {auth.Payer, toPayer}} {
+			if leg.amount.Sign() == 0 {
+				continue
+			}
+			if err := l.state.AssetTransfer(hold[:], leg.to[:], token, leg.amount); err != nil {
+				restore()
+				return nil, err
+			}
+			to, amount := leg.to, leg.amount
+			undo = append(undo, func() { _ = l.state.AssetTransfer(to[:], hold[:], token, amount) })
+		}
+		return restore, nil
+	}
+	payerAcc, err := l.state.GetAccount(auth.Payer[:])
+	if err != nil {
+		return nil, err
+	}
+	payerAcc = cloneAccount(payerAcc)
+	originalPayer := cloneAccount(payerAcc)
+	released := new(big.Int).Add(toMerchant, toPayer)
+	if payerAcc.LockedZNHB.Cmp(released) < 0 {
+		return nil, fmt.Errorf("pos: locked balance inconsistent")
+	}
+	payerAcc.LockedZNHB = new(big.Int).Sub(payerAcc.LockedZNHB, released)
+	if toPayer.Sign() > 0 {
+		payerAcc.BalanceZNHB = new(big.Int).Add(payerAcc.BalanceZNHB, toPayer)
+	}
+	if err := l.state.PutAccount(auth.Payer[:], payerAcc); err != nil {
+		return nil, err
+	}
+	restore := func() { _ = l.state.PutAccount(auth.Payer[:], originalPayer) }
+	if toMerchant.Sign() == 0 {
+		return restore, nil
+	}
+	merchantAcc, err := l.state.GetAccount(auth.Merchant[:])
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	merchantAcc = cloneAccount(merchantAcc)
+	originalMerchant := cloneAccount(merchantAcc)
+	merchantAcc.BalanceZNHB = new(big.Int).Add(merchantAcc.BalanceZNHB, toMerchant)
+	if err := l.state.PutAccount(auth.Merchant[:], merchantAcc); err != nil {
+		restore()
+		return nil, err
+	}
+	return func() {
+		restore()
+		_ = l.state.PutAccount(auth.Merchant[:], originalMerchant)
+	}, nil
+}
+
+// transfer moves amount of token from one account to another, as used by
+// merchant refunds. The returned function undoes the transfer on a
+// best-effort basis.
+func (l *Lifecycle) transfer(from, to [20]byte, token string, amount *big.Int) (func(), error) {
+	token = normalizeToken(token)
+	if token != defaultToken {
+		if err := l.state.AssetTransfer(from[:], to[:], token, amount); err != nil {
+			return nil, fmt.Errorf("%w: %v", errAuthorizationInsufficient, err)
+		}
+		return func() { _ = l.state.AssetTransfer(to[:], from[:], token, amount) }, nil
+	}
+	fromAcc, err := l.state.GetAccount(from[:])
+	if err != nil {
+		return nil, err
+	}
+	fromAcc = cloneAccount(fromAcc)
+	originalFrom := cloneAccount(fromAcc)
+	if fromAcc.BalanceZNHB.Cmp(amount) < 0 {
+		return nil, errAuthorizationInsufficient
+	}
+	toAcc, err := l.state.GetAccount(to[:])
+	if err != nil {
+		return nil, err
+	}
+	toAcc = cloneAccount(toAcc)
+	originalTo := cloneAccount(toAcc)
+	fromAcc.BalanceZNHB = new(big.Int).Sub(fromAcc.BalanceZNHB, amount)
+	toAcc.BalanceZNHB = new(big.Int).Add(toAcc.BalanceZNHB, amount)
+	if err := l.state.PutAccount(from[:], fromAcc); err != nil {
+		return nil, err
+	}
+	restore := func() { _ = l.state.PutAccount(from[:], originalFrom) }
+	if err := l.state.PutAccount(to[:], toAcc); err != nil {
+		restore()
+		return nil, err
+	}
+	return func() {
+		restore()
+		_ = l.state.PutAccount(to[:], originalTo)
+	}, nil
+}
+
+// HoldAddress returns the module account holding NHB and issued assets locked
+// by pending authorizations.
+func HoldAddress(token string) [20]byte {
+	hash := ethcrypto.Keccak256([]byte(holdAddressSeedPrefix + normalizeToken(token)))
+	var addr [20]byte
+	copy(addr[:], hash[len(hash)-20:])
+	return addr
+}
+
+func normalizeToken(token string) string {
+	normalized := strings.ToUpper(strings.TrimSpace(token))
+	if normalized == "" {
+		return defaultToken
+	}
+	return normalized
+}
+
 func (l *Lifecycle) loadAuthorization(id [32]byte) (*Authorization, error) {
 	if l == nil || l.state == nil {
 		return nil, errLifecycleUninitialised
@@ -606,8 +903,8 @@ func (l *Lifecycle) SweepExpired(now time.Time) ([]*Authorization, error) {
 		}
 		expired = append(expired, updated.Clone())
 		amount := big.NewInt(0)
-		if updated.Amount != nil {
-			amount = new(big.Int).Set(updated.Amount)
+		if updated.RefundedAmount != nil {
+			amount = new(big.Int).Set(updated.RefundedAmount)
 		}
 		l.emitter.Emit(events.PosAuthAutoVoided{
 			AuthorizationID: updated.ID,
@@ -706,6 +1003,8 @@ type storedAuthorization struct {
 	CreatedAt      uint64
 	UpdatedAt      uint64
 	VoidReason     string
+	Token          string   `rlp:"optional"`
+	MerchantRefund *big.Int `rlp:"optional"`
 }
 
 type storedAuthorizationNonce struct {
@@ -727,6 +1026,9 @@ func newStoredAuthorization(a *Authorization) *storedAuthorization {
 		UpdatedAt:  a.UpdatedAt,
 		VoidReason: strings.TrimSpace(a.VoidReason),
 	}
+	if token := normalizeToken(a.Token); token != defaultToken {
+		stored.Token = token
+	}
 	if a.Amount != nil {
 		stored.Amount = new(big.Int).Set(a.Amount)
 	}
@@ -736,6 +1038,9 @@ func newStoredAuthorization(a *Authorization) *storedAuthorization {
 	if a.RefundedAmount != nil {
 		stored.RefundedAmount = new(big.Int).Set(a.RefundedAmount)
 	}
+	if a.MerchantRefundedAmount != nil && a.MerchantRefundedAmount.Sign() > 0 {
+		stored.MerchantRefund = new(big.Int).Set(a.MerchantRefundedAmount)
+	}
 	return stored
 }
 
@@ -747,6 +1052,7 @@ func (s *storedAuthorization) toAuthorization() *Authorization {
 		ID:         s.ID,
 		Payer:      s.Payer,
 		Merchant:   s.Merchant,
+		Token:      normalizeToken(s.Token),
 		Amount:     big.NewInt(0),
 		Expiry:     s.Expiry,
 		IntentRef:  append([]byte(nil), s.IntentRef...),
@@ -768,5 +1074,10 @@ func (s *storedAuthorization) toAuthorization() *Authorization {
 	} else {
 		record.RefundedAmount = big.NewInt(0)
 	}
+	if s.MerchantRefund != nil {
+		record.MerchantRefundedAmount = new(big.Int).Set(s.MerchantRefund)
+	} else {
+		record.MerchantRefundedAmount = big.NewInt(0)
+	}
 	return record
 }
@@ -14,12 +14,14 @@ import (
 type memoryLifecycleState struct {
 	kv       map[string][]byte
 	accounts map[string]*types.Account
+	assets   map[string]*big.Int
 }
 
 func newMemoryLifecycleState() *memoryLifecycleState {
 	return &memoryLifecycleState{
 		kv:       make(map[string][]byte),
 		accounts: make(map[string]*types.Account),
+		assets:   make(map[string]*big.Int),
 	}
 }
 
@@ -63,6 +65,23 @@ func (m *memoryLifecycleState) PutAccount(addr []byte, account *types.Account) e
 	return nil
 }
 
+func (m *memoryLifecycleState) assetBalance(addr []byte, symbol string) *big.Int {
+	if balance, ok := m.assets[symbol+string(addr)]; ok {
+		return new(big.Int).Set(balance)
+	}
+	return big.NewInt(0)
+}
+
+func (m *memoryLifecycleState) AssetTransfer(from, to []byte, symbol string, amount *big.Int) error {
+	balance := m.assetBalance(from, symbol)
+	if balance.Cmp(amount) < 0 {
+		return errors.New("insufficient balance")
+	}
+	m.assets[symbol+string(from)] = balance.Sub(balance, amount)
+	m.assets[symbol+string(to)] = new(big.Int).Add(m.assetBalance(to, symbol), amount)
+	return nil
+}
+
 func TestLifecyclePartialCapture(t *testing.T) {
 	state := newMemoryLifecycleState()
 	var payer, merchant [20]byte
@@ -175,3 +194,181 @@ func TestLifecycleAutoVoidOnExpiry(t *testing.T) {
 		t.Fatalf("payer balance after auto-void: got %s want 800", payerAcc.BalanceZNHB)
 	}
 }
+
+func TestLifecycleIssuedAssetHold(t *testing.T) {
+	state := newMemoryLifecycleState()
+	var payer, merchant [20]byte
+	payer[1] = 0x0A
+	merchant[2] = 0x0B
+	state.assets["USDX"+string(payer[:])] = big.NewInt(1_000)
+	hold := HoldAddress("usdx")
+
+	engine := NewLifecycle(state)
+	base := time.Unix(1_700_000_000, 0)
+	engine.SetNowFunc(func() time.Time { return base })
+
+	if _, err := engine.AuthorizeAsset(payer, merchant, "usdx", big.NewInt(2_000), uint64(base.Add(time.Hour).Unix()), nil); !errors.Is(err, errAuthorizationInsufficient) {
+		t.Fatalf("expected insufficient balance, got %v", err)
+	}
+	auth, err := engine.AuthorizeAsset(payer, merchant, "usdx", big.NewInt(600), uint64(base.Add(time.Hour).Unix()), nil)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	if auth.Token != "USDX" {
+		t.Fatalf("unexpected token %q", auth.Token)
+	}
+	if got := state.assetBalance(hold[:], "USDX"); got.Cmp(big.NewInt(600)) != 0 {
+		t.Fatalf("hold balance after auth: got %s want 600", got)
+	}
+
+	if _, err := engine.Capture(auth.ID, big.NewInt(250), merchant); err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if got := state.assetBalance(merchant[:], "USDX"); got.Cmp(big.NewInt(250)) != 0 {
+		t.Fatalf("merchant balance: got %s want 250", got)
+	}
+	if got := state.assetBalance(payer[:], "USDX"); got.Cmp(big.NewInt(750)) != 0 {
+		t.Fatalf("payer balance: got %s want 750", got)
+	}
+	if got := state.assetBalance(hold[:], "USDX"); got.Sign() != 0 {
+		t.Fatalf("hold balance after capture: got %s want 0", got)
+	}
+	stored, err := engine.Get(auth.ID)
+	if err != nil || stored.Token != "USDX" {
+		t.Fatalf("stored token: %v %+v", err, stored)
+	}
+}
+
+func TestLifecycleIncrementMultiCaptureAndRefund(t *testing.T) {
+	state := newMemoryLifecycleState()
+	var payer, merchant [20]byte
+	payer[7] = 0x21
+	merchant[8] = 0x42
+	state.accounts[string(payer[:])] = &types.Account{BalanceZNHB: big.NewInt(1_000), BalanceNHB: big.NewInt(0), LockedZNHB: big.NewInt(0)}
+	state.accounts[string(merchant[:])] = &types.Account{BalanceZNHB: big.NewInt(0), BalanceNHB: big.NewInt(0), LockedZNHB: big.NewInt(0)}
+
+	engine := NewLifecycle(state)
+	base := time.Unix(1_700_000_000, 0)
+	engine.SetNowFunc(func() time.Time { return base })
+	expiry := uint64(base.Add(time.Hour).Unix())
+
+	auth, err := engine.Authorize(payer, merchant, big.NewInt(500), expiry, nil)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	if _, err := engine.Increment(auth.ID, big.NewInt(100), 0, merchant); !errors.Is(err, errAuthorizationUnauthorized) {
+		t.Fatalf("merchant increment error: got %v want %v", err, errAuthorizationUnauthorized)
+	}
+	if _, err := engine.Increment(auth.ID, big.NewInt(100), expiry-1, payer); !errors.Is(err, errAuthorizationBadExpiry) {
+		t.Fatalf("shortened expiry error: got %v want %v", err, errAuthorizationBadExpiry)
+	}
+	updated, err := engine.Increment(auth.ID, big.NewInt(100), expiry+600, payer)
+	if err != nil {
+		t.Fatalf("increment: %v", err)
+	}
+	if updated.Amount.Cmp(big.NewInt(600)) != 0 || updated.Expiry != expiry+600 {
+		t.Fatalf("after increment: amount %s expiry %d", updated.Amount, updated.Expiry)
+	}
+	payerAcc, _ := state.GetAccount(payer[:])
+	if payerAcc.LockedZNHB.Cmp(big.NewInt(600)) != 0 || payerAcc.BalanceZNHB.Cmp(big.NewInt(400)) != 0 {
+		t.Fatalf("payer after increment: balance %s locked %s", payerAcc.BalanceZNHB, payerAcc.LockedZNHB)
+	}
+
+	updated, err = engine.CapturePartial(auth.ID, big.NewInt(200), merchant)
+	if err != nil {
+		t.Fatalf("first capture: %v", err)
+	}
+	if updated.Status != AuthorizationStatusPending || updated.CapturedAmount.Cmp(big.NewInt(200)) != 0 {
+		t.Fatalf("after first capture: status %v captured %s", updated.Status, updated.CapturedAmount)
+	}
+	if _, err := engine.CapturePartial(auth.ID, big.NewInt(401), merchant); !errors.Is(err, errAuthorizationOverCapture) {
+		t.Fatalf("over capture error: got %v want %v", err, errAuthorizationOverCapture)
+	}
+	updated, err = engine.Capture(auth.ID, big.NewInt(300), merchant)
+	if err != nil {
+		t.Fatalf("final capture: %v", err)
+	}
+	if updated.Status != AuthorizationStatusCaptured {
+		t.Fatalf("status after final capture: got %v want captured", updated.Status)
+	}
+	if updated.CapturedAmount.Cmp(big.NewInt(500)) != 0 || updated.RefundedAmount.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("after final capture: captured %s released %s", updated.CapturedAmount, updated.RefundedAmount)
+	}
+	payerAcc, _ = state.GetAccount(payer[:])
+	if payerAcc.LockedZNHB.Sign() != 0 || payerAcc.BalanceZNHB.Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("payer after capture: balance %s locked %s", payerAcc.BalanceZNHB, payerAcc.LockedZNHB)
+	}
+
+	if _, err := engine.Refund(auth.ID, big.NewInt(50), "damaged", payer); !errors.Is(err, errAuthorizationUnauthorized) {
+		t.Fatalf("payer refund error: got %v want %v", err, errAuthorizationUnauthorized)
+	}
+	updated, err = engine.Refund(auth.ID, big.NewInt(150), "returned item", merchant)
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if updated.Status != AuthorizationStatusCaptured || updated.MerchantRefundedAmount.Cmp(big.NewInt(150)) != 0 {
+		t.Fatalf("after refund: status %v refunded %s", updated.Status, updated.MerchantRefundedAmount)
+	}
+	if _, err := engine.Refund(auth.ID, big.NewInt(351), "", merchant); !errors.Is(err, errAuthorizationOverRefund) {
+		t.Fatalf("over refund error: got %v want %v", err, errAuthorizationOverRefund)
+	}
+	updated, err = engine.Refund(auth.ID, big.NewInt(350), "", merchant)
+	if err != nil {
+		t.Fatalf("second refund: %v", err)
+	}
+	if updated.Status != AuthorizationStatusRefunded {
+		t.Fatalf("status after full refund: got %v want refunded", updated.Status)
+	}
+	payerAcc, _ = state.GetAccount(payer[:])
+	if payerAcc.BalanceZNHB.Cmp(big.NewInt(1_000)) != 0 {
+		t.Fatalf("payer balance after refunds: got %s want 1000", payerAcc.BalanceZNHB)
+	}
+	merchantAcc, _ := state.GetAccount(merchant[:])
+	if merchantAcc.BalanceZNHB.Sign() != 0 {
+		t.Fatalf("merchant balance after refunds: got %s want 0", merchantAcc.BalanceZNHB)
+	}
+	stored, err := engine.Get(auth.ID)
+	if err != nil || stored.MerchantRefundedAmount.Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("stored refund total: %v %+v", err, stored)
+	}
+}
+
+func TestLifecycleVoidAfterPartialCaptureKeepsCapturedFunds(t *testing.T) {
+	state := newMemoryLifecycleState()
+	var payer, merchant [20]byte
+	payer[1] = 0x0C
+	merchant[2] = 0x0D
+	state.assets["USDX"+string(payer[:])] = big.NewInt(1_000)
+	hold := HoldAddress("USDX")
+
+	engine := NewLifecycle(state)
+	base := time.Unix(1_700_000_000, 0)
+	engine.SetNowFunc(func() time.Time { return base })
+
+	auth, err := engine.AuthorizeAsset(payer, merchant, "USDX", big.NewInt(600), uint64(base.Add(time.Hour).Unix()), nil)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	if _, err := engine.CapturePartial(auth.ID, big.NewInt(250), merchant); err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	updated, err := engine.Void(auth.ID, "", payer)
+	if err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	if updated.Status != AuthorizationStatusVoided || updated.CapturedAmount.Cmp(big.NewInt(250)) != 0 || updated.RefundedAmount.Cmp(big.NewInt(350)) != 0 {
+		t.Fatalf("after void: status %v captured %s released %s", updated.Status, updated.CapturedAmount, updated.RefundedAmount)
+	}
+	if got := state.assetBalance(merchant[:], "USDX"); got.Cmp(big.NewInt(250)) != 0 {
+		t.Fatalf("merchant balance: got %s want 250", got)
+	}
+	if got := state.assetBalance(hold[:], "USDX"); got.Sign() != 0 {
+		t.Fatalf("hold balance after void: got %s want 0", got)
+	}
+	if _, err := engine.Refund(auth.ID, big.NewInt(250), "", merchant); err != nil {
+		t.Fatalf("refund after void: %v", err)
+	}
+	if got := state.assetBalance(payer[:], "USDX"); got.Cmp(big.NewInt(1_000)) != 0 {
+		t.Fatalf("payer balance: got %s want 1000", got)
+	}
+}
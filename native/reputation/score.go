@@ -0,0 +1,559 @@
+package reputation
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"nhbchain/core/types"
+	"nhbchain/native/escrow"
+)
+
+const (
+	// MaxScore is the upper bound of the aggregate reputation score.
+	MaxScore uint32 = 1000
+
+	// MaxAttestationPoints caps the contribution of verifier attestations.
+	MaxAttestationPoints uint32 = 300
+	// MaxHistoryPoints caps the contribution of completed escrows and trades.
+	MaxHistoryPoints uint32 = 400
+	// MaxArbitrationPoints caps the contribution of arbitration outcomes.
+	MaxArbitrationPoints uint32 = 200
+	// MaxTenurePoints caps the contribution of reputation tenure, the time
+	// since the address first took part in a reputation-relevant event.
+	MaxTenurePoints uint32 = 100
+
+	// BaseAttestationWeight is the weight of an attestation from a verifier
+	// with no reputation of their own. Verifier standing adds up to
+	// StandingAttestationWeight on top.
+	BaseAttestationWeight uint32 = 25
+	// StandingAttestationWeight is the extra weight carried by an attestation
+	// from a verifier with the maximum score.
+	StandingAttestationWeight uint32 = 75
+
+	// HistoryMaturity is the number of completed deals after which the history
+	// component is no longer discounted for a thin track record.
+	HistoryMaturity uint64 = 50
+	// TenureMaturitySeconds is the tenure at which the tenure component is
+	// fully earned.
+	TenureMaturitySeconds int64 = 365 * 24 * 60 * 60
+
+	// MaxTrackedAttestations bounds the attestations kept per aggregate. The
+	// oldest entry is dropped once the limit is reached.
+	MaxTrackedAttestations = 64
+)
+
+var scorePrefix = []byte("reputation/score/")
+
+func scoreKey(addr [20]byte) []byte {
+	return []byte(fmt.Sprintf("%s%x", scorePrefix, addr))
+}
+
+// AttestationWeight records the weight an attestation contributed when it was
+// issued so a later revocation removes exactly the same amount.
+type AttestationWeight struct {
+	ID        [32]byte
+	Verifier  [20]byte
+	Weight    uint32
+	ExpiresAt int64
+}
+
+// Aggregate is the running reputation record for an address. Counters only
+// ever grow; the score is derived from them on read.
+type Aggregate struct {
+	Address           [20]byte
+	FirstSeen         int64
+	UpdatedAt         int64
+	Attestations      []AttestationWeight
+	EscrowsCompleted  uint64
+	EscrowsDisputed   uint64
+	TradesCompleted   uint64
+	TradesDisputed    uint64
+	ArbitrationWins   uint64
+	ArbitrationLosses uint64
+}
+
+// ScoreBreakdown reports the components that make up an aggregate score.
+type ScoreBreakdown struct {
+	Attestations uint32
+	History      uint32
+	Arbitration  uint32
+	Tenure       uint32
+	Total        uint32
+}
+
+// ComputeScore derives the score of an aggregate at the supplied time. A nil
+// aggregate scores zero. The result is deterministic for a given aggregate and
+// timestamp.
+func ComputeScore(agg *Aggregate, now int64) ScoreBreakdown {
+	var out ScoreBreakdown
+	if agg == nil {
+		return out
+	}
+	var weight uint64
+	for _, att := range agg.Attestations {
+		if att.ExpiresAt > 0 && now >= att.ExpiresAt {
+			continue
+		}
+		weight += uint64(att.Weight)
+	}
+	out.Attestations = uint32(minUint64(weight, uint64(MaxAttestationPoints)))
+
+	completed := agg.EscrowsCompleted + agg.TradesCompleted
+	disputed := agg.EscrowsDisputed + agg.TradesDisputed
+	if total := completed + disputed; total > 0 {
+		depth := minUint64(completed, HistoryMaturity)
+		out.History = uint32(uint64(MaxHistoryPoints) * completed * depth / (total * HistoryMaturity))
+	}
+
+	// Like the history component, arbitration is earned from evidence: an
+	// address with no resolved cases scores zero rather than the full share.
+	if cases := agg.ArbitrationWins + agg.ArbitrationLosses; cases > 0 {
+		out.Arbitration = uint32(uint64(MaxArbitrationPoints) * agg.ArbitrationWins / cases)
+	}
+
+	// Tenure counts from the first reputation-relevant event, not from account
+	// creation, so dormant accounts do not accrue standing.
+	if agg.FirstSeen > 0 && now > agg.FirstSeen {
+		tenure := now - agg.FirstSeen
+		if tenure > TenureMaturitySeconds {
+			tenure = TenureMaturitySeconds
+		}
+		out.Tenure = uint32(int64(MaxTenurePoints) * tenure / TenureMaturitySeconds)
+	}
+
+	out.Total = out.Attestations + out.History + out.Arbitration + out.Tenure
+	return out
+}
+
+// AttestationWeightFor returns the weight of an attestation issued by a
+// verifier holding the supplied score.
+func AttestationWeightFor(verifierScore uint32) uint32 {
+	if verifierScore > MaxScore {
+		verifierScore = MaxScore
+	}
+	return BaseAttestationWeight + StandingAttestationWeight*verifierScore/MaxScore
+}
+
+// ScoreBook persists reputation aggregates and folds module events into them.
+type ScoreBook struct {
+	store storage
+}
+
+// NewScoreBook constructs a score book bound to the provided storage backend.
+func NewScoreBook(store storage) *ScoreBook {
+	return &ScoreBook{store: store}
+}
+
+// Get returns the aggregate for addr. Addresses without any recorded activity
+// return ok=false.
+func (b *ScoreBook) Get(addr [20]byte) (*Aggregate, bool, error) {
+	if b == nil || b.store == nil {
+		return nil, false, errors.New("reputation: storage unavailable")
+	}
+	var stored storedAggregate
+	ok, err := b.store.KVGet(scoreKey(addr), &stored)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return stored.toAggregate(), true, nil
+}
+
+// Score returns the aggregate for addr together with its score at now.
+// Unknown addresses return an empty aggregate.
+func (b *ScoreBook) Score(addr [20]byte, now int64) (*Aggregate, ScoreBreakdown, error) {
+	agg, ok, err := b.Get(addr)
+	if err != nil {
+		return nil, ScoreBreakdown{}, err
+	}
+	if !ok {
+		agg = &Aggregate{Address: addr}
+	}
+	return agg, ComputeScore(agg, now), nil
+}
+
+func (b *ScoreBook) put(agg *Aggregate) error {
+	return b.store.KVPut(scoreKey(agg.Address), newStoredAggregate(agg))
+}
+
+// scanner is implemented by storage backends that can enumerate stored
+// values, such as the state manager.
+type scanner interface {
+	KVScan(keyOf func(value []byte) []byte) ([][]byte, error)
+}
+
+// Aggregates returns every stored aggregate ordered by address. It walks the
+// whole store and is meant for genesis export rather than block processing.
+func (b *ScoreBook) Aggregates() ([]*Aggregate, error) {
+	if b == nil || b.store == nil {
+		return nil, errors.New("reputation: storage unavailable")
+	}
+	scan, ok := b.store.(scanner)
+	if !ok {
+		return nil, errors.New("reputation: storage cannot be scanned")
+	}
+	values, err := scan.KVScan(func(value []byte) []byte {
+		var stored storedAggregate
+		if err := rlp.DecodeBytes(value, &stored); err != nil {
+			return nil
+		}
+		return scoreKey(stored.Address)
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Aggregate, 0, len(values))
+	for _, value := range values {
+		var stored storedAggregate
+		if err := rlp.DecodeBytes(value, &stored); err != nil {
+			return nil, err
+		}
+		out = append(out, stored.toAggregate())
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Address[:], out[j].Address[:]) < 0 })
+	return out, nil
+}
+
+// Restore writes an exported aggregate as is. It is meant for genesis import.
+func (b *ScoreBook) Restore(agg *Aggregate) error {
+	if b == nil || b.store == nil {
+		return errors.New("reputation: storage unavailable")
+	}
+	if agg == nil {
+		return errors.New("reputation: nil aggregate")
+	}
+	return b.put(agg)
+}
+
+// Apply folds a batch of events into the aggregates of the parties involved.
+// It understands escrow, trade and reputation events and ignores everything
+// else. Escrow events for trade legs are skipped in favour of the trade
+// events, and releases that are part of an arbitrated resolution are counted
+// as arbitration outcomes rather than completions.
+func (b *ScoreBook) Apply(evts []types.Event, now int64) error {
+	if b == nil || b.store == nil {
+		return errors.New("reputation: storage unavailable")
+	}
+	if len(evts) == 0 {
+		return nil
+	}
+	legs := make(map[string]struct{})
+	resolved := make(map[string]struct{})
+	for i := range evts {
+		attrs := evts[i].Attributes
+		switch evts[i].Type {
+		case escrow.EventTypeTradeCreated, escrow.EventTypeTradeSettled, escrow.EventTypeTradeDisputed, escrow.EventTypeTradeResolved:
+			legs[attrs["escrowBaseId"]] = struct{}{}
+			legs[attrs["escrowQuoteId"]] = struct{}{}
+		case escrow.EventTypeEscrowResolved:
+			resolved[attrs["id"]] = struct{}{}
+		}
+	}
+
+	batch := &scoreBatch{book: b, now: now, cache: make(map[[20]byte]*Aggregate), touched: make(map[[20]byte]bool)}
+	for i := range evts {
+		evt := &evts[i]
+		attrs := evt.Attributes
+		switch evt.Type {
+		case escrow.EventTypeEscrowReleased, escrow.EventTypeEscrowDisputed, escrow.EventTypeEscrowResolved:
+			if _, ok := legs[attrs["id"]]; ok {
+				continue
+			}
+			payer, okPayer := decodeEventAddress(attrs["payer"])
+			payee, okPayee := decodeEventAddress(attrs["payee"])
+			if !okPayer || !okPayee {
+				continue
+			}
+			switch evt.Type {
+			case escrow.EventTypeEscrowReleased:
+				if _, ok := resolved[attrs["id"]]; ok {
+					continue
+				}
+				if err := batch.update(func(a *Aggregate) { a.EscrowsCompleted++ }, payer, payee); err != nil {
+					return err
+				}
+			case escrow.EventTypeEscrowDisputed:
+				if err := batch.update(func(a *Aggregate) { a.EscrowsDisputed++ }, payer, payee); err != nil {
+					return err
+				}
+			case escrow.EventTypeEscrowResolved:
+				if err := batch.arbitration(attrs["decision"], "release", "refund", payee, payer); err != nil {
+					return err
+				}
+			}
+		case escrow.EventTypeTradeSettled, escrow.EventTypeTradeDisputed, escrow.EventTypeTradeResolved:
+			buyer, okBuyer := decodeEventAddress(attrs["buyer"])
+			seller, okSeller := decodeEventAddress(attrs["seller"])
+			if !okBuyer || !okSeller {
+				continue
+			}
+			switch evt.Type {
+			case escrow.EventTypeTradeSettled:
+				if err := batch.update(func(a *Aggregate) { a.TradesCompleted++ }, buyer, seller); err != nil {
+					return err
+				}
+			case escrow.EventTypeTradeDisputed:
+				if err := batch.update(func(a *Aggregate) { a.TradesDisputed++ }, buyer, seller); err != nil {
+					return err
+				}
+			case escrow.EventTypeTradeResolved:
+				// The base leg pays the buyer and the quote leg pays the
+				// seller, so releasing one leg while refunding the other
+				// hands the whole trade to one side.
+				if err := batch.arbitration(attrs["outcome"], "release_base_refund_quote", "release_quote_refund_base", buyer, seller); err != nil {
+					return err
+				}
+			}
+		case EventTypeSkillVerified:
+			if err := batch.attest(attrs); err != nil {
+				return err
+			}
+		case EventTypeSkillRevoked:
+			if err := batch.revoke(attrs); err != nil {
+				return err
+			}
+		}
+	}
+	return batch.flush()
+}
+
+// scoreBatch caches aggregates read while applying a batch and writes the
+// modified ones back in first-update order so the resulting state is
+// deterministic.
+type scoreBatch struct {
+	book    *ScoreBook
+	now     int64
+	cache   map[[20]byte]*Aggregate
+	touched map[[20]byte]bool
+	order   [][20]byte
+}
+
+func (s *scoreBatch) load(addr [20]byte) (*Aggregate, error) {
+	if agg, ok := s.cache[addr]; ok {
+		return agg, nil
+	}
+	agg, ok, err := s.book.Get(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		agg = &Aggregate{Address: addr, FirstSeen: s.now}
+	}
+	s.cache[addr] = agg
+	return agg, nil
+}
+
+func (s *scoreBatch) update(fn func(*Aggregate), addrs ...[20]byte) error {
+	for _, addr := range addrs {
+		if addr == ([20]byte{}) {
+			continue
+		}
+		agg, err := s.load(addr)
+		if err != nil {
+			return err
+		}
+		fn(agg)
+		agg.UpdatedAt = s.now
+		if !s.touched[addr] {
+			s.touched[addr] = true
+			s.order = append(s.order, addr)
+		}
+	}
+	return nil
+}
+
+// arbitration records a win for first and a loss for second when the outcome
+// matches firstWins, and the reverse when it matches secondWins. Any other
+// outcome is neutral.
+func (s *scoreBatch) arbitration(outcome, firstWins, secondWins string, first, second [20]byte) error {
+	switch strings.TrimSpace(outcome) {
+	case firstWins:
+	case secondWins:
+		first, second = second, first
+	default:
+		return nil
+	}
+	if err := s.update(func(a *Aggregate) { a.ArbitrationWins++ }, first); err != nil {
+		return err
+	}
+	return s.update(func(a *Aggregate) { a.ArbitrationLosses++ }, second)
+}
+
+func (s *scoreBatch) attest(attrs map[string]string) error {
+	subject, ok := decodeEventAddress(attrs["subject"])
+	if !ok {
+		return nil
+	}
+	verifier, ok := decodeEventAddress(attrs["verifier"])
+	if !ok {
+		return nil
+	}
+	id, ok := decodeEventID(attrs["attestationId"])
+	if !ok {
+		return nil
+	}
+	var expiresAt int64
+	if raw := strings.TrimSpace(attrs["expiresAt"]); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil
+		}
+		expiresAt = parsed
+	}
+	verifierAgg, err := s.load(verifier)
+	if err != nil {
+		return err
+	}
+	entry := AttestationWeight{
+		ID:        id,
+		Verifier:  verifier,
+		Weight:    AttestationWeightFor(ComputeScore(verifierAgg, s.now).Total),
+		ExpiresAt: expiresAt,
+	}
+	return s.update(func(a *Aggregate) {
+		for i := range a.Attestations {
+			if a.Attestations[i].ID == id {
+				a.Attestations[i] = entry
+				return
+			}
+		}
+		if len(a.Attestations) >= MaxTrackedAttestations {
+			a.Attestations = append(a.Attestations[:0], a.Attestations[1:]...)
+		}
+		a.Attestations = append(a.Attestations, entry)
+	}, subject)
+}
+
+func (s *scoreBatch) revoke(attrs map[string]string) error {
+	subject, ok := decodeEventAddress(attrs["subject"])
+	if !ok {
+		return nil
+	}
+	id, ok := decodeEventID(attrs["attestationId"])
+	if !ok {
+		return nil
+	}
+	return s.update(func(a *Aggregate) {
+		for i := range a.Attestations {
+			if a.Attestations[i].ID == id {
+				a.Attestations = append(a.Attestations[:i], a.Attestations[i+1:]...)
+				return
+			}
+		}
+	}, subject)
+}
+
+func (s *scoreBatch) flush() error {
+	for _, addr := range s.order {
+		if err := s.book.put(s.cache[addr]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeEventAddress(value string) ([20]byte, bool) {
+	var out [20]byte
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(value), "0x"))
+	if err != nil || len(raw) != len(out) {
+		return out, false
+	}
+	copy(out[:], raw)
+	return out, out != ([20]byte{})
+}
+
+func decodeEventID(value string) ([32]byte, bool) {
+	var out [32]byte
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(value), "0x"))
+	if err != nil || len(raw) != len(out) {
+		return out, false
+	}
+	copy(out[:], raw)
+	return out, true
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+type storedAttestationWeight struct {
+	ID        [32]byte
+	Verifier  [20]byte
+	Weight    uint32
+	ExpiresAt uint64
+}
+
+type storedAggregate struct {
+	Address           [20]byte
+	FirstSeen         uint64
+	UpdatedAt         uint64
+	Attestations      []storedAttestationWeight
+	EscrowsCompleted  uint64
+	EscrowsDisputed   uint64
+	TradesCompleted   uint64
+	TradesDisputed    uint64
+	ArbitrationWins   uint64
+	ArbitrationLosses uint64
+}
+
+func newStoredAggregate(agg *Aggregate) *storedAggregate {
+	stored := &storedAggregate{
+		Address:           agg.Address,
+		FirstSeen:         uint64(maxInt64(agg.FirstSeen, 0)),
+		UpdatedAt:         uint64(maxInt64(agg.UpdatedAt, 0)),
+		EscrowsCompleted:  agg.EscrowsCompleted,
+		EscrowsDisputed:   agg.EscrowsDisputed,
+		TradesCompleted:   agg.TradesCompleted,
+		TradesDisputed:    agg.TradesDisputed,
+		ArbitrationWins:   agg.ArbitrationWins,
+		ArbitrationLosses: agg.ArbitrationLosses,
+	}
+	for _, att := range agg.Attestations {
+		stored.Attestations = append(stored.Attestations, storedAttestationWeight{
+			ID:        att.ID,
+			Verifier:  att.Verifier,
+			Weight:    att.Weight,
+			ExpiresAt: uint64(maxInt64(att.ExpiresAt, 0)),
+		})
+	}
+	return stored
+}
+
+func (s *storedAggregate) toAggregate() *Aggregate {
+	agg := &Aggregate{
+		Address:           s.Address,
+		FirstSeen:         int64(s.FirstSeen),
+		UpdatedAt:         int64(s.UpdatedAt),
+		EscrowsCompleted:  s.EscrowsCompleted,
+		EscrowsDisputed:   s.EscrowsDisputed,
+		TradesCompleted:   s.TradesCompleted,
+		TradesDisputed:    s.TradesDisputed,
+		ArbitrationWins:   s.ArbitrationWins,
+		ArbitrationLosses: s.ArbitrationLosses,
+	}
+	for _, att := range s.Attestations {
+		agg.Attestations = append(agg.Attestations, AttestationWeight{
+			ID:        att.ID,
+			Verifier:  att.Verifier,
+			Weight:    att.Weight,
+			ExpiresAt: int64(att.ExpiresAt),
+		})
+	}
+	return agg
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,183 @@
+package reputation
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"nhbchain/core/types"
+	"nhbchain/native/escrow"
+)
+
+func scoreAddr(b byte) [20]byte {
+	var out [20]byte
+	out[19] = b
+	return out
+}
+
+func escrowEvent(eventType string, id byte, payer, payee [20]byte, extra map[string]string) types.Event {
+	var escrowID [32]byte
+	escrowID[31] = id
+	attrs := map[string]string{
+		"id":    hex.EncodeToString(escrowID[:]),
+		"payer": hex.EncodeToString(payer[:]),
+		"payee": hex.EncodeToString(payee[:]),
+	}
+	for k, v := range extra {
+		attrs[k] = v
+	}
+	return types.Event{Type: eventType, Attributes: attrs}
+}
+
+func TestComputeScoreComponents(t *testing.T) {
+	now := int64(1_700_000_000)
+	agg := &Aggregate{
+		FirstSeen:         now - TenureMaturitySeconds/2,
+		Attestations:      []AttestationWeight{{Weight: 100}, {Weight: 60, ExpiresAt: now}},
+		EscrowsCompleted:  20,
+		EscrowsDisputed:   5,
+		TradesCompleted:   5,
+		ArbitrationWins:   1,
+		ArbitrationLosses: 3,
+	}
+	score := ComputeScore(agg, now)
+	// The expired attestation no longer counts.
+	if score.Attestations != 100 {
+		t.Fatalf("unexpected attestation points: %d", score.Attestations)
+	}
+	// 25 of 30 deals completed, discounted to half maturity.
+	if score.History != 400*25*25/(30*50) {
+		t.Fatalf("unexpected history points: %d", score.History)
+	}
+	if score.Arbitration != 50 {
+		t.Fatalf("unexpected arbitration points: %d", score.Arbitration)
+	}
+	if score.Tenure != 50 {
+		t.Fatalf("unexpected tenure points: %d", score.Tenure)
+	}
+	if score.Total != score.Attestations+score.History+score.Arbitration+score.Tenure {
+		t.Fatalf("total does not match components: %+v", score)
+	}
+	if empty := ComputeScore(&Aggregate{}, now); empty.Total != 0 {
+		t.Fatalf("expected a fresh account to score zero, got %+v", empty)
+	}
+}
+
+func TestComputeScoreArbitrationRequiresCases(t *testing.T) {
+	now := int64(1_700_000_000)
+	clean := ComputeScore(&Aggregate{EscrowsCompleted: 10}, now)
+	if clean.Arbitration != 0 {
+		t.Fatalf("expected no arbitration points without cases, got %d", clean.Arbitration)
+	}
+	won := ComputeScore(&Aggregate{EscrowsCompleted: 10, ArbitrationWins: 1}, now)
+	if won.Arbitration != MaxArbitrationPoints {
+		t.Fatalf("expected a won case to earn the full component, got %d", won.Arbitration)
+	}
+	lost := ComputeScore(&Aggregate{EscrowsCompleted: 10, ArbitrationLosses: 1}, now)
+	if lost.Arbitration != 0 || lost.Total > clean.Total {
+		t.Fatalf("expected a lost case not to outscore a clean record: lost=%+v clean=%+v", lost, clean)
+	}
+}
+
+func TestScoreBookAppliesEscrowOutcomes(t *testing.T) {
+	book := NewScoreBook(newMemoryStore())
+	payer, payee := scoreAddr(1), scoreAddr(2)
+	batch := []types.Event{
+		escrowEvent(escrow.EventTypeEscrowReleased, 1, payer, payee, nil),
+		escrowEvent(escrow.EventTypeEscrowDisputed, 2, payer, payee, nil),
+		// An arbitrated release emits a release and a resolution; only the
+		// resolution counts.
+		escrowEvent(escrow.EventTypeEscrowReleased, 2, payer, payee, nil),
+		escrowEvent(escrow.EventTypeEscrowResolved, 2, payer, payee, map[string]string{"decision": "release"}),
+	}
+	if err := book.Apply(batch, 1_000); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	seller, ok, err := book.Get(payee)
+	if err != nil || !ok {
+		t.Fatalf("get payee: ok=%v err=%v", ok, err)
+	}
+	if seller.EscrowsCompleted != 1 || seller.EscrowsDisputed != 1 || seller.ArbitrationWins != 1 || seller.ArbitrationLosses != 0 {
+		t.Fatalf("unexpected payee aggregate: %+v", seller)
+	}
+	if seller.FirstSeen != 1_000 {
+		t.Fatalf("unexpected first seen: %d", seller.FirstSeen)
+	}
+	buyer, _, _ := book.Get(payer)
+	if buyer.ArbitrationLosses != 1 || buyer.ArbitrationWins != 0 {
+		t.Fatalf("unexpected payer aggregate: %+v", buyer)
+	}
+
+	// Later batches accumulate on the stored aggregate.
+	if err := book.Apply([]types.Event{escrowEvent(escrow.EventTypeEscrowReleased, 3, payer, payee, nil)}, 2_000); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	seller, _, _ = book.Get(payee)
+	if seller.EscrowsCompleted != 2 || seller.FirstSeen != 1_000 || seller.UpdatedAt != 2_000 {
+		t.Fatalf("unexpected payee aggregate after second batch: %+v", seller)
+	}
+}
+
+func TestScoreBookSkipsTradeLegs(t *testing.T) {
+	book := NewScoreBook(newMemoryStore())
+	buyer, seller := scoreAddr(3), scoreAddr(4)
+	var base, quote [32]byte
+	base[31], quote[31] = 7, 8
+	trade := types.Event{Type: escrow.EventTypeTradeSettled, Attributes: map[string]string{
+		"buyer":         hex.EncodeToString(buyer[:]),
+		"seller":        hex.EncodeToString(seller[:]),
+		"escrowBaseId":  hex.EncodeToString(base[:]),
+		"escrowQuoteId": hex.EncodeToString(quote[:]),
+	}}
+	batch := []types.Event{
+		escrowEvent(escrow.EventTypeEscrowReleased, 7, seller, buyer, nil),
+		escrowEvent(escrow.EventTypeEscrowReleased, 8, buyer, seller, nil),
+		trade,
+	}
+	if err := book.Apply(batch, 1_000); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	for _, addr := range [][20]byte{buyer, seller} {
+		agg, _, _ := book.Get(addr)
+		if agg.TradesCompleted != 1 || agg.EscrowsCompleted != 0 {
+			t.Fatalf("unexpected aggregate for %x: %+v", addr, agg)
+		}
+	}
+}
+
+func TestScoreBookWeighsAttestationsByVerifierStanding(t *testing.T) {
+	store := newMemoryStore()
+	book := NewScoreBook(store)
+	subject, verifier := scoreAddr(5), scoreAddr(6)
+	verification := &SkillVerification{Subject: subject, Skill: "go", Verifier: verifier, IssuedAt: 100}
+	if err := book.Apply([]types.Event{*NewSkillVerifiedEvent(verification)}, 100); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	agg, _, _ := book.Get(subject)
+	// The verifier has no record, so the attestation carries the base weight.
+	want := AttestationWeightFor(0)
+	if len(agg.Attestations) != 1 || agg.Attestations[0].Weight != want {
+		t.Fatalf("unexpected attestations: %+v", agg.Attestations)
+	}
+	if _, ok, _ := book.Get(verifier); ok {
+		t.Fatalf("expected verifier aggregate to stay unwritten")
+	}
+
+	// Re-issuing replaces the entry rather than stacking weight.
+	if err := book.Apply([]types.Event{*NewSkillVerifiedEvent(verification)}, 200); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	agg, _, _ = book.Get(subject)
+	if len(agg.Attestations) != 1 {
+		t.Fatalf("expected a single attestation, got %d", len(agg.Attestations))
+	}
+
+	id, _ := AttestationID(verification)
+	revocation := &Revocation{AttestationID: id, Subject: subject, Verifier: verifier, Skill: "go", RevokedAt: 300}
+	if err := book.Apply([]types.Event{*NewSkillRevokedEvent(revocation)}, 300); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	agg, _, _ = book.Get(subject)
+	if len(agg.Attestations) != 0 {
+		t.Fatalf("expected revocation to drop the attestation, got %+v", agg.Attestations)
+	}
+}
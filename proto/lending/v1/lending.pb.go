@@ -65,6 +65,86 @@ func (x *MarketKey) GetSymbol() string {
 	return ""
 }
 
+// CollateralAsset describes one asset a market accepts as collateral. Basis
+// point values and the supply cap are decimal strings.
+type CollateralAsset struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Asset                string                 `protobuf:"bytes,1,opt,name=asset,proto3" json:"asset,omitempty"`
+	MaxLtv               string                 `protobuf:"bytes,2,opt,name=max_ltv,json=maxLtv,proto3" json:"max_ltv,omitempty"`
+	LiquidationThreshold string                 `protobuf:"bytes,3,opt,name=liquidation_threshold,json=liquidationThreshold,proto3" json:"liquidation_threshold,omitempty"`
+	OracleFeed           string                 `protobuf:"bytes,4,opt,name=oracle_feed,json=oracleFeed,proto3" json:"oracle_feed,omitempty"`
+	SupplyCap            string                 `protobuf:"bytes,5,opt,name=supply_cap,json=supplyCap,proto3" json:"supply_cap,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *CollateralAsset) Reset() {
+	*x = CollateralAsset{}
+	mi := &file_lending_v1_lending_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollateralAsset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollateralAsset) ProtoMessage() {}
+
+func (x *CollateralAsset) ProtoReflect() protoreflect.Message {
+	mi := &file_lending_v1_lending_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollateralAsset.ProtoReflect.Descriptor instead.
+func (*CollateralAsset) Descriptor() ([]byte, []int) {
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CollateralAsset) GetAsset() string {
+	if x != nil {
+		return x.Asset
+	}
+	return ""
+}
+
+func (x *CollateralAsset) GetMaxLtv() string {
+	if x != nil {
+		return x.MaxLtv
+	}
+	return ""
+}
+
+func (x *CollateralAsset) GetLiquidationThreshold() string {
+	if x != nil {
+		return x.LiquidationThreshold
+	}
+	return ""
+}
+
+func (x *CollateralAsset) GetOracleFeed() string {
+	if x != nil {
+		return x.OracleFeed
+	}
+	return ""
+}
+
+func (x *CollateralAsset) GetSupplyCap() string {
+	if x != nil {
+		return x.SupplyCap
+	}
+	return ""
+}
+
+// collateral_assets is empty for markets that predate multi-asset support;
+// those accept ZNHB under the node-wide risk parameters.
 type Market struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Key              *MarketKey             `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
@@ -73,13 +153,15 @@ type Market struct {
 	ReserveFactor    string                 `protobuf:"bytes,4,opt,name=reserve_factor,json=reserveFactor,proto3" json:"reserve_factor,omitempty"`
 	LiquidityIndex   string                 `protobuf:"bytes,5,opt,name=liquidity_index,json=liquidityIndex,proto3" json:"liquidity_index,omitempty"`
 	BorrowIndex      string                 `protobuf:"bytes,6,opt,name=borrow_index,json=borrowIndex,proto3" json:"borrow_index,omitempty"`
+	BorrowAsset      string                 `protobuf:"bytes,7,opt,name=borrow_asset,json=borrowAsset,proto3" json:"borrow_asset,omitempty"`
+	CollateralAssets []*CollateralAsset     `protobuf:"bytes,8,rep,name=collateral_assets,json=collateralAssets,proto3" json:"collateral_assets,omitempty"`
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Market) Reset() {
 	*x = Market{}
-	mi := &file_lending_v1_lending_proto_msgTypes[1]
+	mi := &file_lending_v1_lending_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -91,7 +173,7 @@ func (x *Market) String() string {
 func (*Market) ProtoMessage() {}
 
 func (x *Market) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[1]
+	mi := &file_lending_v1_lending_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -104,7 +186,7 @@ func (x *Market) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Market.ProtoReflect.Descriptor instead.
 func (*Market) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{1}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Market) GetKey() *MarketKey {
@@ -149,20 +231,89 @@ func (x *Market) GetBorrowIndex() string {
 	return ""
 }
 
-type AccountPosition struct {
+func (x *Market) GetBorrowAsset() string {
+	if x != nil {
+		return x.BorrowAsset
+	}
+	return ""
+}
+
+func (x *Market) GetCollateralAssets() []*CollateralAsset {
+	if x != nil {
+		return x.CollateralAssets
+	}
+	return nil
+}
+
+type CollateralBalance struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Account       string                 `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
-	Supplied      string                 `protobuf:"bytes,2,opt,name=supplied,proto3" json:"supplied,omitempty"`
-	Borrowed      string                 `protobuf:"bytes,3,opt,name=borrowed,proto3" json:"borrowed,omitempty"`
-	Collateral    string                 `protobuf:"bytes,4,opt,name=collateral,proto3" json:"collateral,omitempty"`
-	HealthFactor  string                 `protobuf:"bytes,5,opt,name=health_factor,json=healthFactor,proto3" json:"health_factor,omitempty"`
+	Asset         string                 `protobuf:"bytes,1,opt,name=asset,proto3" json:"asset,omitempty"`
+	Amount        string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *CollateralBalance) Reset() {
+	*x = CollateralBalance{}
+	mi := &file_lending_v1_lending_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollateralBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollateralBalance) ProtoMessage() {}
+
+func (x *CollateralBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_lending_v1_lending_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollateralBalance.ProtoReflect.Descriptor instead.
+func (*CollateralBalance) Descriptor() ([]byte, []int) {
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CollateralBalance) GetAsset() string {
+	if x != nil {
+		return x.Asset
+	}
+	return ""
+}
+
+func (x *CollateralBalance) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+// collateral is the sum of collateral_balances, kept for clients that
+// predate multi-asset markets.
+type AccountPosition struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Account            string                 `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Supplied           string                 `protobuf:"bytes,2,opt,name=supplied,proto3" json:"supplied,omitempty"`
+	Borrowed           string                 `protobuf:"bytes,3,opt,name=borrowed,proto3" json:"borrowed,omitempty"`
+	Collateral         string                 `protobuf:"bytes,4,opt,name=collateral,proto3" json:"collateral,omitempty"`
+	HealthFactor       string                 `protobuf:"bytes,5,opt,name=health_factor,json=healthFactor,proto3" json:"health_factor,omitempty"`
+	CollateralBalances []*CollateralBalance   `protobuf:"bytes,6,rep,name=collateral_balances,json=collateralBalances,proto3" json:"collateral_balances,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
 func (x *AccountPosition) Reset() {
 	*x = AccountPosition{}
-	mi := &file_lending_v1_lending_proto_msgTypes[2]
+	mi := &file_lending_v1_lending_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -174,7 +325,7 @@ func (x *AccountPosition) String() string {
 func (*AccountPosition) ProtoMessage() {}
 
 func (x *AccountPosition) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[2]
+	mi := &file_lending_v1_lending_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -187,7 +338,7 @@ func (x *AccountPosition) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AccountPosition.ProtoReflect.Descriptor instead.
 func (*AccountPosition) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{2}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *AccountPosition) GetAccount() string {
@@ -225,6 +376,13 @@ func (x *AccountPosition) GetHealthFactor() string {
 	return ""
 }
 
+func (x *AccountPosition) GetCollateralBalances() []*CollateralBalance {
+	if x != nil {
+		return x.CollateralBalances
+	}
+	return nil
+}
+
 type GetMarketRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           *MarketKey             `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
@@ -234,7 +392,7 @@ type GetMarketRequest struct {
 
 func (x *GetMarketRequest) Reset() {
 	*x = GetMarketRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[3]
+	mi := &file_lending_v1_lending_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -246,7 +404,7 @@ func (x *GetMarketRequest) String() string {
 func (*GetMarketRequest) ProtoMessage() {}
 
 func (x *GetMarketRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[3]
+	mi := &file_lending_v1_lending_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -259,7 +417,7 @@ func (x *GetMarketRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetMarketRequest.ProtoReflect.Descriptor instead.
 func (*GetMarketRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{3}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetMarketRequest) GetKey() *MarketKey {
@@ -278,7 +436,7 @@ type GetMarketResponse struct {
 
 func (x *GetMarketResponse) Reset() {
 	*x = GetMarketResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[4]
+	mi := &file_lending_v1_lending_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -290,7 +448,7 @@ func (x *GetMarketResponse) String() string {
 func (*GetMarketResponse) ProtoMessage() {}
 
 func (x *GetMarketResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[4]
+	mi := &file_lending_v1_lending_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -303,7 +461,7 @@ func (x *GetMarketResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetMarketResponse.ProtoReflect.Descriptor instead.
 func (*GetMarketResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{4}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetMarketResponse) GetMarket() *Market {
@@ -321,7 +479,7 @@ type ListMarketsRequest struct {
 
 func (x *ListMarketsRequest) Reset() {
 	*x = ListMarketsRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[5]
+	mi := &file_lending_v1_lending_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -333,7 +491,7 @@ func (x *ListMarketsRequest) String() string {
 func (*ListMarketsRequest) ProtoMessage() {}
 
 func (x *ListMarketsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[5]
+	mi := &file_lending_v1_lending_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -346,7 +504,7 @@ func (x *ListMarketsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListMarketsRequest.ProtoReflect.Descriptor instead.
 func (*ListMarketsRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{5}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{7}
 }
 
 type ListMarketsResponse struct {
@@ -358,7 +516,7 @@ type ListMarketsResponse struct {
 
 func (x *ListMarketsResponse) Reset() {
 	*x = ListMarketsResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[6]
+	mi := &file_lending_v1_lending_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -370,7 +528,7 @@ func (x *ListMarketsResponse) String() string {
 func (*ListMarketsResponse) ProtoMessage() {}
 
 func (x *ListMarketsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[6]
+	mi := &file_lending_v1_lending_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -383,7 +541,7 @@ func (x *ListMarketsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListMarketsResponse.ProtoReflect.Descriptor instead.
 func (*ListMarketsResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{6}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ListMarketsResponse) GetMarkets() []*Market {
@@ -402,7 +560,7 @@ type GetPositionRequest struct {
 
 func (x *GetPositionRequest) Reset() {
 	*x = GetPositionRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[7]
+	mi := &file_lending_v1_lending_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -414,7 +572,7 @@ func (x *GetPositionRequest) String() string {
 func (*GetPositionRequest) ProtoMessage() {}
 
 func (x *GetPositionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[7]
+	mi := &file_lending_v1_lending_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -427,7 +585,7 @@ func (x *GetPositionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPositionRequest.ProtoReflect.Descriptor instead.
 func (*GetPositionRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{7}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *GetPositionRequest) GetAccount() string {
@@ -446,7 +604,7 @@ type GetPositionResponse struct {
 
 func (x *GetPositionResponse) Reset() {
 	*x = GetPositionResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[8]
+	mi := &file_lending_v1_lending_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -458,7 +616,7 @@ func (x *GetPositionResponse) String() string {
 func (*GetPositionResponse) ProtoMessage() {}
 
 func (x *GetPositionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[8]
+	mi := &file_lending_v1_lending_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -471,7 +629,7 @@ func (x *GetPositionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPositionResponse.ProtoReflect.Descriptor instead.
 func (*GetPositionResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{8}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *GetPositionResponse) GetPosition() *AccountPosition {
@@ -500,7 +658,7 @@ type SupplyAssetRequest struct {
 
 func (x *SupplyAssetRequest) Reset() {
 	*x = SupplyAssetRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[9]
+	mi := &file_lending_v1_lending_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -512,7 +670,7 @@ func (x *SupplyAssetRequest) String() string {
 func (*SupplyAssetRequest) ProtoMessage() {}
 
 func (x *SupplyAssetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[9]
+	mi := &file_lending_v1_lending_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -525,7 +683,7 @@ func (x *SupplyAssetRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SupplyAssetRequest.ProtoReflect.Descriptor instead.
 func (*SupplyAssetRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{9}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *SupplyAssetRequest) GetAccount() string {
@@ -569,7 +727,7 @@ type SupplyAssetResponse struct {
 
 func (x *SupplyAssetResponse) Reset() {
 	*x = SupplyAssetResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[10]
+	mi := &file_lending_v1_lending_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -581,7 +739,7 @@ func (x *SupplyAssetResponse) String() string {
 func (*SupplyAssetResponse) ProtoMessage() {}
 
 func (x *SupplyAssetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[10]
+	mi := &file_lending_v1_lending_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -594,7 +752,7 @@ func (x *SupplyAssetResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SupplyAssetResponse.ProtoReflect.Descriptor instead.
 func (*SupplyAssetResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{10}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *SupplyAssetResponse) GetTxHash() string {
@@ -616,7 +774,7 @@ type WithdrawAssetRequest struct {
 
 func (x *WithdrawAssetRequest) Reset() {
 	*x = WithdrawAssetRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[11]
+	mi := &file_lending_v1_lending_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -628,7 +786,7 @@ func (x *WithdrawAssetRequest) String() string {
 func (*WithdrawAssetRequest) ProtoMessage() {}
 
 func (x *WithdrawAssetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[11]
+	mi := &file_lending_v1_lending_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -641,7 +799,7 @@ func (x *WithdrawAssetRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WithdrawAssetRequest.ProtoReflect.Descriptor instead.
 func (*WithdrawAssetRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{11}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *WithdrawAssetRequest) GetAccount() string {
@@ -681,7 +839,7 @@ type WithdrawAssetResponse struct {
 
 func (x *WithdrawAssetResponse) Reset() {
 	*x = WithdrawAssetResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[12]
+	mi := &file_lending_v1_lending_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -693,7 +851,7 @@ func (x *WithdrawAssetResponse) String() string {
 func (*WithdrawAssetResponse) ProtoMessage() {}
 
 func (x *WithdrawAssetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[12]
+	mi := &file_lending_v1_lending_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -706,7 +864,7 @@ func (x *WithdrawAssetResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WithdrawAssetResponse.ProtoReflect.Descriptor instead.
 func (*WithdrawAssetResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{12}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *WithdrawAssetResponse) GetTxHash() string {
@@ -728,7 +886,7 @@ type BorrowAssetRequest struct {
 
 func (x *BorrowAssetRequest) Reset() {
 	*x = BorrowAssetRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[13]
+	mi := &file_lending_v1_lending_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -740,7 +898,7 @@ func (x *BorrowAssetRequest) String() string {
 func (*BorrowAssetRequest) ProtoMessage() {}
 
 func (x *BorrowAssetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[13]
+	mi := &file_lending_v1_lending_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -753,7 +911,7 @@ func (x *BorrowAssetRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BorrowAssetRequest.ProtoReflect.Descriptor instead.
 func (*BorrowAssetRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{13}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *BorrowAssetRequest) GetAccount() string {
@@ -793,7 +951,7 @@ type BorrowAssetResponse struct {
 
 func (x *BorrowAssetResponse) Reset() {
 	*x = BorrowAssetResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[14]
+	mi := &file_lending_v1_lending_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -805,7 +963,7 @@ func (x *BorrowAssetResponse) String() string {
 func (*BorrowAssetResponse) ProtoMessage() {}
 
 func (x *BorrowAssetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[14]
+	mi := &file_lending_v1_lending_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -818,7 +976,7 @@ func (x *BorrowAssetResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BorrowAssetResponse.ProtoReflect.Descriptor instead.
 func (*BorrowAssetResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{14}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *BorrowAssetResponse) GetTxHash() string {
@@ -840,7 +998,7 @@ type RepayAssetRequest struct {
 
 func (x *RepayAssetRequest) Reset() {
 	*x = RepayAssetRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[15]
+	mi := &file_lending_v1_lending_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -852,7 +1010,7 @@ func (x *RepayAssetRequest) String() string {
 func (*RepayAssetRequest) ProtoMessage() {}
 
 func (x *RepayAssetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[15]
+	mi := &file_lending_v1_lending_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -865,7 +1023,7 @@ func (x *RepayAssetRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RepayAssetRequest.ProtoReflect.Descriptor instead.
 func (*RepayAssetRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{15}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *RepayAssetRequest) GetAccount() string {
@@ -905,7 +1063,7 @@ type RepayAssetResponse struct {
 
 func (x *RepayAssetResponse) Reset() {
 	*x = RepayAssetResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[16]
+	mi := &file_lending_v1_lending_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -917,7 +1075,7 @@ func (x *RepayAssetResponse) String() string {
 func (*RepayAssetResponse) ProtoMessage() {}
 
 func (x *RepayAssetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[16]
+	mi := &file_lending_v1_lending_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -930,7 +1088,7 @@ func (x *RepayAssetResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RepayAssetResponse.ProtoReflect.Descriptor instead.
 func (*RepayAssetResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{16}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *RepayAssetResponse) GetTxHash() string {
@@ -952,7 +1110,7 @@ type DepositCollateralRequest struct {
 
 func (x *DepositCollateralRequest) Reset() {
 	*x = DepositCollateralRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[17]
+	mi := &file_lending_v1_lending_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -964,7 +1122,7 @@ func (x *DepositCollateralRequest) String() string {
 func (*DepositCollateralRequest) ProtoMessage() {}
 
 func (x *DepositCollateralRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[17]
+	mi := &file_lending_v1_lending_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -977,7 +1135,7 @@ func (x *DepositCollateralRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DepositCollateralRequest.ProtoReflect.Descriptor instead.
 func (*DepositCollateralRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{17}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *DepositCollateralRequest) GetAccount() string {
@@ -1017,7 +1175,7 @@ type DepositCollateralResponse struct {
 
 func (x *DepositCollateralResponse) Reset() {
 	*x = DepositCollateralResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[18]
+	mi := &file_lending_v1_lending_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1029,7 +1187,7 @@ func (x *DepositCollateralResponse) String() string {
 func (*DepositCollateralResponse) ProtoMessage() {}
 
 func (x *DepositCollateralResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[18]
+	mi := &file_lending_v1_lending_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1042,7 +1200,7 @@ func (x *DepositCollateralResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DepositCollateralResponse.ProtoReflect.Descriptor instead.
 func (*DepositCollateralResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{18}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *DepositCollateralResponse) GetTxHash() string {
@@ -1064,7 +1222,7 @@ type WithdrawCollateralRequest struct {
 
 func (x *WithdrawCollateralRequest) Reset() {
 	*x = WithdrawCollateralRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[19]
+	mi := &file_lending_v1_lending_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1076,7 +1234,7 @@ func (x *WithdrawCollateralRequest) String() string {
 func (*WithdrawCollateralRequest) ProtoMessage() {}
 
 func (x *WithdrawCollateralRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[19]
+	mi := &file_lending_v1_lending_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1089,7 +1247,7 @@ func (x *WithdrawCollateralRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WithdrawCollateralRequest.ProtoReflect.Descriptor instead.
 func (*WithdrawCollateralRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{19}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *WithdrawCollateralRequest) GetAccount() string {
@@ -1129,7 +1287,7 @@ type WithdrawCollateralResponse struct {
 
 func (x *WithdrawCollateralResponse) Reset() {
 	*x = WithdrawCollateralResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[20]
+	mi := &file_lending_v1_lending_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1141,7 +1299,7 @@ func (x *WithdrawCollateralResponse) String() string {
 func (*WithdrawCollateralResponse) ProtoMessage() {}
 
 func (x *WithdrawCollateralResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[20]
+	mi := &file_lending_v1_lending_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1154,7 +1312,7 @@ func (x *WithdrawCollateralResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WithdrawCollateralResponse.ProtoReflect.Descriptor instead.
 func (*WithdrawCollateralResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{20}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *WithdrawCollateralResponse) GetTxHash() string {
@@ -1181,7 +1339,7 @@ type LiquidateRequest struct {
 
 func (x *LiquidateRequest) Reset() {
 	*x = LiquidateRequest{}
-	mi := &file_lending_v1_lending_proto_msgTypes[21]
+	mi := &file_lending_v1_lending_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1193,7 +1351,7 @@ func (x *LiquidateRequest) String() string {
 func (*LiquidateRequest) ProtoMessage() {}
 
 func (x *LiquidateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[21]
+	mi := &file_lending_v1_lending_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1206,7 +1364,7 @@ func (x *LiquidateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LiquidateRequest.ProtoReflect.Descriptor instead.
 func (*LiquidateRequest) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{21}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *LiquidateRequest) GetLiquidator() string {
@@ -1246,7 +1404,7 @@ type LiquidateResponse struct {
 
 func (x *LiquidateResponse) Reset() {
 	*x = LiquidateResponse{}
-	mi := &file_lending_v1_lending_proto_msgTypes[22]
+	mi := &file_lending_v1_lending_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1258,7 +1416,7 @@ func (x *LiquidateResponse) String() string {
 func (*LiquidateResponse) ProtoMessage() {}
 
 func (x *LiquidateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_lending_v1_lending_proto_msgTypes[22]
+	mi := &file_lending_v1_lending_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1271,7 +1429,7 @@ func (x *LiquidateResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LiquidateResponse.ProtoReflect.Descriptor instead.
 func (*LiquidateResponse) Descriptor() ([]byte, []int) {
-	return file_lending_v1_lending_proto_rawDescGZIP(), []int{22}
+	return file_lending_v1_lending_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *LiquidateResponse) GetTxHash() string {
@@ -1288,7 +1446,15 @@ const file_lending_v1_lending_proto_rawDesc = "" +
 	"\x18lending/v1/lending.proto\x12\n" +
 	"lending.v1\"#\n" +
 	"\tMarketKey\x12\x16\n" +
-	"\x06symbol\x18\x01 \x01(\tR\x06symbol\"\xf0\x01\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\"\xb5\x01\n" +
+	"\x0fCollateralAsset\x12\x14\n" +
+	"\x05asset\x18\x01 \x01(\tR\x05asset\x12\x17\n" +
+	"\amax_ltv\x18\x02 \x01(\tR\x06maxLtv\x123\n" +
+	"\x15liquidation_threshold\x18\x03 \x01(\tR\x14liquidationThreshold\x12\x1f\n" +
+	"\voracle_feed\x18\x04 \x01(\tR\n" +
+	"oracleFeed\x12\x1d\n" +
+	"\n" +
+	"supply_cap\x18\x05 \x01(\tR\tsupplyCap\"\xdd\x02\n" +
 	"\x06Market\x12'\n" +
 	"\x03key\x18\x01 \x01(\v2\x15.lending.v1.MarketKeyR\x03key\x12\x1d\n" +
 	"\n" +
@@ -1296,7 +1462,12 @@ const file_lending_v1_lending_proto_rawDesc = "" +
 	"\x11collateral_factor\x18\x03 \x01(\tR\x10collateralFactor\x12%\n" +
 	"\x0ereserve_factor\x18\x04 \x01(\tR\rreserveFactor\x12'\n" +
 	"\x0fliquidity_index\x18\x05 \x01(\tR\x0eliquidityIndex\x12!\n" +
-	"\fborrow_index\x18\x06 \x01(\tR\vborrowIndex\"\xa8\x01\n" +
+	"\fborrow_index\x18\x06 \x01(\tR\vborrowIndex\x12!\n" +
+	"\fborrow_asset\x18\a \x01(\tR\vborrowAsset\x12H\n" +
+	"\x11collateral_assets\x18\b \x03(\v2\x1b.lending.v1.CollateralAssetR\x10collateralAssets\"A\n" +
+	"\x11CollateralBalance\x12\x14\n" +
+	"\x05asset\x18\x01 \x01(\tR\x05asset\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\tR\x06amount\"\xf8\x01\n" +
 	"\x0fAccountPosition\x12\x18\n" +
 	"\aaccount\x18\x01 \x01(\tR\aaccount\x12\x1a\n" +
 	"\bsupplied\x18\x02 \x01(\tR\bsupplied\x12\x1a\n" +
@@ -1304,7 +1475,8 @@ const file_lending_v1_lending_proto_rawDesc = "" +
 	"\n" +
 	"collateral\x18\x04 \x01(\tR\n" +
 	"collateral\x12#\n" +
-	"\rhealth_factor\x18\x05 \x01(\tR\fhealthFactor\";\n" +
+	"\rhealth_factor\x18\x05 \x01(\tR\fhealthFactor\x12N\n" +
+	"\x13collateral_balances\x18\x06 \x03(\v2\x1d.lending.v1.CollateralBalanceR\x12collateralBalances\";\n" +
 	"\x10GetMarketRequest\x12'\n" +
 	"\x03key\x18\x01 \x01(\v2\x15.lending.v1.MarketKeyR\x03key\"?\n" +
 	"\x11GetMarketResponse\x12*\n" +
@@ -1392,70 +1564,74 @@ func file_lending_v1_lending_proto_rawDescGZIP() []byte {
 	return file_lending_v1_lending_proto_rawDescData
 }
 
-var file_lending_v1_lending_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_lending_v1_lending_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_lending_v1_lending_proto_goTypes = []any{
 	(*MarketKey)(nil),                  // 0: lending.v1.MarketKey
-	(*Market)(nil),                     // 1: lending.v1.Market
-	(*AccountPosition)(nil),            // 2: lending.v1.AccountPosition
-	(*GetMarketRequest)(nil),           // 3: lending.v1.GetMarketRequest
-	(*GetMarketResponse)(nil),          // 4: lending.v1.GetMarketResponse
-	(*ListMarketsRequest)(nil),         // 5: lending.v1.ListMarketsRequest
-	(*ListMarketsResponse)(nil),        // 6: lending.v1.ListMarketsResponse
-	(*GetPositionRequest)(nil),         // 7: lending.v1.GetPositionRequest
-	(*GetPositionResponse)(nil),        // 8: lending.v1.GetPositionResponse
-	(*SupplyAssetRequest)(nil),         // 9: lending.v1.SupplyAssetRequest
-	(*SupplyAssetResponse)(nil),        // 10: lending.v1.SupplyAssetResponse
-	(*WithdrawAssetRequest)(nil),       // 11: lending.v1.WithdrawAssetRequest
-	(*WithdrawAssetResponse)(nil),      // 12: lending.v1.WithdrawAssetResponse
-	(*BorrowAssetRequest)(nil),         // 13: lending.v1.BorrowAssetRequest
-	(*BorrowAssetResponse)(nil),        // 14: lending.v1.BorrowAssetResponse
-	(*RepayAssetRequest)(nil),          // 15: lending.v1.RepayAssetRequest
-	(*RepayAssetResponse)(nil),         // 16: lending.v1.RepayAssetResponse
-	(*DepositCollateralRequest)(nil),   // 17: lending.v1.DepositCollateralRequest
-	(*DepositCollateralResponse)(nil),  // 18: lending.v1.DepositCollateralResponse
-	(*WithdrawCollateralRequest)(nil),  // 19: lending.v1.WithdrawCollateralRequest
-	(*WithdrawCollateralResponse)(nil), // 20: lending.v1.WithdrawCollateralResponse
-	(*LiquidateRequest)(nil),           // 21: lending.v1.LiquidateRequest
-	(*LiquidateResponse)(nil),          // 22: lending.v1.LiquidateResponse
+	(*CollateralAsset)(nil),            // 1: lending.v1.CollateralAsset
+	(*Market)(nil),                     // 2: lending.v1.Market
+	(*CollateralBalance)(nil),          // 3: lending.v1.CollateralBalance
+	(*AccountPosition)(nil),            // 4: lending.v1.AccountPosition
+	(*GetMarketRequest)(nil),           // 5: lending.v1.GetMarketRequest
+	(*GetMarketResponse)(nil),          // 6: lending.v1.GetMarketResponse
+	(*ListMarketsRequest)(nil),         // 7: lending.v1.ListMarketsRequest
+	(*ListMarketsResponse)(nil),        // 8: lending.v1.ListMarketsResponse
+	(*GetPositionRequest)(nil),         // 9: lending.v1.GetPositionRequest
+	(*GetPositionResponse)(nil),        // 10: lending.v1.GetPositionResponse
+	(*SupplyAssetRequest)(nil),         // 11: lending.v1.SupplyAssetRequest
+	(*SupplyAssetResponse)(nil),        // 12: lending.v1.SupplyAssetResponse
+	(*WithdrawAssetRequest)(nil),       // 13: lending.v1.WithdrawAssetRequest
+	(*WithdrawAssetResponse)(nil),      // 14: lending.v1.WithdrawAssetResponse
+	(*BorrowAssetRequest)(nil),         // 15: lending.v1.BorrowAssetRequest
+	(*BorrowAssetResponse)(nil),        // 16: lending.v1.BorrowAssetResponse
+	(*RepayAssetRequest)(nil),          // 17: lending.v1.RepayAssetRequest
+	(*RepayAssetResponse)(nil),         // 18: lending.v1.RepayAssetResponse
+	(*DepositCollateralRequest)(nil),   // 19: lending.v1.DepositCollateralRequest
+	(*DepositCollateralResponse)(nil),  // 20: lending.v1.DepositCollateralResponse
+	(*WithdrawCollateralRequest)(nil),  // 21: lending.v1.WithdrawCollateralRequest
+	(*WithdrawCollateralResponse)(nil), // 22: lending.v1.WithdrawCollateralResponse
+	(*LiquidateRequest)(nil),           // 23: lending.v1.LiquidateRequest
+	(*LiquidateResponse)(nil),          // 24: lending.v1.LiquidateResponse
 }
 var file_lending_v1_lending_proto_depIdxs = []int32{
 	0,  // 0: lending.v1.Market.key:type_name -> lending.v1.MarketKey
-	0,  // 1: lending.v1.GetMarketRequest.key:type_name -> lending.v1.MarketKey
-	1,  // 2: lending.v1.GetMarketResponse.market:type_name -> lending.v1.Market
-	1,  // 3: lending.v1.ListMarketsResponse.markets:type_name -> lending.v1.Market
-	2,  // 4: lending.v1.GetPositionResponse.position:type_name -> lending.v1.AccountPosition
-	0,  // 5: lending.v1.SupplyAssetRequest.market:type_name -> lending.v1.MarketKey
-	0,  // 6: lending.v1.WithdrawAssetRequest.market:type_name -> lending.v1.MarketKey
-	0,  // 7: lending.v1.BorrowAssetRequest.market:type_name -> lending.v1.MarketKey
-	0,  // 8: lending.v1.RepayAssetRequest.market:type_name -> lending.v1.MarketKey
-	0,  // 9: lending.v1.DepositCollateralRequest.market:type_name -> lending.v1.MarketKey
-	0,  // 10: lending.v1.WithdrawCollateralRequest.market:type_name -> lending.v1.MarketKey
-	0,  // 11: lending.v1.LiquidateRequest.market:type_name -> lending.v1.MarketKey
-	3,  // 12: lending.v1.LendingService.GetMarket:input_type -> lending.v1.GetMarketRequest
-	5,  // 13: lending.v1.LendingService.ListMarkets:input_type -> lending.v1.ListMarketsRequest
-	7,  // 14: lending.v1.LendingService.GetPosition:input_type -> lending.v1.GetPositionRequest
-	9,  // 15: lending.v1.LendingService.SupplyAsset:input_type -> lending.v1.SupplyAssetRequest
-	11, // 16: lending.v1.LendingService.WithdrawAsset:input_type -> lending.v1.WithdrawAssetRequest
-	13, // 17: lending.v1.LendingService.BorrowAsset:input_type -> lending.v1.BorrowAssetRequest
-	15, // 18: lending.v1.LendingService.RepayAsset:input_type -> lending.v1.RepayAssetRequest
-	17, // 19: lending.v1.LendingService.DepositCollateral:input_type -> lending.v1.DepositCollateralRequest
-	19, // 20: lending.v1.LendingService.WithdrawCollateral:input_type -> lending.v1.WithdrawCollateralRequest
-	21, // 21: lending.v1.LendingService.Liquidate:input_type -> lending.v1.LiquidateRequest
-	4,  // 22: lending.v1.LendingService.GetMarket:output_type -> lending.v1.GetMarketResponse
-	6,  // 23: lending.v1.LendingService.ListMarkets:output_type -> lending.v1.ListMarketsResponse
-	8,  // 24: lending.v1.LendingService.GetPosition:output_type -> lending.v1.GetPositionResponse
-	10, // 25: lending.v1.LendingService.SupplyAsset:output_type -> lending.v1.SupplyAssetResponse
-	12, // 26: lending.v1.LendingService.WithdrawAsset:output_type -> lending.v1.WithdrawAssetResponse
-	14, // 27: lending.v1.LendingService.BorrowAsset:output_type -> lending.v1.BorrowAssetResponse
-	16, // 28: lending.v1.LendingService.RepayAsset:output_type -> lending.v1.RepayAssetResponse
-	18, // 29: lending.v1.LendingService.DepositCollateral:output_type -> lending.v1.DepositCollateralResponse
-	20, // 30: lending.v1.LendingService.WithdrawCollateral:output_type -> lending.v1.WithdrawCollateralResponse
-	22, // 31: lending.v1.LendingService.Liquidate:output_type -> lending.v1.LiquidateResponse
-	22, // [22:32] is the sub-list for method output_type
-	12, // [12:22] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	1,  // 1: lending.v1.Market.collateral_assets:type_name -> lending.v1.CollateralAsset
+	3,  // 2: lending.v1.AccountPosition.collateral_balances:type_name -> lending.v1.CollateralBalance
+	0,  // 3: lending.v1.GetMarketRequest.key:type_name -> lending.v1.MarketKey
+	2,  // 4: lending.v1.GetMarketResponse.market:type_name -> lending.v1.Market
+	2,  // 5: lending.v1.ListMarketsResponse.markets:type_name -> lending.v1.Market
+	4,  // 6: lending.v1.GetPositionResponse.position:type_name -> lending.v1.AccountPosition
+	0,  // 7: lending.v1.SupplyAssetRequest.market:type_name -> lending.v1.MarketKey
+	0,  // 8: lending.v1.WithdrawAssetRequest.market:type_name -> lending.v1.MarketKey
+	0,  // 9: lending.v1.BorrowAssetRequest.market:type_name -> lending.v1.MarketKey
+	0,  // 10: lending.v1.RepayAssetRequest.market:type_name -> lending.v1.MarketKey
+	0,  // 11: lending.v1.DepositCollateralRequest.market:type_name -> lending.v1.MarketKey
+	0,  // 12: lending.v1.WithdrawCollateralRequest.market:type_name -> lending.v1.MarketKey
+	0,  // 13: lending.v1.LiquidateRequest.market:type_name -> lending.v1.MarketKey
+	5,  // 14: lending.v1.LendingService.GetMarket:input_type -> lending.v1.GetMarketRequest
+	7,  // 15: lending.v1.LendingService.ListMarkets:input_type -> lending.v1.ListMarketsRequest
+	9,  // 16: lending.v1.LendingService.GetPosition:input_type -> lending.v1.GetPositionRequest
+	11, // 17: lending.v1.LendingService.SupplyAsset:input_type -> lending.v1.SupplyAssetRequest
+	13, // 18: lending.v1.LendingService.WithdrawAsset:input_type -> lending.v1.WithdrawAssetRequest
+	15, // 19: lending.v1.LendingService.BorrowAsset:input_type -> lending.v1.BorrowAssetRequest
+	17, // 20: lending.v1.LendingService.RepayAsset:input_type -> lending.v1.RepayAssetRequest
+	19, // 21: lending.v1.LendingService.DepositCollateral:input_type -> lending.v1.DepositCollateralRequest
+	21, // 22: lending.v1.LendingService.WithdrawCollateral:input_type -> lending.v1.WithdrawCollateralRequest
+	23, // 23: lending.v1.LendingService.Liquidate:input_type -> lending.v1.LiquidateRequest
+	6,  // 24: lending.v1.LendingService.GetMarket:output_type -> lending.v1.GetMarketResponse
+	8,  // 25: lending.v1.LendingService.ListMarkets:output_type -> lending.v1.ListMarketsResponse
+	10, // 26: lending.v1.LendingService.GetPosition:output_type -> lending.v1.GetPositionResponse
+	12, // 27: lending.v1.LendingService.SupplyAsset:output_type -> lending.v1.SupplyAssetResponse
+	14, // 28: lending.v1.LendingService.WithdrawAsset:output_type -> lending.v1.WithdrawAssetResponse
+	16, // 29: lending.v1.LendingService.BorrowAsset:output_type -> lending.v1.BorrowAssetResponse
+	18, // 30: lending.v1.LendingService.RepayAsset:output_type -> lending.v1.RepayAssetResponse
+	20, // 31: lending.v1.LendingService.DepositCollateral:output_type -> lending.v1.DepositCollateralResponse
+	22, // 32: lending.v1.LendingService.WithdrawCollateral:output_type -> lending.v1.WithdrawCollateralResponse
+	24, // 33: lending.v1.LendingService.Liquidate:output_type -> lending.v1.LiquidateResponse
+	24, // [24:34] is the sub-list for method output_type
+	14, // [14:24] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_lending_v1_lending_proto_init() }
@@ -1469,7 +1645,7 @@ func file_lending_v1_lending_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_lending_v1_lending_proto_rawDesc), len(file_lending_v1_lending_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   23,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
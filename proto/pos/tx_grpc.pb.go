@@ -19,9 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Tx_AuthorizePayment_FullMethodName = "/pos.v1.Tx/AuthorizePayment"
-	Tx_CapturePayment_FullMethodName   = "/pos.v1.Tx/CapturePayment"
-	Tx_VoidPayment_FullMethodName      = "/pos.v1.Tx/VoidPayment"
+	Tx_AuthorizePayment_FullMethodName       = "/pos.v1.Tx/AuthorizePayment"
+	Tx_CapturePayment_FullMethodName         = "/pos.v1.Tx/CapturePayment"
+	Tx_VoidPayment_FullMethodName            = "/pos.v1.Tx/VoidPayment"
+	Tx_IncrementAuthorization_FullMethodName = "/pos.v1.Tx/IncrementAuthorization"
+	Tx_RefundPayment_FullMethodName          = "/pos.v1.Tx/RefundPayment"
 )
 
 // TxClient is the client API for Tx service.
@@ -31,6 +33,8 @@ type TxClient interface {
 	AuthorizePayment(ctx context.Context, in *MsgAuthorizePayment, opts ...grpc.CallOption) (*MsgAuthorizePaymentResponse, error)
 	CapturePayment(ctx context.Context, in *MsgCapturePayment, opts ...grpc.CallOption) (*MsgCapturePaymentResponse, error)
 	VoidPayment(ctx context.Context, in *MsgVoidPayment, opts ...grpc.CallOption) (*MsgVoidPaymentResponse, error)
+	IncrementAuthorization(ctx context.Context, in *MsgIncrementAuthorization, opts ...grpc.CallOption) (*MsgIncrementAuthorizationResponse, error)
+	RefundPayment(ctx context.Context, in *MsgRefundPayment, opts ...grpc.CallOption) (*MsgRefundPaymentResponse, error)
 }
 
 type txClient struct {
@@ -68,6 +72,24 @@ func (c *txClient) VoidPayment(ctx context.Context, in *MsgVoidPayment, opts ...
 	return out, nil
 }
 
+func (c *txClient) IncrementAuthorization(ctx context.Context, in *MsgIncrementAuthorization, opts ...grpc.CallOption) (*MsgIncrementAuthorizationResponse, error) {
+	out := new(MsgIncrementAuthorizationResponse)
+	err := c.cc.Invoke(ctx, Tx_IncrementAuthorization_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txClient) RefundPayment(ctx context.Context, in *MsgRefundPayment, opts ...grpc.CallOption) (*MsgRefundPaymentResponse, error) {
+	out := new(MsgRefundPaymentResponse)
+	err := c.cc.Invoke(ctx, Tx_RefundPayment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TxServer is the server API for Tx service.
 // All implementations must embed UnimplementedTxServer
 // for forward compatibility
@@ -75,6 +97,8 @@ type TxServer interface {
 	AuthorizePayment(context.Context, *MsgAuthorizePayment) (*MsgAuthorizePaymentResponse, error)
 	CapturePayment(context.Context, *MsgCapturePayment) (*MsgCapturePaymentResponse, error)
 	VoidPayment(context.Context, *MsgVoidPayment) (*MsgVoidPaymentResponse, error)
+	IncrementAuthorization(context.Context, *MsgIncrementAuthorization) (*MsgIncrementAuthorizationResponse, error)
+	RefundPayment(context.Context, *MsgRefundPayment) (*MsgRefundPaymentResponse, error)
 	mustEmbedUnimplementedTxServer()
 }
 
@@ -91,6 +115,12 @@ func (UnimplementedTxServer) CapturePayment(context.Context, *MsgCapturePayment)
 func (UnimplementedTxServer) VoidPayment(context.Context, *MsgVoidPayment) (*MsgVoidPaymentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method VoidPayment not implemented")
 }
+func (UnimplementedTxServer) IncrementAuthorization(context.Context, *MsgIncrementAuthorization) (*MsgIncrementAuthorizationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IncrementAuthorization not implemented")
+}
+func (UnimplementedTxServer) RefundPayment(context.Context, *MsgRefundPayment) (*MsgRefundPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundPayment not implemented")
+}
 func (UnimplementedTxServer) mustEmbedUnimplementedTxServer() {}
 
 // UnsafeTxServer may be embedded to opt out of forward compatibility for this service.
@@ -158,6 +188,42 @@ func _Tx_VoidPayment_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Tx_IncrementAuthorization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgIncrementAuthorization)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxServer).IncrementAuthorization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tx_IncrementAuthorization_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxServer).IncrementAuthorization(ctx, req.(*MsgIncrementAuthorization))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tx_RefundPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgRefundPayment)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxServer).RefundPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tx_RefundPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxServer).RefundPayment(ctx, req.(*MsgRefundPayment))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Tx_ServiceDesc is the grpc.ServiceDesc for Tx service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -177,6 +243,14 @@ var Tx_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "VoidPayment",
 			Handler:    _Tx_VoidPayment_Handler,
 		},
+		{
+			MethodName: "IncrementAuthorization",
+			Handler:    _Tx_IncrementAuthorization_Handler,
+		},
+		{
+			MethodName: "RefundPayment",
+			Handler:    _Tx_RefundPayment_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pos/tx.proto",
@@ -88,6 +88,10 @@ type creatorPayoutsResult struct {
 	TotalYield string `json:"totalYield"`
 	LastPayout int64  `json:"lastPayout"`
 	Claimed    string `json:"claimed"`
+
+	PendingZNHB            string `json:"pendingZnhb"`
+	TotalSubscriptions     string `json:"totalSubscriptions"`
+	TotalSubscriptionsZNHB string `json:"totalSubscriptionsZnhb"`
 }
 
 func formatCreatorContent(addr string, content *creator.Content) creatorContentResult {
@@ -358,5 +362,10 @@ func (s *Server) handleCreatorPayouts(w http.ResponseWriter, r *http.Request, re
 		LastPayout: last,
 		Claimed:    bigString(claimed),
 	}
+	if ledger != nil {
+		result.PendingZNHB = bigString(ledger.PendingDistributionZNHB)
+		result.TotalSubscriptions = bigString(ledger.TotalSubscriptions)
+		result.TotalSubscriptionsZNHB = bigString(ledger.TotalSubscriptionsZNHB)
+	}
 	writeResult(w, req.ID, result)
 }
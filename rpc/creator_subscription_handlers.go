@@ -0,0 +1,408 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"nhbchain/native/creator"
+)
+
+// creatorAccessSignatureWindow bounds the clock skew accepted between the
+// timestamp a fan signs and the node's clock when requesting an access token.
+const creatorAccessSignatureWindow = 5 * time.Minute
+
+type creatorSetTierParams struct {
+	Caller        string `json:"caller"`
+	TierID        string `json:"tierId"`
+	Name          string `json:"name"`
+	Asset         string `json:"asset"`
+	Price         string `json:"price"`
+	PeriodSeconds int64  `json:"periodSeconds"`
+	Active        *bool  `json:"active,omitempty"`
+}
+
+type creatorGetTierParams struct {
+	Creator string `json:"creator"`
+	TierID  string `json:"tierId"`
+}
+
+type creatorSubscribeParams struct {
+	Caller  string `json:"caller"`
+	Creator string `json:"creator"`
+	TierID  string `json:"tierId"`
+	Periods uint32 `json:"periods"`
+}
+
+type creatorSubscriptionParams struct {
+	Caller  string `json:"caller,omitempty"`
+	Creator string `json:"creator"`
+	Fan     string `json:"fan,omitempty"`
+	Periods uint32 `json:"periods,omitempty"`
+}
+
+type creatorAccessTokenParams struct {
+	Creator   string `json:"creator"`
+	Fan       string `json:"fan"`
+	TierID    string `json:"tierId,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+	Signature string `json:"signature"`
+}
+
+type creatorVerifyAccessParams struct {
+	Token string `json:"token"`
+}
+
+type creatorTierResult struct {
+	Creator       string `json:"creator"`
+	TierID        string `json:"tierId"`
+	Name          string `json:"name"`
+	Asset         string `json:"asset"`
+	Price         string `json:"price"`
+	PeriodSeconds int64  `json:"periodSeconds"`
+	Active        bool   `json:"active"`
+	UpdatedAt     int64  `json:"updatedAt"`
+}
+
+type creatorSubscriptionResult struct {
+	Creator       string `json:"creator"`
+	Fan           string `json:"fan"`
+	TierID        string `json:"tierId"`
+	Asset         string `json:"asset"`
+	Price         string `json:"price"`
+	PeriodSeconds int64  `json:"periodSeconds"`
+	StartedAt     int64  `json:"startedAt"`
+	PaidThrough   int64  `json:"paidThrough"`
+	Active        bool   `json:"active"`
+	Cancelled     bool   `json:"cancelled"`
+	CancelledAt   int64  `json:"cancelledAt,omitempty"`
+	Refund        string `json:"refund,omitempty"`
+}
+
+type creatorAccessTokenResult struct {
+	Token       string `json:"token"`
+	Issuer      string `json:"issuer"`
+	TierID      string `json:"tierId"`
+	PaidThrough int64  `json:"paidThrough"`
+	ExpiresAt   int64  `json:"expiresAt"`
+}
+
+type creatorVerifyAccessResult struct {
+	Valid       bool   `json:"valid"`
+	Reason      string `json:"reason,omitempty"`
+	Creator     string `json:"creator,omitempty"`
+	Fan         string `json:"fan,omitempty"`
+	TierID      string `json:"tierId,omitempty"`
+	PaidThrough int64  `json:"paidThrough,omitempty"`
+	ExpiresAt   int64  `json:"expiresAt,omitempty"`
+}
+
+func formatCreatorTier(tier *creator.SubscriptionTier) creatorTierResult {
+	return creatorTierResult{
+		Creator:       formatAddress(tier.Creator),
+		TierID:        tier.ID,
+		Name:          tier.Name,
+		Asset:         tier.Asset,
+		Price:         bigString(tier.Price),
+		PeriodSeconds: tier.PeriodSeconds,
+		Active:        tier.Active,
+		UpdatedAt:     tier.UpdatedAt,
+	}
+}
+
+func formatCreatorSubscription(sub *creator.Subscription) creatorSubscriptionResult {
+	return creatorSubscriptionResult{
+		Creator:       formatAddress(sub.Creator),
+		Fan:           formatAddress(sub.Fan),
+		TierID:        sub.TierID,
+		Asset:         sub.Asset,
+		Price:         bigString(sub.Price),
+		PeriodSeconds: sub.PeriodSeconds,
+		StartedAt:     sub.StartedAt,
+		PaidThrough:   sub.PaidThrough,
+		Active:        sub.ActiveAt(time.Now().Unix()),
+		Cancelled:     sub.Cancelled,
+		CancelledAt:   sub.CancelledAt,
+	}
+}
+
+// creatorAccessDigest is the message a fan signs to request an access token.
+func creatorAccessDigest(creatorAddr, fan, tierID string, timestamp int64) []byte {
+	payload := fmt.Sprintf("creator_access|%s|%s|%s|%d",
+		strings.ToLower(strings.TrimSpace(creatorAddr)),
+		strings.ToLower(strings.TrimSpace(fan)),
+		strings.TrimSpace(tierID),
+		timestamp)
+	digest := sha256.Sum256([]byte(payload))
+	return digest[:]
+}
+
+func verifyCreatorAccessSignature(params creatorAccessTokenParams, fan [20]byte, now time.Time) error {
+	signedAt := time.Unix(params.Timestamp, 0)
+	if signedAt.Before(now.Add(-creatorAccessSignatureWindow)) || signedAt.After(now.Add(creatorAccessSignatureWindow)) {
+		return fmt.Errorf("timestamp outside the accepted window")
+	}
+	sig, err := decodeHexBytes(params.Signature)
+	if err != nil {
+		return err
+	}
+	if len(sig) != 65 {
+		return fmt.Errorf("signature must be 65 bytes")
+	}
+	pubKey, err := ethcrypto.SigToPub(creatorAccessDigest(params.Creator, params.Fan, params.TierID, params.Timestamp), sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	recovered := ethcrypto.PubkeyToAddress(*pubKey)
+	if !strings.EqualFold(recovered.Hex()[2:], hex.EncodeToString(fan[:])) {
+		return fmt.Errorf("signature does not match fan")
+	}
+	return nil
+}
+
+func decodeCreatorParams(w http.ResponseWriter, req *RPCRequest, out interface{}) bool {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return false
+	}
+	if err := json.Unmarshal(req.Params[0], out); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleCreatorSetTier(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	var params creatorSetTierParams
+	if !decodeCreatorParams(w, req, &params) {
+		return
+	}
+	callerAddr, err := decodeBech32(params.Caller)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid caller address", err.Error())
+		return
+	}
+	price, err := parseAmount(params.Price)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, err.Error(), nil)
+		return
+	}
+	active := true
+	if params.Active != nil {
+		active = *params.Active
+	}
+	tier, err := s.node.CreatorSetTier(callerAddr, &creator.SubscriptionTier{
+		ID:            params.TierID,
+		Name:          params.Name,
+		Asset:         params.Asset,
+		Price:         price,
+		PeriodSeconds: params.PeriodSeconds,
+		Active:        active,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "failed to set tier", err.Error())
+		return
+	}
+	writeResult(w, req.ID, formatCreatorTier(tier))
+}
+
+func (s *Server) handleCreatorGetTier(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	var params creatorGetTierParams
+	if !decodeCreatorParams(w, req, &params) {
+		return
+	}
+	creatorAddr, err := decodeBech32(params.Creator)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid creator address", err.Error())
+		return
+	}
+	tier, err := s.node.CreatorTier(creatorAddr, params.TierID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, "tier not found", err.Error())
+		return
+	}
+	writeResult(w, req.ID, formatCreatorTier(tier))
+}
+
+func (s *Server) handleCreatorSubscribe(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	var params creatorSubscribeParams
+	if !decodeCreatorParams(w, req, &params) {
+		return
+	}
+	callerAddr, err := decodeBech32(params.Caller)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid caller address", err.Error())
+		return
+	}
+	creatorAddr, err := decodeBech32(params.Creator)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid creator address", err.Error())
+		return
+	}
+	periods := params.Periods
+	if periods == 0 {
+		periods = 1
+	}
+	sub, err := s.node.CreatorSubscribe(callerAddr, creatorAddr, params.TierID, periods)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "failed to subscribe", err.Error())
+		return
+	}
+	writeResult(w, req.ID, formatCreatorSubscription(sub))
+}
+
+func (s *Server) handleCreatorRenewSubscription(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	var params creatorSubscriptionParams
+	if !decodeCreatorParams(w, req, &params) {
+		return
+	}
+	callerAddr, err := decodeBech32(params.Caller)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid caller address", err.Error())
+		return
+	}
+	creatorAddr, err := decodeBech32(params.Creator)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid creator address", err.Error())
+		return
+	}
+	periods := params.Periods
+	if periods == 0 {
+		periods = 1
+	}
+	sub, err := s.node.CreatorRenewSubscription(callerAddr, creatorAddr, periods)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "failed to renew subscription", err.Error())
+		return
+	}
+	writeResult(w, req.ID, formatCreatorSubscription(sub))
+}
+
+func (s *Server) handleCreatorCancelSubscription(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	var params creatorSubscriptionParams
+	if !decodeCreatorParams(w, req, &params) {
+		return
+	}
+	callerAddr, err := decodeBech32(params.Caller)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid caller address", err.Error())
+		return
+	}
+	creatorAddr, err := decodeBech32(params.Creator)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid creator address", err.Error())
+		return
+	}
+	sub, refund, err := s.node.CreatorCancelSubscription(callerAddr, creatorAddr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "failed to cancel subscription", err.Error())
+		return
+	}
+	result := formatCreatorSubscription(sub)
+	result.Refund = bigString(refund)
+	writeResult(w, req.ID, result)
+}
+
+func (s *Server) handleCreatorGetSubscription(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	var params creatorSubscriptionParams
+	if !decodeCreatorParams(w, req, &params) {
+		return
+	}
+	creatorAddr, err := decodeBech32(params.Creator)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid creator address", err.Error())
+		return
+	}
+	fan, err := decodeBech32(params.Fan)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid fan address", err.Error())
+		return
+	}
+	sub, err := s.node.CreatorSubscription(creatorAddr, fan)
+	if err != nil {
+		writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, "subscription not found", err.Error())
+		return
+	}
+	writeResult(w, req.ID, formatCreatorSubscription(sub))
+}
+
+// handleCreatorAccessToken issues a short-lived access token to a fan with an
+// active subscription. It needs no RPC credentials: the fan proves control of
+// their address by signing creatorAccessDigest over a recent timestamp.
+func (s *Server) handleCreatorAccessToken(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	var params creatorAccessTokenParams
+	if !decodeCreatorParams(w, req, &params) {
+		return
+	}
+	creatorAddr, err := decodeBech32(params.Creator)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid creator address", err.Error())
+		return
+	}
+	fan, err := decodeBech32(params.Fan)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid fan address", err.Error())
+		return
+	}
+	if err := verifyCreatorAccessSignature(params, fan, time.Now()); err != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, codeUnauthorized, "invalid access request signature", err.Error())
+		return
+	}
+	token, claims, err := s.node.CreatorAccessToken(fan, creatorAddr, params.TierID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, req.ID, codeInvalidParams, "access denied", err.Error())
+		return
+	}
+	writeResult(w, req.ID, creatorAccessTokenResult{
+		Token:       token,
+		Issuer:      formatAddress(s.node.CreatorAccessIssuer()),
+		TierID:      claims.TierID,
+		PaidThrough: claims.PaidThrough,
+		ExpiresAt:   claims.ExpiresAt,
+	})
+}
+
+func (s *Server) handleCreatorVerifyAccess(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	var params creatorVerifyAccessParams
+	if !decodeCreatorParams(w, req, &params) {
+		return
+	}
+	claims, valid, reason, err := s.node.CreatorVerifyAccessToken(params.Token)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to verify token", err.Error())
+		return
+	}
+	result := creatorVerifyAccessResult{Valid: valid, Reason: reason}
+	if claims != nil {
+		if creatorAddr, fan, err := claims.Parties(); err == nil {
+			result.Creator = formatAddress(creatorAddr)
+			result.Fan = formatAddress(fan)
+		}
+		result.TierID = claims.TierID
+		result.PaidThrough = claims.PaidThrough
+		result.ExpiresAt = claims.ExpiresAt
+	}
+	writeResult(w, req.ID, result)
+}
@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"nhbchain/crypto"
+)
+
+func TestVerifyCreatorAccessSignature(t *testing.T) {
+	fanKey, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	otherKey, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	fan := addressFromKey(fanKey)
+	now := time.Unix(1_700_000_000, 0)
+	sign := func(key *crypto.PrivateKey, params creatorAccessTokenParams) creatorAccessTokenParams {
+		sig, err := ethcrypto.Sign(creatorAccessDigest(params.Creator, params.Fan, params.TierID, params.Timestamp), key.PrivateKey)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		params.Signature = "0x" + hex.EncodeToString(sig)
+		return params
+	}
+	base := creatorAccessTokenParams{
+		Creator:   otherKey.PubKey().Address().String(),
+		Fan:       fanKey.PubKey().Address().String(),
+		TierID:    "gold",
+		Timestamp: now.Unix(),
+	}
+
+	if err := verifyCreatorAccessSignature(sign(fanKey, base), fan, now); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	if err := verifyCreatorAccessSignature(sign(otherKey, base), fan, now); err == nil {
+		t.Fatalf("expected signature from another key to be rejected")
+	}
+	signed := sign(fanKey, base)
+	signed.TierID = "platinum"
+	if err := verifyCreatorAccessSignature(signed, fan, now); err == nil {
+		t.Fatalf("expected a changed tier to invalidate the signature")
+	}
+	if err := verifyCreatorAccessSignature(sign(fanKey, base), fan, now.Add(10*time.Minute)); err == nil {
+		t.Fatalf("expected a stale timestamp to be rejected")
+	}
+}
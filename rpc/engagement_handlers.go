@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"nhbchain/core"
+	"nhbchain/core/engagement"
 )
 
 type engagementRegisterDeviceParams struct {
@@ -17,6 +18,26 @@ type engagementRegisterDeviceResult struct {
 	Token string `json:"token"`
 }
 
+type engagementListDevicesParams struct {
+	Address string `json:"address"`
+}
+
+type engagementDeviceResult struct {
+	DeviceID      string `json:"deviceId"`
+	Owner         string `json:"owner"`
+	RegisteredAt  int64  `json:"registeredAt"`
+	LastHeartbeat int64  `json:"lastHeartbeat,omitempty"`
+}
+
+type engagementRevokeDeviceParams struct {
+	Address  string `json:"address"`
+	DeviceID string `json:"deviceId"`
+}
+
+type engagementRevokeDeviceResult struct {
+	Revoked bool `json:"revoked"`
+}
+
 type engagementSubmitHeartbeatParams struct {
 	DeviceID  string `json:"deviceId"`
 	Token     string `json:"token"`
@@ -55,6 +76,72 @@ func (s *Server) handleEngagementRegisterDevice(w http.ResponseWriter, _ *http.R
 	writeResult(w, req.ID, engagementRegisterDeviceResult{Token: token})
 }
 
+func (s *Server) handleEngagementListDevices(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "list requires parameter object", nil)
+		return
+	}
+	var params engagementListDevicesParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid request parameters", err.Error())
+		return
+	}
+	if params.Address == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "address is required", nil)
+		return
+	}
+	addr, err := decodeBech32(params.Address)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid address", err.Error())
+		return
+	}
+	devices, err := s.node.EngagementListDevices(addr)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, err.Error(), nil)
+		return
+	}
+	result := make([]engagementDeviceResult, 0, len(devices))
+	for _, device := range devices {
+		result = append(result, engagementDeviceResult{
+			DeviceID:      device.ID,
+			Owner:         formatAddress(device.Owner),
+			RegisteredAt:  device.RegisteredAt,
+			LastHeartbeat: device.LastHeartbeat,
+		})
+	}
+	writeResult(w, req.ID, result)
+}
+
+func (s *Server) handleEngagementRevokeDevice(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "revoke requires parameter object", nil)
+		return
+	}
+	var params engagementRevokeDeviceParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid request parameters", err.Error())
+		return
+	}
+	if params.Address == "" || params.DeviceID == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "address and deviceId are required", nil)
+		return
+	}
+	addr, err := decodeBech32(params.Address)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid address", err.Error())
+		return
+	}
+	if err := s.node.EngagementRevokeDevice(addr, params.DeviceID); err != nil {
+		if errors.Is(err, engagement.ErrUnknownDevice) {
+			writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, err.Error(), nil)
+			return
+		}
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, err.Error(), nil)
+		return
+	}
+	writeResult(w, req.ID, engagementRevokeDeviceResult{Revoked: true})
+}
+
 func (s *Server) handleEngagementSubmitHeartbeat(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
 	if len(req.Params) != 1 {
 		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "submit requires parameter object", nil)
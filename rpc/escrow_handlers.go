@@ -55,6 +55,13 @@ type escrowDisputeParams struct {
 	Message string `json:"message,omitempty"`
 }
 
+type escrowEvidenceParams struct {
+	ID     string `json:"id"`
+	Caller string `json:"caller"`
+	Hash   string `json:"hash"`
+	URI    string `json:"uri,omitempty"`
+}
+
 type escrowFundParams struct {
 	ID   string `json:"id"`
 	From string `json:"from"`
@@ -260,6 +267,68 @@ func (s *Server) handleEscrowDispute(w http.ResponseWriter, r *http.Request, req
 	writeResult(w, req.ID, "ok")
 }
 
+func (s *Server) handleEscrowSubmitEvidence(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", "exactly one parameter object expected")
+		return
+	}
+	var params escrowEvidenceParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	id, err := parseEscrowID(params.ID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	caller, err := parseBech32Address(params.Caller)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	hash, err := parseEscrowID(params.Hash)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", fmt.Sprintf("hash: %v", err))
+		return
+	}
+	if err := s.node.EscrowSubmitEvidence(id, caller, hash, params.URI); err != nil {
+		writeEscrowError(w, req.ID, err)
+		return
+	}
+	writeResult(w, req.ID, "ok")
+}
+
+func (s *Server) handleEscrowEscalate(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", "exactly one parameter object expected")
+		return
+	}
+	var params escrowIDParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	id, err := parseEscrowID(params.ID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	if err := s.node.EscrowEscalate(id); err != nil {
+		writeEscrowError(w, req.ID, err)
+		return
+	}
+	writeResult(w, req.ID, "ok")
+}
+
 func (s *Server) handleEscrowTransition(w http.ResponseWriter, r *http.Request, req *RPCRequest, fn func([32]byte, [20]byte) error) {
 	if authErr := s.requireAuthInto(&r); authErr != nil {
 		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
@@ -509,6 +578,8 @@ func escrowStatusString(status escrow.EscrowStatus) string {
 		return "expired"
 	case escrow.EscrowDisputed:
 		return "disputed"
+	case escrow.EscrowSplit:
+		return "split"
 	default:
 		return "unknown"
 	}
@@ -47,6 +47,20 @@ type milestoneLegActionParams struct {
 	Caller string `json:"caller"`
 }
 
+type milestoneDisputeParams struct {
+	ID     string `json:"id"`
+	LegID  uint64 `json:"legId"`
+	Caller string `json:"caller"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type milestoneResolveParams struct {
+	ID         string   `json:"id"`
+	LegID      uint64   `json:"legId"`
+	Decision   string   `json:"decision"`
+	Signatures []string `json:"signatures"`
+}
+
 type milestoneSubscriptionUpdateParams struct {
 	ID     string `json:"id"`
 	Caller string `json:"caller"`
@@ -78,6 +92,9 @@ type milestoneLegJSON struct {
 	Amount   string `json:"amount"`
 	Deadline int64  `json:"deadline"`
 	Status   string `json:"status"`
+
+	DisputedAt    int64  `json:"disputedAt,omitempty"`
+	DisputeReason string `json:"disputeReason,omitempty"`
 }
 
 func (s *Server) handleEscrowMilestoneCreate(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
@@ -264,6 +281,95 @@ func (s *Server) handleEscrowMilestoneCancel(w http.ResponseWriter, r *http.Requ
 	writeResult(w, req.ID, map[string]string{"status": "cancelled"})
 }
 
+func (s *Server) handleEscrowMilestoneDispute(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", "exactly one parameter object expected")
+		return
+	}
+	var params milestoneDisputeParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	id, err := parseEscrowID(params.ID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	caller, err := parseBech32Address(params.Caller)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	if params.LegID == 0 {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", "legId must be > 0")
+		return
+	}
+	if err := s.node.EscrowMilestoneDispute(id, params.LegID, caller, params.Reason); err != nil {
+		writeMilestoneError(w, req.ID, err)
+		return
+	}
+	writeResult(w, req.ID, map[string]string{"status": "disputed"})
+}
+
+func (s *Server) handleEscrowMilestoneResolve(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", "exactly one parameter object expected")
+		return
+	}
+	var params milestoneResolveParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	id, err := parseEscrowID(params.ID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	if params.LegID == 0 {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", "legId must be > 0")
+		return
+	}
+	decision := strings.TrimSpace(params.Decision)
+	if decision == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", "decision payload required")
+		return
+	}
+	if len(params.Signatures) == 0 {
+		writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", "signatures required")
+		return
+	}
+	signatures := make([][]byte, len(params.Signatures))
+	for i, sigHex := range params.Signatures {
+		trimmed := strings.TrimSpace(sigHex)
+		decoded, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(trimmed, "0x"), "0X"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, req.ID, codeEscrowInvalidParams, "invalid_params", fmt.Sprintf("invalid signature %d: %v", i, err))
+			return
+		}
+		signatures[i] = decoded
+	}
+	if err := s.node.EscrowMilestoneResolve(id, params.LegID, []byte(decision), signatures); err != nil {
+		writeMilestoneError(w, req.ID, err)
+		return
+	}
+	project, err := s.node.EscrowMilestoneGet(id)
+	if err != nil {
+		writeMilestoneError(w, req.ID, err)
+		return
+	}
+	writeResult(w, req.ID, formatMilestoneJSON(project))
+}
+
 func (s *Server) handleEscrowMilestoneSubscriptionUpdate(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
 	if authErr := s.requireAuthInto(&r); authErr != nil {
 		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
@@ -391,6 +497,9 @@ func formatMilestoneJSON(project *escrow.MilestoneProject) milestoneProjectJSON
 			Amount:   leg.Amount.String(),
 			Deadline: leg.Deadline,
 			Status:   formatMilestoneLegStatus(leg.Status),
+
+			DisputedAt:    leg.DisputedAt,
+			DisputeReason: leg.DisputeReason,
 		})
 	}
 	meta := ""
@@ -446,6 +555,10 @@ func formatMilestoneLegStatus(status escrow.MilestoneLegStatus) string {
 		return "cancelled"
 	case escrow.MilestoneLegExpired:
 		return "expired"
+	case escrow.MilestoneLegDisputed:
+		return "disputed"
+	case escrow.MilestoneLegSplit:
+		return "split"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,296 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nhbchain/core"
+
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+)
+
+type callMsgParams struct {
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Data     string `json:"data,omitempty"`
+	Input    string `json:"input,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Gas      string `json:"gas,omitempty"`
+	GasPrice string `json:"gasPrice,omitempty"`
+}
+
+type traceConfigParams struct {
+	Tracer           string          `json:"tracer,omitempty"`
+	TracerConfig     json.RawMessage `json:"tracerConfig,omitempty"`
+	EnableMemory     bool            `json:"enableMemory,omitempty"`
+	DisableStack     bool            `json:"disableStack,omitempty"`
+	DisableStorage   bool            `json:"disableStorage,omitempty"`
+	EnableReturnData bool            `json:"enableReturnData,omitempty"`
+	Limit            int             `json:"limit,omitempty"`
+}
+
+// CallResultJSON is the JSON shape returned by nhb_call.
+type CallResultJSON struct {
+	ReturnData   string `json:"returnData"`
+	GasUsed      string `json:"gasUsed"`
+	Reverted     bool   `json:"reverted"`
+	RevertReason string `json:"revertReason,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+func (s *Server) handleCall(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) == 0 || len(req.Params) > 2 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", "expected call object and optional block tag")
+		return
+	}
+	msg, err := decodeCallMsgParam(req.Params[0])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	height, err := decodeBlockTagParam(req.Params, 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	result, err := s.node.CallContract(msg, height)
+	if err != nil {
+		writeEVMError(w, req.ID, err)
+		return
+	}
+	writeResult(w, req.ID, formatCallResultJSON(result))
+}
+
+func (s *Server) handleDebugTraceCall(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) == 0 || len(req.Params) > 3 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", "expected call object, optional block tag and optional trace config")
+		return
+	}
+	msg, err := decodeCallMsgParam(req.Params[0])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	height, err := decodeBlockTagParam(req.Params, 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	cfg, err := decodeTraceConfigParam(req.Params, 2)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	trace, err := s.node.TraceCall(msg, height, cfg)
+	if err != nil {
+		writeEVMError(w, req.ID, err)
+		return
+	}
+	writeResult(w, req.ID, trace)
+}
+
+func (s *Server) handleDebugTraceTransaction(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) == 0 || len(req.Params) > 2 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", "expected transaction hash and optional trace config")
+		return
+	}
+	var hashParam string
+	if err := json.Unmarshal(req.Params[0], &hashParam); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", "transaction hash must be a string")
+		return
+	}
+	hash, err := decodeHexParam(hashParam)
+	if err != nil || len(hash) != 32 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", "transaction hash must be 32 bytes of hex")
+		return
+	}
+	cfg, err := decodeTraceConfigParam(req.Params, 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	trace, err := s.node.TraceTransaction(hash, cfg)
+	if err != nil {
+		writeEVMError(w, req.ID, err)
+		return
+	}
+	writeResult(w, req.ID, trace)
+}
+
+func decodeCallMsgParam(raw json.RawMessage) (core.CallMsg, error) {
+	var params callMsgParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return core.CallMsg{}, err
+	}
+	var msg core.CallMsg
+	if strings.TrimSpace(params.From) != "" {
+		from, err := parseCallAddress(params.From)
+		if err != nil {
+			return core.CallMsg{}, fmt.Errorf("from: %w", err)
+		}
+		msg.From = from
+	}
+	if strings.TrimSpace(params.To) != "" {
+		to, err := parseCallAddress(params.To)
+		if err != nil {
+			return core.CallMsg{}, fmt.Errorf("to: %w", err)
+		}
+		msg.To = to
+	}
+	input := params.Data
+	if strings.TrimSpace(input) == "" {
+		input = params.Input
+	}
+	data, err := parseHexBytes(input)
+	if err != nil {
+		return core.CallMsg{}, fmt.Errorf("data: %w", err)
+	}
+	msg.Data = data
+	if msg.Value, err = parseCallQuantity(params.Value); err != nil {
+		return core.CallMsg{}, fmt.Errorf("value: %w", err)
+	}
+	if msg.GasPrice, err = parseCallQuantity(params.GasPrice); err != nil {
+		return core.CallMsg{}, fmt.Errorf("gasPrice: %w", err)
+	}
+	gas, err := parseCallQuantity(params.Gas)
+	if err != nil {
+		return core.CallMsg{}, fmt.Errorf("gas: %w", err)
+	}
+	if gas != nil {
+		if !gas.IsUint64() {
+			return core.CallMsg{}, fmt.Errorf("gas: out of range")
+		}
+		msg.Gas = gas.Uint64()
+	}
+	return msg, nil
+}
+
+// parseCallAddress accepts either a bech32 account or a 0x-prefixed 20-byte
+// hex address, the latter being what EVM tooling produces.
+func parseCallAddress(value string) ([]byte, error) {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "0x") || strings.HasPrefix(trimmed, "0X") {
+		decoded, err := hex.DecodeString(trimmed[2:])
+		if err != nil {
+			return nil, err
+		}
+		if len(decoded) != 20 {
+			return nil, fmt.Errorf("address must be 20 bytes")
+		}
+		return decoded, nil
+	}
+	addr, err := parseBech32Address(trimmed)
+	if err != nil {
+		return nil, err
+	}
+	return addr[:], nil
+}
+
+// parseCallQuantity parses 0x-prefixed hex or decimal quantities. Empty input
+// yields nil so callers can fall back to their defaults.
+func parseCallQuantity(value string) (*big.Int, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil, nil
+	}
+	base := 10
+	if strings.HasPrefix(trimmed, "0x") || strings.HasPrefix(trimmed, "0X") {
+		trimmed = trimmed[2:]
+		base = 16
+	}
+	amount, ok := new(big.Int).SetString(trimmed, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity")
+	}
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("quantity must not be negative")
+	}
+	return amount, nil
+}
+
+// decodeBlockTagParam reads the optional block selector at index. "latest"
+// (or an absent parameter) selects the chain tip; numbers may be JSON
+// integers, decimal strings or 0x-prefixed hex strings.
+func decodeBlockTagParam(params []json.RawMessage, index int) (*uint64, error) {
+	if len(params) <= index {
+		return nil, nil
+	}
+	raw := params[index]
+	var number uint64
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return &number, nil
+	}
+	var tag string
+	if err := json.Unmarshal(raw, &tag); err != nil {
+		return nil, fmt.Errorf("block tag must be a number or string")
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	switch tag {
+	case "", "latest", "pending", "safe", "finalized":
+		return nil, nil
+	case "earliest":
+		number = 0
+		return &number, nil
+	}
+	var err error
+	if strings.HasPrefix(tag, "0x") {
+		number, err = strconv.ParseUint(tag[2:], 16, 64)
+	} else {
+		number, err = strconv.ParseUint(tag, 10, 64)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid block tag %q", tag)
+	}
+	return &number, nil
+}
+
+func decodeTraceConfigParam(params []json.RawMessage, index int) (core.TraceConfig, error) {
+	if len(params) <= index {
+		return core.TraceConfig{}, nil
+	}
+	var cfg traceConfigParams
+	if err := json.Unmarshal(params[index], &cfg); err != nil {
+		return core.TraceConfig{}, fmt.Errorf("trace config: %w", err)
+	}
+	return core.TraceConfig{
+		Tracer:       strings.TrimSpace(cfg.Tracer),
+		TracerConfig: cfg.TracerConfig,
+		Logger: logger.Config{
+			EnableMemory:     cfg.EnableMemory,
+			DisableStack:     cfg.DisableStack,
+			DisableStorage:   cfg.DisableStorage,
+			EnableReturnData: cfg.EnableReturnData,
+			Limit:            cfg.Limit,
+		},
+	}, nil
+}
+
+func formatCallResultJSON(result *core.CallResult) CallResultJSON {
+	if result == nil {
+		return CallResultJSON{ReturnData: "0x", GasUsed: hexString(0)}
+	}
+	return CallResultJSON{
+		ReturnData:   "0x" + hex.EncodeToString(result.ReturnData),
+		GasUsed:      hexString(result.GasUsed),
+		Reverted:     result.Reverted,
+		RevertReason: result.RevertReason,
+		Error:        result.VMError,
+	}
+}
+
+func writeEVMError(w http.ResponseWriter, id interface{}, err error) {
+	switch {
+	case errors.Is(err, core.ErrTraceTxNotFound):
+		writeError(w, http.StatusNotFound, id, codeInvalidParams, "not_found", err.Error())
+	case errors.Is(err, core.ErrTraceNotEVM), errors.Is(err, core.ErrUnknownTracer), errors.Is(err, core.ErrCallStateUnavailable):
+		writeError(w, http.StatusBadRequest, id, codeInvalidParams, "invalid_params", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, id, codeServerError, "internal_error", err.Error())
+	}
+}
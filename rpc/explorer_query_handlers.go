@@ -830,7 +830,8 @@ func mathPow10(digits int) float64 {
 
 func isPaymentLikeType(t types.TxType) bool {
 	switch t {
-	case types.TxTypeTransfer, types.TxTypePOSAuthorize, types.TxTypePOSCapture, types.TxTypePOSVoid:
+	case types.TxTypeTransfer, types.TxTypePOSAuthorize, types.TxTypePOSCapture, types.TxTypePOSVoid,
+		types.TxTypePOSIncrement, types.TxTypePOSRefund:
 		return true
 	default:
 		return false
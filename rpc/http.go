@@ -1070,6 +1070,9 @@ type POSAuthorizationResult struct {
 	CreatedAt      uint64 `json:"createdAt"`
 	UpdatedAt      uint64 `json:"updatedAt"`
 	VoidReason     string `json:"voidReason,omitempty"`
+	// MerchantRefundedAmount is the part of CapturedAmount the merchant has
+	// refunded to the payer after capture.
+	MerchantRefundedAmount string `json:"merchantRefundedAmount"`
 }
 
 func decodeHexParam(value string) ([]byte, error) {
@@ -1089,6 +1092,8 @@ func posAuthorizationStatusLabel(status pos.AuthorizationStatus) string {
 		return "voided"
 	case pos.AuthorizationStatusExpired:
 		return "expired"
+	case pos.AuthorizationStatusRefunded:
+		return "refunded"
 	default:
 		return "unknown"
 	}
@@ -1110,18 +1115,23 @@ func buildPOSAuthorizationResult(auth *pos.Authorization) *POSAuthorizationResul
 	if auth.RefundedAmount != nil {
 		refunded = auth.RefundedAmount.String()
 	}
+	merchantRefunded := "0"
+	if auth.MerchantRefundedAmount != nil {
+		merchantRefunded = auth.MerchantRefundedAmount.String()
+	}
 	result := &POSAuthorizationResult{
-		ID:             ensureHexPrefix(hex.EncodeToString(auth.ID[:])),
-		Payer:          crypto.MustNewAddress(crypto.NHBPrefix, auth.Payer[:]).String(),
-		Merchant:       crypto.MustNewAddress(crypto.NHBPrefix, auth.Merchant[:]).String(),
-		Amount:         amount,
-		CapturedAmount: captured,
-		RefundedAmount: refunded,
-		Expiry:         auth.Expiry,
-		Status:         posAuthorizationStatusLabel(auth.Status),
-		CreatedAt:      auth.CreatedAt,
-		UpdatedAt:      auth.UpdatedAt,
-		VoidReason:     auth.VoidReason,
+		ID:                     ensureHexPrefix(hex.EncodeToString(auth.ID[:])),
+		Payer:                  crypto.MustNewAddress(crypto.NHBPrefix, auth.Payer[:]).String(),
+		Merchant:               crypto.MustNewAddress(crypto.NHBPrefix, auth.Merchant[:]).String(),
+		Amount:                 amount,
+		CapturedAmount:         captured,
+		RefundedAmount:         refunded,
+		Expiry:                 auth.Expiry,
+		Status:                 posAuthorizationStatusLabel(auth.Status),
+		CreatedAt:              auth.CreatedAt,
+		UpdatedAt:              auth.UpdatedAt,
+		VoidReason:             auth.VoidReason,
+		MerchantRefundedAmount: merchantRefunded,
 	}
 	if len(auth.IntentRef) > 0 {
 		result.IntentRef = ensureHexPrefix(hex.EncodeToString(auth.IntentRef))
@@ -1358,6 +1368,20 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 		s.handleGetTransaction(recorder, r, req)
 	case "nhb_getTransactionReceipt":
 		s.handleGetTransactionReceipt(recorder, r, req)
+	case "nhb_call":
+		s.handleCall(recorder, r, req)
+	case "debug_traceCall":
+		if authErr := s.requireAuthInto(&r); authErr != nil {
+			writeError(recorder, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+			return
+		}
+		s.handleDebugTraceCall(recorder, r, req)
+	case "debug_traceTransaction":
+		if authErr := s.requireAuthInto(&r); authErr != nil {
+			writeError(recorder, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+			return
+		}
+		s.handleDebugTraceTransaction(recorder, r, req)
 	case "nhb_searchExplorer":
 		s.handleSearchExplorer(recorder, r, req)
 	case "nhb_getExplorerSnapshot":
@@ -1478,6 +1502,20 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 		s.handlePOSGetAuthorization(recorder, r, req)
 	case "pos_getAuthorizationByIntentRef":
 		s.handlePOSGetAuthorizationByIntentRef(recorder, r, req)
+	case "invoice_get":
+		s.handleInvoiceGet(recorder, r, req)
+	case "invoice_listByMerchant":
+		s.handleInvoiceListByMerchant(recorder, r, req)
+	case "mandate_get":
+		s.handleMandateGet(recorder, r, req)
+	case "mandate_listByPayer":
+		s.handleMandateList(recorder, r, req, mandateListByPayer)
+	case "mandate_listByMerchant":
+		s.handleMandateList(recorder, r, req, mandateListByMerchant)
+	case "recovery_getStatus":
+		s.handleRecoveryGetStatus(recorder, r, req)
+	case "session_listKeys":
+		s.handleSessionListKeys(recorder, r, req)
 	case "lending_getMarket":
 		s.handleLendingGetMarket(recorder, r, req)
 	case "lend_getPools":
@@ -1544,6 +1582,26 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 		s.handleLoyaltyResolveUsername(recorder, r, req)
 	case "loyalty_userQR":
 		s.handleLoyaltyUserQR(recorder, r, req)
+	case "loyalty_setCatalogueItem":
+		s.handleLoyaltySetCatalogueItem(recorder, r, req)
+	case "loyalty_listCatalogue":
+		s.handleLoyaltyListCatalogue(recorder, r, req)
+	case "loyalty_getRedemption":
+		s.handleLoyaltyGetRedemption(recorder, r, req)
+	case "loyalty_userTier":
+		s.handleLoyaltyUserTier(recorder, r, req)
+	case "loyalty_createCoalition":
+		s.handleLoyaltyCreateCoalition(recorder, r, req)
+	case "loyalty_inviteCoalitionMember":
+		s.handleLoyaltyInviteCoalitionMember(recorder, r, req)
+	case "loyalty_joinCoalition":
+		s.handleLoyaltyJoinCoalition(recorder, r, req)
+	case "loyalty_leaveCoalition":
+		s.handleLoyaltyLeaveCoalition(recorder, r, req)
+	case "loyalty_getCoalition":
+		s.handleLoyaltyGetCoalition(recorder, r, req)
+	case "loyalty_coalitionReport":
+		s.handleLoyaltyCoalitionReport(recorder, r, req)
 	case "creator_publish":
 		s.handleCreatorPublish(recorder, r, req)
 	case "creator_tip":
@@ -1554,6 +1612,22 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 		s.handleCreatorUnstake(recorder, r, req)
 	case "creator_payouts":
 		s.handleCreatorPayouts(recorder, r, req)
+	case "creator_setTier":
+		s.handleCreatorSetTier(recorder, r, req)
+	case "creator_getTier":
+		s.handleCreatorGetTier(recorder, r, req)
+	case "creator_subscribe":
+		s.handleCreatorSubscribe(recorder, r, req)
+	case "creator_renewSubscription":
+		s.handleCreatorRenewSubscription(recorder, r, req)
+	case "creator_cancelSubscription":
+		s.handleCreatorCancelSubscription(recorder, r, req)
+	case "creator_getSubscription":
+		s.handleCreatorGetSubscription(recorder, r, req)
+	case "creator_accessToken":
+		s.handleCreatorAccessToken(recorder, r, req)
+	case "creator_verifyAccess":
+		s.handleCreatorVerifyAccess(recorder, r, req)
 	case "identity_setAlias":
 		s.handleIdentitySetAlias(recorder, r, req)
 	case "identity_setAvatar":
@@ -1566,6 +1640,22 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 		s.handleIdentitySetPrimary(recorder, r, req)
 	case "identity_rename":
 		s.handleIdentityRename(recorder, r, req)
+	case "identity_renew":
+		s.handleIdentityRenew(recorder, r, req)
+	case "identity_listForSale":
+		s.handleIdentityListForSale(recorder, r, req)
+	case "identity_cancelListing":
+		s.handleIdentityCancelListing(recorder, r, req)
+	case "identity_buy":
+		s.handleIdentityBuy(recorder, r, req)
+	case "identity_createSubAlias":
+		s.handleIdentityCreateSubAlias(recorder, r, req)
+	case "identity_reassignSubAlias":
+		s.handleIdentityReassignSubAlias(recorder, r, req)
+	case "identity_revokeSubAlias":
+		s.handleIdentityRevokeSubAlias(recorder, r, req)
+	case "identity_listSubAliases":
+		s.handleIdentityListSubAliases(recorder, r, req)
 	case "identity_resolve":
 		s.handleIdentityResolve(recorder, r, req)
 	case "identity_reverse":
@@ -1602,6 +1692,10 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 		s.handleEscrowExpire(recorder, r, req)
 	case "escrow_dispute":
 		s.handleEscrowDispute(recorder, r, req)
+	case "escrow_submitEvidence":
+		s.handleEscrowSubmitEvidence(recorder, r, req)
+	case "escrow_escalate":
+		s.handleEscrowEscalate(recorder, r, req)
 	case "escrow_resolve":
 		s.handleEscrowResolve(recorder, r, req)
 	case "escrow_milestoneCreate":
@@ -1614,6 +1708,10 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 		s.handleEscrowMilestoneRelease(recorder, r, req)
 	case "escrow_milestoneCancel":
 		s.handleEscrowMilestoneCancel(recorder, r, req)
+	case "escrow_milestoneDispute":
+		s.handleEscrowMilestoneDispute(recorder, r, req)
+	case "escrow_milestoneResolve":
+		s.handleEscrowMilestoneResolve(recorder, r, req)
 	case "escrow_milestoneSubscriptionUpdate":
 		s.handleEscrowMilestoneSubscriptionUpdate(recorder, r, req)
 	case "net_info":
@@ -1650,6 +1748,18 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		s.handleEngagementRegisterDevice(recorder, r, req)
+	case "engagement_list_devices":
+		if authErr := s.requireAuthInto(&r); authErr != nil {
+			writeError(recorder, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+			return
+		}
+		s.handleEngagementListDevices(recorder, r, req)
+	case "engagement_revoke_device":
+		if authErr := s.requireAuthInto(&r); authErr != nil {
+			writeError(recorder, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+			return
+		}
+		s.handleEngagementRevokeDevice(recorder, r, req)
 	case "engagement_submit_heartbeat":
 		if authErr := s.requireAuthInto(&r); authErr != nil {
 			writeError(recorder, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
@@ -1726,6 +1836,8 @@ func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
 		s.handleGovernanceExecute(recorder, r, req)
 	case "reputation_verifySkill":
 		s.handleReputationVerifySkill(recorder, r, req)
+	case "reputation_getScore":
+		s.handleReputationGetScore(recorder, r, req)
 	default:
 		writeError(recorder, http.StatusNotFound, req.ID, codeMethodNotFound, fmt.Sprintf("unknown method %s", req.Method), nil)
 	}
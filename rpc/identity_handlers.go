@@ -18,13 +18,75 @@ type identitySetAliasResult struct {
 }
 
 type identityResolveResult struct {
-	Alias     string   `json:"alias"`
-	AliasID   string   `json:"aliasId"`
-	Primary   string   `json:"primary"`
-	Addresses []string `json:"addresses"`
-	AvatarRef string   `json:"avatarRef,omitempty"`
-	CreatedAt int64    `json:"createdAt"`
-	UpdatedAt int64    `json:"updatedAt"`
+	Alias     string                 `json:"alias"`
+	AliasID   string                 `json:"aliasId"`
+	Primary   string                 `json:"primary"`
+	Addresses []string               `json:"addresses"`
+	AvatarRef string                 `json:"avatarRef,omitempty"`
+	CreatedAt int64                  `json:"createdAt"`
+	UpdatedAt int64                  `json:"updatedAt"`
+	ExpiresAt int64                  `json:"expiresAt,omitempty"`
+	Records   map[string]string      `json:"records,omitempty"`
+	Parent    string                 `json:"parent,omitempty"`
+	Listing   *identityListingResult `json:"listing,omitempty"`
+}
+
+type identityListingResult struct {
+	Alias    string `json:"alias"`
+	Seller   string `json:"seller"`
+	Buyer    string `json:"buyer,omitempty"`
+	Price    string `json:"price"`
+	ListedAt int64  `json:"listedAt"`
+}
+
+type identityRenewParams struct {
+	Owner string `json:"owner"`
+	Alias string `json:"alias"`
+}
+
+type identityRenewResult struct {
+	Alias     string `json:"alias"`
+	ExpiresAt int64  `json:"expiresAt"`
+	Fee       string `json:"fee"`
+}
+
+type identityListForSaleParams struct {
+	Owner string `json:"owner"`
+	Alias string `json:"alias"`
+	Price string `json:"price"`
+	Buyer string `json:"buyer,omitempty"`
+}
+
+type identityCancelListingParams struct {
+	Owner string `json:"owner"`
+	Alias string `json:"alias"`
+}
+
+type identityBuyParams struct {
+	Buyer string `json:"buyer"`
+	Alias string `json:"alias"`
+	Price string `json:"price"`
+}
+
+type identitySubAliasParams struct {
+	Owner   string `json:"owner"`
+	Parent  string `json:"parent,omitempty"`
+	Label   string `json:"label,omitempty"`
+	Alias   string `json:"alias,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+type identitySubAliasResult struct {
+	Alias     string `json:"alias"`
+	Parent    string `json:"parent"`
+	Address   string `json:"address"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+type identityBuyResult struct {
+	identityResolveResult
+	EscrowID string `json:"escrowId,omitempty"`
 }
 
 type identityReverseResult struct {
@@ -114,6 +176,33 @@ func identityRecordToResult(record *identity.AliasRecord) identityResolveResult
 	if record.AvatarRef != "" {
 		result.AvatarRef = record.AvatarRef
 	}
+	result.ExpiresAt = record.ExpiresAt
+	result.Parent = record.Parent
+	if len(record.Records) > 0 {
+		result.Records = make(map[string]string, len(record.Records))
+		for _, entry := range record.Records {
+			result.Records[entry.Key] = entry.Value
+		}
+	}
+	return result
+}
+
+func identityListingToResult(listing *identity.Listing) *identityListingResult {
+	if listing == nil {
+		return nil
+	}
+	result := &identityListingResult{
+		Alias:    listing.Alias,
+		Seller:   crypto.MustNewAddress(crypto.NHBPrefix, listing.Seller[:]).String(),
+		Price:    "0",
+		ListedAt: listing.ListedAt,
+	}
+	if listing.Reserved() {
+		result.Buyer = crypto.MustNewAddress(crypto.NHBPrefix, listing.Buyer[:]).String()
+	}
+	if listing.Price != nil {
+		result.Price = listing.Price.String()
+	}
 	return result
 }
 
@@ -146,6 +235,8 @@ func (s *Server) handleIdentitySetAlias(w http.ResponseWriter, r *http.Request,
 			writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid alias", err.Error())
 		case errors.Is(err, identity.ErrAliasTaken):
 			writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "alias already registered", aliasParam)
+		case errors.Is(err, identity.ErrSubAliasNamespace):
+			writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "alias reserved for sub-aliases of its parent", aliasParam)
 		default:
 			writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to set alias", err.Error())
 		}
@@ -397,6 +488,8 @@ func (s *Server) handleIdentityRename(w http.ResponseWriter, r *http.Request, re
 			writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, "alias not registered", alias)
 		case errors.Is(err, identity.ErrAliasTaken):
 			writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "alias already registered", newAlias)
+		case errors.Is(err, identity.ErrSubAliasNamespace):
+			writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "alias reserved for sub-aliases of its parent", newAlias)
 		case errors.Is(err, identity.ErrNotAliasOwner):
 			writeError(w, http.StatusForbidden, req.ID, codeUnauthorized, "caller not alias owner", owner)
 		default:
@@ -427,7 +520,334 @@ func (s *Server) handleIdentityResolve(w http.ResponseWriter, _ *http.Request, r
 		writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, "alias not found", normalized)
 		return
 	}
-	writeResult(w, req.ID, identityRecordToResult(record))
+	result := identityRecordToResult(record)
+	if listing, ok, err := s.node.IdentityGetListing(normalized); err == nil && ok {
+		result.Listing = identityListingToResult(listing)
+	}
+	writeResult(w, req.ID, result)
+}
+
+func (s *Server) handleIdentityRenew(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "expected parameter object", nil)
+		return
+	}
+	var params identityRenewParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	owner := strings.TrimSpace(params.Owner)
+	alias := strings.TrimSpace(params.Alias)
+	if owner == "" || alias == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "owner and alias are required", nil)
+		return
+	}
+	ownerAddr, err := decodeBech32(owner)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid owner address", err.Error())
+		return
+	}
+	record, fee, err := s.node.IdentityRenew(ownerAddr, alias)
+	if err != nil {
+		writeIdentityMutationError(w, req, err, alias, owner, "failed to renew alias")
+		return
+	}
+	feeText := "0"
+	if fee != nil {
+		feeText = fee.String()
+	}
+	writeResult(w, req.ID, identityRenewResult{Alias: record.Alias, ExpiresAt: record.ExpiresAt, Fee: feeText})
+}
+
+func (s *Server) handleIdentityListForSale(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "expected parameter object", nil)
+		return
+	}
+	var params identityListForSaleParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	owner := strings.TrimSpace(params.Owner)
+	alias := strings.TrimSpace(params.Alias)
+	if owner == "" || alias == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "owner and alias are required", nil)
+		return
+	}
+	ownerAddr, err := decodeBech32(owner)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid owner address", err.Error())
+		return
+	}
+	price, err := parseNonNegativeAmount(params.Price)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid price", err.Error())
+		return
+	}
+	var buyerAddr [20]byte
+	if buyer := strings.TrimSpace(params.Buyer); buyer != "" {
+		buyerAddr, err = decodeBech32(buyer)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid buyer address", err.Error())
+			return
+		}
+	}
+	listing, err := s.node.IdentityListForSale(ownerAddr, alias, price, buyerAddr)
+	if err != nil {
+		writeIdentityMutationError(w, req, err, alias, owner, "failed to list alias")
+		return
+	}
+	writeResult(w, req.ID, identityListingToResult(listing))
+}
+
+func (s *Server) handleIdentityCancelListing(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "expected parameter object", nil)
+		return
+	}
+	var params identityCancelListingParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	owner := strings.TrimSpace(params.Owner)
+	alias := strings.TrimSpace(params.Alias)
+	if owner == "" || alias == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "owner and alias are required", nil)
+		return
+	}
+	ownerAddr, err := decodeBech32(owner)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid owner address", err.Error())
+		return
+	}
+	if err := s.node.IdentityCancelListing(ownerAddr, alias); err != nil {
+		writeIdentityMutationError(w, req, err, alias, owner, "failed to cancel listing")
+		return
+	}
+	writeResult(w, req.ID, identitySetAliasResult{OK: true})
+}
+
+func (s *Server) handleIdentityBuy(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "expected parameter object", nil)
+		return
+	}
+	var params identityBuyParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	buyer := strings.TrimSpace(params.Buyer)
+	alias := strings.TrimSpace(params.Alias)
+	if buyer == "" || alias == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "buyer and alias are required", nil)
+		return
+	}
+	buyerAddr, err := decodeBech32(buyer)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid buyer address", err.Error())
+		return
+	}
+	price, err := parseNonNegativeAmount(params.Price)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid price", err.Error())
+		return
+	}
+	record, escrowID, err := s.node.IdentityBuy(buyerAddr, alias, price)
+	if err != nil {
+		writeIdentityMutationError(w, req, err, alias, buyer, "failed to buy alias")
+		return
+	}
+	result := identityBuyResult{identityResolveResult: identityRecordToResult(record)}
+	if escrowID != ([32]byte{}) {
+		result.EscrowID = "0x" + hex.EncodeToString(escrowID[:])
+	}
+	writeResult(w, req.ID, result)
+}
+
+func identitySubAliasToResult(sub *identity.SubAlias) identitySubAliasResult {
+	return identitySubAliasResult{
+		Alias:     sub.Name(),
+		Parent:    sub.Parent,
+		Address:   crypto.MustNewAddress(crypto.NHBPrefix, sub.Target[:]).String(),
+		CreatedAt: sub.CreatedAt,
+		UpdatedAt: sub.UpdatedAt,
+	}
+}
+
+// decodeIdentitySubAliasParams reads the single parameter object shared by
+// the sub-alias mutations and decodes the owner address.
+func decodeIdentitySubAliasParams(w http.ResponseWriter, req *RPCRequest) (identitySubAliasParams, [20]byte, bool) {
+	var params identitySubAliasParams
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "expected parameter object", nil)
+		return params, [20]byte{}, false
+	}
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return params, [20]byte{}, false
+	}
+	params.Owner = strings.TrimSpace(params.Owner)
+	params.Parent = strings.TrimSpace(params.Parent)
+	params.Label = strings.TrimSpace(params.Label)
+	params.Alias = strings.TrimSpace(params.Alias)
+	params.Address = strings.TrimSpace(params.Address)
+	if params.Owner == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "owner is required", nil)
+		return params, [20]byte{}, false
+	}
+	ownerAddr, err := decodeBech32(params.Owner)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid owner address", err.Error())
+		return params, [20]byte{}, false
+	}
+	return params, ownerAddr, true
+}
+
+func (s *Server) handleIdentityCreateSubAlias(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	params, ownerAddr, ok := decodeIdentitySubAliasParams(w, req)
+	if !ok {
+		return
+	}
+	if params.Parent == "" || params.Label == "" || params.Address == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "owner, parent, label, and address are required", nil)
+		return
+	}
+	target, err := decodeBech32(params.Address)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid address", err.Error())
+		return
+	}
+	sub, err := s.node.IdentityCreateSubAlias(ownerAddr, params.Parent, params.Label, target)
+	if err != nil {
+		writeIdentityMutationError(w, req, err, params.Label+"."+params.Parent, params.Owner, "failed to create sub-alias")
+		return
+	}
+	writeResult(w, req.ID, identitySubAliasToResult(sub))
+}
+
+func (s *Server) handleIdentityReassignSubAlias(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	params, ownerAddr, ok := decodeIdentitySubAliasParams(w, req)
+	if !ok {
+		return
+	}
+	if params.Alias == "" || params.Address == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "owner, alias, and address are required", nil)
+		return
+	}
+	target, err := decodeBech32(params.Address)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid address", err.Error())
+		return
+	}
+	sub, err := s.node.IdentityReassignSubAlias(ownerAddr, params.Alias, target)
+	if err != nil {
+		writeIdentityMutationError(w, req, err, params.Alias, params.Owner, "failed to reassign sub-alias")
+		return
+	}
+	writeResult(w, req.ID, identitySubAliasToResult(sub))
+}
+
+func (s *Server) handleIdentityRevokeSubAlias(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	params, ownerAddr, ok := decodeIdentitySubAliasParams(w, req)
+	if !ok {
+		return
+	}
+	if params.Alias == "" {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "owner and alias are required", nil)
+		return
+	}
+	if err := s.node.IdentityRevokeSubAlias(ownerAddr, params.Alias); err != nil {
+		writeIdentityMutationError(w, req, err, params.Alias, params.Owner, "failed to revoke sub-alias")
+		return
+	}
+	writeResult(w, req.ID, identitySetAliasResult{OK: true})
+}
+
+func (s *Server) handleIdentityListSubAliases(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "parent alias parameter required", nil)
+		return
+	}
+	var parentParam string
+	if err := json.Unmarshal(req.Params[0], &parentParam); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parent parameter", err.Error())
+		return
+	}
+	subs, err := s.node.IdentitySubAliases(parentParam)
+	if err != nil {
+		writeIdentityMutationError(w, req, err, parentParam, "", "failed to list sub-aliases")
+		return
+	}
+	results := make([]identitySubAliasResult, 0, len(subs))
+	for _, sub := range subs {
+		results = append(results, identitySubAliasToResult(sub))
+	}
+	writeResult(w, req.ID, results)
+}
+
+// writeIdentityMutationError maps errors from alias renewals, sales and
+// sub-alias management onto JSON-RPC responses.
+func writeIdentityMutationError(w http.ResponseWriter, req *RPCRequest, err error, alias, caller, fallback string) {
+	switch {
+	case errors.Is(err, identity.ErrInvalidAlias):
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid alias", err.Error())
+	case errors.Is(err, identity.ErrAliasNotFound):
+		writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, "alias not registered", alias)
+	case errors.Is(err, identity.ErrNotAliasOwner):
+		writeError(w, http.StatusForbidden, req.ID, codeUnauthorized, "caller not alias owner", caller)
+	case errors.Is(err, identity.ErrAliasExpired):
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "alias registration expired", alias)
+	case errors.Is(err, identity.ErrTermsDisabled):
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "alias registrations do not expire", alias)
+	case errors.Is(err, identity.ErrListingNotFound):
+		writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, "alias not listed for sale", alias)
+	case errors.Is(err, identity.ErrListingMismatch):
+		writeError(w, http.StatusConflict, req.ID, codeInvalidParams, "listing does not match request", err.Error())
+	case errors.Is(err, identity.ErrInvalidPrice), errors.Is(err, identity.ErrInvalidAddress):
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid listing", err.Error())
+	case errors.Is(err, identity.ErrAddressLinked):
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "address already linked to another alias", caller)
+	case errors.Is(err, identity.ErrAliasTaken):
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "alias already registered", alias)
+	case errors.Is(err, identity.ErrSubAliasNotFound):
+		writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, "sub-alias not found", alias)
+	case errors.Is(err, identity.ErrSubAliasLimit):
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "sub-alias limit reached", alias)
+	default:
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, fallback, err.Error())
+	}
 }
 
 func (s *Server) handleIdentityCreateClaimable(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/crypto"
+)
+
+type invoiceIDParams struct {
+	ID string `json:"id"`
+}
+
+type invoiceMerchantParams struct {
+	Merchant string `json:"merchant"`
+}
+
+// InvoiceResult is the JSON view of a native merchant invoice.
+type InvoiceResult struct {
+	ID           string `json:"id"`
+	Merchant     string `json:"merchant"`
+	Asset        string `json:"asset"`
+	Amount       string `json:"amount"`
+	Paid         string `json:"paid"`
+	Remaining    string `json:"remaining"`
+	MemoHash     string `json:"memoHash,omitempty"`
+	AllowPartial bool   `json:"allowPartial"`
+	Status       string `json:"status"`
+	CreatedAt    uint64 `json:"createdAt"`
+	Expiry       uint64 `json:"expiry,omitempty"`
+	SettledAt    uint64 `json:"settledAt,omitempty"`
+	IntentRef    string `json:"intentRef"`
+}
+
+func (s *Server) handleInvoiceGet(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if s.node == nil {
+		writeError(w, http.StatusServiceUnavailable, req.ID, codeServerError, "node unavailable", nil)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params invoiceIDParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	id, err := parseEscrowID(params.ID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	invoice, err := s.node.GetInvoice(id)
+	if err != nil {
+		if errors.Is(err, nhbstate.ErrInvoiceNotFound) {
+			writeResultAllowNil(w, req.ID, nil)
+			return
+		}
+		slog.Error("rpc: get invoice failed", slog.String("id", params.ID), slog.Any("error", err))
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load invoice", nil)
+		return
+	}
+	writeResult(w, req.ID, buildInvoiceResult(invoice, time.Now()))
+}
+
+func (s *Server) handleInvoiceListByMerchant(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if s.node == nil {
+		writeError(w, http.StatusServiceUnavailable, req.ID, codeServerError, "node unavailable", nil)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params invoiceMerchantParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	merchant, err := parseBech32Address(params.Merchant)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	invoices, err := s.node.ListInvoicesByMerchant(merchant)
+	if err != nil {
+		slog.Error("rpc: list invoices failed", slog.String("merchant", params.Merchant), slog.Any("error", err))
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to list invoices", nil)
+		return
+	}
+	now := time.Now()
+	results := make([]InvoiceResult, 0, len(invoices))
+	for _, invoice := range invoices {
+		results = append(results, buildInvoiceResult(invoice, now))
+	}
+	writeResult(w, req.ID, results)
+}
+
+// buildInvoiceResult renders an invoice, reporting accepting invoices whose
+// expiry has passed as "expired".
+func buildInvoiceResult(invoice *nhbstate.StoredInvoice, now time.Time) InvoiceResult {
+	status := invoice.Status
+	if nhbstate.InvoiceStatus(status).Accepting() && invoice.Expired(uint64(now.Unix())) {
+		status = "expired"
+	}
+	result := InvoiceResult{
+		ID:           formatEscrowID(invoice.ID),
+		Merchant:     crypto.MustNewAddress(crypto.NHBPrefix, invoice.Merchant[:]).String(),
+		Asset:        invoice.Asset,
+		Amount:       invoice.Amount.String(),
+		Paid:         invoice.Paid.String(),
+		Remaining:    invoice.Remaining().String(),
+		AllowPartial: invoice.AllowPartial,
+		Status:       status,
+		CreatedAt:    invoice.CreatedAt,
+		Expiry:       invoice.Expiry,
+		SettledAt:    invoice.SettledAt,
+	}
+	if invoice.MemoHash != ([32]byte{}) {
+		result.MemoHash = "0x" + hex.EncodeToString(invoice.MemoHash[:])
+	}
+	if ref, err := nhbstate.InvoiceIntentRef(invoice.ID, nil); err == nil {
+		result.IntentRef = "0x" + hex.EncodeToString(ref)
+	}
+	return result
+}
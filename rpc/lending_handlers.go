@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"nhbchain/native/lending"
+	"nhbchain/rpc/modules"
 )
 
 const defaultLendingPoolID = "default"
@@ -40,17 +41,25 @@ type lendingPositionResult struct {
 	ValueUsd  string `json:"valueUsd"`
 }
 
+// lendingCollateralResult reports the amount pledged for one collateral
+// asset.
+type lendingCollateralResult struct {
+	Asset     string `json:"asset"`
+	AmountWei string `json:"amountWei"`
+}
+
 // lendingAccountResult is the JSON-tagged account view returned over RPC.
 // lending.UserAccount itself has no JSON tags and carries an unexported
 // crypto.Address field that serializes as "{}", so it can never be returned
 // to clients directly -- this type is the properly-shaped replacement.
 type lendingAccountResult struct {
-	Address            string                  `json:"address"`
-	Supplied           []lendingPositionResult `json:"supplied"`
-	Borrowed           []lendingPositionResult `json:"borrowed"`
-	CollateralValueUsd string                  `json:"collateralValueUsd"`
-	BorrowedValueUsd   string                  `json:"borrowedValueUsd"`
-	RewardsWei         string                  `json:"rewardsWei"`
+	Address            string                    `json:"address"`
+	Supplied           []lendingPositionResult   `json:"supplied"`
+	Borrowed           []lendingPositionResult   `json:"borrowed"`
+	Collateral         []lendingCollateralResult `json:"collateral"`
+	CollateralValueUsd string                    `json:"collateralValueUsd"`
+	BorrowedValueUsd   string                    `json:"borrowedValueUsd"`
+	RewardsWei         string                    `json:"rewardsWei"`
 }
 
 type lendingUserAccountResult struct {
@@ -61,13 +70,12 @@ type lendingUserAccountResult struct {
 // trimmed base-10 decimal string, e.g. "12.5", mirroring the nhbportal
 // client's own fromWei conversion so amounts surfaced over RPC render
 // consistently with values the client formats locally. NHB is a $1-pegged
-// asset (see README.md) and, per the known collateral-valuation placeholder
-// in native/lending/engine.go (positionHealthy compares raw ZNHB collateral
-// directly against NHB debt with no oracle conversion), ZNHB collateral is
-// currently valued 1:1 against it. This helper therefore doubles as the
-// wei-to-USD converter for collateralValueUsd/borrowedValueUsd until a real
-// price oracle is wired in -- it does not attempt to fix that pricing gap,
-// only to surface whatever value the engine already computes.
+// asset (see README.md) and collateral in markets without oracle feeds is
+// valued 1:1 against it (see positionHealthy in native/lending/engine.go).
+// This helper therefore doubles as the wei-to-USD converter for
+// collateralValueUsd/borrowedValueUsd. Markets that price collateral through
+// oracle feeds still report the raw sum here; the per-asset amounts in the
+// collateral array are exact.
 func weiToDecimalString(amount *big.Int) string {
 	if amount == nil || amount.Sign() == 0 {
 		return "0"
@@ -99,9 +107,10 @@ func weiToDecimalString(amount *big.Int) string {
 // lending.RedeemableSupply).
 func newLendingAccountResult(poolID string, addr [20]byte, account *lending.UserAccount, supplyIndex *big.Int) *lendingAccountResult {
 	result := &lendingAccountResult{
-		Address:  "0x" + hex.EncodeToString(addr[:]),
-		Supplied: []lendingPositionResult{},
-		Borrowed: []lendingPositionResult{},
+		Address:    "0x" + hex.EncodeToString(addr[:]),
+		Supplied:   []lendingPositionResult{},
+		Borrowed:   []lendingPositionResult{},
+		Collateral: []lendingCollateralResult{},
 		// The lending engine does not yet accrue a separate rewards balance
 		// (see native/lending/engine.go); report zero rather than inventing
 		// a figure until that mechanism exists.
@@ -130,14 +139,32 @@ func newLendingAccountResult(poolID string, addr [20]byte, account *lending.User
 		})
 	}
 
-	result.CollateralValueUsd = weiToDecimalString(account.CollateralZNHB)
+	totalCollateral := big.NewInt(0)
+	for _, asset := range account.CollateralAssets() {
+		amount := account.CollateralOf(asset)
+		result.Collateral = append(result.Collateral, lendingCollateralResult{Asset: asset, AmountWei: amount.String()})
+		totalCollateral.Add(totalCollateral, amount)
+	}
+	result.CollateralValueUsd = weiToDecimalString(totalCollateral)
 	result.BorrowedValueUsd = weiToDecimalString(account.DebtNHB)
 	return result
 }
 
 type lendingCreatePoolParams struct {
-	PoolID         string `json:"poolId"`
-	DeveloperOwner string `json:"developerOwner"`
+	PoolID              string                        `json:"poolId"`
+	DeveloperOwner      string                        `json:"developerOwner"`
+	BorrowAsset         string                        `json:"borrowAsset,omitempty"`
+	BorrowOracleFeed    string                        `json:"borrowOracleFeed,omitempty"`
+	OracleMaxAgeSeconds uint64                        `json:"oracleMaxAgeSeconds,omitempty"`
+	Collateral          []lendingCollateralAssetParam `json:"collateral,omitempty"`
+}
+
+type lendingCollateralAssetParam struct {
+	Asset                string `json:"asset"`
+	MaxLTV               uint64 `json:"maxLTV"`
+	LiquidationThreshold uint64 `json:"liquidationThreshold"`
+	OracleFeed           string `json:"oracleFeed,omitempty"`
+	SupplyCap            string `json:"supplyCap,omitempty"`
 }
 
 func (s *Server) handleLendingGetMarket(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
@@ -215,7 +242,29 @@ func (s *Server) handleLendCreatePool(w http.ResponseWriter, r *http.Request, re
 		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid developerOwner", err.Error())
 		return
 	}
-	market, moduleErr := s.lending.CreatePool(poolID, ownerAddr)
+	assets := modules.LendingPoolAssets{
+		BorrowAsset:         input.BorrowAsset,
+		BorrowOracleFeed:    input.BorrowOracleFeed,
+		OracleMaxAgeSeconds: input.OracleMaxAgeSeconds,
+	}
+	for _, param := range input.Collateral {
+		cfg := lending.CollateralAsset{
+			Asset:                param.Asset,
+			MaxLTV:               param.MaxLTV,
+			LiquidationThreshold: param.LiquidationThreshold,
+			OracleFeed:           param.OracleFeed,
+		}
+		if capStr := strings.TrimSpace(param.SupplyCap); capStr != "" {
+			supplyCap, ok := new(big.Int).SetString(capStr, 10)
+			if !ok || supplyCap.Sign() < 0 {
+				writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid collateral supplyCap", param.SupplyCap)
+				return
+			}
+			cfg.SupplyCap = supplyCap
+		}
+		assets.Collateral = append(assets.Collateral, cfg)
+	}
+	market, moduleErr := s.lending.CreatePool(poolID, ownerAddr, assets)
 	if moduleErr != nil {
 		writeError(w, moduleErr.HTTPStatus, req.ID, moduleErr.Code, moduleErr.Message, moduleErr.Data)
 		return
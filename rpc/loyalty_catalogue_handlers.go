@@ -0,0 +1,281 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"nhbchain/crypto"
+	"nhbchain/native/loyalty"
+)
+
+type catalogueItemParams struct {
+	Caller    string `json:"caller"`
+	ProgramID string `json:"programId"`
+	ItemID    string `json:"itemId"`
+	Name      string `json:"name,omitempty"`
+	CostWei   string `json:"costWei"`
+	Mode      string `json:"mode,omitempty"`
+	Stock     uint64 `json:"stock,omitempty"`
+	Active    *bool  `json:"active,omitempty"`
+}
+
+type programQueryParams struct {
+	ProgramID string `json:"programId"`
+}
+
+type redemptionQueryParams struct {
+	ID string `json:"id"`
+}
+
+type userTierParams struct {
+	ProgramID string `json:"programId"`
+	User      string `json:"user"`
+}
+
+type catalogueItemResult struct {
+	ProgramID string `json:"programId"`
+	ItemID    string `json:"itemId"`
+	Name      string `json:"name,omitempty"`
+	CostWei   string `json:"costWei"`
+	Mode      string `json:"mode"`
+	Stock     uint64 `json:"stock"`
+	Redeemed  uint64 `json:"redeemed"`
+	Active    bool   `json:"active"`
+}
+
+type redemptionResult struct {
+	ID         string `json:"id"`
+	ProgramID  string `json:"programId"`
+	ItemID     string `json:"itemId"`
+	User       string `json:"user"`
+	Quantity   uint64 `json:"quantity"`
+	CostWei    string `json:"costWei"`
+	Token      string `json:"token"`
+	Mode       string `json:"mode"`
+	RedeemedAt uint64 `json:"redeemedAt"`
+}
+
+type rewardLotResult struct {
+	Amount    string `json:"amount"`
+	Remaining string `json:"remaining"`
+	AccruedAt uint64 `json:"accruedAt"`
+	ExpiresAt uint64 `json:"expiresAt,omitempty"`
+}
+
+type userTierResult struct {
+	ProgramID      string            `json:"programId"`
+	User           string            `json:"user"`
+	TrailingSpend  string            `json:"trailingSpend"`
+	TierWindowDays uint32            `json:"tierWindowDays"`
+	Tier           string            `json:"tier,omitempty"`
+	AccrualBps     uint32            `json:"accrualBps"`
+	Lots           []rewardLotResult `json:"lots"`
+	PendingExpiry  string            `json:"pendingExpiry"`
+}
+
+func (s *Server) handleLoyaltySetCatalogueItem(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params catalogueItemParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	callerAddr, err := decodeBech32(params.Caller)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid caller address", err.Error())
+		return
+	}
+	programID, err := parseProgramID(params.ProgramID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid programId", err.Error())
+		return
+	}
+	cost, err := parseBigInt(&params.CostWei)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid costWei", err.Error())
+		return
+	}
+	active := true
+	if params.Active != nil {
+		active = *params.Active
+	}
+	item := &loyalty.CatalogueItem{
+		ProgramID: programID,
+		ItemID:    params.ItemID,
+		Name:      params.Name,
+		CostWei:   cost,
+		Mode:      params.Mode,
+		Stock:     params.Stock,
+		Active:    active,
+	}
+	registry := s.node.LoyaltyRegistry()
+	if err := registry.SetCatalogueItem(callerAddr, item); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "failed to set catalogue item", err.Error())
+		return
+	}
+	writeResult(w, req.ID, "ok")
+}
+
+func (s *Server) handleLoyaltyListCatalogue(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params programQueryParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	programID, err := parseProgramID(params.ProgramID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid programId", err.Error())
+		return
+	}
+	items, err := loyalty.NewRegistry(s.node.LoyaltyManager()).ListCatalogue(programID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load catalogue", err.Error())
+		return
+	}
+	results := make([]catalogueItemResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, catalogueItemResult{
+			ProgramID: formatProgramID(item.ProgramID),
+			ItemID:    item.ItemID,
+			Name:      item.Name,
+			CostWei:   bigIntToString(item.CostWei),
+			Mode:      item.Mode,
+			Stock:     item.Stock,
+			Redeemed:  item.Redeemed,
+			Active:    item.Active,
+		})
+	}
+	writeResult(w, req.ID, results)
+}
+
+func (s *Server) handleLoyaltyGetRedemption(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params redemptionQueryParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	id, err := parseRedemptionID(params.ID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid id", err.Error())
+		return
+	}
+	receipt, ok, err := loyalty.NewRegistry(s.node.LoyaltyManager()).Redemption(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load redemption", err.Error())
+		return
+	}
+	if !ok {
+		writeResultAllowNil(w, req.ID, nil)
+		return
+	}
+	writeResult(w, req.ID, redemptionResult{
+		ID:         "0x" + hex.EncodeToString(receipt.ID[:]),
+		ProgramID:  formatProgramID(receipt.ProgramID),
+		ItemID:     receipt.ItemID,
+		User:       crypto.MustNewAddress(crypto.NHBPrefix, receipt.User[:]).String(),
+		Quantity:   receipt.Quantity,
+		CostWei:    bigIntToString(receipt.CostWei),
+		Token:      receipt.Token,
+		Mode:       receipt.Mode,
+		RedeemedAt: receipt.RedeemedAt,
+	})
+}
+
+func (s *Server) handleLoyaltyUserTier(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params userTierParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	programID, err := parseProgramID(params.ProgramID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid programId", err.Error())
+		return
+	}
+	addr, err := decodeBech32(params.User)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid user address", err.Error())
+		return
+	}
+	program, ok, err := s.node.LoyaltyProgramByID(programID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load program", err.Error())
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, "program not found", params.ProgramID)
+		return
+	}
+	manager := s.node.LoyaltyManager()
+	now := uint64(time.Now().Unix())
+	trailing, err := loyalty.TrailingSpend(manager, program, addr[:], now)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load meters", err.Error())
+		return
+	}
+	lots, err := manager.LoyaltyRewardLots(programID, addr[:])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load reward lots", err.Error())
+		return
+	}
+	live, expired := loyalty.ExpireLots(lots, now)
+	tier, bps := program.TierFor(trailing)
+	result := userTierResult{
+		ProgramID:      formatProgramID(programID),
+		User:           strings.TrimSpace(params.User),
+		TrailingSpend:  trailing.String(),
+		TierWindowDays: program.TierWindowDays,
+		AccrualBps:     bps,
+		Lots:           make([]rewardLotResult, 0, len(live)),
+		PendingExpiry:  expired.String(),
+	}
+	if tier != nil {
+		result.Tier = tier.Name
+	}
+	for _, lot := range live {
+		result.Lots = append(result.Lots, rewardLotResult{
+			Amount:    bigIntToString(lot.Amount),
+			Remaining: bigIntToString(lot.Remaining),
+			AccruedAt: lot.AccruedAt,
+			ExpiresAt: lot.ExpiresAt,
+		})
+	}
+	writeResult(w, req.ID, result)
+}
+
+func parseRedemptionID(id string) ([32]byte, error) {
+	var out [32]byte
+	cleaned := strings.TrimPrefix(strings.TrimSpace(id), "0x")
+	bytes, err := hex.DecodeString(cleaned)
+	if err != nil {
+		return out, err
+	}
+	if len(bytes) != len(out) {
+		return out, fmt.Errorf("id must be %d bytes", len(out))
+	}
+	copy(out[:], bytes)
+	return out, nil
+}
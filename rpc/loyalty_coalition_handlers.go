@@ -0,0 +1,339 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"nhbchain/crypto"
+	"nhbchain/native/loyalty"
+)
+
+type coalitionSpec struct {
+	Caller        string `json:"caller"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Admin         string `json:"admin,omitempty"`
+	Mode          string `json:"mode,omitempty"`
+	Pool          string `json:"pool,omitempty"`
+	PeriodSeconds uint64 `json:"periodSeconds"`
+}
+
+type coalitionMemberParams struct {
+	Caller      string `json:"caller"`
+	CoalitionID string `json:"coalitionId"`
+	ProgramID   string `json:"programId"`
+}
+
+type coalitionQueryParams struct {
+	CoalitionID string `json:"coalitionId"`
+}
+
+type coalitionReportParams struct {
+	CoalitionID string  `json:"coalitionId"`
+	Period      *uint64 `json:"period,omitempty"`
+}
+
+type coalitionResult struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Admin         string   `json:"admin"`
+	Mode          string   `json:"mode"`
+	Pool          string   `json:"pool,omitempty"`
+	PeriodSeconds uint64   `json:"periodSeconds"`
+	Members       []string `json:"members"`
+	Invited       []string `json:"invited"`
+	Active        bool     `json:"active"`
+}
+
+type coalitionMemberReport struct {
+	ProgramID string `json:"programId"`
+	Issued    string `json:"issued"`
+	Redeemed  string `json:"redeemed"`
+	// Net is positive when the member owes the coalition and negative when
+	// it is owed.
+	Net string `json:"net"`
+}
+
+type coalitionTransferReport struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount string `json:"amount"`
+	Paid   string `json:"paid"`
+}
+
+type coalitionReportResult struct {
+	CoalitionID string                    `json:"coalitionId"`
+	Mode        string                    `json:"mode"`
+	Period      uint64                    `json:"period"`
+	PeriodStart uint64                    `json:"periodStart"`
+	PeriodEnd   uint64                    `json:"periodEnd"`
+	Settled     bool                      `json:"settled"`
+	SettledAt   uint64                    `json:"settledAt,omitempty"`
+	Members     []coalitionMemberReport   `json:"members"`
+	Transfers   []coalitionTransferReport `json:"transfers"`
+	Shortfall   string                    `json:"shortfall"`
+}
+
+func (s *Server) handleLoyaltyCreateCoalition(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params coalitionSpec
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	callerAddr, err := decodeBech32(params.Caller)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid caller address", err.Error())
+		return
+	}
+	id, err := parseCoalitionID(params.ID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid id", err.Error())
+		return
+	}
+	coalition := &loyalty.Coalition{
+		ID:            id,
+		Name:          params.Name,
+		Mode:          params.Mode,
+		PeriodSeconds: params.PeriodSeconds,
+	}
+	if strings.TrimSpace(params.Admin) != "" {
+		if coalition.Admin, err = decodeBech32(params.Admin); err != nil {
+			writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid admin address", err.Error())
+			return
+		}
+	}
+	if strings.TrimSpace(params.Pool) != "" {
+		if coalition.Pool, err = decodeBech32(params.Pool); err != nil {
+			writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid pool address", err.Error())
+			return
+		}
+	}
+	if err := s.node.LoyaltyRegistry().CreateCoalition(callerAddr, coalition); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "failed to create coalition", err.Error())
+		return
+	}
+	writeResult(w, req.ID, "ok")
+}
+
+func (s *Server) handleLoyaltyInviteCoalitionMember(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	s.handleCoalitionMembership(w, r, req, "invite", (*loyalty.Registry).InviteCoalitionMember)
+}
+
+func (s *Server) handleLoyaltyJoinCoalition(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	s.handleCoalitionMembership(w, r, req, "join", (*loyalty.Registry).JoinCoalition)
+}
+
+func (s *Server) handleLoyaltyLeaveCoalition(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	s.handleCoalitionMembership(w, r, req, "leave", (*loyalty.Registry).LeaveCoalition)
+}
+
+func (s *Server) handleCoalitionMembership(w http.ResponseWriter, r *http.Request, req *RPCRequest, action string, apply func(*loyalty.Registry, [20]byte, loyalty.CoalitionID, loyalty.ProgramID) error) {
+	if authErr := s.requireAuthInto(&r); authErr != nil {
+		writeError(w, http.StatusUnauthorized, req.ID, authErr.Code, authErr.Message, authErr.Data)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params coalitionMemberParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	callerAddr, err := decodeBech32(params.Caller)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid caller address", err.Error())
+		return
+	}
+	coalitionID, err := parseCoalitionID(params.CoalitionID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid coalitionId", err.Error())
+		return
+	}
+	programID, err := parseProgramID(params.ProgramID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid programId", err.Error())
+		return
+	}
+	if err := apply(s.node.LoyaltyRegistry(), callerAddr, coalitionID, programID); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, fmt.Sprintf("failed to %s coalition", action), err.Error())
+		return
+	}
+	writeResult(w, req.ID, "ok")
+}
+
+func (s *Server) handleLoyaltyGetCoalition(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params coalitionQueryParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	id, err := parseCoalitionID(params.CoalitionID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid coalitionId", err.Error())
+		return
+	}
+	coalition, ok, err := loyalty.NewRegistry(s.node.LoyaltyManager()).Coalition(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load coalition", err.Error())
+		return
+	}
+	if !ok {
+		writeResultAllowNil(w, req.ID, nil)
+		return
+	}
+	writeResult(w, req.ID, formatCoalition(coalition))
+}
+
+func (s *Server) handleLoyaltyCoalitionReport(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params coalitionReportParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid parameter object", err.Error())
+		return
+	}
+	id, err := parseCoalitionID(params.CoalitionID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid coalitionId", err.Error())
+		return
+	}
+	registry := loyalty.NewRegistry(s.node.LoyaltyManager())
+	coalition, ok, err := registry.Coalition(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load coalition", err.Error())
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, req.ID, codeInvalidParams, "coalition not found", params.CoalitionID)
+		return
+	}
+	period := coalition.Period(uint64(time.Now().Unix()))
+	if params.Period != nil {
+		period = *params.Period
+	}
+	report, err := buildCoalitionReport(registry, coalition, period)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load coalition ledger", err.Error())
+		return
+	}
+	writeResult(w, req.ID, report)
+}
+
+// buildCoalitionReport returns the stored settlement of a period, or the
+// projected transfers while the period is unsettled.
+func buildCoalitionReport(registry *loyalty.Registry, coalition *loyalty.Coalition, period uint64) (*coalitionReportResult, error) {
+	result := &coalitionReportResult{
+		CoalitionID: "0x" + hex.EncodeToString(coalition.ID[:]),
+		Mode:        coalition.Mode,
+		Period:      period,
+		PeriodStart: period * coalition.PeriodSeconds,
+		PeriodEnd:   coalition.PeriodEnd(period),
+	}
+	settlement, settled, err := registry.CoalitionSettlement(coalition.ID, period)
+	if err != nil {
+		return nil, err
+	}
+	if !settled {
+		ledger, err := registry.CoalitionLedger(coalition.ID, period)
+		if err != nil {
+			return nil, err
+		}
+		settlement = &loyalty.CoalitionSettlement{
+			CoalitionID: coalition.ID,
+			Period:      period,
+			Entries:     ledger.Entries,
+		}
+		if !coalition.Shared() {
+			settlement.Transfers = loyalty.ComputeSettlement(ledger)
+		}
+	}
+	result.Settled = settled
+	result.SettledAt = settlement.SettledAt
+	net := loyalty.NetPositions(&loyalty.CoalitionLedger{Entries: settlement.Entries})
+	result.Members = make([]coalitionMemberReport, 0, len(settlement.Entries))
+	for i, entry := range settlement.Entries {
+		result.Members = append(result.Members, coalitionMemberReport{
+			ProgramID: formatProgramID(entry.ProgramID),
+			Issued:    bigIntToString(entry.Issued),
+			Redeemed:  bigIntToString(entry.Redeemed),
+			Net:       net[i].String(),
+		})
+	}
+	shortfall := big.NewInt(0)
+	result.Transfers = make([]coalitionTransferReport, 0, len(settlement.Transfers))
+	for _, transfer := range settlement.Transfers {
+		paid := transfer.Paid
+		if paid == nil {
+			paid = big.NewInt(0)
+		}
+		if settled && transfer.Amount != nil {
+			shortfall.Add(shortfall, new(big.Int).Sub(transfer.Amount, paid))
+		}
+		result.Transfers = append(result.Transfers, coalitionTransferReport{
+			From:   formatProgramID(transfer.From),
+			To:     formatProgramID(transfer.To),
+			Amount: bigIntToString(transfer.Amount),
+			Paid:   paid.String(),
+		})
+	}
+	result.Shortfall = shortfall.String()
+	return result, nil
+}
+
+func formatCoalition(c *loyalty.Coalition) coalitionResult {
+	result := coalitionResult{
+		ID:            "0x" + hex.EncodeToString(c.ID[:]),
+		Name:          c.Name,
+		Admin:         crypto.MustNewAddress(crypto.NHBPrefix, c.Admin[:]).String(),
+		Mode:          c.Mode,
+		PeriodSeconds: c.PeriodSeconds,
+		Members:       make([]string, 0, len(c.Members)),
+		Invited:       make([]string, 0, len(c.Invited)),
+		Active:        c.Active,
+	}
+	if c.Pool != ([20]byte{}) {
+		result.Pool = crypto.MustNewAddress(crypto.NHBPrefix, c.Pool[:]).String()
+	}
+	for _, id := range c.Members {
+		result.Members = append(result.Members, formatProgramID(id))
+	}
+	for _, id := range c.Invited {
+		result.Invited = append(result.Invited, formatProgramID(id))
+	}
+	return result
+}
+
+func parseCoalitionID(id string) (loyalty.CoalitionID, error) {
+	var out loyalty.CoalitionID
+	cleaned := strings.TrimPrefix(strings.TrimSpace(id), "0x")
+	bytes, err := hex.DecodeString(cleaned)
+	if err != nil {
+		return out, err
+	}
+	if len(bytes) != len(out) {
+		return out, fmt.Errorf("coalitionId must be %d bytes", len(out))
+	}
+	copy(out[:], bytes)
+	return out, nil
+}
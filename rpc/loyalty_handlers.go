@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"nhbchain/crypto"
 	"nhbchain/native/loyalty"
@@ -51,6 +52,22 @@ type programStatsParams struct {
 	Day       string `json:"day"`
 }
 
+type programCoalitionStats struct {
+	CoalitionID string `json:"coalitionId"`
+	Period      uint64 `json:"period"`
+	Issued      string `json:"issued"`
+	Redeemed    string `json:"redeemed"`
+	Net         string `json:"net"`
+	Settled     bool   `json:"settled"`
+}
+
+type programStatsResult struct {
+	RewardsPaid string                 `json:"rewardsPaid"`
+	TxCount     string                 `json:"txCount"`
+	CapUsage    string                 `json:"capUsage"`
+	Coalition   *programCoalitionStats `json:"coalition,omitempty"`
+}
+
 type userDailyParams struct {
 	User      string `json:"user"`
 	ProgramID string `json:"programId"`
@@ -67,39 +84,51 @@ type userQRParams struct {
 }
 
 type programSpec struct {
-	ID                 string  `json:"id"`
-	Owner              string  `json:"owner"`
-	Pool               string  `json:"pool"`
-	TokenSymbol        string  `json:"tokenSymbol"`
-	AccrualBps         uint32  `json:"accrualBps"`
-	MinSpendWei        *string `json:"minSpendWei,omitempty"`
-	CapPerTx           *string `json:"capPerTx,omitempty"`
-	DailyCapUser       *string `json:"dailyCapUser,omitempty"`
-	DailyCapProgram    *string `json:"dailyCapProgram,omitempty"`
-	EpochCapProgram    *string `json:"epochCapProgram,omitempty"`
-	EpochLengthSeconds *uint64 `json:"epochLengthSeconds,omitempty"`
-	IssuanceCapUser    *string `json:"issuanceCapUser,omitempty"`
-	StartTime          *uint64 `json:"startTime,omitempty"`
-	EndTime            *uint64 `json:"endTime,omitempty"`
-	Active             *bool   `json:"active,omitempty"`
+	ID                 string     `json:"id"`
+	Owner              string     `json:"owner"`
+	Pool               string     `json:"pool"`
+	TokenSymbol        string     `json:"tokenSymbol"`
+	AccrualBps         uint32     `json:"accrualBps"`
+	MinSpendWei        *string    `json:"minSpendWei,omitempty"`
+	CapPerTx           *string    `json:"capPerTx,omitempty"`
+	DailyCapUser       *string    `json:"dailyCapUser,omitempty"`
+	DailyCapProgram    *string    `json:"dailyCapProgram,omitempty"`
+	EpochCapProgram    *string    `json:"epochCapProgram,omitempty"`
+	EpochLengthSeconds *uint64    `json:"epochLengthSeconds,omitempty"`
+	IssuanceCapUser    *string    `json:"issuanceCapUser,omitempty"`
+	StartTime          *uint64    `json:"startTime,omitempty"`
+	EndTime            *uint64    `json:"endTime,omitempty"`
+	Active             *bool      `json:"active,omitempty"`
+	Tiers              []tierSpec `json:"tiers,omitempty"`
+	TierWindowDays     *uint32    `json:"tierWindowDays,omitempty"`
+	RewardExpiryDays   *uint32    `json:"rewardExpiryDays,omitempty"`
+}
+
+type tierSpec struct {
+	Name        string `json:"name"`
+	MinSpendWei string `json:"minSpendWei"`
+	AccrualBps  uint32 `json:"accrualBps"`
 }
 
 type programResult struct {
-	ID                 string `json:"id"`
-	Owner              string `json:"owner"`
-	Pool               string `json:"pool"`
-	TokenSymbol        string `json:"tokenSymbol"`
-	AccrualBps         uint32 `json:"accrualBps"`
-	MinSpendWei        string `json:"minSpendWei"`
-	CapPerTx           string `json:"capPerTx"`
-	DailyCapUser       string `json:"dailyCapUser"`
-	DailyCapProgram    string `json:"dailyCapProgram"`
-	EpochCapProgram    string `json:"epochCapProgram"`
-	EpochLengthSeconds uint64 `json:"epochLengthSeconds"`
-	IssuanceCapUser    string `json:"issuanceCapUser"`
-	StartTime          uint64 `json:"startTime"`
-	EndTime            uint64 `json:"endTime"`
-	Active             bool   `json:"active"`
+	ID                 string     `json:"id"`
+	Owner              string     `json:"owner"`
+	Pool               string     `json:"pool"`
+	TokenSymbol        string     `json:"tokenSymbol"`
+	AccrualBps         uint32     `json:"accrualBps"`
+	MinSpendWei        string     `json:"minSpendWei"`
+	CapPerTx           string     `json:"capPerTx"`
+	DailyCapUser       string     `json:"dailyCapUser"`
+	DailyCapProgram    string     `json:"dailyCapProgram"`
+	EpochCapProgram    string     `json:"epochCapProgram"`
+	EpochLengthSeconds uint64     `json:"epochLengthSeconds"`
+	IssuanceCapUser    string     `json:"issuanceCapUser"`
+	StartTime          uint64     `json:"startTime"`
+	EndTime            uint64     `json:"endTime"`
+	Active             bool       `json:"active"`
+	Tiers              []tierSpec `json:"tiers,omitempty"`
+	TierWindowDays     uint32     `json:"tierWindowDays,omitempty"`
+	RewardExpiryDays   uint32     `json:"rewardExpiryDays,omitempty"`
 }
 
 type businessResult struct {
@@ -559,15 +588,44 @@ func (s *Server) handleLoyaltyProgramStats(w http.ResponseWriter, _ *http.Reques
 		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "day is required", nil)
 		return
 	}
-	if _, err := parseProgramID(params.ProgramID); err != nil {
+	programID, err := parseProgramID(params.ProgramID)
+	if err != nil {
 		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid programId", err.Error())
 		return
 	}
-	writeResult(w, req.ID, map[string]string{
-		"rewardsPaid": "0",
-		"txCount":     "0",
-		"capUsage":    "0",
-	})
+	result := programStatsResult{RewardsPaid: "0", TxCount: "0", CapUsage: "0"}
+	// Coalition members also report their position in the settlement period
+	// containing the requested day.
+	if day, err := time.Parse("2006-01-02", strings.TrimSpace(params.Day)); err == nil {
+		registry := loyalty.NewRegistry(s.node.LoyaltyManager())
+		coalition, ok, err := registry.CoalitionForProgram(programID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load coalition", err.Error())
+			return
+		}
+		if ok {
+			report, err := buildCoalitionReport(registry, coalition, coalition.Period(uint64(day.Unix())))
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load coalition ledger", err.Error())
+				return
+			}
+			stats := &programCoalitionStats{
+				CoalitionID: report.CoalitionID,
+				Period:      report.Period,
+				Issued:      "0",
+				Redeemed:    "0",
+				Net:         "0",
+				Settled:     report.Settled,
+			}
+			for _, member := range report.Members {
+				if member.ProgramID == formatProgramID(programID) {
+					stats.Issued, stats.Redeemed, stats.Net = member.Issued, member.Redeemed, member.Net
+				}
+			}
+			result.Coalition = stats
+		}
+	}
+	writeResult(w, req.ID, result)
 }
 
 func (s *Server) handleLoyaltyUserDaily(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
@@ -777,6 +835,14 @@ func formatBusiness(business *loyalty.Business) businessResult {
 }
 
 func formatProgram(program *loyalty.Program) programResult {
+	var tiers []tierSpec
+	for _, tier := range program.Tiers {
+		tiers = append(tiers, tierSpec{
+			Name:        tier.Name,
+			MinSpendWei: bigIntToString(tier.MinSpendWei),
+			AccrualBps:  tier.AccrualBps,
+		})
+	}
 	return programResult{
 		ID:                 formatProgramID(program.ID),
 		Owner:              crypto.MustNewAddress(crypto.NHBPrefix, program.Owner[:]).String(),
@@ -793,6 +859,9 @@ func formatProgram(program *loyalty.Program) programResult {
 		StartTime:          program.StartTime,
 		EndTime:            program.EndTime,
 		Active:             program.Active,
+		Tiers:              tiers,
+		TierWindowDays:     program.TierWindowDays,
+		RewardExpiryDays:   program.RewardExpiryDays,
 	}
 }
 
@@ -863,6 +932,22 @@ func buildProgramFromSpec(spec *programSpec) (*loyalty.Program, error) {
 	if spec.EndTime != nil {
 		endTime = *spec.EndTime
 	}
+	tiers := make([]loyalty.Tier, 0, len(spec.Tiers))
+	for i, tier := range spec.Tiers {
+		minSpend, err := parseBigInt(&tier.MinSpendWei)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tiers[%d].minSpendWei: %w", i, err)
+		}
+		tiers = append(tiers, loyalty.Tier{Name: tier.Name, MinSpendWei: minSpend, AccrualBps: tier.AccrualBps})
+	}
+	tierWindowDays := uint32(0)
+	if spec.TierWindowDays != nil {
+		tierWindowDays = *spec.TierWindowDays
+	}
+	rewardExpiryDays := uint32(0)
+	if spec.RewardExpiryDays != nil {
+		rewardExpiryDays = *spec.RewardExpiryDays
+	}
 	return &loyalty.Program{
 		ID:                 id,
 		Owner:              owner,
@@ -879,6 +964,9 @@ func buildProgramFromSpec(spec *programSpec) (*loyalty.Program, error) {
 		StartTime:          startTime,
 		EndTime:            endTime,
 		Active:             active,
+		Tiers:              tiers,
+		TierWindowDays:     tierWindowDays,
+		RewardExpiryDays:   rewardExpiryDays,
 	}, nil
 }
 
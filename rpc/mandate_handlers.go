@@ -0,0 +1,165 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/crypto"
+)
+
+// mandateListScope selects which side of a mandate a list query indexes by.
+type mandateListScope int
+
+const (
+	mandateListByPayer mandateListScope = iota
+	mandateListByMerchant
+)
+
+type mandateIDParams struct {
+	ID string `json:"id"`
+}
+
+type mandateAddressParams struct {
+	Address string `json:"address"`
+}
+
+// MandateResult is the JSON view of a recurring payment mandate.
+type MandateResult struct {
+	ID               string `json:"id"`
+	Payer            string `json:"payer"`
+	Merchant         string `json:"merchant"`
+	Asset            string `json:"asset"`
+	MaxAmount        string `json:"maxAmount"`
+	PeriodSeconds    uint64 `json:"periodSeconds"`
+	StartAt          uint64 `json:"startAt"`
+	EndAt            uint64 `json:"endAt,omitempty"`
+	CreatedAt        uint64 `json:"createdAt"`
+	Status           string `json:"status"`
+	CurrentPeriod    uint64 `json:"currentPeriod"`
+	PulledThisPeriod bool   `json:"pulledThisPeriod"`
+	NextPullAt       uint64 `json:"nextPullAt,omitempty"`
+	PulledTotal      string `json:"pulledTotal"`
+	PullCount        uint64 `json:"pullCount"`
+	FailedPulls      uint64 `json:"failedPulls"`
+	LastFailureAt    uint64 `json:"lastFailureAt,omitempty"`
+	CancelledAt      uint64 `json:"cancelledAt,omitempty"`
+}
+
+func (s *Server) handleMandateGet(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if s.node == nil {
+		writeError(w, http.StatusServiceUnavailable, req.ID, codeServerError, "node unavailable", nil)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params mandateIDParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	id, err := parseEscrowID(params.ID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	mandate, err := s.node.GetMandate(id)
+	if err != nil {
+		if errors.Is(err, nhbstate.ErrMandateNotFound) {
+			writeResultAllowNil(w, req.ID, nil)
+			return
+		}
+		slog.Error("rpc: get mandate failed", slog.String("id", params.ID), slog.Any("error", err))
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load mandate", nil)
+		return
+	}
+	writeResult(w, req.ID, buildMandateResult(mandate, time.Now()))
+}
+
+func (s *Server) handleMandateList(w http.ResponseWriter, _ *http.Request, req *RPCRequest, scope mandateListScope) {
+	if s.node == nil {
+		writeError(w, http.StatusServiceUnavailable, req.ID, codeServerError, "node unavailable", nil)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params mandateAddressParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	addr, err := parseBech32Address(params.Address)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	var mandates []*nhbstate.StoredMandate
+	if scope == mandateListByMerchant {
+		mandates, err = s.node.ListMandatesByMerchant(addr)
+	} else {
+		mandates, err = s.node.ListMandatesByPayer(addr)
+	}
+	if err != nil {
+		slog.Error("rpc: list mandates failed", slog.String("address", params.Address), slog.Any("error", err))
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to list mandates", nil)
+		return
+	}
+	now := time.Now()
+	results := make([]MandateResult, 0, len(mandates))
+	for _, mandate := range mandates {
+		results = append(results, buildMandateResult(mandate, now))
+	}
+	writeResult(w, req.ID, results)
+}
+
+// buildMandateResult renders a mandate, reporting active mandates whose end
+// date has passed as "ended" and the start of the next billing period.
+func buildMandateResult(mandate *nhbstate.StoredMandate, now time.Time) MandateResult {
+	ts := uint64(now.Unix())
+	status := mandate.Status
+	active := nhbstate.MandateStatus(status) == nhbstate.MandateStatusActive
+	if active && mandate.Ended(ts) {
+		status = "ended"
+		active = false
+	}
+	result := MandateResult{
+		ID:            formatEscrowID(mandate.ID),
+		Payer:         crypto.MustNewAddress(crypto.NHBPrefix, mandate.Payer[:]).String(),
+		Merchant:      crypto.MustNewAddress(crypto.NHBPrefix, mandate.Merchant[:]).String(),
+		Asset:         mandate.Asset,
+		MaxAmount:     mandate.MaxAmount.String(),
+		PeriodSeconds: mandate.PeriodSeconds,
+		StartAt:       mandate.StartAt,
+		EndAt:         mandate.EndAt,
+		CreatedAt:     mandate.CreatedAt,
+		Status:        status,
+		PulledTotal:   mandate.PulledTotal.String(),
+		PullCount:     mandate.PullCount,
+		FailedPulls:   mandate.FailedPulls,
+		LastFailureAt: mandate.LastFailureAt,
+		CancelledAt:   mandate.CancelledAt,
+	}
+	period, started := mandate.Period(ts)
+	if started {
+		result.CurrentPeriod = period
+		result.PulledThisPeriod = mandate.PulledInPeriod(period)
+	}
+	if active {
+		switch {
+		case !started:
+			result.NextPullAt = mandate.StartAt
+		case result.PulledThisPeriod:
+			result.NextPullAt = mandate.StartAt + (period+1)*mandate.PeriodSeconds
+		default:
+			result.NextPullAt = ts
+		}
+	}
+	return result
+}
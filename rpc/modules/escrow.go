@@ -46,6 +46,12 @@ type EscrowRealmResult struct {
 	UpdatedAt       int64                      `json:"updatedAt"`
 	Arbitrators     *EscrowArbitratorResult    `json:"arbitrators,omitempty"`
 	Metadata        *EscrowRealmMetadataResult `json:"metadata,omitempty"`
+
+	ArbitratorFeeBps      uint32                  `json:"arbitratorFeeBps,omitempty"`
+	EvidenceWindowSeconds int64                   `json:"evidenceWindowSeconds,omitempty"`
+	ArbitrationSLASeconds int64                   `json:"arbitrationSlaSeconds,omitempty"`
+	FallbackArbitrators   *EscrowArbitratorResult `json:"fallbackArbitrators,omitempty"`
+	MinReputationScore    uint32                  `json:"minReputationScore,omitempty"`
 }
 
 // EscrowArbitratorResult captures the resolved arbitrator policy for a realm.
@@ -82,6 +88,17 @@ type EscrowSnapshotResult struct {
 	Realm          *string             `json:"realm,omitempty"`
 	FrozenPolicy   *FrozenPolicyResult `json:"frozenPolicy,omitempty"`
 	ResolutionHash *string             `json:"resolutionHash,omitempty"`
+	DisputedAt     int64               `json:"disputedAt,omitempty"`
+	EscalatedAt    int64               `json:"escalatedAt,omitempty"`
+	Evidence       []EvidenceResult    `json:"evidence,omitempty"`
+}
+
+// EvidenceResult describes one evidence entry attached to a disputed escrow.
+type EvidenceResult struct {
+	Submitter   string `json:"submitter"`
+	Hash        string `json:"hash"`
+	URI         string `json:"uri,omitempty"`
+	SubmittedAt int64  `json:"submittedAt"`
 }
 
 // FrozenPolicyResult exposes the immutable arbitrator policy captured at
@@ -95,6 +112,11 @@ type FrozenPolicyResult struct {
 	Members      []string                   `json:"members,omitempty"`
 	FrozenAt     int64                      `json:"frozenAt,omitempty"`
 	Metadata     *EscrowRealmMetadataResult `json:"metadata,omitempty"`
+
+	ArbitratorFeeBps      uint32                  `json:"arbitratorFeeBps,omitempty"`
+	EvidenceWindowSeconds int64                   `json:"evidenceWindowSeconds,omitempty"`
+	ArbitrationSLASeconds int64                   `json:"arbitrationSlaSeconds,omitempty"`
+	Fallback              *EscrowArbitratorResult `json:"fallback,omitempty"`
 }
 
 // EscrowEventResult represents an emitted escrow-related event.
@@ -224,19 +246,31 @@ func formatRealmResult(realm *escrow.EscrowRealm) EscrowRealmResult {
 		CreatedAt:       realm.CreatedAt,
 		UpdatedAt:       realm.UpdatedAt,
 	}
-	if realm.Arbitrators != nil {
-		result.Arbitrators = &EscrowArbitratorResult{
-			Scheme:    formatScheme(realm.Arbitrators.Scheme),
-			Threshold: realm.Arbitrators.Threshold,
-			Members:   formatAddressList(realm.Arbitrators.Members),
-		}
-	}
+	result.Arbitrators = formatArbitratorSet(realm.Arbitrators)
 	if realm.Metadata != nil {
 		result.Metadata = formatRealmMetadata(realm.Metadata)
 	}
+	if realm.FeeSchedule != nil {
+		result.ArbitratorFeeBps = realm.FeeSchedule.ArbitratorFeeBps
+	}
+	result.EvidenceWindowSeconds = realm.EvidenceWindowSeconds
+	result.ArbitrationSLASeconds = realm.ArbitrationSLASeconds
+	result.FallbackArbitrators = formatArbitratorSet(realm.FallbackArbitrators)
+	result.MinReputationScore = realm.MinReputationScore
 	return result
 }
 
+func formatArbitratorSet(set *escrow.ArbitratorSet) *EscrowArbitratorResult {
+	if set == nil {
+		return nil
+	}
+	return &EscrowArbitratorResult{
+		Scheme:    formatScheme(set.Scheme),
+		Threshold: set.Threshold,
+		Members:   formatAddressList(set.Members),
+	}
+}
+
 func formatSnapshotResult(esc *escrow.Escrow) *EscrowSnapshotResult {
 	if esc == nil {
 		return nil
@@ -280,8 +314,25 @@ func formatSnapshotResult(esc *escrow.Escrow) *EscrowSnapshotResult {
 			Members:      formatAddressList(esc.FrozenArb.Members),
 			FrozenAt:     esc.FrozenArb.FrozenAt,
 			Metadata:     formatRealmMetadata(esc.FrozenArb.Metadata),
+
+			EvidenceWindowSeconds: esc.FrozenArb.EvidenceWindowSeconds,
+			ArbitrationSLASeconds: esc.FrozenArb.ArbitrationSLASeconds,
+			Fallback:              formatArbitratorSet(esc.FrozenArb.Fallback),
+		}
+		if esc.FrozenArb.FeeSchedule != nil {
+			result.FrozenPolicy.ArbitratorFeeBps = esc.FrozenArb.FeeSchedule.ArbitratorFeeBps
 		}
 	}
+	result.DisputedAt = esc.DisputedAt
+	result.EscalatedAt = esc.EscalatedAt
+	for _, item := range esc.Evidence {
+		result.Evidence = append(result.Evidence, EvidenceResult{
+			Submitter:   crypto.MustNewAddress(crypto.NHBPrefix, item.Submitter[:]).String(),
+			Hash:        "0x" + hex.EncodeToString(item.Hash[:]),
+			URI:         item.URI,
+			SubmittedAt: item.SubmittedAt,
+		})
+	}
 	if esc.ResolutionHash != ([32]byte{}) {
 		hash := "0x" + hex.EncodeToString(esc.ResolutionHash[:])
 		result.ResolutionHash = &hash
@@ -376,6 +427,8 @@ func escrowStatusString(status escrow.EscrowStatus) string {
 		return "expired"
 	case escrow.EscrowDisputed:
 		return "disputed"
+	case escrow.EscrowSplit:
+		return "split"
 	default:
 		return "unknown"
 	}
@@ -32,6 +32,17 @@ var lendingRay = mustBigInt("1000000000000000000000000000")
 
 type lendingReplayPayload struct {
 	PoolID string `json:"poolId,omitempty"`
+	Asset  string `json:"asset,omitempty"`
+}
+
+// LendingPoolAssets configures the borrow asset and accepted collateral of a
+// new pool. The zero value creates an NHB pool backed by ZNHB under the
+// node's risk parameters.
+type LendingPoolAssets struct {
+	BorrowAsset         string
+	BorrowOracleFeed    string
+	OracleMaxAgeSeconds uint64
+	Collateral          []lending.CollateralAsset
 }
 
 func (m *LendingModule) moduleUnavailable() *ModuleError {
@@ -172,7 +183,7 @@ func (m *LendingModule) defaultMarket(poolID string) *lending.Market {
 	}
 }
 
-func (m *LendingModule) CreatePool(poolID string, owner [20]byte) (*lending.Market, *ModuleError) {
+func (m *LendingModule) CreatePool(poolID string, owner [20]byte, assets LendingPoolAssets) (*lending.Market, *ModuleError) {
 	if m == nil || m.node == nil {
 		return nil, m.moduleUnavailable()
 	}
@@ -181,6 +192,20 @@ func (m *LendingModule) CreatePool(poolID string, owner [20]byte) (*lending.Mark
 		return nil, &ModuleError{HTTPStatus: http.StatusBadRequest, Code: codeInvalidParams, Message: "poolId required"}
 	}
 	ownerAddr := toCryptoAddress(owner)
+	template := &lending.Market{
+		BorrowAsset:         lending.NormalizeAsset(assets.BorrowAsset),
+		BorrowOracleFeed:    strings.TrimSpace(assets.BorrowOracleFeed),
+		OracleMaxAgeSeconds: assets.OracleMaxAgeSeconds,
+	}
+	for _, cfg := range assets.Collateral {
+		cfg = cfg.Clone()
+		cfg.Asset = lending.NormalizeAsset(cfg.Asset)
+		cfg.OracleFeed = strings.TrimSpace(cfg.OracleFeed)
+		template.Collateral = append(template.Collateral, cfg)
+	}
+	if err := lending.ValidateMarketAssets(template); err != nil {
+		return nil, &ModuleError{HTTPStatus: http.StatusBadRequest, Code: codeInvalidParams, Message: err.Error()}
+	}
 	var created *lending.Market
 	err := m.node.WithState(func(manager *nhbstate.Manager) error {
 		existing, ok, err := manager.LendingGetMarket(id)
@@ -198,6 +223,10 @@ func (m *LendingModule) CreatePool(poolID string, owner [20]byte) (*lending.Mark
 			DeveloperFeeCollector: collector,
 			ReserveFactor:         m.node.LendingReserveFactorBps(),
 			LastUpdateBlock:       m.node.GetHeight(),
+			BorrowAsset:           template.BorrowAsset,
+			BorrowOracleFeed:      template.BorrowOracleFeed,
+			OracleMaxAgeSeconds:   template.OracleMaxAgeSeconds,
+			Collateral:            template.Collateral,
 			TotalNHBSupplied:      big.NewInt(0),
 			TotalSupplyShares:     big.NewInt(0),
 			TotalNHBBorrowed:      big.NewInt(0),
@@ -352,11 +381,11 @@ func (m *LendingModule) replayCommittedPoolState(poolID string) (*lending.Market
 			user := users[addr]
 			if user == nil {
 				user = &lending.UserAccount{
-					Address:        toCryptoAddress(addr),
-					CollateralZNHB: big.NewInt(0),
-					SupplyShares:   big.NewInt(0),
-					DebtNHB:        big.NewInt(0),
-					ScaledDebt:     big.NewInt(0),
+					Address:      toCryptoAddress(addr),
+					Collateral:   make(map[string]*big.Int),
+					SupplyShares: big.NewInt(0),
+					DebtNHB:      big.NewInt(0),
+					ScaledDebt:   big.NewInt(0),
 				}
 				users[addr] = user
 			}
@@ -383,12 +412,14 @@ func (m *LendingModule) replayCommittedPoolState(poolID string) (*lending.Market
 					market.TotalNHBSupplied = new(big.Int).Sub(market.TotalNHBSupplied, amount)
 				}
 			case types.TxTypeLendingDepositZNHB:
-				user.CollateralZNHB = sumBigInt(user.CollateralZNHB, amount)
+				asset := lendingCollateralAssetFromTxData(tx.Data)
+				user.SetCollateral(asset, sumBigInt(user.CollateralOf(asset), amount))
 			case types.TxTypeLendingWithdrawZNHB:
-				if user.CollateralZNHB.Cmp(amount) < 0 {
-					user.CollateralZNHB = big.NewInt(0)
+				asset := lendingCollateralAssetFromTxData(tx.Data)
+				if pledged := user.CollateralOf(asset); pledged.Cmp(amount) < 0 {
+					user.SetCollateral(asset, nil)
 				} else {
-					user.CollateralZNHB = new(big.Int).Sub(user.CollateralZNHB, amount)
+					user.SetCollateral(asset, new(big.Int).Sub(pledged, amount))
 				}
 			case types.TxTypeLendingBorrowNHB:
 				user.DebtNHB = sumBigInt(user.DebtNHB, amount)
@@ -452,6 +483,19 @@ func lendingPoolIDFromTxData(data []byte) (string, error) {
 	return id, nil
 }
 
+// lendingCollateralAssetFromTxData returns the collateral asset named by a
+// collateral transaction, defaulting to ZNHB for payloads that predate
+// multi-asset markets.
+func lendingCollateralAssetFromTxData(data []byte) string {
+	var payload lendingReplayPayload
+	if len(data) > 0 && json.Unmarshal(data, &payload) == nil {
+		if asset := lending.NormalizeAsset(payload.Asset); asset != "" {
+			return asset
+		}
+	}
+	return lending.AssetZNHB
+}
+
 // reconcileLegacyPoolStateInto mirrors rebuildPoolStateInto: it performs the
 // same legacy-account migration as reconcileLegacyUserAccount, scaled across
 // every account, but writes through the caller-supplied manager instead of
@@ -547,11 +591,11 @@ func (m *LendingModule) legacyLendingPosition(addr [20]byte, account *types.Acco
 	supplyIndex := normalizedLendingIndex(account.LendingSnapshot.SupplyIndex)
 	borrowIndex := normalizedLendingIndex(account.LendingSnapshot.BorrowIndex)
 	user := &lending.UserAccount{
-		Address:        toCryptoAddress(addr),
-		CollateralZNHB: collateral,
-		SupplyShares:   supplyShares,
-		DebtNHB:        debt,
-		ScaledDebt:     scaledDebtFromAmountLegacy(debt, borrowIndex),
+		Address:      toCryptoAddress(addr),
+		Collateral:   map[string]*big.Int{lending.AssetZNHB: collateral},
+		SupplyShares: supplyShares,
+		DebtNHB:      debt,
+		ScaledDebt:   scaledDebtFromAmountLegacy(debt, borrowIndex),
 	}
 	return user, liquidityFromSharesLegacy(supplyShares, supplyIndex), debt, true
 }
@@ -663,7 +707,7 @@ func (m *LendingModule) DepositZNHB(poolID string, addr [20]byte, amount *big.In
 		return "", m.moduleUnavailable()
 	}
 	err := m.withEngine(poolID, func(engine *lending.Engine, _ *lending.Market) error {
-		return engine.DepositCollateral(toCryptoAddress(addr), amount)
+		return engine.DepositCollateral(toCryptoAddress(addr), lending.AssetZNHB, amount)
 	})
 	if err != nil {
 		return "", m.wrapError(err)
@@ -676,7 +720,7 @@ func (m *LendingModule) WithdrawZNHB(poolID string, addr [20]byte, amount *big.I
 		return "", m.moduleUnavailable()
 	}
 	err := m.withEngine(poolID, func(engine *lending.Engine, _ *lending.Market) error {
-		return engine.WithdrawCollateral(toCryptoAddress(addr), amount)
+		return engine.WithdrawCollateral(toCryptoAddress(addr), lending.AssetZNHB, amount)
 	})
 	if err != nil {
 		return "", m.wrapError(err)
@@ -762,13 +806,13 @@ func (m *LendingModule) RepayNHB(poolID string, addr [20]byte, amount *big.Int)
 	return m.makeTxHash("repay", formatHexAddress(addr), amount, repaid), nil
 }
 
-func (m *LendingModule) Liquidate(poolID string, liquidator [20]byte, borrower [20]byte) (string, *ModuleError) {
+func (m *LendingModule) Liquidate(poolID string, liquidator [20]byte, borrower [20]byte, collateralAsset string, repayAmount *big.Int) (string, *ModuleError) {
 	if m == nil || m.node == nil {
 		return "", m.moduleUnavailable()
 	}
 	var repaid, seized *big.Int
 	err := m.withEngine(poolID, func(engine *lending.Engine, _ *lending.Market) error {
-		debt, collateral, err := engine.Liquidate(toCryptoAddress(liquidator), toCryptoAddress(borrower))
+		debt, collateral, err := engine.Liquidate(toCryptoAddress(liquidator), toCryptoAddress(borrower), collateralAsset, repayAmount)
 		if err != nil {
 			return err
 		}
@@ -802,6 +846,7 @@ func (m *LendingModule) withEngine(poolID string, fn func(*lending.Engine, *lend
 		engine.SetProtocolFeeBps(m.node.LendingProtocolFeeBps())
 		engine.SetBlockHeight(m.node.GetHeight())
 		engine.SetCollateralRouting(m.node.LendingCollateralRouting())
+		engine.SetPriceSource(manager.LendingPriceSource(time.Now()))
 		var market *lending.Market
 		stored, ok, err := manager.LendingGetMarket(id)
 		if err != nil {
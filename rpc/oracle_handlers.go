@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"nhbchain/services/swapd/stable"
 )
 
 func (s *Server) handleGetOraclePrice(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
@@ -20,14 +22,15 @@ func (s *Server) handleGetOraclePrice(w http.ResponseWriter, _ *http.Request, re
 		return
 	}
 
-	rate, observedAt, stale, ok := engine.CurrentPrice(currency, asset)
-	if !ok || rate <= 0 {
+	rateUnits, observedAt, stale, ok := engine.CurrentPrice(currency, asset)
+	if !ok || rateUnits <= 0 {
 		writeError(w, http.StatusServiceUnavailable, req.ID, codeServerError, "oracle unavailable", "price unavailable")
 		return
 	}
 	if observedAt.IsZero() {
 		observedAt = time.Now().UTC()
 	}
+	rate := json.Number(stable.FormatRate(rateUnits))
 
 	writeResult(w, req.ID, map[string]any{
 		"asset":      asset,
@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"encoding/json"
+	"math/big"
 	"net/http/httptest"
 	"testing"
 	"time"
@@ -24,7 +25,7 @@ func TestHandleGetOraclePriceReportsStaleness(t *testing.T) {
 	engine.SetPriceMaxAge(time.Minute)
 	// Record a quote that is already older than the freshness window so the
 	// refresh loop is effectively "frozen" (the bug being fixed).
-	engine.RecordPrice("USD", "ZNHB", 0.05, now.Add(-2*time.Minute))
+	engine.RecordPrice("USD", "ZNHB", big.NewRat(5, 100), now.Add(-2*time.Minute))
 	env.server.ConfigureStableEngine(engine, stable.Limits{}, assets, func() time.Time { return now })
 
 	req := &RPCRequest{ID: 1, Params: []json.RawMessage{marshalParam(t, "ZNHB")}}
@@ -49,7 +50,7 @@ func TestHandleGetOraclePriceReportsStaleness(t *testing.T) {
 	}
 
 	// A fresh observation should report stale=false.
-	engine.RecordPrice("USD", "ZNHB", 0.06, now)
+	engine.RecordPrice("USD", "ZNHB", big.NewRat(6, 100), now)
 	recorder2 := httptest.NewRecorder()
 	env.server.handleGetOraclePrice(recorder2, env.newRequest(), req)
 	raw2, rpcErr2 := decodeRPCResponse(t, recorder2)
@@ -49,9 +49,13 @@ type p2pDisputeParams struct {
 }
 
 type p2pResolveParams struct {
-	ID      string `json:"tradeId"`
-	Caller  string `json:"caller"`
-	Outcome string `json:"outcome"`
+	ID            string `json:"tradeId"`
+	Caller        string `json:"caller"`
+	Outcome       string `json:"outcome"`
+	BasePayeeBps  uint32 `json:"basePayeeBps,omitempty"`
+	BasePayerBps  uint32 `json:"basePayerBps,omitempty"`
+	QuotePayeeBps uint32 `json:"quotePayeeBps,omitempty"`
+	QuotePayerBps uint32 `json:"quotePayerBps,omitempty"`
 }
 
 type p2pCreateResult struct {
@@ -91,6 +95,7 @@ var validP2PResolveOutcomes = map[string]struct{}{
 	"refund_both":               {},
 	"release_base_refund_quote": {},
 	"release_quote_refund_base": {},
+	"split":                     {},
 }
 
 func (s *Server) handleP2PCreateTrade(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
@@ -305,6 +310,22 @@ func (s *Server) handleP2PResolve(w http.ResponseWriter, r *http.Request, req *R
 		writeError(w, http.StatusBadRequest, req.ID, codeP2PInvalidParams, "invalid_params", "invalid outcome")
 		return
 	}
+	if outcome == "split" {
+		base := escrow.SplitDecision{PayeeBps: params.BasePayeeBps, PayerBps: params.BasePayerBps}
+		quote := escrow.SplitDecision{PayeeBps: params.QuotePayeeBps, PayerBps: params.QuotePayerBps}
+		for _, split := range []escrow.SplitDecision{base, quote} {
+			if err := split.Validate(); err != nil {
+				writeError(w, http.StatusBadRequest, req.ID, codeP2PInvalidParams, "invalid_params", err.Error())
+				return
+			}
+		}
+		if err := s.node.P2PResolveSplit(id, caller, base, quote); err != nil {
+			writeP2PError(w, req.ID, err)
+			return
+		}
+		writeResult(w, req.ID, map[string]bool{"ok": true})
+		return
+	}
 	if err := s.node.P2PResolve(id, caller, outcome); err != nil {
 		writeP2PError(w, req.ID, err)
 		return
@@ -136,6 +136,28 @@ func TestP2PResolveInvalidOutcome(t *testing.T) {
 	}
 }
 
+func TestP2PResolveSplitRequiresFullShares(t *testing.T) {
+	env := newTestEnv(t)
+	payload := map[string]interface{}{
+		"tradeId":       "0x" + strings.Repeat("0", 64),
+		"caller":        "nhb1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq9uq0",
+		"outcome":       "split",
+		"basePayeeBps":  6000,
+		"basePayerBps":  4000,
+		"quotePayeeBps": 5000,
+	}
+	req := &RPCRequest{ID: 6, Params: []json.RawMessage{marshalParam(t, payload)}}
+	rec := httptest.NewRecorder()
+	env.server.handleP2PResolve(rec, env.newRequest(), req)
+	_, rpcErr := decodeRPCResponse(t, rec)
+	if rpcErr == nil {
+		t.Fatalf("expected error")
+	}
+	if rpcErr.Code != codeP2PInvalidParams {
+		t.Fatalf("expected code %d got %d", codeP2PInvalidParams, rpcErr.Code)
+	}
+}
+
 func TestP2PCreateAndGetTrade(t *testing.T) {
 	env := newTestEnv(t)
 	buyerKey, _ := crypto.GeneratePrivateKey()
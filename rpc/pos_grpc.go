@@ -89,6 +89,20 @@ func (s *posServer) VoidPayment(ctx context.Context, req *posv1.MsgVoidPayment)
 	return &posv1.MsgVoidPaymentResponse{AuthorizationId: req.AuthorizationId}, nil
 }
 
+func (s *posServer) IncrementAuthorization(ctx context.Context, req *posv1.MsgIncrementAuthorization) (*posv1.MsgIncrementAuthorizationResponse, error) {
+	if _, err := s.submitPayload(req); err != nil {
+		return nil, err
+	}
+	return &posv1.MsgIncrementAuthorizationResponse{AuthorizationId: req.AuthorizationId}, nil
+}
+
+func (s *posServer) RefundPayment(ctx context.Context, req *posv1.MsgRefundPayment) (*posv1.MsgRefundPaymentResponse, error) {
+	if _, err := s.submitPayload(req); err != nil {
+		return nil, err
+	}
+	return &posv1.MsgRefundPaymentResponse{AuthorizationId: req.AuthorizationId}, nil
+}
+
 func (s *posServer) RegisterMerchant(ctx context.Context, req *posv1.MsgRegisterMerchant) (*posv1.MsgRegisterMerchantResponse, error) {
 	hash, err := s.submitPayload(req)
 	if err != nil {
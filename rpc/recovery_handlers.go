@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/crypto"
+)
+
+type recoveryStatusParams struct {
+	Address string `json:"address"`
+}
+
+// RecoveryStatusResult is the JSON view of an account's guardian set and any
+// pending key rotation.
+type RecoveryStatusResult struct {
+	Address      string                 `json:"address"`
+	Guardians    []string               `json:"guardians"`
+	Threshold    uint32                 `json:"threshold"`
+	DelaySeconds uint64                 `json:"delaySeconds"`
+	Pending      *RecoveryPendingResult `json:"pending,omitempty"`
+}
+
+// RecoveryPendingResult describes a rotation awaiting approvals or its delay.
+// NewAddress and Approvals describe the address that reached the threshold,
+// if any; Candidates lists every address guardians currently approve.
+type RecoveryPendingResult struct {
+	NewAddress   string                    `json:"newAddress,omitempty"`
+	Approvals    []string                  `json:"approvals"`
+	Candidates   []RecoveryCandidateResult `json:"candidates"`
+	CreatedAt    uint64                    `json:"createdAt"`
+	ExecutableAt uint64                    `json:"executableAt,omitempty"`
+}
+
+// RecoveryCandidateResult is a proposed new address and the guardians
+// approving it.
+type RecoveryCandidateResult struct {
+	NewAddress string   `json:"newAddress"`
+	Approvals  []string `json:"approvals"`
+}
+
+func (s *Server) handleRecoveryGetStatus(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if s.node == nil {
+		writeError(w, http.StatusServiceUnavailable, req.ID, codeServerError, "node unavailable", nil)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params recoveryStatusParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	addr, err := parseBech32Address(params.Address)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	cfg, pending, err := s.node.RecoveryStatus(addr)
+	if err != nil {
+		slog.Error("rpc: recovery status failed", slog.String("address", params.Address), slog.Any("error", err))
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to load recovery status", nil)
+		return
+	}
+	if cfg == nil && pending == nil {
+		writeResultAllowNil(w, req.ID, nil)
+		return
+	}
+	writeResult(w, req.ID, buildRecoveryStatusResult(addr, cfg, pending))
+}
+
+func buildRecoveryStatusResult(addr [20]byte, cfg *nhbstate.RecoveryConfig, pending *nhbstate.RecoveryRequest) RecoveryStatusResult {
+	result := RecoveryStatusResult{
+		Address:   crypto.MustNewAddress(crypto.NHBPrefix, addr[:]).String(),
+		Guardians: []string{},
+	}
+	if cfg != nil {
+		result.Guardians = formatRecoveryAddresses(cfg.Guardians)
+		result.Threshold = cfg.Threshold
+		result.DelaySeconds = cfg.DelaySeconds
+	}
+	if pending != nil {
+		result.Pending = &RecoveryPendingResult{
+			Approvals:    []string{},
+			Candidates:   []RecoveryCandidateResult{},
+			CreatedAt:    pending.CreatedAt,
+			ExecutableAt: pending.ExecutableAt,
+		}
+		if pending.NewAddress != ([20]byte{}) {
+			result.Pending.NewAddress = crypto.MustNewAddress(crypto.NHBPrefix, pending.NewAddress[:]).String()
+			result.Pending.Approvals = formatRecoveryAddresses(pending.Approvals(pending.NewAddress))
+		}
+		for _, candidate := range pending.Candidates() {
+			result.Pending.Candidates = append(result.Pending.Candidates, RecoveryCandidateResult{
+				NewAddress: crypto.MustNewAddress(crypto.NHBPrefix, candidate[:]).String(),
+				Approvals:  formatRecoveryAddresses(pending.Approvals(candidate)),
+			})
+		}
+	}
+	return result
+}
+
+func formatRecoveryAddresses(addrs [][20]byte) []string {
+	out := make([]string, len(addrs))
+	for i, addr := range addrs {
+		out[i] = crypto.MustNewAddress(crypto.NHBPrefix, addr[:]).String()
+	}
+	return out
+}
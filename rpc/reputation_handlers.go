@@ -66,6 +66,83 @@ func (s *Server) handleReputationVerifySkill(w http.ResponseWriter, r *http.Requ
 	writeResult(w, req.ID, formatReputationVerificationJSON(verification))
 }
 
+type reputationScoreParams struct {
+	Address string `json:"address"`
+}
+
+type reputationComponentsJSON struct {
+	Attestations uint32 `json:"attestations"`
+	History      uint32 `json:"history"`
+	Arbitration  uint32 `json:"arbitration"`
+	Tenure       uint32 `json:"tenure"`
+}
+
+type reputationScoreJSON struct {
+	Address           string                   `json:"address"`
+	Score             uint32                   `json:"score"`
+	MaxScore          uint32                   `json:"maxScore"`
+	Components        reputationComponentsJSON `json:"components"`
+	Attestations      int                      `json:"attestations"`
+	EscrowsCompleted  uint64                   `json:"escrowsCompleted"`
+	EscrowsDisputed   uint64                   `json:"escrowsDisputed"`
+	TradesCompleted   uint64                   `json:"tradesCompleted"`
+	TradesDisputed    uint64                   `json:"tradesDisputed"`
+	ArbitrationWins   uint64                   `json:"arbitrationWins"`
+	ArbitrationLosses uint64                   `json:"arbitrationLosses"`
+	FirstSeen         int64                    `json:"firstSeen,omitempty"`
+	UpdatedAt         int64                    `json:"updatedAt,omitempty"`
+}
+
+func (s *Server) handleReputationGetScore(w http.ResponseWriter, r *http.Request, req *RPCRequest) {
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", "exactly one parameter object expected")
+		return
+	}
+	var params reputationScoreParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	addr, err := parseBech32Address(params.Address)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	agg, score, err := s.node.ReputationScore(addr)
+	if err != nil {
+		writeReputationError(w, req.ID, err)
+		return
+	}
+	writeResult(w, req.ID, formatReputationScoreJSON(addr, agg, score))
+}
+
+func formatReputationScoreJSON(addr [20]byte, agg *reputation.Aggregate, score reputation.ScoreBreakdown) reputationScoreJSON {
+	result := reputationScoreJSON{
+		Address:  formatAddress(addr),
+		Score:    score.Total,
+		MaxScore: reputation.MaxScore,
+		Components: reputationComponentsJSON{
+			Attestations: score.Attestations,
+			History:      score.History,
+			Arbitration:  score.Arbitration,
+			Tenure:       score.Tenure,
+		},
+	}
+	if agg == nil {
+		return result
+	}
+	result.Attestations = len(agg.Attestations)
+	result.EscrowsCompleted = agg.EscrowsCompleted
+	result.EscrowsDisputed = agg.EscrowsDisputed
+	result.TradesCompleted = agg.TradesCompleted
+	result.TradesDisputed = agg.TradesDisputed
+	result.ArbitrationWins = agg.ArbitrationWins
+	result.ArbitrationLosses = agg.ArbitrationLosses
+	result.FirstSeen = agg.FirstSeen
+	result.UpdatedAt = agg.UpdatedAt
+	return result
+}
+
 func formatReputationVerificationJSON(v *reputation.SkillVerification) reputationVerificationJSON {
 	if v == nil {
 		return reputationVerificationJSON{}
@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	nhbstate "nhbchain/core/state"
+	"nhbchain/crypto"
+)
+
+type sessionKeysParams struct {
+	Account string `json:"account"`
+}
+
+// SessionKeyResult is the JSON view of a session key and today's spend.
+type SessionKeyResult struct {
+	Key            string   `json:"key"`
+	Account        string   `json:"account"`
+	TxTypes        []string `json:"txTypes"`
+	DailyLimitNHB  string   `json:"dailyLimitNHB"`
+	DailyLimitZNHB string   `json:"dailyLimitZNHB"`
+	SpentTodayNHB  string   `json:"spentTodayNHB"`
+	SpentTodayZNHB string   `json:"spentTodayZNHB"`
+	Counterparties []string `json:"counterparties"`
+	ExpiresAt      uint64   `json:"expiresAt"`
+	CreatedAt      uint64   `json:"createdAt"`
+	Expired        bool     `json:"expired"`
+}
+
+func (s *Server) handleSessionListKeys(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
+	if s.node == nil {
+		writeError(w, http.StatusServiceUnavailable, req.ID, codeServerError, "node unavailable", nil)
+		return
+	}
+	if len(req.Params) != 1 {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "exactly one parameter object expected", nil)
+		return
+	}
+	var params sessionKeysParams
+	if err := json.Unmarshal(req.Params[0], &params); err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	account, err := parseBech32Address(params.Account)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid_params", err.Error())
+		return
+	}
+	keys, err := s.node.SessionKeys(account)
+	if err != nil {
+		slog.Error("rpc: list session keys failed", slog.String("account", params.Account), slog.Any("error", err))
+		writeError(w, http.StatusInternalServerError, req.ID, codeServerError, "failed to list session keys", nil)
+		return
+	}
+	now := time.Now().UTC()
+	results := make([]SessionKeyResult, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, buildSessionKeyResult(key, now))
+	}
+	writeResult(w, req.ID, results)
+}
+
+func buildSessionKeyResult(key *nhbstate.SessionKey, now time.Time) SessionKeyResult {
+	day := now.Format("2006-01-02")
+	txTypes := make([]string, len(key.TxTypes))
+	for i, t := range key.TxTypes {
+		txTypes[i] = fmt.Sprintf("0x%02x", t)
+	}
+	counterparties := make([]string, len(key.Counterparties))
+	for i, addr := range key.Counterparties {
+		counterparties[i] = crypto.MustNewAddress(crypto.NHBPrefix, addr[:]).String()
+	}
+	return SessionKeyResult{
+		Key:            crypto.MustNewAddress(crypto.NHBPrefix, key.Key[:]).String(),
+		Account:        crypto.MustNewAddress(crypto.NHBPrefix, key.Account[:]).String(),
+		TxTypes:        txTypes,
+		DailyLimitNHB:  key.DailyLimit("NHB").String(),
+		DailyLimitZNHB: key.DailyLimit("ZNHB").String(),
+		SpentTodayNHB:  key.Spent("NHB", day).String(),
+		SpentTodayZNHB: key.Spent("ZNHB", day).String(),
+		Counterparties: counterparties,
+		ExpiresAt:      key.ExpiresAt,
+		CreatedAt:      key.CreatedAt,
+		Expired:        uint64(now.Unix()) >= key.ExpiresAt,
+	}
+}
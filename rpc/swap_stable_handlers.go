@@ -60,9 +60,9 @@ func (s *Server) handleStableRequestSwapApproval(w http.ResponseWriter, r *http.
 			"getAsset":    getAsset,
 			"amountIn":    payload.AmountIn,
 			"amountOut":   amountOutWei,
-			"payAssetUsd": payRate,
-			"getAssetUsd": getRate,
-			"price":       getRate,
+			"payAssetUsd": json.Number(stable.FormatRate(payRate)),
+			"getAssetUsd": json.Number(stable.FormatRate(getRate)),
+			"price":       json.Number(stable.FormatRate(getRate)),
 			"expiresAt":   time.Now().UTC().Add(30 * time.Second).Format(time.RFC3339),
 		}
 		if traceID != "" {
@@ -111,21 +111,26 @@ func (s *Server) handleStableSwapMint(w http.ResponseWriter, r *http.Request, re
 		return
 	}
 	var payload struct {
-		QuoteID  string  `json:"quoteId"`
-		AmountIn float64 `json:"amountIn"`
-		Account  string  `json:"account"`
+		QuoteID  string          `json:"quoteId"`
+		AmountIn json.RawMessage `json:"amountIn"`
+		Account  string          `json:"account"`
 	}
 	if err := json.Unmarshal(req.Params[0], &payload); err != nil {
 		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid payload", err.Error())
 		return
 	}
+	amountIn, err := parseFlexibleAmount(payload.AmountIn)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "invalid amountIn", err.Error())
+		return
+	}
 	quoteID := strings.TrimSpace(payload.QuoteID)
 	account := strings.TrimSpace(payload.Account)
-	if quoteID == "" || account == "" || payload.AmountIn <= 0 {
+	if quoteID == "" || account == "" || amountIn <= 0 {
 		writeError(w, http.StatusBadRequest, req.ID, codeInvalidParams, "quoteId, account, and positive amountIn required", nil)
 		return
 	}
-	reservation, err := engine.Reserve(r.Context(), stable.ReserveRequest{QuoteID: quoteID, Account: account, AmountIn: payload.AmountIn})
+	reservation, err := engine.Reserve(r.Context(), stable.ReserveRequest{QuoteID: quoteID, Account: account, AmountIn: amountIn})
 	if err != nil {
 		s.writeStableRPCError(w, req.ID, err)
 		return
@@ -175,7 +180,7 @@ func (s *Server) handleStableSwapBurn(w http.ResponseWriter, r *http.Request, re
 	response := map[string]any{
 		"intentId":      intent.Intent.ID,
 		"reservationId": intent.Intent.ReservationID,
-		"amount":        intent.Intent.Amount,
+		"amount":        json.Number(stable.FormatAmount(intent.Intent.AmountUnits)),
 		"createdAt":     intent.Intent.CreatedAt.UTC().Format(time.RFC3339),
 	}
 	if traceID != "" {
@@ -253,44 +258,32 @@ func quoteCrossAsset(engine *stable.Engine, payAsset string, getAsset string, am
 	if _, ok := amountIn.SetString(strings.TrimSpace(amountInWei), 10); !ok || amountIn.Sign() <= 0 {
 		return "", errors.New("amountIn must be a positive integer string")
 	}
-	payRateFloat := new(big.Float).SetPrec(256).SetFloat64(payRate)
-	getRateFloat := new(big.Float).SetPrec(256).SetFloat64(getRate)
-	if getRateFloat.Sign() <= 0 {
-		return "", stable.ErrPriceUnavailable
-	}
-	amountInFloat := new(big.Float).SetPrec(256).SetInt(amountIn)
-	usdValueWei := new(big.Float).SetPrec(256).Mul(amountInFloat, payRateFloat)
-	amountOutFloat := new(big.Float).SetPrec(256).Quo(usdValueWei, getRateFloat)
-	amountOut, _ := amountOutFloat.Int(nil)
-	if amountOut == nil || amountOut.Sign() <= 0 {
+	// Both rates share the engine's rate scale, so the scale cancels and the
+	// conversion is exact integer arithmetic truncated toward zero.
+	usdValueWei := new(big.Int).Mul(amountIn, big.NewInt(payRate))
+	amountOut := new(big.Int).Quo(usdValueWei, big.NewInt(getRate))
+	if amountOut.Sign() <= 0 {
 		return "", errors.New("quoted amount is zero")
 	}
 	return amountOut.String(), nil
 }
 
-func parseFlexibleAmount(raw json.RawMessage) (float64, error) {
+// parseFlexibleAmount accepts a JSON number or numeric string and returns the
+// exact amount in stable engine units. Absent values yield zero so callers can
+// report the missing field themselves.
+func parseFlexibleAmount(raw json.RawMessage) (int64, error) {
 	trimmed := strings.TrimSpace(string(raw))
 	if trimmed == "" || trimmed == "null" {
 		return 0, nil
 	}
-	var numeric float64
-	if err := json.Unmarshal(raw, &numeric); err == nil {
-		return numeric, nil
+	var numeric json.Number
+	if err := json.Unmarshal(raw, &numeric); err != nil {
+		return 0, errors.New("amount must be a number or numeric string")
 	}
-	var text string
-	if err := json.Unmarshal(raw, &text); err == nil {
-		text = strings.TrimSpace(text)
-		if text == "" {
-			return 0, nil
-		}
-		value, ok := new(big.Float).SetString(text)
-		if !ok {
-			return 0, errors.New("amount string must be numeric")
-		}
-		floatValue, _ := value.Float64()
-		return floatValue, nil
+	if strings.TrimSpace(numeric.String()) == "" {
+		return 0, nil
 	}
-	return 0, errors.New("amount must be a number or numeric string")
+	return stable.ParseAmount(numeric.String())
 }
 
 func (s *Server) handleCheckSwapAllowance(w http.ResponseWriter, _ *http.Request, req *RPCRequest) {
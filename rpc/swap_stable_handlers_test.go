@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -319,7 +320,7 @@ func newStableRPCTestEngine(t *testing.T, base time.Time) *stable.Engine {
 		counter++
 		return ts
 	})
-	engine.RecordPrice("ZNHB", "USD", 1.0, base)
+	engine.RecordPrice("ZNHB", "USD", big.NewRat(1, 1), base)
 	engine.SetPriceMaxAge(0)
 	return engine
 }
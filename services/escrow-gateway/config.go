@@ -42,9 +42,10 @@ type Config struct {
 	NonceCapacity        int
 	APIKeys              []APIKeyConfig
 	MerchantConfigs      map[string]MerchantConfig
-	WebhookQueueCapacity int
 	WebhookHistorySize   int
-	WebhookQueueTTL      time.Duration
+	WebhookMaxAttempts   int
+	WebhookMaxBackoff    time.Duration
+	AdminToken           string
 }
 
 // LoadConfigFromEnv builds a configuration using environment variables.
@@ -56,9 +57,10 @@ func LoadConfigFromEnv() (Config, error) {
 		DatabasePath:         getenvDefault("ESCROW_GATEWAY_DB_PATH", "escrow-gateway.db"),
 		AllowedTimestampSkew: 2 * time.Minute,
 		NonceCapacity:        1024,
-		WebhookQueueCapacity: defaultTaskCapacity,
 		WebhookHistorySize:   defaultHistoryCapacity,
-		WebhookQueueTTL:      defaultQueueTTL,
+		WebhookMaxAttempts:   defaultWebhookMaxAttempts,
+		WebhookMaxBackoff:    defaultWebhookMaxBackoff,
+		AdminToken:           strings.TrimSpace(os.Getenv("ESCROW_GATEWAY_ADMIN_TOKEN")),
 	}
 
 	if skew := strings.TrimSpace(os.Getenv("ESCROW_GATEWAY_TIMESTAMP_SKEW")); skew != "" {
@@ -99,37 +101,37 @@ func LoadConfigFromEnv() (Config, error) {
 		return Config{}, errors.New("ESCROW_GATEWAY_NODE_URL is required")
 	}
 
-	if raw := strings.TrimSpace(os.Getenv("ESCROW_GATEWAY_QUEUE_CAP")); raw != "" {
+	if raw := strings.TrimSpace(os.Getenv("ESCROW_GATEWAY_QUEUE_HISTORY")); raw != "" {
 		val, err := strconv.Atoi(raw)
 		if err != nil {
-			return Config{}, fmt.Errorf("parse ESCROW_GATEWAY_QUEUE_CAP: %w", err)
+			return Config{}, fmt.Errorf("parse ESCROW_GATEWAY_QUEUE_HISTORY: %w", err)
 		}
 		if val <= 0 {
-			return Config{}, errors.New("ESCROW_GATEWAY_QUEUE_CAP must be positive")
+			return Config{}, errors.New("ESCROW_GATEWAY_QUEUE_HISTORY must be positive")
 		}
-		cfg.WebhookQueueCapacity = val
+		cfg.WebhookHistorySize = val
 	}
 
-	if raw := strings.TrimSpace(os.Getenv("ESCROW_GATEWAY_QUEUE_HISTORY")); raw != "" {
+	if raw := strings.TrimSpace(os.Getenv("ESCROW_GATEWAY_WEBHOOK_MAX_ATTEMPTS")); raw != "" {
 		val, err := strconv.Atoi(raw)
 		if err != nil {
-			return Config{}, fmt.Errorf("parse ESCROW_GATEWAY_QUEUE_HISTORY: %w", err)
+			return Config{}, fmt.Errorf("parse ESCROW_GATEWAY_WEBHOOK_MAX_ATTEMPTS: %w", err)
 		}
 		if val <= 0 {
-			return Config{}, errors.New("ESCROW_GATEWAY_QUEUE_HISTORY must be positive")
+			return Config{}, errors.New("ESCROW_GATEWAY_WEBHOOK_MAX_ATTEMPTS must be positive")
 		}
-		cfg.WebhookHistorySize = val
+		cfg.WebhookMaxAttempts = val
 	}
 
-	if raw := strings.TrimSpace(os.Getenv("ESCROW_GATEWAY_QUEUE_TTL")); raw != "" {
+	if raw := strings.TrimSpace(os.Getenv("ESCROW_GATEWAY_WEBHOOK_MAX_BACKOFF")); raw != "" {
 		dur, err := time.ParseDuration(raw)
 		if err != nil {
-			return Config{}, fmt.Errorf("parse ESCROW_GATEWAY_QUEUE_TTL: %w", err)
+			return Config{}, fmt.Errorf("parse ESCROW_GATEWAY_WEBHOOK_MAX_BACKOFF: %w", err)
 		}
-		if dur <= 0 {
-			return Config{}, errors.New("ESCROW_GATEWAY_QUEUE_TTL must be positive")
+		if dur < defaultWebhookMinBackoff {
+			return Config{}, fmt.Errorf("ESCROW_GATEWAY_WEBHOOK_MAX_BACKOFF must be at least %s", defaultWebhookMinBackoff)
 		}
-		cfg.WebhookQueueTTL = dur
+		cfg.WebhookMaxBackoff = dur
 	}
 
 	// Parse API keys from JSON array: [{"key":"...","secret":"..."}, ...]
@@ -59,13 +59,20 @@ func main() {
 
 	auth := NewAuthenticator(cfg.APIKeys, cfg.AllowedTimestampSkew, cfg.NonceTTL, cfg.NonceCapacity, nil)
 	node := NewRPCNodeClient(cfg.NodeURL, cfg.NodeAuthToken)
-	queue := NewWebhookQueue(
-		WithWebhookTaskCapacity(cfg.WebhookQueueCapacity),
+	queue, err := NewWebhookQueue(store,
 		WithWebhookHistoryCapacity(cfg.WebhookHistorySize),
-		WithWebhookTTL(cfg.WebhookQueueTTL),
+		WithWebhookRetryPolicy(cfg.WebhookMaxAttempts, cfg.WebhookMaxBackoff),
 	)
+	if err != nil {
+		log.Fatalf("init webhook outbox: %v", err)
+	}
 	intents := NewPayIntentBuilder()
 	server := NewServer(auth, node, store, queue, intents, cfg.MerchantConfigs)
+	server.SetAdminToken(cfg.AdminToken)
+
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+	go NewWebhookWorker(store, queue).Run(workerCtx)
 
 	srv := &http.Server{
 		Addr:    cfg.ListenAddress,
@@ -84,6 +91,7 @@ func main() {
 	<-sig
 
 	log.Printf("shutting down escrow gateway")
+	stopWorker()
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
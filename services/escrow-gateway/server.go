@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math/big"
 	"net/http"
 	"strings"
@@ -21,6 +22,7 @@ import (
 
 	nhbcrypto "nhbchain/crypto"
 	escrowpkg "nhbchain/native/escrow"
+	"nhbchain/services/webhook/outbox"
 )
 
 const (
@@ -28,6 +30,7 @@ const (
 	headerWalletAddress  = "X-Sig-Addr"
 	headerWalletSig      = "X-Sig"
 	maxRequestBody       = 1 << 20 // 1 MiB
+	webhookAdminPrefix   = "/admin/webhooks"
 )
 
 // Server is the HTTP front-end for escrow interactions.
@@ -39,6 +42,8 @@ type Server struct {
 	intents       *PayIntentBuilder
 	nowFn         func() time.Time
 	merchants     map[string]MerchantConfig
+	adminToken    string
+	admin         http.Handler
 }
 
 func NewServer(auth *Authenticator, node NodeClient, store *SQLiteStore, queue *WebhookQueue, intents *PayIntentBuilder, merchants map[string]MerchantConfig) *Server {
@@ -52,7 +57,10 @@ func NewServer(auth *Authenticator, node NodeClient, store *SQLiteStore, queue *
 		panic("sqlite store required")
 	}
 	if queue == nil {
-		queue = NewWebhookQueue()
+		var err error
+		if queue, err = NewWebhookQueue(store); err != nil {
+			panic(fmt.Sprintf("webhook queue: %v", err))
+		}
 	}
 	if intents == nil {
 		intents = NewPayIntentBuilder()
@@ -69,9 +77,17 @@ func NewServer(auth *Authenticator, node NodeClient, store *SQLiteStore, queue *
 		intents:       intents,
 		nowFn:         time.Now,
 		merchants:     clonedMerchants,
+		admin:         http.StripPrefix(webhookAdminPrefix, outbox.NewAdminHandler(queue.Outbox())),
 	}
 }
 
+// SetAdminToken enables the operator endpoints under /admin/webhooks/ and
+// requires callers to present token as a bearer credential. An empty token
+// keeps the endpoints disabled.
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = strings.TrimSpace(token)
+}
+
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch {
 	case r.Method == http.MethodPost && r.URL.Path == "/escrow/create":
@@ -94,6 +110,8 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.handleAcceptOffer(w, r)
 	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/p2p/trades/"):
 		s.handleGetTrade(w, r)
+	case strings.HasPrefix(r.URL.Path, webhookAdminPrefix+"/"):
+		s.handleWebhookAdmin(w, r)
 	default:
 		http.NotFound(w, r)
 	}
@@ -192,7 +210,11 @@ func (s *Server) handleEscrowCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.queue.Enqueue(WebhookEvent{Type: "escrow.created", EscrowID: created.ID, CreatedAt: s.nowFn()})
+	if err := s.queue.Enqueue(r.Context(), WebhookEvent{Type: "escrow.created", EscrowID: created.ID, CreatedAt: s.nowFn()}); err != nil {
+		// The escrow already exists on chain; failing the request would only
+		// invite a duplicate create.
+		log.Printf("escrow gateway: enqueue escrow.created webhooks for %s: %v", created.ID, err)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -451,6 +473,21 @@ func (s *Server) readRequestBody(r *http.Request) ([]byte, error) {
 	return data, nil
 }
 
+// handleWebhookAdmin serves the outbox operator endpoints once the bearer
+// token has been verified.
+func (s *Server) handleWebhookAdmin(w http.ResponseWriter, r *http.Request) {
+	if s.adminToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	presented := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(s.adminToken)) != 1 {
+		s.writeError(w, http.StatusUnauthorized, errors.New("invalid admin token"))
+		return
+	}
+	s.admin.ServeHTTP(w, r)
+}
+
 func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
 	if status <= 0 {
 		status = http.StatusInternalServerError
@@ -199,7 +199,10 @@ func newTestServer(t *testing.T, node NodeClient, merchants map[string]MerchantC
 	auth := NewAuthenticator([]APIKeyConfig{{Key: "test", Secret: "secret"}}, time.Minute, 2*time.Minute, 4, func() time.Time {
 		return time.Unix(1700000000, 0).UTC()
 	})
-	queue := NewWebhookQueue()
+	queue, err := NewWebhookQueue(store)
+	if err != nil {
+		t.Fatalf("new webhook queue: %v", err)
+	}
 	server := NewServer(auth, node, store, queue, NewPayIntentBuilder(), merchants)
 	return server, store, queue
 }
@@ -619,7 +622,10 @@ func TestEventWatcherProcessesEvents(t *testing.T) {
 		t.Fatalf("store: %v", err)
 	}
 	defer store.Close()
-	queue := NewWebhookQueue()
+	queue, err := NewWebhookQueue(store)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
 	now := time.Unix(1700001000, 0).UTC()
 	trade := P2PTrade{
 		ID:            "0xtrade",
@@ -676,6 +682,48 @@ func TestEventWatcherProcessesEvents(t *testing.T) {
 	}
 }
 
+func TestEventWatcherForwardsInvoiceEvents(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewSQLiteStore("file:testwatcherinvoice?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	defer store.Close()
+	queue, err := NewWebhookQueue(store)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	node := &mockNodeClient{
+		events: []NodeEvent{{
+			Sequence:   1,
+			Type:       "invoice.paid",
+			Attributes: map[string]string{"invoiceId": "ABCD", "amount": "100"},
+			Timestamp:  1700001000,
+		}},
+	}
+	watcher := NewEventWatcher(node, store, queue)
+	watcher.poll(ctx, 0)
+
+	events := queue.Events()
+	if len(events) != 1 {
+		t.Fatalf("expected one webhook event, got %d", len(events))
+	}
+	if events[0].InvoiceID != "0xabcd" || events[0].EscrowID != "" {
+		t.Fatalf("unexpected identifiers: invoice=%q escrow=%q", events[0].InvoiceID, events[0].EscrowID)
+	}
+	body, err := renderWebhookPayload(events[0])
+	if err != nil {
+		t.Fatalf("render payload: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if decoded["invoiceId"] != "0xabcd" {
+		t.Fatalf("expected invoiceId in payload, got %v", decoded["invoiceId"])
+	}
+}
+
 func TestWebhookWorkerDelivers(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -684,7 +732,10 @@ func TestWebhookWorkerDelivers(t *testing.T) {
 		t.Fatalf("store: %v", err)
 	}
 	defer store.Close()
-	queue := NewWebhookQueue()
+	queue, err := NewWebhookQueue(store)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
 	payloadCh := make(chan []byte, 1)
 	sigCh := make(chan string, 1)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -712,13 +763,15 @@ func TestWebhookWorkerDelivers(t *testing.T) {
 
 	go worker.Run(ctx)
 
-	queue.Enqueue(WebhookEvent{
+	if err := queue.Enqueue(ctx, WebhookEvent{
 		Sequence:   1,
 		Type:       "escrow.trade.funded",
 		TradeID:    "0xtrade",
 		Attributes: map[string]string{"tradeId": "trade"},
 		CreatedAt:  now,
-	})
+	}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
 
 	select {
 	case body := <-payloadCh:
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -20,7 +21,7 @@ type SQLiteStore struct {
 var ErrIdempotencyMismatch = errors.New("idempotency key reuse with different request body")
 
 func NewSQLiteStore(path string) (*SQLiteStore, error) {
-	db, err := sql.Open("sqlite", path)
+	db, err := sql.Open("sqlite", withBusyTimeout(path))
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +33,19 @@ func NewSQLiteStore(path string) (*SQLiteStore, error) {
 	return store, nil
 }
 
+// withBusyTimeout makes every pooled connection wait for the webhook worker's
+// write locks instead of failing immediately with SQLITE_BUSY.
+func withBusyTimeout(path string) string {
+	if strings.Contains(path, "busy_timeout") {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "_pragma=busy_timeout(5000)"
+}
+
 func (s *SQLiteStore) init() error {
 	schema := []string{
 		`CREATE TABLE IF NOT EXISTS idempotency_keys (
@@ -408,6 +422,23 @@ func (s *SQLiteStore) ListWebhooksForEvent(ctx context.Context, eventType string
 	return subs, nil
 }
 
+// GetWebhook loads a subscription by id. sql.ErrNoRows is returned when it
+// does not exist.
+func (s *SQLiteStore) GetWebhook(ctx context.Context, id int64) (WebhookSubscription, error) {
+	const query = `SELECT id, api_key, event_type, url, secret, rate_limit, active, created_at FROM webhooks WHERE id = ?`
+	var sub WebhookSubscription
+	var active int
+	row := s.db.QueryRowContext(ctx, query, id)
+	if err := row.Scan(&sub.ID, &sub.APIKey, &sub.EventType, &sub.URL, &sub.Secret, &sub.RateLimit, &active, &sub.CreatedAt); err != nil {
+		return WebhookSubscription{}, err
+	}
+	sub.Active = active == 1
+	if sub.RateLimit <= 0 {
+		sub.RateLimit = 60
+	}
+	return sub, nil
+}
+
 // WebhookAttempt captures a delivery attempt.
 type WebhookAttempt struct {
 	WebhookID     int64
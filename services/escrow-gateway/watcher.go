@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"log"
 	"strings"
 	"time"
 )
@@ -19,8 +20,9 @@ type EventWatcher struct {
 
 // NewEventWatcher constructs a watcher with sane defaults.
 func NewEventWatcher(node NodeClient, store *SQLiteStore, queue *WebhookQueue) *EventWatcher {
-	if queue == nil {
-		queue = NewWebhookQueue()
+	if queue == nil && store != nil {
+		// A watcher without a queue stays idle; Run checks for nil.
+		queue, _ = NewWebhookQueue(store)
 	}
 	return &EventWatcher{
 		node:         node,
@@ -71,14 +73,21 @@ func (w *EventWatcher) poll(ctx context.Context, after int64) int64 {
 		if evt.Sequence <= lastSeq {
 			continue
 		}
-		w.handleEvent(ctx, evt)
+		if err := w.handleEvent(ctx, evt); err != nil {
+			// Stop before the cursor passes an event whose webhooks were not
+			// persisted; it is fetched again on the next poll.
+			log.Printf("escrow gateway: enqueue webhooks for event %d: %v", evt.Sequence, err)
+			break
+		}
 		lastSeq = evt.Sequence
 	}
-	_ = w.store.UpdateEventSequence(ctx, lastSeq)
+	if lastSeq != after {
+		_ = w.store.UpdateEventSequence(ctx, lastSeq)
+	}
 	return lastSeq
 }
 
-func (w *EventWatcher) handleEvent(ctx context.Context, evt NodeEvent) {
+func (w *EventWatcher) handleEvent(ctx context.Context, evt NodeEvent) error {
 	createdAt := time.Unix(evt.Timestamp, 0)
 	if evt.Timestamp == 0 {
 		createdAt = w.nowFn().UTC()
@@ -106,6 +115,9 @@ func (w *EventWatcher) handleEvent(ctx context.Context, evt NodeEvent) {
 	if id := strings.TrimSpace(payload["id"]); id != "" {
 		webhook.EscrowID = normalizeHex(id)
 	}
+	if invoice := strings.TrimSpace(payload["invoiceId"]); invoice != "" {
+		webhook.InvoiceID = normalizeHex(invoice)
+	}
 	if trade := strings.TrimSpace(payload["tradeId"]); trade != "" {
 		webhook.TradeID = normalizeHex(trade)
 		if status := tradeStatusFromEvent(evt.Type); status != "" {
@@ -116,7 +128,7 @@ func (w *EventWatcher) handleEvent(ctx context.Context, evt NodeEvent) {
 			webhook.TradeID = tradeID
 		}
 	}
-	w.queue.Enqueue(webhook)
+	return w.queue.Enqueue(ctx, webhook)
 }
 
 func tradeStatusFromEvent(eventType string) string {
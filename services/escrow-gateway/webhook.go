@@ -5,19 +5,21 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"nhbchain/services/webhook"
+	"nhbchain/services/webhook/outbox"
 )
 
-const maxWebhookAttempts = 5
-
-// WebhookWorker delivers queued events to external subscribers.
+// WebhookWorker delivers outbox messages to external subscribers.
 type WebhookWorker struct {
 	store  *SQLiteStore
 	queue  *WebhookQueue
@@ -25,6 +27,7 @@ type WebhookWorker struct {
 	nowFn  func() time.Time
 
 	limiter *webhook.RateLimiter
+	metrics *webhookQueueMetrics
 }
 
 func NewWebhookWorker(store *SQLiteStore, queue *WebhookQueue) *WebhookWorker {
@@ -34,120 +37,73 @@ func NewWebhookWorker(store *SQLiteStore, queue *WebhookQueue) *WebhookWorker {
 		client:  &http.Client{Timeout: 10 * time.Second},
 		nowFn:   time.Now,
 		limiter: webhook.NewRateLimiter(),
+		metrics: queueMetrics(),
 	}
 }
 
-// Run processes webhook tasks until the context is cancelled.
+// Run processes webhook deliveries until the context is cancelled.
 func (w *WebhookWorker) Run(ctx context.Context) {
-	for {
-		task, ok := w.queue.Dequeue(ctx)
-		if !ok {
-			return
-		}
-		if task.Subscription == nil {
-			w.expandTask(ctx, task)
-			continue
-		}
-		w.handleDelivery(ctx, task)
-	}
+	_ = w.queue.Outbox().Run(ctx, w.deliver)
 }
 
-func (w *WebhookWorker) expandTask(ctx context.Context, task WebhookTask) {
-	subs, err := w.store.ListWebhooksForEvent(ctx, task.Event.Type)
+func (w *WebhookWorker) deliver(ctx context.Context, msg outbox.Message) error {
+	subID, err := strconv.ParseInt(msg.Subscriber, 10, 64)
 	if err != nil {
-		return
+		w.metrics.recordDeadLettered("invalid_subscriber")
+		return outbox.Permanent(fmt.Errorf("invalid webhook subscriber %q", msg.Subscriber))
 	}
-	for i := range subs {
-		sub := subs[i]
-		if !sub.Active {
-			continue
-		}
-		clone := WebhookTask{
-			Event:        task.Event,
-			Subscription: &sub,
-			Attempt:      0,
-		}
-		w.queue.enqueueTask(clone)
+	sub, err := w.store.GetWebhook(ctx, subID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !sub.Active) {
+		w.metrics.recordDeadLettered("inactive")
+		return outbox.Permanent(errors.New("webhook subscription inactive"))
 	}
-}
-
-func (w *WebhookWorker) handleDelivery(ctx context.Context, task WebhookTask) {
-	sub := task.Subscription
-	if sub == nil || !sub.Active {
-		return
+	if err != nil {
+		return err
 	}
 	now := w.nowFn()
 	if !w.limiter.Allow(sub.ID, sub.RateLimit, now) {
-		task.NotBefore = w.limiter.ResetAt(sub.ID, now)
-		w.queue.enqueueTask(task)
-		return
-	}
-	body := map[string]interface{}{
-		"type":       task.Event.Type,
-		"sequence":   task.Event.Sequence,
-		"escrowId":   task.Event.EscrowID,
-		"tradeId":    task.Event.TradeID,
-		"attributes": task.Event.Attributes,
-		"timestamp":  task.Event.CreatedAt.UTC().Format(time.RFC3339Nano),
-	}
-	if provider := extractProviderMetadata(task.Event.Attributes); provider != nil {
-		body["provider"] = provider
-	}
-	payload, err := json.Marshal(body)
-	if err != nil {
-		w.recordAttempt(ctx, task, "error", err.Error(), now, time.Time{})
-		return
+		return outbox.Defer(w.limiter.ResetAt(sub.ID, now))
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytesClone(payload))
+	sequence := payloadSequence(msg.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytesClone(msg.Payload))
 	if err != nil {
-		w.recordAttempt(ctx, task, "error", err.Error(), now, time.Time{})
-		return
+		w.recordAttempt(ctx, sub.ID, sequence, msg.Attempts, "error", err.Error(), now, time.Time{})
+		w.metrics.recordDeadLettered("invalid_request")
+		return outbox.Permanent(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Webhook-Signature", signPayload(sub.Secret, payload))
+	req.Header.Set("X-Webhook-Signature", signPayload(sub.Secret, msg.Payload))
 
 	resp, err := w.client.Do(req)
 	if err != nil {
-		w.retryLater(ctx, task, err.Error())
-		return
+		w.recordFailure(ctx, sub.ID, sequence, msg.Attempts, err.Error(), now)
+		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		w.retryLater(ctx, task, resp.Status)
-		return
+		w.recordFailure(ctx, sub.ID, sequence, msg.Attempts, resp.Status, now)
+		return fmt.Errorf("webhook delivery failed: %s", resp.Status)
 	}
-	w.recordAttempt(ctx, task, "success", "", now, time.Time{})
-}
-
-func (w *WebhookWorker) retryLater(ctx context.Context, task WebhookTask, errMsg string) {
-	now := w.nowFn()
-	attemptNum := task.Attempt + 1
-	w.recordAttempt(ctx, task, "failed", errMsg, now, now.Add(w.backoffDuration(attemptNum)))
-	if attemptNum >= maxWebhookAttempts {
-		return
-	}
-	task.Attempt++
-	task.NotBefore = now.Add(w.backoffDuration(attemptNum))
-	w.queue.enqueueTask(task)
+	w.recordAttempt(ctx, sub.ID, sequence, msg.Attempts, "success", "", now, time.Time{})
+	return nil
 }
 
-func (w *WebhookWorker) backoffDuration(attempt int) time.Duration {
-	base := time.Second
-	if attempt <= 0 {
-		attempt = 1
+func (w *WebhookWorker) recordFailure(ctx context.Context, webhookID, sequence int64, attempts int, errMsg string, now time.Time) {
+	policy := w.queue.Outbox().RetryPolicy()
+	var next time.Time
+	if attempts+1 < policy.MaxAttempts {
+		next = now.Add(policy.Backoff(attempts + 1))
+	} else {
+		w.metrics.recordDeadLettered("exhausted")
 	}
-	d := base * time.Duration(1<<uint(attempt-1))
-	if d > 5*time.Minute {
-		return 5 * time.Minute
-	}
-	return d
+	w.recordAttempt(ctx, webhookID, sequence, attempts, "failed", errMsg, now, next)
 }
 
-func (w *WebhookWorker) recordAttempt(ctx context.Context, task WebhookTask, status, errMsg string, now time.Time, next time.Time) {
+func (w *WebhookWorker) recordAttempt(ctx context.Context, webhookID, sequence int64, attempts int, status, errMsg string, now time.Time, next time.Time) {
 	attempt := WebhookAttempt{
-		WebhookID:     task.Subscription.ID,
-		EventSequence: task.Event.Sequence,
-		Attempt:       task.Attempt + 1,
+		WebhookID:     webhookID,
+		EventSequence: sequence,
+		Attempt:       attempts + 1,
 		Status:        status,
 		Error:         errMsg,
 		NextAttempt:   next,
@@ -156,6 +112,14 @@ func (w *WebhookWorker) recordAttempt(ctx context.Context, task WebhookTask, sta
 	_ = w.store.InsertWebhookAttempt(ctx, attempt)
 }
 
+func payloadSequence(payload []byte) int64 {
+	var body struct {
+		Sequence int64 `json:"sequence"`
+	}
+	_ = json.Unmarshal(payload, &body)
+	return body.Sequence
+}
+
 func signPayload(secret string, payload []byte) string {
 	mac := hmac.New(sha256.New, []byte(secret))
 	mac.Write(payload)
@@ -2,6 +2,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -9,6 +13,8 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/noop"
+
+	"nhbchain/services/webhook/outbox"
 )
 
 // WebhookEvent represents a queued webhook notification.
@@ -17,52 +23,26 @@ type WebhookEvent struct {
 	Type       string
 	EscrowID   string
 	TradeID    string
+	InvoiceID  string
 	Attributes map[string]string
 	CreatedAt  time.Time
 }
 
-type WebhookTask struct {
-	Event        WebhookEvent
-	Subscription *WebhookSubscription
-	Attempt      int
-	NotBefore    time.Time
-}
-
-type queuedTask struct {
-	task       WebhookTask
-	enqueuedAt time.Time
-}
-
-type historyEntry struct {
-	event      WebhookEvent
-	enqueuedAt time.Time
-}
-
 // WebhookQueueOption adjusts the behaviour of the queue.
 type WebhookQueueOption func(*webhookQueueConfig)
 
 type webhookQueueConfig struct {
-	taskCapacity    int
 	historyCapacity int
-	ttl             time.Duration
-	now             func() time.Time
+	outboxOpts      []outbox.Option
 }
 
 const (
-	defaultTaskCapacity    = 1024
-	defaultHistoryCapacity = 256
-	defaultQueueTTL        = 15 * time.Minute
+	defaultHistoryCapacity    = 256
+	defaultWebhookMaxAttempts = 5
+	defaultWebhookMinBackoff  = time.Second
+	defaultWebhookMaxBackoff  = 5 * time.Minute
 )
 
-// WithWebhookTaskCapacity sets the maximum number of pending webhook tasks.
-func WithWebhookTaskCapacity(capacity int) WebhookQueueOption {
-	return func(cfg *webhookQueueConfig) {
-		if capacity > 0 {
-			cfg.taskCapacity = capacity
-		}
-	}
-}
-
 // WithWebhookHistoryCapacity sets the number of events retained for inspection.
 func WithWebhookHistoryCapacity(capacity int) WebhookQueueOption {
 	return func(cfg *webhookQueueConfig) {
@@ -72,176 +52,132 @@ func WithWebhookHistoryCapacity(capacity int) WebhookQueueOption {
 	}
 }
 
-// WithWebhookTTL configures how long queued items remain eligible for delivery.
-func WithWebhookTTL(ttl time.Duration) WebhookQueueOption {
+// WithWebhookRetryPolicy configures how many times a delivery is attempted
+// and the ceiling for the exponential backoff between attempts.
+func WithWebhookRetryPolicy(maxAttempts int, maxBackoff time.Duration) WebhookQueueOption {
 	return func(cfg *webhookQueueConfig) {
-		if ttl > 0 {
-			cfg.ttl = ttl
-		}
+		cfg.outboxOpts = append(cfg.outboxOpts, outbox.WithRetryPolicy(outbox.RetryPolicy{
+			MaxAttempts: maxAttempts,
+			MinBackoff:  defaultWebhookMinBackoff,
+			MaxBackoff:  maxBackoff,
+		}))
 	}
 }
 
-// withWebhookClock overrides the clock used for TTL evaluation (test only).
-func withWebhookClock(now func() time.Time) WebhookQueueOption {
+// withWebhookOutboxOptions passes raw outbox options through (test only).
+func withWebhookOutboxOptions(opts ...outbox.Option) WebhookQueueOption {
 	return func(cfg *webhookQueueConfig) {
-		if now != nil {
-			cfg.now = now
-		}
+		cfg.outboxOpts = append(cfg.outboxOpts, opts...)
 	}
 }
 
-// WebhookQueue stores webhook tasks prior to delivery.
+// WebhookQueue fans events out to the subscriptions interested in them and
+// persists one outbox message per subscription, so deliveries survive
+// restarts and receiver outages. Each subscription is delivered in event
+// order. A bounded history of recent events is kept in memory for
+// diagnostics.
 type WebhookQueue struct {
+	store  *SQLiteStore
+	outbox *outbox.Outbox
+
 	mu      sync.Mutex
-	tasks   queueRing[queuedTask]
-	history queueRing[historyEntry]
-	ttl     time.Duration
-	now     func() time.Time
-	metrics *webhookQueueMetrics
+	history queueRing[WebhookEvent]
 }
 
-// NewWebhookQueue constructs a bounded queue with optional customisation.
-func NewWebhookQueue(opts ...WebhookQueueOption) *WebhookQueue {
+// NewWebhookQueue prepares the webhook outbox inside the gateway database.
+func NewWebhookQueue(store *SQLiteStore, opts ...WebhookQueueOption) (*WebhookQueue, error) {
+	if store == nil {
+		return nil, errors.New("sqlite store required")
+	}
 	cfg := webhookQueueConfig{
-		taskCapacity:    defaultTaskCapacity,
 		historyCapacity: defaultHistoryCapacity,
-		ttl:             defaultQueueTTL,
-		now:             time.Now,
+		outboxOpts: []outbox.Option{outbox.WithRetryPolicy(outbox.RetryPolicy{
+			MaxAttempts: defaultWebhookMaxAttempts,
+			MinBackoff:  defaultWebhookMinBackoff,
+			MaxBackoff:  defaultWebhookMaxBackoff,
+		})},
 	}
 	for _, opt := range opts {
 		opt(&cfg)
 	}
-
-	q := &WebhookQueue{
-		tasks:   newQueueRing[queuedTask](cfg.taskCapacity),
-		history: newQueueRing[historyEntry](cfg.historyCapacity),
-		ttl:     cfg.ttl,
-		now:     cfg.now,
-		metrics: queueMetrics(),
+	ob, err := outbox.New(store.db, cfg.outboxOpts...)
+	if err != nil {
+		return nil, err
 	}
-	return q
-}
-
-// Enqueue adds an event to the queue.
-func (q *WebhookQueue) Enqueue(evt WebhookEvent) {
-	q.enqueueTask(WebhookTask{Event: evt})
+	return &WebhookQueue{
+		store:   store,
+		outbox:  ob,
+		history: newQueueRing[WebhookEvent](cfg.historyCapacity),
+	}, nil
 }
 
-func (q *WebhookQueue) enqueueTask(task WebhookTask) {
-	now := q.now()
+// Enqueue records the event and persists a delivery for every active
+// subscription. Delivery is at-least-once: if persisting fails part way the
+// caller may retry the event and earlier subscriptions receive it twice, so
+// receivers should deduplicate on type and sequence.
+func (q *WebhookQueue) Enqueue(ctx context.Context, evt WebhookEvent) error {
 	q.mu.Lock()
-	defer q.mu.Unlock()
-	q.evictExpiredLocked(now)
-	if task.Subscription == nil {
-		q.recordHistoryLocked(historyEntry{event: task.Event, enqueuedAt: now})
+	q.history.push(evt)
+	q.mu.Unlock()
+
+	subs, err := q.store.ListWebhooksForEvent(ctx, evt.Type)
+	if err != nil {
+		return fmt.Errorf("list webhooks for %s: %w", evt.Type, err)
+	}
+	var payload []byte
+	for _, sub := range subs {
+		if !sub.Active {
+			continue
+		}
+		if payload == nil {
+			if payload, err = renderWebhookPayload(evt); err != nil {
+				return err
+			}
+		}
+		if _, err := q.outbox.Enqueue(ctx, webhookSubscriber(sub.ID), evt.Type, payload); err != nil {
+			return err
+		}
 	}
-	q.recordTaskLocked(queuedTask{task: task, enqueuedAt: now})
+	return nil
 }
 
-// Events returns a snapshot copy of queued events. Primarily used in tests.
+// Events returns a snapshot copy of recently queued events. Primarily used in tests.
 func (q *WebhookQueue) Events() []WebhookEvent {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.evictExpiredLocked(q.now())
 	snapshot := make([]WebhookEvent, 0, q.history.len())
-	q.history.forEach(func(entry historyEntry) {
-		snapshot = append(snapshot, entry.event)
+	q.history.forEach(func(evt WebhookEvent) {
+		snapshot = append(snapshot, evt)
 	})
 	return snapshot
 }
 
-// Dequeue waits for the next webhook task. Returns false if the context is cancelled.
-func (q *WebhookQueue) Dequeue(ctx context.Context) (WebhookTask, bool) {
-	for {
-		q.mu.Lock()
-		q.evictExpiredLocked(q.now())
-		queued, ok := q.tasks.pop()
-		q.mu.Unlock()
-		if !ok {
-			select {
-			case <-ctx.Done():
-				return WebhookTask{}, false
-			case <-time.After(25 * time.Millisecond):
-				continue
-			}
-		}
-
-		if delay := time.Until(queued.task.NotBefore); delay > 0 {
-			timer := time.NewTimer(delay)
-			select {
-			case <-ctx.Done():
-				timer.Stop()
-				return WebhookTask{}, false
-			case <-timer.C:
-			}
-		}
-
-		if q.ttl > 0 {
-			if age := q.now().Sub(queued.enqueuedAt); age > q.ttl {
-				q.metrics.recordDropped("ttl", 1)
-				continue
-			}
-		}
-
-		return queued.task, true
-	}
+// Outbox exposes the durable delivery queue backing the webhook pipeline.
+func (q *WebhookQueue) Outbox() *outbox.Outbox {
+	return q.outbox
 }
 
-func (q *WebhookQueue) recordTaskLocked(task queuedTask) {
-	if q.tasks.capacity() == 0 {
-		q.metrics.recordDropped("overflow", 1)
-		return
+func renderWebhookPayload(evt WebhookEvent) ([]byte, error) {
+	body := map[string]interface{}{
+		"type":       evt.Type,
+		"sequence":   evt.Sequence,
+		"escrowId":   evt.EscrowID,
+		"tradeId":    evt.TradeID,
+		"attributes": evt.Attributes,
+		"timestamp":  evt.CreatedAt.UTC().Format(time.RFC3339Nano),
 	}
-	if _, ok := q.tasks.push(task); ok {
-		q.metrics.recordDropped("overflow", 1)
-	}
-}
-
-func (q *WebhookQueue) recordHistoryLocked(entry historyEntry) {
-	if q.history.capacity() == 0 {
-		q.metrics.recordDropped("history_overflow", 1)
-		return
+	if evt.InvoiceID != "" {
+		body["invoiceId"] = evt.InvoiceID
 	}
-	if _, ok := q.history.push(entry); ok {
-		q.metrics.recordDropped("history_overflow", 1)
+	if provider := extractProviderMetadata(evt.Attributes); provider != nil {
+		body["provider"] = provider
 	}
+	return json.Marshal(body)
 }
 
-func (q *WebhookQueue) evictExpiredLocked(now time.Time) {
-	if q.ttl <= 0 {
-		return
-	}
-	expired := 0
-	for {
-		queued, ok := q.tasks.peek()
-		if !ok {
-			break
-		}
-		if now.Sub(queued.enqueuedAt) <= q.ttl {
-			break
-		}
-		q.tasks.pop()
-		expired++
-	}
-	if expired > 0 {
-		q.metrics.recordDropped("ttl", expired)
-	}
-
-	historyExpired := 0
-	for {
-		entry, ok := q.history.peek()
-		if !ok {
-			break
-		}
-		if now.Sub(entry.enqueuedAt) <= q.ttl {
-			break
-		}
-		q.history.pop()
-		historyExpired++
-	}
-	if historyExpired > 0 {
-		q.metrics.recordDropped("history_ttl", historyExpired)
-	}
+// webhookSubscriber maps a subscription to its outbox ordering key.
+func webhookSubscriber(id int64) string {
+	return strconv.FormatInt(id, 10)
 }
 
 // queueRing is a fixed-size ring buffer that overwrites the oldest element on overflow.
@@ -278,35 +214,10 @@ func (r *queueRing[T]) push(v T) (T, bool) {
 	return zero, false
 }
 
-func (r *queueRing[T]) pop() (T, bool) {
-	if r.size == 0 || len(r.buf) == 0 {
-		var zero T
-		return zero, false
-	}
-	v := r.buf[r.head]
-	var zero T
-	r.buf[r.head] = zero
-	r.head = (r.head + 1) % len(r.buf)
-	r.size--
-	return v, true
-}
-
-func (r *queueRing[T]) peek() (T, bool) {
-	if r.size == 0 || len(r.buf) == 0 {
-		var zero T
-		return zero, false
-	}
-	return r.buf[r.head], true
-}
-
 func (r *queueRing[T]) len() int {
 	return r.size
 }
 
-func (r *queueRing[T]) capacity() int {
-	return len(r.buf)
-}
-
 func (r *queueRing[T]) forEach(fn func(T)) {
 	if r.size == 0 || len(r.buf) == 0 {
 		return
@@ -323,25 +234,25 @@ var (
 )
 
 type webhookQueueMetrics struct {
-	dropped metric.Int64Counter
+	deadLettered metric.Int64Counter
 }
 
 func queueMetrics() *webhookQueueMetrics {
 	metricsOnce.Do(func() {
 		meter := otel.GetMeterProvider().Meter("nhbchain/escrow-gateway")
-		counter, err := meter.Int64Counter("nhb.escrow.webhooks.dropped")
+		counter, err := meter.Int64Counter("nhb.escrow.webhooks.dead_lettered")
 		if err != nil {
 			fallback := noop.NewMeterProvider().Meter("nhbchain/escrow-gateway")
-			counter, _ = fallback.Int64Counter("nhb.escrow.webhooks.dropped")
+			counter, _ = fallback.Int64Counter("nhb.escrow.webhooks.dead_lettered")
 		}
-		sharedQueueMetrics = &webhookQueueMetrics{dropped: counter}
+		sharedQueueMetrics = &webhookQueueMetrics{deadLettered: counter}
 	})
 	return sharedQueueMetrics
 }
 
-func (m *webhookQueueMetrics) recordDropped(reason string, count int) {
-	if m == nil || m.dropped == nil || count <= 0 {
+func (m *webhookQueueMetrics) recordDeadLettered(reason string) {
+	if m == nil || m.deadLettered == nil {
 		return
 	}
-	m.dropped.Add(context.Background(), int64(count), metric.WithAttributes(attribute.String("reason", reason)))
+	m.deadLettered.Add(context.Background(), 1, metric.WithAttributes(attribute.String("reason", reason)))
 }
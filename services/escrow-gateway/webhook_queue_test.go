@@ -2,92 +2,185 @@ package main
 
 import (
 	"context"
-	"sync"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"nhbchain/services/webhook/outbox"
 )
 
-type fakeClock struct {
-	mu  sync.Mutex
-	now time.Time
+func newFileStore(t *testing.T, path string) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	return store
 }
 
-func newFakeClock(start time.Time) *fakeClock {
-	return &fakeClock{now: start}
+func insertTestWebhook(t *testing.T, store *SQLiteStore, eventType, url string, active bool) int64 {
+	t.Helper()
+	id, err := store.InsertWebhook(context.Background(), WebhookSubscription{
+		APIKey:    "test",
+		EventType: eventType,
+		URL:       url,
+		Secret:    "whsecret",
+		RateLimit: 100,
+		Active:    active,
+		CreatedAt: time.Unix(1700000000, 0).UTC(),
+	})
+	if err != nil {
+		t.Fatalf("insert webhook: %v", err)
+	}
+	return id
 }
 
-func (c *fakeClock) Now() time.Time {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.now
-}
+func TestWebhookQueuePersistsPerSubscription(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "gateway.db")
+	store := newFileStore(t, path)
+	active := insertTestWebhook(t, store, "escrow.trade.funded", "https://example.com/a", true)
+	insertTestWebhook(t, store, "escrow.trade.funded", "https://example.com/b", false)
+	other := insertTestWebhook(t, store, "escrow.created", "https://example.com/c", true)
 
-func (c *fakeClock) Advance(d time.Duration) {
-	c.mu.Lock()
-	c.now = c.now.Add(d)
-	c.mu.Unlock()
-}
+	queue, err := NewWebhookQueue(store)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	for seq := int64(1); seq <= 2; seq++ {
+		if err := queue.Enqueue(ctx, WebhookEvent{Sequence: seq, Type: "escrow.trade.funded", CreatedAt: time.Unix(1700000000, 0)}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("close store: %v", err)
+	}
 
-func TestWebhookQueueDropOldest(t *testing.T) {
-	clock := newFakeClock(time.Unix(1700000000, 0).UTC())
-	queue := NewWebhookQueue(
-		WithWebhookTaskCapacity(3),
-		WithWebhookHistoryCapacity(2),
-		WithWebhookTTL(time.Minute),
-		withWebhookClock(clock.Now),
-	)
+	// Deliveries must survive a restart of the gateway.
+	reopened := newFileStore(t, path)
+	defer reopened.Close()
+	queue, err = NewWebhookQueue(reopened)
+	if err != nil {
+		t.Fatalf("reopen queue: %v", err)
+	}
+	pending, err := queue.Outbox().Pending(ctx, outbox.Filter{})
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending deliveries, got %d", len(pending))
+	}
+	for i, msg := range pending {
+		if msg.Subscriber != webhookSubscriber(active) {
+			t.Fatalf("unexpected subscriber %s", msg.Subscriber)
+		}
+		if seq := payloadSequence(msg.Payload); seq != int64(i+1) {
+			t.Fatalf("expected sequence %d at position %d, got %d", i+1, i, seq)
+		}
+	}
+	if msgs, _ := queue.Outbox().Pending(ctx, outbox.Filter{Subscriber: webhookSubscriber(other)}); len(msgs) != 0 {
+		t.Fatalf("unexpected deliveries for unrelated subscription: %d", len(msgs))
+	}
+}
 
+func TestWebhookQueueHistoryKeepsNewest(t *testing.T) {
+	store := newFileStore(t, filepath.Join(t.TempDir(), "gateway.db"))
+	defer store.Close()
+	queue, err := NewWebhookQueue(store, WithWebhookHistoryCapacity(2))
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
 	for i := 0; i < 5; i++ {
-		queue.Enqueue(WebhookEvent{Sequence: int64(i), CreatedAt: clock.Now()})
+		if err := queue.Enqueue(context.Background(), WebhookEvent{Sequence: int64(i), Type: "escrow.created"}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
 	}
-
 	events := queue.Events()
-	if len(events) != 2 {
-		t.Fatalf("expected 2 events in history, got %d", len(events))
-	}
-	if events[0].Sequence != 3 || events[1].Sequence != 4 {
-		t.Fatalf("unexpected history sequences: %+v", events)
+	if len(events) != 2 || events[0].Sequence != 3 || events[1].Sequence != 4 {
+		t.Fatalf("unexpected history: %+v", events)
 	}
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+func TestWebhookWorkerDeadLettersAfterRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	store := newFileStore(t, filepath.Join(t.TempDir(), "gateway.db"))
+	defer store.Close()
 
-	var sequences []int64
-	for len(sequences) < 3 {
-		task, ok := queue.Dequeue(ctx)
-		if !ok {
-			t.Fatalf("expected task, queue closed early after %d items", len(sequences))
-		}
-		sequences = append(sequences, task.Event.Sequence)
+	var calls int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+	subID := insertTestWebhook(t, store, "escrow.released", receiver.URL, true)
+
+	queue, err := NewWebhookQueue(store, withWebhookOutboxOptions(
+		outbox.WithRetryPolicy(outbox.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		outbox.WithPollInterval(5*time.Millisecond),
+	))
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	go NewWebhookWorker(store, queue).Run(ctx)
+	if err := queue.Enqueue(ctx, WebhookEvent{Sequence: 7, Type: "escrow.released", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("enqueue: %v", err)
 	}
 
-	expected := []int64{2, 3, 4}
-	for i, seq := range expected {
-		if sequences[i] != seq {
-			t.Fatalf("expected sequence %d at position %d, got %d", seq, i, sequences[i])
+	var letters []outbox.DeadLetter
+	deadline := time.Now().Add(2 * time.Second)
+	for len(letters) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for dead letter")
+		}
+		time.Sleep(10 * time.Millisecond)
+		letters, err = queue.Outbox().DeadLetters(ctx, outbox.Filter{})
+		if err != nil {
+			t.Fatalf("dead letters: %v", err)
 		}
 	}
+	if letters[0].Attempts != 2 || letters[0].Subscriber != webhookSubscriber(subID) {
+		t.Fatalf("unexpected dead letter %+v", letters[0])
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected 2 delivery attempts, got %d", got)
+	}
+	var failed int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM webhook_attempts WHERE webhook_id = ? AND event_sequence = 7 AND status = 'failed'", subID).Scan(&failed); err != nil {
+		t.Fatalf("count attempts: %v", err)
+	}
+	if failed != 2 {
+		t.Fatalf("expected 2 failed attempts recorded, got %d", failed)
+	}
 }
 
-func TestWebhookQueueEvictsExpired(t *testing.T) {
-	clock := newFakeClock(time.Unix(1700000000, 0).UTC())
-	queue := NewWebhookQueue(
-		WithWebhookTaskCapacity(2),
-		WithWebhookHistoryCapacity(2),
-		WithWebhookTTL(10*time.Second),
-		withWebhookClock(clock.Now),
-	)
+func TestWebhookAdminRequiresToken(t *testing.T) {
+	server, store, _ := newTestServer(t, &mockNodeClient{}, nil)
+	defer store.Close()
 
-	queue.Enqueue(WebhookEvent{Sequence: 42, CreatedAt: clock.Now()})
-	clock.Advance(11 * time.Second)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/webhooks/dead-letters", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected admin endpoints disabled without token, got %d", rec.Code)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
-	defer cancel()
-	if task, ok := queue.Dequeue(ctx); ok {
-		t.Fatalf("expected expired event to be dropped, dequeued sequence %d", task.Event.Sequence)
+	server.SetAdminToken("ops-token")
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin/webhooks/dead-letters", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with wrong token, got %d", rec.Code)
 	}
 
-	if remaining := queue.Events(); len(remaining) != 0 {
-		t.Fatalf("expected no history events after TTL eviction, got %d", len(remaining))
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/admin/webhooks/dead-letters", nil)
+	req.Header.Set("Authorization", "Bearer ops-token")
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with admin token, got %d: %s", rec.Code, rec.Body.String())
 	}
 }
@@ -1,6 +1,10 @@
 package engine
 
-import "context"
+import (
+	"context"
+	"math/big"
+	"strings"
+)
 
 // Engine describes the operations required by the lending gRPC surface.
 //
@@ -45,6 +49,22 @@ type MarketSnapshot struct {
 	BorrowIndex       string `json:"borrowIndex"`
 	ReserveFactor     uint64 `json:"reserveFactor"`
 	LastUpdateBlock   uint64 `json:"lastUpdateBlock"`
+	BadDebtNHB        string `json:"badDebtNHB,omitempty"`
+	BorrowAsset       string `json:"borrowAsset,omitempty"`
+	// Collateral lists the assets the market accepts. Empty for markets
+	// that predate multi-asset support, which accept ZNHB under the
+	// node-wide risk parameters.
+	Collateral []CollateralAsset `json:"collateral,omitempty"`
+}
+
+// CollateralAsset describes the risk settings of one accepted collateral
+// asset.
+type CollateralAsset struct {
+	Asset                string `json:"asset"`
+	MaxLTV               uint64 `json:"maxLTV"`
+	LiquidationThreshold uint64 `json:"liquidationThreshold"`
+	OracleFeed           string `json:"oracleFeed,omitempty"`
+	SupplyCap            string `json:"supplyCap,omitempty"`
 }
 
 // RiskParameters exposes the governance controlled safety configuration.
@@ -52,6 +72,8 @@ type RiskParameters struct {
 	MaxLTV               uint64         `json:"maxLTV"`
 	LiquidationThreshold uint64         `json:"liquidationThreshold"`
 	LiquidationBonus     uint64         `json:"liquidationBonus"`
+	CloseFactorBps       uint64         `json:"closeFactorBps"`
+	Auction              AuctionConfig  `json:"auction"`
 	DeveloperFeeCapBps   uint64         `json:"developerFeeCapBps"`
 	BorrowCaps           BorrowCaps     `json:"borrowCaps"`
 	Oracle               OracleConfig   `json:"oracle"`
@@ -73,6 +95,17 @@ type OracleConfig struct {
 	MaxDeviationBps uint64 `json:"maxDeviationBps"`
 }
 
+// AuctionConfig describes the Dutch-auction liquidation schedule. The bonus
+// starts at StartBonusBps and rises by StepBonusBps every StepBlocks blocks up
+// to MaxBonusBps.
+type AuctionConfig struct {
+	Enabled       bool   `json:"enabled"`
+	StartBonusBps uint64 `json:"startBonusBps"`
+	StepBonusBps  uint64 `json:"stepBonusBps"`
+	StepBlocks    uint64 `json:"stepBlocks"`
+	MaxBonusBps   uint64 `json:"maxBonusBps"`
+}
+
 // ActionPauses captures fine grained pause switches for market operations.
 type ActionPauses struct {
 	Supply    bool `json:"supply"`
@@ -94,12 +127,39 @@ type Position struct {
 }
 
 // AccountSnapshot captures on-ledger balances using decimal encoded strings.
+// CollateralZNHB is the single-asset form; Collateral carries every pledged
+// asset when the node reports it.
 type AccountSnapshot struct {
-	Address        string `json:"address,omitempty"`
-	CollateralZNHB string `json:"collateralZNHB"`
-	SupplyShares   string `json:"supplyShares"`
-	DebtNHB        string `json:"debtNHB"`
-	ScaledDebt     string `json:"scaledDebt,omitempty"`
+	Address        string              `json:"address,omitempty"`
+	CollateralZNHB string              `json:"collateralZNHB"`
+	Collateral     []CollateralBalance `json:"collateral,omitempty"`
+	SupplyShares   string              `json:"supplyShares"`
+	DebtNHB        string              `json:"debtNHB"`
+	ScaledDebt     string              `json:"scaledDebt,omitempty"`
+}
+
+// CollateralBalance reports the amount pledged for one collateral asset.
+type CollateralBalance struct {
+	Asset     string `json:"asset"`
+	AmountWei string `json:"amountWei"`
+}
+
+// TotalCollateral sums the pledged collateral across assets, falling back to
+// CollateralZNHB for snapshots without per-asset balances.
+func (a *AccountSnapshot) TotalCollateral() string {
+	if a == nil {
+		return ""
+	}
+	if len(a.Collateral) == 0 {
+		return a.CollateralZNHB
+	}
+	total := big.NewInt(0)
+	for _, balance := range a.Collateral {
+		if amount, ok := new(big.Int).SetString(strings.TrimSpace(balance.AmountWei), 10); ok {
+			total.Add(total, amount)
+		}
+	}
+	return total.String()
 }
 
 // Health combines the market snapshot and user account used for risk checks.
@@ -151,7 +151,7 @@ func (a *NodeAdapter) GetHealth(ctx context.Context, addr string) (Health, error
 		Market:         market.Market,
 		RiskParameters: market.RiskParameters,
 		Account:        position.Account,
-		HealthFactor:   computeHealthFactor(position.Account.CollateralZNHB, position.Account.DebtNHB),
+		HealthFactor:   computeHealthFactor(position.Account.TotalCollateral(), position.Account.DebtNHB),
 	}, nil
 }
 
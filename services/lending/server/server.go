@@ -360,6 +360,19 @@ func toProtoMarket(snapshot engine.Market) *lendingv1.Market {
 		ReserveFactor:    formatUint(snapshot.Market.ReserveFactor),
 		LiquidityIndex:   normalizeAmount(snapshot.Market.SupplyIndex),
 		BorrowIndex:      normalizeAmount(snapshot.Market.BorrowIndex),
+		BorrowAsset:      strings.TrimSpace(snapshot.Market.BorrowAsset),
+	}
+	if market.BorrowAsset == "" {
+		market.BorrowAsset = defaultBaseAsset
+	}
+	for _, cfg := range snapshot.Market.Collateral {
+		market.CollateralAssets = append(market.CollateralAssets, &lendingv1.CollateralAsset{
+			Asset:                strings.TrimSpace(cfg.Asset),
+			MaxLtv:               formatUint(cfg.MaxLTV),
+			LiquidationThreshold: formatUint(cfg.LiquidationThreshold),
+			OracleFeed:           strings.TrimSpace(cfg.OracleFeed),
+			SupplyCap:            normalizeAmount(cfg.SupplyCap),
+		})
 	}
 	return market
 }
@@ -369,13 +382,21 @@ func toProtoPosition(pos engine.Position) *lendingv1.AccountPosition {
 		return nil
 	}
 	account := pos.Account
-	return &lendingv1.AccountPosition{
+	collateral := account.TotalCollateral()
+	position := &lendingv1.AccountPosition{
 		Account:      strings.TrimSpace(account.Address),
 		Supplied:     normalizeAmount(account.SupplyShares),
 		Borrowed:     normalizeAmount(account.DebtNHB),
-		Collateral:   normalizeAmount(account.CollateralZNHB),
-		HealthFactor: computeHealthFactor(account.CollateralZNHB, account.DebtNHB),
+		Collateral:   normalizeAmount(collateral),
+		HealthFactor: computeHealthFactor(collateral, account.DebtNHB),
+	}
+	for _, balance := range account.Collateral {
+		position.CollateralBalances = append(position.CollateralBalances, &lendingv1.CollateralBalance{
+			Asset:  strings.TrimSpace(balance.Asset),
+			Amount: normalizeAmount(balance.AmountWei),
+		})
 	}
+	return position
 }
 
 func formatUint(value uint64) string {
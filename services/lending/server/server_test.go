@@ -618,6 +618,34 @@ func TestService_GetPosition(t *testing.T) {
 			t.Fatalf("unexpected health factor: %q", pos.GetHealthFactor())
 		}
 	})
+
+	t.Run("multi-asset collateral", func(t *testing.T) {
+		t.Parallel()
+
+		svc := &Service{engine: &fakeEngine{getPositionFn: func(context.Context, string, string) (engine.Position, error) {
+			return engine.Position{Account: &engine.AccountSnapshot{
+				Address: "bob",
+				DebtNHB: "5",
+				Collateral: []engine.CollateralBalance{
+					{Asset: "NHB", AmountWei: "4"},
+					{Asset: "ZNHB", AmountWei: "6"},
+				},
+			}}, nil
+		}}}
+
+		resp, err := svc.GetPosition(ctx, &lendingv1.GetPositionRequest{Account: "bob"})
+		if err != nil {
+			t.Fatalf("expected success, got %v", err)
+		}
+		pos := resp.GetPosition()
+		if pos.GetCollateral() != "10" || pos.GetHealthFactor() != "2" {
+			t.Fatalf("unexpected totals: collateral=%q health=%q", pos.GetCollateral(), pos.GetHealthFactor())
+		}
+		balances := pos.GetCollateralBalances()
+		if len(balances) != 2 || balances[1].GetAsset() != "ZNHB" || balances[1].GetAmount() != "6" {
+			t.Fatalf("unexpected collateral balances: %v", balances)
+		}
+	})
 }
 
 func TestService_EnsureEngine(t *testing.T) {
@@ -33,8 +33,8 @@ type MintInvoiceRow struct {
 	QuoteExpiry        string `json:"quoteExpiry"`
 	CreatedAt          string `json:"createdAt"`
 	UpdatedAt          string `json:"updatedAt"`
-	NowPaymentsID      string `json:"nowpaymentsId"`
-	NowPaymentsURL     string `json:"nowpaymentsUrl"`
+	ProviderRef        string `json:"providerReference"`
+	PaymentURL         string `json:"paymentUrl"`
 	TxHash             string `json:"txHash,omitempty"`
 }
 
@@ -104,7 +104,7 @@ func (r *MintReader) Close() error {
 // List returns mint-side reconciliation rows.
 func (r *MintReader) List(ctx context.Context, filter MintFilter) ([]MintInvoiceRow, error) {
 	query := `
-SELECT i.id, i.quote_id, i.recipient, i.status, i.nowpayments_id, i.nowpayments_url, i.tx_hash, i.created_at, i.updated_at,
+SELECT i.id, i.quote_id, i.recipient, i.status, i.provider_ref, i.provider_url, i.tx_hash, i.created_at, i.updated_at,
        q.fiat_currency, q.token, q.mint_asset, q.pay_currency, q.amount_fiat, q.service_fee_fiat, q.total_fiat, q.amount_token, q.estimated_pay_amount, q.expiry
 FROM invoices i
 JOIN quotes q ON q.id = i.quote_id
@@ -152,14 +152,14 @@ JOIN quotes q ON q.id = i.quote_id
 	for rows.Next() {
 		var item MintInvoiceRow
 		var quoteExpiry, createdAt, updatedAt time.Time
-		var txHash sql.NullString
+		var txHash, providerRef, paymentURL sql.NullString
 		if err := rows.Scan(
 			&item.InvoiceID,
 			&item.QuoteID,
 			&item.Recipient,
 			&item.Status,
-			&item.NowPaymentsID,
-			&item.NowPaymentsURL,
+			&providerRef,
+			&paymentURL,
 			&txHash,
 			&createdAt,
 			&updatedAt,
@@ -188,6 +188,8 @@ JOIN quotes q ON q.id = i.quote_id
 		item.QuoteExpiry = quoteExpiry.UTC().Format(time.RFC3339)
 		item.CreatedAt = createdAt.UTC().Format(time.RFC3339)
 		item.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+		item.ProviderRef = providerRef.String
+		item.PaymentURL = paymentURL.String
 		if txHash.Valid {
 			item.TxHash = txHash.String
 		}
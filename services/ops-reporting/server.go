@@ -375,7 +375,7 @@ func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
 
 func marshalMintCSV(items []MintInvoiceRow) ([]byte, error) {
 	var builder strings.Builder
-	builder.WriteString("invoice_id,quote_id,recipient,status,fiat,token,mint_asset,pay_currency,amount_fiat,service_fee_fiat,total_fiat,amount_token,estimated_pay_amount,quote_expiry,created_at,updated_at,nowpayments_id,nowpayments_url,tx_hash\n")
+	builder.WriteString("invoice_id,quote_id,recipient,status,fiat,token,mint_asset,pay_currency,amount_fiat,service_fee_fiat,total_fiat,amount_token,estimated_pay_amount,quote_expiry,created_at,updated_at,provider_ref,provider_url,tx_hash\n")
 	for _, item := range items {
 		builder.WriteString(strings.Join([]string{
 			csvEscape(item.InvoiceID),
@@ -394,8 +394,8 @@ func marshalMintCSV(items []MintInvoiceRow) ([]byte, error) {
 			csvEscape(item.QuoteExpiry),
 			csvEscape(item.CreatedAt),
 			csvEscape(item.UpdatedAt),
-			csvEscape(item.NowPaymentsID),
-			csvEscape(item.NowPaymentsURL),
+			csvEscape(item.ProviderRef),
+			csvEscape(item.PaymentURL),
 			csvEscape(item.TxHash),
 		}, ","))
 		builder.WriteString("\n")
@@ -311,8 +311,8 @@ func seedPaymentsDB(path string) error {
 			quote_id TEXT NOT NULL,
 			recipient TEXT NOT NULL,
 			status TEXT NOT NULL,
-			nowpayments_id TEXT,
-			nowpayments_url TEXT,
+			provider_ref TEXT,
+			provider_url TEXT,
 			tx_hash TEXT,
 			created_at TIMESTAMP NOT NULL,
 			updated_at TIMESTAMP NOT NULL,
@@ -339,13 +339,13 @@ func seedPaymentsDB(path string) error {
 		return err
 	}
 	if _, err := db.ExecContext(context.Background(),
-		`INSERT INTO invoices(id, quote_id, recipient, status, nowpayments_id, nowpayments_url, tx_hash, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO invoices(id, quote_id, recipient, status, provider_ref, provider_url, tx_hash, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		"invoice-1", "quote-1", "nhb1merchant", "minted", "np-1", "https://nowpayments.example/invoice/np-1", "0xabc123", base.Add(time.Minute), base.Add(3*time.Minute),
 	); err != nil {
 		return err
 	}
 	if _, err := db.ExecContext(context.Background(),
-		`INSERT INTO invoices(id, quote_id, recipient, status, nowpayments_id, nowpayments_url, tx_hash, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO invoices(id, quote_id, recipient, status, provider_ref, provider_url, tx_hash, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		"invoice-2", "quote-2", "nhb1buyer", "pending", "np-2", "https://nowpayments.example/invoice/np-2", nil, base.Add(2*time.Minute), base.Add(4*time.Minute),
 	); err != nil {
 		return err
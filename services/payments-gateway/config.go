@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
@@ -26,6 +27,9 @@ type Config struct {
 	NowPaymentsBaseURL   string
 	MinterKMSEnv         string
 	PublicIPNCallbackURL string
+	BankInstructions     map[string]string
+	ProviderRoutes       string
+	OperatorToken        string
 }
 
 const (
@@ -44,6 +48,9 @@ const (
 	envNowBaseURL      = "PAY_GATEWAY_NOW_BASE"
 	envKMSEnv          = "PAY_GATEWAY_MINTER_KMS_ENV"
 	envIPNCallbackURL  = "PAY_GATEWAY_PUBLIC_IPN_URL"
+	envBankInstr       = "PAY_GATEWAY_BANK_INSTRUCTIONS"
+	envProviderRoutes  = "PAY_GATEWAY_PROVIDER_ROUTES"
+	envOperatorToken   = "PAY_GATEWAY_OPERATOR_TOKEN"
 )
 
 // LoadConfigFromEnv resolves configuration from environment variables with sane defaults.
@@ -67,17 +74,29 @@ func LoadConfigFromEnv() (*Config, error) {
 		// Optional: if unset, NOWPayments falls back to whichever IPN URL is
 		// configured in the merchant dashboard for the account.
 		PublicIPNCallbackURL: strings.TrimSpace(os.Getenv(envIPNCallbackURL)),
+		ProviderRoutes:       strings.TrimSpace(os.Getenv(envProviderRoutes)),
+		OperatorToken:        strings.TrimSpace(os.Getenv(envOperatorToken)),
+	}
+	if raw := strings.TrimSpace(os.Getenv(envBankInstr)); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.BankInstructions); err != nil {
+			return nil, fmt.Errorf("%s must be a JSON object of strings: %w", envBankInstr, err)
+		}
 	}
 
 	if cfg.NodeURL == "" {
 		return nil, fmt.Errorf("%s is required", envNodeURL)
 	}
-	if cfg.NowPaymentsAPIKey == "" {
+	// NOWPayments is optional once another provider is configured, but its
+	// IPN secret is mandatory whenever the API key is set.
+	if cfg.NowPaymentsAPIKey == "" && len(cfg.BankInstructions) == 0 {
 		return nil, fmt.Errorf("%s is required", envNowAPIKey)
 	}
-	if cfg.NowPaymentsIPNSecret == "" {
+	if cfg.NowPaymentsAPIKey != "" && cfg.NowPaymentsIPNSecret == "" {
 		return nil, fmt.Errorf("%s is required", envNowIPNSecret)
 	}
+	if len(cfg.BankInstructions) > 0 && cfg.OperatorToken == "" {
+		return nil, fmt.Errorf("%s is required when %s is set", envOperatorToken, envBankInstr)
+	}
 	if cfg.MinterKMSEnv == "" {
 		return nil, fmt.Errorf("%s is required", envKMSEnv)
 	}
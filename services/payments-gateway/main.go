@@ -63,10 +63,15 @@ func main() {
 	if err != nil {
 		log.Fatalf("configure kms signer: %v", err)
 	}
-	nowClient := NewHTTPNowPaymentsClient(cfg.NowPaymentsBaseURL, cfg.NowPaymentsAPIKey)
+	providers, err := buildProviderRouter(cfg)
+	if err != nil {
+		log.Fatalf("configure payment providers: %v", err)
+	}
+	log.Printf("payment providers: %s", strings.Join(providers.Names(), ", "))
 	nodeClient := NewRPCNodeClient(cfg.NodeURL, cfg.NodeAuthToken)
 
-	server := NewServer(store, oracle, nowClient, nodeClient, signer, cfg.QuoteTTL, cfg.QuoteCurrency, cfg.DefaultMintAsset, cfg.ServiceFeeBps, cfg.NowPaymentsIPNSecret, cfg.PublicIPNCallbackURL)
+	server := NewServer(store, oracle, providers, nodeClient, signer, cfg.QuoteTTL, cfg.QuoteCurrency, cfg.DefaultMintAsset, cfg.ServiceFeeBps)
+	server.SetOperatorToken(cfg.OperatorToken)
 	srv := &http.Server{Addr: cfg.ListenAddress, Handler: otelhttp.NewHandler(server, "payments-gateway")}
 
 	go func() {
@@ -87,3 +92,32 @@ func main() {
 		log.Printf("graceful shutdown failed: %v", err)
 	}
 }
+
+// buildProviderRouter registers the configured payment providers. NOWPayments,
+// when enabled, is the fallback for routes without an explicit entry.
+func buildProviderRouter(cfg *Config) (*ProviderRouter, error) {
+	var providers []PaymentProvider
+	if cfg.NowPaymentsAPIKey != "" {
+		nowClient := NewHTTPNowPaymentsClient(cfg.NowPaymentsBaseURL, cfg.NowPaymentsAPIKey)
+		provider, err := NewNowPaymentsProvider(nowClient, cfg.NowPaymentsIPNSecret, cfg.PublicIPNCallbackURL)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+	if len(cfg.BankInstructions) > 0 {
+		provider, err := NewManualBankTransferProvider(cfg.BankInstructions)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+	router, err := NewProviderRouter(providers...)
+	if err != nil {
+		return nil, err
+	}
+	if err := router.ParseRoutes(cfg.ProviderRoutes); err != nil {
+		return nil, err
+	}
+	return router, nil
+}
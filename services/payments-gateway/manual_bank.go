@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ProviderManualBank identifies the manual bank transfer provider.
+const ProviderManualBank = "manual_bank"
+
+// ManualBankTransferProvider issues bank transfer instructions and relies on
+// an operator to confirm that the funds arrived before the mint is released.
+type ManualBankTransferProvider struct {
+	instructions map[string]string
+}
+
+// NewManualBankTransferProvider returns a provider that hands payers the
+// supplied bank details (for example account name, IBAN and BIC).
+func NewManualBankTransferProvider(instructions map[string]string) (*ManualBankTransferProvider, error) {
+	if len(instructions) == 0 {
+		return nil, errors.New("bank transfer instructions required")
+	}
+	copied := make(map[string]string, len(instructions))
+	for key, value := range instructions {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		copied[key] = strings.TrimSpace(value)
+	}
+	return &ManualBankTransferProvider{instructions: copied}, nil
+}
+
+// Name implements PaymentProvider.
+func (p *ManualBankTransferProvider) Name() string { return ProviderManualBank }
+
+// Estimate implements PaymentProvider. Bank transfers settle in the quoted
+// fiat currency, so no conversion is offered.
+func (p *ManualBankTransferProvider) Estimate(_ context.Context, fiatCurrency, totalFiat, payCurrency string) (string, error) {
+	if !strings.EqualFold(strings.TrimSpace(fiatCurrency), strings.TrimSpace(payCurrency)) {
+		return "", fmt.Errorf("bank transfers must be paid in %s", strings.ToUpper(fiatCurrency))
+	}
+	return totalFiat, nil
+}
+
+// CreateInvoice implements PaymentProvider. The returned reference must be
+// quoted by the payer so the operator can match the incoming transfer.
+func (p *ManualBankTransferProvider) CreateInvoice(_ context.Context, req ProviderInvoiceRequest) (*ProviderInvoice, error) {
+	if !strings.EqualFold(req.FiatCurrency, req.PayCurrency) {
+		return nil, fmt.Errorf("bank transfers must be paid in %s", strings.ToUpper(req.FiatCurrency))
+	}
+	reference := "NHB-" + strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")[:12])
+	instructions := make(map[string]string, len(p.instructions)+3)
+	for key, value := range p.instructions {
+		instructions[key] = value
+	}
+	instructions["reference"] = reference
+	instructions["amount"] = req.TotalFiat
+	instructions["currency"] = strings.ToUpper(req.FiatCurrency)
+	return &ProviderInvoice{Reference: reference, Instructions: instructions}, nil
+}
+
+// VerifyWebhook implements PaymentProvider. Bank transfers are confirmed
+// through the operator API rather than callbacks.
+func (p *ManualBankTransferProvider) VerifyWebhook(*http.Request, []byte) (ProviderEvent, error) {
+	return ProviderEvent{}, ErrWebhooksUnsupported
+}
+
+// InvoiceStatus implements PaymentProvider. Nothing can be observed until an
+// operator confirms the transfer.
+func (p *ManualBankTransferProvider) InvoiceStatus(context.Context, string) (ProviderStatus, error) {
+	return ProviderStatusPending, nil
+}
+
+// ConfirmSettlement implements OperatorSettledProvider. The operator must
+// supply the bank's transaction reference and at least the quoted total in
+// the quoted currency.
+func (p *ManualBankTransferProvider) ConfirmSettlement(_ context.Context, quote *QuoteRecord, confirmation OperatorConfirmation) error {
+	if quote == nil {
+		return errors.New("quote required")
+	}
+	if strings.TrimSpace(confirmation.Reference) == "" {
+		return errors.New("settlement reference required")
+	}
+	if !strings.EqualFold(strings.TrimSpace(confirmation.Currency), quote.FiatCurrency) {
+		return fmt.Errorf("settlement currency must be %s", quote.FiatCurrency)
+	}
+	received, ok := new(big.Rat).SetString(strings.TrimSpace(confirmation.AmountReceived))
+	if !ok {
+		return fmt.Errorf("invalid amountReceived: %s", confirmation.AmountReceived)
+	}
+	due, ok := new(big.Rat).SetString(quote.TotalFiat)
+	if !ok {
+		return fmt.Errorf("invalid quote total: %s", quote.TotalFiat)
+	}
+	if received.Cmp(due) < 0 {
+		return fmt.Errorf("amount received %s is below the invoice total %s", confirmation.AmountReceived, quote.TotalFiat)
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -200,3 +203,150 @@ func (c *HTTPNowPaymentsClient) doRequest(ctx context.Context, method, path stri
 	}
 	return &invoice, nil
 }
+
+const (
+	// ProviderNowPayments identifies the NOWPayments provider.
+	ProviderNowPayments = "nowpayments"
+
+	headerNowPaymentsSig  = "X-Nowpayments-Signature"
+	headerNowPaymentsSig2 = "x-nowpayments-sig"
+)
+
+// NowPaymentsWebhookPayload models the minimal webhook structure.
+type NowPaymentsWebhookPayload struct {
+	InvoiceID     string `json:"invoice_id"`
+	PaymentStatus string `json:"payment_status"`
+	Status        string `json:"status"`
+}
+
+// NowPaymentsProvider adapts the NOWPayments API to PaymentProvider.
+type NowPaymentsProvider struct {
+	client         NowPaymentsClient
+	ipnSecret      []byte
+	ipnCallbackURL string
+}
+
+// NewNowPaymentsProvider wraps a NOWPayments client. ipnSecret authenticates
+// IPN callbacks; ipnCallbackURL is optional and falls back to the URL
+// configured in the merchant dashboard.
+func NewNowPaymentsProvider(client NowPaymentsClient, ipnSecret, ipnCallbackURL string) (*NowPaymentsProvider, error) {
+	if client == nil {
+		return nil, fmt.Errorf("nowpayments client required")
+	}
+	secret := []byte(strings.TrimSpace(ipnSecret))
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("nowpayments ipn secret required")
+	}
+	return &NowPaymentsProvider{
+		client:         client,
+		ipnSecret:      secret,
+		ipnCallbackURL: strings.TrimSpace(ipnCallbackURL),
+	}, nil
+}
+
+// Name implements PaymentProvider.
+func (p *NowPaymentsProvider) Name() string { return ProviderNowPayments }
+
+// Estimate implements PaymentProvider.
+func (p *NowPaymentsProvider) Estimate(ctx context.Context, fiatCurrency, totalFiat, payCurrency string) (string, error) {
+	estimate, err := p.client.Estimate(ctx, &NowPaymentsEstimateRequest{
+		Amount:       totalFiat,
+		CurrencyFrom: fiatCurrency,
+		CurrencyTo:   payCurrency,
+	})
+	if err != nil {
+		return "", err
+	}
+	amount := strings.TrimSpace(firstNonEmpty(string(estimate.EstimatedAmount), string(estimate.AmountTo)))
+	if amount == "" {
+		return "", fmt.Errorf("nowpayments estimate returned empty amount")
+	}
+	return amount, nil
+}
+
+// CreateInvoice implements PaymentProvider.
+func (p *NowPaymentsProvider) CreateInvoice(ctx context.Context, req ProviderInvoiceRequest) (*ProviderInvoice, error) {
+	invoice, err := p.client.CreateInvoice(ctx, &NowPaymentsInvoiceRequest{
+		PriceAmount:   req.TotalFiat,
+		PriceCurrency: req.FiatCurrency,
+		PayCurrency:   req.PayCurrency,
+		OrderID:       req.InvoiceID,
+		OrderDesc:     req.Description,
+		FixedRate:     true,
+		// The swapper bears NOWPayments' processing cost, not the NHBCoin
+		// treasury: NOWPayments grosses up what the payer is asked to send
+		// so the merchant account still receives TotalFiat in full.
+		IsFeePaidByUser: true,
+		IpnCallbackURL:  p.ipnCallbackURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderInvoice{
+		Reference:  firstNonEmpty(invoice.InvoiceID, invoice.ID),
+		PaymentURL: invoice.InvoiceURL,
+	}, nil
+}
+
+// VerifyWebhook implements PaymentProvider by checking the IPN HMAC-SHA512
+// signature.
+func (p *NowPaymentsProvider) VerifyWebhook(r *http.Request, body []byte) (ProviderEvent, error) {
+	sig := strings.TrimSpace(r.Header.Get(headerNowPaymentsSig))
+	if sig == "" {
+		sig = strings.TrimSpace(r.Header.Get(headerNowPaymentsSig2))
+	}
+	if !p.verifyHMAC(body, sig) {
+		return ProviderEvent{}, ErrInvalidWebhookSignature
+	}
+	var payload NowPaymentsWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ProviderEvent{}, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	return ProviderEvent{Reference: strings.TrimSpace(payload.InvoiceID)}, nil
+}
+
+// InvoiceStatus implements PaymentProvider. The webhook body is never
+// trusted for status; it is always re-fetched from the API.
+func (p *NowPaymentsProvider) InvoiceStatus(ctx context.Context, reference string) (ProviderStatus, error) {
+	invoice, err := p.client.GetInvoice(ctx, reference)
+	if err != nil {
+		return "", err
+	}
+	return invoice.ProviderStatus(), nil
+}
+
+// ProviderStatus maps the NOWPayments payment status onto ProviderStatus.
+func (i *NowPaymentsInvoice) ProviderStatus() ProviderStatus {
+	if i.Paid() {
+		return ProviderStatusPaid
+	}
+	status := strings.ToLower(strings.TrimSpace(i.PaymentStatus))
+	if status == "" {
+		status = strings.ToLower(strings.TrimSpace(i.Status))
+	}
+	switch status {
+	case "", "waiting", "new":
+		return ProviderStatusPending
+	case "failed", "expired", "refunded":
+		return ProviderStatusFailed
+	default:
+		return ProviderStatusProcessing
+	}
+}
+
+func (p *NowPaymentsProvider) verifyHMAC(body []byte, signature string) bool {
+	if strings.TrimSpace(signature) == "" {
+		return false
+	}
+	mac := hmac.New(sha512.New, p.ipnSecret)
+	mac.Write(body)
+	expected := mac.Sum(nil)
+	decoded, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return false
+	}
+	if len(decoded) != len(expected) {
+		return false
+	}
+	return hmac.Equal(decoded, expected)
+}
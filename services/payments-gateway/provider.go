@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrInvalidWebhookSignature is returned when a provider callback fails authentication.
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	// ErrWebhooksUnsupported is returned by providers that do not settle through callbacks.
+	ErrWebhooksUnsupported = errors.New("provider does not accept webhooks")
+	// ErrNoProviderRoute is returned when no provider serves a fiat/currency pair.
+	ErrNoProviderRoute = errors.New("no payment provider for route")
+)
+
+// ProviderStatus is a processor-neutral view of an invoice's payment state.
+type ProviderStatus string
+
+const (
+	// ProviderStatusPending means the payer has not paid yet.
+	ProviderStatusPending ProviderStatus = "pending"
+	// ProviderStatusProcessing means a payment was seen but is not final.
+	ProviderStatusProcessing ProviderStatus = "processing"
+	// ProviderStatusPaid means funds are settled and the mint may proceed.
+	ProviderStatusPaid ProviderStatus = "paid"
+	// ProviderStatusFailed means the payment failed, expired or was refunded.
+	ProviderStatusFailed ProviderStatus = "failed"
+)
+
+// InvoiceRecordStatus maps a provider status to the status stored on an
+// InvoiceRecord before minting.
+func (s ProviderStatus) InvoiceRecordStatus() string {
+	switch s {
+	case ProviderStatusPending:
+		return "pending"
+	case ProviderStatusFailed:
+		return "failed"
+	default:
+		return "processing"
+	}
+}
+
+// ProviderInvoiceRequest describes the invoice a provider should open.
+type ProviderInvoiceRequest struct {
+	InvoiceID    string
+	FiatCurrency string
+	TotalFiat    string
+	PayCurrency  string
+	Description  string
+}
+
+// ProviderInvoice is the provider's view of a newly opened invoice.
+type ProviderInvoice struct {
+	// Reference identifies the invoice at the provider and is how
+	// callbacks are matched back to the InvoiceRecord.
+	Reference string
+	// PaymentURL is where the payer completes the payment, if any.
+	PaymentURL string
+	// Instructions carries out-of-band payment details such as bank
+	// account data for providers without a hosted checkout.
+	Instructions map[string]string
+}
+
+// ProviderEvent is an authenticated provider callback.
+type ProviderEvent struct {
+	Reference string
+}
+
+// PaymentProvider abstracts a payment processor used to fund mints.
+type PaymentProvider interface {
+	// Name is the stable identifier used in routes, webhook paths and
+	// stored invoices.
+	Name() string
+	// Estimate converts totalFiat into the amount the payer sends in
+	// payCurrency.
+	Estimate(ctx context.Context, fiatCurrency, totalFiat, payCurrency string) (string, error)
+	// CreateInvoice opens an invoice with the provider.
+	CreateInvoice(ctx context.Context, req ProviderInvoiceRequest) (*ProviderInvoice, error)
+	// VerifyWebhook authenticates a callback and extracts the invoice
+	// reference it concerns.
+	VerifyWebhook(r *http.Request, body []byte) (ProviderEvent, error)
+	// InvoiceStatus fetches the authoritative status for reference.
+	InvoiceStatus(ctx context.Context, reference string) (ProviderStatus, error)
+}
+
+// OperatorSettledProvider is implemented by providers whose payments are
+// confirmed by an operator instead of a processor callback.
+type OperatorSettledProvider interface {
+	PaymentProvider
+	// ConfirmSettlement validates an operator's confirmation against the
+	// quoted invoice before the mint is released.
+	ConfirmSettlement(ctx context.Context, quote *QuoteRecord, confirmation OperatorConfirmation) error
+}
+
+// OperatorConfirmation is the operator's evidence that funds were received.
+type OperatorConfirmation struct {
+	Reference      string `json:"reference"`
+	AmountReceived string `json:"amountReceived"`
+	Currency       string `json:"currency"`
+}
+
+// ProviderRouter selects a payment provider for each fiat/pay currency route.
+type ProviderRouter struct {
+	providers map[string]PaymentProvider
+	routes    map[string]string
+	fallback  string
+}
+
+// NewProviderRouter registers providers. The first provider is the fallback
+// for routes without an explicit entry.
+func NewProviderRouter(providers ...PaymentProvider) (*ProviderRouter, error) {
+	router := &ProviderRouter{
+		providers: make(map[string]PaymentProvider, len(providers)),
+		routes:    make(map[string]string),
+	}
+	for _, provider := range providers {
+		if provider == nil {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(provider.Name()))
+		if name == "" {
+			return nil, errors.New("payment provider name required")
+		}
+		if _, exists := router.providers[name]; exists {
+			return nil, fmt.Errorf("duplicate payment provider %q", name)
+		}
+		router.providers[name] = provider
+		if router.fallback == "" {
+			router.fallback = name
+		}
+	}
+	if len(router.providers) == 0 {
+		return nil, errors.New("at least one payment provider required")
+	}
+	return router, nil
+}
+
+// SetRoute sends invoices for fiat paid in payCurrency to the named
+// provider. Either side may be "*" to match any currency.
+func (r *ProviderRouter) SetRoute(fiat, payCurrency, provider string) error {
+	name := strings.ToLower(strings.TrimSpace(provider))
+	if _, ok := r.providers[name]; !ok {
+		return fmt.Errorf("unknown payment provider %q", provider)
+	}
+	r.routes[routeKey(fiat, payCurrency)] = name
+	return nil
+}
+
+// ParseRoutes applies a comma separated route list such as
+// "USD:BTC=nowpayments,EUR:*=manual_bank".
+func (r *ProviderRouter) ParseRoutes(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, provider, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid provider route %q", entry)
+		}
+		fiat, payCurrency, ok := strings.Cut(route, ":")
+		if !ok {
+			return fmt.Errorf("invalid provider route %q", entry)
+		}
+		if err := r.SetRoute(fiat, payCurrency, provider); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resolve returns the provider for a route, preferring an exact match, then
+// a fiat wildcard, then a global wildcard and finally the fallback provider.
+func (r *ProviderRouter) Resolve(fiat, payCurrency string) (PaymentProvider, error) {
+	candidates := []string{
+		routeKey(fiat, payCurrency),
+		routeKey(fiat, "*"),
+		routeKey("*", payCurrency),
+		routeKey("*", "*"),
+	}
+	for _, key := range candidates {
+		if name, ok := r.routes[key]; ok {
+			return r.providers[name], nil
+		}
+	}
+	if provider, ok := r.providers[r.fallback]; ok {
+		return provider, nil
+	}
+	return nil, fmt.Errorf("%w %s/%s", ErrNoProviderRoute, fiat, payCurrency)
+}
+
+// Provider returns a registered provider by name.
+func (r *ProviderRouter) Provider(name string) (PaymentProvider, bool) {
+	provider, ok := r.providers[strings.ToLower(strings.TrimSpace(name))]
+	return provider, ok
+}
+
+// Names lists the registered providers.
+func (r *ProviderRouter) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func routeKey(fiat, payCurrency string) string {
+	return strings.ToUpper(strings.TrimSpace(fiat)) + ":" + strings.ToUpper(strings.TrimSpace(payCurrency))
+}
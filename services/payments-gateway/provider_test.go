@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderRouterResolve(t *testing.T) {
+	np, err := NewNowPaymentsProvider(&stubNowPayments{}, "secret", "")
+	if err != nil {
+		t.Fatalf("nowpayments provider: %v", err)
+	}
+	bank, err := NewManualBankTransferProvider(map[string]string{"iban": "DE00"})
+	if err != nil {
+		t.Fatalf("bank provider: %v", err)
+	}
+	router, err := NewProviderRouter(np, bank)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	if err := router.ParseRoutes("usd:usd=manual_bank, EUR:*=manual_bank"); err != nil {
+		t.Fatalf("parse routes: %v", err)
+	}
+	cases := []struct {
+		fiat, pay, want string
+	}{
+		{"USD", "USD", ProviderManualBank},
+		{"USD", "BTC", ProviderNowPayments},
+		{"EUR", "USDT", ProviderManualBank},
+	}
+	for _, tc := range cases {
+		provider, err := router.Resolve(tc.fiat, tc.pay)
+		if err != nil {
+			t.Fatalf("resolve %s/%s: %v", tc.fiat, tc.pay, err)
+		}
+		if provider.Name() != tc.want {
+			t.Fatalf("resolve %s/%s: got %s want %s", tc.fiat, tc.pay, provider.Name(), tc.want)
+		}
+	}
+	if err := router.ParseRoutes("USD:BTC=stripe"); err == nil {
+		t.Fatalf("expected unknown provider to be rejected")
+	}
+	if err := router.ParseRoutes("USD=manual_bank"); err == nil {
+		t.Fatalf("expected malformed route to be rejected")
+	}
+}
+
+func TestManualBankInvoiceConfirmedByOperator(t *testing.T) {
+	store := newTestStore(t)
+	t.Cleanup(func() { store.Close() })
+	np := &stubNowPayments{}
+	node := &stubNode{}
+	srv := newTestServer(t, store, np, node, &stubSigner{})
+	bank, err := NewManualBankTransferProvider(map[string]string{"accountName": "NHB Treasury", "iban": "DE89370400440532013000"})
+	if err != nil {
+		t.Fatalf("bank provider: %v", err)
+	}
+	router, err := NewProviderRouter(srv.providers.providers[ProviderNowPayments], bank)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	if err := router.SetRoute("USD", "USD", ProviderManualBank); err != nil {
+		t.Fatalf("set route: %v", err)
+	}
+	srv.providers = router
+	srv.SetOperatorToken("ops-token")
+
+	quoteRes := httptest.NewRecorder()
+	srv.ServeHTTP(quoteRes, httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewReader([]byte(`{"fiat":"USD","mintAsset":"NHB","payCurrency":"USD","amountMint":"40"}`))))
+	if quoteRes.Code != http.StatusOK {
+		t.Fatalf("quote failed: %s", quoteRes.Body.String())
+	}
+	var quote QuoteResponse
+	if err := json.Unmarshal(quoteRes.Body.Bytes(), &quote); err != nil {
+		t.Fatalf("decode quote: %v", err)
+	}
+
+	invReq := httptest.NewRequest(http.MethodPost, "/invoices", bytes.NewReader([]byte(`{"quoteId":"`+quote.QuoteID+`","recipient":"nhb1carol"}`)))
+	invReq.Header.Set(headerIdempotencyKey, "bank-1")
+	invRes := httptest.NewRecorder()
+	srv.ServeHTTP(invRes, invReq)
+	if invRes.Code != http.StatusOK {
+		t.Fatalf("invoice create failed: %s", invRes.Body.String())
+	}
+	var invoice struct {
+		InvoiceID    string            `json:"invoiceId"`
+		Provider     string            `json:"provider"`
+		Instructions map[string]string `json:"instructions"`
+	}
+	if err := json.Unmarshal(invRes.Body.Bytes(), &invoice); err != nil {
+		t.Fatalf("decode invoice: %v", err)
+	}
+	if invoice.Provider != ProviderManualBank {
+		t.Fatalf("expected manual bank provider, got %s", invoice.Provider)
+	}
+	if invoice.Instructions["iban"] != "DE89370400440532013000" || invoice.Instructions["amount"] != "40" || invoice.Instructions["reference"] == "" {
+		t.Fatalf("unexpected instructions %+v", invoice.Instructions)
+	}
+	if np.createCalls != 0 {
+		t.Fatalf("nowpayments must not be called for bank transfers")
+	}
+
+	confirm := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/operator/invoices/"+invoice.InvoiceID+"/confirm", bytes.NewReader([]byte(body)))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res := httptest.NewRecorder()
+		srv.ServeHTTP(res, req)
+		return res
+	}
+	if res := confirm("wrong", `{"reference":"BANK-1","amountReceived":"40","currency":"USD"}`); res.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong token, got %d", res.Code)
+	}
+	if res := confirm("ops-token", `{"reference":"BANK-1","amountReceived":"39.99","currency":"USD"}`); res.Code != http.StatusBadRequest {
+		t.Fatalf("expected underpayment to be rejected, got %d: %s", res.Code, res.Body.String())
+	}
+	if node.callCount != 0 {
+		t.Fatalf("mint must not happen before confirmation")
+	}
+	res := confirm("ops-token", `{"reference":"BANK-1","amountReceived":"40.00","currency":"usd"}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("confirm failed: %d %s", res.Code, res.Body.String())
+	}
+	if node.callCount != 1 || node.lastVoucher.InvoiceID != invoice.InvoiceID || node.lastVoucher.Amount != "40" {
+		t.Fatalf("unexpected mint %+v (calls %d)", node.lastVoucher, node.callCount)
+	}
+	stored, err := store.GetInvoice(context.Background(), invoice.InvoiceID)
+	if err != nil {
+		t.Fatalf("get invoice: %v", err)
+	}
+	if stored.Status != "minted" || stored.SettlementReference != "BANK-1" || stored.Provider != ProviderManualBank {
+		t.Fatalf("unexpected stored invoice %+v", stored)
+	}
+	if res := confirm("ops-token", `{"reference":"BANK-1","amountReceived":"40","currency":"USD"}`); res.Code != http.StatusOK || node.callCount != 1 {
+		t.Fatalf("expected repeated confirmation to be a no-op, got %d (calls %d)", res.Code, node.callCount)
+	}
+
+	whRes := httptest.NewRecorder()
+	srv.ServeHTTP(whRes, httptest.NewRequest(http.MethodPost, "/webhooks/manual_bank", bytes.NewReader([]byte(`{}`))))
+	if whRes.Code != http.StatusNotFound {
+		t.Fatalf("expected bank webhooks to be rejected, got %d", whRes.Code)
+	}
+}
+
+func TestOperatorConfirmRejectsCallbackProviders(t *testing.T) {
+	store := newTestStore(t)
+	t.Cleanup(func() { store.Close() })
+	np := &stubNowPayments{
+		createFn: func(ctx context.Context, req *NowPaymentsInvoiceRequest) (*NowPaymentsInvoice, error) {
+			return &NowPaymentsInvoice{InvoiceID: "np-op", InvoiceURL: "https://nowpay/invoice/np-op"}, nil
+		},
+	}
+	srv := newTestServer(t, store, np, &stubNode{}, &stubSigner{})
+
+	req := httptest.NewRequest(http.MethodPost, "/operator/invoices/any/confirm", bytes.NewReader([]byte(`{}`)))
+	res := httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected operator API disabled without token, got %d", res.Code)
+	}
+	srv.SetOperatorToken("ops-token")
+
+	quoteRes := httptest.NewRecorder()
+	srv.ServeHTTP(quoteRes, httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewReader([]byte(`{"fiat":"USD","mintAsset":"NHB","payCurrency":"BTC","amountMint":"10"}`))))
+	var quote QuoteResponse
+	if err := json.Unmarshal(quoteRes.Body.Bytes(), &quote); err != nil {
+		t.Fatalf("decode quote: %v", err)
+	}
+	invReq := httptest.NewRequest(http.MethodPost, "/invoices", bytes.NewReader([]byte(`{"quoteId":"`+quote.QuoteID+`","recipient":"nhb1dave"}`)))
+	invReq.Header.Set(headerIdempotencyKey, "np-op")
+	invRes := httptest.NewRecorder()
+	srv.ServeHTTP(invRes, invReq)
+	var invoice map[string]string
+	if err := json.Unmarshal(invRes.Body.Bytes(), &invoice); err != nil {
+		t.Fatalf("decode invoice: %v", err)
+	}
+	if invoice["provider"] != ProviderNowPayments || invoice["nowpaymentsUrl"] != "https://nowpay/invoice/np-op" {
+		t.Fatalf("unexpected invoice response %+v", invoice)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/operator/invoices/"+invoice["invoiceId"]+"/confirm", bytes.NewReader([]byte(`{"reference":"x","amountReceived":"10","currency":"USD"}`)))
+	req.Header.Set("Authorization", "Bearer ops-token")
+	res = httptest.NewRecorder()
+	srv.ServeHTTP(res, req)
+	if res.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for callback-settled provider, got %d", res.Code)
+	}
+}
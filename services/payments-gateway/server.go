@@ -2,9 +2,8 @@ package main
 
 import (
 	"context"
-	"crypto/hmac"
 	"crypto/sha256"
-	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -23,27 +22,25 @@ import (
 )
 
 const (
-	maxRequestBody        = 1 << 20
-	headerIdempotencyKey  = "Idempotency-Key"
-	headerNowPaymentsSig  = "X-Nowpayments-Signature"
-	headerNowPaymentsSig2 = "x-nowpayments-sig"
-	mintVoucherTTL        = 10 * time.Minute
+	maxRequestBody       = 1 << 20
+	headerIdempotencyKey = "Idempotency-Key"
+	mintVoucherTTL       = 10 * time.Minute
+	providerCallTimeout  = 10 * time.Second
 )
 
 // Server exposes HTTP endpoints for fiat-to-token flows.
 type Server struct {
 	store            *SQLiteStore
 	oracle           *Oracle
-	nowPayments      NowPaymentsClient
+	providers        *ProviderRouter
 	node             NodeClient
 	signer           Signer
 	quoteTTL         time.Duration
 	quoteCurrency    string
 	defaultMintAsset string
 	serviceFeeBps    int
-	hmacSecret       []byte
+	operatorToken    string
 	nowFn            func() time.Time
-	ipnCallbackURL   string
 }
 
 // QuoteRequest is the payload accepted by POST /quotes.
@@ -77,23 +74,16 @@ type InvoiceCreateRequest struct {
 	Recipient string `json:"recipient"`
 }
 
-// NowPaymentsWebhookPayload models the minimal webhook structure.
-type NowPaymentsWebhookPayload struct {
-	InvoiceID     string `json:"invoice_id"`
-	PaymentStatus string `json:"payment_status"`
-	Status        string `json:"status"`
-}
-
 // NewServer constructs a payments gateway server.
-func NewServer(store *SQLiteStore, oracle *Oracle, nowClient NowPaymentsClient, node NodeClient, signer Signer, quoteTTL time.Duration, quoteCurrency, defaultMintAsset string, serviceFeeBps int, hmacSecret string, ipnCallbackURL string) *Server {
+func NewServer(store *SQLiteStore, oracle *Oracle, providers *ProviderRouter, node NodeClient, signer Signer, quoteTTL time.Duration, quoteCurrency, defaultMintAsset string, serviceFeeBps int) *Server {
 	if store == nil {
 		panic("store required")
 	}
 	if oracle == nil {
 		panic("oracle required")
 	}
-	if nowClient == nil {
-		panic("nowpayments client required")
+	if providers == nil {
+		panic("payment providers required")
 	}
 	if node == nil {
 		panic("node client required")
@@ -101,10 +91,6 @@ func NewServer(store *SQLiteStore, oracle *Oracle, nowClient NowPaymentsClient,
 	if signer == nil {
 		panic("kms signer required")
 	}
-	secret := []byte(strings.TrimSpace(hmacSecret))
-	if len(secret) == 0 {
-		panic("hmac secret required")
-	}
 	if quoteTTL <= 0 {
 		quoteTTL = 5 * time.Minute
 	}
@@ -117,19 +103,23 @@ func NewServer(store *SQLiteStore, oracle *Oracle, nowClient NowPaymentsClient,
 	return &Server{
 		store:            store,
 		oracle:           oracle,
-		nowPayments:      nowClient,
+		providers:        providers,
 		node:             node,
 		signer:           signer,
 		quoteTTL:         quoteTTL,
 		quoteCurrency:    strings.ToUpper(strings.TrimSpace(quoteCurrency)),
 		defaultMintAsset: strings.ToUpper(strings.TrimSpace(defaultMintAsset)),
 		serviceFeeBps:    serviceFeeBps,
-		hmacSecret:       secret,
 		nowFn:            time.Now,
-		ipnCallbackURL:   strings.TrimSpace(ipnCallbackURL),
 	}
 }
 
+// SetOperatorToken enables the operator settlement endpoints guarded by the
+// supplied bearer token. An empty token disables them.
+func (s *Server) SetOperatorToken(token string) {
+	s.operatorToken = strings.TrimSpace(token)
+}
+
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch {
 	case r.Method == http.MethodPost && (r.URL.Path == "/quotes" || r.URL.Path == "/swap/quotes"):
@@ -144,8 +134,10 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.handleReconciliationSummary(w, r)
 	case r.Method == http.MethodGet && r.URL.Path == "/reconciliation/export":
 		s.handleReconciliationExport(w, r)
-	case r.Method == http.MethodPost && r.URL.Path == "/webhooks/nowpayments":
-		s.handleNowPaymentsWebhook(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/webhooks/"):
+		s.handleProviderWebhook(w, r, strings.TrimPrefix(r.URL.Path, "/webhooks/"))
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/operator/invoices/") && strings.HasSuffix(r.URL.Path, "/confirm"):
+		s.handleOperatorConfirm(w, r)
 	default:
 		http.NotFound(w, r)
 	}
@@ -182,7 +174,12 @@ func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, r, http.StatusInternalServerError, err, body, nil)
 		return
 	}
-	estimatedPayAmount, err := s.estimatePayAmount(r.Context(), totalFiat, normalised.PayCurrency)
+	provider, err := s.providers.Resolve(s.quoteCurrency, normalised.PayCurrency)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err, body, nil)
+		return
+	}
+	estimatedPayAmount, err := s.estimatePayAmount(r.Context(), provider, totalFiat, normalised.PayCurrency)
 	if err != nil {
 		s.writeError(w, r, http.StatusBadGateway, err, body, nil)
 		return
@@ -200,6 +197,7 @@ func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
 		TotalFiat:          totalFiat,
 		AmountToken:        amountToken,
 		EstimatedPayAmount: estimatedPayAmount,
+		Provider:           provider.Name(),
 		Expiry:             expiry,
 		CreatedAt:          now,
 	}
@@ -310,26 +308,27 @@ func applyFeeToAmount(amountFiat string, feeBps int) (string, string, error) {
 	return formatRat(fee, 8), formatRat(total, 8), nil
 }
 
-func (s *Server) estimatePayAmount(ctx context.Context, totalFiat, payCurrency string) (string, error) {
+func (s *Server) estimatePayAmount(ctx context.Context, provider PaymentProvider, totalFiat, payCurrency string) (string, error) {
 	payCurrency = strings.ToUpper(strings.TrimSpace(payCurrency))
 	if payCurrency == "" || strings.EqualFold(payCurrency, s.quoteCurrency) {
 		return totalFiat, nil
 	}
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, providerCallTimeout)
 	defer cancel()
-	estimate, err := s.nowPayments.Estimate(ctx, &NowPaymentsEstimateRequest{
-		Amount:       totalFiat,
-		CurrencyFrom: s.quoteCurrency,
-		CurrencyTo:   payCurrency,
-	})
-	if err != nil {
-		return "", err
-	}
-	amount := strings.TrimSpace(firstNonEmpty(string(estimate.EstimatedAmount), string(estimate.AmountTo)))
-	if amount == "" {
-		return "", fmt.Errorf("nowpayments estimate returned empty amount")
+	return provider.Estimate(ctx, s.quoteCurrency, totalFiat, payCurrency)
+}
+
+// quoteProvider returns the provider a quote was priced with, resolving the
+// route for quotes created before providers were recorded.
+func (s *Server) quoteProvider(quote *QuoteRecord) (PaymentProvider, error) {
+	if name := strings.TrimSpace(quote.Provider); name != "" {
+		provider, ok := s.providers.Provider(name)
+		if !ok {
+			return nil, fmt.Errorf("payment provider %q is not configured", name)
+		}
+		return provider, nil
 	}
-	return amount, nil
+	return s.providers.Resolve(quote.FiatCurrency, quote.PayCurrency)
 }
 
 func convertQuote(price float64, amountFiat string) (string, error) {
@@ -412,47 +411,53 @@ func (s *Server) handleInvoiceCreate(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, r, http.StatusBadRequest, errors.New("quote expired"), body, nil)
 		return
 	}
+	provider, err := s.quoteProvider(quote)
+	if err != nil {
+		s.writeError(w, r, http.StatusServiceUnavailable, err, body, nil)
+		return
+	}
 	invoiceID := uuid.NewString()
-	npReq := &NowPaymentsInvoiceRequest{
-		PriceAmount:   quote.TotalFiat,
-		PriceCurrency: quote.FiatCurrency,
-		PayCurrency:   quote.PayCurrency,
-		OrderID:       invoiceID,
-		OrderDesc:     fmt.Sprintf("Mint %s %s via %s", quote.AmountToken, quote.MintAsset, quote.PayCurrency),
-		FixedRate:     true,
-		// The swapper bears NOWPayments' processing cost, not the NHBCoin
-		// treasury: NOWPayments grosses up what the payer is asked to send
-		// so the merchant account still receives TotalFiat in full.
-		IsFeePaidByUser: true,
-		IpnCallbackURL:  s.ipnCallbackURL,
-	}
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), providerCallTimeout)
 	defer cancel()
-	invoice, err := s.nowPayments.CreateInvoice(ctx, npReq)
+	invoice, err := provider.CreateInvoice(ctx, ProviderInvoiceRequest{
+		InvoiceID:    invoiceID,
+		FiatCurrency: quote.FiatCurrency,
+		TotalFiat:    quote.TotalFiat,
+		PayCurrency:  quote.PayCurrency,
+		Description:  fmt.Sprintf("Mint %s %s via %s", quote.AmountToken, quote.MintAsset, quote.PayCurrency),
+	})
 	if err != nil {
 		s.writeError(w, r, http.StatusBadGateway, err, body, nil)
 		return
 	}
-	nowID := firstNonEmpty(invoice.InvoiceID, invoice.ID)
 	record := InvoiceRecord{
-		ID:        invoiceID,
-		QuoteID:   quote.ID,
-		Recipient: req.Recipient,
-		Status:    "pending",
-		NowID:     nowID,
-		NowURL:    invoice.InvoiceURL,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:           invoiceID,
+		QuoteID:      quote.ID,
+		Recipient:    req.Recipient,
+		Status:       "pending",
+		Provider:     provider.Name(),
+		ProviderRef:  invoice.Reference,
+		PaymentURL:   invoice.PaymentURL,
+		Instructions: invoice.Instructions,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 	if err := s.store.InsertInvoice(r.Context(), record); err != nil {
 		s.writeError(w, r, http.StatusInternalServerError, err, body, nil)
 		return
 	}
-	resp := map[string]string{
-		"invoiceId":      record.ID,
-		"nowpaymentsUrl": record.NowURL,
-		"mintAsset":      quote.MintAsset,
-		"payCurrency":    quote.PayCurrency,
+	resp := map[string]interface{}{
+		"invoiceId":   record.ID,
+		"provider":    record.Provider,
+		"paymentUrl":  record.PaymentURL,
+		"mintAsset":   quote.MintAsset,
+		"payCurrency": quote.PayCurrency,
+	}
+	if record.Provider == ProviderNowPayments {
+		resp["nowpaymentsUrl"] = record.PaymentURL
+	}
+	if len(record.Instructions) > 0 {
+		resp["instructions"] = record.Instructions
 	}
 	respBody, _ := json.Marshal(resp)
 	if err := s.store.SaveIdempotency(r.Context(), key, requestHash, http.StatusOK, respBody); err != nil {
@@ -589,31 +594,34 @@ func (s *Server) handleReconciliationExport(w http.ResponseWriter, r *http.Reque
 	}
 }
 
-func (s *Server) handleNowPaymentsWebhook(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleProviderWebhook(w http.ResponseWriter, r *http.Request, name string) {
 	body, err := s.readBody(w, r)
 	if err != nil {
 		s.writeError(w, r, http.StatusBadRequest, err, body, nil)
 		return
 	}
-	sig := strings.TrimSpace(r.Header.Get(headerNowPaymentsSig))
-	if sig == "" {
-		sig = strings.TrimSpace(r.Header.Get(headerNowPaymentsSig2))
-	}
-	if !s.verifyHMAC(body, sig) {
-		s.writeError(w, r, http.StatusUnauthorized, errors.New("invalid webhook signature"), body, nil)
+	provider, ok := s.providers.Provider(name)
+	if !ok {
+		s.writeError(w, r, http.StatusNotFound, fmt.Errorf("unknown payment provider %q", name), body, nil)
 		return
 	}
-	var payload NowPaymentsWebhookPayload
-	if err := json.Unmarshal(body, &payload); err != nil {
-		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid webhook payload: %w", err), body, nil)
+	event, err := provider.VerifyWebhook(r, body)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, ErrInvalidWebhookSignature):
+			status = http.StatusUnauthorized
+		case errors.Is(err, ErrWebhooksUnsupported):
+			status = http.StatusNotFound
+		}
+		s.writeError(w, r, status, err, body, nil)
 		return
 	}
-	nowID := strings.TrimSpace(firstNonEmpty(payload.InvoiceID))
-	if nowID == "" {
+	if event.Reference == "" {
 		s.writeJSON(w, r, http.StatusOK, map[string]string{"status": "ignored"}, body)
 		return
 	}
-	invoice, err := s.store.GetInvoiceByNowID(r.Context(), nowID)
+	invoice, err := s.store.GetInvoiceByProviderRef(r.Context(), provider.Name(), event.Reference)
 	if err != nil {
 		s.writeError(w, r, http.StatusInternalServerError, err, body, nil)
 		return
@@ -626,16 +634,81 @@ func (s *Server) handleNowPaymentsWebhook(w http.ResponseWriter, r *http.Request
 		s.writeJSON(w, r, http.StatusOK, map[string]string{"status": "already minted"}, body)
 		return
 	}
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), providerCallTimeout)
 	defer cancel()
-	latest, err := s.nowPayments.GetInvoice(ctx, nowID)
+	status, err := provider.InvoiceStatus(ctx, invoice.ProviderRef)
 	if err != nil {
 		s.writeError(w, r, http.StatusBadGateway, err, body, nil)
 		return
 	}
-	if !latest.Paid() {
-		_ = s.store.UpdateInvoiceStatus(r.Context(), invoice.ID, "processing", nil)
-		s.writeJSON(w, r, http.StatusOK, map[string]string{"status": "pending"}, body)
+	if status != ProviderStatusPaid {
+		_ = s.store.UpdateInvoiceStatus(r.Context(), invoice.ID, status.InvoiceRecordStatus(), nil)
+		respStatus := "pending"
+		if status == ProviderStatusFailed {
+			respStatus = "failed"
+		}
+		s.writeJSON(w, r, http.StatusOK, map[string]string{"status": respStatus}, body)
+		return
+	}
+	quote, err := s.store.GetQuote(r.Context(), invoice.QuoteID)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err, body, nil)
+		return
+	}
+	if quote == nil {
+		s.writeError(w, r, http.StatusInternalServerError, fmt.Errorf("quote %s missing", invoice.QuoteID), body, nil)
+		return
+	}
+	s.settleInvoice(ctx, w, r, invoice, quote, body)
+}
+
+// handleOperatorConfirm releases the mint for an invoice whose provider is
+// settled out of band, once an operator has confirmed receipt of funds.
+func (s *Server) handleOperatorConfirm(w http.ResponseWriter, r *http.Request) {
+	if s.operatorToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := s.readBody(w, r)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err, body, nil)
+		return
+	}
+	if !s.authorizedOperator(r) {
+		s.writeError(w, r, http.StatusUnauthorized, errors.New("unauthorized"), body, nil)
+		return
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/operator/invoices/"), "/confirm")
+	if id == "" || strings.Contains(id, "/") {
+		s.writeError(w, r, http.StatusBadRequest, errors.New("invoice id required"), body, nil)
+		return
+	}
+	var confirmation OperatorConfirmation
+	if err := json.Unmarshal(body, &confirmation); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid JSON payload: %w", err), body, nil)
+		return
+	}
+	invoice, err := s.store.GetInvoice(r.Context(), id)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err, body, nil)
+		return
+	}
+	if invoice == nil {
+		s.writeError(w, r, http.StatusNotFound, errors.New("invoice not found"), body, nil)
+		return
+	}
+	provider, ok := s.providers.Provider(invoice.Provider)
+	if !ok {
+		s.writeError(w, r, http.StatusServiceUnavailable, fmt.Errorf("payment provider %q is not configured", invoice.Provider), body, nil)
+		return
+	}
+	settled, ok := provider.(OperatorSettledProvider)
+	if !ok {
+		s.writeError(w, r, http.StatusConflict, fmt.Errorf("invoices from %s are not settled by operators", provider.Name()), body, nil)
+		return
+	}
+	if strings.EqualFold(invoice.Status, "minted") {
+		s.writeJSON(w, r, http.StatusOK, map[string]string{"status": "already minted"}, body)
 		return
 	}
 	quote, err := s.store.GetQuote(r.Context(), invoice.QuoteID)
@@ -647,6 +720,32 @@ func (s *Server) handleNowPaymentsWebhook(w http.ResponseWriter, r *http.Request
 		s.writeError(w, r, http.StatusInternalServerError, fmt.Errorf("quote %s missing", invoice.QuoteID), body, nil)
 		return
 	}
+	if err := settled.ConfirmSettlement(r.Context(), quote, confirmation); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err, body, nil)
+		return
+	}
+	reference := strings.TrimSpace(confirmation.Reference)
+	if err := s.store.RecordSettlementReference(r.Context(), invoice.ID, reference); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err, body, nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), providerCallTimeout)
+	defer cancel()
+	s.settleInvoice(ctx, w, r, invoice, quote, body)
+}
+
+func (s *Server) authorizedOperator(r *http.Request) bool {
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.operatorToken)) == 1
+}
+
+// settleInvoice mints the quoted amount for a paid invoice and records the
+// outcome.
+func (s *Server) settleInvoice(ctx context.Context, w http.ResponseWriter, r *http.Request, invoice *InvoiceRecord, quote *QuoteRecord, body []byte) {
 	txHash, voucherHash, err := s.mintWithVoucher(ctx, invoice, quote)
 	if err != nil {
 		_ = s.store.UpdateInvoiceStatus(r.Context(), invoice.ID, "error", nil)
@@ -685,26 +784,6 @@ func (s *Server) mintWithVoucher(ctx context.Context, invoice *InvoiceRecord, qu
 	return txHash, voucherHash, nil
 }
 
-func (s *Server) verifyHMAC(body []byte, signature string) bool {
-	if strings.TrimSpace(signature) == "" {
-		return false
-	}
-	mac := hmac.New(sha512.New, s.hmacSecret)
-	mac.Write(body)
-	expected := mac.Sum(nil)
-	decoded, err := hex.DecodeString(strings.TrimSpace(signature))
-	if err != nil {
-		return false
-	}
-	if len(decoded) != len(expected) {
-		return false
-	}
-	if hmac.Equal(decoded, expected) {
-		return true
-	}
-	return false
-}
-
 func (s *Server) readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
 	reader := http.MaxBytesReader(w, r.Body, maxRequestBody)
 	defer func() {
@@ -102,7 +102,15 @@ func (s *stubSigner) Sign(ctx context.Context, payload []byte) ([]byte, error) {
 
 func newTestServer(t *testing.T, store *SQLiteStore, np *stubNowPayments, node *stubNode, signer *stubSigner) *Server {
 	oracle := NewOracle(time.Minute, 0.10, 0.50)
-	srv := NewServer(store, oracle, np, node, signer, time.Minute, "USD", "NHB", 0, "secret", "https://api.nhbcoin.com/webhooks/nowpayments")
+	provider, err := NewNowPaymentsProvider(np, "secret", "https://api.nhbcoin.com/webhooks/nowpayments")
+	if err != nil {
+		t.Fatalf("nowpayments provider: %v", err)
+	}
+	router, err := NewProviderRouter(provider)
+	if err != nil {
+		t.Fatalf("provider router: %v", err)
+	}
+	srv := NewServer(store, oracle, router, node, signer, time.Minute, "USD", "NHB", 0)
 	fixed := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
 	srv.nowFn = func() time.Time { return fixed }
 	return srv
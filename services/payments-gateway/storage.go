@@ -66,8 +66,8 @@ func (s *SQLiteStore) init() error {
             quote_id TEXT NOT NULL,
             recipient TEXT NOT NULL,
             status TEXT NOT NULL,
-            nowpayments_id TEXT,
-            nowpayments_url TEXT,
+            provider_ref TEXT,
+            provider_url TEXT,
             tx_hash TEXT,
             created_at TIMESTAMP NOT NULL,
             updated_at TIMESTAMP NOT NULL,
@@ -85,19 +85,65 @@ func (s *SQLiteStore) init() error {
 		"service_fee_fiat":     "TEXT NOT NULL DEFAULT '0'",
 		"total_fiat":           "TEXT NOT NULL DEFAULT '0'",
 		"estimated_pay_amount": "TEXT NOT NULL DEFAULT ''",
+		"provider":             "TEXT NOT NULL DEFAULT ''",
 	}
 	for name, def := range quoteColumns {
 		if err := s.ensureColumn("quotes", name, def); err != nil {
 			return err
 		}
 	}
+	// Invoices created before provider routing existed were all opened with
+	// NOWPayments, whose reference and URL were stored in nowpayments_id and
+	// nowpayments_url. Renaming keeps those values under the generic columns.
+	invoiceRenames := [][2]string{
+		{"nowpayments_id", "provider_ref"},
+		{"nowpayments_url", "provider_url"},
+	}
+	for _, rename := range invoiceRenames {
+		if err := s.renameColumn("invoices", rename[0], rename[1]); err != nil {
+			return err
+		}
+	}
+	invoiceColumns := map[string]string{
+		"provider":              "TEXT NOT NULL DEFAULT '" + ProviderNowPayments + "'",
+		"provider_instructions": "TEXT NOT NULL DEFAULT ''",
+		"settlement_reference":  "TEXT NOT NULL DEFAULT ''",
+	}
+	for name, def := range invoiceColumns {
+		if err := s.ensureColumn("invoices", name, def); err != nil {
+			return err
+		}
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS invoices_provider_ref ON invoices(provider, provider_ref)`); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (s *SQLiteStore) ensureColumn(table, column, definition string) error {
+	exists, err := s.hasColumn(table, column)
+	if err != nil || exists {
+		return err
+	}
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// renameColumn renames from to to when the table still carries the old
+// column. Indexes on the column follow the rename.
+func (s *SQLiteStore) renameColumn(table, from, to string) error {
+	exists, err := s.hasColumn(table, from)
+	if err != nil || !exists {
+		return err
+	}
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, from, to))
+	return err
+}
+
+func (s *SQLiteStore) hasColumn(table, column string) (bool, error) {
 	rows, err := s.db.Query(`PRAGMA table_info(` + table + `)`)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer rows.Close()
 	for rows.Next() {
@@ -110,17 +156,13 @@ func (s *SQLiteStore) ensureColumn(table, column, definition string) error {
 			primary  int
 		)
 		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultV, &primary); err != nil {
-			return err
+			return false, err
 		}
 		if strings.EqualFold(name, column) {
-			return nil
+			return true, nil
 		}
 	}
-	if err := rows.Err(); err != nil {
-		return err
-	}
-	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
-	return err
+	return false, rows.Err()
 }
 
 func (s *SQLiteStore) Close() error { return s.db.Close() }
@@ -184,21 +226,22 @@ type QuoteRecord struct {
 	TotalFiat          string
 	AmountToken        string
 	EstimatedPayAmount string
+	Provider           string
 	Expiry             time.Time
 	CreatedAt          time.Time
 }
 
 func (s *SQLiteStore) InsertQuote(ctx context.Context, q QuoteRecord) error {
-	const stmt = `INSERT INTO quotes(id, fiat_currency, token, mint_asset, pay_currency, amount_fiat, service_fee_fiat, total_fiat, amount_token, estimated_pay_amount, expiry, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err := s.db.ExecContext(ctx, stmt, q.ID, q.FiatCurrency, q.Token, q.MintAsset, q.PayCurrency, q.AmountFiat, q.ServiceFeeFiat, q.TotalFiat, q.AmountToken, q.EstimatedPayAmount, q.Expiry, q.CreatedAt)
+	const stmt = `INSERT INTO quotes(id, fiat_currency, token, mint_asset, pay_currency, amount_fiat, service_fee_fiat, total_fiat, amount_token, estimated_pay_amount, provider, expiry, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.db.ExecContext(ctx, stmt, q.ID, q.FiatCurrency, q.Token, q.MintAsset, q.PayCurrency, q.AmountFiat, q.ServiceFeeFiat, q.TotalFiat, q.AmountToken, q.EstimatedPayAmount, q.Provider, q.Expiry, q.CreatedAt)
 	return err
 }
 
 func (s *SQLiteStore) GetQuote(ctx context.Context, id string) (*QuoteRecord, error) {
-	const query = `SELECT id, fiat_currency, token, mint_asset, pay_currency, amount_fiat, service_fee_fiat, total_fiat, amount_token, estimated_pay_amount, expiry, created_at FROM quotes WHERE id = ?`
+	const query = `SELECT id, fiat_currency, token, mint_asset, pay_currency, amount_fiat, service_fee_fiat, total_fiat, amount_token, estimated_pay_amount, provider, expiry, created_at FROM quotes WHERE id = ?`
 	row := s.db.QueryRowContext(ctx, query, id)
 	var rec QuoteRecord
-	if err := row.Scan(&rec.ID, &rec.FiatCurrency, &rec.Token, &rec.MintAsset, &rec.PayCurrency, &rec.AmountFiat, &rec.ServiceFeeFiat, &rec.TotalFiat, &rec.AmountToken, &rec.EstimatedPayAmount, &rec.Expiry, &rec.CreatedAt); err != nil {
+	if err := row.Scan(&rec.ID, &rec.FiatCurrency, &rec.Token, &rec.MintAsset, &rec.PayCurrency, &rec.AmountFiat, &rec.ServiceFeeFiat, &rec.TotalFiat, &rec.AmountToken, &rec.EstimatedPayAmount, &rec.Provider, &rec.Expiry, &rec.CreatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -218,15 +261,18 @@ func (s *SQLiteStore) GetQuote(ctx context.Context, id string) (*QuoteRecord, er
 
 // InvoiceRecord captures stored invoice metadata.
 type InvoiceRecord struct {
-	ID        string
-	QuoteID   string
-	Recipient string
-	Status    string
-	NowID     string
-	NowURL    string
-	TxHash    sql.NullString
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                  string
+	QuoteID             string
+	Recipient           string
+	Status              string
+	Provider            string
+	ProviderRef         string
+	PaymentURL          string
+	Instructions        map[string]string
+	SettlementReference string
+	TxHash              sql.NullString
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
 }
 
 // InvoiceView joins invoice and quote state for reconciliation/reporting.
@@ -235,8 +281,9 @@ type InvoiceView struct {
 	QuoteID            string
 	Recipient          string
 	Status             string
-	NowID              string
-	NowURL             string
+	Provider           string
+	ProviderRef        string
+	PaymentURL         string
 	TxHash             sql.NullString
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
@@ -263,36 +310,71 @@ type InvoiceListFilter struct {
 	Limit       int
 }
 
+const invoiceColumnsSQL = `id, quote_id, recipient, status, provider, provider_ref, provider_url, provider_instructions, settlement_reference, tx_hash, created_at, updated_at`
+
 func (s *SQLiteStore) InsertInvoice(ctx context.Context, inv InvoiceRecord) error {
-	const stmt = `INSERT INTO invoices(id, quote_id, recipient, status, nowpayments_id, nowpayments_url, tx_hash, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err := s.db.ExecContext(ctx, stmt, inv.ID, inv.QuoteID, inv.Recipient, inv.Status, inv.NowID, inv.NowURL, inv.TxHash, inv.CreatedAt, inv.UpdatedAt)
+	instructions := ""
+	if len(inv.Instructions) > 0 {
+		encoded, err := json.Marshal(inv.Instructions)
+		if err != nil {
+			return err
+		}
+		instructions = string(encoded)
+	}
+	provider := strings.TrimSpace(inv.Provider)
+	if provider == "" {
+		provider = ProviderNowPayments
+	}
+	const stmt = `INSERT INTO invoices(` + invoiceColumnsSQL + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.db.ExecContext(ctx, stmt, inv.ID, inv.QuoteID, inv.Recipient, inv.Status, provider, inv.ProviderRef, inv.PaymentURL, instructions, inv.SettlementReference, inv.TxHash, inv.CreatedAt, inv.UpdatedAt)
 	return err
 }
 
 func (s *SQLiteStore) GetInvoice(ctx context.Context, id string) (*InvoiceRecord, error) {
-	const query = `SELECT id, quote_id, recipient, status, nowpayments_id, nowpayments_url, tx_hash, created_at, updated_at FROM invoices WHERE id = ?`
+	const query = `SELECT ` + invoiceColumnsSQL + ` FROM invoices WHERE id = ?`
 	row := s.db.QueryRowContext(ctx, query, id)
 	return scanInvoice(row)
 }
 
-func (s *SQLiteStore) GetInvoiceByNowID(ctx context.Context, nowID string) (*InvoiceRecord, error) {
-	const query = `SELECT id, quote_id, recipient, status, nowpayments_id, nowpayments_url, tx_hash, created_at, updated_at FROM invoices WHERE nowpayments_id = ?`
-	row := s.db.QueryRowContext(ctx, query, nowID)
+// GetInvoiceByProviderRef looks up an invoice by the reference the provider
+// assigned to it.
+func (s *SQLiteStore) GetInvoiceByProviderRef(ctx context.Context, provider, ref string) (*InvoiceRecord, error) {
+	const query = `SELECT ` + invoiceColumnsSQL + ` FROM invoices WHERE provider = ? AND provider_ref = ?`
+	row := s.db.QueryRowContext(ctx, query, provider, ref)
 	return scanInvoice(row)
 }
 
 func scanInvoice(row *sql.Row) (*InvoiceRecord, error) {
-	var rec InvoiceRecord
-	err := row.Scan(&rec.ID, &rec.QuoteID, &rec.Recipient, &rec.Status, &rec.NowID, &rec.NowURL, &rec.TxHash, &rec.CreatedAt, &rec.UpdatedAt)
+	var (
+		rec          InvoiceRecord
+		ref, url     sql.NullString
+		instructions string
+	)
+	err := row.Scan(&rec.ID, &rec.QuoteID, &rec.Recipient, &rec.Status, &rec.Provider, &ref, &url, &instructions, &rec.SettlementReference, &rec.TxHash, &rec.CreatedAt, &rec.UpdatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	rec.ProviderRef = ref.String
+	rec.PaymentURL = url.String
+	if instructions != "" {
+		if err := json.Unmarshal([]byte(instructions), &rec.Instructions); err != nil {
+			return nil, fmt.Errorf("decode instructions for invoice %s: %w", rec.ID, err)
+		}
+	}
 	return &rec, nil
 }
 
+// RecordSettlementReference stores the operator-supplied reference for a
+// manually confirmed payment.
+func (s *SQLiteStore) RecordSettlementReference(ctx context.Context, id, reference string) error {
+	const stmt = `UPDATE invoices SET settlement_reference = ?, updated_at = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, stmt, reference, time.Now().UTC(), id)
+	return err
+}
+
 func (s *SQLiteStore) UpdateInvoiceStatus(ctx context.Context, id, status string, txHash *string) error {
 	const stmt = `UPDATE invoices SET status = ?, tx_hash = ?, updated_at = ? WHERE id = ?`
 	var hash interface{}
@@ -308,7 +390,7 @@ func (s *SQLiteStore) UpdateInvoiceStatus(ctx context.Context, id, status string
 // ListInvoiceViews returns invoice reconciliation rows joined with their originating quotes.
 func (s *SQLiteStore) ListInvoiceViews(ctx context.Context, filter InvoiceListFilter) ([]InvoiceView, error) {
 	query := `
-SELECT i.id, i.quote_id, i.recipient, i.status, i.nowpayments_id, i.nowpayments_url, i.tx_hash, i.created_at, i.updated_at,
+SELECT i.id, i.quote_id, i.recipient, i.status, i.provider, i.provider_ref, i.provider_url, i.tx_hash, i.created_at, i.updated_at,
        q.fiat_currency, q.token, q.mint_asset, q.pay_currency, q.amount_fiat, q.service_fee_fiat, q.total_fiat, q.amount_token, q.estimated_pay_amount, q.expiry
 FROM invoices i
 JOIN quotes q ON q.id = i.quote_id
@@ -354,14 +436,18 @@ JOIN quotes q ON q.id = i.quote_id
 	defer rows.Close()
 	items := make([]InvoiceView, 0)
 	for rows.Next() {
-		var item InvoiceView
+		var (
+			item     InvoiceView
+			ref, url sql.NullString
+		)
 		if err := rows.Scan(
 			&item.ID,
 			&item.QuoteID,
 			&item.Recipient,
 			&item.Status,
-			&item.NowID,
-			&item.NowURL,
+			&item.Provider,
+			&ref,
+			&url,
 			&item.TxHash,
 			&item.CreatedAt,
 			&item.UpdatedAt,
@@ -378,6 +464,8 @@ JOIN quotes q ON q.id = i.quote_id
 		); err != nil {
 			return nil, err
 		}
+		item.ProviderRef = ref.String
+		item.PaymentURL = url.String
 		if strings.TrimSpace(item.MintAsset) == "" {
 			item.MintAsset = item.Token
 		}
@@ -450,8 +538,13 @@ func FormatInvoiceView(inv InvoiceView) map[string]interface{} {
 		"quoteExpiry":        inv.QuoteExpiry.UTC().Format(time.RFC3339),
 		"createdAt":          inv.CreatedAt.UTC().Format(time.RFC3339),
 		"updatedAt":          inv.UpdatedAt.UTC().Format(time.RFC3339),
-		"nowpaymentsId":      inv.NowID,
-		"nowpaymentsUrl":     inv.NowURL,
+		"provider":           inv.Provider,
+		"providerReference":  inv.ProviderRef,
+		"paymentUrl":         inv.PaymentURL,
+	}
+	if inv.Provider == ProviderNowPayments {
+		payload["nowpaymentsId"] = inv.ProviderRef
+		payload["nowpaymentsUrl"] = inv.PaymentURL
 	}
 	if inv.TxHash.Valid {
 		payload["txHash"] = inv.TxHash.String
@@ -471,7 +564,7 @@ func MarshalInvoiceViews(items []InvoiceView) ([]byte, error) {
 // MarshalInvoiceViewCSV renders reconciliation rows as CSV.
 func MarshalInvoiceViewCSV(items []InvoiceView) ([]byte, error) {
 	var builder strings.Builder
-	builder.WriteString("invoice_id,quote_id,recipient,status,fiat,token,mint_asset,pay_currency,amount_fiat,service_fee_fiat,total_fiat,amount_token,estimated_pay_amount,quote_expiry,created_at,updated_at,nowpayments_id,nowpayments_url,tx_hash\n")
+	builder.WriteString("invoice_id,quote_id,recipient,status,fiat,token,mint_asset,pay_currency,amount_fiat,service_fee_fiat,total_fiat,amount_token,estimated_pay_amount,quote_expiry,created_at,updated_at,provider_ref,provider_url,tx_hash,provider\n")
 	for _, item := range items {
 		txHash := ""
 		if item.TxHash.Valid {
@@ -494,9 +587,10 @@ func MarshalInvoiceViewCSV(items []InvoiceView) ([]byte, error) {
 			csvEscape(item.QuoteExpiry.UTC().Format(time.RFC3339)),
 			csvEscape(item.CreatedAt.UTC().Format(time.RFC3339)),
 			csvEscape(item.UpdatedAt.UTC().Format(time.RFC3339)),
-			csvEscape(item.NowID),
-			csvEscape(item.NowURL),
+			csvEscape(item.ProviderRef),
+			csvEscape(item.PaymentURL),
 			csvEscape(txHash),
+			csvEscape(item.Provider),
 		}
 		builder.WriteString(strings.Join(line, ","))
 		builder.WriteString("\n")
@@ -582,13 +676,26 @@ func MarshalInvoice(inv *InvoiceRecord, quote *QuoteRecord) ([]byte, error) {
 		"quoteId":   inv.QuoteID,
 		"recipient": inv.Recipient,
 		"status":    inv.Status,
-		"nowpayments": map[string]string{
-			"id":  inv.NowID,
-			"url": inv.NowURL,
+		"provider":  inv.Provider,
+		"payment": map[string]string{
+			"reference": inv.ProviderRef,
+			"url":       inv.PaymentURL,
 		},
 		"updatedAt": inv.UpdatedAt.UTC().Format(time.RFC3339),
 		"createdAt": inv.CreatedAt.UTC().Format(time.RFC3339),
 	}
+	if inv.Provider == ProviderNowPayments {
+		payload["nowpayments"] = map[string]string{
+			"id":  inv.ProviderRef,
+			"url": inv.PaymentURL,
+		}
+	}
+	if len(inv.Instructions) > 0 {
+		payload["instructions"] = inv.Instructions
+	}
+	if inv.SettlementReference != "" {
+		payload["settlementReference"] = inv.SettlementReference
+	}
 	if quote != nil {
 		payload["amountFiat"] = quote.AmountFiat
 		payload["amountToken"] = quote.AmountToken
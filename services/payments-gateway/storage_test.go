@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStoreMigratesNowPaymentsColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payments.db")
+	legacy, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open legacy db: %v", err)
+	}
+	created := time.Date(2026, 4, 12, 10, 0, 0, 0, time.UTC)
+	stmts := []string{
+		`CREATE TABLE quotes (
+            id TEXT PRIMARY KEY,
+            fiat_currency TEXT NOT NULL,
+            token TEXT NOT NULL,
+            amount_fiat TEXT NOT NULL,
+            amount_token TEXT NOT NULL,
+            expiry TIMESTAMP NOT NULL,
+            created_at TIMESTAMP NOT NULL
+        );`,
+		`CREATE TABLE invoices (
+            id TEXT PRIMARY KEY,
+            quote_id TEXT NOT NULL,
+            recipient TEXT NOT NULL,
+            status TEXT NOT NULL,
+            nowpayments_id TEXT,
+            nowpayments_url TEXT,
+            tx_hash TEXT,
+            created_at TIMESTAMP NOT NULL,
+            updated_at TIMESTAMP NOT NULL,
+            UNIQUE(quote_id)
+        );`,
+	}
+	for _, stmt := range stmts {
+		if _, err := legacy.Exec(stmt); err != nil {
+			t.Fatalf("create legacy schema: %v", err)
+		}
+	}
+	if _, err := legacy.Exec(`INSERT INTO quotes(id, fiat_currency, token, amount_fiat, amount_token, expiry, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		"quote-legacy", "USD", "NHB", "35", "7", created.Add(time.Minute), created); err != nil {
+		t.Fatalf("insert legacy quote: %v", err)
+	}
+	if _, err := legacy.Exec(`INSERT INTO invoices(id, quote_id, recipient, status, nowpayments_id, nowpayments_url, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"invoice-legacy", "quote-legacy", "nhb1legacy", "pending", "np-legacy", "https://nowpay/invoice/np-legacy", created, created); err != nil {
+		t.Fatalf("insert legacy invoice: %v", err)
+	}
+	if err := legacy.Close(); err != nil {
+		t.Fatalf("close legacy db: %v", err)
+	}
+
+	// Opening twice checks that the migration is idempotent.
+	for i := 0; i < 2; i++ {
+		store, err := NewSQLiteStore(path)
+		if err != nil {
+			t.Fatalf("open store (pass %d): %v", i, err)
+		}
+		invoice, err := store.GetInvoiceByProviderRef(context.Background(), ProviderNowPayments, "np-legacy")
+		if err != nil || invoice == nil {
+			t.Fatalf("lookup migrated invoice (pass %d): invoice=%v err=%v", i, invoice, err)
+		}
+		if invoice.ID != "invoice-legacy" || invoice.PaymentURL != "https://nowpay/invoice/np-legacy" {
+			t.Fatalf("unexpected migrated invoice: %+v", invoice)
+		}
+		views, err := store.ListInvoiceViews(context.Background(), InvoiceListFilter{})
+		if err != nil {
+			t.Fatalf("list views: %v", err)
+		}
+		if len(views) != 1 || views[0].ProviderRef != "np-legacy" || views[0].Provider != ProviderNowPayments {
+			t.Fatalf("unexpected reconciliation rows: %+v", views)
+		}
+		if err := store.Close(); err != nil {
+			t.Fatalf("close store: %v", err)
+		}
+	}
+
+	check, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("reopen db: %v", err)
+	}
+	defer check.Close()
+	var legacyColumns int
+	if err := check.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('invoices') WHERE name IN ('nowpayments_id', 'nowpayments_url')`).Scan(&legacyColumns); err != nil {
+		t.Fatalf("inspect schema: %v", err)
+	}
+	if legacyColumns != 0 {
+		t.Fatalf("expected legacy columns to be renamed, found %d", legacyColumns)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math/big"
 	"os"
 	"os/signal"
 	"strconv"
@@ -347,11 +348,11 @@ func (p *stableEnginePublisher) PublishOracleUpdate(_ context.Context, update or
 	if p == nil || p.engine == nil {
 		return nil
 	}
-	rate, err := strconv.ParseFloat(strings.TrimSpace(update.Median), 64)
-	if err != nil {
-		return fmt.Errorf("swapd: parse oracle median %q for %s/%s: %w", update.Median, update.Base, update.Quote, err)
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(update.Median))
+	if !ok {
+		return fmt.Errorf("swapd: parse oracle median %q for %s/%s", update.Median, update.Base, update.Quote)
 	}
-	if rate <= 0 {
+	if rate.Sign() <= 0 {
 		return fmt.Errorf("swapd: non-positive oracle median %q for %s/%s", update.Median, update.Base, update.Quote)
 	}
 	p.engine.RecordPrice(update.Base, update.Quote, rate, update.Time)
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"nhbchain/services/swapd/settlement"
-	"nhbchain/services/swapd/stable"
 	"nhbchain/services/swapd/storage"
 )
 
@@ -130,7 +129,7 @@ func settlementJSON(rec storage.SettlementRecord) map[string]any {
 		"reservation_id": rec.ReservationID,
 		"partner_id":     rec.PartnerID,
 		"asset":          rec.Asset,
-		"amount":         stable.FromAmountUnits(rec.AmountUnits),
+		"amount":         stableAmountJSON(rec.AmountUnits),
 		"account":        rec.Account,
 		"rail":           rec.Rail,
 		"status":         rec.Status,
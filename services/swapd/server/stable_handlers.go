@@ -47,20 +47,25 @@ func (s *Server) handleStableQuote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var payload struct {
-		Asset   string  `json:"asset"`
-		Amount  float64 `json:"amount"`
-		Account string  `json:"account"`
+		Asset   string      `json:"asset"`
+		Amount  json.Number `json:"amount"`
+		Account string      `json:"account"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		s.writeStableError(w, http.StatusBadRequest, "invalid payload")
 		return
 	}
 	asset := strings.ToUpper(strings.TrimSpace(payload.Asset))
-	if asset == "" || payload.Amount <= 0 {
+	if asset == "" || strings.TrimSpace(payload.Amount.String()) == "" {
 		s.writeStableError(w, http.StatusBadRequest, "asset and positive amount required")
 		return
 	}
-	quote, err := s.stable.engine.Price(r.Context(), stable.QuoteRequest{Asset: asset, Amount: payload.Amount})
+	amountUnits, err := stable.ParseAmount(payload.Amount.String())
+	if err != nil {
+		s.writeStableError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	quote, err := s.stable.engine.Price(r.Context(), stable.QuoteRequest{Asset: asset, Amount: amountUnits})
 	if err != nil {
 		status, message := stableErrorStatus(err)
 		if status >= http.StatusInternalServerError && s.logger != nil {
@@ -68,7 +73,7 @@ func (s *Server) handleStableQuote(w http.ResponseWriter, r *http.Request) {
 		}
 		s.recordAudit(r.Context(), "quote", partner.ID, "", "error", map[string]any{
 			"asset":  asset,
-			"amount": payload.Amount,
+			"amount": stableAmountJSON(amountUnits),
 			"error":  err.Error(),
 		})
 		s.writeStableError(w, status, message)
@@ -76,15 +81,15 @@ func (s *Server) handleStableQuote(w http.ResponseWriter, r *http.Request) {
 	}
 	s.recordAudit(r.Context(), "quote", partner.ID, quote.Quote.ID, "success", map[string]any{
 		"asset":      quote.Quote.Asset,
-		"amount":     payload.Amount,
-		"price":      stable.FromRateUnits(quote.Quote.Price),
+		"amount":     stableAmountJSON(amountUnits),
+		"price":      stableRateJSON(quote.Quote.Price),
 		"expires_at": quote.Quote.ExpiresAt.UTC().Format(time.RFC3339),
 	})
 	traceID := traceIDFromContext(r.Context())
 	response := map[string]any{
 		"quote_id":   quote.Quote.ID,
 		"asset":      quote.Quote.Asset,
-		"price":      stable.FromRateUnits(quote.Quote.Price),
+		"price":      stableRateJSON(quote.Quote.Price),
 		"expires_at": quote.Quote.ExpiresAt.UTC().Format(time.RFC3339),
 	}
 	if traceID != "" {
@@ -111,9 +116,9 @@ func (s *Server) handleStableReserve(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var payload struct {
-		QuoteID  string  `json:"quote_id"`
-		AmountIn float64 `json:"amount_in"`
-		Account  string  `json:"account"`
+		QuoteID  string      `json:"quote_id"`
+		AmountIn json.Number `json:"amount_in"`
+		Account  string      `json:"account"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		s.writeStableError(w, http.StatusBadRequest, "invalid payload")
@@ -121,11 +126,16 @@ func (s *Server) handleStableReserve(w http.ResponseWriter, r *http.Request) {
 	}
 	quoteID := strings.TrimSpace(payload.QuoteID)
 	account := strings.TrimSpace(payload.Account)
-	if quoteID == "" || account == "" || payload.AmountIn <= 0 {
+	if quoteID == "" || account == "" || strings.TrimSpace(payload.AmountIn.String()) == "" {
 		s.writeStableError(w, http.StatusBadRequest, "quote_id, account, and positive amount_in required")
 		return
 	}
-	reservation, err := s.stable.engine.Reserve(r.Context(), stable.ReserveRequest{QuoteID: quoteID, Account: account, AmountIn: payload.AmountIn})
+	amountInUnits, err := stable.ParseAmount(payload.AmountIn.String())
+	if err != nil {
+		s.writeStableError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	reservation, err := s.stable.engine.Reserve(r.Context(), stable.ReserveRequest{QuoteID: quoteID, Account: account, AmountIn: amountInUnits})
 	if err != nil {
 		status, message := stableErrorStatus(err)
 		if status >= http.StatusInternalServerError && s.logger != nil {
@@ -133,7 +143,7 @@ func (s *Server) handleStableReserve(w http.ResponseWriter, r *http.Request) {
 		}
 		s.recordAudit(r.Context(), "reserve", partner.ID, quoteID, "error", map[string]any{
 			"account":   account,
-			"amount_in": payload.AmountIn,
+			"amount_in": stableAmountJSON(amountInUnits),
 			"error":     err.Error(),
 		})
 		s.writeStableError(w, status, message)
@@ -159,7 +169,7 @@ func (s *Server) handleStableReserve(w http.ResponseWriter, r *http.Request) {
 			s.logger.Printf("swapd: revert reservation after quota exhaustion: %v", cancelErr)
 		}
 		s.recordAudit(r.Context(), "reserve", partner.ID, reservation.Reservation.QuoteID, "quota_exceeded", map[string]any{
-			"amount_out": stable.FromAmountUnits(amountOut),
+			"amount_out": stableAmountJSON(amountOut),
 		})
 		s.writeStableError(w, http.StatusTooManyRequests, "partner quota exceeded")
 		return
@@ -167,16 +177,16 @@ func (s *Server) handleStableReserve(w http.ResponseWriter, r *http.Request) {
 	s.setReservationOwner(reservation.Reservation.QuoteID, partner.ID)
 	s.recordAudit(r.Context(), "reserve", partner.ID, reservation.Reservation.QuoteID, "success", map[string]any{
 		"account":    account,
-		"amount_in":  stable.FromAmountUnits(reservation.Reservation.AmountIn),
-		"amount_out": stable.FromAmountUnits(reservation.Reservation.AmountOut),
+		"amount_in":  stableAmountJSON(reservation.Reservation.AmountIn),
+		"amount_out": stableAmountJSON(reservation.Reservation.AmountOut),
 		"expires_at": reservation.Reservation.ExpiresAt.UTC().Format(time.RFC3339),
 	})
 	traceID := traceIDFromContext(r.Context())
 	response := map[string]any{
 		"reservation_id": reservation.Reservation.QuoteID,
 		"quote_id":       reservation.Reservation.QuoteID,
-		"amount_in":      stable.FromAmountUnits(reservation.Reservation.AmountIn),
-		"amount_out":     stable.FromAmountUnits(reservation.Reservation.AmountOut),
+		"amount_in":      stableAmountJSON(reservation.Reservation.AmountIn),
+		"amount_out":     stableAmountJSON(reservation.Reservation.AmountOut),
 		"expires_at":     reservation.Reservation.ExpiresAt.UTC().Format(time.RFC3339),
 	}
 	if traceID != "" {
@@ -241,13 +251,13 @@ func (s *Server) handleStableCashOut(w http.ResponseWriter, r *http.Request) {
 	}
 	s.recordAudit(r.Context(), "cashout", partner.ID, intent.Intent.ID, "success", map[string]any{
 		"reservation_id": intent.Intent.ReservationID,
-		"amount":         intent.Intent.Amount,
+		"amount":         stableAmountJSON(intent.Intent.AmountUnits),
 	})
 
 	response := map[string]any{
 		"intent_id":      intent.Intent.ID,
 		"reservation_id": intent.Intent.ReservationID,
-		"amount":         intent.Intent.Amount,
+		"amount":         stableAmountJSON(intent.Intent.AmountUnits),
 		"created_at":     intent.Intent.CreatedAt.UTC().Format(time.RFC3339),
 	}
 	if s.settlement != nil {
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -94,7 +95,7 @@ func TestStableHandlersFlow(t *testing.T) {
 	}))
 
 	quoteBody := `{"asset":"ZNHB","amount":100,"account":"merchant-123"}`
-	engine.RecordPrice("ZNHB", "USD", 1.02, base)
+	engine.RecordPrice("ZNHB", "USD", big.NewRat(102, 100), base)
 
 	quoteResp := doStableRequest(t, mux, traceCtx, http.MethodPost, "/v1/stable/quote", quoteBody, &creds)
 	assertStatus(t, quoteResp.Code, http.StatusOK)
@@ -102,7 +103,13 @@ func TestStableHandlersFlow(t *testing.T) {
 
 	quoteID := extractField(t, quoteResp.Body.Bytes(), "quote_id")
 
-	reserveBody := `{"quote_id":"` + quoteID + `","amount_in":100,"account":"merchant-123"}`
+	// Amounts finer than the engine's six-decimal scale are rejected rather
+	// than silently rounded; numeric strings are parsed exactly.
+	preciseBody := `{"quote_id":"` + quoteID + `","amount_in":"100.0000001","account":"merchant-123"}`
+	preciseResp := doStableRequest(t, mux, traceCtx, http.MethodPost, "/v1/stable/reserve", preciseBody, &creds)
+	assertStatus(t, preciseResp.Code, http.StatusBadRequest)
+
+	reserveBody := `{"quote_id":"` + quoteID + `","amount_in":"100.000000","account":"merchant-123"}`
 	reserveResp := doStableRequest(t, mux, traceCtx, http.MethodPost, "/v1/stable/reserve", reserveBody, &creds)
 	assertStatus(t, reserveResp.Code, http.StatusOK)
 	assertGoldenJSON(t, "stable_reserve.json", reserveResp.Body.Bytes())
@@ -148,7 +155,7 @@ func TestStableHandlersFlow(t *testing.T) {
 	newQuoteID := extractField(t, quoteSlippage.Body.Bytes(), "quote_id")
 
 	// Move the oracle by 5% to trigger slippage guard (limit is 0.5%).
-	engine.RecordPrice("ZNHB", "USD", 1.07, base.Add(30*time.Second))
+	engine.RecordPrice("ZNHB", "USD", big.NewRat(107, 100), base.Add(30*time.Second))
 	reserveSlippage := doStableRequest(t, mux, traceCtx, http.MethodPost, "/v1/stable/reserve", `{"quote_id":"`+newQuoteID+`","amount_in":50,"account":"merchant-123"}`, &creds)
 	assertStatus(t, reserveSlippage.Code, http.StatusConflict)
 
@@ -527,7 +534,7 @@ func newTestStableEngine(t *testing.T, base time.Time, store *storage.Storage) *
 		return ts
 	})
 	engine.SetPriceMaxAge(24 * time.Hour)
-	engine.RecordPrice("ZNHB", "USD", 1.02, base)
+	engine.RecordPrice("ZNHB", "USD", big.NewRat(102, 100), base)
 	return engine
 }
 
@@ -1,31 +1,32 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
-)
+	"strconv"
 
-const stableAmountScale = 1_000_000
+	"nhbchain/services/swapd/stable"
+)
 
-// ToStableAmountUnits converts a decimal amount into scaled integer units used by the stable engine.
+// ToStableAmountUnits converts a configured decimal amount into scaled integer
+// units used by the stable engine. The float is rendered with the shortest
+// representation that round-trips, which is the literal an operator wrote in
+// the config file, and then parsed exactly.
 func ToStableAmountUnits(amount float64) (int64, error) {
-	if amount <= 0 {
-		return 0, fmt.Errorf("amount must be positive")
-	}
-	scaled := math.Round(amount * float64(stableAmountScale))
-	units := int64(scaled)
-	if units <= 0 {
+	if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
 		return 0, fmt.Errorf("amount must be positive")
 	}
-	if !withinStableTolerance(amount, units) {
-		return 0, fmt.Errorf("amount precision exceeds supported scale")
-	}
-	return units, nil
+	return stable.ParseAmount(strconv.FormatFloat(amount, 'f', -1, 64))
+}
+
+// stableAmountJSON renders scaled amount units as an exact JSON number so
+// clients decoding into decimals never see binary floating point artefacts.
+func stableAmountJSON(units int64) json.Number {
+	return json.Number(stable.FormatAmount(units))
 }
 
-func withinStableTolerance(value float64, units int64) bool {
-	recon := float64(units) / float64(stableAmountScale)
-	diff := math.Abs(value - recon)
-	tolerance := 1.0 / float64(stableAmountScale*10)
-	return diff <= tolerance
+// stableRateJSON renders scaled rate units as an exact JSON number.
+func stableRateJSON(units int64) json.Number {
+	return json.Number(stable.FormatRate(units))
 }
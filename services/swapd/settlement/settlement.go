@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -406,6 +407,13 @@ func detailJSON(v map[string]any) string {
 	return string(payload)
 }
 
+// fromUnitsFloat renders engine units for the NOWPayments wire format, which
+// only accepts JSON numbers. Parsing the exact decimal rendering yields the
+// float64 closest to the true amount.
 func fromUnitsFloat(units int64) float64 {
-	return stable.FromAmountUnits(units)
+	value, err := strconv.ParseFloat(stable.FormatAmount(units), 64)
+	if err != nil {
+		return 0
+	}
+	return value
 }
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"math"
 	"math/big"
 	"strings"
 	"sync"
@@ -99,6 +98,7 @@ var (
 	ErrQuoteAmountMismatch = errors.New("quote amount mismatch")
 	ErrReservationExpired  = errors.New("reservation expired")
 	ErrReservationConsumed = errors.New("reservation already consumed")
+	ErrAmountNotPositive   = errors.New("amount must be positive")
 )
 
 // NewEngine constructs an Engine from assets and limits, restoring persisted state if available.
@@ -271,7 +271,8 @@ func (e *Engine) WithClock(clock func() time.Time) {
 }
 
 // PriceQuote calculates a treasury-backed quote for the requested stable asset.
-func (e *Engine) PriceQuote(ctx context.Context, asset string, amount float64) (Quote, error) {
+// The amount is expressed in scaled amount units (see ParseAmount).
+func (e *Engine) PriceQuote(ctx context.Context, asset string, amount int64) (Quote, error) {
 	start := e.clock()
 	ctx, span := e.tracer.Start(ctx, "stable.price_quote",
 		trace.WithAttributes(attribute.String("asset", strings.ToUpper(asset))))
@@ -286,8 +287,8 @@ func (e *Engine) PriceQuote(ctx context.Context, asset string, amount float64) (
 		e.metrics.Observe("quote", e.clock().Sub(start), err)
 		return Quote{}, ErrNotSupported
 	}
-	amountUnits, err := toAmountUnits(amount)
-	if err != nil {
+	if amount <= 0 {
+		err := ErrAmountNotPositive
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		e.metrics.Observe("quote", e.clock().Sub(start), err)
@@ -309,7 +310,7 @@ func (e *Engine) PriceQuote(ctx context.Context, asset string, amount float64) (
 	}
 	e.quotes[quote.ID] = &quoteState{
 		Quote:  quote,
-		Amount: amountUnits,
+		Amount: amount,
 		Asset:  assetCfg.Symbol,
 		Price:  price.rate,
 		Issued: now,
@@ -320,8 +321,9 @@ func (e *Engine) PriceQuote(ctx context.Context, asset string, amount float64) (
 	return quote, nil
 }
 
-// ReserveQuote reserves an existing quote for execution.
-func (e *Engine) ReserveQuote(ctx context.Context, id, account string, amountIn float64) (Reservation, error) {
+// ReserveQuote reserves an existing quote for execution. The input amount is
+// expressed in scaled amount units and must match the quoted amount exactly.
+func (e *Engine) ReserveQuote(ctx context.Context, id, account string, amountIn int64) (Reservation, error) {
 	start := e.clock()
 	ctx, span := e.tracer.Start(ctx, "stable.reserve_quote",
 		trace.WithAttributes(attribute.String("quote.id", id)))
@@ -353,13 +355,14 @@ func (e *Engine) ReserveQuote(ctx context.Context, id, account string, amountIn
 		e.metrics.Observe("reserve", e.clock().Sub(start), err)
 		return Reservation{}, err
 	}
-	amountUnits, err := toAmountUnits(amountIn)
-	if err != nil {
+	if amountIn <= 0 {
+		err := ErrAmountNotPositive
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		e.metrics.Observe("reserve", e.clock().Sub(start), err)
 		return Reservation{}, err
 	}
+	amountUnits := amountIn
 	if !amountMatches(amountUnits, quoteState.Amount) {
 		err := ErrQuoteAmountMismatch
 		span.RecordError(err)
@@ -454,11 +457,11 @@ func (e *Engine) ReserveQuote(ctx context.Context, id, account string, amountIn
 }
 
 // CashOutIntent records a created stable cash-out intent derived from a live
-// reservation awaiting payout.
+// reservation awaiting payout. AmountUnits is expressed in scaled amount
+// units; use FormatAmount to render it.
 type CashOutIntent struct {
 	ID            string
 	ReservationID string
-	Amount        float64
 	AmountUnits   int64
 	Asset         string
 	Account       string
@@ -524,11 +527,10 @@ func (e *Engine) CreateCashOutIntent(ctx context.Context, reservationID string)
 	prevIntentAt := resState.IntentCreatedAt
 	ledger.reserved -= res.AmountOut
 	ledger.payouts += res.AmountOut
-	payout := fromAmountUnits(res.AmountOut)
+	payout := FormatAmount(res.AmountOut)
 	intent := CashOutIntent{
 		ID:            fmt.Sprintf("i-%d", now.UnixNano()),
 		ReservationID: reservationID,
-		Amount:        payout,
 		AmountUnits:   res.AmountOut,
 		Asset:         resState.Asset,
 		Account:       res.Account,
@@ -566,14 +568,14 @@ func (e *Engine) CreateCashOutIntent(ctx context.Context, reservationID string)
 		return CashOutIntent{}, err
 	}
 	span.SetAttributes(
-		attribute.Float64("amount", payout),
+		attribute.String("amount", payout),
 		attribute.String("account", res.Account),
 	)
 	span.SetStatus(codes.Ok, "intent created")
 	e.metrics.Observe("cashout_intent", e.clock().Sub(start), nil)
 	slog.InfoContext(ctx, "cashout intent created",
 		slog.String("reservation_id", reservationID),
-		slog.Float64("amount", payout),
+		slog.String("amount", payout),
 		slog.String("account", res.Account),
 	)
 	return intent, nil
@@ -655,61 +657,6 @@ func pairKey(base, quote string) string {
 	return b + "/" + q
 }
 
-func toAmountUnits(amount float64) (int64, error) {
-	if amount <= 0 {
-		return 0, fmt.Errorf("amount must be positive")
-	}
-	scaled := math.Round(amount * float64(amountScale))
-	units := int64(scaled)
-	if units <= 0 {
-		return 0, fmt.Errorf("amount must be positive")
-	}
-	if !withinTolerance(amount, units, amountScale) {
-		return 0, fmt.Errorf("amount precision exceeds supported scale")
-	}
-	return units, nil
-}
-
-func fromAmountUnits(units int64) float64 {
-	return float64(units) / float64(amountScale)
-}
-
-func toRateUnits(rate float64) (int64, error) {
-	if rate <= 0 {
-		return 0, fmt.Errorf("rate must be positive")
-	}
-	scaled := math.Round(rate * float64(priceScale))
-	units := int64(scaled)
-	if units <= 0 {
-		return 0, fmt.Errorf("rate must be positive")
-	}
-	if !withinTolerance(rate, units, priceScale) {
-		return 0, fmt.Errorf("rate precision exceeds supported scale")
-	}
-	return units, nil
-}
-
-func fromRateUnits(units int64) float64 {
-	return float64(units) / float64(priceScale)
-}
-
-// FromAmountUnits converts scaled integer amounts to user friendly values.
-func FromAmountUnits(units int64) float64 {
-	return fromAmountUnits(units)
-}
-
-// FromRateUnits converts scaled integer rates to user friendly values.
-func FromRateUnits(units int64) float64 {
-	return fromRateUnits(units)
-}
-
-func withinTolerance(value float64, units, scale int64) bool {
-	recon := float64(units) / float64(scale)
-	diff := math.Abs(value - recon)
-	tolerance := 1.0 / float64(scale*10)
-	return diff <= tolerance
-}
-
 func mulDivRound(a, b, denom int64) int64 {
 	if denom == 0 {
 		return 0
@@ -952,15 +899,13 @@ func sameDay(a, b time.Time) bool {
 	return ay == by && am == bm && ad == bd
 }
 
-// RecordPrice updates the in-memory price cache used for quoting.
-func (e *Engine) RecordPrice(base, quote string, rate float64, updated time.Time) {
+// RecordPrice updates the in-memory price cache used for quoting. The rate is
+// rounded to the nearest rate unit (see RateUnitsFromRat).
+func (e *Engine) RecordPrice(base, quote string, rate *big.Rat, updated time.Time) {
 	if e == nil {
 		return
 	}
-	if rate <= 0 {
-		return
-	}
-	rateUnits, err := toRateUnits(rate)
+	rateUnits, err := RateUnitsFromRat(rate)
 	if err != nil {
 		return
 	}
@@ -976,12 +921,12 @@ func (e *Engine) RecordPrice(base, quote string, rate float64, updated time.Time
 	e.prices[pairKey(base, quote)] = pricePoint{rate: rateUnits, updated: updated}
 }
 
-// CurrentPrice returns the currently cached rate for the requested pair as a
-// float along with the timestamp of the observation, plus whether that
-// observation is stale under the same freshness window lookupPrice enforces
-// (e.MaxQuoteAge/priceAges). The returned rate is unchanged either way -
+// CurrentPrice returns the currently cached rate for the requested pair in
+// scaled rate units (see FormatRate) along with the timestamp of the
+// observation, plus whether that observation is stale under the same
+// freshness window lookupPrice enforces (e.MaxQuoteAge/priceAges). The returned rate is unchanged either way -
 // stale only tells the caller whether to trust it as "live".
-func (e *Engine) CurrentPrice(base, quote string) (float64, time.Time, bool, bool) {
+func (e *Engine) CurrentPrice(base, quote string) (int64, time.Time, bool, bool) {
 	if e == nil {
 		return 0, time.Time{}, false, false
 	}
@@ -992,7 +937,7 @@ func (e *Engine) CurrentPrice(base, quote string) (float64, time.Time, bool, boo
 		return 0, time.Time{}, false, false
 	}
 	stale := e.priceAges > 0 && e.clock().Sub(point.updated) > e.priceAges
-	return fromRateUnits(point.rate), point.updated, stale, true
+	return point.rate, point.updated, stale, true
 }
 
 // LedgerBalance returns a snapshot of the treasury ledger for the asset in scaled units.
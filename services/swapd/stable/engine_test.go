@@ -4,31 +4,42 @@ import (
 	"context"
 	"errors"
 	"math"
+	"math/big"
 	"path/filepath"
 	"testing"
+	"testing/quick"
 	"time"
 
 	"nhbchain/services/swapd/storage"
 )
 
-func mustAmountUnits(t *testing.T, amount float64) int64 {
+func mustAmountUnits(t *testing.T, amount string) int64 {
 	t.Helper()
-	units, err := toAmountUnits(amount)
+	units, err := ParseAmount(amount)
 	if err != nil {
 		t.Fatalf("amount quantisation failed: %v", err)
 	}
 	return units
 }
 
-func mustRateUnits(t *testing.T, rate float64) int64 {
+func mustRateUnits(t *testing.T, rate string) int64 {
 	t.Helper()
-	units, err := toRateUnits(rate)
+	units, err := ParseRate(rate)
 	if err != nil {
 		t.Fatalf("rate quantisation failed: %v", err)
 	}
 	return units
 }
 
+func mustRat(t *testing.T, value string) *big.Rat {
+	t.Helper()
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		t.Fatalf("invalid rational %q", value)
+	}
+	return r
+}
+
 type testClock struct {
 	now  time.Time
 	step time.Duration
@@ -87,16 +98,16 @@ func TestEnginePriceQuoteRequiresFreshOracle(t *testing.T) {
 	base := time.Date(2024, time.June, 7, 19, 15, 17, 0, time.UTC)
 	engine, clock := buildTestEngine(t, base, 1_000_000, time.Minute, Limits{})
 	ctx := context.Background()
-	if _, err := engine.PriceQuote(ctx, "ZNHB", 10); !errors.Is(err, ErrPriceUnavailable) {
+	if _, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "10")); !errors.Is(err, ErrPriceUnavailable) {
 		t.Fatalf("expected ErrPriceUnavailable, got %v", err)
 	}
-	engine.RecordPrice("ZNHB", "USD", 1.05, time.Time{})
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 10)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.05"), time.Time{})
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "10"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	if quote.Price != mustRateUnits(t, 1.05) {
-		t.Fatalf("unexpected price units: got %d want %d", quote.Price, mustRateUnits(t, 1.05))
+	if quote.Price != mustRateUnits(t, "1.05") {
+		t.Fatalf("unexpected price units: got %d want %d", quote.Price, mustRateUnits(t, "1.05"))
 	}
 	expiryDelta := quote.ExpiresAt.Sub(base)
 	if expiryDelta < time.Minute || expiryDelta > time.Minute+10*time.Second {
@@ -104,7 +115,7 @@ func TestEnginePriceQuoteRequiresFreshOracle(t *testing.T) {
 	}
 	// Ensure subsequent quotes fail when the oracle sample ages out.
 	clock.Advance(48 * time.Hour)
-	if _, err := engine.PriceQuote(ctx, "ZNHB", 5); !errors.Is(err, ErrPriceUnavailable) {
+	if _, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "5")); !errors.Is(err, ErrPriceUnavailable) {
 		t.Fatalf("expected stale price error, got %v", err)
 	}
 }
@@ -113,36 +124,36 @@ func TestEngineReserveSlippageAndInventory(t *testing.T) {
 	base := time.Date(2024, time.June, 7, 19, 15, 17, 0, time.UTC)
 	engine, _ := buildTestEngine(t, base, 1_000, time.Minute, Limits{})
 	ctx := context.Background()
-	engine.RecordPrice("ZNHB", "USD", 1.00, time.Time{})
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 100)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.00"), time.Time{})
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "100"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	engine.RecordPrice("ZNHB", "USD", 1.07, time.Time{})
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", 100); !errors.Is(err, ErrSlippageExceeded) {
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.07"), time.Time{})
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "100")); !errors.Is(err, ErrSlippageExceeded) {
 		t.Fatalf("expected slippage error, got %v", err)
 	}
 	available, reserved, payouts, ok := engine.LedgerBalance("ZNHB")
 	if !ok {
 		t.Fatalf("ledger missing")
 	}
-	if available != mustAmountUnits(t, 1_000) || reserved != 0 || payouts != 0 {
+	if available != mustAmountUnits(t, "1000") || reserved != 0 || payouts != 0 {
 		t.Fatalf("ledger mutated on slippage rejection: available=%d reserved=%d payouts=%d", available, reserved, payouts)
 	}
 	// Rewind price within tolerance and reserve successfully.
-	engine.RecordPrice("ZNHB", "USD", 1.004, time.Time{})
-	res, err := engine.ReserveQuote(ctx, quote.ID, "acct", 100)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.004"), time.Time{})
+	res, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "100"))
 	if err != nil {
 		t.Fatalf("reserve quote: %v", err)
 	}
-	if res.AmountOut != mustAmountUnits(t, 100) {
-		t.Fatalf("unexpected amount out units: got %d want %d", res.AmountOut, mustAmountUnits(t, 100))
+	if res.AmountOut != mustAmountUnits(t, "100") {
+		t.Fatalf("unexpected amount out units: got %d want %d", res.AmountOut, mustAmountUnits(t, "100"))
 	}
 	available, reserved, _, _ = engine.LedgerBalance("ZNHB")
-	if available != mustAmountUnits(t, 900) {
+	if available != mustAmountUnits(t, "900") {
 		t.Fatalf("available mismatch after reserve: got %d", available)
 	}
-	if reserved != mustAmountUnits(t, 100) {
+	if reserved != mustAmountUnits(t, "100") {
 		t.Fatalf("reserved mismatch after reserve: got %d", reserved)
 	}
 }
@@ -151,29 +162,29 @@ func TestEngineDailyCapAndInsufficientInventory(t *testing.T) {
 	base := time.Date(2024, time.June, 7, 19, 15, 17, 0, time.UTC)
 	engine, _ := buildTestEngine(t, base, 150, time.Minute, Limits{DailyCap: 120})
 	ctx := context.Background()
-	engine.RecordPrice("ZNHB", "USD", 1.00, time.Time{})
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 200)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.00"), time.Time{})
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "200"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", 200); !errors.Is(err, ErrInsufficientReserve) {
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "200")); !errors.Is(err, ErrInsufficientReserve) {
 		t.Fatalf("expected insufficient reserve, got %v", err)
 	}
-	engine.RecordPrice("ZNHB", "USD", 1.00, time.Time{})
-	quote, err = engine.PriceQuote(ctx, "ZNHB", 100)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.00"), time.Time{})
+	quote, err = engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "100"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", 100); err != nil {
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "100")); err != nil {
 		t.Fatalf("reserve quote: %v", err)
 	}
 	// A second reservation exceeding the cap should fail.
-	engine.RecordPrice("ZNHB", "USD", 1.00, time.Time{})
-	quote, err = engine.PriceQuote(ctx, "ZNHB", 50)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.00"), time.Time{})
+	quote, err = engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "50"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", 50); !errors.Is(err, ErrDailyCapExceeded) {
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "50")); !errors.Is(err, ErrDailyCapExceeded) {
 		t.Fatalf("expected daily cap error, got %v", err)
 	}
 }
@@ -182,20 +193,20 @@ func TestEngineCashOutFlow(t *testing.T) {
 	base := time.Date(2024, time.June, 7, 19, 15, 17, 0, time.UTC)
 	engine, clock := buildTestEngine(t, base, 10_000, time.Minute, Limits{})
 	ctx := context.Background()
-	engine.RecordPrice("ZNHB", "USD", 1.25, time.Time{})
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 80)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.25"), time.Time{})
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "80"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	res, err := engine.ReserveQuote(ctx, quote.ID, "merchant", 80)
+	res, err := engine.ReserveQuote(ctx, quote.ID, "merchant", mustAmountUnits(t, "80"))
 	if err != nil {
 		t.Fatalf("reserve quote: %v", err)
 	}
 	available, reserved, payouts, _ := engine.LedgerBalance("ZNHB")
-	if available != mustAmountUnits(t, 9_900) {
+	if available != mustAmountUnits(t, "9900") {
 		t.Fatalf("available mismatch: got %d", available)
 	}
-	if reserved != mustAmountUnits(t, 100) {
+	if reserved != mustAmountUnits(t, "100") {
 		t.Fatalf("reserved mismatch: got %d", reserved)
 	}
 	if payouts != 0 {
@@ -205,17 +216,17 @@ func TestEngineCashOutFlow(t *testing.T) {
 	if err != nil {
 		t.Fatalf("cash out intent: %v", err)
 	}
-	if intent.Amount != 100.0 {
-		t.Fatalf("unexpected intent amount: %.2f", intent.Amount)
+	if intent.AmountUnits != mustAmountUnits(t, "100") || FormatAmount(intent.AmountUnits) != "100" {
+		t.Fatalf("unexpected intent amount: %s", FormatAmount(intent.AmountUnits))
 	}
 	available, reserved, payouts, _ = engine.LedgerBalance("ZNHB")
-	if available != mustAmountUnits(t, 9_900) {
+	if available != mustAmountUnits(t, "9900") {
 		t.Fatalf("available after cashout mismatch: got %d", available)
 	}
 	if reserved != 0 {
 		t.Fatalf("reserved after cashout mismatch: %d", reserved)
 	}
-	if payouts != mustAmountUnits(t, 100) {
+	if payouts != mustAmountUnits(t, "100") {
 		t.Fatalf("payouts mismatch: %d", payouts)
 	}
 	if _, err := engine.CreateCashOutIntent(ctx, res.QuoteID); !errors.Is(err, ErrReservationConsumed) {
@@ -223,8 +234,8 @@ func TestEngineCashOutFlow(t *testing.T) {
 	}
 	// Ensure the daily cap counter reset via new quote once we advance to next day.
 	clock.Advance(24 * time.Hour)
-	engine.RecordPrice("ZNHB", "USD", 1.25, time.Time{})
-	if _, err := engine.PriceQuote(ctx, "ZNHB", 10); err != nil {
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.25"), time.Time{})
+	if _, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "10")); err != nil {
 		t.Fatalf("quote after advance: %v", err)
 	}
 }
@@ -234,20 +245,20 @@ func TestEngineReservationExpiryReleasesInventory(t *testing.T) {
 	limits := Limits{DailyCap: 200}
 	engine, clock := buildTestEngine(t, base, 5_000, 10*time.Second, limits)
 	ctx := context.Background()
-	engine.RecordPrice("ZNHB", "USD", 1.00, time.Time{})
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 150)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.00"), time.Time{})
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "150"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	res, err := engine.ReserveQuote(ctx, quote.ID, "merchant", 150)
+	res, err := engine.ReserveQuote(ctx, quote.ID, "merchant", mustAmountUnits(t, "150"))
 	if err != nil {
 		t.Fatalf("reserve quote: %v", err)
 	}
 	available, reserved, _, _ := engine.LedgerBalance("ZNHB")
-	if available != mustAmountUnits(t, 4_850) {
+	if available != mustAmountUnits(t, "4850") {
 		t.Fatalf("available mismatch: %d", available)
 	}
-	if reserved != mustAmountUnits(t, 150) {
+	if reserved != mustAmountUnits(t, "150") {
 		t.Fatalf("reserved mismatch: %d", reserved)
 	}
 	clock.Advance(5 * time.Second)
@@ -255,18 +266,18 @@ func TestEngineReservationExpiryReleasesInventory(t *testing.T) {
 		t.Fatalf("expected reservation expired, got %v", err)
 	}
 	available, reserved, _, _ = engine.LedgerBalance("ZNHB")
-	if available != mustAmountUnits(t, 5_000) {
+	if available != mustAmountUnits(t, "5000") {
 		t.Fatalf("available not restored: %d", available)
 	}
 	if reserved != 0 {
 		t.Fatalf("reserved not released: %d", reserved)
 	}
-	engine.RecordPrice("ZNHB", "USD", 1.00, time.Time{})
-	quote, err = engine.PriceQuote(ctx, "ZNHB", 150)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.00"), time.Time{})
+	quote, err = engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "150"))
 	if err != nil {
 		t.Fatalf("quote after expiry: %v", err)
 	}
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "merchant", 150); err != nil {
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "merchant", mustAmountUnits(t, "150")); err != nil {
 		t.Fatalf("reserve after expiry cleanup: %v", err)
 	}
 }
@@ -291,20 +302,20 @@ func TestEnginePersistenceAcrossRestart(t *testing.T) {
 	clock := newTestClock(base)
 	engine.WithClock(clock.Now)
 	engine.SetPriceMaxAge(24 * time.Hour)
-	engine.RecordPrice("ZNHB", "USD", 1.02, base)
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 100)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.02"), base)
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "100"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	reservation, err := engine.ReserveQuote(ctx, quote.ID, "acct", 100)
+	reservation, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "100"))
 	if err != nil {
 		t.Fatalf("reserve quote: %v", err)
 	}
 	available, reserved, payouts, _ := engine.LedgerBalance("ZNHB")
-	if available != mustAmountUnits(t, 1_000_000-102) {
+	if available != mustAmountUnits(t, "999898") {
 		t.Fatalf("available mismatch after reserve: got %d", available)
 	}
-	if reserved != mustAmountUnits(t, 102) {
+	if reserved != mustAmountUnits(t, "102") {
 		t.Fatalf("reserved mismatch after reserve: got %d", reserved)
 	}
 	if payouts != 0 {
@@ -321,7 +332,7 @@ func TestEnginePersistenceAcrossRestart(t *testing.T) {
 	if !ok {
 		t.Fatalf("ledger missing after restart")
 	}
-	if available != mustAmountUnits(t, 1_000_000-102) || reserved != mustAmountUnits(t, 102) || payouts != 0 {
+	if available != mustAmountUnits(t, "999898") || reserved != mustAmountUnits(t, "102") || payouts != 0 {
 		t.Fatalf("ledger mismatch after restart: available=%d reserved=%d payouts=%d", available, reserved, payouts)
 	}
 	intent, err := engineRestart.CreateCashOutIntent(ctx, reservation.QuoteID)
@@ -332,7 +343,7 @@ func TestEnginePersistenceAcrossRestart(t *testing.T) {
 		t.Fatalf("intent reservation mismatch: got %s want %s", intent.ReservationID, reservation.QuoteID)
 	}
 	available, reserved, payouts, _ = engineRestart.LedgerBalance("ZNHB")
-	if available != mustAmountUnits(t, 1_000_000-102) || reserved != 0 || payouts != mustAmountUnits(t, 102) {
+	if available != mustAmountUnits(t, "999898") || reserved != 0 || payouts != mustAmountUnits(t, "102") {
 		t.Fatalf("ledger mismatch after cashout: available=%d reserved=%d payouts=%d", available, reserved, payouts)
 	}
 
@@ -346,7 +357,7 @@ func TestEnginePersistenceAcrossRestart(t *testing.T) {
 	if !ok {
 		t.Fatalf("ledger missing after final restart")
 	}
-	if available != mustAmountUnits(t, 1_000_000-102) || reserved != 0 || payouts != mustAmountUnits(t, 102) {
+	if available != mustAmountUnits(t, "999898") || reserved != 0 || payouts != mustAmountUnits(t, "102") {
 		t.Fatalf("ledger mismatch after final restart: available=%d reserved=%d payouts=%d", available, reserved, payouts)
 	}
 	if _, err := engineFinal.CreateCashOutIntent(ctx, reservation.QuoteID); !errors.Is(err, ErrReservationConsumed) {
@@ -362,12 +373,12 @@ func TestEngineDailyCapPersistsAcrossRestart(t *testing.T) {
 
 	engine, _ := buildTestEngine(t, base, 1_000, time.Minute, limits)
 	engine.WithDailyUsageStore(store)
-	engine.RecordPrice("ZNHB", "USD", 1.00, time.Time{})
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 100)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.00"), time.Time{})
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "100"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", 100); err != nil {
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "100")); err != nil {
 		t.Fatalf("reserve quote: %v", err)
 	}
 	day, amount, ok, err := store.LatestDailyUsage(ctx)
@@ -377,7 +388,7 @@ func TestEngineDailyCapPersistsAcrossRestart(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected persisted usage record")
 	}
-	wantUnits := mustAmountUnits(t, 100)
+	wantUnits := mustAmountUnits(t, "100")
 	if amount != wantUnits {
 		t.Fatalf("unexpected stored amount: got %d want %d", amount, wantUnits)
 	}
@@ -389,20 +400,20 @@ func TestEngineDailyCapPersistsAcrossRestart(t *testing.T) {
 	restartBase := base.Add(2 * time.Hour)
 	engine2, _ := buildTestEngine(t, restartBase, 1_000, time.Minute, limits)
 	engine2.WithDailyUsageStore(store)
-	engine2.RecordPrice("ZNHB", "USD", 1.00, time.Time{})
+	engine2.RecordPrice("ZNHB", "USD", mustRat(t, "1.00"), time.Time{})
 
-	quote, err = engine2.PriceQuote(ctx, "ZNHB", 40)
+	quote, err = engine2.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "40"))
 	if err != nil {
 		t.Fatalf("price quote after restart: %v", err)
 	}
-	if _, err := engine2.ReserveQuote(ctx, quote.ID, "acct", 40); err != nil {
+	if _, err := engine2.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "40")); err != nil {
 		t.Fatalf("reserve after restart: %v", err)
 	}
-	quote, err = engine2.PriceQuote(ctx, "ZNHB", 20)
+	quote, err = engine2.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "20"))
 	if err != nil {
 		t.Fatalf("price quote for cap check: %v", err)
 	}
-	if _, err := engine2.ReserveQuote(ctx, quote.ID, "acct", 20); !errors.Is(err, ErrDailyCapExceeded) {
+	if _, err := engine2.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "20")); !errors.Is(err, ErrDailyCapExceeded) {
 		t.Fatalf("expected cap exceeded after restart, got %v", err)
 	}
 
@@ -414,7 +425,7 @@ func TestEngineDailyCapPersistsAcrossRestart(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected usage record after restart reservations")
 	}
-	remaining := wantUnits + mustAmountUnits(t, 40)
+	remaining := wantUnits + mustAmountUnits(t, "40")
 	if amount != remaining {
 		t.Fatalf("unexpected stored amount after restart: got %d want %d", amount, remaining)
 	}
@@ -427,28 +438,27 @@ func TestEnginePrecisionPreserved(t *testing.T) {
 	base := time.Date(2024, time.June, 7, 19, 15, 17, 0, time.UTC)
 	engine, _ := buildTestEngine(t, base, 1_000_000, 2*time.Minute, Limits{})
 	ctx := context.Background()
-	engine.RecordPrice("ZNHB", "USD", 1.23456789, time.Time{})
-	amount := 123.456789
-	quote, err := engine.PriceQuote(ctx, "ZNHB", amount)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.23456789"), time.Time{})
+	amountUnits := mustAmountUnits(t, "123.456789")
+	quote, err := engine.PriceQuote(ctx, "ZNHB", amountUnits)
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	if quote.Price != mustRateUnits(t, 1.23456789) {
+	if quote.Price != mustRateUnits(t, "1.23456789") {
 		t.Fatalf("unexpected price units: got %d", quote.Price)
 	}
-	res, err := engine.ReserveQuote(ctx, quote.ID, "acct", amount)
+	res, err := engine.ReserveQuote(ctx, quote.ID, "acct", amountUnits)
 	if err != nil {
 		t.Fatalf("reserve quote: %v", err)
 	}
-	amountUnits := mustAmountUnits(t, amount)
-	rateUnits := mustRateUnits(t, 1.23456789)
+	rateUnits := mustRateUnits(t, "1.23456789")
 	expectedOut := mulDivRound(amountUnits, rateUnits, priceScale)
 	if res.AmountOut != expectedOut {
 		t.Fatalf("unexpected amount out units: got %d want %d", res.AmountOut, expectedOut)
 	}
 	available, reserved, _, _ := engine.LedgerBalance("ZNHB")
-	if available != mustAmountUnits(t, 1_000_000)-expectedOut {
-		t.Fatalf("available mismatch with precision preservation: got %d want %d", available, mustAmountUnits(t, 1_000_000)-expectedOut)
+	if available != mustAmountUnits(t, "1000000")-expectedOut {
+		t.Fatalf("available mismatch with precision preservation: got %d want %d", available, mustAmountUnits(t, "1000000")-expectedOut)
 	}
 	if reserved != expectedOut {
 		t.Fatalf("reserved mismatch with precision preservation: got %d want %d", reserved, expectedOut)
@@ -459,28 +469,28 @@ func TestEngineDailyCapBoundaryExactMatch(t *testing.T) {
 	base := time.Date(2024, time.June, 7, 19, 15, 17, 0, time.UTC)
 	engine, _ := buildTestEngine(t, base, 1_000, time.Minute, Limits{DailyCap: 150})
 	ctx := context.Background()
-	engine.RecordPrice("ZNHB", "USD", 1.0, time.Time{})
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 100)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.0"), time.Time{})
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "100"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", 100); err != nil {
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "100")); err != nil {
 		t.Fatalf("reserve quote: %v", err)
 	}
-	engine.RecordPrice("ZNHB", "USD", 1.0, time.Time{})
-	quote, err = engine.PriceQuote(ctx, "ZNHB", 50)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.0"), time.Time{})
+	quote, err = engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "50"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", 50); err != nil {
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "50")); err != nil {
 		t.Fatalf("reserve quote hitting cap: %v", err)
 	}
-	engine.RecordPrice("ZNHB", "USD", 1.0, time.Time{})
-	quote, err = engine.PriceQuote(ctx, "ZNHB", 0.000001)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.0"), time.Time{})
+	quote, err = engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "0.000001"))
 	if err != nil {
 		t.Fatalf("price quote tiny amount: %v", err)
 	}
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", 0.000001); !errors.Is(err, ErrDailyCapExceeded) {
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "0.000001")); !errors.Is(err, ErrDailyCapExceeded) {
 		t.Fatalf("expected daily cap exceeded on boundary, got %v", err)
 	}
 }
@@ -489,31 +499,34 @@ func TestEngineRejectsExcessPrecisionAmounts(t *testing.T) {
 	base := time.Date(2024, time.June, 7, 19, 15, 17, 0, time.UTC)
 	engine, _ := buildTestEngine(t, base, 1_000, time.Minute, Limits{})
 	ctx := context.Background()
-	engine.RecordPrice("ZNHB", "USD", 1.0, time.Time{})
-	if _, err := engine.PriceQuote(ctx, "ZNHB", 1.0000004); err == nil {
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.0"), time.Time{})
+	if _, err := ParseAmount("1.0000004"); err == nil {
 		t.Fatalf("expected precision error")
 	}
+	if _, err := engine.PriceQuote(ctx, "ZNHB", 0); !errors.Is(err, ErrAmountNotPositive) {
+		t.Fatalf("expected non-positive amount error, got %v", err)
+	}
 }
 
 func TestEngineReserveQuoteRoundingUp(t *testing.T) {
 	base := time.Date(2024, time.June, 7, 19, 15, 17, 0, time.UTC)
 	engine, _ := buildTestEngine(t, base, 10_000, time.Minute, Limits{})
 	ctx := context.Background()
-	engine.RecordPrice("ZNHB", "USD", 1.0000005, time.Time{})
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 1)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.0000005"), time.Time{})
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "1"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	res, err := engine.ReserveQuote(ctx, quote.ID, "acct", 1)
+	res, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "1"))
 	if err != nil {
 		t.Fatalf("reserve quote: %v", err)
 	}
-	expected := mustAmountUnits(t, 1.000001)
+	expected := mustAmountUnits(t, "1.000001")
 	if res.AmountOut != expected {
 		t.Fatalf("unexpected rounded amount: got %d want %d", res.AmountOut, expected)
 	}
 	available, reserved, _, _ := engine.LedgerBalance("ZNHB")
-	if available != mustAmountUnits(t, 10_000)-expected {
+	if available != mustAmountUnits(t, "10000")-expected {
 		t.Fatalf("available mismatch after rounding reserve: got %d", available)
 	}
 	if reserved != expected {
@@ -526,12 +539,12 @@ func TestEngineDailyCapWithLargeValues(t *testing.T) {
 	hugeCap := int64(math.MaxInt64 / amountScale)
 	engine, _ := buildTestEngine(t, base, 1_000_000_000, time.Minute, Limits{DailyCap: hugeCap})
 	ctx := context.Background()
-	engine.RecordPrice("ZNHB", "USD", 1.01, time.Time{})
-	quote, err := engine.PriceQuote(ctx, "ZNHB", 100)
+	engine.RecordPrice("ZNHB", "USD", mustRat(t, "1.01"), time.Time{})
+	quote, err := engine.PriceQuote(ctx, "ZNHB", mustAmountUnits(t, "100"))
 	if err != nil {
 		t.Fatalf("price quote: %v", err)
 	}
-	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", 100); err != nil {
+	if _, err := engine.ReserveQuote(ctx, quote.ID, "acct", mustAmountUnits(t, "100")); err != nil {
 		t.Fatalf("reserve quote under large cap: %v", err)
 	}
 	available, reserved, _, _ := engine.LedgerBalance("ZNHB")
@@ -539,3 +552,82 @@ func TestEngineDailyCapWithLargeValues(t *testing.T) {
 		t.Fatalf("ledger not updated for large cap scenario: available=%d reserved=%d", available, reserved)
 	}
 }
+
+func TestEngineReserveReleaseNetsToZero(t *testing.T) {
+	store := openTestStorage(t)
+	ctx := context.Background()
+	base := time.Date(2024, time.August, 1, 8, 0, 0, 0, time.UTC)
+	asset := Asset{
+		Symbol:         "ZNHB",
+		BasePair:       "ZNHB",
+		QuotePair:      "USD",
+		QuoteTTL:       time.Minute,
+		MaxSlippageBps: 50,
+		SoftInventory:  100_000_000,
+	}
+	engine, err := NewEngine([]Asset{asset}, Limits{DailyCap: 1_000_000_000}, store)
+	if err != nil {
+		t.Fatalf("new engine: %v", err)
+	}
+	engine.WithDailyUsageStore(store)
+	clock := newTestClock(base)
+	engine.WithClock(clock.Now)
+	engine.SetPriceMaxAge(24 * time.Hour)
+
+	initialAvailable, initialReserved, initialPayouts, _ := engine.LedgerBalance("ZNHB")
+	property := func(rawAmount, rawRate uint64, expire bool) bool {
+		// Amounts span one unit to a million tokens and rates span one rate
+		// unit to 10.0, covering values no float64 path could round-trip.
+		amount := int64(rawAmount%1_000_000_000_000) + 1
+		rate := int64(rawRate%10_000_000_000) + 1
+		engine.RecordPrice("ZNHB", "USD", RateRat(rate), time.Time{})
+		quote, err := engine.PriceQuote(ctx, "ZNHB", amount)
+		if err != nil {
+			t.Logf("price quote amount=%d rate=%d: %v", amount, rate, err)
+			return false
+		}
+		res, err := engine.ReserveQuote(ctx, quote.ID, "acct", amount)
+		if err != nil {
+			t.Logf("reserve amount=%d rate=%d: %v", amount, rate, err)
+			return false
+		}
+		if expire {
+			clock.Advance(2 * time.Minute)
+			if _, err := engine.CreateCashOutIntent(ctx, res.QuoteID); !errors.Is(err, ErrReservationExpired) {
+				t.Logf("expected expiry release, got %v", err)
+				return false
+			}
+		} else if err := engine.CancelReservation(ctx, res.QuoteID); err != nil {
+			t.Logf("cancel reservation: %v", err)
+			return false
+		}
+		available, reserved, payouts, _ := engine.LedgerBalance("ZNHB")
+		if available != initialAvailable || reserved != initialReserved || payouts != initialPayouts {
+			t.Logf("ledger drifted: available=%d reserved=%d payouts=%d", available, reserved, payouts)
+			return false
+		}
+		if engine.daily.amount != 0 {
+			t.Logf("daily usage drifted: %d", engine.daily.amount)
+			return false
+		}
+		balances, err := store.LoadLedgerBalances(ctx)
+		if err != nil || len(balances) != 1 {
+			t.Logf("load persisted ledger: %v", err)
+			return false
+		}
+		persisted := balances[0]
+		if persisted.Available != initialAvailable || persisted.Reserved != initialReserved || persisted.Payouts != initialPayouts {
+			t.Logf("persisted ledger drifted: %+v", persisted)
+			return false
+		}
+		reservations, err := store.LoadReservations(ctx)
+		if err != nil || len(reservations) != 0 {
+			t.Logf("persisted reservations left behind: %d (%v)", len(reservations), err)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,10 +2,11 @@ package stable
 
 import "context"
 
-// QuoteRequest describes input for a quote calculation.
+// QuoteRequest describes input for a quote calculation. Amount is expressed
+// in scaled amount units (see ParseAmount).
 type QuoteRequest struct {
 	Asset  string
-	Amount float64
+	Amount int64
 }
 
 // QuoteResponse wraps the result of a quote lookup.
@@ -9,11 +9,12 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
-// ReserveRequest captures the required fields for a reservation.
+// ReserveRequest captures the required fields for a reservation. AmountIn is
+// expressed in scaled amount units and must equal the quoted amount.
 type ReserveRequest struct {
 	QuoteID  string
 	Account  string
-	AmountIn float64
+	AmountIn int64
 }
 
 // ReserveResponse returns the confirmed reservation details.
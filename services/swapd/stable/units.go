@@ -0,0 +1,158 @@
+package stable
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// The engine never handles money as floating point. Amounts are carried as
+// int64 minor units at amountScale (six decimals) and rates as int64 units at
+// priceScale (nine decimals). Decimal input is parsed exactly via big.Rat and
+// output is rendered from the integers, so a value only ever changes when a
+// documented rounding rule says it does.
+
+// ParseAmount converts a decimal string such as "12.5" into scaled amount
+// units. Values with more precision than the amount scale supports are
+// rejected rather than rounded.
+func ParseAmount(value string) (int64, error) {
+	r, err := parseDecimal(value)
+	if err != nil {
+		return 0, fmt.Errorf("amount: %w", err)
+	}
+	return AmountUnitsFromRat(r)
+}
+
+// ParseRate converts a decimal string into scaled rate units. Values with more
+// precision than the rate scale supports are rejected rather than rounded.
+func ParseRate(value string) (int64, error) {
+	r, err := parseDecimal(value)
+	if err != nil {
+		return 0, fmt.Errorf("rate: %w", err)
+	}
+	units, exact, err := ratToUnits(r, priceScale)
+	if err != nil {
+		return 0, fmt.Errorf("rate: %w", err)
+	}
+	if !exact {
+		return 0, fmt.Errorf("rate precision exceeds supported scale")
+	}
+	return units, nil
+}
+
+// AmountUnitsFromRat converts an exact amount into scaled amount units,
+// rejecting values that would need rounding.
+func AmountUnitsFromRat(amount *big.Rat) (int64, error) {
+	units, exact, err := ratToUnits(amount, amountScale)
+	if err != nil {
+		return 0, fmt.Errorf("amount: %w", err)
+	}
+	if !exact {
+		return 0, fmt.Errorf("amount precision exceeds supported scale")
+	}
+	return units, nil
+}
+
+// RateUnitsFromRat converts an oracle rate into scaled rate units, rounding
+// half away from zero to the nearest unit. Oracle medians routinely carry
+// more decimals than the engine keeps, so unlike user supplied amounts they
+// are rounded instead of rejected.
+func RateUnitsFromRat(rate *big.Rat) (int64, error) {
+	if rate == nil || rate.Sign() <= 0 {
+		return 0, fmt.Errorf("rate must be positive")
+	}
+	scaled := new(big.Rat).Mul(rate, new(big.Rat).SetInt64(priceScale))
+	units := roundHalfUp(scaled)
+	if !units.IsInt64() {
+		return 0, fmt.Errorf("rate out of range")
+	}
+	if units.Sign() <= 0 {
+		return 0, fmt.Errorf("rate must be positive")
+	}
+	return units.Int64(), nil
+}
+
+// FormatAmount renders scaled amount units as a decimal string without
+// trailing zeros, e.g. 1_500_000 -> "1.5".
+func FormatAmount(units int64) string {
+	return formatUnits(units, amountScale)
+}
+
+// FormatRate renders scaled rate units as a decimal string without trailing
+// zeros.
+func FormatRate(units int64) string {
+	return formatUnits(units, priceScale)
+}
+
+// AmountRat returns scaled amount units as an exact rational value.
+func AmountRat(units int64) *big.Rat {
+	return big.NewRat(units, amountScale)
+}
+
+// RateRat returns scaled rate units as an exact rational value.
+func RateRat(units int64) *big.Rat {
+	return big.NewRat(units, priceScale)
+}
+
+func parseDecimal(value string) (*big.Rat, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil, fmt.Errorf("value required")
+	}
+	// big.Rat also accepts fractions such as "1/3"; only decimal notation is
+	// a valid monetary input.
+	if strings.Contains(trimmed, "/") {
+		return nil, fmt.Errorf("invalid decimal %q", trimmed)
+	}
+	r, ok := new(big.Rat).SetString(trimmed)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", trimmed)
+	}
+	return r, nil
+}
+
+func ratToUnits(value *big.Rat, scale int64) (int64, bool, error) {
+	if value == nil || value.Sign() <= 0 {
+		return 0, false, fmt.Errorf("must be positive")
+	}
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt64(scale))
+	if !scaled.IsInt() {
+		return 0, false, nil
+	}
+	units := scaled.Num()
+	if !units.IsInt64() {
+		return 0, false, fmt.Errorf("out of range")
+	}
+	return units.Int64(), true, nil
+}
+
+func roundHalfUp(value *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	doubled := new(big.Int).Lsh(new(big.Int).Abs(remainder), 1)
+	if doubled.Cmp(value.Denom()) >= 0 {
+		if value.Sign() >= 0 {
+			quotient.Add(quotient, big.NewInt(1))
+		} else {
+			quotient.Sub(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+func formatUnits(units, scale int64) string {
+	sign := ""
+	magnitude := new(big.Int).SetInt64(units)
+	if magnitude.Sign() < 0 {
+		sign = "-"
+		magnitude.Neg(magnitude)
+	}
+	whole, frac := new(big.Int).QuoRem(magnitude, big.NewInt(scale), new(big.Int))
+	if frac.Sign() == 0 {
+		return sign + whole.String()
+	}
+	digits := len(strconv.FormatInt(scale, 10)) - 1
+	fraction := frac.String()
+	fraction = strings.Repeat("0", digits-len(fraction)) + fraction
+	return sign + whole.String() + "." + strings.TrimRight(fraction, "0")
+}
@@ -0,0 +1,77 @@
+package stable
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestParseAmountExact(t *testing.T) {
+	cases := map[string]int64{
+		"1":             1_000_000,
+		"0.000001":      1,
+		"123.456789":    123_456_789,
+		"1e-6":          1,
+		" 2.50 ":        2_500_000,
+		"9223372036854": 9_223_372_036_854_000_000,
+	}
+	for input, want := range cases {
+		got, err := ParseAmount(input)
+		if err != nil {
+			t.Fatalf("parse %q: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("parse %q: got %d want %d", input, got, want)
+		}
+	}
+	for _, input := range []string{"", "0", "-1", "1.0000001", "1/3", "abc", "9223372036855"} {
+		if _, err := ParseAmount(input); err == nil {
+			t.Fatalf("expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestRateUnitsFromRatRoundsHalfUp(t *testing.T) {
+	cases := map[string]int64{
+		"1.23456789":           1_234_567_890,
+		"1.0000000005":         1_000_000_001,
+		"1.0000000004999":      1_000_000_000,
+		"0.000000000500000000": 1,
+	}
+	for input, want := range cases {
+		rate, _ := new(big.Rat).SetString(input)
+		got, err := RateUnitsFromRat(rate)
+		if err != nil {
+			t.Fatalf("rate %q: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("rate %q: got %d want %d", input, got, want)
+		}
+	}
+	if _, err := RateUnitsFromRat(big.NewRat(1, 10_000_000_000)); err == nil {
+		t.Fatalf("expected rate rounding to zero to be rejected")
+	}
+	if _, err := ParseRate("1.0000000005"); err == nil {
+		t.Fatalf("expected ParseRate to reject excess precision")
+	}
+}
+
+func TestFormatAmountRoundTrips(t *testing.T) {
+	if got := FormatAmount(1_500_000); got != "1.5" {
+		t.Fatalf("format: got %q", got)
+	}
+	if got := FormatRate(1_020_000_000); got != "1.02" {
+		t.Fatalf("format rate: got %q", got)
+	}
+	if got := FormatAmount(-1); got != "-0.000001" {
+		t.Fatalf("format negative: got %q", got)
+	}
+	property := func(raw uint64) bool {
+		units := int64(raw>>2) + 1
+		parsed, err := ParseAmount(FormatAmount(units))
+		return err == nil && parsed == units && AmountRat(units).Cmp(big.NewRat(units, amountScale)) == 0
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Fatal(err)
+	}
+}
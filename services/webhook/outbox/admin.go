@@ -0,0 +1,160 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewAdminHandler exposes operator endpoints for an outbox. Routes are
+// relative to wherever the handler is mounted:
+//
+//	GET    /pending                   list queued messages
+//	GET    /dead-letters              list dead letters
+//	POST   /dead-letters/{id}/replay  move a dead letter back onto the queue
+//	DELETE /dead-letters/{id}         purge a single dead letter
+//	DELETE /dead-letters              purge dead letters matching a filter
+//
+// List and bulk purge requests accept subscriber, before (RFC 3339) and limit
+// query parameters. A bulk purge without any filter must pass all=true. The
+// handler performs no authentication; callers are expected to wrap it.
+func NewAdminHandler(o *Outbox) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pending", func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseFilter(r)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		msgs, err := o.Pending(r.Context(), filter)
+		if err != nil {
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+		views := make([]messageView, 0, len(msgs))
+		for _, msg := range msgs {
+			views = append(views, messageView{Message: msg, Payload: payloadJSON(msg.Payload)})
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"messages": views})
+	})
+	mux.HandleFunc("GET /dead-letters", func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseFilter(r)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		letters, err := o.DeadLetters(r.Context(), filter)
+		if err != nil {
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+		views := make([]deadLetterView, 0, len(letters))
+		for _, dl := range letters {
+			views = append(views, deadLetterView{DeadLetter: dl, Payload: payloadJSON(dl.Payload)})
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"deadLetters": views})
+	})
+	mux.HandleFunc("POST /dead-letters/{id}/replay", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, errors.New("invalid dead letter id"))
+			return
+		}
+		newID, err := o.Replay(r.Context(), id)
+		if err != nil {
+			writeAdminError(w, statusForError(err), err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"replayed": id, "messageId": newID})
+	})
+	mux.HandleFunc("DELETE /dead-letters/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, errors.New("invalid dead letter id"))
+			return
+		}
+		if err := o.PurgeDeadLetter(r.Context(), id); err != nil {
+			writeAdminError(w, statusForError(err), err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"purged": 1})
+	})
+	mux.HandleFunc("DELETE /dead-letters", func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseFilter(r)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		if filter.Subscriber == "" && filter.Before.IsZero() && r.URL.Query().Get("all") != "true" {
+			writeAdminError(w, http.StatusBadRequest, errors.New("subscriber, before or all=true required"))
+			return
+		}
+		purged, err := o.PurgeDeadLetters(r.Context(), filter)
+		if err != nil {
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"purged": purged})
+	})
+	return mux
+}
+
+type messageView struct {
+	Message
+	Payload json.RawMessage `json:"payload"`
+}
+
+type deadLetterView struct {
+	DeadLetter
+	Payload json.RawMessage `json:"payload"`
+}
+
+func parseFilter(r *http.Request) (Filter, error) {
+	query := r.URL.Query()
+	filter := Filter{Subscriber: strings.TrimSpace(query.Get("subscriber"))}
+	if raw := strings.TrimSpace(query.Get("before")); raw != "" {
+		before, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return Filter{}, errors.New("before must be an RFC 3339 timestamp")
+		}
+		filter.Before = before
+	}
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return Filter{}, errors.New("limit must be a positive integer")
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// payloadJSON returns payloads that are valid JSON verbatim and quotes
+// anything else so listings always render.
+func payloadJSON(payload []byte) json.RawMessage {
+	if json.Valid(payload) {
+		return json.RawMessage(payload)
+	}
+	quoted, _ := json.Marshal(string(payload))
+	return quoted
+}
+
+func statusForError(err error) int {
+	if errors.Is(err, ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,633 @@
+// Package outbox provides a durable, SQLite backed delivery queue for
+// outbound webhooks. Messages are persisted before delivery is attempted,
+// delivered strictly in order per subscriber, retried with exponential
+// backoff and parked in a dead-letter table once the retry budget is spent so
+// operators can inspect, replay or purge them.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxAttempts  = 5
+	defaultMinBackoff   = time.Second
+	defaultMaxBackoff   = 5 * time.Minute
+	defaultPollInterval = time.Second
+	defaultConcurrency  = 8
+	defaultListLimit    = 100
+	maxListLimit        = 1000
+	maxErrorLength      = 1024
+)
+
+var (
+	// ErrNotFound is returned when a referenced message does not exist.
+	ErrNotFound = errors.New("outbox: message not found")
+	// ErrSubscriberRequired is returned when enqueuing without a subscriber.
+	ErrSubscriberRequired = errors.New("outbox: subscriber required")
+)
+
+// Message is a pending delivery.
+type Message struct {
+	ID            int64     `json:"id"`
+	Subscriber    string    `json:"subscriber"`
+	EventType     string    `json:"eventType"`
+	Payload       []byte    `json:"-"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	LastError     string    `json:"lastError,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// DeadLetter is a message that exhausted its retry budget or failed
+// permanently.
+type DeadLetter struct {
+	ID         int64     `json:"id"`
+	OriginalID int64     `json:"originalId"`
+	Subscriber string    `json:"subscriber"`
+	EventType  string    `json:"eventType"`
+	Payload    []byte    `json:"-"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"lastError,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	FailedAt   time.Time `json:"failedAt"`
+}
+
+// Filter narrows list and purge operations. Zero values match everything.
+type Filter struct {
+	Subscriber string
+	Before     time.Time
+	Limit      int
+}
+
+// RetryPolicy controls how failed deliveries are rescheduled.
+type RetryPolicy struct {
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: defaultMaxAttempts, MinBackoff: defaultMinBackoff, MaxBackoff: defaultMaxBackoff}
+}
+
+// Backoff returns the delay before the next attempt once attempt deliveries
+// have failed. The delay doubles per attempt and is capped at MaxBackoff.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt <= 0 {
+		attempt = 1
+	}
+	delay := p.MinBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= p.MaxBackoff || delay <= 0 {
+			return p.MaxBackoff
+		}
+	}
+	if delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+func (p RetryPolicy) sanitized() RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = def.MinBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = p.MinBackoff
+		if def.MaxBackoff > p.MaxBackoff {
+			p.MaxBackoff = def.MaxBackoff
+		}
+	}
+	return p
+}
+
+// DeliverFunc attempts a single delivery. Returning nil acknowledges the
+// message. Errors wrapped with Permanent dead-letter the message immediately
+// and errors produced by Defer reschedule it without consuming an attempt.
+type DeliverFunc func(ctx context.Context, msg Message) error
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a delivery error as non-retryable.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+type deferError struct{ until time.Time }
+
+func (e *deferError) Error() string {
+	return fmt.Sprintf("outbox: delivery deferred until %s", e.until.UTC().Format(time.RFC3339Nano))
+}
+
+// Defer postpones a delivery until the supplied time without counting it as
+// a failed attempt. It is intended for local throttling such as rate limits.
+func Defer(until time.Time) error {
+	return &deferError{until: until}
+}
+
+// Option customises an Outbox.
+type Option func(*Outbox)
+
+// WithRetryPolicy overrides the retry configuration.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *Outbox) {
+		o.policy = policy.sanitized()
+	}
+}
+
+// WithPollInterval sets how often Run checks for due messages when it has not
+// been woken by a new enqueue.
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *Outbox) {
+		if interval > 0 {
+			o.pollInterval = interval
+		}
+	}
+}
+
+// WithConcurrency bounds how many subscribers are delivered to in parallel.
+func WithConcurrency(n int) Option {
+	return func(o *Outbox) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithClock overrides the time source.
+func WithClock(now func() time.Time) Option {
+	return func(o *Outbox) {
+		if now != nil {
+			o.now = now
+		}
+	}
+}
+
+// Outbox persists webhook deliveries in SQLite. The caller owns the database
+// handle; the outbox only creates and uses its own tables. A single Run loop
+// should be active per outbox.
+type Outbox struct {
+	db           *sql.DB
+	policy       RetryPolicy
+	pollInterval time.Duration
+	concurrency  int
+	now          func() time.Time
+
+	// SQLite serialises writers; funnelling them through one mutex avoids
+	// spurious SQLITE_BUSY errors when several subscribers settle at once.
+	writeMu sync.Mutex
+	wake    chan struct{}
+}
+
+// New prepares the outbox tables in db and returns a ready outbox.
+func New(db *sql.DB, opts ...Option) (*Outbox, error) {
+	if db == nil {
+		return nil, errors.New("outbox: database required")
+	}
+	o := &Outbox{
+		db:           db,
+		policy:       DefaultRetryPolicy(),
+		pollInterval: defaultPollInterval,
+		concurrency:  defaultConcurrency,
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if err := o.init(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *Outbox) init() error {
+	schema := []string{
+		`CREATE TABLE IF NOT EXISTS webhook_outbox (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            subscriber TEXT NOT NULL,
+            event_type TEXT NOT NULL,
+            payload BLOB NOT NULL,
+            attempts INTEGER NOT NULL DEFAULT 0,
+            next_attempt_at INTEGER NOT NULL,
+            last_error TEXT NOT NULL DEFAULT '',
+            created_at INTEGER NOT NULL
+        );`,
+		`CREATE INDEX IF NOT EXISTS webhook_outbox_subscriber_idx ON webhook_outbox(subscriber, id);`,
+		`CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            original_id INTEGER NOT NULL,
+            subscriber TEXT NOT NULL,
+            event_type TEXT NOT NULL,
+            payload BLOB NOT NULL,
+            attempts INTEGER NOT NULL,
+            last_error TEXT NOT NULL DEFAULT '',
+            created_at INTEGER NOT NULL,
+            failed_at INTEGER NOT NULL
+        );`,
+		`CREATE INDEX IF NOT EXISTS webhook_dead_letters_subscriber_idx ON webhook_dead_letters(subscriber, id);`,
+	}
+	for _, stmt := range schema {
+		if _, err := o.db.Exec(stmt); err != nil {
+			return fmt.Errorf("outbox: init schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// RetryPolicy reports the active retry configuration.
+func (o *Outbox) RetryPolicy() RetryPolicy {
+	return o.policy
+}
+
+// Enqueue persists a message for subscriber. Messages for the same
+// subscriber are delivered in the order they were enqueued.
+func (o *Outbox) Enqueue(ctx context.Context, subscriber, eventType string, payload []byte) (int64, error) {
+	subscriber = strings.TrimSpace(subscriber)
+	if subscriber == "" {
+		return 0, ErrSubscriberRequired
+	}
+	now := o.now()
+	o.writeMu.Lock()
+	res, err := o.db.ExecContext(ctx,
+		`INSERT INTO webhook_outbox(subscriber, event_type, payload, attempts, next_attempt_at, created_at) VALUES (?, ?, ?, 0, ?, ?)`,
+		subscriber, eventType, append([]byte(nil), payload...), now.UnixNano(), now.UnixNano())
+	o.writeMu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("outbox: enqueue: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("outbox: enqueue: %w", err)
+	}
+	o.notify()
+	return id, nil
+}
+
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers due messages until ctx is cancelled.
+func (o *Outbox) Run(ctx context.Context, deliver DeliverFunc) error {
+	if deliver == nil {
+		return errors.New("outbox: deliver func required")
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		case <-o.wake:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		}
+		processed, err := o.DeliverDue(ctx, deliver)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		wait := o.pollInterval
+		if err == nil && processed > 0 {
+			// Heads were settled; the next message per subscriber may already
+			// be due.
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// DeliverDue performs one delivery pass: the oldest message of every
+// subscriber is attempted if it is due. Later messages for a subscriber wait
+// until its head is acknowledged or dead-lettered. It returns the number of
+// messages that were settled (acknowledged or dead-lettered).
+func (o *Outbox) DeliverDue(ctx context.Context, deliver DeliverFunc) (int, error) {
+	heads, err := o.dueHeads(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(heads) == 0 {
+		return 0, nil
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		settled  int
+		firstErr error
+		sem      = make(chan struct{}, o.concurrency)
+	)
+	for _, msg := range heads {
+		msg := msg
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			done, err := o.settle(ctx, msg, deliver(ctx, msg))
+			mu.Lock()
+			defer mu.Unlock()
+			if done {
+				settled++
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}()
+	}
+	wg.Wait()
+	return settled, firstErr
+}
+
+func (o *Outbox) dueHeads(ctx context.Context) ([]Message, error) {
+	const query = `SELECT o.id, o.subscriber, o.event_type, o.payload, o.attempts, o.next_attempt_at, o.last_error, o.created_at
+        FROM webhook_outbox o
+        JOIN (SELECT subscriber, MIN(id) AS head FROM webhook_outbox GROUP BY subscriber) h ON o.id = h.head
+        WHERE o.next_attempt_at <= ?
+        ORDER BY o.id`
+	rows, err := o.db.QueryContext(ctx, query, o.now().UnixNano())
+	if err != nil {
+		return nil, fmt.Errorf("outbox: load due messages: %w", err)
+	}
+	defer rows.Close()
+	var heads []Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		heads = append(heads, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return heads, nil
+}
+
+// settle records the outcome of a delivery attempt. It reports whether the
+// message left the pending queue.
+func (o *Outbox) settle(ctx context.Context, msg Message, deliveryErr error) (bool, error) {
+	if ctx.Err() != nil && deliveryErr != nil {
+		// Shutting down mid-delivery is not the receiver's fault; leave the
+		// message untouched so it is retried after restart.
+		return false, nil
+	}
+	// Outcomes are recorded even if ctx is cancelled afterwards so a
+	// delivered message is not sent twice.
+	ctx = context.WithoutCancel(ctx)
+	if deliveryErr == nil {
+		return true, o.ack(ctx, msg.ID)
+	}
+	var deferred *deferError
+	if errors.As(deliveryErr, &deferred) {
+		return false, o.reschedule(ctx, msg.ID, msg.Attempts, deferred.until, msg.LastError)
+	}
+	attempts := msg.Attempts + 1
+	errMsg := truncateError(deliveryErr.Error())
+	var permanent *permanentError
+	if errors.As(deliveryErr, &permanent) || attempts >= o.policy.MaxAttempts {
+		msg.Attempts = attempts
+		msg.LastError = errMsg
+		return true, o.deadLetter(ctx, msg)
+	}
+	return false, o.reschedule(ctx, msg.ID, attempts, o.now().Add(o.policy.Backoff(attempts)), errMsg)
+}
+
+func (o *Outbox) ack(ctx context.Context, id int64) error {
+	o.writeMu.Lock()
+	defer o.writeMu.Unlock()
+	if _, err := o.db.ExecContext(ctx, `DELETE FROM webhook_outbox WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("outbox: ack %d: %w", id, err)
+	}
+	return nil
+}
+
+func (o *Outbox) reschedule(ctx context.Context, id int64, attempts int, next time.Time, lastError string) error {
+	o.writeMu.Lock()
+	defer o.writeMu.Unlock()
+	_, err := o.db.ExecContext(ctx,
+		`UPDATE webhook_outbox SET attempts = ?, next_attempt_at = ?, last_error = ? WHERE id = ?`,
+		attempts, next.UnixNano(), lastError, id)
+	if err != nil {
+		return fmt.Errorf("outbox: reschedule %d: %w", id, err)
+	}
+	return nil
+}
+
+func (o *Outbox) deadLetter(ctx context.Context, msg Message) error {
+	o.writeMu.Lock()
+	defer o.writeMu.Unlock()
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("outbox: dead-letter %d: %w", msg.ID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO webhook_dead_letters(original_id, subscriber, event_type, payload, attempts, last_error, created_at, failed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		msg.ID, msg.Subscriber, msg.EventType, msg.Payload, msg.Attempts, msg.LastError, msg.CreatedAt.UnixNano(), o.now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("outbox: dead-letter %d: %w", msg.ID, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_outbox WHERE id = ?`, msg.ID); err != nil {
+		return fmt.Errorf("outbox: dead-letter %d: %w", msg.ID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("outbox: dead-letter %d: %w", msg.ID, err)
+	}
+	return nil
+}
+
+// Pending lists queued messages in delivery order.
+func (o *Outbox) Pending(ctx context.Context, filter Filter) ([]Message, error) {
+	query := `SELECT id, subscriber, event_type, payload, attempts, next_attempt_at, last_error, created_at FROM webhook_outbox`
+	where, args := filter.clauses()
+	rows, err := o.db.QueryContext(ctx, query+where+` ORDER BY id LIMIT ?`, append(args, filter.limit())...)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: list pending: %w", err)
+	}
+	defer rows.Close()
+	var out []Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, msg)
+	}
+	return out, rows.Err()
+}
+
+// DeadLetters lists dead-lettered messages, oldest first.
+func (o *Outbox) DeadLetters(ctx context.Context, filter Filter) ([]DeadLetter, error) {
+	query := `SELECT id, original_id, subscriber, event_type, payload, attempts, last_error, created_at, failed_at FROM webhook_dead_letters`
+	where, args := filter.clauses()
+	rows, err := o.db.QueryContext(ctx, query+where+` ORDER BY id LIMIT ?`, append(args, filter.limit())...)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: list dead letters: %w", err)
+	}
+	defer rows.Close()
+	var out []DeadLetter
+	for rows.Next() {
+		var (
+			dl                  DeadLetter
+			createdAt, failedAt int64
+		)
+		if err := rows.Scan(&dl.ID, &dl.OriginalID, &dl.Subscriber, &dl.EventType, &dl.Payload, &dl.Attempts, &dl.LastError, &createdAt, &failedAt); err != nil {
+			return nil, err
+		}
+		dl.CreatedAt = time.Unix(0, createdAt).UTC()
+		dl.FailedAt = time.Unix(0, failedAt).UTC()
+		out = append(out, dl)
+	}
+	return out, rows.Err()
+}
+
+// Replay moves a dead letter back onto the pending queue with a fresh retry
+// budget. The message is appended behind anything already queued for its
+// subscriber. It returns the id of the new pending message.
+func (o *Outbox) Replay(ctx context.Context, deadLetterID int64) (int64, error) {
+	o.writeMu.Lock()
+	defer o.writeMu.Unlock()
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: replay %d: %w", deadLetterID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var (
+		subscriber, eventType string
+		payload               []byte
+	)
+	row := tx.QueryRowContext(ctx, `SELECT subscriber, event_type, payload FROM webhook_dead_letters WHERE id = ?`, deadLetterID)
+	if err := row.Scan(&subscriber, &eventType, &payload); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("outbox: replay %d: %w", deadLetterID, err)
+	}
+	now := o.now().UnixNano()
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO webhook_outbox(subscriber, event_type, payload, attempts, next_attempt_at, created_at) VALUES (?, ?, ?, 0, ?, ?)`,
+		subscriber, eventType, payload, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: replay %d: %w", deadLetterID, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("outbox: replay %d: %w", deadLetterID, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_dead_letters WHERE id = ?`, deadLetterID); err != nil {
+		return 0, fmt.Errorf("outbox: replay %d: %w", deadLetterID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("outbox: replay %d: %w", deadLetterID, err)
+	}
+	o.notify()
+	return id, nil
+}
+
+// PurgeDeadLetter permanently removes a single dead letter.
+func (o *Outbox) PurgeDeadLetter(ctx context.Context, id int64) error {
+	o.writeMu.Lock()
+	defer o.writeMu.Unlock()
+	res, err := o.db.ExecContext(ctx, `DELETE FROM webhook_dead_letters WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("outbox: purge %d: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PurgeDeadLetters removes every dead letter matching filter and returns the
+// number of rows deleted. Filter.Limit is ignored.
+func (o *Outbox) PurgeDeadLetters(ctx context.Context, filter Filter) (int64, error) {
+	where, args := filter.clauses()
+	o.writeMu.Lock()
+	defer o.writeMu.Unlock()
+	res, err := o.db.ExecContext(ctx, `DELETE FROM webhook_dead_letters`+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: purge dead letters: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (f Filter) clauses() (string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if subscriber := strings.TrimSpace(f.Subscriber); subscriber != "" {
+		conds = append(conds, "subscriber = ?")
+		args = append(args, subscriber)
+	}
+	if !f.Before.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, f.Before.UnixNano())
+	}
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (f Filter) limit() int {
+	switch {
+	case f.Limit <= 0:
+		return defaultListLimit
+	case f.Limit > maxListLimit:
+		return maxListLimit
+	default:
+		return f.Limit
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMessage(row scanner) (Message, error) {
+	var (
+		msg                  Message
+		nextAttempt, created int64
+	)
+	if err := row.Scan(&msg.ID, &msg.Subscriber, &msg.EventType, &msg.Payload, &msg.Attempts, &nextAttempt, &msg.LastError, &created); err != nil {
+		return Message{}, err
+	}
+	msg.NextAttemptAt = time.Unix(0, nextAttempt).UTC()
+	msg.CreatedAt = time.Unix(0, created).UTC()
+	return msg, nil
+}
+
+func truncateError(msg string) string {
+	if len(msg) > maxErrorLength {
+		return msg[:maxErrorLength]
+	}
+	return msg
+}
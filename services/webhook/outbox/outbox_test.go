@@ -0,0 +1,346 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func openTestDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func newTestOutbox(t *testing.T, clock *testClock, opts ...Option) *Outbox {
+	t.Helper()
+	db := openTestDB(t, filepath.Join(t.TempDir(), "outbox.db"))
+	opts = append([]Option{WithClock(clock.Now)}, opts...)
+	ob, err := New(db, opts...)
+	if err != nil {
+		t.Fatalf("new outbox: %v", err)
+	}
+	return ob
+}
+
+func mustEnqueue(t *testing.T, ob *Outbox, subscriber, payload string) int64 {
+	t.Helper()
+	id, err := ob.Enqueue(context.Background(), subscriber, "test.event", []byte(payload))
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	return id
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want {
+			t.Fatalf("attempt %d: expected %s got %s", i+1, want, got)
+		}
+	}
+	if got := policy.Backoff(200); got != 10*time.Second {
+		t.Fatalf("expected cap for large attempt, got %s", got)
+	}
+}
+
+func TestDeliverDuePreservesPerSubscriberOrder(t *testing.T) {
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	ob := newTestOutbox(t, clock, WithRetryPolicy(RetryPolicy{MaxAttempts: 5, MinBackoff: time.Second, MaxBackoff: time.Minute}))
+	ctx := context.Background()
+
+	mustEnqueue(t, ob, "a", `"a1"`)
+	mustEnqueue(t, ob, "b", `"b1"`)
+	mustEnqueue(t, ob, "a", `"a2"`)
+
+	var (
+		mu        sync.Mutex
+		delivered []string
+		failA     = true
+	)
+	deliver := func(_ context.Context, msg Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if msg.Subscriber == "a" && failA {
+			return errors.New("receiver down")
+		}
+		delivered = append(delivered, string(msg.Payload))
+		return nil
+	}
+
+	if _, err := ob.DeliverDue(ctx, deliver); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	// b is independent of a; a2 must wait behind the failing a1.
+	if len(delivered) != 1 || delivered[0] != `"b1"` {
+		t.Fatalf("unexpected deliveries %v", delivered)
+	}
+	pending, err := ob.Pending(ctx, Filter{Subscriber: "a"})
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 2 || pending[0].Attempts != 1 || pending[0].LastError != "receiver down" {
+		t.Fatalf("unexpected pending state %+v", pending)
+	}
+	if !pending[0].NextAttemptAt.Equal(clock.Now().Add(time.Second)) {
+		t.Fatalf("expected retry after 1s, got %s", pending[0].NextAttemptAt)
+	}
+
+	// Not due yet: nothing is attempted.
+	if n, err := ob.DeliverDue(ctx, deliver); err != nil || n != 0 {
+		t.Fatalf("expected no due messages, got %d (%v)", n, err)
+	}
+
+	failA = false
+	clock.Advance(time.Second)
+	for i := 0; i < 2; i++ {
+		if _, err := ob.DeliverDue(ctx, deliver); err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+	}
+	want := []string{`"b1"`, `"a1"`, `"a2"`}
+	if len(delivered) != len(want) {
+		t.Fatalf("expected %v got %v", want, delivered)
+	}
+	for i := range want {
+		if delivered[i] != want[i] {
+			t.Fatalf("expected %v got %v", want, delivered)
+		}
+	}
+}
+
+func TestExhaustedMessagesAreDeadLetteredAndReplayable(t *testing.T) {
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	ob := newTestOutbox(t, clock, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Second, MaxBackoff: time.Second}))
+	ctx := context.Background()
+
+	first := mustEnqueue(t, ob, "a", `{"n":1}`)
+	mustEnqueue(t, ob, "a", `{"n":2}`)
+
+	failing := func(context.Context, Message) error { return errors.New("HTTP 500") }
+	for i := 0; i < 3; i++ {
+		if _, err := ob.DeliverDue(ctx, failing); err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+		clock.Advance(time.Second)
+	}
+	letters, err := ob.DeadLetters(ctx, Filter{})
+	if err != nil {
+		t.Fatalf("dead letters: %v", err)
+	}
+	if len(letters) != 1 || letters[0].OriginalID != first || letters[0].Attempts != 3 || letters[0].LastError != "HTTP 500" {
+		t.Fatalf("unexpected dead letters %+v", letters)
+	}
+
+	// The next message becomes the head once its predecessor is parked.
+	var got []string
+	ok := func(_ context.Context, msg Message) error {
+		got = append(got, string(msg.Payload))
+		return nil
+	}
+	if _, err := ob.DeliverDue(ctx, ok); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	newID, err := ob.Replay(ctx, letters[0].ID)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	pending, err := ob.Pending(ctx, Filter{})
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != newID || pending[0].Attempts != 0 {
+		t.Fatalf("unexpected replayed message %+v", pending)
+	}
+	if _, err := ob.DeliverDue(ctx, ok); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if len(got) != 2 || got[0] != `{"n":2}` || got[1] != `{"n":1}` {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+	if letters, _ := ob.DeadLetters(ctx, Filter{}); len(letters) != 0 {
+		t.Fatalf("expected replay to remove dead letter, got %+v", letters)
+	}
+	if _, err := ob.Replay(ctx, letters[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found on second replay, got %v", err)
+	}
+}
+
+func TestPermanentAndDeferredOutcomes(t *testing.T) {
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	ob := newTestOutbox(t, clock)
+	ctx := context.Background()
+
+	mustEnqueue(t, ob, "gone", `{}`)
+	mustEnqueue(t, ob, "busy", `{}`)
+	until := clock.Now().Add(time.Minute)
+	deliver := func(_ context.Context, msg Message) error {
+		if msg.Subscriber == "gone" {
+			return Permanent(errors.New("subscription removed"))
+		}
+		return Defer(until)
+	}
+	if _, err := ob.DeliverDue(ctx, deliver); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	letters, _ := ob.DeadLetters(ctx, Filter{Subscriber: "gone"})
+	if len(letters) != 1 || letters[0].Attempts != 1 {
+		t.Fatalf("expected permanent failure to dead-letter immediately, got %+v", letters)
+	}
+	pending, _ := ob.Pending(ctx, Filter{Subscriber: "busy"})
+	if len(pending) != 1 || pending[0].Attempts != 0 || !pending[0].NextAttemptAt.Equal(until) {
+		t.Fatalf("expected deferral without attempt, got %+v", pending)
+	}
+}
+
+func TestOutboxSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.db")
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	db := openTestDB(t, path)
+	ob, err := New(db, WithClock(clock.Now))
+	if err != nil {
+		t.Fatalf("new outbox: %v", err)
+	}
+	mustEnqueue(t, ob, "a", `{"escrow":"released"}`)
+	if err := db.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	reopened, err := New(openTestDB(t, path), WithClock(clock.Now))
+	if err != nil {
+		t.Fatalf("reopen outbox: %v", err)
+	}
+	var got string
+	if _, err := reopened.DeliverDue(context.Background(), func(_ context.Context, msg Message) error {
+		got = string(msg.Payload)
+		return nil
+	}); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if got != `{"escrow":"released"}` {
+		t.Fatalf("expected persisted message after reopen, got %q", got)
+	}
+}
+
+func TestRunDeliversOnEnqueue(t *testing.T) {
+	ob := newTestOutbox(t, &testClock{now: time.Now()}, WithPollInterval(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	delivered := make(chan string, 1)
+	go func() {
+		_ = ob.Run(ctx, func(_ context.Context, msg Message) error {
+			delivered <- string(msg.Payload)
+			return nil
+		})
+	}()
+	mustEnqueue(t, ob, "a", `"hello"`)
+	select {
+	case got := <-delivered:
+		if got != `"hello"` {
+			t.Fatalf("unexpected payload %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for delivery")
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	ob := newTestOutbox(t, clock, WithRetryPolicy(RetryPolicy{MaxAttempts: 1, MinBackoff: time.Second, MaxBackoff: time.Second}))
+	ctx := context.Background()
+	mustEnqueue(t, ob, "a", `{"n":1}`)
+	mustEnqueue(t, ob, "b", `{"n":2}`)
+	if _, err := ob.DeliverDue(ctx, func(context.Context, Message) error { return errors.New("boom") }); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	mustEnqueue(t, ob, "a", `{"n":3}`)
+
+	handler := NewAdminHandler(ob)
+	do := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/dead-letters?subscriber=a")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list dead letters: %d %s", rec.Code, rec.Body.String())
+	}
+	var listed struct {
+		DeadLetters []struct {
+			ID         int64           `json:"id"`
+			Subscriber string          `json:"subscriber"`
+			Payload    json.RawMessage `json:"payload"`
+			LastError  string          `json:"lastError"`
+		} `json:"deadLetters"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(listed.DeadLetters) != 1 || string(listed.DeadLetters[0].Payload) != `{"n":1}` || listed.DeadLetters[0].LastError != "boom" {
+		t.Fatalf("unexpected listing %s", rec.Body.String())
+	}
+
+	if rec := do(http.MethodPost, "/dead-letters/999/replay"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown dead letter, got %d", rec.Code)
+	}
+	replayPath := "/dead-letters/" + strconv.FormatInt(listed.DeadLetters[0].ID, 10) + "/replay"
+	if rec := do(http.MethodPost, replayPath); rec.Code != http.StatusOK {
+		t.Fatalf("replay: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodGet, "/pending?subscriber=a")
+	var pending struct {
+		Messages []struct {
+			Payload json.RawMessage `json:"payload"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &pending); err != nil {
+		t.Fatalf("decode pending: %v", err)
+	}
+	if len(pending.Messages) != 2 || string(pending.Messages[1].Payload) != `{"n":1}` {
+		t.Fatalf("expected replayed message at the tail, got %s", rec.Body.String())
+	}
+
+	if rec := do(http.MethodDelete, "/dead-letters"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected unfiltered purge to be rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/dead-letters?subscriber=b"); rec.Code != http.StatusOK {
+		t.Fatalf("purge: %d %s", rec.Code, rec.Body.String())
+	}
+	if letters, _ := ob.DeadLetters(ctx, Filter{}); len(letters) != 0 {
+		t.Fatalf("expected dead letters purged, got %+v", letters)
+	}
+}
@@ -93,6 +93,30 @@ func (t *Trie) Reset(root common.Hash) error {
 	return nil
 }
 
+// Revert discards every change made since snapshot was taken with Copy,
+// including uncommitted ones, which Reset cannot restore.
+func (t *Trie) Revert(snapshot *Trie) {
+	t.trie = snapshot.trie.Copy()
+	t.root = snapshot.root
+}
+
+// ForEach visits every key/value pair stored in the trie in key order,
+// including uncommitted mutations. Iteration stops at the first error returned
+// by fn.
+func (t *Trie) ForEach(fn func(key, value []byte) error) error {
+	nodeIter, err := t.trie.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	iter := gethtrie.NewIterator(nodeIter)
+	for iter.Next() {
+		if err := fn(iter.Key, iter.Value); err != nil {
+			return err
+		}
+	}
+	return iter.Err
+}
+
 // Copy creates a shallow copy of the trie wrapper using go-ethereum's trie
 // cloning facilities. The returned trie shares the same underlying database but
 // can be mutated independently.
@@ -132,6 +156,34 @@ func (t *Trie) Commit(parent common.Hash, blockNumber uint64) (common.Hash, erro
 	return newRoot, nil
 }
 
+// Stage registers the pending mutations with the in-memory trie database
+// without flushing them to disk and returns the resulting root. Staged roots
+// can be opened by other state layers (e.g. go-ethereum's state package) for
+// speculative execution. The returned release function drops the staged nodes
+// again and must be called once the caller is done with the root; it is a
+// no-op when there were no pending mutations to stage.
+func (t *Trie) Stage(blockNumber uint64) (common.Hash, func(), error) {
+	newRoot, nodes := t.trie.Commit(false)
+	release := func() {}
+	if nodes != nil {
+		merged := trienode.NewMergedNodeSet()
+		if err := merged.Merge(nodes); err != nil {
+			return common.Hash{}, nil, err
+		}
+		if err := t.trieDB.Update(newRoot, t.root, blockNumber, merged, nil); err != nil {
+			return common.Hash{}, nil, err
+		}
+		release = func() { _ = t.trieDB.Dereference(newRoot) }
+	}
+	underlying, err := gethtrie.New(gethtrie.TrieID(newRoot), t.trieDB)
+	if err != nil {
+		release()
+		return common.Hash{}, nil, err
+	}
+	t.trie = underlying
+	return newRoot, release, nil
+}
+
 // Store exposes the backing storage in case callers need to access it directly.
 func (t *Trie) Store() storage.Database {
 	return t.store
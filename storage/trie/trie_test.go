@@ -39,3 +39,25 @@ func TestTrieCommitFlushPersistsData(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, value, got)
 }
+
+func TestTrieRevertRestoresUncommittedSnapshot(t *testing.T) {
+	tr, err := NewTrie(storage.NewMemDB(), nil)
+	require.NoError(t, err)
+
+	kept := crypto.Keccak256Hash([]byte("kept"))
+	dropped := crypto.Keccak256Hash([]byte("dropped"))
+	require.NoError(t, tr.Update(kept.Bytes(), []byte("v1")))
+
+	snapshot, err := tr.Copy()
+	require.NoError(t, err)
+	require.NoError(t, tr.Update(kept.Bytes(), []byte("v2")))
+	require.NoError(t, tr.Update(dropped.Bytes(), []byte("x")))
+
+	tr.Revert(snapshot)
+	got, err := tr.Get(kept.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), got)
+	got, err = tr.Get(dropped.Bytes())
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
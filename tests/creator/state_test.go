@@ -11,6 +11,8 @@ type testState struct {
 	contents map[string]*creator.Content
 	stakes   map[string]*creator.Stake
 	ledgers  map[string]*creator.PayoutLedger
+	tiers    map[string]*creator.SubscriptionTier
+	subs     map[string]*creator.Subscription
 	accounts map[string]*types.Account
 	rate     *creator.RateLimitSnapshot
 }
@@ -20,6 +22,8 @@ func newTestState() *testState {
 		contents: make(map[string]*creator.Content),
 		stakes:   make(map[string]*creator.Stake),
 		ledgers:  make(map[string]*creator.PayoutLedger),
+		tiers:    make(map[string]*creator.SubscriptionTier),
+		subs:     make(map[string]*creator.Subscription),
 		accounts: make(map[string]*types.Account),
 	}
 }
@@ -111,6 +115,54 @@ func (s *testState) CreatorPayoutLedgerPut(ledger *creator.PayoutLedger) error {
 	return nil
 }
 
+func (s *testState) CreatorTierGet(creatorAddr [20]byte, id string) (*creator.SubscriptionTier, bool, error) {
+	tier, ok := s.tiers[string(creatorAddr[:])+id]
+	if !ok {
+		return nil, false, nil
+	}
+	clone := *tier
+	if tier.Price != nil {
+		clone.Price = new(big.Int).Set(tier.Price)
+	}
+	return &clone, true, nil
+}
+
+func (s *testState) CreatorTierPut(tier *creator.SubscriptionTier) error {
+	if tier == nil {
+		return nil
+	}
+	clone := *tier
+	if tier.Price != nil {
+		clone.Price = new(big.Int).Set(tier.Price)
+	}
+	s.tiers[string(tier.Creator[:])+tier.ID] = &clone
+	return nil
+}
+
+func (s *testState) CreatorSubscriptionGet(creatorAddr [20]byte, fan [20]byte) (*creator.Subscription, bool, error) {
+	sub, ok := s.subs[stakeKey(creatorAddr, fan)]
+	if !ok {
+		return nil, false, nil
+	}
+	clone := *sub
+	if sub.Price != nil {
+		clone.Price = new(big.Int).Set(sub.Price)
+	}
+	return &clone, true, nil
+}
+
+func (s *testState) CreatorSubscriptionPut(sub *creator.Subscription) error {
+	if sub == nil {
+		return nil
+	}
+	clone := *sub
+	if sub.Price != nil {
+		clone.Price = new(big.Int).Set(sub.Price)
+	}
+	s.subs[stakeKey(sub.Creator, sub.Fan)] = &clone
+	return nil
+}
+
 func (s *testState) CreatorRateLimitGet() (*creator.RateLimitSnapshot, bool, error) {
 	if s.rate == nil {
 		return nil, false, nil
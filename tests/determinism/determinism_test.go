@@ -5,11 +5,42 @@ import (
 	"time"
 
 	"nhbchain/core/engagement"
+	"nhbchain/core/state"
+	"nhbchain/storage"
+	statetrie "nhbchain/storage/trie"
 )
 
+// newDeviceRegistry returns an empty state-backed device registry.
+func newDeviceRegistry(t *testing.T) *state.Manager {
+	t.Helper()
+	db := storage.NewMemDB()
+	t.Cleanup(db.Close)
+	tr, err := statetrie.NewTrie(db, nil)
+	if err != nil {
+		t.Fatalf("create trie: %v", err)
+	}
+	return state.NewManager(tr)
+}
+
+// registerDevice issues a token and stores the registration the way an
+// included TxTypeRegisterDevice transaction would.
+func registerDevice(t *testing.T, manager *engagement.Manager, registry *state.Manager, owner [20]byte, deviceID string) string {
+	t.Helper()
+	token, err := manager.RegisterDevice(owner, deviceID)
+	if err != nil {
+		t.Fatalf("register device: %v", err)
+	}
+	device := &state.EngagementDevice{DeviceID: deviceID, Owner: owner, TokenHash: engagement.TokenHash(token)}
+	if err := registry.EngagementDevicePut(device, 0); err != nil {
+		t.Fatalf("store device: %v", err)
+	}
+	return token
+}
+
 func TestHeartbeatSequenceDeterministic(t *testing.T) {
 	cfg := engagement.DefaultConfig()
-	manager := engagement.NewManager(cfg)
+	registry := newDeviceRegistry(t)
+	manager := engagement.NewManagerWithRegistry(cfg, registry)
 
 	now := time.Unix(1700000000, 0)
 	manager.SetNow(func() time.Time {
@@ -19,10 +50,7 @@ func TestHeartbeatSequenceDeterministic(t *testing.T) {
 	var validator [20]byte
 	copy(validator[:], []byte("deterministic-validator"))
 
-	token, err := manager.RegisterDevice(validator, "validator-device")
-	if err != nil {
-		t.Fatalf("register device: %v", err)
-	}
+	token := registerDevice(t, manager, registry, validator, "validator-device")
 
 	stamp1, err := manager.SubmitHeartbeat("validator-device", token, 0)
 	if err != nil {
@@ -48,11 +76,9 @@ func TestHeartbeatSequenceDeterministic(t *testing.T) {
 	})
 
 	// Recreate the manager to ensure the same explicit timestamps are accepted.
-	manager2 := engagement.NewManager(cfg)
-	token2, err := manager2.RegisterDevice(validator, "validator-device")
-	if err != nil {
-		t.Fatalf("register device on new manager: %v", err)
-	}
+	registry2 := newDeviceRegistry(t)
+	manager2 := engagement.NewManagerWithRegistry(cfg, registry2)
+	token2 := registerDevice(t, manager2, registry2, validator, "validator-device")
 	for _, ts := range []int64{stamp1, stamp2 + int64(cfg.HeartbeatInterval.Seconds())} {
 		got, err := manager2.SubmitHeartbeat("validator-device", token2, ts)
 		if err != nil {
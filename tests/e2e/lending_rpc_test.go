@@ -270,7 +270,7 @@ func TestLendingRPCEndpoints(t *testing.T) {
 		t.Fatalf("expected zero debt, got borrowed=%+v borrowedValueUsd=%v", accountResult.Account.Borrowed, accountResult.Account.BorrowedValueUsd)
 	}
 
-	if _, moduleErr := lendingModule.Liquidate("default", [20]byte(liquidatorAddr.Bytes()), [20]byte(borrowerAddr.Bytes())); moduleErr != nil {
+	if _, moduleErr := lendingModule.Liquidate("default", [20]byte(liquidatorAddr.Bytes()), [20]byte(borrowerAddr.Bytes()), "", nil); moduleErr != nil {
 		t.Fatalf("liquidate: %+v", moduleErr)
 	}
 
@@ -346,10 +346,10 @@ func seedLendingState(node *core.Node, userAddr, liquidatorAddr, borrowerAddr cr
 		}
 
 		unhealthy := &lending.UserAccount{
-			Address:        borrowerAddr,
-			CollateralZNHB: weiBig(100),
-			DebtNHB:        weiBig(120),
-			ScaledDebt:     weiBig(120),
+			Address:    borrowerAddr,
+			Collateral: map[string]*big.Int{lending.AssetZNHB: weiBig(100)},
+			DebtNHB:    weiBig(120),
+			ScaledDebt: weiBig(120),
 		}
 		if err := manager.LendingPutUserAccount(poolID, unhealthy); err != nil {
 			return err
@@ -72,6 +72,18 @@ func (s *fuzzCreatorState) CreatorRateLimitGet() (*creator.RateLimitSnapshot, bo
 
 func (s *fuzzCreatorState) CreatorRateLimitPut(*creator.RateLimitSnapshot) error { return nil }
 
+func (s *fuzzCreatorState) CreatorTierGet([20]byte, string) (*creator.SubscriptionTier, bool, error) {
+	return nil, false, nil
+}
+
+func (s *fuzzCreatorState) CreatorTierPut(*creator.SubscriptionTier) error { return nil }
+
+func (s *fuzzCreatorState) CreatorSubscriptionGet([20]byte, [20]byte) (*creator.Subscription, bool, error) {
+	return nil, false, nil
+}
+
+func (s *fuzzCreatorState) CreatorSubscriptionPut(*creator.Subscription) error { return nil }
+
 func FuzzCreatorURISanitization(f *testing.F) {
 	seeds := []string{
 		"https://example.com/content",
@@ -50,11 +50,11 @@ func setupCapsEngine(moduleAddr, collateralAddr, borrower crypto.Address, modify
 	state.accounts[state.key(moduleAddr)] = &types.Account{BalanceNHB: new(big.Int).Mul(one, big.NewInt(50))}
 	state.accounts[state.key(borrower)] = &types.Account{BalanceNHB: big.NewInt(0)}
 	state.users[state.key(borrower)] = &lending.UserAccount{
-		Address:        borrower,
-		CollateralZNHB: new(big.Int).Mul(one, big.NewInt(10)),
-		SupplyShares:   big.NewInt(0),
-		DebtNHB:        big.NewInt(0),
-		ScaledDebt:     big.NewInt(0),
+		Address:      borrower,
+		Collateral:   map[string]*big.Int{lending.AssetZNHB: new(big.Int).Mul(one, big.NewInt(10))},
+		SupplyShares: big.NewInt(0),
+		DebtNHB:      big.NewInt(0),
+		ScaledDebt:   big.NewInt(0),
 	}
 	return engine, state
 }
@@ -219,6 +219,10 @@ func (s *testState) EscrowFrozenPolicyGet([32]byte) (*escrowpkg.FrozenArb, bool,
 	return nil, false, nil
 }
 func (s *testState) ParamStoreGet(string) ([]byte, bool, error) { return nil, false, nil }
+func (s *testState) TokenExists(string) bool                    { return false }
+func (s *testState) AssetTransfer([]byte, []byte, string, *big.Int) error {
+	return fmt.Errorf("issued assets not supported")
+}
 
 func newAddress(seed byte) [20]byte {
 	var addr [20]byte